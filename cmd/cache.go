@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd is the `pop cache` command group. Bare `pop cache` prints help.
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the glob cache",
+	Long: `The glob cache remembers what each projects glob expanded to, so the picker
+opens without listing every directory. An entry is reused until a directory it
+depends on changes, or, with cache_ttl set, until it is older than that.`,
+}
+
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the cached globs and whether each is still fresh",
+	Long: `Print the glob cache's location and one line per cached glob: its match
+count, when it was cached, and whether the next scan reuses it ("fresh") or
+globs again because a directory changed ("changed") or it is older than
+cache_ttl ("expired").`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheStatusWith(defaultCacheDeps())
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the glob cache",
+	Long:  `Delete the glob cache so the next scan lists every projects directory again.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheClearWith(defaultCacheDeps())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatusCmd, cacheClearCmd)
+}
+
+// cacheDeps holds dependencies for the pop cache commands.
+type cacheDeps struct {
+	CachePath  string
+	LoadConfig func() (*config.Config, error)
+	Now        func() time.Time
+	HomeDir    func() (string, error)
+	Stdout     io.Writer
+}
+
+func defaultCacheDeps() *cacheDeps {
+	return &cacheDeps{
+		CachePath: config.DefaultCachePath(),
+		LoadConfig: func() (*config.Config, error) {
+			return config.Load(config.DefaultConfigPath())
+		},
+		Now:     time.Now,
+		HomeDir: os.UserHomeDir,
+		Stdout:  os.Stdout,
+	}
+}
+
+func runCacheStatusWith(d *cacheDeps) error {
+	var ttl time.Duration
+	if cfg, err := d.LoadConfig(); err == nil {
+		ttl = cfg.GlobCacheTTL()
+	}
+	statuses, err := config.InspectGlobCache(d.CachePath, ttl, d.Now())
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(d.Stdout, "No glob cache at %s\n", d.CachePath)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w (pop cache clear removes it)", err)
+	}
+
+	fmt.Fprintf(d.Stdout, "Glob cache: %s (%d entries)\n", d.CachePath, len(statuses))
+	if ttl > 0 {
+		fmt.Fprintf(d.Stdout, "cache_ttl: %s\n", ttl)
+	} else {
+		fmt.Fprintln(d.Stdout, "cache_ttl: none (entries last until a directory changes)")
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	home, _ := d.HomeDir()
+	fmt.Fprintln(d.Stdout)
+	tw := tabwriter.NewWriter(d.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range statuses {
+		state := "fresh"
+		switch {
+		case s.Changed:
+			state = "changed"
+		case s.Expired:
+			state = "expired"
+		}
+		cached := "unknown"
+		if !s.CachedAt.IsZero() {
+			cached = s.CachedAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%d matches\t%s\t%s\n", ui.ContractTilde(s.Pattern, home), s.Matches, cached, state)
+	}
+	return tw.Flush()
+}
+
+func runCacheClearWith(d *cacheDeps) error {
+	if _, err := os.Stat(d.CachePath); errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(d.Stdout, "No glob cache at %s\n", d.CachePath)
+		return nil
+	}
+	if err := config.ClearGlobCache(d.CachePath); err != nil {
+		return fmt.Errorf("clear glob cache: %w", err)
+	}
+	fmt.Fprintf(d.Stdout, "Removed %s\n", d.CachePath)
+	return nil
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+)
+
+// writeGlobCache writes a glob cache with a fresh entry for dir/* and one
+// whose tracked directory has since changed.
+func writeGlobCache(t *testing.T, path, dir string, cachedAt time.Time) {
+	t.Helper()
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := config.GlobCache{Version: 1, Entries: map[string]config.GlobCacheEntry{
+		dir + "/*": {
+			BasePath:  dir,
+			Matches:   []string{dir + "/a", dir + "/b"},
+			DirMtimes: map[string]time.Time{dir: info.ModTime()},
+			CachedAt:  cachedAt,
+		},
+		dir + "/gone/*": {
+			BasePath:  dir + "/gone",
+			Matches:   []string{dir + "/gone/x"},
+			DirMtimes: map[string]time.Time{dir + "/gone": info.ModTime()},
+		},
+	}}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testCacheDeps(path, ttl string, now time.Time, out *bytes.Buffer) *cacheDeps {
+	return &cacheDeps{
+		CachePath:  path,
+		LoadConfig: func() (*config.Config, error) { return &config.Config{CacheTTL: ttl}, nil },
+		Now:        func() time.Time { return now },
+		HomeDir:    func() (string, error) { return "", nil },
+		Stdout:     out,
+	}
+}
+
+func TestRunCacheStatus(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "glob_cache.json")
+	now := time.Now()
+	writeGlobCache(t, path, dir, now.Add(-2*time.Hour))
+
+	var out bytes.Buffer
+	if err := runCacheStatusWith(testCacheDeps(path, "", now, &out)); err != nil {
+		t.Fatalf("runCacheStatusWith() error = %v", err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if !strings.Contains(lines[0], "(2 entries)") || !strings.Contains(out.String(), "cache_ttl: none") {
+		t.Errorf("header missing entry count or ttl:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), dir+"/*") || !strings.Contains(out.String(), "2 matches") {
+		t.Errorf("status missing the fresh entry:\n%s", out.String())
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, dir+"/* ") && !strings.HasSuffix(line, "fresh") {
+			t.Errorf("line %q, want fresh", line)
+		}
+		if strings.HasPrefix(line, dir+"/gone/*") && !strings.HasSuffix(line, "changed") {
+			t.Errorf("line %q, want changed", line)
+		}
+	}
+
+	out.Reset()
+	if err := runCacheStatusWith(testCacheDeps(path, "1h", now, &out)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "cache_ttl: 1h0m0s") || !strings.Contains(out.String(), "expired") {
+		t.Errorf("a 1h ttl should expire the 2h old entry:\n%s", out.String())
+	}
+}
+
+func TestRunCacheStatus_NoCache(t *testing.T) {
+	var out bytes.Buffer
+	path := filepath.Join(t.TempDir(), "glob_cache.json")
+	if err := runCacheStatusWith(testCacheDeps(path, "", time.Now(), &out)); err != nil {
+		t.Fatalf("runCacheStatusWith() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "No glob cache") {
+		t.Errorf("output = %q, want a no-cache note", out.String())
+	}
+}
+
+func TestRunCacheClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glob_cache.json")
+	writeGlobCache(t, path, t.TempDir(), time.Now())
+
+	var out bytes.Buffer
+	if err := runCacheClearWith(testCacheDeps(path, "", time.Now(), &out)); err != nil {
+		t.Fatalf("runCacheClearWith() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cache still present after clear: %v", err)
+	}
+	if !strings.Contains(out.String(), "Removed "+path) {
+		t.Errorf("output = %q, want a removed note", out.String())
+	}
+
+	out.Reset()
+	if err := runCacheClearWith(testCacheDeps(path, "", time.Now(), &out)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No glob cache") {
+		t.Errorf("second clear output = %q, want a no-cache note", out.String())
+	}
+}
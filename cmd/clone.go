@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneBare bool
+	cloneRoot string
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <url>",
+	Short: "Clone a repository into your projects and open it",
+	Long: `Clone a repository into the projects root and switch to a session for it.
+
+The root is --root, else clone_root from the config, else the base directory of
+the first "<dir>/*" projects glob. With --bare the repository is cloned as a
+bare repo (<name>/.bare) with a worktree for its default branch, the layout
+pop expands into one picker entry per worktree.
+
+The clone is recorded in history, and added to the projects list unless an
+existing entry already covers it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runClone,
+}
+
+func init() {
+	rootCmd.AddCommand(cloneCmd)
+	cloneCmd.Flags().BoolVar(&cloneBare, "bare", false, "clone as a bare repo with a worktree for the default branch")
+	cloneCmd.Flags().StringVar(&cloneRoot, "root", "", "directory to clone into (overrides clone_root)")
+}
+
+// cloneDeps holds the dependencies behind pop clone. Config, history and
+// session handling come from the project command's deps.
+type cloneDeps struct {
+	Project *ProjectDeps
+	Git     deps.Git
+	FS      deps.FileSystem
+}
+
+func runClone(cmd *cobra.Command, args []string) error {
+	d := &cloneDeps{
+		Project: DefaultProjectDeps(),
+		Git:     deps.NewRealGit(),
+		FS:      deps.NewRealFileSystem(),
+	}
+	return runCloneWith(d, args[0], cloneRoot, cloneBare, os.Stdout)
+}
+
+// runCloneWith clones url under root (or the configured clone root), registers
+// the clone and opens a session for it.
+func runCloneWith(d *cloneDeps, url, root string, bare bool, w io.Writer) error {
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	cfg, err := d.Project.LoadConfig()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg = &config.Config{}
+	}
+
+	if root == "" {
+		root = cfg.GetCloneRoot()
+	}
+	if root == "" {
+		return fmt.Errorf("no clone root: set clone_root in %s or pass --root", cfgPath)
+	}
+	name := repoNameFromURL(url)
+	if name == "" {
+		return fmt.Errorf("cannot derive a directory name from %q", url)
+	}
+	dest := filepath.Join(root, name)
+	if _, err := d.FS.Stat(dest); err == nil {
+		return fmt.Errorf("%s already exists", dest)
+	}
+	if err := d.FS.MkdirAll(root, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", root, err)
+	}
+
+	fmt.Fprintf(w, "Cloning %s into %s\n", url, dest)
+	sessionPath := dest
+	if bare {
+		sessionPath, err = cloneBareWith(d, url, dest)
+	} else {
+		_, err = d.Git.Command("clone", url, dest)
+	}
+	if err != nil {
+		return fmt.Errorf("clone %s: %w", url, err)
+	}
+
+	if !cfg.MatchesProjectPath(dest) {
+		add, err := planProjectsAddition(d.FS, cfgPath, []config.ProjectEntry{{Path: dest}})
+		if err != nil {
+			return err
+		}
+		if add != nil {
+			if err := add.write(d.FS); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "Added %s to %s\n", dest, add.Path)
+		}
+	}
+
+	if hist, err := d.Project.LoadHistory(); err == nil {
+		if err := hist.Update(func(h *history.History) { h.Record(sessionPath) }); err != nil {
+			debug.Error("clone: save history: %v", err)
+		}
+	}
+
+	item := &ui.Item{
+		Name:        filepath.Base(sessionPath),
+		Path:        sessionPath,
+		SessionName: project.SessionNameWith(d.Project.Project, sessionPath),
+	}
+	tmux := withSessionHooks(d.Project.Tmux, &sessionHooks{cfg: cfg, Run: d.Project.RunHook})
+	return d.Project.OpenSession(tmux, item)
+}
+
+// cloneBareWith clones url as <dest>/.bare with a .git file pointing at it,
+// then adds a worktree for the default branch, returning its path.
+func cloneBareWith(d *cloneDeps, url, dest string) (string, error) {
+	if _, err := d.Git.Command("clone", "--bare", url, filepath.Join(dest, ".bare")); err != nil {
+		return "", err
+	}
+	if err := d.FS.MkdirAll(dest, 0o755); err != nil {
+		return "", err
+	}
+	if err := d.FS.WriteFile(filepath.Join(dest, ".git"), []byte("gitdir: ./.bare\n"), 0o644); err != nil {
+		return "", err
+	}
+	// A bare clone maps no remote-tracking refs; restore the usual refspec so
+	// fetch and the worktree picker's remote branches work.
+	if _, err := d.Git.CommandInDir(dest, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return "", err
+	}
+	branch, err := d.Git.CommandInDir(dest, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("resolve default branch: %w", err)
+	}
+	branch, dir := project.DeriveWorktreeName(strings.TrimSpace(branch), false)
+	worktree := filepath.Join(dest, dir)
+	if _, err := d.Git.CommandInDir(dest, "worktree", "add", worktree, branch); err != nil {
+		return "", err
+	}
+	return worktree, nil
+}
+
+// repoNameFromURL returns the directory name git clone would pick for url:
+// its last path segment without a trailing .git.
+func repoNameFromURL(url string) string {
+	url = strings.TrimRight(url, "/")
+	if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+		url = url[i+1:]
+	}
+	return strings.TrimSuffix(url, ".git")
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+// cloneTestDeps returns clone deps over a real filesystem with a config file
+// listing projects, git calls recorded into calls, and the opened item stored
+// into opened.
+func cloneTestDeps(t *testing.T, projects []string, calls *[]string, opened **ui.Item) (*cloneDeps, string) {
+	t.Helper()
+	pd := testProjectDeps(t)
+
+	cfgPath := filepath.Join(t.TempDir(), "config.toml")
+	var entries []config.ProjectEntry
+	for _, p := range projects {
+		entries = append(entries, config.ProjectEntry{Path: p})
+	}
+	data, err := encodeConfig(&config.Config{Projects: entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	oldCfgFile := cfgFile
+	cfgFile = cfgPath
+	t.Cleanup(func() { cfgFile = oldCfgFile })
+	pd.LoadConfig = func() (*config.Config, error) { return config.Load(cfgPath) }
+	pd.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		*opened = item
+		return nil
+	}
+
+	git := &deps.MockGit{
+		CommandFunc: func(args ...string) (string, error) {
+			*calls = append(*calls, strings.Join(args, " "))
+			return "", nil
+		},
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			*calls = append(*calls, "-C "+dir+" "+strings.Join(args, " "))
+			if args[0] == "symbolic-ref" {
+				return "main\n", nil
+			}
+			return "", nil
+		},
+	}
+	return &cloneDeps{Project: pd, Git: git, FS: deps.NewRealFileSystem()}, cfgPath
+}
+
+func TestRunCloneWithBareRegistersAndOpens(t *testing.T) {
+	var calls []string
+	var opened *ui.Item
+	d, cfgPath := cloneTestDeps(t, []string{"/elsewhere/*"}, &calls, &opened)
+	root := t.TempDir()
+
+	var out strings.Builder
+	if err := runCloneWith(d, "git@github.com:me/tool.git", root, true, &out); err != nil {
+		t.Fatalf("runCloneWith: %v", err)
+	}
+
+	dest := filepath.Join(root, "tool")
+	wantCalls := []string{
+		"clone --bare git@github.com:me/tool.git " + filepath.Join(dest, ".bare"),
+		"-C " + dest + " config remote.origin.fetch +refs/heads/*:refs/remotes/origin/*",
+		"-C " + dest + " symbolic-ref --short HEAD",
+		"-C " + dest + " worktree add " + filepath.Join(dest, "main") + " main",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("git calls = %q, want %q", calls, wantCalls)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, ".git")); err != nil || string(data) != "gitdir: ./.bare\n" {
+		t.Errorf(".git file = %q, %v", data, err)
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.MatchesProjectPath(dest) {
+		t.Errorf("config projects %v do not cover %s", cfg.Projects, dest)
+	}
+
+	hist, err := d.Project.LoadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hist.Entries) != 1 || hist.Entries[0].Path != filepath.Join(dest, "main") {
+		t.Errorf("history = %+v, want the main worktree", hist.Entries)
+	}
+	if opened == nil || opened.Path != filepath.Join(dest, "main") {
+		t.Errorf("opened = %+v, want the main worktree", opened)
+	}
+}
+
+func TestRunCloneWithUsesGlobRootAndKeepsConfig(t *testing.T) {
+	var calls []string
+	var opened *ui.Item
+	root := t.TempDir()
+	d, cfgPath := cloneTestDeps(t, []string{root + "/*"}, &calls, &opened)
+	before, _ := os.ReadFile(cfgPath)
+
+	if err := runCloneWith(d, "https://example.com/me/app/", "", false, &strings.Builder{}); err != nil {
+		t.Fatalf("runCloneWith: %v", err)
+	}
+
+	dest := filepath.Join(root, "app")
+	if want := []string{"clone https://example.com/me/app/ " + dest}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("git calls = %q, want %q", calls, want)
+	}
+	if after, _ := os.ReadFile(cfgPath); string(after) != string(before) {
+		t.Errorf("config rewritten although %s/* covers the clone", root)
+	}
+	if opened == nil || opened.Path != dest {
+		t.Errorf("opened = %+v, want %s", opened, dest)
+	}
+}
+
+func TestRunCloneWithAppendsToFileOwningProjects(t *testing.T) {
+	var calls []string
+	var opened *ui.Item
+	d, cfgPath := cloneTestDeps(t, nil, &calls, &opened)
+	main := "# mine\nincludes = [\"work.toml\"]\n"
+	work := "# work repos\n[[projects]]\npath = \"/elsewhere/*\"\n"
+	workPath := filepath.Join(filepath.Dir(cfgPath), "work.toml")
+	if err := os.WriteFile(cfgPath, []byte(main), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(workPath, []byte(work), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+
+	if err := runCloneWith(d, "https://example.com/app.git", root, false, &strings.Builder{}); err != nil {
+		t.Fatalf("runCloneWith: %v", err)
+	}
+
+	if got, _ := os.ReadFile(cfgPath); string(got) != main {
+		t.Errorf("main config rewritten:\n%s", got)
+	}
+	want := work + "\n[[projects]]\npath = \"" + filepath.Join(root, "app") + "\"\n"
+	if got, _ := os.ReadFile(workPath); string(got) != want {
+		t.Errorf("work.toml =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRunCloneWithRefusesExistingDir(t *testing.T) {
+	var calls []string
+	var opened *ui.Item
+	d, _ := cloneTestDeps(t, nil, &calls, &opened)
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runCloneWith(d, "https://example.com/app.git", root, false, &strings.Builder{})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("err = %v, want already exists", err)
+	}
+	if len(calls) != 0 || opened != nil {
+		t.Errorf("cloned or opened despite the existing directory: %v", calls)
+	}
+}
+
+func TestRepoNameFromURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"git@github.com:me/tool.git":     "tool",
+		"https://example.com/me/app/":    "app",
+		"https://example.com/me/app.git": "app",
+		"/srv/git/lib":                   "lib",
+	} {
+		if got := repoNameFromURL(url); got != want {
+			t.Errorf("repoNameFromURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -21,7 +21,9 @@ var configCmd = &cobra.Command{
 pop config keys lists the keys each config surface accepts, so you can learn
 what is available without trial and error. The list is reflected directly from
 the code that decodes each surface, so it never drifts from what actually
-loads.`,
+loads.
+
+pop config edit opens the config in $EDITOR and validates it on save.`,
 }
 
 var (
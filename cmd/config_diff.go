@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines unifiedDiff keeps around each
+// change, as diff -u does.
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the unified diff turning before into after, labelled
+// with path, or "" when they are equal. Config files are small, so a plain
+// LCS table is fast enough.
+func unifiedDiff(path string, before, after []byte) string {
+	a, b := diffLines(before), diffLines(after)
+	ops := diffEditScript(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s (new)\n", path, path)
+	changed := false
+	// aLine and bLine count the lines of a and b before ops[i].
+	aLine, bLine := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			aLine++
+			bLine++
+			continue
+		}
+		changed = true
+		// Take in the leading context, then extend the hunk until a run of
+		// unchanged lines longer than two contexts ends it.
+		start := max(i-diffContext, 0)
+		aStart, bStart := aLine-(i-start), bLine-(i-start)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = run
+		}
+
+		var aCount, bCount int
+		var body strings.Builder
+		for _, op := range ops[start:end] {
+			body.WriteByte(op.kind)
+			body.WriteString(op.line)
+			body.WriteByte('\n')
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		out.WriteString(body.String())
+
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				aLine++
+			}
+			if op.kind != '-' {
+				bLine++
+			}
+		}
+		i = end
+	}
+	if !changed {
+		return ""
+	}
+	return out.String()
+}
+
+// hunkRange formats a hunk header range: the 1-based first line and the line
+// count, with the line before the hunk for an empty range.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffLines splits data into lines without their newlines.
+func diffLines(data []byte) []string {
+	s := strings.TrimSuffix(string(data), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffEditScript returns the shortest edit script turning a into b, removals
+// before additions within each change.
+func diffEditScript(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// colorizeDiff colors a unified diff from unifiedDiff: the two file header
+// lines bold, hunk headers cyan, removed lines red and added lines green.
+func colorizeDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		style := ""
+		switch {
+		case i < 2:
+			style = doctorANSIBold
+		case strings.HasPrefix(line, "@@"):
+			style = doctorANSICyan
+		case strings.HasPrefix(line, "-"):
+			style = doctorANSIRed
+		case strings.HasPrefix(line, "+"):
+			style = doctorANSIGreen
+		}
+		if style != "" {
+			text := strings.TrimSuffix(line, "\n")
+			lines[i] = style + text + doctorANSIReset + line[len(text):]
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          string
+	}{
+		{
+			name:   "equal",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "new file",
+			before: "",
+			after:  "a\n",
+			want:   "--- c.toml\n+++ c.toml (new)\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			name:   "change keeps three lines of context",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			after:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want:   "--- c.toml\n+++ c.toml (new)\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:   "distant changes get separate hunks",
+			before: "# comment\n1\n2\n3\n4\n5\n6\n7\n8\n9\nlast\n",
+			after:  "1\n2\n3\n4\n5\n6\n7\n8\n9\nlast\nadded\n",
+			want: "--- c.toml\n+++ c.toml (new)\n" +
+				"@@ -1,4 +1,3 @@\n-# comment\n 1\n 2\n 3\n" +
+				"@@ -9,3 +8,4 @@\n 8\n 9\n last\n+added\n",
+		},
+		{
+			name:   "close changes share a hunk",
+			before: "a\n1\n2\n3\nb\n",
+			after:  "A\n1\n2\n3\nB\n",
+			want:   "--- c.toml\n+++ c.toml (new)\n@@ -1,5 +1,5 @@\n-a\n+A\n 1\n 2\n 3\n-b\n+B\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("c.toml", []byte(tt.before), []byte(tt.after)); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorizeDiff(t *testing.T) {
+	got := colorizeDiff("--- c.toml\n+++ c.toml (new)\n@@ -1,1 +1,1 @@\n--- removed\n+added\n same\n")
+	for _, want := range []string{
+		doctorANSIBold + "--- c.toml" + doctorANSIReset + "\n",
+		doctorANSIRed + "--- removed" + doctorANSIReset + "\n",
+		doctorANSIGreen + "+added" + doctorANSIReset + "\n",
+		"\n same\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("colorizeDiff() = %q, want it to contain %q", got, want)
+		}
+	}
+}
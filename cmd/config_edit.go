@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/routine"
+	"github.com/spf13/cobra"
+)
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config in $EDITOR and validate it on save",
+	Long: `Open the active config file in $EDITOR (falling back to vi), then re-load it
+once the editor exits.
+
+Warnings are printed but accepted. When the file no longer loads (a TOML syntax
+error, an unreadable include), the error is printed and you are offered to
+re-open the editor, so a broken config is caught here rather than silently
+breaking the popup later. A missing config file is created on save.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEdit,
+}
+
+func init() {
+	configCmd.AddCommand(configEditCmd)
+}
+
+// configEditDeps holds dependencies for pop config edit.
+type configEditDeps struct {
+	FS         deps.FileSystem
+	Stdin      io.Reader
+	Stdout     io.Writer
+	OpenEditor func(path string) error
+	Load       func(path string) (*config.Config, error)
+}
+
+func defaultConfigEditDeps() *configEditDeps {
+	return &configEditDeps{
+		FS:         deps.NewRealFileSystem(),
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		OpenEditor: routine.OpenEditor,
+		Load:       config.Load,
+	}
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	return runConfigEditWith(defaultConfigEditDeps())
+}
+
+// runConfigEditWith runs the edit → validate loop. It returns nil once the
+// config loads cleanly, and the load error when the user declines to re-open a
+// broken config or stdin has no answer to give.
+func runConfigEditWith(d *configEditDeps) error {
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	if err := d.FS.MkdirAll(filepath.Dir(cfgPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	scanner := bufio.NewScanner(d.Stdin)
+	for {
+		if err := d.OpenEditor(cfgPath); err != nil {
+			return fmt.Errorf("open config in editor: %w", err)
+		}
+		if _, err := d.FS.Stat(cfgPath); os.IsNotExist(err) {
+			fmt.Fprintf(d.Stdout, "%s was not saved; nothing to validate\n", cfgPath)
+			return nil
+		}
+
+		cfg, err := d.Load(cfgPath)
+		if err != nil {
+			fmt.Fprintf(d.Stdout, "Config error: %v\n", err)
+			if confirmReopen(scanner, d.Stdout) {
+				continue
+			}
+			return fmt.Errorf("config %s does not load: %w", cfgPath, err)
+		}
+
+		cfg.ScriptCommands() // records the scripts' problems as warnings
+		for _, w := range cfg.Warnings {
+			fmt.Fprintf(d.Stdout, "Warning: %s\n", w)
+		}
+		fmt.Fprintf(d.Stdout, "Config OK: %s\n", cfgPath)
+		return nil
+	}
+}
+
+// confirmReopen asks whether to re-open the editor on a broken config. Enter
+// means yes, but unlike confirmY a closed stdin means no: with no one to
+// answer, the editor would re-open forever.
+func confirmReopen(scanner *bufio.Scanner, w io.Writer) bool {
+	fmt.Fprint(w, "Re-open the editor to fix it? [Y/n]: ")
+	if !scanner.Scan() {
+		return false
+	}
+	return strings.ToLower(strings.TrimSpace(scanner.Text())) != "n"
+}
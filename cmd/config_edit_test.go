@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// editorWriting returns an OpenEditor stub that writes the next content on
+// each call, simulating a user saving successive revisions.
+func editorWriting(t *testing.T, calls *int, contents ...string) func(path string) error {
+	t.Helper()
+	return func(path string) error {
+		if *calls >= len(contents) {
+			t.Fatalf("editor opened %d times, want at most %d", *calls+1, len(contents))
+		}
+		content := contents[*calls]
+		*calls++
+		return os.WriteFile(path, []byte(content), 0o644)
+	}
+}
+
+func setTestCfgFile(t *testing.T) string {
+	t.Helper()
+	cfgPath := filepath.Join(t.TempDir(), "pop", "config.toml")
+	oldCfgFile := cfgFile
+	cfgFile = cfgPath
+	t.Cleanup(func() { cfgFile = oldCfgFile })
+	return cfgPath
+}
+
+func TestRunConfigEdit_ValidConfig(t *testing.T) {
+	setTestCfgFile(t)
+	var calls int
+	var output bytes.Buffer
+	d := &configEditDeps{
+		FS:         deps.NewRealFileSystem(),
+		Stdin:      strings.NewReader(""),
+		Stdout:     &output,
+		OpenEditor: editorWriting(t, &calls, `projects = [{ path = "/tmp" }]`+"\n"),
+		Load:       config.Load,
+	}
+
+	if err := runConfigEditWith(d); err != nil {
+		t.Fatalf("runConfigEditWith() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("editor opened %d times, want 1", calls)
+	}
+	if !strings.Contains(output.String(), "Config OK") {
+		t.Errorf("expected Config OK, got: %s", output.String())
+	}
+}
+
+func TestRunConfigEdit_ReopensUntilValid(t *testing.T) {
+	setTestCfgFile(t)
+	var calls int
+	var output bytes.Buffer
+	d := &configEditDeps{
+		FS:     deps.NewRealFileSystem(),
+		Stdin:  strings.NewReader("\n"), // default yes: re-open
+		Stdout: &output,
+		OpenEditor: editorWriting(t, &calls,
+			"projects = [\n",
+			`projects = [{ path = "/tmp" }]`+"\n",
+		),
+		Load: config.Load,
+	}
+
+	if err := runConfigEditWith(d); err != nil {
+		t.Fatalf("runConfigEditWith() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("editor opened %d times, want 2", calls)
+	}
+	out := output.String()
+	if !strings.Contains(out, "Config error:") || !strings.Contains(out, "Config OK") {
+		t.Errorf("expected an error then Config OK, got: %s", out)
+	}
+}
+
+func TestRunConfigEdit_DeclineReopenReturnsError(t *testing.T) {
+	setTestCfgFile(t)
+	var calls int
+	d := &configEditDeps{
+		FS:         deps.NewRealFileSystem(),
+		Stdin:      strings.NewReader("n\n"),
+		Stdout:     &bytes.Buffer{},
+		OpenEditor: editorWriting(t, &calls, "projects = [\n"),
+		Load:       config.Load,
+	}
+
+	err := runConfigEditWith(d)
+	if err == nil || !strings.Contains(err.Error(), "does not load") {
+		t.Errorf("runConfigEditWith() error = %v, want a load error", err)
+	}
+}
+
+func TestRunConfigEdit_ClosedStdinReturnsError(t *testing.T) {
+	setTestCfgFile(t)
+	var calls int
+	d := &configEditDeps{
+		FS:         deps.NewRealFileSystem(),
+		Stdin:      strings.NewReader(""),
+		Stdout:     &bytes.Buffer{},
+		OpenEditor: editorWriting(t, &calls, "projects = [\n"),
+		Load:       config.Load,
+	}
+
+	err := runConfigEditWith(d)
+	if err == nil || !strings.Contains(err.Error(), "does not load") {
+		t.Errorf("runConfigEditWith() error = %v, want a load error", err)
+	}
+	if calls != 1 {
+		t.Errorf("editor opened %d times, want 1", calls)
+	}
+}
+
+func TestRunConfigEdit_UnsavedNewFile(t *testing.T) {
+	setTestCfgFile(t)
+	var output bytes.Buffer
+	d := &configEditDeps{
+		FS:         deps.NewRealFileSystem(),
+		Stdin:      strings.NewReader(""),
+		Stdout:     &output,
+		OpenEditor: func(path string) error { return nil },
+		Load: func(path string) (*config.Config, error) {
+			t.Fatal("Load should not run when nothing was saved")
+			return nil, nil
+		},
+	}
+
+	if err := runConfigEditWith(d); err != nil {
+		t.Fatalf("runConfigEditWith() error = %v", err)
+	}
+	if !strings.Contains(output.String(), "was not saved") {
+		t.Errorf("expected not-saved notice, got: %s", output.String())
+	}
+}
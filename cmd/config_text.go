@@ -0,0 +1,430 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// The config files pop edits are the user's, comments and layout included,
+// so edits go in as text: new projects are appended to the file (or its
+// inline projects array) and the includes array is replaced in place, leaving
+// every other byte as it was.
+
+// projectsAddition is an append to a config's projects list, planned as text.
+type projectsAddition struct {
+	Path   string // the file that gets the entries
+	Before []byte
+	After  []byte
+}
+
+// planProjectsAddition plans adding entries to the file that owns the
+// config's projects list: the main config at cfgPath, unless it lists no
+// projects and one of its include files does. Nil means every entry is
+// listed already.
+func planProjectsAddition(fs deps.FileSystem, cfgPath string, entries []config.ProjectEntry) (*projectsAddition, error) {
+	path, before, err := projectsOwner(fs, cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	after, err := appendProjectsText(path, before, entries)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(after, before) {
+		return nil, nil
+	}
+	return &projectsAddition{Path: path, Before: before, After: after}, nil
+}
+
+func (a *projectsAddition) write(fs deps.FileSystem) error {
+	return writeConfigData(fs, a.Path, a.After)
+}
+
+// projectsOwner returns the path and contents of the file holding the
+// projects list of the config at cfgPath.
+func projectsOwner(fs deps.FileSystem, cfgPath string) (string, []byte, error) {
+	data, err := fs.ReadFile(cfgPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", nil, fmt.Errorf("failed to read %s: %w", cfgPath, err)
+	}
+	if paths, _, err := documentProjects(cfgPath, data); err != nil || len(paths) > 0 {
+		return cfgPath, data, err
+	}
+	for _, entry := range mainConfigIncludes(data) {
+		path := config.ResolveIncludePath(cfgPath, entry)
+		included, err := fs.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if paths, _, err := documentProjects(path, included); err == nil && len(paths) > 0 {
+			return path, included, nil
+		}
+	}
+	return cfgPath, data, nil
+}
+
+// appendProjectsText returns data with entries added to its projects list,
+// skipping patterns the file already lists. A file whose projects is an
+// inline array gets the entries inside that array; otherwise each becomes a
+// [[projects]] table at the end of the file.
+func appendProjectsText(path string, data []byte, entries []config.ProjectEntry) ([]byte, error) {
+	existing, inline, err := documentProjects(path, data)
+	if err != nil {
+		return nil, err
+	}
+	var added []config.ProjectEntry
+	for _, entry := range entries {
+		if !slices.Contains(existing, entry.Path) {
+			existing = append(existing, entry.Path)
+			added = append(added, entry)
+		}
+	}
+	if len(added) == 0 {
+		return data, nil
+	}
+
+	var out []byte
+	if inline {
+		_, open, end, ok := topLevelArray(data, "projects")
+		if !ok {
+			return nil, fmt.Errorf("failed to find the projects array in %s", path)
+		}
+		out = insertArrayElements(data, open, end, projectInlineTables(added))
+	} else {
+		var b bytes.Buffer
+		b.Write(data)
+		if len(data) > 0 {
+			if !bytes.HasSuffix(data, []byte("\n")) {
+				b.WriteByte('\n')
+			}
+			b.WriteByte('\n')
+		}
+		for i, entry := range added {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString("[[projects]]\n")
+			for _, kv := range projectKeyValues(entry) {
+				b.WriteString(kv + "\n")
+			}
+		}
+		out = b.Bytes()
+	}
+
+	after, _, err := documentProjects(path, out)
+	if err != nil || len(after) != len(existing) {
+		return nil, fmt.Errorf("failed to add projects to %s: the edited file does not parse back", path)
+	}
+	return out, nil
+}
+
+// setIncludesText returns data with its includes array replaced, added at
+// the top (below any leading comments) when the file has none, or removed
+// when includes is empty.
+func setIncludesText(path string, data []byte, includes []string) ([]byte, error) {
+	value := tomlStringArray(includes)
+	var out []byte
+	if line, open, end, ok := topLevelArray(data, "includes"); ok {
+		if len(includes) == 0 {
+			rest := end + 1
+			if nl := bytes.IndexByte(data[rest:], '\n'); nl >= 0 {
+				rest += nl + 1
+			} else {
+				rest = len(data)
+			}
+			out = slices.Concat(data[:line], data[rest:])
+		} else {
+			out = slices.Concat(data[:open], []byte(value), data[end+1:])
+		}
+	} else if len(includes) > 0 {
+		at := leadingCommentsEnd(data)
+		text := "includes = " + value + "\n"
+		if at < len(data) && data[at] != '\n' {
+			text += "\n"
+		}
+		out = slices.Concat(data[:at], []byte(text), data[at:])
+	} else {
+		return data, nil
+	}
+
+	var own struct {
+		Includes []string `toml:"includes"`
+	}
+	if _, err := toml.Decode(string(out), &own); err != nil || !slices.Equal(own.Includes, includes) {
+		return nil, fmt.Errorf("failed to set includes in %s: the edited file does not parse back", path)
+	}
+	return out, nil
+}
+
+// documentProjects returns the paths data's projects list holds and whether
+// it is written as an inline array. A projects key of any other shape is an
+// error, so an edit never drops entries it failed to read.
+func documentProjects(path string, data []byte) (paths []string, inline bool, err error) {
+	var doc map[string]any
+	md, err := toml.Decode(string(data), &doc)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	raw, ok := doc["projects"]
+	if !ok {
+		return nil, false, nil
+	}
+	var tables []map[string]any
+	switch v := raw.(type) {
+	case []map[string]any:
+		tables = v
+	case []any:
+		for _, item := range v {
+			table, ok := item.(map[string]any)
+			if !ok {
+				return nil, false, fmt.Errorf("projects in %s is not a list of tables", path)
+			}
+			tables = append(tables, table)
+		}
+	default:
+		return nil, false, fmt.Errorf("projects in %s is not a list of tables", path)
+	}
+	for _, table := range tables {
+		p, _ := table["path"].(string)
+		paths = append(paths, p)
+	}
+	return paths, md.Type("projects") == "Array", nil
+}
+
+// projectKeyValues renders entry's keys as TOML key/value pairs.
+func projectKeyValues(entry config.ProjectEntry) []string {
+	kvs := []string{"path = " + tomlString(entry.Path)}
+	if entry.DisplayDepth > 1 {
+		kvs = append(kvs, fmt.Sprintf("display_depth = %d", entry.DisplayDepth))
+	}
+	return kvs
+}
+
+func projectInlineTables(entries []config.ProjectEntry) []string {
+	tables := make([]string, len(entries))
+	for i, entry := range entries {
+		tables[i] = "{ " + strings.Join(projectKeyValues(entry), ", ") + " }"
+	}
+	return tables
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b bytes.Buffer
+	// Encoding a string can't fail.
+	_ = toml.NewEncoder(&b).Encode(map[string]string{"v": s})
+	return strings.TrimSuffix(strings.TrimPrefix(b.String(), "v = "), "\n")
+}
+
+func tomlStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = tomlString(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// insertArrayElements returns data with elems added after the last element
+// of the array whose brackets sit at open and end, following its layout: a
+// multi-line array gets one element per line at its indentation and keeps a
+// trailing comma if it had one.
+func insertArrayElements(data []byte, open, end int, elems []string) []byte {
+	last, first := -1, -1
+	for i := open + 1; i < end; {
+		switch c := data[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+			continue
+		case c == '#':
+			i = lineEnd(data, i)
+			continue
+		case c == '"' || c == '\'':
+			i = skipTOMLString(data, i)
+		case c == '[' || c == '{':
+			i = matchTOMLBracket(data, i) + 1
+		default:
+			i++
+		}
+		if first < 0 {
+			first = i - 1
+		}
+		last = i - 1
+	}
+
+	at := open + 1
+	if last >= 0 {
+		at = last + 1
+	}
+	var text strings.Builder
+	trailing := last >= 0 && data[last] == ','
+	if last >= 0 && !trailing {
+		text.WriteByte(',')
+	}
+	if bytes.IndexByte(data[open:end], '\n') >= 0 {
+		indent := "  "
+		if first >= 0 {
+			lineStart := bytes.LastIndexByte(data[:first], '\n') + 1
+			if lineStart > open {
+				indent = string(data[lineStart : lineStart+len(data[lineStart:])-len(bytes.TrimLeft(data[lineStart:], " \t"))])
+			}
+		}
+		for i, elem := range elems {
+			if i > 0 {
+				text.WriteByte(',')
+			}
+			text.WriteString("\n" + indent + elem)
+		}
+		if trailing || last < 0 {
+			text.WriteByte(',')
+		}
+	} else {
+		if last >= 0 {
+			text.WriteByte(' ')
+		}
+		text.WriteString(strings.Join(elems, ", "))
+		if trailing {
+			text.WriteByte(',')
+		}
+	}
+	return slices.Concat(data[:at], []byte(text.String()), data[at:])
+}
+
+// topLevelArray finds the array assigned to key before data's first table
+// header: the offset of its line and of its opening and closing brackets.
+func topLevelArray(data []byte, key string) (line, open, end int, ok bool) {
+	for i := 0; i < len(data); {
+		start := i
+		for i < len(data) && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+		if i == len(data) {
+			break
+		}
+		switch data[i] {
+		case '\n', '\r', '#':
+			i = lineEnd(data, i) + 1
+			continue
+		case '[':
+			return 0, 0, 0, false
+		}
+		eq := i
+		for eq < len(data) && data[eq] != '=' && data[eq] != '\n' {
+			eq++
+		}
+		if eq == len(data) || data[eq] != '=' {
+			i = lineEnd(data, i) + 1
+			continue
+		}
+		name := strings.Trim(strings.TrimSpace(string(data[i:eq])), `"'`)
+		v := eq + 1
+		for v < len(data) && (data[v] == ' ' || data[v] == '\t') {
+			v++
+		}
+		if name == key && v < len(data) && data[v] == '[' {
+			return start, v, matchTOMLBracket(data, v), true
+		}
+		i = valueEnd(data, v) + 1
+	}
+	return 0, 0, 0, false
+}
+
+// leadingCommentsEnd returns the offset just past the comment and blank
+// lines data starts with.
+func leadingCommentsEnd(data []byte) int {
+	i := 0
+	for i < len(data) {
+		trimmed := bytes.TrimLeft(data[i:], " \t")
+		if len(trimmed) > 0 && trimmed[0] != '#' && trimmed[0] != '\n' && trimmed[0] != '\r' {
+			return i
+		}
+		i = lineEnd(data, i) + 1
+	}
+	return len(data)
+}
+
+// valueEnd returns the offset of the newline ending the value starting at i,
+// which may span lines inside brackets or multi-line strings.
+func valueEnd(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case '\n':
+			return i
+		case '#':
+			return lineEnd(data, i)
+		case '"', '\'':
+			i = skipTOMLString(data, i)
+		case '[', '{':
+			i = matchTOMLBracket(data, i) + 1
+		default:
+			i++
+		}
+	}
+	return len(data)
+}
+
+// matchTOMLBracket returns the offset of the bracket closing the one at
+// open, skipping strings and comments, or len(data)-1 when it is unclosed.
+func matchTOMLBracket(data []byte, open int) int {
+	depth := 0
+	for i := open; i < len(data); {
+		switch data[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '#':
+			i = lineEnd(data, i)
+			continue
+		case '"', '\'':
+			i = skipTOMLString(data, i)
+			continue
+		}
+		i++
+	}
+	return len(data) - 1
+}
+
+// skipTOMLString returns the offset just past the string starting at i,
+// basic or literal, single- or multi-line.
+func skipTOMLString(data []byte, i int) int {
+	q := data[i]
+	delim := []byte{q}
+	if bytes.HasPrefix(data[i:], []byte{q, q, q}) {
+		delim = []byte{q, q, q}
+	}
+	for j := i + len(delim); j < len(data); j++ {
+		if q == '"' && data[j] == '\\' {
+			j++
+			continue
+		}
+		if bytes.HasPrefix(data[j:], delim) {
+			j += len(delim)
+			// A multi-line string may end in up to two extra quotes.
+			for len(delim) == 3 && j < len(data) && data[j] == q {
+				j++
+			}
+			return j
+		}
+	}
+	return len(data)
+}
+
+// lineEnd returns the offset of the newline ending the line holding i, or
+// len(data) on the last line.
+func lineEnd(data []byte, i int) int {
+	if nl := bytes.IndexByte(data[i:], '\n'); nl >= 0 {
+		return i + nl
+	}
+	return len(data)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+)
+
+func TestAppendProjectsText(t *testing.T) {
+	c := []config.ProjectEntry{{Path: "~/c"}}
+	tests := []struct {
+		name    string
+		data    string
+		entries []config.ProjectEntry
+		want    string
+	}{
+		{
+			name:    "empty file",
+			data:    "",
+			entries: []config.ProjectEntry{{Path: "~/c", DisplayDepth: 2}},
+			want:    "[[projects]]\npath = \"~/c\"\ndisplay_depth = 2\n",
+		},
+		{
+			name:    "tables keep comments",
+			data:    "# mine\nicons = \"ascii\" # short\n\n[[projects]]\npath = \"~/a\"",
+			entries: c,
+			want:    "# mine\nicons = \"ascii\" # short\n\n[[projects]]\npath = \"~/a\"\n\n[[projects]]\npath = \"~/c\"\n",
+		},
+		{
+			name:    "inline array",
+			data:    "projects = [ {path=\"~/a\"}, {path=\"~/b\"} ] # work\n",
+			entries: c,
+			want:    "projects = [ {path=\"~/a\"}, {path=\"~/b\"}, { path = \"~/c\" } ] # work\n",
+		},
+		{
+			name:    "multi-line inline array",
+			data:    "projects = [\n    { path = \"~/a\" }, # first\n    { path = \"~/b\" },\n]\n\n[ui]\nicons = \"ascii\"\n",
+			entries: c,
+			want:    "projects = [\n    { path = \"~/a\" }, # first\n    { path = \"~/b\" },\n    { path = \"~/c\" },\n]\n\n[ui]\nicons = \"ascii\"\n",
+		},
+		{
+			name:    "empty inline array",
+			data:    "projects = []\n",
+			entries: c,
+			want:    "projects = [{ path = \"~/c\" }]\n",
+		},
+		{
+			name:    "listed pattern skipped",
+			data:    "projects = [{ path = \"~/c\" }]\n",
+			entries: c,
+			want:    "projects = [{ path = \"~/c\" }]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := appendProjectsText("/cfg.toml", []byte(tt.data), tt.entries)
+			if err != nil {
+				t.Fatalf("appendProjectsText() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("appendProjectsText() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendProjectsText_RejectsOtherShapes(t *testing.T) {
+	for _, data := range []string{"projects = \"~/a\"\n", "projects = [\"~/a\"]\n", "projects = [\n"} {
+		if _, err := appendProjectsText("/cfg.toml", []byte(data), []config.ProjectEntry{{Path: "~/c"}}); err == nil {
+			t.Errorf("appendProjectsText(%q) error = nil, want an error", data)
+		}
+	}
+}
+
+func TestSetIncludesText(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		includes []string
+		want     string
+	}{
+		{
+			name:     "replaced in place",
+			data:     "# top\nincludes = [\n  \"a.toml\", # work\n]\n\n[[projects]]\npath = \"~/a\"\n",
+			includes: []string{"a.toml", "b.toml"},
+			want:     "# top\nincludes = [\"a.toml\", \"b.toml\"]\n\n[[projects]]\npath = \"~/a\"\n",
+		},
+		{
+			name:     "added below leading comments",
+			data:     "# top\n\n[[projects]]\npath = \"~/a\"\n",
+			includes: []string{"a.toml"},
+			want:     "# top\n\nincludes = [\"a.toml\"]\n\n[[projects]]\npath = \"~/a\"\n",
+		},
+		{
+			name:     "removed",
+			data:     "includes = [\"a.toml\"] # old\nicons = \"ascii\"\n",
+			includes: nil,
+			want:     "icons = \"ascii\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setIncludesText("/cfg.toml", []byte(tt.data), tt.includes)
+			if err != nil {
+				t.Fatalf("setIncludesText() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("setIncludesText() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
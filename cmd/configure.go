@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +13,7 @@ import (
 	"github.com/BurntSushi/toml"
 	"github.com/glebglazov/pop/config"
 	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
 	"github.com/glebglazov/pop/ui"
 	"github.com/spf13/cobra"
 )
@@ -22,6 +25,8 @@ var configureCmd = &cobra.Command{
 
 If a config already exists, shows current patterns and offers to add more.
 Opens a TUI for entering path patterns with tab completion and live preview.
+When the config has include files, lists them and asks which file the new
+patterns go to; pop configure includes adds, removes, and creates them.
 
 Example:
   pop configure`,
@@ -39,6 +44,11 @@ type configureDeps struct {
 	Stdout      io.Writer
 	PickDir     func() (ui.ConfigurePickerResult, error)
 	ShowWelcome bool // show welcome message (when triggered from project command)
+	// Color colors the diff shown before rewriting an existing config.
+	Color bool
+	// Page shows text in a pager and reports whether it did; nil or false
+	// prints it to Stdout instead.
+	Page func(text string) bool
 }
 
 func defaultConfigureDeps() *configureDeps {
@@ -59,7 +69,31 @@ func defaultConfigureDeps() *configureDeps {
 				}
 				return result
 			}
-			return ui.RunConfigurePicker(expandFn)
+			worktreeCount := func(path string) int {
+				return configureWorktreeCount(project.DefaultDeps(), path)
+			}
+			opts := []ui.ConfigurePickerOption{ui.WithWorktreeCount(worktreeCount)}
+			cfgPath := cfgFile
+			if cfgPath == "" {
+				cfgPath = config.DefaultConfigPath()
+			}
+			if cfg, err := config.Load(cfgPath); err == nil {
+				opts = append(opts, ui.WithCollisionCount(configureCollisionCounter(cfg)), ui.WithConfigureTheme(pickerTheme(cfg)))
+			}
+			return ui.RunConfigurePicker(expandFn, opts...)
+		},
+		Color: taskStdoutInteractive() && os.Getenv("NO_COLOR") == "",
+		Page: func(text string) bool {
+			if !taskStdoutInteractive() {
+				return false
+			}
+			pw, done, err := taskOpenPager()
+			if err != nil {
+				return false
+			}
+			io.WriteString(pw, text)
+			_ = done() // quitting the pager early is fine
+			return true
 		},
 	}
 }
@@ -74,8 +108,15 @@ func runConfigureWith(d *configureDeps) error {
 		cfgPath = config.DefaultConfigPath()
 	}
 
+	// Snapshot the file as loaded: the picker can run for minutes, and another
+	// configure run or a manual edit may rewrite the file meanwhile.
+	loaded, _ := d.FS.ReadFile(cfgPath)
 	cfg, err := config.Load(cfgPath)
 	if err != nil {
+		// A config that exists but doesn't parse must not be written over.
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
 		cfg = &config.Config{}
 	}
 
@@ -91,12 +132,17 @@ func runConfigureWith(d *configureDeps) error {
 		}
 	}
 
-	if len(cfg.Projects) > 0 {
+	includes := configureIncludesOf(d.FS, cfgPath, mainConfigIncludes(loaded))
+	if len(cfg.Projects) > 0 || len(includes) > 0 {
 		fmt.Fprintf(d.Stdout, "Config found at %s\n", cfgPath)
 		fmt.Fprintf(d.Stdout, "Current patterns:\n")
 		for _, p := range cfg.Projects {
 			fmt.Fprintf(d.Stdout, "  - %s\n", p.Path)
 		}
+		if len(includes) > 0 {
+			fmt.Fprintln(d.Stdout, "Include files:")
+			printConfigureIncludes(d.Stdout, includes)
+		}
 		fmt.Fprintln(d.Stdout)
 
 		if !confirm(scanner, d.Stdout, "Add another directory?") {
@@ -104,6 +150,7 @@ func runConfigureWith(d *configureDeps) error {
 		}
 	}
 
+	var added []config.ProjectEntry
 	for {
 		result, err := d.PickDir()
 		if err != nil {
@@ -130,6 +177,7 @@ func runConfigureWith(d *configureDeps) error {
 		}
 
 		cfg.Projects = append(cfg.Projects, entry)
+		added = append(added, entry)
 
 		if !confirm(scanner, d.Stdout, "Add another directory?") {
 			break
@@ -140,25 +188,144 @@ func runConfigureWith(d *configureDeps) error {
 		return nil
 	}
 
+	// With include files, the loaded config carries their patterns too; edit
+	// just the [[projects]] of the file the user picks so none get copied over.
+	if len(includes) > 0 {
+		if len(added) == 0 {
+			return nil
+		}
+		return writeConfigureEntries(d, scanner, pickConfigureTarget(scanner, d.Stdout, cfgPath, includes), added)
+	}
+
+	current, _ := d.FS.ReadFile(cfgPath)
+	if !bytes.Equal(current, loaded) {
+		fmt.Fprintf(d.Stdout, "\nWarning: %s changed while configure was running; adding the new patterns to the current file instead of overwriting it.\n", cfgPath)
+		if cfg, err = mergeConfigureEntries(d.FS, cfgPath, added); err != nil {
+			return err
+		}
+	}
+
+	data, err := encodeConfig(cfg)
+	if err != nil {
+		return err
+	}
+	// Rewriting drops comments and hand formatting, so show what an existing
+	// file will turn into and let the user back out.
+	if !confirmConfigDiff(d, scanner, cfgPath, current, data) {
+		return nil
+	}
+
+	if err := writeConfigData(d.FS, cfgPath, data); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(d.Stdout, "\nConfig written to %s\n", cfgPath)
+
+	return nil
+}
+
+// configureWorktreeCount returns how many worktrees the picker would expand
+// the matched path into, or 0 when it stays a single project — the same
+// file-based check expandProjectsWith makes.
+func configureWorktreeCount(d *project.Deps, path string) int {
+	if !project.HasWorktreesWith(d, path) {
+		return 0
+	}
+	worktrees, err := project.ListWorktreesForPathWith(d, path)
+	if err != nil {
+		return 0
+	}
+	return len(worktrees)
+}
+
+// configureCollisionCounter returns the configure picker's collision count:
+// how many of the previewed paths DisambiguateNames would rename at the given
+// depth, among themselves and the projects cfg already lists. The existing
+// projects are expanded once, on first use.
+func configureCollisionCounter(cfg *config.Config) func(paths []string, depth int) int {
+	var existing []config.ExpandedPath
+	expanded := false
+	return func(paths []string, depth int) int {
+		if !expanded {
+			existing, _ = cfg.ExpandProjects()
+			expanded = true
+		}
+		previewed := make(map[string]bool, len(paths))
+		for _, p := range paths {
+			previewed[p] = true
+		}
+		var items []project.ExpandedProject
+		for _, e := range existing {
+			// Re-adding a pattern shouldn't count its own matches as collisions.
+			if !previewed[e.Path] {
+				items = append(items, project.ExpandedProject{Name: ui.LastNSegments(e.Path, e.DisplayDepth), Path: e.Path})
+			}
+		}
+		start := len(items)
+		for _, p := range paths {
+			items = append(items, project.ExpandedProject{Name: ui.LastNSegments(p, depth), Path: p})
+		}
+		project.DisambiguateNames(items, cfg.GetDisambiguationStrategy())
+		n := 0
+		for i, p := range paths {
+			if items[start+i].Name != ui.LastNSegments(p, depth) {
+				n++
+			}
+		}
+		return n
+	}
+}
+
+// encodeConfig serializes cfg as TOML.
+func encodeConfig(cfg *config.Config) ([]byte, error) {
 	data, err := toml.Marshal(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to encode config: %w", err)
+		return nil, fmt.Errorf("failed to encode config: %w", err)
 	}
+	return data, nil
+}
 
+// writeConfigData writes encoded config data to cfgPath, creating its
+// directory as needed.
+func writeConfigData(fs deps.FileSystem, cfgPath string, data []byte) error {
 	dir := filepath.Dir(cfgPath)
-	if err := d.FS.MkdirAll(dir, 0o755); err != nil {
+	if err := fs.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	if err := d.FS.WriteFile(cfgPath, data, 0o644); err != nil {
+	if err := fs.WriteFile(cfgPath, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
-
-	fmt.Fprintf(d.Stdout, "\nConfig written to %s\n", cfgPath)
-
 	return nil
 }
 
+// mergeConfigureEntries rereads the config file at cfgPath through fs and
+// appends the entries this configure run added, skipping patterns the file
+// already lists, so a concurrent edit survives. Only the file itself is
+// decoded, keeping include files' patterns out of it. A file that no longer
+// parses is an error: writing over it would lose everything in it.
+func mergeConfigureEntries(fs deps.FileSystem, cfgPath string, added []config.ProjectEntry) (*config.Config, error) {
+	data, err := fs.ReadFile(cfgPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", cfgPath, err)
+	}
+	var cfg config.Config
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", cfgPath, err)
+	}
+	existing := make(map[string]bool, len(cfg.Projects))
+	for _, p := range cfg.Projects {
+		existing[p.Path] = true
+	}
+	for _, entry := range added {
+		if !existing[entry.Path] {
+			existing[entry.Path] = true
+			cfg.Projects = append(cfg.Projects, entry)
+		}
+	}
+	return &cfg, nil
+}
+
 func confirm(scanner *bufio.Scanner, w io.Writer, prompt string) bool {
 	fmt.Fprintf(w, "%s [y/N]: ", prompt)
 	if !scanner.Scan() {
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/spf13/cobra"
+)
+
+var configureIncludesCmd = &cobra.Command{
+	Use:   "includes",
+	Short: "List and manage the config's include files",
+	Long: `Show the files the config's includes entry lists — each one's resolved
+path, whether it exists, and how many projects it contributes — and add,
+remove, or create include files interactively.
+
+Changes to the includes list are shown as a diff and written only once you
+confirm; new include files are created after that.
+
+Example:
+  pop configure includes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigureIncludesWith(defaultConfigureDeps())
+	},
+}
+
+func init() {
+	configureCmd.AddCommand(configureIncludesCmd)
+}
+
+// configureInclude is one includes entry of the main config as configure
+// shows it.
+type configureInclude struct {
+	Entry string // as written in the config
+	Path  string // resolved file
+	// Exists is false when the file is missing; Err is set when it exists but
+	// does not parse.
+	Exists   bool
+	Err      error
+	Projects int
+}
+
+// configureIncludesOf resolves and inspects each includes entry of the config
+// at cfgPath.
+func configureIncludesOf(fs deps.FileSystem, cfgPath string, includes []string) []configureInclude {
+	result := make([]configureInclude, 0, len(includes))
+	for _, entry := range includes {
+		inc := configureInclude{Entry: entry, Path: config.ResolveIncludePath(cfgPath, entry)}
+		data, err := fs.ReadFile(inc.Path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				inc.Exists, inc.Err = true, err
+			}
+			result = append(result, inc)
+			continue
+		}
+		inc.Exists = true
+		var included config.Config
+		if _, err := toml.Decode(string(data), &included); err != nil {
+			inc.Err = err
+		} else if len(included.Projects) > 0 {
+			if paths, err := (&config.Config{Projects: included.Projects}).ExpandProjects(); err == nil {
+				inc.Projects = len(paths)
+			}
+		}
+		result = append(result, inc)
+	}
+	return result
+}
+
+func printConfigureIncludes(w io.Writer, includes []configureInclude) {
+	for i, inc := range includes {
+		status := fmt.Sprintf("%d projects", inc.Projects)
+		switch {
+		case !inc.Exists:
+			status = "missing"
+		case inc.Err != nil:
+			status = "unreadable"
+		}
+		fmt.Fprintf(w, "  %d) %s — %s\n", i+1, inc.Path, status)
+	}
+}
+
+// mainConfigIncludes returns the includes the main config file lists itself,
+// leaving out those its include files list in turn.
+func mainConfigIncludes(data []byte) []string {
+	var own struct {
+		Includes []string `toml:"includes"`
+	}
+	if _, err := toml.Decode(string(data), &own); err != nil {
+		return nil
+	}
+	return own.Includes
+}
+
+// confirmConfigDiff shows how data changes the existing file at path and asks
+// before rewriting it. A new file needs no confirmation.
+func confirmConfigDiff(d *configureDeps, scanner *bufio.Scanner, path string, current, data []byte) bool {
+	diff := unifiedDiff(path, current, data)
+	if len(current) == 0 || diff == "" {
+		return true
+	}
+	if d.Color {
+		diff = colorizeDiff(diff)
+	}
+	fmt.Fprintln(d.Stdout)
+	if d.Page == nil || !d.Page(diff) {
+		fmt.Fprint(d.Stdout, diff)
+	}
+	if !confirm(scanner, d.Stdout, "Write these changes?") {
+		fmt.Fprintf(d.Stdout, "%s left unchanged.\n", path)
+		return false
+	}
+	return true
+}
+
+// pickConfigureTarget asks which file the new patterns go to: the main config
+// (the default) or one of its include files.
+func pickConfigureTarget(scanner *bufio.Scanner, w io.Writer, cfgPath string, includes []configureInclude) string {
+	fmt.Fprintln(w, "\nWrite the new patterns to:")
+	fmt.Fprintf(w, "  0) %s (main config)\n", cfgPath)
+	printConfigureIncludes(w, includes)
+	for {
+		fmt.Fprintf(w, "Choice [0]: ")
+		if !scanner.Scan() {
+			return cfgPath
+		}
+		answer := strings.TrimSpace(scanner.Text())
+		if answer == "" || answer == "0" {
+			return cfgPath
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(includes) {
+			return includes[n-1].Path
+		}
+		fmt.Fprintf(w, "Enter a number from 0 to %d.\n", len(includes))
+	}
+}
+
+// writeConfigureEntries adds entries to the config file at path — the main
+// config or an include — appending them to its projects and leaving the rest
+// of the file as written.
+func writeConfigureEntries(d *configureDeps, scanner *bufio.Scanner, path string, entries []config.ProjectEntry) error {
+	current, err := d.FS.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	data, err := appendProjectsText(path, current, entries)
+	if err != nil {
+		return err
+	}
+	if !confirmConfigDiff(d, scanner, path, current, data) {
+		return nil
+	}
+	if err := writeConfigData(d.FS, path, data); err != nil {
+		return err
+	}
+	fmt.Fprintf(d.Stdout, "\nConfig written to %s\n", path)
+	return nil
+}
+
+// includeFileHeader starts an include file pop configure includes creates.
+const includeFileHeader = "# Included by %s. Add [[projects]] entries here.\n"
+
+func runConfigureIncludesWith(d *configureDeps) error {
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+
+	loaded, err := d.FS.ReadFile(cfgPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", cfgPath, err)
+	}
+	original := mainConfigIncludes(loaded)
+	includes := slices.Clone(original)
+	var created []string
+
+	scanner := bufio.NewScanner(d.Stdin)
+	ask := func(prompt string) string {
+		fmt.Fprint(d.Stdout, prompt)
+		if !scanner.Scan() {
+			return ""
+		}
+		return strings.TrimSpace(scanner.Text())
+	}
+
+	fmt.Fprintf(d.Stdout, "Config at %s\n", cfgPath)
+loop:
+	for {
+		if len(includes) == 0 {
+			fmt.Fprintln(d.Stdout, "No include files.")
+		} else {
+			fmt.Fprintln(d.Stdout, "Include files:")
+			printConfigureIncludes(d.Stdout, configureIncludesOf(d.FS, cfgPath, includes))
+		}
+		fmt.Fprint(d.Stdout, "[a]dd existing file, [n]ew file, [r]emove, [q]uit: ")
+		if !scanner.Scan() {
+			break
+		}
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "a":
+			entry := ask("Include path: ")
+			if entry == "" {
+				continue
+			}
+			if slices.Contains(includes, entry) {
+				fmt.Fprintf(d.Stdout, "%s is already included.\n", entry)
+				continue
+			}
+			if _, err := d.FS.ReadFile(config.ResolveIncludePath(cfgPath, entry)); errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(d.Stdout, "Note: %s does not exist yet; pop skips it until it does.\n", entry)
+			}
+			includes = append(includes, entry)
+		case "n":
+			entry := ask("Path for the new include file: ")
+			if entry == "" {
+				continue
+			}
+			if slices.Contains(includes, entry) {
+				fmt.Fprintf(d.Stdout, "%s is already included.\n", entry)
+				continue
+			}
+			if _, err := d.FS.ReadFile(config.ResolveIncludePath(cfgPath, entry)); err == nil {
+				fmt.Fprintf(d.Stdout, "%s already exists; including it as is.\n", entry)
+			} else {
+				created = append(created, entry)
+			}
+			includes = append(includes, entry)
+		case "r":
+			if len(includes) == 0 {
+				continue
+			}
+			n, err := strconv.Atoi(ask(fmt.Sprintf("Remove which include? [1-%d]: ", len(includes))))
+			if err != nil || n < 1 || n > len(includes) {
+				fmt.Fprintln(d.Stdout, "Nothing removed.")
+				continue
+			}
+			entry := includes[n-1]
+			includes = slices.Delete(includes, n-1, n)
+			created = slices.DeleteFunc(created, func(c string) bool { return c == entry })
+		case "q", "":
+			break loop
+		}
+		fmt.Fprintln(d.Stdout)
+	}
+
+	if slices.Equal(includes, original) {
+		return nil
+	}
+	data, err := setIncludesText(cfgPath, loaded, includes)
+	if err != nil {
+		return err
+	}
+	if !confirmConfigDiff(d, scanner, cfgPath, loaded, data) {
+		return nil
+	}
+	if err := writeConfigData(d.FS, cfgPath, data); err != nil {
+		return err
+	}
+	fmt.Fprintf(d.Stdout, "\nConfig written to %s\n", cfgPath)
+
+	for _, entry := range created {
+		path := config.ResolveIncludePath(cfgPath, entry)
+		if err := writeConfigData(d.FS, path, []byte(fmt.Sprintf(includeFileHeader, cfgPath))); err != nil {
+			return err
+		}
+		fmt.Fprintf(d.Stdout, "Created %s\n", path)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/glebglazov/pop/config"
+)
+
+// writeIncludeFixture writes a main config including work.toml (with one
+// project) and gone.toml (missing), and points cfgFile at it.
+func writeIncludeFixture(t *testing.T) (cfgPath, workPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfgPath = filepath.Join(dir, "config.toml")
+	workPath = filepath.Join(dir, "work.toml")
+	if err := os.WriteFile(cfgPath, []byte("includes = [\"work.toml\", \"gone.toml\"]\n\n[[projects]]\npath = \"/main/*\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(workPath, []byte("[[projects]]\npath = \""+dir+"\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	oldCfgFile := cfgFile
+	cfgFile = cfgPath
+	t.Cleanup(func() { cfgFile = oldCfgFile })
+	return cfgPath, workPath
+}
+
+func readProjects(t *testing.T, path string) []string {
+	t.Helper()
+	var cfg config.Config
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	var paths []string
+	for _, p := range cfg.Projects {
+		paths = append(paths, p.Path)
+	}
+	return paths
+}
+
+func TestRunConfigure_ListsIncludesAndWritesChosenInclude(t *testing.T) {
+	cfgPath, workPath := writeIncludeFixture(t)
+	mainBefore, _ := os.ReadFile(cfgPath)
+
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	var output bytes.Buffer
+	d := &configureDeps{
+		FS: fs,
+		// add, stop adding, target include 1, confirm the diff
+		Stdin:   strings.NewReader("y\nn\n1\ny\n"),
+		Stdout:  &output,
+		PickDir: mockPickDir("/new/projects/*", 2),
+	}
+	if err := runConfigureWith(d); err != nil {
+		t.Fatalf("runConfigureWith() error = %v", err)
+	}
+
+	out := output.String()
+	for _, want := range []string{"Include files:", workPath + " — 1 projects", "gone.toml — missing", "Write the new patterns to:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if got := readProjects(t, workPath); len(got) != 2 || got[1] != "/new/projects/*" {
+		t.Errorf("include projects = %v, want the new pattern appended", got)
+	}
+	if mainAfter, _ := os.ReadFile(cfgPath); !bytes.Equal(mainAfter, mainBefore) {
+		t.Errorf("main config rewritten:\n%s", mainAfter)
+	}
+}
+
+func TestRunConfigure_InlineIncludeProjectsKept(t *testing.T) {
+	_, workPath := writeIncludeFixture(t)
+	inline := "# work repos\nprojects = [ {path=\"~/a\"}, {path=\"~/b\"} ]\n"
+	if err := os.WriteFile(workPath, []byte(inline), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	d := &configureDeps{
+		FS:      fs,
+		Stdin:   strings.NewReader("y\nn\n1\ny\n"),
+		Stdout:  &bytes.Buffer{},
+		PickDir: mockPickDir("~/c", 1),
+	}
+	if err := runConfigureWith(d); err != nil {
+		t.Fatalf("runConfigureWith() error = %v", err)
+	}
+
+	want := "# work repos\nprojects = [ {path=\"~/a\"}, {path=\"~/b\"}, { path = \"~/c\" } ]\n"
+	if got := string(mustRead(t, workPath)); got != want {
+		t.Errorf("include file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRunConfigure_MainTargetKeepsIncludedPatternsOut(t *testing.T) {
+	cfgPath, _ := writeIncludeFixture(t)
+
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	d := &configureDeps{
+		FS:      fs,
+		Stdin:   strings.NewReader("y\nn\n\ny\n"),
+		Stdout:  &bytes.Buffer{},
+		PickDir: mockPickDir("/new/projects/*", 1),
+	}
+	if err := runConfigureWith(d); err != nil {
+		t.Fatalf("runConfigureWith() error = %v", err)
+	}
+
+	got := readProjects(t, cfgPath)
+	if len(got) != 2 || got[0] != "/main/*" || got[1] != "/new/projects/*" {
+		t.Errorf("main projects = %v, want [/main/* /new/projects/*]", got)
+	}
+	if includes := mainConfigIncludes(mustRead(t, cfgPath)); len(includes) != 2 {
+		t.Errorf("includes = %v, want both kept", includes)
+	}
+}
+
+func TestRunConfigureIncludes(t *testing.T) {
+	cfgPath, _ := writeIncludeFixture(t)
+	dir := filepath.Dir(cfgPath)
+
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	var output bytes.Buffer
+	d := &configureDeps{
+		FS: fs,
+		// remove gone.toml, create personal.toml, quit, confirm
+		Stdin:  strings.NewReader("r\n2\nn\nlocal/personal.toml\nq\ny\n"),
+		Stdout: &output,
+	}
+	if err := runConfigureIncludesWith(d); err != nil {
+		t.Fatalf("runConfigureIncludesWith() error = %v", err)
+	}
+
+	data := mustRead(t, cfgPath)
+	if got := mainConfigIncludes(data); len(got) != 2 || got[0] != "work.toml" || got[1] != "local/personal.toml" {
+		t.Errorf("includes = %v, want [work.toml local/personal.toml]", got)
+	}
+	if got := readProjects(t, cfgPath); len(got) != 1 || got[0] != "/main/*" {
+		t.Errorf("main projects = %v, want only its own", got)
+	}
+	created := filepath.Join(dir, "local", "personal.toml")
+	if !strings.HasPrefix(string(mustRead(t, created)), "# Included by "+cfgPath) {
+		t.Errorf("new include file missing its header")
+	}
+	if !strings.Contains(output.String(), "Created "+created) {
+		t.Errorf("expected a created note, got:\n%s", output.String())
+	}
+}
+
+func TestRunConfigureIncludes_DeclineLeavesFilesAlone(t *testing.T) {
+	cfgPath, _ := writeIncludeFixture(t)
+	before := mustRead(t, cfgPath)
+
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	d := &configureDeps{
+		FS:     fs,
+		Stdin:  strings.NewReader("n\nnew.toml\nq\nn\n"),
+		Stdout: &bytes.Buffer{},
+	}
+	if err := runConfigureIncludesWith(d); err != nil {
+		t.Fatalf("runConfigureIncludesWith() error = %v", err)
+	}
+	if after := mustRead(t, cfgPath); !bytes.Equal(after, before) {
+		t.Errorf("config rewritten after declining:\n%s", after)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(cfgPath), "new.toml")); !os.IsNotExist(err) {
+		t.Errorf("new include created after declining: %v", err)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
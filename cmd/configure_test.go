@@ -11,6 +11,7 @@ import (
 	"github.com/BurntSushi/toml"
 	"github.com/glebglazov/pop/config"
 	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
 	"github.com/glebglazov/pop/ui"
 )
 
@@ -151,6 +152,115 @@ func TestRunConfigure_ExistingConfig(t *testing.T) {
 	}
 }
 
+func TestRunConfigure_ConcurrentEditIsMerged(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.toml")
+
+	existingCfg := config.Config{Projects: []config.ProjectEntry{{Path: "~/existing/pattern"}}}
+	data, _ := toml.Marshal(existingCfg)
+	if err := os.WriteFile(cfgPath, data, 0o644); err != nil {
+		t.Fatalf("failed to write existing config: %v", err)
+	}
+
+	oldCfgFile := cfgFile
+	cfgFile = cfgPath
+	defer func() { cfgFile = oldCfgFile }()
+
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	var output bytes.Buffer
+	d := &configureDeps{
+		FS:     fs,
+		Stdin:  strings.NewReader("y\nn\ny\n"),
+		Stdout: &output,
+		// Another configure run saves while this one's picker is open, adding
+		// a pattern of its own plus the one this run is about to add.
+		PickDir: func() (ui.ConfigurePickerResult, error) {
+			concurrent := config.Config{Projects: []config.ProjectEntry{
+				{Path: "~/existing/pattern"},
+				{Path: "~/other/*"},
+				{Path: "/new/projects/*"},
+			}}
+			data, _ := toml.Marshal(concurrent)
+			if err := os.WriteFile(cfgPath, data, 0o644); err != nil {
+				t.Fatalf("concurrent write: %v", err)
+			}
+			return ui.ConfigurePickerResult{Path: "/new/projects/*", DisplayDepth: 1}, nil
+		},
+	}
+
+	if err := runConfigureWith(d); err != nil {
+		t.Fatalf("runConfigureWith() error = %v", err)
+	}
+	if !strings.Contains(output.String(), "changed while configure was running") {
+		t.Errorf("expected a concurrent-edit warning, got: %s", output.String())
+	}
+
+	written, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	var cfg config.Config
+	if err := toml.Unmarshal(written, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	var got []string
+	for _, p := range cfg.Projects {
+		got = append(got, p.Path)
+	}
+	want := []string{"~/existing/pattern", "~/other/*", "/new/projects/*"}
+	if !equalStrings(got, want) {
+		t.Errorf("projects = %v, want %v (concurrent entry kept, no duplicate)", got, want)
+	}
+}
+
+func TestRunConfigure_UnparsableConfigIsLeftAlone(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.toml")
+	broken := "[[projects]]\npath = \"~/a\"\nbroken =\n"
+	if err := os.WriteFile(cfgPath, []byte(broken), 0o644); err != nil {
+		t.Fatalf("failed to write existing config: %v", err)
+	}
+
+	oldCfgFile := cfgFile
+	cfgFile = cfgPath
+	defer func() { cfgFile = oldCfgFile }()
+
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	d := &configureDeps{
+		FS:      fs,
+		Stdin:   strings.NewReader("y\nn\ny\n"),
+		Stdout:  &bytes.Buffer{},
+		PickDir: mockPickDir("/new/projects/*", 1),
+	}
+	if err := runConfigureWith(d); err == nil {
+		t.Fatal("runConfigureWith() error = nil, want the parse error")
+	}
+	written, _ := os.ReadFile(cfgPath)
+	if string(written) != broken {
+		t.Errorf("config rewritten to:\n%s", written)
+	}
+}
+
+func TestMergeConfigureEntries(t *testing.T) {
+	read := func(data string) *deps.MockFileSystem {
+		return &deps.MockFileSystem{ReadFileFunc: func(string) ([]byte, error) { return []byte(data), nil }}
+	}
+	added := []config.ProjectEntry{{Path: "~/a"}, {Path: "~/b"}}
+
+	cfg, err := mergeConfigureEntries(read("[[projects]]\npath = \"~/a\"\n"), "/cfg.toml", added)
+	if err != nil {
+		t.Fatalf("mergeConfigureEntries() error = %v", err)
+	}
+	if len(cfg.Projects) != 2 || cfg.Projects[1].Path != "~/b" {
+		t.Errorf("projects = %+v, want ~/a then ~/b", cfg.Projects)
+	}
+
+	if _, err := mergeConfigureEntries(read("projects = [\n"), "/cfg.toml", added); err == nil {
+		t.Error("mergeConfigureEntries() of an unparsable file error = nil, want an error")
+	}
+}
+
 func TestRunConfigure_ExistingConfigDecline(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfgPath := filepath.Join(tmpDir, "config.toml")
@@ -187,6 +297,100 @@ func TestRunConfigure_ExistingConfigDecline(t *testing.T) {
 	}
 }
 
+func TestRunConfigure_ShowsDiffBeforeRewriting(t *testing.T) {
+	tests := []struct {
+		name      string
+		answer    string
+		wantWrite bool
+	}{
+		{name: "declined", answer: "n", wantWrite: false},
+		{name: "accepted", answer: "y", wantWrite: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config.toml")
+			handEdited := "# my projects\n[[projects]]\npath = \"~/existing/pattern\"\n"
+			if err := os.WriteFile(cfgPath, []byte(handEdited), 0o644); err != nil {
+				t.Fatalf("failed to write existing config: %v", err)
+			}
+
+			oldCfgFile := cfgFile
+			cfgFile = cfgPath
+			defer func() { cfgFile = oldCfgFile }()
+
+			fs := realFSDeps()
+			fs.ReadFileFunc = os.ReadFile
+			var output bytes.Buffer
+			d := &configureDeps{
+				FS:      fs,
+				Stdin:   strings.NewReader("y\nn\n" + tt.answer + "\n"),
+				Stdout:  &output,
+				PickDir: mockPickDir("/new/projects/*", 1),
+			}
+
+			if err := runConfigureWith(d); err != nil {
+				t.Fatalf("runConfigureWith() error = %v", err)
+			}
+
+			out := output.String()
+			for _, want := range []string{"--- " + cfgPath, "-# my projects", "+  path = \"/new/projects/*\"", "Write these changes?"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q, got: %s", want, out)
+				}
+			}
+
+			written, err := os.ReadFile(cfgPath)
+			if err != nil {
+				t.Fatalf("failed to read config: %v", err)
+			}
+			if wrote := string(written) != handEdited; wrote != tt.wantWrite {
+				t.Errorf("config rewritten = %v, want %v; file:\n%s", wrote, tt.wantWrite, written)
+			}
+			if !tt.wantWrite && !strings.Contains(out, "left unchanged") {
+				t.Errorf("expected a left-unchanged note, got: %s", out)
+			}
+		})
+	}
+}
+
+func TestRunConfigure_DiffGoesToPager(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(cfgPath, []byte("[[projects]]\npath = \"~/a\"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write existing config: %v", err)
+	}
+
+	oldCfgFile := cfgFile
+	cfgFile = cfgPath
+	defer func() { cfgFile = oldCfgFile }()
+
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	var output bytes.Buffer
+	var paged string
+	d := &configureDeps{
+		FS:      fs,
+		Stdin:   strings.NewReader("y\nn\nn\n"),
+		Stdout:  &output,
+		PickDir: mockPickDir("/new/projects/*", 1),
+		Color:   true,
+		Page: func(text string) bool {
+			paged = text
+			return true
+		},
+	}
+
+	if err := runConfigureWith(d); err != nil {
+		t.Fatalf("runConfigureWith() error = %v", err)
+	}
+	if !strings.Contains(paged, doctorANSIGreen+"+") {
+		t.Errorf("pager got %q, want the colored diff", paged)
+	}
+	if strings.Contains(output.String(), "--- "+cfgPath) {
+		t.Errorf("diff printed to stdout as well as paged: %s", output.String())
+	}
+}
+
 func TestRunConfigure_MultiplePatterns(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfgPath := filepath.Join(tmpDir, "pop", "config.toml")
@@ -383,3 +587,52 @@ func TestConfirm(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureWorktreeCount(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(path string) {
+		if err := os.MkdirAll(filepath.Join(root, path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mkdir("repo/.bare")
+	mkdir("plain")
+	for _, wt := range []string{"main", "feature"} {
+		mkdir(filepath.Join("repo", wt))
+		gitFile := filepath.Join(root, "repo", wt, ".git")
+		if err := os.WriteFile(gitFile, []byte("gitdir: ../.bare/worktrees/"+wt+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := &project.Deps{FS: deps.NewRealFileSystem(), Git: &deps.MockGit{}}
+	if got := configureWorktreeCount(d, filepath.Join(root, "repo")); got != 2 {
+		t.Errorf("configureWorktreeCount(repo) = %d, want 2", got)
+	}
+	if got := configureWorktreeCount(d, filepath.Join(root, "plain")); got != 0 {
+		t.Errorf("configureWorktreeCount(plain) = %d, want 0", got)
+	}
+}
+
+func TestConfigureCollisionCounter(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"work/api", "other/api", "other/web"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(root, "work/api")}}}
+	count := configureCollisionCounter(cfg)
+
+	preview := []string{filepath.Join(root, "other/api"), filepath.Join(root, "other/web")}
+	if got := count(preview, 1); got != 1 {
+		t.Errorf("count at depth 1 = %d, want 1 (other/api collides with the configured api)", got)
+	}
+	if got := count(preview, 2); got != 0 {
+		t.Errorf("count at depth 2 = %d, want 0", got)
+	}
+	// Previewing a configured project again is not a collision with itself.
+	if got := count([]string{filepath.Join(root, "work/api")}, 1); got != 0 {
+		t.Errorf("count for the configured path = %d, want 0", got)
+	}
+}
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/project"
+	"github.com/spf13/cobra"
+)
+
+// projectDaemonDebounce is how long the daemon waits after a change on disk
+// for the next one before rescanning, so a checkout or a clone that touches
+// many entries costs one rescan.
+const projectDaemonDebounce = 250 * time.Millisecond
+
+// projectDaemonPollInterval is how often the daemon rescans when it cannot
+// watch the filesystem (no inotify instances left, say). Unchanged glob bases
+// are answered from the glob cache by their mtimes, so a rescan of a quiet
+// tree costs little more than stats.
+const projectDaemonPollInterval = 5 * time.Second
+
+// projectDaemonTimeout bounds how long the picker waits on the daemon before
+// scanning itself.
+const projectDaemonTimeout = 2 * time.Second
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep the project list warm for the picker (foreground)",
+	Long: `Run in the foreground, keeping the expanded project list in memory. The
+daemon watches the config file and its includes, the directories the project
+globs list, and the bare repos holding worktrees, and rescans when one of them
+changes: globs are re-expanded (keeping the glob cache current) and bare repos
+re-listed for their worktrees. The result, with any config problems the scan
+found, is served over a unix socket in the pop cache directory, and pop project
+/ pop select read it from there instead of scanning, so the picker opens at
+once however large the tree.
+
+Without a running daemon, or when it was started for another config file, pop
+scans directly as usual. Where the filesystem cannot be watched the daemon
+falls back to rescanning every few seconds. Stop the daemon with Ctrl-C or pop
+daemon stop.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop a running pop daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resp, err := sendProjectDaemonRequest(projectDaemonSocketPath(), projectDaemonRequest{Cmd: "shutdown"})
+		if err != nil {
+			return fmt.Errorf("daemon not running: %w", err)
+		}
+		if !resp.OK {
+			return fmt.Errorf("daemon refused shutdown: %s", resp.Error)
+		}
+		fmt.Println("Daemon stopped")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+}
+
+// projectDaemonSocketPath returns the unix socket the daemon listens on, next
+// to the glob cache.
+func projectDaemonSocketPath() string {
+	return filepath.Join(filepath.Dir(config.DefaultCachePath()), "daemon.sock")
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	socketPath := projectDaemonSocketPath()
+
+	ln, err := listenProjectDaemon(socketPath)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	pd := newProjectDaemon(cfgPath, func() (projectScan, error) {
+		return scanProjectDaemon(cfgPath)
+	})
+
+	var watcher projectWatcher
+	fsWatcher, err := newFSProjectWatcher(projectDaemonDebounce)
+	if err != nil {
+		debug.Error("daemon: watch: %v", err)
+		watcher = newPollProjectWatcher(projectDaemonPollInterval)
+		fmt.Printf("Daemon serving %s on %s (cannot watch files, rescanning every %s)\n", cfgPath, socketPath, projectDaemonPollInterval)
+	} else {
+		watcher = fsWatcher
+		fmt.Printf("Daemon serving %s on %s\n", cfgPath, socketPath)
+	}
+	defer watcher.Close()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	return serveProjectDaemon(ln, pd, watcher, sigCh)
+}
+
+// scanProjectDaemon loads the config at cfgPath and expands its projects,
+// returning what changes should trigger the next scan even when this one
+// fails, so fixing a broken config is picked up.
+func scanProjectDaemon(cfgPath string) (projectScan, error) {
+	scan := projectScan{Watch: projectWatch{Files: []string{cfgPath}}}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return scan, fmt.Errorf("failed to load config: %w", err)
+	}
+	for _, include := range cfg.Includes {
+		scan.Watch.Files = append(scan.Watch.Files, config.ResolveIncludePath(cfgPath, include))
+	}
+	if _, err := cfg.ProjectEntries(); err != nil {
+		return scan, fmt.Errorf("invalid projects configuration: %w", err)
+	}
+	scan.Watch.Dirs = cfg.ProjectWatchDirs()
+
+	// The client loads the config itself and has the load-time findings;
+	// only those expansion adds are carried over.
+	loaded := len(cfg.Findings)
+	scan.Projects, scan.Failed, err = scanProjectsWith(context.Background(), project.DefaultDeps(), cfg, cfgPath, nil)
+	if err != nil {
+		return scan, err
+	}
+	scan.Findings = cfg.Findings[loaded:]
+	for _, ep := range scan.Projects {
+		// New worktrees of a bare repo are created inside it.
+		if ep.RepoRoot != "" && !slices.Contains(scan.Watch.Dirs, ep.RepoRoot) {
+			scan.Watch.Dirs = append(scan.Watch.Dirs, ep.RepoRoot)
+		}
+	}
+	return scan, nil
+}
+
+// listenProjectDaemon binds the daemon socket. A socket file nobody answers
+// on is left over from a daemon that died and is replaced; one that answers
+// means a daemon is already running.
+func listenProjectDaemon(socketPath string) (net.Listener, error) {
+	if _, err := os.Stat(socketPath); err == nil {
+		if conn, err := net.DialTimeout("unix", socketPath, projectDaemonTimeout); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("daemon already running at %s", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	return ln, nil
+}
+
+// projectDaemonRequest is one request to the daemon. Config is the config
+// path the client resolved; the daemon only answers for its own.
+type projectDaemonRequest struct {
+	Cmd    string `json:"cmd"`
+	Config string `json:"config,omitempty"`
+}
+
+// projectDaemonResponse carries the latest scan: the expanded projects before
+// exclusion, disambiguation and history sorting, which stay with the client,
+// and the findings expanding them recorded on the daemon's config.
+type projectDaemonResponse struct {
+	OK       bool                      `json:"ok"`
+	Error    string                    `json:"error,omitempty"`
+	Projects []project.ExpandedProject `json:"projects,omitempty"`
+	Failed   []string                  `json:"failed,omitempty"`
+	Findings []config.Finding          `json:"findings,omitempty"`
+}
+
+// projectScan is the result of one daemon scan.
+type projectScan struct {
+	Projects []project.ExpandedProject
+	Failed   []string
+	// Findings are the config problems expansion ran into, such as an
+	// invalid glob, beyond those loading the config reports.
+	Findings []config.Finding
+	// Watch is what to watch for the change that calls for the next scan.
+	Watch projectWatch
+}
+
+// projectDaemon holds the result of the latest scan of one config.
+type projectDaemon struct {
+	cfgPath string
+	scan    func() (projectScan, error)
+
+	mu      sync.RWMutex
+	scanned bool
+	last    projectScan
+	err     error
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+func newProjectDaemon(cfgPath string, scan func() (projectScan, error)) *projectDaemon {
+	return &projectDaemon{cfgPath: cfgPath, scan: scan, shutdown: make(chan struct{})}
+}
+
+// rescan runs a scan and swaps its result in, returning what to watch until
+// the next. Requests keep reading the previous result while it runs.
+func (pd *projectDaemon) rescan() projectWatch {
+	scan, err := pd.scan()
+	if err != nil {
+		debug.Error("daemon: scan %s: %v", pd.cfgPath, err)
+	}
+	// The daemon never exits to flush the glob cache; persist each scan's.
+	if err := config.FlushGlobCache(); err != nil {
+		debug.Error("daemon: glob cache: %v", err)
+	}
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	pd.scanned = true
+	pd.last, pd.err = scan, err
+	return scan.Watch
+}
+
+func (pd *projectDaemon) handle(req projectDaemonRequest) projectDaemonResponse {
+	switch req.Cmd {
+	case "projects":
+		if req.Config != pd.cfgPath {
+			return projectDaemonResponse{Error: fmt.Sprintf("daemon serves %s", pd.cfgPath)}
+		}
+		pd.mu.RLock()
+		defer pd.mu.RUnlock()
+		if !pd.scanned {
+			return projectDaemonResponse{Error: "first scan not finished"}
+		}
+		if pd.err != nil {
+			return projectDaemonResponse{Error: pd.err.Error()}
+		}
+		return projectDaemonResponse{OK: true, Projects: pd.last.Projects, Failed: pd.last.Failed, Findings: pd.last.Findings}
+	case "shutdown":
+		pd.shutdownOnce.Do(func() { close(pd.shutdown) })
+		return projectDaemonResponse{OK: true}
+	}
+	return projectDaemonResponse{Error: fmt.Sprintf("unknown command: %s", req.Cmd)}
+}
+
+// serveProjectDaemon answers requests on ln and rescans whenever watcher
+// reports a change to what the last scan depends on, until a shutdown request
+// or a signal on sigCh.
+func serveProjectDaemon(ln net.Listener, pd *projectDaemon, watcher projectWatcher, sigCh <-chan os.Signal) error {
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				// Listener closed — normal shutdown path.
+				return
+			}
+			go handleProjectDaemonConn(conn, pd)
+		}
+	}()
+
+	watcher.Watch(pd.rescan())
+	for {
+		select {
+		case <-watcher.Changes():
+			watcher.Watch(pd.rescan())
+		case <-pd.shutdown:
+			return nil
+		case sig := <-sigCh:
+			fmt.Printf("\nReceived %s, shutting down\n", sig)
+			return nil
+		}
+	}
+}
+
+// handleProjectDaemonConn reads one JSON request, answers it, and closes.
+func handleProjectDaemonConn(conn net.Conn, pd *projectDaemon) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(projectDaemonTimeout))
+
+	var req projectDaemonRequest
+	resp := projectDaemonResponse{Error: "invalid request"}
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		debug.Error("daemon: decode request: %v", err)
+	} else {
+		resp = pd.handle(req)
+	}
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		debug.Error("daemon: write response: %v", err)
+	}
+}
+
+// sendProjectDaemonRequest dials the daemon at socketPath, sends req, and
+// returns the response.
+func sendProjectDaemonRequest(socketPath string, req projectDaemonRequest) (projectDaemonResponse, error) {
+	conn, err := net.DialTimeout("unix", socketPath, projectDaemonTimeout)
+	if err != nil {
+		return projectDaemonResponse{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(projectDaemonTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return projectDaemonResponse{}, fmt.Errorf("write request: %w", err)
+	}
+	var resp projectDaemonResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return projectDaemonResponse{}, fmt.Errorf("read response: %w", err)
+	}
+	return resp, nil
+}
+
+// queryProjectDaemon asks the daemon at socketPath for the expanded projects
+// of the config at cfgPath and the findings expanding them recorded. ok is
+// false when there is no daemon, or it cannot answer for that config; the
+// caller then scans itself.
+func queryProjectDaemon(socketPath, cfgPath string) (projects []project.ExpandedProject, failed []string, findings []config.Finding, ok bool) {
+	resp, err := sendProjectDaemonRequest(socketPath, projectDaemonRequest{Cmd: "projects", Config: cfgPath})
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, syscall.ECONNREFUSED) {
+			debug.Log("daemon: query: %v", err)
+		}
+		return nil, nil, nil, false
+	}
+	if !resp.OK {
+		debug.Log("daemon: %s", resp.Error)
+		return nil, nil, nil, false
+	}
+	return resp.Projects, resp.Failed, resp.Findings, true
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/project"
+)
+
+func TestProjectDaemon_ServesLatestScan(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+	ln, err := listenProjectDaemon(socketPath)
+	if err != nil {
+		t.Fatalf("listenProjectDaemon: %v", err)
+	}
+	defer ln.Close()
+
+	finding := config.Finding{Path: "projects[].path", Message: "bad glob"}
+	scans := 0
+	pd := newProjectDaemon("/cfg.toml", func() (projectScan, error) {
+		scans++
+		return projectScan{
+			Projects: []project.ExpandedProject{{Name: "api", Path: "/dev/api", SessionName: "api"}},
+			Failed:   []string{"broken"},
+			Findings: []config.Finding{finding},
+			Watch:    projectWatch{Files: []string{"/cfg.toml"}, Dirs: []string{"/dev"}},
+		}, nil
+	})
+	watcher := &fakeProjectWatcher{changes: make(chan struct{}), watched: make(chan projectWatch, 2)}
+	served := make(chan error, 1)
+	go func() { served <- serveProjectDaemon(ln, pd, watcher, nil) }()
+
+	if w := <-watcher.watched; !equalStrings(w.Dirs, []string{"/dev"}) {
+		t.Errorf("watching %+v, want the scan's directories", w)
+	}
+	var (
+		projects []project.ExpandedProject
+		failed   []string
+		findings []config.Finding
+		ok       bool
+	)
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if projects, failed, findings, ok = queryProjectDaemon(socketPath, "/cfg.toml"); ok {
+			break
+		}
+	}
+	if !ok {
+		t.Fatal("daemon never answered")
+	}
+	if len(projects) != 1 || projects[0].Path != "/dev/api" || !equalStrings(failed, []string{"broken"}) {
+		t.Errorf("query = %+v, %v; want the scanned project and failure", projects, failed)
+	}
+	if len(findings) != 1 || findings[0] != finding {
+		t.Errorf("findings = %+v, want the scan's", findings)
+	}
+
+	watcher.changes <- struct{}{}
+	<-watcher.watched
+	if scans != 2 {
+		t.Errorf("scanned %d times, want a rescan on the change", scans)
+	}
+
+	if _, _, _, ok := queryProjectDaemon(socketPath, "/other.toml"); ok {
+		t.Error("daemon answered for a config it does not serve")
+	}
+
+	if _, err := sendProjectDaemonRequest(socketPath, projectDaemonRequest{Cmd: "shutdown"}); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("serveProjectDaemon: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("daemon did not stop on shutdown")
+	}
+}
+
+func TestProjectDaemon_ScanErrorFallsBack(t *testing.T) {
+	pd := newProjectDaemon("/cfg.toml", func() (projectScan, error) {
+		return projectScan{}, os.ErrPermission
+	})
+	if resp := pd.handle(projectDaemonRequest{Cmd: "projects", Config: "/cfg.toml"}); resp.OK {
+		t.Error("answered before the first scan")
+	}
+	pd.rescan()
+	if resp := pd.handle(projectDaemonRequest{Cmd: "projects", Config: "/cfg.toml"}); resp.OK || resp.Error == "" {
+		t.Errorf("response = %+v, want the scan error", resp)
+	}
+}
+
+// fakeProjectWatcher reports a change on each send to changes and passes
+// what it is told to watch on to watched.
+type fakeProjectWatcher struct {
+	changes chan struct{}
+	watched chan projectWatch
+}
+
+func (w *fakeProjectWatcher) Watch(pw projectWatch)    { w.watched <- pw }
+func (w *fakeProjectWatcher) Changes() <-chan struct{} { return w.changes }
+func (w *fakeProjectWatcher) Close() error             { return nil }
+
+func TestScanProjectDaemon_WatchesConfigAndRoots(t *testing.T) {
+	testProjectDeps(t)
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(t.TempDir(), "config.toml")
+	data := "includes = [\"work.toml\"]\n\n[[projects]]\npath = \"" + root + "/*\"\n\n[[projects]]\npath = \"" + root + "/[\"\n"
+	if err := os.WriteFile(cfgPath, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err := scanProjectDaemon(cfgPath)
+	if err != nil {
+		t.Fatalf("scanProjectDaemon: %v", err)
+	}
+	if len(scan.Projects) != 1 || scan.Projects[0].Path != filepath.Join(root, "api") {
+		t.Errorf("projects = %+v, want %s/api", scan.Projects, root)
+	}
+	wantFiles := []string{cfgPath, filepath.Join(filepath.Dir(cfgPath), "work.toml")}
+	if !equalStrings(scan.Watch.Files, wantFiles) {
+		t.Errorf("watched files = %q, want %q", scan.Watch.Files, wantFiles)
+	}
+	if !slices.Contains(scan.Watch.Dirs, root) {
+		t.Errorf("watched dirs = %q, want the glob base %s", scan.Watch.Dirs, root)
+	}
+	if len(scan.Findings) != 1 || !strings.Contains(scan.Findings[0].Message, "not a valid glob") {
+		t.Errorf("findings = %+v, want the invalid glob", scan.Findings)
+	}
+}
+
+func TestFSProjectWatcher_ReportsNewProject(t *testing.T) {
+	root := t.TempDir()
+	cfgPath := filepath.Join(t.TempDir(), "config.toml")
+	pw, err := newFSProjectWatcher(10 * time.Millisecond)
+	if err != nil {
+		t.Skipf("no filesystem watching here: %v", err)
+	}
+	defer pw.Close()
+	pw.Watch(projectWatch{Files: []string{cfgPath}, Dirs: []string{root}})
+
+	changed := func(what string, change func() error) {
+		t.Helper()
+		if err := change(); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-pw.Changes():
+		case <-time.After(2 * time.Second):
+			t.Errorf("no change reported after %s", what)
+		}
+	}
+	changed("a new project directory", func() error { return os.Mkdir(filepath.Join(root, "api"), 0o755) })
+	changed("a config write", func() error { return os.WriteFile(cfgPath, []byte("# edited\n"), 0o644) })
+
+	// Work inside a project is not a change to the list.
+	if err := os.WriteFile(filepath.Join(root, "api", "main.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-pw.Changes():
+		t.Error("a file written inside a project reported a change")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestListenProjectDaemon_ReplacesStaleSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+	if err := os.WriteFile(socketPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := listenProjectDaemon(socketPath)
+	if err != nil {
+		t.Fatalf("listenProjectDaemon over a stale file: %v", err)
+	}
+	defer ln.Close()
+
+	if _, err := listenProjectDaemon(socketPath); err == nil {
+		t.Error("second daemon bound a socket that is in use")
+	}
+}
+
+func TestCollectProjectsWith_UsesDaemonProjects(t *testing.T) {
+	d := testProjectDeps(t)
+	var gotCfgPath string
+	d.CachedProjects = func(cfgPath string) ([]project.ExpandedProject, []string, []config.Finding, bool) {
+		gotCfgPath = cfgPath
+		findings := []config.Finding{{Path: "projects[].path", Message: "bad glob"}}
+		return []project.ExpandedProject{{Name: "cached", Path: "/dev/cached", SessionName: "cached"}}, nil, findings, true
+	}
+	// The configured path does not exist, so a scan would find nothing.
+	cfg := &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(t.TempDir(), "missing")}}}
+
+	projects, _, err := collectProjectsWith(d, cfg, "/cfg.toml", &history.History{}, nil)
+	if err != nil {
+		t.Fatalf("collectProjectsWith: %v", err)
+	}
+	if gotCfgPath != "/cfg.toml" {
+		t.Errorf("CachedProjects(%q), want the config path", gotCfgPath)
+	}
+	if len(projects) != 1 || projects[0].Path != "/dev/cached" {
+		t.Errorf("projects = %+v, want the daemon's", projects)
+	}
+	if !equalStrings(cfg.Warnings, []string{"bad glob"}) {
+		t.Errorf("warnings = %q, want the daemon's finding", cfg.Warnings)
+	}
+}
+
+func TestCollectProjectsWith_WritesGlobCacheBeforeReturning(t *testing.T) {
+	d := testProjectDeps(t)
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(root, "*")}}}
+
+	if _, _, err := collectProjectsWith(d, cfg, "/cfg.toml", &history.History{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	// pop may not live to flush on exit, so the scan's cache must already be
+	// on disk.
+	if _, err := os.Stat(config.DefaultCachePath()); err != nil {
+		t.Errorf("glob cache not written after the scan: %v", err)
+	}
+}
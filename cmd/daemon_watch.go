@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/glebglazov/pop/debug"
+)
+
+// projectWatch is what a daemon scan depends on.
+type projectWatch struct {
+	// Files are config files; any change to one calls for a rescan.
+	Files []string
+	// Dirs are directories whose entries the scan listed; an entry created,
+	// removed or renamed in one calls for a rescan.
+	Dirs []string
+}
+
+// projectWatcher tells the daemon when to rescan.
+type projectWatcher interface {
+	// Watch replaces what is watched with w.
+	Watch(w projectWatch)
+	// Changes receives once for each burst of changes.
+	Changes() <-chan struct{}
+	Close() error
+}
+
+// fsProjectWatcher watches a projectWatch through fsnotify. Files are watched
+// through their directories, so an editor replacing one by rename is seen.
+type fsProjectWatcher struct {
+	w       *fsnotify.Watcher
+	changes chan struct{}
+
+	mu      sync.Mutex
+	files   map[string]bool
+	dirs    map[string]bool
+	watched map[string]bool // directories added to w
+}
+
+// newFSProjectWatcher starts a watcher that reports a change once debounce
+// passes without another.
+func newFSProjectWatcher(debounce time.Duration) (*fsProjectWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	pw := &fsProjectWatcher{
+		w:       w,
+		changes: make(chan struct{}, 1),
+		watched: make(map[string]bool),
+	}
+	go pw.run(debounce)
+	return pw, nil
+}
+
+func (pw *fsProjectWatcher) Watch(watch projectWatch) {
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	want := make(map[string]bool)
+	for _, f := range watch.Files {
+		files[f] = true
+		want[filepath.Dir(f)] = true
+		// A config symlinked from a dotfiles repo changes at its target.
+		if r, err := filepath.EvalSymlinks(f); err == nil && r != f {
+			files[r] = true
+			want[filepath.Dir(r)] = true
+		}
+	}
+	for _, d := range watch.Dirs {
+		dirs[d] = true
+		want[d] = true
+	}
+
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.files, pw.dirs = files, dirs
+	for dir := range pw.watched {
+		if !want[dir] {
+			pw.w.Remove(dir)
+			delete(pw.watched, dir)
+		}
+	}
+	for dir := range want {
+		if pw.watched[dir] {
+			continue
+		}
+		if err := pw.w.Add(dir); err != nil {
+			// Missing now; the scan that follows its creation retries.
+			debug.Log("daemon: watch %s: %v", dir, err)
+			continue
+		}
+		pw.watched[dir] = true
+	}
+}
+
+func (pw *fsProjectWatcher) Changes() <-chan struct{} { return pw.changes }
+
+func (pw *fsProjectWatcher) Close() error { return pw.w.Close() }
+
+// relevant reports whether ev changes what the last scan depends on: any
+// change to a watched file, or an entry coming or going in a watched
+// directory. Writes inside a project are not.
+func (pw *fsProjectWatcher) relevant(ev fsnotify.Event) bool {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.files[ev.Name] {
+		return true
+	}
+	return pw.dirs[filepath.Dir(ev.Name)] && (ev.Has(fsnotify.Create) || ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename))
+}
+
+// run turns relevant events into debounced changes until the watcher closes.
+func (pw *fsProjectWatcher) run(debounce time.Duration) {
+	var fire <-chan time.Time
+	for {
+		select {
+		case ev, ok := <-pw.w.Events:
+			if !ok {
+				return
+			}
+			if pw.relevant(ev) {
+				fire = time.After(debounce)
+			}
+		case err, ok := <-pw.w.Errors:
+			if !ok {
+				return
+			}
+			debug.Error("daemon: watch: %v", err)
+			// Dropped events may have been relevant.
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				fire = time.After(debounce)
+			}
+		case <-fire:
+			fire = nil
+			select {
+			case pw.changes <- struct{}{}:
+			default: // a rescan is already pending
+			}
+		}
+	}
+}
+
+// pollProjectWatcher reports a change every interval, for filesystems that
+// cannot be watched.
+type pollProjectWatcher struct {
+	ticker  *time.Ticker
+	changes chan struct{}
+	done    chan struct{}
+}
+
+func newPollProjectWatcher(interval time.Duration) *pollProjectWatcher {
+	pw := &pollProjectWatcher{
+		ticker:  time.NewTicker(interval),
+		changes: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case <-pw.ticker.C:
+				select {
+				case pw.changes <- struct{}{}:
+				default:
+				}
+			case <-pw.done:
+				return
+			}
+		}
+	}()
+	return pw
+}
+
+func (pw *pollProjectWatcher) Watch(projectWatch) {}
+
+func (pw *pollProjectWatcher) Changes() <-chan struct{} { return pw.changes }
+
+func (pw *pollProjectWatcher) Close() error {
+	pw.ticker.Stop()
+	close(pw.done)
+	return nil
+}
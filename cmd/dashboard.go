@@ -10,6 +10,7 @@ import (
 	"github.com/glebglazov/pop/config"
 	"github.com/glebglazov/pop/debug"
 	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
 	"github.com/glebglazov/pop/monitor"
 	"github.com/glebglazov/pop/ui"
 	"github.com/spf13/cobra"
@@ -64,6 +65,8 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 	if cfg == nil {
 		cfg = &config.Config{}
 	}
+	// The dashboard's switch goes through switchTmux, closing the popup with it.
+	switchTmux := withPopupSwitch(defaultTmux, cfg.GetPopupSwitch(), inTmuxPopup())
 
 	cursorPosition := cfg.DashboardCursorPosition()
 	var currentPaneID, currentPaneSession string
@@ -155,11 +158,11 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 	switch result.Action {
 	case ui.MonitorDashboardActionConfirm:
 		if target := handleDashboardSwitch(result, true); target != "" {
-			return switchToDashboardTarget(cfg, target)
+			return switchToDashboardTarget(switchTmux, cfg, target)
 		}
 	case ui.MonitorDashboardActionPeek:
 		if target := handleDashboardSwitch(result, false); target != "" {
-			return switchToDashboardTarget(cfg, target)
+			return switchToDashboardTarget(switchTmux, cfg, target)
 		}
 	case ui.MonitorDashboardActionCancel:
 		os.Exit(1)
@@ -172,11 +175,11 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 // [dashboard] zoom_on_switch is enabled (the default) the pane is maximized
 // within its window; otherwise it is focused in place, preserving the window's
 // split layout.
-func switchToDashboardTarget(cfg *config.Config, target string) error {
+func switchToDashboardTarget(tmux deps.Tmux, cfg *config.Config, target string) error {
 	if cfg.DashboardZoomOnSwitch() {
-		return switchToTmuxTargetAndZoom(target)
+		return switchToTmuxTargetAndZoomWith(tmux, target)
 	}
-	return switchToTmuxTarget(target)
+	return switchToTmuxTargetWith(tmux, target)
 }
 
 func sessionLocalDashboardPanes(panes []ui.AttentionPane, session, currentPaneID string) []ui.AttentionPane {
@@ -212,8 +215,8 @@ func handleDashboardSwitch(result ui.MonitorDashboardResult, dismissUnread bool)
 	if hist == nil {
 		hist = &history.History{}
 	}
-	hist.Record(sessionHistoryPath(result.Selected.Session, hist))
-	if err := hist.Save(); err != nil {
+	path := sessionHistoryPath(result.Selected.Session, hist)
+	if err := hist.Update(func(h *history.History) { h.Record(path) }); err != nil {
 		debug.Error("dashboard: save history: %v", err)
 	}
 	if dismissUnread {
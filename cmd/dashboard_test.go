@@ -443,13 +443,12 @@ func TestSessionAccessTime(t *testing.T) {
 	})
 
 	t.Run("exact match with dots sanitized", func(t *testing.T) {
-		// sanitizeSessionName replaces . with _
+		// SafeSessionName replaces . with _
 		hist := &history.History{
 			Entries: []history.Entry{
 				{Path: "/home/user/my.project", LastAccess: now},
 			},
 		}
-		// Session name has dots sanitized to underscores
 		result := sessionAccessTime("my_project", hist)
 		if result != now.Unix() {
 			t.Errorf("expected %d, got %d", now.Unix(), result)
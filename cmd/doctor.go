@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/glebglazov/pop/config"
@@ -14,6 +17,7 @@ import (
 	"github.com/glebglazov/pop/project"
 	"github.com/glebglazov/pop/release"
 	"github.com/glebglazov/pop/tasks"
+	"github.com/glebglazov/pop/ui"
 	"github.com/glebglazov/pop/wayfinder"
 	"github.com/spf13/cobra"
 )
@@ -37,7 +41,11 @@ import (
 type doctorDeps struct {
 	integrate                 *integrateDeps
 	tmuxAvailable             func() bool
+	tmuxVersion               func() (string, error)
+	gitVersion                func() (string, error)
 	loadProjectConfig         func() (*config.Config, error)
+	parseHistoryFile          func() error
+	parseGlobCacheFile        func() error
 	projectConfigureAvailable func() bool
 	expandProjectConfig       func(*config.Config) ([]config.ExpandedPath, error)
 	expandProjects            func([]config.ExpandedPath) ([]project.ExpandedProject, []string)
@@ -66,10 +74,18 @@ func defaultDoctorDeps() *doctorDeps {
 			_, err := exec.LookPath("tmux")
 			return err == nil
 		},
+		tmuxVersion: func() (string, error) { return doctorToolVersion("tmux", "-V") },
+		gitVersion:  func() (string, error) { return doctorToolVersion("git", "--version") },
 		loadProjectConfig: func() (*config.Config, error) {
 			path := config.DefaultConfigPath()
 			return config.Load(path)
 		},
+		parseHistoryFile: func() error {
+			return doctorParseJSONFile(history.DefaultHistoryPath(), &history.History{})
+		},
+		parseGlobCacheFile: func() error {
+			return doctorParseJSONFile(config.DefaultCachePath(), &config.GlobCache{})
+		},
 		projectConfigureAvailable: func() bool { return true },
 		expandProjectConfig: func(cfg *config.Config) ([]config.ExpandedPath, error) {
 			return cfg.ExpandProjects()
@@ -192,6 +208,10 @@ depends on agent setup, Doctor reads Pop's existing integration evidence to
 explain that family's readiness; it does not present a support matrix or
 per-agent component inventory as the report.
 
+pop project also checks the tmux version, config warnings (unknown keys
+among them), [keys] remaps that can't work, and whether the history and glob
+cache files parse; pop worktree checks that git is installed.
+
 Each actionable check carries a copy-paste command that fixes it. Doctor
 always exits 0 when it succeeds in rendering the report; the exit status
 reflects rendering, not the findings.`,
@@ -266,9 +286,14 @@ func historyTmuxSessionActivity() map[string]int64 {
 }
 
 func doctorProjectChecks(d *doctorDeps) []doctorCheck {
+	tmuxAvailable := d.tmuxAvailable()
 	checks := []doctorCheck{
-		doctorBoolCheck("tmux available", d.tmuxAvailable(), "tmux executable was not found", "", ""),
+		doctorBoolCheck("tmux available", tmuxAvailable, "tmux executable was not found", "", ""),
+	}
+	if tmuxAvailable {
+		checks = append(checks, doctorTmuxVersionCheck(d))
 	}
+	checks = append(checks, doctorStateFileChecks(d)...)
 
 	cfg, err := d.loadProjectConfig()
 	if err != nil {
@@ -289,6 +314,7 @@ func doctorProjectChecks(d *doctorDeps) []doctorCheck {
 		return checks
 	}
 	checks = append(checks, doctorCheck{label: "project config", status: doctorStatusOK, detail: "config loads"})
+	checks = append(checks, doctorConfigChecks(cfg)...)
 
 	paths, err := d.expandProjectConfig(cfg)
 	if err != nil {
@@ -329,20 +355,30 @@ func doctorProjectChecks(d *doctorDeps) []doctorCheck {
 }
 
 func doctorWorktreeChecks(d *doctorDeps) []doctorCheck {
-	ctx, err := d.detectRepoContext()
+	version, err := d.gitVersion()
 	if err != nil {
 		return []doctorCheck{{
+			label:  "git available",
+			status: doctorStatusBlocked,
+			detail: fmt.Sprintf("git executable was not found or failed: %v", err),
+		}}
+	}
+	checks := []doctorCheck{{label: "git available", status: doctorStatusOK, detail: version}}
+
+	ctx, err := d.detectRepoContext()
+	if err != nil {
+		return append(checks, doctorCheck{
 			label:  "git repository detected",
 			status: doctorStatusBlocked,
 			detail: "not in a git repository",
-		}}
+		})
 	}
 
-	checks := []doctorCheck{{
+	checks = append(checks, doctorCheck{
 		label:  "git repository detected",
 		status: doctorStatusOK,
 		detail: ctx.GitRoot,
-	}}
+	})
 	worktrees, err := d.listWorktrees(ctx)
 	if err != nil {
 		checks = append(checks, doctorCheck{
@@ -360,6 +396,109 @@ func doctorWorktreeChecks(d *doctorDeps) []doctorCheck {
 	return checks
 }
 
+// doctorMinTmuxVersion is the oldest tmux with display-popup -E, which the
+// README's popup bindings and --popup rely on.
+var doctorMinTmuxVersion = [2]int{3, 2}
+
+var doctorVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// doctorTmuxVersionCheck reports the tmux version, degraded below
+// doctorMinTmuxVersion. A version it cannot parse (a build from master) passes.
+func doctorTmuxVersionCheck(d *doctorDeps) doctorCheck {
+	version, err := d.tmuxVersion()
+	if err != nil {
+		return doctorCheck{label: "tmux version", status: doctorStatusDegraded, detail: fmt.Sprintf("tmux -V failed: %v", err)}
+	}
+	if m := doctorVersionPattern.FindStringSubmatch(version); m != nil {
+		major, _ := strconv.Atoi(m[1])
+		minor, _ := strconv.Atoi(m[2])
+		if major < doctorMinTmuxVersion[0] || major == doctorMinTmuxVersion[0] && minor < doctorMinTmuxVersion[1] {
+			return doctorCheck{
+				label:  "tmux version",
+				status: doctorStatusDegraded,
+				detail: fmt.Sprintf("%s is older than %d.%d; popups (display-popup -E, --popup) will not work", version, doctorMinTmuxVersion[0], doctorMinTmuxVersion[1]),
+			}
+		}
+	}
+	return doctorCheck{label: "tmux version", status: doctorStatusOK, detail: version}
+}
+
+// doctorConfigChecks reports what the config loaded with but ignored: load
+// warnings (unknown keys, bad globs, missing include files, unknown [keys]
+// actions, ...) and [keys] remaps that cannot work.
+func doctorConfigChecks(cfg *config.Config) []doctorCheck {
+	var checks []doctorCheck
+	cfg.ScriptCommands() // records the scripts' problems as warnings
+	for _, warning := range cfg.Warnings {
+		checks = append(checks, doctorCheck{label: "config warning", status: doctorStatusDegraded, detail: warning})
+	}
+
+	if problems := ui.KeyBindingProblems(cfg.Keys); len(problems) > 0 {
+		checks = append(checks, doctorCheck{
+			label:      "key bindings",
+			status:     doctorStatusDegraded,
+			detail:     strings.Join(problems, "; "),
+			nextAction: "pop keys",
+		})
+	} else {
+		checks = append(checks, doctorCheck{label: "key bindings", status: doctorStatusOK, detail: "no conflicts"})
+	}
+	return checks
+}
+
+// doctorStateFileChecks reports whether the history and glob cache files
+// parse. pop reads either one that doesn't as empty, so a corrupt history
+// silently loses its ranking.
+func doctorStateFileChecks(d *doctorDeps) []doctorCheck {
+	var checks []doctorCheck
+	if err := d.parseHistoryFile(); err != nil {
+		checks = append(checks, doctorCheck{
+			label:  "history file",
+			status: doctorStatusDegraded,
+			detail: fmt.Sprintf("%v; projects are listed without recency until it is fixed or removed", err),
+		})
+	} else {
+		checks = append(checks, doctorCheck{label: "history file", status: doctorStatusOK, detail: "parses"})
+	}
+	if err := d.parseGlobCacheFile(); err != nil {
+		checks = append(checks, doctorCheck{
+			label:      "glob cache",
+			status:     doctorStatusDegraded,
+			detail:     fmt.Sprintf("%v; globs are rescanned on every run", err),
+			nextAction: "pop cache clear",
+		})
+	} else {
+		checks = append(checks, doctorCheck{label: "glob cache", status: doctorStatusOK, detail: "parses"})
+	}
+	return checks
+}
+
+// doctorParseJSONFile reports whether the JSON file at path decodes into v. A
+// missing file is fine: pop creates it on first write.
+func doctorParseJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// doctorToolVersion runs name with args and returns the first line it prints.
+func doctorToolVersion(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return line, nil
+}
+
 func doctorPaneChecks(d *doctorDeps) []doctorCheck {
 	tmuxAvailable := d.tmuxAvailable()
 	checks := []doctorCheck{
@@ -29,12 +29,16 @@ func readOnlyDoctorDeps(t *testing.T, fs *fakeFS, tmux, cfgOK, daemon bool) *doc
 	return &doctorDeps{
 		integrate:     base,
 		tmuxAvailable: func() bool { return tmux },
+		tmuxVersion:   func() (string, error) { return "tmux 3.4", nil },
+		gitVersion:    func() (string, error) { return "git version 2.45.0", nil },
 		loadProjectConfig: func() (*config.Config, error) {
 			if cfgOK {
 				return &config.Config{}, nil
 			}
 			return nil, errors.New("/cfg/config.toml: not found")
 		},
+		parseHistoryFile:          func() error { return nil },
+		parseGlobCacheFile:        func() error { return nil },
 		projectConfigureAvailable: func() bool { return true },
 		expandProjectConfig: func(*config.Config) ([]config.ExpandedPath, error) {
 			return []config.ExpandedPath{{Path: "/repo/app", Explicit: true}}, nil
@@ -871,6 +875,113 @@ func TestDoctorWorktreeReadinessBlocksOutsideGit(t *testing.T) {
 	}
 }
 
+func TestDoctorWorktreeReadinessBlocksWithoutGit(t *testing.T) {
+	d := readOnlyDoctorDeps(t, newFakeFS(), true, true, true)
+	d.gitVersion = func() (string, error) { return "", exec.ErrNotFound }
+
+	report, err := buildDoctorReport(d)
+	if err != nil {
+		t.Fatalf("buildDoctorReport: %v", err)
+	}
+	family, _ := familyByCommand(report, "pop worktree")
+	check, ok := checkByLabel(family, "git available")
+	if !ok || check.status != doctorStatusBlocked || family.status != doctorStatusBlocked {
+		t.Fatalf("worktree family = %+v, want a blocked git available check", family)
+	}
+}
+
+func TestDoctorTmuxVersionCheck(t *testing.T) {
+	tests := []struct {
+		version string
+		want    doctorStatus
+	}{
+		{"tmux 3.4", doctorStatusOK},
+		{"tmux 3.2a", doctorStatusOK},
+		{"tmux next-3.5", doctorStatusOK},
+		{"tmux master", doctorStatusOK},
+		{"tmux 3.1c", doctorStatusDegraded},
+		{"tmux 2.9", doctorStatusDegraded},
+	}
+	for _, tt := range tests {
+		d := readOnlyDoctorDeps(t, newFakeFS(), true, true, true)
+		d.tmuxVersion = func() (string, error) { return tt.version, nil }
+		if got := doctorTmuxVersionCheck(d); got.status != tt.want {
+			t.Errorf("doctorTmuxVersionCheck(%q) = %+v, want %s", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestDoctorProjectReportsIgnoredConfig(t *testing.T) {
+	d := readOnlyDoctorDeps(t, newFakeFS(), true, true, true)
+	d.loadProjectConfig = func() (*config.Config, error) {
+		return &config.Config{
+			Warnings: []string{`include file "~/work.toml" not found, skipping`},
+			Keys:     map[string]string{"reset": "ctrl-k", "help": "crtl-h"},
+		}, nil
+	}
+
+	report, err := buildDoctorReport(d)
+	if err != nil {
+		t.Fatalf("buildDoctorReport: %v", err)
+	}
+	family, _ := familyByCommand(report, "pop project")
+	if family.status != doctorStatusDegraded {
+		t.Errorf("project status = %s, want %s (%s)", family.status, doctorStatusDegraded, family.reason)
+	}
+	for label, want := range map[string]string{
+		"config warning": "work.toml",
+		"key bindings":   "ctrl+k is bound to both kill_session and reset",
+	} {
+		check, ok := checkByLabel(family, label)
+		if !ok || check.status != doctorStatusDegraded || !strings.Contains(check.detail, want) {
+			t.Errorf("%s check = %+v, want Degraded mentioning %q", label, check, want)
+		}
+	}
+	if check, _ := checkByLabel(family, "key bindings"); !strings.Contains(check.detail, `"crtl-h" is not a key name`) {
+		t.Errorf("key bindings check = %+v, want the misspelt key reported", check)
+	}
+}
+
+func TestDoctorProjectCorruptStateFilesDegrade(t *testing.T) {
+	d := readOnlyDoctorDeps(t, newFakeFS(), true, true, true)
+	d.parseHistoryFile = func() error { return errors.New("history.json: unexpected end of JSON input") }
+
+	report, err := buildDoctorReport(d)
+	if err != nil {
+		t.Fatalf("buildDoctorReport: %v", err)
+	}
+	family, _ := familyByCommand(report, "pop project")
+	if check, ok := checkByLabel(family, "history file"); !ok || check.status != doctorStatusDegraded {
+		t.Errorf("history file check = %+v, want Degraded", check)
+	}
+	if check, ok := checkByLabel(family, "glob cache"); !ok || check.status != doctorStatusOK {
+		t.Errorf("glob cache check = %+v, want OK", check)
+	}
+}
+
+func TestDoctorParseJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(good, []byte(`{"entries": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte(`{"entries": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var v map[string]any
+	if err := doctorParseJSONFile(good, &v); err != nil {
+		t.Errorf("good file: %v", err)
+	}
+	if err := doctorParseJSONFile(filepath.Join(dir, "missing.json"), &v); err != nil {
+		t.Errorf("missing file: %v, want nil", err)
+	}
+	if err := doctorParseJSONFile(bad, &v); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("bad file: %v, want an error naming it", err)
+	}
+}
+
 func TestDoctorTaskHealthyStorageIsOK(t *testing.T) {
 	d := readOnlyDoctorDeps(t, newFakeFS(), true, true, true)
 
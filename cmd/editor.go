@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+)
+
+// editorCommand returns the command that opens path in target, one of the
+// editor open_with targets: a VS Code or Zed window, or a cd in the running
+// nvim server.
+func editorCommand(cfg *config.Config, target, path string) ([]string, error) {
+	switch target {
+	case config.OpenWithCode:
+		return []string{"code", path}, nil
+	case config.OpenWithZed:
+		return []string{"zed", path}, nil
+	case config.OpenWithNvim:
+		server := cfg.ProjectNvimServer()
+		if server == "" {
+			return nil, fmt.Errorf("no nvim server: start nvim with --listen and set nvim_server under [project]")
+		}
+		// The path travels as a Vim string literal, where only ' is special,
+		// and fnameescape guards it on the :cd line: sent as keys, a path with
+		// |, %, # or < would run commands or expand instead. The edit is a
+		// command of its own, so nothing follows the escaped path.
+		literal := "'" + strings.ReplaceAll(path, "'", "''") + "'"
+		return []string{"nvim", "--server", server, "--remote-expr", "execute(['cd ' .. fnameescape(" + literal + "), 'edit .'])"}, nil
+	}
+	return nil, fmt.Errorf("unknown editor %q", target)
+}
+
+// openInEditorsWith opens path in each editor among targets, skipping tmux,
+// which the caller handles.
+func openInEditorsWith(d *ProjectDeps, cfg *config.Config, targets []string, path string) error {
+	for _, target := range targets {
+		if target == config.OpenWithTmux {
+			continue
+		}
+		argv, err := editorCommand(cfg, target, path)
+		if err != nil {
+			return err
+		}
+		if err := d.RunEditor(argv[0], argv[1:]...); err != nil {
+			return fmt.Errorf("open %s in %s: %w", path, target, err)
+		}
+	}
+	return nil
+}
+
+// opensInTmux reports whether a project with these open_with targets gets a
+// tmux session; no targets means tmux only.
+func opensInTmux(targets []string) bool {
+	return len(targets) == 0 || slices.Contains(targets, config.OpenWithTmux)
+}
+
+// runEditorCommand runs an editor launcher, folding its output into the error
+// when it fails.
+func runEditorCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestEditorCommand(t *testing.T) {
+	withServer := &config.Config{Project: &config.ProjectConfig{NvimServer: "/tmp/nvim.sock"}}
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{name: "code", cfg: &config.Config{}, target: "code", want: "code /p/my app"},
+		{name: "zed", cfg: &config.Config{}, target: "zed", want: "zed /p/my app"},
+		{name: "nvim", cfg: withServer, target: "nvim", want: `nvim --server /tmp/nvim.sock --remote-expr execute(['cd ' .. fnameescape('/p/my app'), 'edit .'])`},
+		{name: "unknown", cfg: &config.Config{}, target: "emacs", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argv, err := editorCommand(tt.cfg, tt.target, "/p/my app")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Join(argv, " "); !tt.wantErr && got != tt.want {
+				t.Errorf("command = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorCommand_NvimQuotesPath(t *testing.T) {
+	cfg := &config.Config{Project: &config.ProjectConfig{NvimServer: "/tmp/nvim.sock"}}
+	argv, err := editorCommand(cfg, "nvim", `/p/it's | %a#<b>\`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `execute(['cd ' .. fnameescape('/p/it''s | %a#<b>\'), 'edit .'])`
+	if got := argv[len(argv)-1]; got != want {
+		t.Errorf("expr = %q, want %q", got, want)
+	}
+}
+
+func TestEditorCommand_NvimNeedsServer(t *testing.T) {
+	t.Setenv("NVIM", "")
+	if _, err := editorCommand(&config.Config{}, "nvim", "/p"); err == nil {
+		t.Error("want an error without an nvim server")
+	}
+}
+
+// editorTestDeps opens one project configured with openWith, recording the
+// editor commands run and the sessions opened.
+func editorTestDeps(t *testing.T, openWith []string, action ui.Action, ran, opened *[]string) *ProjectDeps {
+	t.Helper()
+	d := testProjectDeps(t)
+	dir := t.TempDir()
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{
+			Projects: []config.ProjectEntry{{Path: dir, OpenWith: openWith}},
+			Project:  &config.ProjectConfig{Editor: "zed"},
+		}, nil
+	}
+	d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+		return ui.Result{Action: action, Selected: &items[0]}
+	})
+	d.RunEditor = func(name string, args ...string) error {
+		*ran = append(*ran, name)
+		return nil
+	}
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		*opened = append(*opened, item.SessionName)
+		return nil
+	}
+	return d
+}
+
+func TestRunProject_OpenWith(t *testing.T) {
+	tests := []struct {
+		name        string
+		openWith    []string
+		action      ui.Action
+		wantRan     []string
+		wantSession bool
+	}{
+		{name: "default is tmux", action: ui.ActionConfirm, wantSession: true},
+		{name: "editor instead of tmux", openWith: []string{"code"}, action: ui.ActionConfirm, wantRan: []string{"code"}},
+		{name: "editor alongside tmux", openWith: []string{"tmux", "code"}, action: ui.ActionConfirm, wantRan: []string{"code"}, wantSession: true},
+		{name: "alt+enter uses [project] editor", openWith: []string{"code"}, action: ui.ActionOpenEditor, wantRan: []string{"zed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran, opened []string
+			d := editorTestDeps(t, tt.openWith, tt.action, &ran, &opened)
+			if err := RunProject(d); err != nil {
+				t.Fatal(err)
+			}
+			if !equalStrings(ran, tt.wantRan) {
+				t.Errorf("editors run = %q, want %q", ran, tt.wantRan)
+			}
+			if (len(opened) > 0) != tt.wantSession {
+				t.Errorf("sessions opened = %q, want a session: %v", opened, tt.wantSession)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// projectFetcher starts the fetch_on_open fetches, at most one per project
+// within the configured interval. The last fetch of each project is kept in a
+// stamp file so the interval holds across pop runs.
+type projectFetcher struct {
+	cfg       *config.Config
+	fs        deps.FileSystem
+	stampPath string
+	now       func() time.Time
+	// Start launches the fetch in path without waiting for it;
+	// startBackgroundFetch in production.
+	Start func(path string) error
+}
+
+// newProjectFetcher returns the fetcher for cfg, or nil when neither the
+// global fetch_on_open nor any projects entry turns it on.
+func newProjectFetcher(cfg *config.Config, start func(path string) error) *projectFetcher {
+	if start == nil || !cfg.FetchOnOpen && !slices.ContainsFunc(cfg.Projects, func(p config.ProjectEntry) bool {
+		return p.FetchOnOpen != nil && *p.FetchOnOpen
+	}) {
+		return nil
+	}
+	return &projectFetcher{
+		cfg:       cfg,
+		fs:        deps.NewRealFileSystem(),
+		stampPath: filepath.Join(filepath.Dir(config.DefaultCachePath()), "fetch_on_open.json"),
+		now:       time.Now,
+		Start:     start,
+	}
+}
+
+// fetch starts a fetch in path when fetch_on_open applies to it and it was not
+// fetched within the interval.
+func (f *projectFetcher) fetch(path string) {
+	if path == "" || !f.cfg.FetchOnOpenFor(path) {
+		return
+	}
+	stamps := map[string]time.Time{}
+	if data, err := f.fs.ReadFile(f.stampPath); err == nil {
+		if err := json.Unmarshal(data, &stamps); err != nil {
+			debug.Error("fetch_on_open: parse %s: %v", f.stampPath, err)
+		}
+	}
+	now := f.now()
+	interval := f.cfg.FetchOnOpenInterval()
+	if last, ok := stamps[path]; ok && now.Sub(last) < interval {
+		return
+	}
+	// A stamp past the interval throttles nothing; dropping it keeps the file
+	// to the projects opened recently.
+	for p, last := range stamps {
+		if now.Sub(last) >= interval {
+			delete(stamps, p)
+		}
+	}
+	stamps[path] = now
+	if err := f.save(stamps); err != nil {
+		debug.Error("fetch_on_open: write %s: %v", f.stampPath, err)
+	}
+	if err := f.Start(path); err != nil {
+		debug.Error("fetch_on_open: %s: %v", path, err)
+	}
+}
+
+// save writes stamps through a temp file renamed into place, so a pop run
+// reading the stamps never sees a half-written file.
+func (f *projectFetcher) save(stamps map[string]time.Time) error {
+	data, err := json.Marshal(stamps)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.stampPath)
+	if err := f.fs.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(dir, fmt.Sprintf(".%s.%d.tmp", filepath.Base(f.stampPath), os.Getpid()))
+	if err := f.fs.WriteFile(tmpPath, data, 0o644); err != nil {
+		_ = f.fs.RemoveAll(tmpPath)
+		return err
+	}
+	if err := f.fs.Rename(tmpPath, f.stampPath); err != nil {
+		_ = f.fs.RemoveAll(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// startBackgroundFetch runs git fetch --quiet in path detached from pop, so it
+// outlives a popup that closes on switch. Credential prompts are turned off:
+// there is no terminal to answer them.
+func startBackgroundFetch(path string) error {
+	cmd := exec.Command("git", "-C", path, "fetch", "--quiet")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// fetchTmux starts fetch_on_open fetches around the tmux calls that create and
+// switch to sessions, before the switch so a closing popup can't prevent it.
+type fetchTmux struct {
+	deps.Tmux
+	fetcher *projectFetcher
+}
+
+// withFetchOnOpen wraps tmux in fetchTmux when fetcher is non-nil.
+func withFetchOnOpen(tmux deps.Tmux, fetcher *projectFetcher) deps.Tmux {
+	if fetcher == nil {
+		return tmux
+	}
+	return fetchTmux{Tmux: tmux, fetcher: fetcher}
+}
+
+func (t fetchTmux) NewSession(name, dir string) error {
+	if err := t.Tmux.NewSession(name, dir); err != nil {
+		return err
+	}
+	t.fetcher.fetch(dir)
+	return nil
+}
+
+func (t fetchTmux) SwitchClient(name string) error {
+	t.fetchFor(name)
+	return t.Tmux.SwitchClient(name)
+}
+
+func (t fetchTmux) AttachSession(name string) error {
+	t.fetchFor(name)
+	return t.Tmux.AttachSession(name)
+}
+
+func (t fetchTmux) AttachSessionDetachOthers(name string) error {
+	t.fetchFor(name)
+	return t.Tmux.AttachSessionDetachOthers(name)
+}
+
+func (t fetchTmux) Command(args ...string) (string, error) {
+	if len(args) == 0 {
+		return t.Tmux.Command(args...)
+	}
+	switch args[0] {
+	case "new-session":
+		out, err := t.Tmux.Command(args...)
+		if err == nil {
+			t.fetcher.fetch(flagValue(args, "-c"))
+		}
+		return out, err
+	case "switch-client":
+		t.fetchFor(flagValue(args, "-t"))
+	}
+	return t.Tmux.Command(args...)
+}
+
+// fetchFor fetches in the start directory of the session behind target (a
+// session name or a pane ID).
+func (t fetchTmux) fetchFor(target string) {
+	path, err := t.Tmux.Command("display-message", "-p", "-t", target, "#{session_path}")
+	if err != nil {
+		debug.Error("fetch_on_open: resolve session %s: %v", target, err)
+		return
+	}
+	t.fetcher.fetch(path)
+}
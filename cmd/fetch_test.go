@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func testFetcher(t *testing.T, cfg *config.Config, now *time.Time, fetched *[]string) *projectFetcher {
+	t.Helper()
+	fs := realFSDeps()
+	fs.ReadFileFunc = os.ReadFile
+	fs.RenameFunc = os.Rename
+	fs.RemoveAllFunc = os.RemoveAll
+	f := newProjectFetcher(cfg, func(path string) error {
+		*fetched = append(*fetched, path)
+		return nil
+	})
+	if f == nil {
+		t.Fatal("newProjectFetcher() = nil with fetch_on_open on")
+	}
+	f.fs = fs
+	f.stampPath = filepath.Join(t.TempDir(), "fetch_on_open.json")
+	f.now = func() time.Time { return *now }
+	return f
+}
+
+func TestProjectFetcher_DedupesWithinInterval(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var fetched []string
+	f := testFetcher(t, &config.Config{FetchOnOpen: true, FetchOnOpenMinutes: 10}, &now, &fetched)
+
+	f.fetch("/src/api")
+	now = now.Add(9 * time.Minute)
+	f.fetch("/src/api")
+	f.fetch("/src/web")
+	now = now.Add(2 * time.Minute)
+	f.fetch("/src/api")
+
+	if !equalStrings(fetched, []string{"/src/api", "/src/web", "/src/api"}) {
+		t.Errorf("fetched = %v, want api, web, then api again after the interval", fetched)
+	}
+}
+
+func TestProjectFetcher_DropsStaleStamps(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var fetched []string
+	f := testFetcher(t, &config.Config{FetchOnOpen: true, FetchOnOpenMinutes: 10}, &now, &fetched)
+
+	f.fetch("/src/api")
+	now = now.Add(5 * time.Minute)
+	f.fetch("/src/web")
+	now = now.Add(6 * time.Minute)
+	f.fetch("/src/cli")
+
+	data, err := os.ReadFile(f.stampPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stamps map[string]time.Time
+	if err := json.Unmarshal(data, &stamps); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stamps["/src/api"]; ok || len(stamps) != 2 {
+		t.Errorf("stamps = %v, want only web and cli", stamps)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(f.stampPath))
+	if len(entries) != 1 {
+		t.Errorf("stamp dir holds %d entries, want only the stamp file", len(entries))
+	}
+}
+
+func TestProjectFetcher_EntryOverride(t *testing.T) {
+	on, off := true, false
+	cfg := &config.Config{Projects: []config.ProjectEntry{
+		{Path: "/src/api", FetchOnOpen: &on},
+		{Path: "/src/*", FetchOnOpen: &off},
+	}}
+	now := time.Now()
+	var fetched []string
+	f := testFetcher(t, cfg, &now, &fetched)
+
+	f.fetch("/src/api")
+	f.fetch("/src/web")
+	if !equalStrings(fetched, []string{"/src/api"}) {
+		t.Errorf("fetched = %v, want only /src/api", fetched)
+	}
+}
+
+func TestNewProjectFetcher_OffWithoutConfig(t *testing.T) {
+	start := func(string) error { return nil }
+	if f := newProjectFetcher(&config.Config{}, start); f != nil {
+		t.Error("newProjectFetcher() should be nil when fetch_on_open is off everywhere")
+	}
+	if f := newProjectFetcher(&config.Config{FetchOnOpen: true}, nil); f != nil {
+		t.Error("newProjectFetcher() should be nil without a start func")
+	}
+}
+
+func TestFetchTmux(t *testing.T) {
+	now := time.Now()
+	var fetched []string
+	f := testFetcher(t, &config.Config{FetchOnOpen: true}, &now, &fetched)
+	inner := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			if args[0] == "display-message" {
+				return "/src/api", nil
+			}
+			return "", nil
+		},
+	}
+	tmux := withFetchOnOpen(inner, f)
+
+	if err := tmux.NewSession("web", "/src/web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmux.SwitchClient("api"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmux.Command("switch-client", "-t", "api"); err != nil {
+		t.Fatal(err)
+	}
+	if !equalStrings(fetched, []string{"/src/web", "/src/api"}) {
+		t.Errorf("fetched = %v, want web on create and api once on switch", fetched)
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcDays int
+	gcYes  bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Kill detached project sessions you have not used in a while",
+	Long: `Find tmux sessions of configured projects that no client is attached to and
+that have not been used for --days days, and offer to kill them. A session
+counts as used when its project was opened through pop or the session saw
+activity in tmux, whichever is later. Sessions that are not pop projects are
+never touched.
+
+The stale sessions open in a checkbox list with all of them checked: Enter
+kills the checked ones, space unchecks any to keep, Esc kills nothing. --yes
+kills them all without asking.
+
+--days defaults to [project] gc_after_days, 7 when unset.`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().IntVar(&gcDays, "days", 0, "days a detached session may go unused (default [project] gc_after_days, or 7)")
+	gcCmd.Flags().BoolVarP(&gcYes, "yes", "y", false, "kill every stale session without asking")
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	if gcDays < 0 {
+		return fmt.Errorf("--days must not be negative")
+	}
+	return runGCWith(DefaultProjectDeps(), gcDays, gcYes, time.Now(), os.Stdout)
+}
+
+// staleSession is a detached project session pop gc offers to kill.
+type staleSession struct {
+	Name     string
+	Project  string
+	LastUsed time.Time
+}
+
+// runGCWith kills the detached project sessions unused for days days (0
+// meaning the configured default) as of now: all of them with yes, otherwise
+// those left checked in the list.
+func runGCWith(d *ProjectDeps, days int, yes bool, now time.Time, w io.Writer) error {
+	cfg, err := d.LoadConfig()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg = &config.Config{}
+	}
+	if days == 0 {
+		days = cfg.ProjectGCAfterDays()
+	}
+
+	stale, err := findStaleSessionsWith(d, cfg, now.AddDate(0, 0, -days))
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		fmt.Fprintf(w, "No detached project sessions unused for %d days\n", days)
+		return nil
+	}
+
+	names := make([]string, len(stale))
+	for i, s := range stale {
+		names[i] = s.Name
+	}
+	if !yes {
+		if names, err = pickStaleSessions(stale, now); err != nil || len(names) == 0 {
+			return err
+		}
+	}
+
+	tmux := d.Tmux
+	if d.RunHook != nil {
+		tmux = withSessionHooks(tmux, &sessionHooks{cfg: cfg, Run: d.RunHook})
+	}
+	return killSessionsWith(tmux, names, w)
+}
+
+// findStaleSessionsWith returns the detached sessions of configured projects
+// last used before cutoff, least recently used first.
+func findStaleSessionsWith(d *ProjectDeps, cfg *config.Config, cutoff time.Time) ([]staleSession, error) {
+	sessions := listTmuxSessionsWith(d)
+	if len(sessions) == 0 || len(cfg.Projects) == 0 {
+		return nil, nil
+	}
+
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	hist, err := d.LoadHistory()
+	if err != nil {
+		hist = &history.History{}
+	}
+	projects, _, err := collectProjectsWith(d, cfg, cfgPath, hist, nil)
+	if err != nil {
+		return nil, err
+	}
+	lastAccess := make(map[string]time.Time, len(hist.Entries))
+	for _, e := range hist.Entries {
+		lastAccess[e.Path] = e.LastAccess
+	}
+	bySession := make(map[string]staleSession, len(projects))
+	for _, p := range projects {
+		bySession[p.SessionName] = staleSession{Name: p.SessionName, Project: p.Name, LastUsed: lastAccess[p.Path]}
+	}
+
+	// listTmuxSessionsWith orders by activity; keep that order, which is
+	// least recently used first whenever tmux activity is the later signal.
+	var stale []staleSession
+	for _, s := range sessions {
+		candidate, ok := bySession[s.Name]
+		if !ok || s.Attached {
+			continue
+		}
+		if activity := time.Unix(s.Activity, 0); activity.After(candidate.LastUsed) {
+			candidate.LastUsed = activity
+		}
+		if candidate.LastUsed.Before(cutoff) {
+			stale = append(stale, candidate)
+		}
+	}
+	return stale, nil
+}
+
+// runGCMultiSelect shows the stale sessions; a seam so tests answer in
+// place of the checkbox TUI.
+var runGCMultiSelect = func(title string, items []ui.MultiSelectItem) (ui.MultiSelectResult, error) {
+	return ui.RunMultiSelect(title, items)
+}
+
+// pickStaleSessions lists the stale sessions in a checkbox list with every one
+// checked and returns the names left checked. It returns nil when the list is
+// cancelled.
+func pickStaleSessions(stale []staleSession, now time.Time) ([]string, error) {
+	items := make([]ui.MultiSelectItem, len(stale))
+	for i, s := range stale {
+		items[i] = ui.MultiSelectItem{
+			Label:   fmt.Sprintf("%s (%s, unused %dd)", s.Name, s.Project, int(now.Sub(s.LastUsed).Hours()/24)),
+			Checked: true,
+		}
+	}
+	result, err := runGCMultiSelect("Kill stale sessions (space unchecks, Esc keeps all)", items)
+	if err != nil || !result.Confirmed {
+		return nil, err
+	}
+	names := make([]string, len(result.Checked))
+	for i, idx := range result.Checked {
+		names[i] = stale[idx].Name
+	}
+	return names, nil
+}
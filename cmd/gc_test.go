@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/ui"
+)
+
+// gcTestDeps configures two projects, "old" and "fresh", with tmux sessions
+// last active at oldActivity and freshActivity, plus a standalone "scratch"
+// session and an attached "old-attached" project session.
+func gcTestDeps(t *testing.T, oldActivity, freshActivity time.Time, killed *[]string) *ProjectDeps {
+	t.Helper()
+	d := testProjectDeps(t)
+	root := t.TempDir()
+	for _, name := range []string{"old", "fresh", "busy"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(root, "*")}}}, nil
+	}
+	d.LoadHistory = func() (*history.History, error) { return &history.History{}, nil }
+	d.Tmux = killTestTmux(fmt.Sprintf("old\t0\t%d\nfresh\t0\t%d\nbusy\t1\t%d\nscratch\t0\t%d",
+		oldActivity.Unix(), freshActivity.Unix(), oldActivity.Unix(), oldActivity.Unix()), killed)
+	return d
+}
+
+func TestRunGCWith_YesKillsStaleDetachedProjectSessions(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var killed []string
+	d := gcTestDeps(t, now.AddDate(0, 0, -10), now.AddDate(0, 0, -2), &killed)
+	old := runGCMultiSelect
+	t.Cleanup(func() { runGCMultiSelect = old })
+	runGCMultiSelect = func(string, []ui.MultiSelectItem) (ui.MultiSelectResult, error) {
+		t.Fatal("--yes must not ask")
+		return ui.MultiSelectResult{}, nil
+	}
+
+	var out bytes.Buffer
+	if err := runGCWith(d, 0, true, now, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"=old"}; !equalStrings(killed, want) {
+		t.Errorf("killed = %q, want %q", killed, want)
+	}
+}
+
+func TestRunGCWith_HistoryAccessKeepsSession(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var killed []string
+	d := gcTestDeps(t, now.AddDate(0, 0, -10), now.AddDate(0, 0, -10), &killed)
+	cfg, _ := d.LoadConfig()
+	fresh := filepath.Join(filepath.Dir(cfg.Projects[0].Path), "fresh")
+	d.LoadHistory = func() (*history.History, error) {
+		return &history.History{Entries: []history.Entry{{Path: fresh, LastAccess: now.Add(-time.Hour)}}}, nil
+	}
+
+	if err := runGCWith(d, 0, true, now, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"=old"}; !equalStrings(killed, want) {
+		t.Errorf("killed = %q, want %q", killed, want)
+	}
+}
+
+func TestRunGCWith_MultiSelectKillsChecked(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var killed []string
+	d := gcTestDeps(t, now.AddDate(0, 0, -10), now.AddDate(0, 0, -4), &killed)
+
+	var shown []ui.MultiSelectItem
+	old := runGCMultiSelect
+	t.Cleanup(func() { runGCMultiSelect = old })
+	runGCMultiSelect = func(_ string, items []ui.MultiSelectItem) (ui.MultiSelectResult, error) {
+		shown = items
+		return ui.MultiSelectResult{Confirmed: true, Checked: []int{1}}, nil
+	}
+
+	// --days 3 makes both detached project sessions stale.
+	if err := runGCWith(d, 3, false, now, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(shown) != 2 || !shown[0].Checked || !shown[1].Checked {
+		t.Fatalf("items = %+v, want old and fresh, both checked", shown)
+	}
+	if want := []string{"=fresh"}; !equalStrings(killed, want) {
+		t.Errorf("killed = %q, want %q", killed, want)
+	}
+}
+
+func TestRunGCWith_NothingStale(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var killed []string
+	d := gcTestDeps(t, now.Add(-time.Hour), now.Add(-time.Hour), &killed)
+
+	var out bytes.Buffer
+	if err := runGCWith(d, 0, false, now, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(killed) != 0 {
+		t.Errorf("killed = %q", killed)
+	}
+	if got := out.String(); got != "No detached project sessions unused for 7 days\n" {
+		t.Errorf("output = %q", got)
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// ConfigTemplate is the annotated example config (config.example.toml), set
+// by main from its embedded copy since the file lives at the repo root.
+var ConfigTemplate string
+
+var generateCmd = &cobra.Command{
+	Use:   "generate <dir>",
+	Short: "Write man pages, shell completions and the config template",
+	Long: `Write the files a package ships alongside the pop binary into <dir>:
+
+  man/                  one man page per command (pop.1, pop-project.1, ...)
+  completions/pop.bash  bash completion
+  completions/_pop      zsh completion
+  completions/pop.fish  fish completion
+  config.example.toml   the annotated example config
+
+Existing files are overwritten. Man pages take their date from
+SOURCE_DATE_EPOCH when set, so release builds stay reproducible.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGenerate,
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+}
+
+func runGenerate(cmd *cobra.Command, args []string) error {
+	return runGenerateWith(rootCmd, args[0], ConfigTemplate, os.Stdout)
+}
+
+// runGenerateWith writes root's packaging artifacts into dir. An empty
+// template skips config.example.toml.
+func runGenerateWith(root *cobra.Command, dir, template string, w io.Writer) error {
+	manDir := filepath.Join(dir, "man")
+	if err := os.MkdirAll(manDir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", manDir, err)
+	}
+	// The "Auto generated by spf13/cobra" footer carries a date, which would
+	// make every regeneration a diff.
+	root.DisableAutoGenTag = true
+	header := &doc.GenManHeader{Title: "POP", Section: "1", Source: "pop " + root.Version}
+	if err := doc.GenManTree(root, header, manDir); err != nil {
+		return fmt.Errorf("generate man pages: %w", err)
+	}
+
+	completionDir := filepath.Join(dir, "completions")
+	if err := os.MkdirAll(completionDir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", completionDir, err)
+	}
+	completions := []struct {
+		name string
+		gen  func(path string) error
+	}{
+		{"pop.bash", func(path string) error { return root.GenBashCompletionFileV2(path, true) }},
+		{"_pop", root.GenZshCompletionFile},
+		{"pop.fish", func(path string) error { return root.GenFishCompletionFile(path, true) }},
+	}
+	for _, c := range completions {
+		if err := c.gen(filepath.Join(completionDir, c.name)); err != nil {
+			return fmt.Errorf("generate %s: %w", c.name, err)
+		}
+	}
+
+	if template != "" {
+		path := filepath.Join(dir, "config.example.toml")
+		if err := os.WriteFile(path, []byte(template), 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+
+	fmt.Fprintf(w, "Wrote man pages, completions and config template to %s\n", dir)
+	return nil
+}
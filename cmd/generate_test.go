@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunGenerateWith(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := runGenerateWith(rootCmd, dir, "# template\n", io.Discard); err != nil {
+		t.Fatalf("runGenerateWith: %v", err)
+	}
+
+	for path, want := range map[string]string{
+		"man/pop.1":            ".TH \"POP\" \"1\"",
+		"man/pop-project.1":    "pop-project",
+		"completions/pop.bash": "# bash completion V2 for pop",
+		"completions/_pop":     "#compdef pop",
+		"completions/pop.fish": "complete -c pop",
+		"config.example.toml":  "# template",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s missing %q", path, want)
+		}
+	}
+
+	// Hidden commands stay out of the man pages.
+	if _, err := os.Stat(filepath.Join(dir, "man", "pop-dashboard.1")); err == nil {
+		t.Error("hidden legacy dashboard alias got a man page")
+	}
+}
+
+func TestRunGenerateWithSkipsEmptyTemplate(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := runGenerateWith(rootCmd, dir, "", io.Discard); err != nil {
+		t.Fatalf("runGenerateWith: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config.example.toml")); !os.IsNotExist(err) {
+		t.Errorf("config.example.toml written without a template (err = %v)", err)
+	}
+}
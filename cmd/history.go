@@ -0,0 +1,692 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd is the `pop history` command group. Bare `pop history` prints help.
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Inspect and edit project access history",
+}
+
+var historyTopDays int
+
+var historyTopCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Rank the most-used projects",
+	Long: `Print a ranked table of the projects you open most, with their access count
+and last access — your actual working set, handy when pruning config entries.
+
+Only projects accessed within the last --days days are listed (default 30);
+--days 0 lists all of history. Access counts are recorded from the version of
+pop that introduced them, so projects last opened before that show a count of 0.`,
+	Args: cobra.NoArgs,
+	RunE: runHistoryTop,
+}
+
+var (
+	historyPruneDryRun bool
+	historyPruneLimit  int
+)
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Drop dead and excess history entries",
+	Long: `Drop history entries whose directory no longer exists, then keep only the
+most recently opened entries up to the history limit: --limit when given,
+otherwise [project] history_limit (default 500). Like history_limit, -1
+keeps every remaining entry. Standalone tmux session entries are never treated as missing.
+
+The picker applies the limit on its own each time it opens, and drops missing
+directories too when [project] history_prune_missing is set.`,
+	Args: cobra.NoArgs,
+	RunE: runHistoryPrune,
+}
+
+var historyListFormat string
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every history entry",
+	Long: `Print every project history entry, most recently opened first.
+
+--format plain (default) prints one "last access<TAB>count<TAB>path" line per
+entry. --format json prints an array of objects with path, id, last access,
+count, killed at and the frecency score the picker ranks by; pop history
+import reads it back.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHistoryListWith(defaultHistoryDeps(), historyListFormat)
+	},
+}
+
+var historyRmCmd = &cobra.Command{
+	Use:   "rm <path>...",
+	Short: "Remove projects from history",
+	Long: `Remove the history entries of the given paths, as ctrl-r does in the picker.
+Relative paths and symlinks are resolved; a path with no entry is an error.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHistoryRmWith(defaultHistoryDeps(), args)
+	},
+}
+
+var historyClearYes bool
+
+var historyClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every history entry",
+	Long:  `Remove every project history entry after asking for confirmation; --yes skips the question.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHistoryClearWith(defaultHistoryDeps(), historyClearYes)
+	},
+}
+
+var historyImportFrom string
+
+var historyImportCmd = &cobra.Command{
+	Use:   "import <file> | --from zoxide|autojump",
+	Short: "Merge history entries from a file, zoxide or autojump",
+	Long: `Merge history entries from a file into your history: a history.json from
+another machine or the output of pop history list --format json ("-" reads
+stdin). An entry for a path already in history keeps the later access and the
+higher count, so importing a file twice changes nothing.
+
+--from zoxide or --from autojump instead seeds history from that tool's
+database, so the ranking you built there carries over. Each directory it
+knows counts towards the configured project containing it. The scores are
+scaled to at most 5 opens and the projects dated a little over a week back,
+best first, so they keep their order among themselves while anything opened
+in pop in the last day still ranks above them. Projects already in pop's history
+are left as they are.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d := defaultHistoryDeps()
+		if historyImportFrom != "" {
+			if len(args) > 0 {
+				return errors.New("--from takes no file argument")
+			}
+			return runHistoryImportFromWith(d, historyImportFrom)
+		}
+		if len(args) == 0 {
+			return errors.New("import needs a file, or --from zoxide or autojump")
+		}
+		return runHistoryImportWith(d, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd, historyRmCmd, historyClearCmd, historyImportCmd)
+	historyListCmd.Flags().StringVar(&historyListFormat, "format", "plain", "output format: plain or json")
+	historyClearCmd.Flags().BoolVarP(&historyClearYes, "yes", "y", false, "clear without asking")
+	historyImportCmd.Flags().StringVar(&historyImportFrom, "from", "", "seed history from zoxide or autojump instead of a file")
+	historyCmd.AddCommand(historyTopCmd)
+	historyTopCmd.Flags().IntVar(&historyTopDays, "days", 30, "only include projects accessed within this many days (0 = all)")
+	historyCmd.AddCommand(historyPruneCmd)
+	historyPruneCmd.Flags().BoolVar(&historyPruneDryRun, "dry-run", false, "list the entries that would be dropped without saving")
+	historyPruneCmd.Flags().IntVar(&historyPruneLimit, "limit", 0, "most entries to keep (-1 = no limit; default from config)")
+}
+
+// historyTopDeps holds dependencies for pop history top.
+type historyTopDeps struct {
+	LoadHistory func() (*history.History, error)
+	Now         func() time.Time
+	HomeDir     func() (string, error)
+	Stdout      io.Writer
+}
+
+func defaultHistoryTopDeps() *historyTopDeps {
+	return &historyTopDeps{
+		LoadHistory: func() (*history.History, error) {
+			return history.Load(history.DefaultHistoryPath())
+		},
+		Now:     time.Now,
+		HomeDir: os.UserHomeDir,
+		Stdout:  os.Stdout,
+	}
+}
+
+func runHistoryTop(cmd *cobra.Command, args []string) error {
+	return runHistoryTopWith(defaultHistoryTopDeps(), historyTopDays)
+}
+
+// runHistoryTopWith prints the history ranking for the last days days (all of
+// history when days is 0).
+func runHistoryTopWith(d *historyTopDeps, days int) error {
+	if days < 0 {
+		return fmt.Errorf("--days must not be negative, got %d", days)
+	}
+	hist, err := d.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+
+	var since time.Time
+	if days > 0 {
+		since = d.Now().AddDate(0, 0, -days)
+	}
+	top := hist.Top(since)
+	if len(top) == 0 {
+		if days > 0 {
+			fmt.Fprintf(d.Stdout, "No projects accessed in the last %d days.\n", days)
+		} else {
+			fmt.Fprintln(d.Stdout, "No project history yet.")
+		}
+		return nil
+	}
+
+	home, _ := d.HomeDir()
+	w := tabwriter.NewWriter(d.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tCOUNT\tLAST ACCESS\tPROJECT")
+	for i, e := range top {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\n", i+1, e.Count, e.LastAccess.Local().Format("2006-01-02 15:04"), ui.ContractTilde(e.Path, home))
+	}
+	return w.Flush()
+}
+
+// historyPruneDeps holds dependencies for pop history prune.
+type historyPruneDeps struct {
+	LoadHistory func() (*history.History, error)
+	LoadConfig  func() (*config.Config, error)
+	HomeDir     func() (string, error)
+	Stdout      io.Writer
+}
+
+func defaultHistoryPruneDeps() *historyPruneDeps {
+	return &historyPruneDeps{
+		LoadHistory: func() (*history.History, error) {
+			return history.Load(history.DefaultHistoryPath())
+		},
+		LoadConfig: func() (*config.Config, error) {
+			cfgPath := cfgFile
+			if cfgPath == "" {
+				cfgPath = config.DefaultConfigPath()
+			}
+			return config.Load(cfgPath)
+		},
+		HomeDir: os.UserHomeDir,
+		Stdout:  os.Stdout,
+	}
+}
+
+func runHistoryPrune(cmd *cobra.Command, args []string) error {
+	limit := -1
+	if cmd.Flags().Changed("limit") {
+		var err error
+		if limit, err = historyPruneFlagLimit(historyPruneLimit); err != nil {
+			return err
+		}
+	}
+	return runHistoryPruneWith(defaultHistoryPruneDeps(), limit, historyPruneDryRun)
+}
+
+// historyPruneFlagLimit turns --limit, which reads like [project]
+// history_limit with -1 for no limit, into runHistoryPruneWith's limit. 0,
+// which history_limit takes for the default, is rejected rather than read
+// either way.
+func historyPruneFlagLimit(n int) (int, error) {
+	switch {
+	case n == 0:
+		return 0, fmt.Errorf("--limit must be positive, or -1 for no limit")
+	case n < 0:
+		return 0, nil
+	}
+	return n, nil
+}
+
+// runHistoryPruneWith drops history entries for missing directories and those
+// beyond limit, most recently opened kept. A negative limit means the
+// configured one; 0 keeps every remaining entry. With dryRun the dropped
+// entries are listed but history is left as it is.
+func runHistoryPruneWith(d *historyPruneDeps, limit int, dryRun bool) error {
+	if limit < 0 {
+		cfg, err := d.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		limit = cfg.ProjectHistoryLimit()
+	}
+	hist, err := d.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+
+	var missing, excess []history.Entry
+	prune := func(h *history.History) {
+		missing = h.Prune(history.PruneOptions{Missing: true})
+		excess = h.Prune(history.PruneOptions{Limit: limit})
+	}
+	if dryRun {
+		prune(hist)
+	} else if err := hist.Update(prune); err != nil {
+		return fmt.Errorf("save history: %w", err)
+	}
+
+	if len(missing)+len(excess) == 0 {
+		fmt.Fprintf(d.Stdout, "Nothing to prune (%d entries).\n", len(hist.Entries))
+		return nil
+	}
+	home, _ := d.HomeDir()
+	for _, e := range missing {
+		fmt.Fprintf(d.Stdout, "missing  %s\n", ui.ContractTilde(e.Path, home))
+	}
+	for _, e := range excess {
+		fmt.Fprintf(d.Stdout, "excess   %s\n", ui.ContractTilde(e.Path, home))
+	}
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	fmt.Fprintf(d.Stdout, "%s %d entries, %d kept.\n", verb, len(missing)+len(excess), len(hist.Entries))
+	return nil
+}
+
+// historyDeps holds dependencies for pop history list, rm, clear and import.
+type historyDeps struct {
+	LoadHistory func() (*history.History, error)
+	Now         func() time.Time
+	// ResolvePath returns the forms of a command-line path a history entry
+	// may be stored under.
+	ResolvePath func(path string) []string
+	ReadFile    func(path string) ([]byte, error)
+	// Output runs a command and returns what it prints, for reading the
+	// databases of zoxide and autojump.
+	Output func(name string, args ...string) ([]byte, error)
+	// ProjectPaths returns the paths of the projects the picker lists.
+	ProjectPaths func() ([]string, error)
+	Stdin        io.Reader
+	Stdout       io.Writer
+}
+
+func defaultHistoryDeps() *historyDeps {
+	return &historyDeps{
+		LoadHistory: func() (*history.History, error) {
+			return history.Load(history.DefaultHistoryPath())
+		},
+		Now:         time.Now,
+		ResolvePath: historyPathForms,
+		ReadFile:    os.ReadFile,
+		Output: func(name string, args ...string) ([]byte, error) {
+			return exec.Command(name, args...).Output()
+		},
+		ProjectPaths: pickerProjectPaths,
+		Stdin:        os.Stdin,
+		Stdout:       os.Stdout,
+	}
+}
+
+// pickerProjectPaths returns the paths of the projects the picker lists for
+// the current config.
+func pickerProjectPaths() ([]string, error) {
+	d := DefaultProjectDeps()
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	cfg, err := d.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	projects, _, err := collectProjectsWith(d, cfg, cfgPath, &history.History{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(projects))
+	for i, p := range projects {
+		paths[i] = p.Path
+	}
+	return paths, nil
+}
+
+// historyPathForms returns path as given, made absolute, and with symlinks
+// resolved — history stores canonical paths, and non-path entries such as
+// tmux:<session> only match as given.
+func historyPathForms(path string) []string {
+	forms := []string{path}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return forms
+	}
+	forms = append(forms, abs)
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		forms = append(forms, resolved)
+	}
+	return forms
+}
+
+// historyListEntry is one entry in `pop history list --format json` output.
+// Its fields are a superset of history.Entry's, so the output imports back.
+type historyListEntry struct {
+	Path       string     `json:"path"`
+	ID         string     `json:"id,omitempty"`
+	LastAccess time.Time  `json:"last_access"`
+	Count      int        `json:"count"`
+	KilledAt   *time.Time `json:"killed_at,omitempty"`
+	Frecency   float64    `json:"frecency"`
+}
+
+// runHistoryListWith prints every history entry in format, most recently
+// accessed first.
+func runHistoryListWith(d *historyDeps, format string) error {
+	if format != "plain" && format != "json" {
+		return fmt.Errorf("invalid --format %q (want plain or json)", format)
+	}
+	hist, err := d.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+	entries := slices.Clone(hist.Entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastAccess.After(entries[j].LastAccess)
+	})
+
+	if format == "plain" {
+		for _, e := range entries {
+			fmt.Fprintf(d.Stdout, "%s\t%d\t%s\n", e.LastAccess.Local().Format("2006-01-02 15:04"), e.Count, e.Path)
+		}
+		return nil
+	}
+
+	now := d.Now()
+	out := make([]historyListEntry, len(entries))
+	for i, e := range entries {
+		out[i] = historyListEntry{
+			Path:       e.Path,
+			ID:         e.Identity(),
+			LastAccess: e.LastAccess,
+			Count:      e.Count,
+			Frecency:   e.Frecency(now),
+		}
+		if !e.KilledAt.IsZero() {
+			killedAt := e.KilledAt
+			out[i].KilledAt = &killedAt
+		}
+	}
+	enc := json.NewEncoder(d.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+// runHistoryRmWith removes the history entries of paths. Every path must
+// have an entry; when one does not, nothing is removed.
+func runHistoryRmWith(d *historyDeps, paths []string) error {
+	hist, err := d.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+	var missing []string
+	err = hist.Update(func(h *history.History) {
+		missing = nil
+		var remove []string
+		for _, p := range paths {
+			entry, ok := findHistoryEntry(h, d.ResolvePath(p))
+			if !ok {
+				missing = append(missing, p)
+				continue
+			}
+			remove = append(remove, entry)
+		}
+		if len(missing) > 0 {
+			return
+		}
+		for _, p := range remove {
+			h.Remove(p)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("save history: %w", err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no history entry for %s", strings.Join(missing, ", "))
+	}
+	for _, p := range paths {
+		fmt.Fprintf(d.Stdout, "Removed %s\n", p)
+	}
+	return nil
+}
+
+// findHistoryEntry returns the path of h's entry for the first of forms it
+// has one for.
+func findHistoryEntry(h *history.History, forms []string) (string, bool) {
+	for _, form := range forms {
+		for _, e := range h.Entries {
+			if e.Path == form {
+				return e.Path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// runHistoryClearWith removes every history entry, asking first unless yes.
+func runHistoryClearWith(d *historyDeps, yes bool) error {
+	hist, err := d.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+	if len(hist.Entries) == 0 {
+		fmt.Fprintln(d.Stdout, "No project history yet.")
+		return nil
+	}
+	if !yes && !confirm(bufio.NewScanner(d.Stdin), d.Stdout, fmt.Sprintf("Remove all %d history entries?", len(hist.Entries))) {
+		fmt.Fprintln(d.Stdout, "History left unchanged.")
+		return nil
+	}
+	cleared := 0
+	err = hist.Update(func(h *history.History) {
+		cleared = len(h.Entries)
+		h.Entries = nil
+	})
+	if err != nil {
+		return fmt.Errorf("save history: %w", err)
+	}
+	fmt.Fprintf(d.Stdout, "Removed %d history entries.\n", cleared)
+	return nil
+}
+
+// runHistoryImportWith merges the entries in file ("-" for stdin) into
+// history.
+func runHistoryImportWith(d *historyDeps, file string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = io.ReadAll(d.Stdin)
+	} else {
+		data, err = d.ReadFile(file)
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", file, err)
+	}
+	entries, err := parseHistoryImport(data)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", file, err)
+	}
+
+	hist, err := d.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+	added := 0
+	if err := hist.Update(func(h *history.History) { added = h.Merge(entries) }); err != nil {
+		return fmt.Errorf("save history: %w", err)
+	}
+	fmt.Fprintf(d.Stdout, "Imported %d entries: %d new, %d merged.\n", len(entries), added, len(entries)-added)
+	return nil
+}
+
+// parseHistoryImport reads history entries from a history.json file or a
+// pop history list --format json array.
+func parseHistoryImport(data []byte) ([]history.Entry, error) {
+	var entries []history.Entry
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+	} else {
+		var file history.History
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+		entries = file.Entries
+	}
+	for _, e := range entries {
+		if e.Path == "" {
+			return nil, errors.New("entry without a path")
+		}
+	}
+	return entries, nil
+}
+
+// historyImportSources maps the tools pop history import --from reads to the
+// command printing each one's database as "score path" lines.
+var historyImportSources = map[string][]string{
+	"zoxide":   {"zoxide", "query", "--list", "--score"},
+	"autojump": {"autojump", "--stat"},
+}
+
+// historyImportMaxCount is the access count the best-scored project imported
+// by --from gets; the others scale down from it, to at least 1.
+const historyImportMaxCount = 5
+
+// historyImportAge is how long before the import the best-scored project
+// counts as last opened, the others a minute apart below it: old enough for
+// the lowest frecency weight, so anything opened in pop in the last day ranks
+// above them.
+const historyImportAge = 8 * 24 * time.Hour
+
+// runHistoryImportFromWith seeds history from the database of source, zoxide
+// or autojump. Each ranked directory counts towards the deepest project
+// containing it; projects already in history are left alone. Scores become
+// counts and last accesses by historyImportMaxCount and historyImportAge.
+func runHistoryImportFromWith(d *historyDeps, source string) error {
+	argv, ok := historyImportSources[source]
+	if !ok {
+		return fmt.Errorf("invalid --from %q (want zoxide or autojump)", source)
+	}
+	out, err := d.Output(argv[0], argv[1:]...)
+	if err != nil {
+		return fmt.Errorf("run %s: %w", strings.Join(argv, " "), err)
+	}
+	projects, err := d.ProjectPaths()
+	if err != nil {
+		return err
+	}
+
+	scores := make(map[string]float64)
+	var order []string
+	unmatched := 0
+	for _, rp := range parseRankedPaths(out) {
+		p, ok := containingProject(projects, rp.path)
+		if !ok {
+			unmatched++
+			continue
+		}
+		if _, seen := scores[p]; !seen {
+			order = append(order, p)
+		}
+		scores[p] += rp.score
+	}
+
+	hist, err := d.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+	// Best first, so a better score also reads as a later access.
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+	top := 0.0
+	if len(order) > 0 {
+		top = scores[order[0]]
+	}
+	newest := d.Now().Add(-historyImportAge)
+	seeded := 0
+	err = hist.Update(func(h *history.History) {
+		var entries []history.Entry
+		for i, p := range order {
+			if _, ok := findHistoryEntry(h, []string{p}); ok {
+				continue
+			}
+			count := 1
+			if top > 0 {
+				count = max(1, int(math.Round(scores[p]/top*historyImportMaxCount)))
+			}
+			entries = append(entries, history.Entry{
+				Path:       p,
+				LastAccess: newest.Add(-time.Duration(i) * time.Minute),
+				Count:      count,
+			})
+		}
+		seeded = h.Merge(entries)
+	})
+	if err != nil {
+		return fmt.Errorf("save history: %w", err)
+	}
+	fmt.Fprintf(d.Stdout, "Seeded %d projects from %s: %d already in history, %d directories outside any project.\n",
+		seeded, source, len(order)-seeded, unmatched)
+	return nil
+}
+
+// rankedPath is a directory and its score in zoxide's or autojump's
+// database.
+type rankedPath struct {
+	path  string
+	score float64
+}
+
+// parseRankedPaths reads "score path" lines as zoxide query --list --score
+// prints them, or "score:<TAB>path" as autojump --stat does. Lines without a
+// score or an absolute path, such as autojump's totals, are skipped.
+func parseRankedPaths(out []byte) []rankedPath {
+	var ranked []rankedPath
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			continue
+		}
+		score, err := strconv.ParseFloat(strings.TrimSuffix(line[:i], ":"), 64)
+		path := strings.TrimSpace(line[i:])
+		if err != nil || !filepath.IsAbs(path) {
+			continue
+		}
+		ranked = append(ranked, rankedPath{path: filepath.Clean(path), score: score})
+	}
+	return ranked
+}
+
+// containingProject returns the deepest of projects that is path or one of
+// its parents.
+func containingProject(projects []string, path string) (string, bool) {
+	best := ""
+	for _, p := range projects {
+		if (path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/")) && len(p) > len(best) {
+			best = p
+		}
+	}
+	return best, best != ""
+}
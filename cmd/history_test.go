@@ -0,0 +1,443 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+)
+
+func historyTopTestDeps(entries []history.Entry, out *bytes.Buffer) *historyTopDeps {
+	return &historyTopDeps{
+		LoadHistory: func() (*history.History, error) {
+			return &history.History{Entries: entries}, nil
+		},
+		Now:     func() time.Time { return time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC) },
+		HomeDir: func() (string, error) { return "/home/user", nil },
+		Stdout:  out,
+	}
+}
+
+func TestRunHistoryTop(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	entries := []history.Entry{
+		{Path: "/home/user/Dev/rarely", LastAccess: now.Add(-time.Hour), Count: 2},
+		{Path: "/home/user/Dev/daily", LastAccess: now.Add(-2 * time.Hour), Count: 40},
+		{Path: "/srv/old", LastAccess: now.AddDate(0, 0, -45), Count: 90},
+	}
+
+	t.Run("ranks recent projects by count", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runHistoryTopWith(historyTopTestDeps(entries, &out), 30); err != nil {
+			t.Fatalf("runHistoryTopWith() error = %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("got %d lines, want header + 2 rows:\n%s", len(lines), out.String())
+		}
+		if !strings.HasPrefix(lines[0], "#") || !strings.Contains(lines[0], "COUNT") {
+			t.Errorf("header = %q", lines[0])
+		}
+		if !strings.Contains(lines[1], "40") || !strings.HasSuffix(lines[1], "~/Dev/daily") {
+			t.Errorf("row 1 = %q, want ~/Dev/daily with count 40", lines[1])
+		}
+		if !strings.HasSuffix(lines[2], "~/Dev/rarely") {
+			t.Errorf("row 2 = %q, want ~/Dev/rarely", lines[2])
+		}
+	})
+
+	t.Run("days 0 includes all history", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runHistoryTopWith(historyTopTestDeps(entries, &out), 0); err != nil {
+			t.Fatalf("runHistoryTopWith() error = %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 4 || !strings.HasSuffix(lines[1], "/srv/old") {
+			t.Errorf("want /srv/old ranked first of 3 rows, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("empty window prints a note", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runHistoryTopWith(historyTopTestDeps(nil, &out), 7); err != nil {
+			t.Fatalf("runHistoryTopWith() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "No projects accessed in the last 7 days") {
+			t.Errorf("output = %q", out.String())
+		}
+	})
+
+	t.Run("negative days is an error", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runHistoryTopWith(historyTopTestDeps(entries, &out), -1); err == nil {
+			t.Error("expected error for negative --days")
+		}
+	})
+}
+
+func TestRunHistoryPrune(t *testing.T) {
+	dir := t.TempDir()
+	live := filepath.Join(dir, "live")
+	if err := os.Mkdir(live, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	older := filepath.Join(dir, "older")
+	if err := os.Mkdir(older, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	seed := history.History{Entries: []history.Entry{
+		{Path: live, LastAccess: now},
+		{Path: older, LastAccess: now.Add(-time.Hour)},
+		{Path: filepath.Join(dir, "gone"), LastAccess: now},
+		{Path: tmuxSessionPathPrefix + "scratch", LastAccess: now.Add(-time.Minute)},
+	}}
+	data, _ := json.Marshal(seed)
+	histPath := filepath.Join(dir, "history.json")
+
+	run := func(t *testing.T, limit int, dryRun bool) (string, []string) {
+		t.Helper()
+		if err := os.WriteFile(histPath, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		d := &historyPruneDeps{
+			LoadHistory: func() (*history.History, error) { return history.Load(histPath) },
+			LoadConfig: func() (*config.Config, error) {
+				return &config.Config{Project: &config.ProjectConfig{HistoryLimit: 2}}, nil
+			},
+			HomeDir: func() (string, error) { return dir, nil },
+			Stdout:  &out,
+		}
+		if err := runHistoryPruneWith(d, limit, dryRun); err != nil {
+			t.Fatalf("runHistoryPruneWith() error = %v", err)
+		}
+		saved, err := history.Load(histPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, e := range saved.Entries {
+			paths = append(paths, e.Path)
+		}
+		return out.String(), paths
+	}
+
+	t.Run("drops missing paths and applies the configured limit", func(t *testing.T) {
+		out, paths := run(t, -1, false)
+		if want := []string{live, tmuxSessionPathPrefix + "scratch"}; !equalStrings(paths, want) {
+			t.Errorf("kept %v, want %v", paths, want)
+		}
+		for _, want := range []string{"missing  ~/gone", "excess   ~/older", "Pruned 2 entries, 2 kept."} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("limit 0 keeps every existing entry", func(t *testing.T) {
+		_, paths := run(t, 0, false)
+		if len(paths) != 3 {
+			t.Errorf("kept %v, want all but the missing entry", paths)
+		}
+	})
+
+	t.Run("dry run leaves history alone", func(t *testing.T) {
+		out, paths := run(t, -1, true)
+		if len(paths) != 4 {
+			t.Errorf("kept %v, want history unchanged", paths)
+		}
+		if !strings.Contains(out, "Would prune 2 entries") {
+			t.Errorf("output = %q, want a dry-run summary", out)
+		}
+	})
+}
+
+// historyTestDeps returns historyDeps over a history file in a temp dir
+// seeded with entries, and the file's path.
+func historyTestDeps(t *testing.T, entries []history.Entry, stdin string, out *bytes.Buffer) (*historyDeps, string) {
+	t.Helper()
+	histPath := filepath.Join(t.TempDir(), "history.json")
+	data, _ := json.Marshal(history.History{Entries: entries})
+	if err := os.WriteFile(histPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return &historyDeps{
+		LoadHistory: func() (*history.History, error) { return history.Load(histPath) },
+		Now:         func() time.Time { return time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC) },
+		ResolvePath: func(path string) []string { return []string{path} },
+		ReadFile:    os.ReadFile,
+		Stdin:       strings.NewReader(stdin),
+		Stdout:      out,
+	}, histPath
+}
+
+func historyFilePaths(t *testing.T, histPath string) []string {
+	t.Helper()
+	hist, err := history.Load(histPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, e := range hist.Entries {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func TestRunHistoryListWith(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	entries := []history.Entry{
+		{Path: "/src/old", LastAccess: now.AddDate(0, 0, -10), Count: 3},
+		{Path: "/src/api", LastAccess: now.Add(-time.Minute), Count: 7, ID: "origin:abc"},
+	}
+
+	t.Run("plain", func(t *testing.T) {
+		var out bytes.Buffer
+		d, _ := historyTestDeps(t, entries, "", &out)
+		if err := runHistoryListWith(d, "plain"); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 2 || !strings.HasSuffix(lines[0], "\t7\t/src/api") || !strings.HasSuffix(lines[1], "\t3\t/src/old") {
+			t.Errorf("output = %q, want most recent first", out.String())
+		}
+	})
+
+	t.Run("json imports back", func(t *testing.T) {
+		var out bytes.Buffer
+		d, _ := historyTestDeps(t, entries, "", &out)
+		if err := runHistoryListWith(d, "json"); err != nil {
+			t.Fatal(err)
+		}
+		var listed []historyListEntry
+		if err := json.Unmarshal(out.Bytes(), &listed); err != nil {
+			t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+		}
+		if len(listed) != 2 || listed[0].Path != "/src/api" || listed[0].ID != "origin:abc" || listed[0].Frecency != 7*4 {
+			t.Errorf("listed = %+v", listed)
+		}
+		imported, err := parseHistoryImport(out.Bytes())
+		if err != nil || len(imported) != 2 || imported[0].Count != 7 {
+			t.Errorf("parseHistoryImport(list output) = %+v, %v", imported, err)
+		}
+	})
+
+	t.Run("bad format", func(t *testing.T) {
+		var out bytes.Buffer
+		d, _ := historyTestDeps(t, entries, "", &out)
+		if err := runHistoryListWith(d, "yaml"); err == nil {
+			t.Error("expected an error for an unknown format")
+		}
+	})
+}
+
+func TestRunHistoryRmWith(t *testing.T) {
+	entries := []history.Entry{{Path: "/src/api"}, {Path: "/src/web"}, {Path: "tmux:scratch"}}
+
+	t.Run("removes the entries", func(t *testing.T) {
+		var out bytes.Buffer
+		d, histPath := historyTestDeps(t, entries, "", &out)
+		if err := runHistoryRmWith(d, []string{"/src/api", "tmux:scratch"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := historyFilePaths(t, histPath); !equalStrings(got, []string{"/src/web"}) {
+			t.Errorf("history = %v, want only /src/web", got)
+		}
+	})
+
+	t.Run("unknown path removes nothing", func(t *testing.T) {
+		var out bytes.Buffer
+		d, histPath := historyTestDeps(t, entries, "", &out)
+		err := runHistoryRmWith(d, []string{"/src/api", "/nowhere"})
+		if err == nil || !strings.Contains(err.Error(), "/nowhere") {
+			t.Fatalf("error = %v, want one naming /nowhere", err)
+		}
+		if got := historyFilePaths(t, histPath); len(got) != 3 {
+			t.Errorf("history = %v, want it unchanged", got)
+		}
+	})
+}
+
+func TestHistoryPathForms(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "real")
+	if err := os.Mkdir(real, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatal(err)
+	}
+	resolvedReal, _ := filepath.EvalSymlinks(real)
+	forms := historyPathForms(link)
+	if forms[0] != link || forms[len(forms)-1] != resolvedReal {
+		t.Errorf("historyPathForms(%s) = %v, want it as given through the resolved path", link, forms)
+	}
+}
+
+func TestRunHistoryClearWith(t *testing.T) {
+	entries := []history.Entry{{Path: "/src/api"}, {Path: "/src/web"}}
+
+	t.Run("declined", func(t *testing.T) {
+		var out bytes.Buffer
+		d, histPath := historyTestDeps(t, entries, "n\n", &out)
+		if err := runHistoryClearWith(d, false); err != nil {
+			t.Fatal(err)
+		}
+		if got := historyFilePaths(t, histPath); len(got) != 2 {
+			t.Errorf("history = %v, want it unchanged", got)
+		}
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		var out bytes.Buffer
+		d, histPath := historyTestDeps(t, entries, "y\n", &out)
+		if err := runHistoryClearWith(d, false); err != nil {
+			t.Fatal(err)
+		}
+		if got := historyFilePaths(t, histPath); len(got) != 0 {
+			t.Errorf("history = %v, want it empty", got)
+		}
+	})
+
+	t.Run("yes skips the question", func(t *testing.T) {
+		var out bytes.Buffer
+		d, histPath := historyTestDeps(t, entries, "", &out)
+		if err := runHistoryClearWith(d, true); err != nil {
+			t.Fatal(err)
+		}
+		if got := historyFilePaths(t, histPath); len(got) != 0 {
+			t.Errorf("history = %v, want it empty", got)
+		}
+		if strings.Contains(out.String(), "[y/N]") {
+			t.Errorf("asked despite --yes: %s", out.String())
+		}
+	})
+}
+
+func TestRunHistoryImportWith(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	d, histPath := historyTestDeps(t, []history.Entry{{Path: "/src/api", LastAccess: now.Add(-time.Hour), Count: 2}}, "", &out)
+
+	imported := history.History{Entries: []history.Entry{
+		{Path: "/src/api", LastAccess: now, Count: 5},
+		{Path: "/src/web", LastAccess: now, Count: 1},
+	}}
+	data, _ := json.Marshal(imported)
+	file := filepath.Join(t.TempDir(), "other.json")
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := runHistoryImportWith(d, file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hist, _ := history.Load(histPath)
+	if len(hist.Entries) != 2 {
+		t.Fatalf("entries = %+v, want /src/api and /src/web", hist.Entries)
+	}
+	api := hist.Entries[0]
+	if api.Path != "/src/api" || api.Count != 5 || !api.LastAccess.Equal(now) {
+		t.Errorf("/src/api = %+v, want the later access and higher count", api)
+	}
+	if !strings.Contains(out.String(), "1 new, 1 merged") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	if _, err := parseHistoryImport([]byte(`[{"last_access": "2026-03-31T12:00:00Z"}]`)); err == nil {
+		t.Error("expected an error for an entry without a path")
+	}
+}
+
+func TestParseRankedPaths(t *testing.T) {
+	zoxide := "  52.5 /home/me/src/api\n   4.0 /home/me/my notes\n"
+	autojump := "10.0:\t/home/me/src/web\n________________________________________\n\n120:\t total weight\n2:\t number of entries\ndata:\t /home/me/.local/share/autojump/autojump.txt\n"
+
+	got := parseRankedPaths([]byte(zoxide + autojump))
+	want := []rankedPath{
+		{path: "/home/me/src/api", score: 52.5},
+		{path: "/home/me/my notes", score: 4},
+		{path: "/home/me/src/web", score: 10},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseRankedPaths() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunHistoryImportFromWith(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	d, histPath := historyTestDeps(t, []history.Entry{{Path: "/src/web", LastAccess: now.Add(-time.Hour), Count: 2}}, "", &out)
+	var ran string
+	d.Output = func(name string, args ...string) ([]byte, error) {
+		ran = strings.Join(append([]string{name}, args...), " ")
+		return []byte("  30.0 /src/api\n  12.4 /src/api/internal/db\n   8.0 /src/web\n   5.0 /tmp\n   1.0 /src/api-old\n"), nil
+	}
+	d.ProjectPaths = func() ([]string, error) {
+		return []string{"/src/api", "/src/web", "/src/api/internal"}, nil
+	}
+
+	if err := runHistoryImportFromWith(d, "zoxide"); err != nil {
+		t.Fatal(err)
+	}
+	if ran != "zoxide query --list --score" {
+		t.Errorf("ran %q", ran)
+	}
+	hist, _ := history.Load(histPath)
+	counts := make(map[string]int)
+	accesses := make(map[string]time.Time)
+	for _, e := range hist.Entries {
+		counts[e.Path] = e.Count
+		accesses[e.Path] = e.LastAccess
+	}
+	// Scaled to the best score; web was already in history.
+	want := map[string]int{"/src/web": 2, "/src/api": historyImportMaxCount, "/src/api/internal": 2}
+	if !maps.Equal(counts, want) {
+		t.Errorf("history counts = %v, want %v", counts, want)
+	}
+	if api, internal := accesses["/src/api"], accesses["/src/api/internal"]; !api.Equal(now.Add(-historyImportAge)) || !internal.Before(api) {
+		t.Errorf("last accesses api %v, internal %v; want the best score latest, %v before now", api, internal, historyImportAge)
+	}
+	// Imported projects rank below one opened in pop in the last day.
+	day := history.FrecencyScore(1, now.Add(-23*time.Hour), now)
+	if got := history.FrecencyScore(counts["/src/api"], accesses["/src/api"], now); got >= day {
+		t.Errorf("imported frecency %v, want below %v", got, day)
+	}
+	if !strings.Contains(out.String(), "Seeded 2 projects from zoxide: 1 already in history, 2 directories outside any project.") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	if err := runHistoryImportFromWith(d, "fasd"); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
+
+func TestHistoryPruneFlagLimit(t *testing.T) {
+	tests := []struct {
+		flag    int
+		want    int
+		wantErr bool
+	}{
+		{flag: 10, want: 10},
+		{flag: -1, want: 0}, // no limit, as history_limit = -1
+		{flag: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := historyPruneFlagLimit(tt.flag)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("historyPruneFlagLimit(%d) = (%d, %v), want (%d, error %v)", tt.flag, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/events"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+// sessionHooks runs the [hooks] commands and publishes the matching events
+// (see the events package), running their [events] commands. Hooks are side
+// effects the user bolted on (direnv, window titles, usage logs), so a failing
+// one is reported and logged but never aborts the action it is attached to.
+type sessionHooks struct {
+	cfg *config.Config
+	// Run executes a hook command in dir with env appended to the
+	// environment; runPipelineCommand in production.
+	Run func(command, dir string, env ...string) error
+	// Bus receives the published events; nil means events.Default.
+	Bus *events.Bus
+}
+
+// hookEvents maps the [hooks] events that have an event-bus counterpart.
+var hookEvents = map[string]events.Kind{
+	config.HookOnCreate: events.SessionCreated,
+	config.HookOnKill:   events.SessionKilled,
+	config.HookOnSelect: events.ProjectSelected,
+}
+
+// fire runs the command configured for event, if any, then emits its
+// event-bus counterpart. path is "" when the session has no known directory
+// (a standalone session); the hook then runs in pop's working directory.
+func (h *sessionHooks) fire(event, sessionName, path string) {
+	if command := h.cfg.HookCommand(event); command != "" {
+		h.run(event+" hook", command, sessionName, path,
+			"POP_HOOK="+event,
+			"POP_SESSION_NAME="+sessionName,
+			"POP_PROJECT_PATH="+path,
+		)
+	}
+	if kind, ok := hookEvents[event]; ok {
+		h.emit(kind, sessionName, path)
+	}
+}
+
+// emit runs the [events] command for kind, if any, and publishes the event to
+// the bus.
+func (h *sessionHooks) emit(kind events.Kind, sessionName, path string) {
+	e := events.Event{Kind: kind, SessionName: sessionName, Path: path}
+	if command := h.cfg.EventCommand(string(kind)); command != "" {
+		h.run(string(kind)+" event", command, sessionName, path, e.Env()...)
+	}
+	h.bus().Publish(e)
+}
+
+func (h *sessionHooks) run(what, command, sessionName, path string, env ...string) {
+	if h.Run == nil {
+		return
+	}
+	if err := h.Run(command, path, env...); err != nil {
+		debug.Error("hooks: %s %q for %s: %v", what, command, sessionName, err)
+		ui.Notify(ui.LevelError, "%s failed: %v", what, err)
+	}
+}
+
+func (h *sessionHooks) bus() *events.Bus {
+	if h.Bus != nil {
+		return h.Bus
+	}
+	return events.Default
+}
+
+// configured reports whether any session-lifecycle hook, event command or
+// event subscriber is set, i.e. whether tmux needs wrapping at all.
+func (h *sessionHooks) configured() bool {
+	return h.cfg.HookCommand(config.HookOnCreate) != "" ||
+		h.cfg.HookCommand(config.HookOnSwitch) != "" ||
+		h.cfg.HookCommand(config.HookOnKill) != "" ||
+		h.cfg.EventCommand(string(events.SessionCreated)) != "" ||
+		h.cfg.EventCommand(string(events.SessionKilled)) != "" ||
+		h.bus().HasSubscribers(events.SessionCreated) ||
+		h.bus().HasSubscribers(events.SessionKilled)
+}
+
+// hooksTmux fires the on_create, on_switch and on_kill hooks (and the
+// session_created and session_killed events) around the tmux
+// calls that create, switch to and kill sessions, whichever path makes them:
+// flat sessions go through NewSession, Workbench sessions through a raw
+// new-session, the dashboard-style switch-and-zoom through a raw
+// switch-client. on_switch runs before the switch since attaching from
+// outside tmux blocks until the client detaches, and switching from a popup
+// may close it (see popupTmux), ending pop.
+type hooksTmux struct {
+	deps.Tmux
+	hooks *sessionHooks
+}
+
+// withSessionHooks wraps tmux in hooksTmux when a lifecycle hook, event
+// command or event subscriber is configured.
+func withSessionHooks(tmux deps.Tmux, hooks *sessionHooks) deps.Tmux {
+	if !hooks.configured() {
+		return tmux
+	}
+	return hooksTmux{Tmux: tmux, hooks: hooks}
+}
+
+func (t hooksTmux) NewSession(name, dir string) error {
+	if err := t.Tmux.NewSession(name, dir); err != nil {
+		return err
+	}
+	t.hooks.fire(config.HookOnCreate, name, dir)
+	return nil
+}
+
+func (t hooksTmux) SwitchClient(name string) error {
+	t.fireFor(config.HookOnSwitch, name)
+	return t.Tmux.SwitchClient(name)
+}
+
+func (t hooksTmux) AttachSession(name string) error {
+	t.fireFor(config.HookOnSwitch, name)
+	return t.Tmux.AttachSession(name)
+}
+
+func (t hooksTmux) AttachSessionDetachOthers(name string) error {
+	t.fireFor(config.HookOnSwitch, name)
+	return t.Tmux.AttachSessionDetachOthers(name)
+}
+
+func (t hooksTmux) KillSession(name string) error {
+	sessionName, path := t.sessionInfo(name)
+	if err := t.Tmux.KillSession(name); err != nil {
+		return err
+	}
+	t.hooks.fire(config.HookOnKill, sessionName, path)
+	return nil
+}
+
+func (t hooksTmux) Command(args ...string) (string, error) {
+	if len(args) == 0 {
+		return t.Tmux.Command(args...)
+	}
+	switch args[0] {
+	case "new-session":
+		out, err := t.Tmux.Command(args...)
+		if err == nil {
+			t.hooks.fire(config.HookOnCreate, flagValue(args, "-s"), flagValue(args, "-c"))
+		}
+		return out, err
+	case "switch-client":
+		t.fireFor(config.HookOnSwitch, flagValue(args, "-t"))
+	case "kill-session":
+		sessionName, path := t.sessionInfo(flagValue(args, "-t"))
+		out, err := t.Tmux.Command(args...)
+		if err == nil {
+			t.hooks.fire(config.HookOnKill, sessionName, path)
+		}
+		return out, err
+	}
+	return t.Tmux.Command(args...)
+}
+
+// fireFor fires event for the session behind target (a session name or a pane
+// ID).
+func (t hooksTmux) fireFor(event, target string) {
+	sessionName, path := t.sessionInfo(target)
+	t.hooks.fire(event, sessionName, path)
+}
+
+// sessionInfo resolves target to its session name and start directory. On
+// failure it falls back to target itself with no directory, so a hook still
+// runs with what is known.
+func (t hooksTmux) sessionInfo(target string) (string, string) {
+	out, err := t.Tmux.Command("display-message", "-p", "-t", target, "#{session_name}\t#{session_path}")
+	if err != nil {
+		debug.Error("hooks: resolve session %s: %v", target, err)
+		return strings.TrimPrefix(target, "="), ""
+	}
+	name, path, _ := strings.Cut(out, "\t")
+	return name, path
+}
+
+// flagValue returns the argument following flag in a tmux command line, or ""
+// when the flag is absent.
+func flagValue(args []string, flag string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == ";" {
+			break
+		}
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/events"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+// hookCall is one recorded hook run.
+type hookCall struct {
+	command string
+	dir     string
+	env     string
+}
+
+func recordingHooks(cfg *config.Config, calls *[]hookCall) *sessionHooks {
+	return &sessionHooks{cfg: cfg, Run: func(command, dir string, env ...string) error {
+		*calls = append(*calls, hookCall{command: command, dir: dir, env: strings.Join(env, " ")})
+		return nil
+	}}
+}
+
+func TestHooksTmux(t *testing.T) {
+	cfg := &config.Config{Hooks: &config.HooksConfig{
+		OnCreate: "echo created",
+		OnSwitch: "echo switched",
+		OnKill:   "echo killed",
+	}}
+	inner := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			if args[0] == "display-message" {
+				return "api\t/src/api", nil
+			}
+			return "", nil
+		},
+	}
+
+	t.Run("flat create", func(t *testing.T) {
+		var calls []hookCall
+		tmux := withSessionHooks(inner, recordingHooks(cfg, &calls))
+		if err := tmux.NewSession("api", "/src/api"); err != nil {
+			t.Fatal(err)
+		}
+		if len(calls) != 1 || calls[0].command != "echo created" || calls[0].dir != "/src/api" {
+			t.Fatalf("calls = %+v, want on_create in /src/api", calls)
+		}
+		for _, want := range []string{"POP_HOOK=on_create", "POP_SESSION_NAME=api", "POP_PROJECT_PATH=/src/api"} {
+			if !strings.Contains(calls[0].env, want) {
+				t.Errorf("env %q missing %s", calls[0].env, want)
+			}
+		}
+	})
+
+	t.Run("workbench create via raw new-session", func(t *testing.T) {
+		var calls []hookCall
+		tmux := withSessionHooks(inner, recordingHooks(cfg, &calls))
+		if _, err := tmux.Command("new-session", "-d", "-s", "web", "-c", "/src/web", "-P", "-F", "#{window_id}"); err != nil {
+			t.Fatal(err)
+		}
+		if len(calls) != 1 || !strings.Contains(calls[0].env, "POP_SESSION_NAME=web") || calls[0].dir != "/src/web" {
+			t.Errorf("calls = %+v, want on_create for web", calls)
+		}
+	})
+
+	t.Run("switch resolves the session and runs first", func(t *testing.T) {
+		var order []string
+		hooks := &sessionHooks{cfg: cfg, Run: func(command, dir string, env ...string) error {
+			order = append(order, command)
+			return nil
+		}}
+		tmux := withSessionHooks(&deps.MockTmux{
+			CommandFunc: inner.CommandFunc,
+			SwitchClientFunc: func(name string) error {
+				order = append(order, "switch-client")
+				return nil
+			},
+		}, hooks)
+		if err := tmux.SwitchClient("api"); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(order, ",") != "echo switched,switch-client" {
+			t.Errorf("order = %v, want hook before switch-client", order)
+		}
+	})
+
+	t.Run("failed kill fires nothing", func(t *testing.T) {
+		var calls []hookCall
+		tmux := withSessionHooks(&deps.MockTmux{
+			CommandFunc: func(args ...string) (string, error) {
+				if args[0] == "kill-session" {
+					return "", errors.New("can't find session")
+				}
+				return inner.CommandFunc(args...)
+			},
+		}, recordingHooks(cfg, &calls))
+		if _, err := tmux.Command("kill-session", "-t", "api"); err == nil {
+			t.Fatal("expected kill error")
+		}
+		if len(calls) != 0 {
+			t.Errorf("calls = %+v, want none after a failed kill", calls)
+		}
+	})
+}
+
+func TestWithSessionHooks_Unconfigured(t *testing.T) {
+	inner := &deps.MockTmux{}
+	cfg := &config.Config{Hooks: &config.HooksConfig{OnSelect: "echo picked"}}
+	if withSessionHooks(inner, &sessionHooks{cfg: cfg}) != deps.Tmux(inner) {
+		t.Error("only on_select set: tmux should not be wrapped")
+	}
+	if withSessionHooks(inner, &sessionHooks{}) != deps.Tmux(inner) {
+		t.Error("no config: tmux should not be wrapped")
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	args := []string{"switch-client", "-t", "%5", ";", "select-pane", "-t", "%6"}
+	if got := flagValue(args, "-t"); got != "%5" {
+		t.Errorf("flagValue(-t) = %q, want %%5", got)
+	}
+	if got := flagValue(args, "-c"); got != "" {
+		t.Errorf("flagValue(-c) = %q, want empty", got)
+	}
+}
+
+func TestRunProject_OnSelectHook(t *testing.T) {
+	d := testProjectDeps(t)
+	load := d.LoadConfig
+	d.LoadConfig = func() (*config.Config, error) {
+		cfg, err := load()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Hooks = &config.HooksConfig{OnSelect: "log-usage"}
+		return cfg, nil
+	}
+	var calls []hookCall
+	d.RunHook = func(command, dir string, env ...string) error {
+		calls = append(calls, hookCall{command: command, dir: dir, env: strings.Join(env, " ")})
+		return nil
+	}
+	var selected string
+	d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+		selected = items[0].Path
+		return ui.Result{Action: ui.ActionConfirm, Selected: &items[0]}
+	})
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if len(calls) != 1 || calls[0].command != "log-usage" || calls[0].dir != selected {
+		t.Errorf("calls = %+v, want on_select in %s", calls, selected)
+	}
+}
+
+func TestRunProject_OnSelectHookFiresForEachMarked(t *testing.T) {
+	for _, action := range []ui.Action{ui.ActionConfirm, ui.ActionOpenWindow} {
+		t.Run(action.String(), func(t *testing.T) {
+			d := testProjectDeps(t)
+			d.InTmux = func() bool { return true }
+			load := d.LoadConfig
+			d.LoadConfig = func() (*config.Config, error) {
+				cfg, err := load()
+				if err != nil {
+					return nil, err
+				}
+				cfg.Hooks = &config.HooksConfig{OnSelect: "log-usage"}
+				return cfg, nil
+			}
+			var dirs []string
+			d.RunHook = func(command, dir string, env ...string) error {
+				dirs = append(dirs, dir)
+				return nil
+			}
+			d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+				marked := []ui.Item{
+					{Name: "api", Path: "/src/api", SessionName: "api"},
+					{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"},
+					{Name: "web", Path: "/src/web", SessionName: "web"},
+				}
+				return ui.Result{Action: action, Selected: &marked[0], Marked: marked}, nil
+			}
+			d.OpenWindow = func(tmux deps.Tmux, item *ui.Item) error { return nil }
+
+			if err := RunProject(d); err != nil {
+				t.Fatalf("RunProject: %v", err)
+			}
+			if !equalStrings(dirs, []string{"/src/api", "/src/web"}) {
+				t.Errorf("on_select ran in %v, want once per marked project", dirs)
+			}
+		})
+	}
+}
+
+func TestSessionHooks_Events(t *testing.T) {
+	cfg := &config.Config{Events: &config.EventsConfig{SessionKilled: "track stop"}}
+	inner := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			if args[0] == "display-message" {
+				return "api\t/src/api", nil
+			}
+			return "", nil
+		},
+	}
+	var calls []hookCall
+	hooks := recordingHooks(cfg, &calls)
+	hooks.Bus = &events.Bus{}
+	var published []events.Event
+	hooks.Bus.SubscribeAll(func(e events.Event) { published = append(published, e) })
+
+	tmux := withSessionHooks(inner, hooks)
+	if err := tmux.NewSession("web", "/src/web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmux.KillSession("api"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 1 || calls[0].command != "track stop" || calls[0].dir != "/src/api" {
+		t.Fatalf("calls = %+v, want the session_killed command in /src/api", calls)
+	}
+	for _, want := range []string{"POP_EVENT=session_killed", "POP_SESSION_NAME=api", "POP_PROJECT_PATH=/src/api"} {
+		if !strings.Contains(calls[0].env, want) {
+			t.Errorf("env %q missing %s", calls[0].env, want)
+		}
+	}
+	if len(published) != 2 ||
+		published[0].Kind != events.SessionCreated || published[0].SessionName != "web" ||
+		published[1].Kind != events.SessionKilled || published[1].Path != "/src/api" {
+		t.Errorf("published = %+v, want session_created for web then session_killed for api", published)
+	}
+}
+
+func TestWithSessionHooks_WrapsForSubscribers(t *testing.T) {
+	inner := &deps.MockTmux{}
+	bus := &events.Bus{}
+	hooks := &sessionHooks{cfg: &config.Config{}, Bus: bus}
+	if got := withSessionHooks(inner, hooks); got != deps.Tmux(inner) {
+		t.Fatalf("withSessionHooks with no subscribers wrapped tmux: %T", got)
+	}
+	bus.Subscribe(events.SessionCreated, func(events.Event) {})
+	if _, ok := withSessionHooks(inner, hooks).(hooksTmux); !ok {
+		t.Error("withSessionHooks should wrap tmux for a session_created subscriber")
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+// itemMetadata is a picker item as user-defined commands receive it in
+// POP_ITEM_JSON (and, one per marked item, in POP_ITEMS_JSON), for scripts
+// that would rather parse JSON than a handful of POP_* variables.
+type itemMetadata struct {
+	Name        string   `json:"name"`
+	Path        string   `json:"path"`
+	Context     string   `json:"context"`
+	Branch      string   `json:"branch"`
+	IsWorktree  bool     `json:"is_worktree"`
+	SessionName string   `json:"session_name"`
+	RepoRoot    string   `json:"repo_root"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// projectItemMetadata describes a project picker item. The worktree fields
+// come from the expanded project at the item's path, when there is one.
+func projectItemMetadata(item *ui.Item, projects map[string]project.ExpandedProject) itemMetadata {
+	m := itemMetadata{
+		Name:        item.Name,
+		Path:        item.Path,
+		Context:     item.Context,
+		SessionName: item.SessionName,
+		Tags:        item.Tags,
+	}
+	if ep, ok := projects[item.Path]; ok {
+		m.IsWorktree = ep.IsWorktree
+		m.Branch = ep.Branch
+		m.RepoRoot = ep.RepoRoot
+	}
+	return m
+}
+
+// worktreeItemMetadata describes a worktree picker item of the repo in ctx.
+func worktreeItemMetadata(item *ui.Item, ctx *project.RepoContext) itemMetadata {
+	return itemMetadata{
+		Name:        filepath.Base(item.Path),
+		Path:        item.Path,
+		Context:     item.Context,
+		Branch:      item.Context,
+		IsWorktree:  true,
+		SessionName: project.TmuxSessionName(ctx, item.Name),
+		RepoRoot:    ctx.GitRoot,
+	}
+}
+
+// itemJSONEnv returns the POP_ITEM_JSON variable for m.
+func itemJSONEnv(m itemMetadata) string {
+	// Marshalling strings, a bool and a string slice cannot fail.
+	b, _ := json.Marshal(m)
+	return "POP_ITEM_JSON=" + string(b)
+}
+
+// itemsJSONEnv returns the POP_ITEMS_JSON variable, a JSON array of ms, for
+// multi user-defined commands.
+func itemsJSONEnv(ms []itemMetadata) string {
+	if ms == nil {
+		ms = []itemMetadata{}
+	}
+	b, _ := json.Marshal(ms)
+	return "POP_ITEMS_JSON=" + string(b)
+}
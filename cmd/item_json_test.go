@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestProjectItemMetadata(t *testing.T) {
+	projects := map[string]project.ExpandedProject{
+		"/dev/repo/feature": {Path: "/dev/repo/feature", IsWorktree: true, Branch: "feat/x", RepoRoot: "/dev/repo"},
+	}
+
+	got := projectItemMetadata(&ui.Item{Name: "repo/feature", Path: "/dev/repo/feature", Context: "repo", SessionName: "repo/feature", Tags: []string{"work"}}, projects)
+	want := itemMetadata{Name: "repo/feature", Path: "/dev/repo/feature", Context: "repo", Branch: "feat/x", IsWorktree: true, SessionName: "repo/feature", RepoRoot: "/dev/repo", Tags: []string{"work"}}
+	if got.Name != want.Name || got.Branch != want.Branch || !got.IsWorktree || got.RepoRoot != want.RepoRoot || got.SessionName != want.SessionName || !equalStrings(got.Tags, want.Tags) {
+		t.Errorf("projectItemMetadata = %+v, want %+v", got, want)
+	}
+
+	// A standalone session item has no expanded project behind it.
+	got = projectItemMetadata(&ui.Item{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"}, projects)
+	if got.IsWorktree || got.Branch != "" || got.RepoRoot != "" {
+		t.Errorf("standalone metadata = %+v, want no worktree fields", got)
+	}
+}
+
+func TestWorktreeItemMetadata(t *testing.T) {
+	ctx := &project.RepoContext{RepoName: "repo", GitRoot: "/dev/repo", IsBare: true}
+	got := worktreeItemMetadata(&ui.Item{Name: "feature", Path: "/dev/repo/feature", Context: "feat/x"}, ctx)
+	if got.Name != "feature" || got.Branch != "feat/x" || !got.IsWorktree || got.RepoRoot != "/dev/repo" {
+		t.Errorf("worktreeItemMetadata = %+v", got)
+	}
+	if want := project.TmuxSessionName(ctx, "feature"); got.SessionName != want {
+		t.Errorf("SessionName = %q, want %q", got.SessionName, want)
+	}
+}
+
+func TestItemsJSONEnv(t *testing.T) {
+	env := itemsJSONEnv(nil)
+	if env != "POP_ITEMS_JSON=[]" {
+		t.Errorf("itemsJSONEnv(nil) = %q, want an empty array", env)
+	}
+
+	env = itemsJSONEnv([]itemMetadata{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}})
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(env, "POP_ITEMS_JSON=")), &got); err != nil {
+		t.Fatalf("POP_ITEMS_JSON is not JSON: %v", err)
+	}
+	if len(got) != 2 || got[1]["path"] != "/b" || got[0]["is_worktree"] != false {
+		t.Errorf("POP_ITEMS_JSON = %v", got)
+	}
+	if _, ok := got[0]["tags"]; ok {
+		t.Error("empty tags should be omitted")
+	}
+}
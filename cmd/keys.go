@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+var keysFormat string
+
+var keysCmd = &cobra.Command{
+	Use:   "keys [project|worktree]",
+	Short: "Print the effective picker keybindings",
+	Long: `Print the keys a picker mode binds, as its help overlay (C-h) lists them: the
+built-in keys, minus any a user-defined command takes over, followed by the
+user-defined commands from [[commands]] and the mode's own section, with the
+built-ins at the keys [keys] remaps them to. The mode defaults to project;
+select is accepted as an alias.
+
+--format plain (default) prints an aligned two-column table. --format markdown
+prints a Markdown table, ready to paste into notes.
+
+The open-in-window key (C-o) is listed although the picker only binds it
+inside tmux.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"project", "worktree"},
+	RunE:      runKeys,
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.Flags().StringVar(&keysFormat, "format", "plain", "output format: plain or markdown")
+}
+
+func runKeys(cmd *cobra.Command, args []string) error {
+	mode := "project"
+	if len(args) > 0 {
+		mode = args[0]
+	}
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		// Without a config the built-in table still applies.
+		cfg = &config.Config{}
+	}
+	return runKeysWith(cfg, mode, keysFormat, os.Stdout)
+}
+
+// runKeysWith prints the key table of the picker for mode to w in format.
+func runKeysWith(cfg *config.Config, mode, format string, w io.Writer) error {
+	if format != "plain" && format != "markdown" {
+		return fmt.Errorf("invalid --format %q (want plain or markdown)", format)
+	}
+
+	var opts []ui.PickerOption
+	switch mode {
+	case "project", "select":
+		opts = projectPickerKeys(cfg.QuickAccessModifierForMode("project"), cfg.KeyBindingsForPicker(), true, pickerCommands(cfg, "project"))
+	case "worktree":
+		opts = append(worktreePickerKeys(cfg.QuickAccessModifierForMode("worktree"), cfg.KeyBindingsForPicker(), pickerCommands(cfg, "worktree")),
+			ui.WithSortModes("", ui.SortMode{Name: worktreeSortRecent}, ui.SortMode{Name: worktreeSortActivity}))
+	default:
+		return fmt.Errorf("unknown mode %q (want project or worktree)", mode)
+	}
+	entries := append(ui.HelpEntries(opts...), ui.HelpEntry{Key: ui.HelpToggleKey(opts...), Desc: "Toggle help"})
+
+	if format == "markdown" {
+		fmt.Fprintln(w, "| Key | Action |")
+		fmt.Fprintln(w, "| --- | --- |")
+		for _, e := range entries {
+			fmt.Fprintf(w, "| `%s` | %s |\n", markdownCell(e.Key), markdownCell(e.Desc))
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tACTION")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\n", e.Key, e.Desc)
+	}
+	return tw.Flush()
+}
+
+// markdownCell escapes the pipes that would split a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// pickerCommands converts the user-defined commands configured for mode into
+// picker bindings.
+func pickerCommands(cfg *config.Config, mode string) []ui.UserDefinedCommand {
+	var commands []ui.UserDefinedCommand
+	for _, cc := range cfg.CommandsForMode(mode) {
+		commands = append(commands, ui.UserDefinedCommand{
+			Key:     cc.Key,
+			Label:   cc.Label,
+			Hint:    cc.Hint,
+			HintBar: cc.HintBar,
+			Command: cc.Command,
+			Exit:    cc.Exit,
+			Multi:   cc.Multi,
+		})
+	}
+	return commands
+}
+
+// projectPickerKeys returns the options that decide which keys the project
+// picker binds, with keyBindings ([keys]) remapping the built-ins. The picker
+// and pop keys share them so the printed table can't drift from the real one.
+func projectPickerKeys(quickAccessModifier string, keyBindings map[string]string, inTmux bool, customCommands []ui.UserDefinedCommand) []ui.PickerOption {
+	opts := []ui.PickerOption{
+		ui.WithKillSession(),
+		ui.WithReset(),
+		ui.WithSetPreferredWorkbench(),
+		ui.WithBrowseWorktrees(),
+		ui.WithOpenEditor(),
+		ui.WithRename(standaloneSessionRename),
+		ui.WithQuickAccess(quickAccessModifier),
+		ui.WithSessionFilter(),
+		ui.WithMultiSelect(),
+	}
+	if inTmux {
+		opts = append(opts, ui.WithOpenWindow())
+	}
+	if len(keyBindings) > 0 {
+		opts = append(opts, ui.WithKeyBindings(keyBindings))
+	}
+	if len(customCommands) > 0 {
+		opts = append(opts, ui.WithUserDefinedCommands(customCommands))
+	}
+	return opts
+}
+
+// worktreePickerKeys is projectPickerKeys for the worktree picker. The sort
+// key depends on git reporting branch activity, so callers add it.
+func worktreePickerKeys(quickAccessModifier string, keyBindings map[string]string, customCommands []ui.UserDefinedCommand) []ui.PickerOption {
+	opts := []ui.PickerOption{
+		ui.WithDelete(),
+		ui.WithKillSession(),
+		ui.WithReset(),
+		ui.WithCreateWorktree(),
+		ui.WithSetPreferredWorkbench(),
+		ui.WithQuickAccess(quickAccessModifier),
+		ui.WithSessionFilter(),
+	}
+	if len(keyBindings) > 0 {
+		opts = append(opts, ui.WithKeyBindings(keyBindings))
+	}
+	if len(customCommands) > 0 {
+		opts = append(opts, ui.WithUserDefinedCommands(customCommands))
+	}
+	return opts
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+)
+
+func TestRunKeysWith(t *testing.T) {
+	cfg := &config.Config{
+		Commands: []config.UserDefinedCommand{
+			{Key: "ctrl+k", Label: "Open in editor", Command: "$EDITOR ."},
+			{Key: "ctrl+e", Label: "Run tests", Command: "make test"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := runKeysWith(cfg, "project", "plain", &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"KEY", "C-r", "Reset history", "C-o", "C-e", "Run tests", "C-h"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("plain output missing %q:\n%s", want, out)
+		}
+	}
+	// ctrl+k is taken over by the user-defined command.
+	if strings.Contains(out, "Kill tmux session") {
+		t.Errorf("plain output lists the overridden kill key:\n%s", out)
+	}
+	if !strings.Contains(out, "Open in editor") {
+		t.Errorf("plain output missing the overriding command:\n%s", out)
+	}
+}
+
+func TestRunKeysWith_WorktreeMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := runKeysWith(&config.Config{}, "worktree", "markdown", &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "| Key | Action |" || lines[1] != "| --- | --- |" {
+		t.Fatalf("markdown header = %q", lines[:2])
+	}
+	out := buf.String()
+	for _, want := range []string{"| `C-a` | Create worktree |", "| `C-s` | Cycle sort order |", "| `C-d` | Delete |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "C-o") {
+		t.Errorf("worktree table lists open-in-window:\n%s", out)
+	}
+}
+
+func TestRunKeysWith_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := runKeysWith(&config.Config{}, "dashboard", "plain", &buf); err == nil {
+		t.Error("unknown mode: want error")
+	}
+	if err := runKeysWith(&config.Config{}, "project", "html", &buf); err == nil {
+		t.Error("unknown format: want error")
+	}
+}
+
+func TestRunKeysWith_RemappedKeys(t *testing.T) {
+	cfg := &config.Config{Keys: map[string]string{"kill_session": "ctrl-q", "help": "ctrl-g"}}
+
+	var buf bytes.Buffer
+	if err := runKeysWith(cfg, "worktree", "markdown", &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"| `C-q` | Kill tmux session |", "| `C-g` | Toggle help |", "| `C-d` | Delete |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown output missing %q:\n%s", want, out)
+		}
+	}
+}
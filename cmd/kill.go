@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/session"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+var killAllDetached bool
+
+var killCmd = &cobra.Command{
+	Use:   "kill",
+	Short: "Pick tmux sessions to kill",
+	Long: `List every tmux session — project sessions marked ■ with their project name,
+other sessions □ — and kill the ones you pick. Mark several with tab; Enter
+kills every marked session, or the highlighted one when none are marked. The
+session pop runs in is left out of the list. ctrl+k works the same as Enter.
+
+--all-detached skips the picker and kills every session no client is attached
+to.`,
+	Args: cobra.NoArgs,
+	RunE: runKill,
+}
+
+func init() {
+	rootCmd.AddCommand(killCmd)
+	killCmd.Flags().BoolVar(&killAllDetached, "all-detached", false, "kill every session with no attached client, without the picker")
+}
+
+func runKill(cmd *cobra.Command, args []string) error {
+	return runKillWith(DefaultProjectDeps(), killAllDetached, os.Stdout)
+}
+
+// tmuxSession is one line of tmux list-sessions.
+type tmuxSession struct {
+	Name     string
+	Attached bool
+	Activity int64
+}
+
+// listTmuxSessionsWith returns the running sessions, least recently active
+// first. No tmux server means no sessions.
+func listTmuxSessionsWith(d *ProjectDeps) []tmuxSession {
+	out, err := d.Tmux.Command("list-sessions", "-F", "#{session_name}\t#{session_attached}\t#{session_activity}")
+	if err != nil {
+		debug.Error("kill: list-sessions: %v", err)
+		return nil
+	}
+	var sessions []tmuxSession
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[0] == "" {
+			continue
+		}
+		attached, _ := strconv.Atoi(fields[1])
+		activity, _ := strconv.ParseInt(fields[2], 10, 64)
+		sessions = append(sessions, tmuxSession{Name: fields[0], Attached: attached > 0, Activity: activity})
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].Activity < sessions[j].Activity })
+	return sessions
+}
+
+// runKillWith kills the sessions picked in the kill picker, or with
+// allDetached every detached session, reporting each to w.
+func runKillWith(d *ProjectDeps, allDetached bool, w io.Writer) error {
+	sessions := listTmuxSessionsWith(d)
+	if len(sessions) == 0 {
+		return fmt.Errorf("no tmux sessions running")
+	}
+
+	cfg, err := d.LoadConfig()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg = &config.Config{}
+	}
+	tmux := d.Tmux
+	if d.RunHook != nil {
+		tmux = withSessionHooks(tmux, &sessionHooks{cfg: cfg, Run: d.RunHook})
+	}
+
+	var names []string
+	if allDetached {
+		for _, s := range sessions {
+			if !s.Attached {
+				names = append(names, s.Name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(w, "No detached sessions")
+			return nil
+		}
+	} else {
+		names, err = pickSessionsToKill(d, cfg, sessions)
+		if err != nil || len(names) == 0 {
+			return err
+		}
+	}
+
+	return killSessionsWith(tmux, names, w)
+}
+
+// killSessionsWith kills each named session, reporting each to w, and fails
+// when any of them could not be killed.
+func killSessionsWith(tmux deps.Tmux, names []string, w io.Writer) error {
+	var failed int
+	for _, name := range names {
+		if err := session.KillWith(&session.Deps{Tmux: tmux}, "="+name); err != nil {
+			debug.Error("kill: %s: %v", name, err)
+			fmt.Fprintf(w, "Failed to kill %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "Killed %s\n", name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d session(s) could not be killed", failed)
+	}
+	return nil
+}
+
+// pickSessionsToKill shows sessions in a multi-select picker, marking those
+// that belong to a configured project, and returns the names picked. It
+// returns nil when the picker is cancelled.
+func pickSessionsToKill(d *ProjectDeps, cfg *config.Config, sessions []tmuxSession) ([]string, error) {
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	projectNames := make(map[string]string) // session name → project name
+	if len(cfg.Projects) > 0 {
+		hist, err := d.LoadHistory()
+		if err != nil {
+			hist = &history.History{}
+		}
+		projects, _, err := collectProjectsWith(d, cfg, cfgPath, hist, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			projectNames[p.SessionName] = p.Name
+		}
+	}
+
+	current := d.CurrentSession(d.Tmux)
+	var items []ui.Item
+	for _, s := range sessions {
+		if s.Name == current {
+			continue
+		}
+		item := ui.Item{Name: s.Name, Path: tmuxSessionPathPrefix + s.Name, Icon: iconStandaloneSession}
+		if name, ok := projectNames[s.Name]; ok {
+			item.Icon = iconDirSession
+			item.Context = name
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no other tmux sessions running")
+	}
+
+	result, err := d.RunPicker(items,
+		ui.WithTheme(pickerTheme(cfg)),
+		ui.WithCursorAtEnd(),
+		ui.WithContext(),
+		ui.WithKillSession(),
+		ui.WithMultiSelect(),
+		ui.WithHeader("Pick sessions to kill (tab marks several)"),
+		ui.WithIconLegend(
+			ui.IconLegend{Icon: iconDirSession, Desc: "Project session"},
+			ui.IconLegend{Icon: iconStandaloneSession, Desc: "Standalone tmux session"},
+		),
+	)
+	if err != nil || (result.Action != ui.ActionConfirm && result.Action != ui.ActionKillSession) {
+		return nil, err
+	}
+	var names []string
+	for _, item := range result.Marked {
+		names = append(names, standaloneSessionName(item))
+	}
+	return names, nil
+}
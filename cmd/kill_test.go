@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+// killTestTmux answers list-sessions with sessions and records every
+// kill-session target.
+func killTestTmux(sessions string, killed *[]string) *deps.MockTmux {
+	return &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			switch args[0] {
+			case "list-sessions":
+				return sessions, nil
+			case "kill-session":
+				*killed = append(*killed, flagValue(args, "-t"))
+			}
+			return "", nil
+		},
+	}
+}
+
+func TestRunKillWith_AllDetached(t *testing.T) {
+	var killed []string
+	d := testProjectDeps(t)
+	d.Tmux = killTestTmux("api\t1\t300\nold\t0\t100\nscratch\t0\t200", &killed)
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		t.Fatal("--all-detached must not open the picker")
+		return ui.Result{}, nil
+	}
+
+	var out bytes.Buffer
+	if err := runKillWith(d, true, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"=old", "=scratch"}; !equalStrings(killed, want) {
+		t.Errorf("killed = %q, want %q", killed, want)
+	}
+	if got := out.String(); got != "Killed old\nKilled scratch\n" {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestRunKillWith_PickerKillsMarked(t *testing.T) {
+	var killed []string
+	d := testProjectDeps(t)
+	projectDir := t.TempDir()
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{Projects: []config.ProjectEntry{{Path: projectDir}}}, nil
+	}
+	projectSession := filepath.Base(projectDir)
+	d.Tmux = killTestTmux("scratch\t0\t300\nmain\t1\t200\n"+projectSession+"\t0\t100", &killed)
+	d.CurrentSession = func(deps.Tmux) string { return "main" }
+
+	var shown []ui.Item
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		shown = items
+		return ui.Result{Action: ui.ActionConfirm, Marked: items}, nil
+	}
+
+	var out bytes.Buffer
+	if err := runKillWith(d, false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(shown) != 2 {
+		t.Fatalf("picker items = %+v, want the two non-current sessions", shown)
+	}
+	// Least recently active first; the project session is marked.
+	if shown[0].Name != projectSession || shown[0].Icon != iconDirSession || shown[0].Context == "" {
+		t.Errorf("project session item = %+v", shown[0])
+	}
+	if shown[1].Name != "scratch" || shown[1].Icon != iconStandaloneSession {
+		t.Errorf("standalone session item = %+v", shown[1])
+	}
+	if want := []string{"=" + projectSession, "=scratch"}; !equalStrings(killed, want) {
+		t.Errorf("killed = %q, want %q", killed, want)
+	}
+}
+
+func TestRunKillWith_CancelKillsNothing(t *testing.T) {
+	var killed []string
+	d := testProjectDeps(t)
+	d.Tmux = killTestTmux("scratch\t0\t100", &killed)
+	d.CurrentSession = func(deps.Tmux) string { return "" }
+
+	if err := runKillWith(d, false, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(killed) != 0 {
+		t.Errorf("killed = %q after cancel", killed)
+	}
+}
+
+func TestRunKillWith_NoSessions(t *testing.T) {
+	d := testProjectDeps(t)
+	if err := runKillWith(d, true, &bytes.Buffer{}); err == nil {
+		t.Error("want an error with no tmux sessions")
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listFormat string
+	listWatch  bool
+)
+
+// listWatchInterval is how often pop list --watch checks for changes.
+const listWatchInterval = time.Second
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print projects without the picker",
+	Long: `Print the projects the picker would show — configured projects, worktrees of
+bare repos and pop-managed worktrees, with disambiguated names — in picker
+order (least recently used first, most recent last).
+
+--format plain (default) prints one "name<TAB>path" line per project, ready for
+fzf, rofi or cut. --format json prints an array of objects with path, name,
+session_name, has_session, last_access (omitted for never-opened projects) and
+tags (omitted when none are configured).
+
+--watch keeps running and prints the list again whenever the config, the glob
+cache, the history or the set of tmux sessions changes, for status bars and
+widgets that mirror pop. Each plain snapshot ends with an empty line; with
+--format json each snapshot is one compact JSON array per line.`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&listFormat, "format", "plain", "output format: plain or json")
+	listCmd.Flags().BoolVar(&listWatch, "watch", false, "print the list again whenever it may have changed")
+}
+
+// listEntry is one project in `pop list --format json` output.
+type listEntry struct {
+	Path        string     `json:"path"`
+	Name        string     `json:"name"`
+	SessionName string     `json:"session_name"`
+	HasSession  bool       `json:"has_session"`
+	LastAccess  *time.Time `json:"last_access,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	d := DefaultProjectDeps()
+	if listWatch {
+		cfgPath := cfgFile
+		if cfgPath == "" {
+			cfgPath = config.DefaultConfigPath()
+		}
+		fingerprint := func() string { return listWatchFingerprint(d, cfgPath) }
+		return runListWatchWith(d, listFormat, os.Stdout, listWatchInterval, fingerprint, nil)
+	}
+	return runListWith(d, listFormat, os.Stdout)
+}
+
+// runListWith prints the project list in format to w. It shares the picker's
+// expansion, disambiguation and history sorting, but never excludes the
+// current session and never prompts to create a missing config.
+func runListWith(d *ProjectDeps, format string, w io.Writer) error {
+	return writeListWith(d, format, false, w)
+}
+
+// runListWatchWith prints the list like runListWith, then again each time
+// fingerprint changes and the list with it, until stop is closed. It checks
+// every interval. Only the first list failing is fatal; later failures (a
+// config saved half-edited) are logged and the previous list stands.
+func runListWatchWith(d *ProjectDeps, format string, w io.Writer, interval time.Duration, fingerprint func() string, stop <-chan struct{}) error {
+	var lastFingerprint, lastList string
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for first := true; ; first = false {
+		if fp := fingerprint(); first || fp != lastFingerprint {
+			lastFingerprint = fp
+			var buf bytes.Buffer
+			if err := writeListWith(d, format, true, &buf); err != nil {
+				if first {
+					return err
+				}
+				debug.Error("list --watch: %v", err)
+			} else if buf.String() != lastList {
+				lastList = buf.String()
+				if format == "plain" {
+					buf.WriteByte('\n')
+				}
+				if _, err := w.Write(buf.Bytes()); err != nil {
+					return err
+				}
+			}
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// listWatchFingerprint summarises what pop list output depends on: the
+// mtimes of the config, glob cache and history files, and the names of the
+// running tmux sessions.
+func listWatchFingerprint(d *ProjectDeps, cfgPath string) string {
+	var b strings.Builder
+	for _, path := range []string{cfgPath, config.DefaultCachePath(), history.DefaultHistoryPath()} {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s %d\n", path, info.ModTime().UnixNano())
+		}
+	}
+	b.WriteString(strings.Join(slices.Sorted(maps.Keys(d.SessionActivity())), "\n"))
+	return b.String()
+}
+
+// writeListWith is runListWith; compact prints JSON on a single line.
+func writeListWith(d *ProjectDeps, format string, compact bool, w io.Writer) error {
+	if format != "plain" && format != "json" {
+		return fmt.Errorf("invalid --format %q (want plain or json)", format)
+	}
+
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	cfg, err := d.LoadConfig()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no config at %s — run pop configure first", cfgPath)
+		}
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	hist, err := d.LoadHistory()
+	if err != nil {
+		hist = &history.History{}
+	}
+	projects, _, err := collectProjectsWith(d, cfg, cfgPath, hist, nil)
+	if err != nil {
+		return err
+	}
+
+	if format == "plain" {
+		for _, p := range projects {
+			fmt.Fprintf(w, "%s\t%s\n", p.Name, p.Path)
+		}
+		return nil
+	}
+
+	lastAccess := make(map[string]time.Time, len(hist.Entries))
+	for _, e := range hist.Entries {
+		lastAccess[e.Path] = e.LastAccess
+	}
+	activity := d.SessionActivity()
+	entries := make([]listEntry, len(projects))
+	for i, p := range projects {
+		_, hasSession := activity[p.SessionName]
+		entries[i] = listEntry{
+			Path:        p.Path,
+			Name:        p.Name,
+			SessionName: p.SessionName,
+			HasSession:  hasSession,
+			Tags:        p.Tags,
+		}
+		if t, ok := lastAccess[p.Path]; ok {
+			entries[i].LastAccess = &t
+		}
+	}
+	enc := json.NewEncoder(w)
+	if !compact {
+		enc.SetIndent("", "  ")
+	}
+	enc.SetEscapeHTML(false)
+	return enc.Encode(entries)
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+)
+
+func listTestDeps(t *testing.T) (*ProjectDeps, string, string) {
+	t.Helper()
+	d := testProjectDeps(t)
+	root := t.TempDir()
+	older := filepath.Join(root, "older")
+	recent := filepath.Join(root, "recent")
+	for _, dir := range []string{older, recent} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(root, "*")}}}, nil
+	}
+	now := time.Now()
+	d.LoadHistory = func() (*history.History, error) {
+		return &history.History{Entries: []history.Entry{
+			{Path: older, LastAccess: now.Add(-48 * time.Hour), Count: 1},
+			{Path: recent, LastAccess: now.Add(-time.Minute), Count: 3},
+		}}, nil
+	}
+	d.SessionActivity = func() map[string]int64 { return map[string]int64{"recent": now.Unix()} }
+	return d, older, recent
+}
+
+func TestRunList_Plain(t *testing.T) {
+	d, older, recent := listTestDeps(t)
+	var out bytes.Buffer
+	if err := runListWith(d, "plain", &out); err != nil {
+		t.Fatalf("runListWith() error = %v", err)
+	}
+	want := "older\t" + older + "\nrecent\t" + recent + "\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q (most recent last)", out.String(), want)
+	}
+}
+
+func TestRunList_JSON(t *testing.T) {
+	d, older, recent := listTestDeps(t)
+	var out bytes.Buffer
+	if err := runListWith(d, "json", &out); err != nil {
+		t.Fatalf("runListWith() error = %v", err)
+	}
+	var entries []listEntry
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Path != older || entries[0].HasSession {
+		t.Errorf("entry 0 = %+v, want %s without session", entries[0], older)
+	}
+	if entries[1].Path != recent || !entries[1].HasSession || entries[1].SessionName != "recent" {
+		t.Errorf("entry 1 = %+v, want %s with session", entries[1], recent)
+	}
+	if entries[1].LastAccess == nil {
+		t.Error("entry 1 should carry last_access")
+	}
+}
+
+func TestRunList_NeverOpenedOmitsLastAccess(t *testing.T) {
+	d, _, _ := listTestDeps(t)
+	d.LoadHistory = func() (*history.History, error) { return &history.History{}, nil }
+	var out bytes.Buffer
+	if err := runListWith(d, "json", &out); err != nil {
+		t.Fatalf("runListWith() error = %v", err)
+	}
+	if strings.Contains(out.String(), "last_access") {
+		t.Errorf("never-opened projects must omit last_access:\n%s", out.String())
+	}
+}
+
+func TestRunList_InvalidFormat(t *testing.T) {
+	d, _, _ := listTestDeps(t)
+	if err := runListWith(d, "yaml", &bytes.Buffer{}); err == nil {
+		t.Error("expected error for unknown --format")
+	}
+}
+
+func TestRunListWatch_ReprintsOnlyWhenListChanges(t *testing.T) {
+	d, older, _ := listTestDeps(t)
+	stop := make(chan struct{})
+	calls := 0
+	fingerprint := func() string {
+		calls++
+		switch calls {
+		case 1, 2:
+			return "a"
+		case 3:
+			// The list depends on the directory listing; a new project
+			// appears once the fingerprint moves.
+			if err := os.Mkdir(filepath.Join(filepath.Dir(older), "added"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		case 4:
+			close(stop)
+		}
+		return "b"
+	}
+
+	var out bytes.Buffer
+	if err := runListWatchWith(d, "plain", &out, time.Millisecond, fingerprint, stop); err != nil {
+		t.Fatalf("runListWatchWith: %v", err)
+	}
+	snapshots := strings.Split(strings.TrimSuffix(out.String(), "\n\n"), "\n\n")
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2:\n%s", len(snapshots), out.String())
+	}
+	if strings.Contains(snapshots[0], "added") || !strings.HasPrefix(snapshots[1], "added\t") {
+		t.Errorf("snapshots = %q, want the new project in the second only", snapshots)
+	}
+}
+
+func TestRunListWatch_JSONIsOneLinePerSnapshot(t *testing.T) {
+	d, _, _ := listTestDeps(t)
+	stop := make(chan struct{})
+	close(stop)
+
+	var out bytes.Buffer
+	if err := runListWatchWith(d, "json", &out, time.Millisecond, func() string { return "" }, stop); err != nil {
+		t.Fatalf("runListWatchWith: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want one compact snapshot:\n%s", len(lines), out.String())
+	}
+	var entries []listEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entries); err != nil || len(entries) != 2 {
+		t.Errorf("snapshot = %s (%v), want two entries", lines[0], err)
+	}
+}
+
+func TestRunListWatch_FirstFailureIsFatal(t *testing.T) {
+	d, _, _ := listTestDeps(t)
+	if err := runListWatchWith(d, "yaml", &bytes.Buffer{}, time.Millisecond, func() string { return "" }, nil); err == nil {
+		t.Error("want the invalid format to fail")
+	}
+}
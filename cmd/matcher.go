@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/glebglazov/pop/ui"
+)
+
+// matcherTimeout bounds a single matcher command run. The matcher runs on every
+// keystroke, so a slow one would make typing lag; past this the picker falls
+// back to its built-in fuzzy ranking for that query.
+const matcherTimeout = 500 * time.Millisecond
+
+// matcherRequest is the JSON a matcher command reads on stdin.
+type matcherRequest struct {
+	Query      string             `json:"query"`
+	Candidates []matcherCandidate `json:"candidates"`
+}
+
+type matcherCandidate struct {
+	Name string   `json:"name"`
+	Path string   `json:"path"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// matcherFunc returns the ui.MatchFunc for a configured matcher_command, or nil
+// when command is empty (built-in fuzzy matching).
+func matcherFunc(command string, run func(command, query string, items []ui.Item) ([]int, error)) ui.MatchFunc {
+	if command == "" || run == nil {
+		return nil
+	}
+	return func(query string, items []ui.Item) ([]int, error) {
+		return run(command, query, items)
+	}
+}
+
+// runMatcherCommand runs a user-configured matcher via `sh -c`. The query and
+// candidates arrive as one JSON object on stdin; the command prints a JSON
+// array of candidate indices, best match first, and omits non-matches.
+func runMatcherCommand(command, query string, items []ui.Item) ([]int, error) {
+	req := matcherRequest{Query: query, Candidates: make([]matcherCandidate, len(items))}
+	for i, item := range items {
+		req.Candidates[i] = matcherCandidate{Name: item.Name, Path: item.Path, Tags: item.Tags}
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), matcherTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(input)
+	// Don't wait on grandchildren still holding stdout once the deadline kills sh.
+	cmd.WaitDelay = 50 * time.Millisecond
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("timed out after %s", matcherTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseMatcherOutput(out)
+}
+
+// parseMatcherOutput decodes a matcher command's stdout.
+func parseMatcherOutput(out []byte) ([]int, error) {
+	var indices []int
+	if err := json.Unmarshal(bytes.TrimSpace(out), &indices); err != nil {
+		return nil, fmt.Errorf("parse matcher output: %w", err)
+	}
+	return indices, nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestMatcherFuncDisabledWhenEmpty(t *testing.T) {
+	run := func(string, string, []ui.Item) ([]int, error) { return nil, nil }
+	if matcherFunc("", run) != nil {
+		t.Error("empty command should disable the matcher")
+	}
+	if matcherFunc("m", nil) != nil {
+		t.Error("nil runner should disable the matcher")
+	}
+}
+
+func TestRunMatcherCommand(t *testing.T) {
+	items := []ui.Item{{Name: "alpha", Path: "/a"}, {Name: "beta", Path: "/b"}}
+
+	// The script answers [1, 0] only when stdin carries the query and the
+	// candidates.
+	script := `input=$(cat); case "$input" in *'"query":"be"'*'"name":"beta","path":"/b"'*) echo '[1, 0]';; *) echo '[]';; esac`
+	got, err := runMatcherCommand(script, "be", items)
+	if err != nil {
+		t.Fatalf("runMatcherCommand: %v", err)
+	}
+	if want := []int{1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("indices = %v, want %v", got, want)
+	}
+
+	if _, err := runMatcherCommand("echo not json", "be", items); err == nil || !strings.Contains(err.Error(), "parse matcher output") {
+		t.Errorf("malformed output: err = %v, want parse error", err)
+	}
+	if _, err := runMatcherCommand("exit 2", "be", items); err == nil {
+		t.Error("failing command should return an error")
+	}
+	if _, err := runMatcherCommand("sleep 5", "be", items); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow command: err = %v, want timeout", err)
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/internal/shell"
+	"github.com/glebglazov/pop/ui"
+)
+
+// pathsPlaceholder is replaced with the shell-quoted paths of every marked
+// item when a multi user-defined command runs.
+const pathsPlaceholder = "{paths}"
+
+// expandPathsPlaceholder substitutes {paths} with the items' shell-quoted
+// paths, space-separated, so `code {paths}` opens them all at once.
+func expandPathsPlaceholder(command string, items []ui.Item) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = shell.Quote(item.Path)
+	}
+	return strings.ReplaceAll(command, pathsPlaceholder, strings.Join(quoted, " "))
+}
+
+// nulDelimitedPaths joins the items' paths for `xargs -0`-style consumers.
+func nulDelimitedPaths(items []ui.Item) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString(item.Path)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// executeMultiCustomCommand runs a multi user-defined command once for all
+// marked items. A command using {paths} receives them as arguments and keeps
+// the terminal on stdin (editors need it); any other command receives them
+// NUL-delimited on stdin. extraEnv is appended to the environment.
+func executeMultiCustomCommand(command string, items []ui.Item, extraEnv ...string) {
+	cmd := exec.Command("sh", "-c", expandPathsPlaceholder(command, items))
+	cmd.Env = append(os.Environ(), extraEnv...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if strings.Contains(command, pathsPlaceholder) {
+		cmd.Stdin = os.Stdin
+	} else {
+		cmd.Stdin = strings.NewReader(nulDelimitedPaths(items))
+	}
+	if err := cmd.Run(); err != nil {
+		debug.Error("custom command %q on %d items: %v", command, len(items), err)
+		ui.Notify(ui.LevelError, "Custom command failed: %v", err)
+	}
+}
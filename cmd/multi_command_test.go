@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestExpandPathsPlaceholder(t *testing.T) {
+	items := []ui.Item{{Path: "/src/a"}, {Path: "/src/my repo"}}
+	got := expandPathsPlaceholder("code {paths}", items)
+	want := `code '/src/a' '/src/my repo'`
+	if got != want {
+		t.Errorf("expandPathsPlaceholder = %q, want %q", got, want)
+	}
+	if got := expandPathsPlaceholder("xargs -0 ls", items); got != "xargs -0 ls" {
+		t.Errorf("command without {paths} changed: %q", got)
+	}
+}
+
+func TestNulDelimitedPaths(t *testing.T) {
+	got := nulDelimitedPaths([]ui.Item{{Path: "/a"}, {Path: "/b c"}})
+	if got != "/a\x00/b c\x00" {
+		t.Errorf("nulDelimitedPaths = %q", got)
+	}
+}
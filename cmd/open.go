@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+// openAmbiguousLimit caps the candidates listed when a query is ambiguous.
+const openAmbiguousLimit = 5
+
+var openCmd = &cobra.Command{
+	Use:   "open <query>",
+	Short: "Open the project best matching a query, without the picker",
+	Long: `Fuzzy-match query against the projects the picker would list and open the
+best match exactly as Enter in the picker would: switch to its tmux session,
+creating it first when needed, and record it in history.
+
+A project whose name equals the query wins outright; otherwise the highest
+score wins, and a shorter name breaks a tie. When the best candidates still
+tie, pop open lists them and exits non-zero instead of guessing.
+
+--session-name opens the project in the named tmux session instead of the
+one its name or session_name entry derives, used exactly as given: pop
+switches to the session when it already runs and creates it otherwise. The
+name may not hold ".", ":", spaces, quotes or non-ASCII characters, nor start
+with $, @, % or =.
+
+--tag narrows the candidates to projects carrying the tag; repeat it to
+require several.
+
+--all opens nothing: it makes sure every candidate — each project carrying
+the --tag tags and, given a query, matching it — has a tmux session, creating
+the missing ones detached from the project's preferred workbench, or from
+--workbench when given. Sessions that already run are left alone.
+
+Examples:
+  bind-key a run-shell 'pop open api'
+  pop open api --session-name api-review
+  pop open --tag work --all --workbench dev`,
+	Args: validateOpenArgs,
+	RunE: runOpen,
+}
+
+var (
+	openSessionName string
+	openTags        []string
+	openAll         bool
+	openWorkbench   string
+)
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().StringVar(&openSessionName, "session-name", "", "tmux session to open the project in, used as given")
+	openCmd.Flags().StringArrayVar(&openTags, "tag", nil, "only consider projects carrying this tag (repeatable)")
+	openCmd.Flags().BoolVar(&openAll, "all", false, "create a detached session for every matching project instead of opening one")
+	openCmd.Flags().StringVar(&openWorkbench, "workbench", "", "with --all, the workbench to create missing sessions from")
+	openCmd.Flags().StringVar(&resultOutput, "output", "", "print the project opened to stdout: json")
+}
+
+// validateOpenArgs requires a query unless --all has --tag to go by, and
+// keeps the flags that only make sense for one of the two modes to it.
+func validateOpenArgs(cmd *cobra.Command, args []string) error {
+	if !openAll {
+		if openWorkbench != "" {
+			return fmt.Errorf("--workbench needs --all")
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	}
+	if openSessionName != "" {
+		return fmt.Errorf("--session-name can't be combined with --all")
+	}
+	if len(args) == 0 && len(openTags) == 0 {
+		return fmt.Errorf("--all needs a query or --tag")
+	}
+	return nil
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	if err := validateSessionName(openSessionName); err != nil {
+		return err
+	}
+	if err := validateResultOutput(resultOutput); err != nil {
+		return err
+	}
+	d := DefaultProjectDeps()
+	d.Output = resultOutput
+	d.Query = strings.Join(args, " ")
+	d.SessionName = openSessionName
+	d.Tags = openTags
+	d.All = openAll
+	d.Workbench = openWorkbench
+	// Nothing is shown, so don't spend the once-a-day notice or a tip.
+	d.UpdateNotice = nil
+	d.NextTip = nil
+	d.RunConfigure = func() error {
+		return fmt.Errorf("no config — run pop configure first")
+	}
+	return RunProject(d)
+}
+
+// validateSessionName rejects an explicit session name that is not already
+// what project.SafeSessionName makes of it: tmux would rewrite some of its
+// characters, and pop, which derives and looks up every other session name
+// through SafeSessionName, would then look for a session that never exists.
+// An empty name means none was given.
+func validateSessionName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if safe := project.SafeSessionName(name); safe != name {
+		return fmt.Errorf("invalid session name %q: avoid \".\", \":\", spaces, quotes, non-ASCII and a leading $@%%= (e.g. %q)", name, safe)
+	}
+	return nil
+}
+
+// pickByQuery stands in for the project picker: it confirms the project item
+// best matching query. Standalone tmux sessions are not candidates.
+func pickByQuery(query string, items []ui.Item) (ui.Result, error) {
+	var projects []ui.Item
+	for _, item := range items {
+		if !isStandaloneSession(item) {
+			projects = append(projects, item)
+		}
+	}
+	matches := ui.MatchItems(query, projects)
+	if len(matches) == 0 {
+		return ui.Result{}, fmt.Errorf("no project matches %q", query)
+	}
+
+	// Stable, so equal candidates keep picker order.
+	sort.SliceStable(matches, func(i, j int) bool {
+		return compareOpenMatches(query, matches[i], matches[j]) < 0
+	})
+	var tied []string
+	for _, m := range matches {
+		if compareOpenMatches(query, matches[0], m) != 0 {
+			break
+		}
+		tied = append(tied, m.Item.Name)
+	}
+	if len(tied) > 1 {
+		if len(tied) > openAmbiguousLimit {
+			tied = append(tied[:openAmbiguousLimit], fmt.Sprintf("… %d more", len(tied)-openAmbiguousLimit))
+		}
+		return ui.Result{}, fmt.Errorf("%q is ambiguous: %s", query, strings.Join(tied, ", "))
+	}
+	return ui.Result{Action: ui.ActionConfirm, Selected: &matches[0].Item}, nil
+}
+
+// pickAll stands in for the project picker for pop open --all: it confirms
+// every project item carrying tags and, when query is set, matching it,
+// marked. Standalone tmux sessions are not candidates.
+func pickAll(query string, tags []string, items []ui.Item) (ui.Result, error) {
+	var marked []ui.Item
+	for _, item := range taggedItems(items, tags) {
+		if !isStandaloneSession(item) {
+			marked = append(marked, item)
+		}
+	}
+	if query != "" {
+		matches := ui.MatchItems(query, marked)
+		marked = marked[:0:0]
+		for _, m := range matches {
+			marked = append(marked, m.Item)
+		}
+	}
+	if len(marked) == 0 {
+		return ui.Result{}, fmt.Errorf("no project matches %s", describeOpenFilter(query, tags))
+	}
+	return ui.Result{Action: ui.ActionConfirm, Selected: &marked[0], Marked: marked}, nil
+}
+
+// taggedItems returns the items carrying every tag in tags, compared without
+// regard to case. It returns items itself when tags is empty.
+func taggedItems(items []ui.Item, tags []string) []ui.Item {
+	if len(tags) == 0 {
+		return items
+	}
+	var kept []ui.Item
+	for _, item := range items {
+		if hasEveryTag(item, tags) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func hasEveryTag(item ui.Item, tags []string) bool {
+	for _, want := range tags {
+		if !slices.ContainsFunc(item.Tags, func(tag string) bool { return strings.EqualFold(tag, want) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// describeOpenFilter renders query and tags for an error, e.g. `"api" #work`.
+func describeOpenFilter(query string, tags []string) string {
+	var parts []string
+	if query != "" {
+		parts = append(parts, fmt.Sprintf("%q", query))
+	}
+	for _, tag := range tags {
+		parts = append(parts, "#"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+// compareOpenMatches orders a before b (negative) when it is the better pick
+// for query: an exact name first, then a higher score, then a shorter name.
+func compareOpenMatches(query string, a, b ui.ItemMatch) int {
+	if aExact, bExact := strings.EqualFold(a.Item.Name, query), strings.EqualFold(b.Item.Name, query); aExact != bExact {
+		if aExact {
+			return -1
+		}
+		return 1
+	}
+	if a.Score != b.Score {
+		return b.Score - a.Score
+	}
+	return len(a.Item.Name) - len(b.Item.Name)
+}
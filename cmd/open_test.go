@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestPickByQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		items   []ui.Item
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "exact name wins",
+			query: "api",
+			items: []ui.Item{{Name: "api", Path: "/api"}, {Name: "api-gateway", Path: "/api-gateway"}},
+			want:  "/api",
+		},
+		{
+			name:  "better score wins",
+			query: "web",
+			items: []ui.Item{{Name: "w-e-b", Path: "/scattered"}, {Name: "website", Path: "/website"}},
+			want:  "/website",
+		},
+		{
+			name:  "shorter name breaks a tie",
+			query: "dot",
+			items: []ui.Item{{Name: "dotfiles-old", Path: "/old"}, {Name: "dotfiles", Path: "/dotfiles"}},
+			want:  "/dotfiles",
+		},
+		{
+			name:    "equal candidates are ambiguous",
+			query:   "api",
+			items:   []ui.Item{{Name: "work/api", Path: "/work/api"}, {Name: "home/api", Path: "/home/api"}},
+			wantErr: `"api" is ambiguous: work/api, home/api`,
+		},
+		{
+			name:    "no match",
+			query:   "zzz",
+			items:   []ui.Item{{Name: "api", Path: "/api"}},
+			wantErr: `no project matches "zzz"`,
+		},
+		{
+			name:  "standalone sessions are not candidates",
+			query: "scratch",
+			items: []ui.Item{{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"}, {Name: "scratchpad", Path: "/scratchpad"}},
+			want:  "/scratchpad",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := pickByQuery(tt.query, tt.items)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pickByQuery: %v", err)
+			}
+			if result.Action != ui.ActionConfirm || result.Selected == nil || result.Selected.Path != tt.want {
+				t.Errorf("result = %+v, want confirm of %s", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickByQuery_ListsAtMostFiveTiedCandidates(t *testing.T) {
+	var items []ui.Item
+	for _, parent := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		items = append(items, ui.Item{Name: parent + "/api", Path: "/" + parent + "/api"})
+	}
+	_, err := pickByQuery("api", items)
+	if err == nil || !strings.HasSuffix(err.Error(), "e/api, … 2 more") {
+		t.Errorf("err = %v, want five candidates and a count", err)
+	}
+}
+
+func TestValidateSessionName(t *testing.T) {
+	for _, name := range []string{"", "scratch2", "api-review", "work/api", "me@host", "api_v2"} {
+		if err := validateSessionName(name); err != nil {
+			t.Errorf("validateSessionName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{" ", "api.v2", "host:api", "api review", "$home", "Café"} {
+		if err := validateSessionName(name); err == nil {
+			t.Errorf("validateSessionName(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestRunProject_QueryOpensInGivenSessionName(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	d := testProjectDeps(t)
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(root, "api"), SessionName: "backend"}}}, nil
+	}
+	var session string
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		session = item.SessionName
+		return nil
+	}
+	d.Query = "api"
+	d.SessionName = "api review"
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if session != "api review" {
+		t.Errorf("opened in session %q, want the --session-name override", session)
+	}
+}
+
+func TestRunProject_QueryOpensBestMatchWithoutPicker(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := testProjectDeps(t)
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{
+			Projects: []config.ProjectEntry{{Path: filepath.Join(root, "*")}},
+			Project:  &config.ProjectConfig{Stream: true},
+		}, nil
+	}
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		t.Fatal("pop open showed the picker")
+		return ui.Result{}, nil
+	}
+	var opened string
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		opened = item.Path
+		return nil
+	}
+	d.Query = "web"
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if want := filepath.Join(root, "web"); opened != want {
+		t.Errorf("opened %q, want %q", opened, want)
+	}
+}
+
+func TestPickAll(t *testing.T) {
+	items := []ui.Item{
+		{Name: "api", Path: "/api", Tags: []string{"work"}},
+		{Name: "web", Path: "/web", Tags: []string{"Work", "frontend"}},
+		{Name: "dotfiles", Path: "/dotfiles"},
+		{Name: "work", Path: tmuxSessionPathPrefix + "work"},
+	}
+	tests := []struct {
+		name    string
+		query   string
+		tags    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "every project carrying the tag", tags: []string{"work"}, want: []string{"/api", "/web"}},
+		{name: "every tag is required", tags: []string{"work", "frontend"}, want: []string{"/web"}},
+		{name: "query narrows the tagged projects", query: "api", tags: []string{"work"}, want: []string{"/api"}},
+		{name: "query alone", query: "dot", want: []string{"/dotfiles"}},
+		{name: "nothing carries the tag", tags: []string{"home"}, wantErr: "no project matches #home"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := pickAll(tt.query, tt.tags, items)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pickAll: %v", err)
+			}
+			var got []string
+			for _, item := range result.Marked {
+				got = append(got, item.Path)
+			}
+			if result.Action != ui.ActionConfirm || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("marked %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunProject_AllEnsuresDetachedSessionsForTag(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"api", "web", "dotfiles"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := testProjectDeps(t)
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{Projects: []config.ProjectEntry{
+			{Path: filepath.Join(root, "api"), Tags: []string{"work"}},
+			{Path: filepath.Join(root, "web"), Tags: []string{"work"}},
+			{Path: filepath.Join(root, "dotfiles")},
+		}}, nil
+	}
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		t.Fatal("pop open --all showed the picker")
+		return ui.Result{}, nil
+	}
+	d.Tmux = &deps.MockTmux{HasSessionFunc: func(name string) bool { return name == "web" }}
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		t.Errorf("pop open --all switched to %s", item.Name)
+		return nil
+	}
+	var ensured []string
+	d.EnsureSession = func(tmux deps.Tmux, item *ui.Item, workbenchName string) error {
+		ensured = append(ensured, item.Name+":"+workbenchName)
+		return nil
+	}
+	d.Tags = []string{"work"}
+	d.All = true
+	d.Workbench = "dev"
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if got := strings.Join(ensured, ","); got != "api:dev" {
+		t.Errorf("ensured %q, want only the missing api session, from the dev workbench", got)
+	}
+}
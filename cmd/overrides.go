@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/internal/shell"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/session"
+	"github.com/glebglazov/pop/ui"
+)
+
+// startupCommandTmux starts a project's configured session the way its
+// entry asks, right as pop creates it: with container, the session's first
+// window runs the docker exec into it in place of the default shell, and the
+// entry's command is then typed into that window, so it runs inside the
+// container or not at all. Like hooksTmux it watches both creation paths:
+// NewSession for flat sessions and a raw new-session for Workbench ones.
+// Sessions that already exist are left alone, so switching back to a project
+// never reruns its commands.
+type startupCommandTmux struct {
+	deps.Tmux
+	containers map[string]string // session name → container
+	commands   map[string]string // session name → command
+}
+
+// withStartupCommands wraps tmux in startupCommandTmux when any of projects
+// configures a command or a container.
+func withStartupCommands(tmux deps.Tmux, projects []project.ExpandedProject) deps.Tmux {
+	containers := make(map[string]string)
+	commands := make(map[string]string)
+	for _, p := range projects {
+		if p.Container != "" {
+			containers[p.SessionName] = p.Container
+		}
+		if p.Command != "" {
+			commands[p.SessionName] = p.Command
+		}
+	}
+	if len(containers) == 0 && len(commands) == 0 {
+		return tmux
+	}
+	return startupCommandTmux{Tmux: tmux, containers: containers, commands: commands}
+}
+
+func (t startupCommandTmux) NewSession(name, dir string) error {
+	if container, ok := t.containers[name]; ok {
+		// Raw, so the docker exec is the window's process: when it fails or
+		// exits, the session ends instead of leaving a host shell.
+		if _, err := t.Tmux.Command("new-session", "-d", "-s", name, "-c", dir, containerCommand(container)); err != nil {
+			return err
+		}
+	} else if err := t.Tmux.NewSession(name, dir); err != nil {
+		return err
+	}
+	t.send(name)
+	return nil
+}
+
+func (t startupCommandTmux) Command(args ...string) (string, error) {
+	if len(args) == 0 || args[0] != "new-session" {
+		return t.Tmux.Command(args...)
+	}
+	name := flagValue(args, "-s")
+	if container, ok := t.containers[name]; ok {
+		args = append(slices.Clone(args), containerCommand(container))
+	}
+	out, err := t.Tmux.Command(args...)
+	if err == nil {
+		t.send(name)
+	}
+	return out, err
+}
+
+// send types the session's command into its first window. The session is
+// already up, so a failure is reported rather than undoing the create.
+func (t startupCommandTmux) send(name string) {
+	command, ok := t.commands[name]
+	if !ok {
+		return
+	}
+	if err := session.SendCommandWith(&session.Deps{Tmux: t.Tmux}, "="+name+":", command); err != nil {
+		debug.Error("project: startup command for %s: %v", name, err)
+		ui.Notify(ui.LevelError, "startup command for %s failed: %v", name, err)
+	}
+}
+
+// containerWorkdir is where a container session's shell starts, the usual
+// dev-container workspace mount.
+const containerWorkdir = "/workspace"
+
+// containerCommand is the shell-command a container session starts with: an
+// interactive login shell in container, starting in containerWorkdir.
+func containerCommand(container string) string {
+	return "docker exec -it " + shell.Quote(container) + " bash -lc " + shell.Quote("cd "+containerWorkdir+" && exec $SHELL")
+}
+
+// withEntryWorkbench layers each project's configured workbench under resolve:
+// when no preferred Workbench resolves for a path, the projects entry's own
+// workbench applies, provided it names a Workbench available there.
+func withEntryWorkbench(resolve func(cfg *config.Config, path string) (string, []string), available func(cfg *config.Config, path string) []config.Workbench, projects []project.ExpandedProject) func(cfg *config.Config, path string) (string, []string) {
+	workbenches := make(map[string]string)
+	for _, p := range projects {
+		if p.Workbench != "" {
+			workbenches[p.Path] = p.Workbench
+		}
+	}
+	if len(workbenches) == 0 {
+		return resolve
+	}
+	return func(cfg *config.Config, path string) (string, []string) {
+		name, warns := resolve(cfg, path)
+		fallback, ok := workbenches[path]
+		if name != "" || !ok {
+			return name, warns
+		}
+		if _, found := findWorkbench(available(cfg, path), fallback); !found {
+			return "", append(warns, fmt.Sprintf("projects entry workbench %q not found for %s; ignoring it", fallback, path))
+		}
+		return fallback, warns
+	}
+}
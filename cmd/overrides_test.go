@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+)
+
+func TestStartupCommandTmux(t *testing.T) {
+	var calls []string
+	inner := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			calls = append(calls, strings.Join(args, " "))
+			return "", nil
+		},
+		NewSessionFunc: func(name, dir string) error {
+			calls = append(calls, "new "+name)
+			return nil
+		},
+	}
+	tmux := withStartupCommands(inner, []project.ExpandedProject{
+		{SessionName: "api", Command: "make dev"},
+		{SessionName: "docs"},
+	})
+
+	if err := tmux.NewSession("api", "/src/api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmux.NewSession("docs", "/src/docs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmux.Command("new-session", "-d", "-s", "api", "-c", "/src/api"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmux.Command("switch-client", "-t", "api"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"new api",
+		"send-keys -t =api: make dev Enter",
+		"new docs",
+		"new-session -d -s api -c /src/api",
+		"send-keys -t =api: make dev Enter",
+		"switch-client -t api",
+	}
+	if !equalStrings(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestStartupCommandTmux_ContainerIsTheSessionCommand(t *testing.T) {
+	var calls []string
+	inner := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			calls = append(calls, strings.Join(args, " "))
+			return "", nil
+		},
+	}
+	tmux := withStartupCommands(inner, []project.ExpandedProject{
+		{SessionName: "api", Container: "api-dev", Command: "make dev"},
+	})
+
+	if err := tmux.NewSession("api", "/src/api"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmux.Command("new-session", "-d", "-s", "api", "-c", "/src/api", "-P", "-F", "#{window_id}"); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := "docker exec -it 'api-dev' bash -lc 'cd /workspace && exec $SHELL'"
+	want := []string{
+		"new-session -d -s api -c /src/api " + exec,
+		"send-keys -t =api: make dev Enter",
+		"new-session -d -s api -c /src/api -P -F #{window_id} " + exec,
+		"send-keys -t =api: make dev Enter",
+	}
+	if !equalStrings(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestWithStartupCommands_NoCommandsLeavesTmuxUnwrapped(t *testing.T) {
+	inner := &deps.MockTmux{}
+	if got := withStartupCommands(inner, []project.ExpandedProject{{SessionName: "api"}}); got != deps.Tmux(inner) {
+		t.Errorf("withStartupCommands wrapped tmux with no commands configured: %T", got)
+	}
+}
+
+func TestWithEntryWorkbench(t *testing.T) {
+	preferred := map[string]string{"/src/web": "frontend"}
+	resolve := func(cfg *config.Config, path string) (string, []string) {
+		return preferred[path], nil
+	}
+	available := func(cfg *config.Config, path string) []config.Workbench {
+		return []config.Workbench{{Name: "dev"}, {Name: "frontend"}}
+	}
+	resolveWith := withEntryWorkbench(resolve, available, []project.ExpandedProject{
+		{Path: "/src/api", Workbench: "dev"},
+		{Path: "/src/web", Workbench: "dev"},
+		{Path: "/src/old", Workbench: "gone"},
+	})
+
+	tests := []struct {
+		path      string
+		want      string
+		wantWarns int
+	}{
+		{"/src/api", "dev", 0},      // entry workbench fills the gap
+		{"/src/web", "frontend", 0}, // a preferred Workbench still wins
+		{"/src/old", "", 1},         // an unknown name is reported and ignored
+		{"/src/none", "", 0},
+	}
+	for _, tt := range tests {
+		got, warns := resolveWith(nil, tt.path)
+		if got != tt.want || len(warns) != tt.wantWarns {
+			t.Errorf("resolve(%s) = (%q, %q), want (%q, %d warning(s))", tt.path, got, warns, tt.want, tt.wantWarns)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+// pickerDisplayOptions returns the display options of mode's picker: the
+// cursor on the last item and icons shown, with the context column as
+// showContext says, each overridden by the mode's [<mode>.ui] table. The
+// [theme] colors come from pickerTheme.
+func pickerDisplayOptions(cfg *config.Config, mode string, showContext bool) []ui.PickerOption {
+	display := cfg.PickerUIForMode(mode)
+	var opts []ui.PickerOption
+	if display.CursorAtEnd == nil || *display.CursorAtEnd {
+		opts = append(opts, ui.WithCursorAtEnd())
+	}
+	if display.ShowContext != nil {
+		showContext = *display.ShowContext
+	}
+	if showContext {
+		opts = append(opts, ui.WithContext())
+	}
+	if display.ShowIcons != nil && !*display.ShowIcons {
+		opts = append(opts, ui.WithoutIcons())
+	}
+	if display.Height > 0 {
+		opts = append(opts, ui.WithMaxHeight(display.Height))
+	}
+	return opts
+}
+
+// pickerTheme returns cfg's [theme] colors for a picker.
+func pickerTheme(cfg *config.Config) ui.Theme {
+	theme := cfg.GetTheme()
+	return ui.Theme{
+		SelectedBg: theme.SelectedBg,
+		SelectedFg: theme.SelectedFg,
+		Accent:     theme.Accent,
+		Hint:       theme.Hint,
+		Context:    theme.Context,
+	}
+}
+
+// withPickerTheme makes run open every picker in cfg's [theme] colors.
+func withPickerTheme(run func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error), cfg *config.Config) func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+	theme := ui.WithTheme(pickerTheme(cfg))
+	return func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		return run(items, append([]ui.PickerOption{theme}, opts...)...)
+	}
+}
+
+// runThemedPicker is ui.Run in the [theme] colors of the config, for pickers
+// opened with no config at hand. Without a config the defaults apply.
+func runThemedPicker(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	if cfg, err := config.Load(cfgPath); err == nil {
+		return withPickerTheme(ui.Run, cfg)(items, opts...)
+	}
+	return ui.Run(items, opts...)
+}
+
+// gitItemStatus is the picker status of git_status: an item's change and
+// ahead/behind markers. Standalone sessions, and paths git cannot read (a
+// bare repo root, a plain directory), show none.
+func gitItemStatus(d *project.Deps) ui.StatusFunc {
+	return func(item ui.Item) string {
+		if isStandaloneSession(item) {
+			return ""
+		}
+		status, err := project.GitStatusOfWith(d, item.Path)
+		if err != nil {
+			debug.Log("git status %s: %v", item.Path, err)
+			return ""
+		}
+		return status.String()
+	}
+}
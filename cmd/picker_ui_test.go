@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+func renderWithDisplayOptions(t *testing.T, cfg *config.Config, mode string, showContext bool) string {
+	t.Helper()
+	items := []ui.Item{
+		{Name: "main", Path: "/repo/main", Context: "trunk", Icon: iconDirSession},
+		{Name: "feature", Path: "/repo/feature", Context: "feat/x"},
+	}
+	p := ui.NewPicker(items, pickerDisplayOptions(cfg, mode, showContext)...)
+	p.Init()
+	p.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	return ui.StripANSI(p.View().Content)
+}
+
+func TestPickerDisplayOptions_BuiltInDefaults(t *testing.T) {
+	view := renderWithDisplayOptions(t, &config.Config{}, "worktree", true)
+	if !strings.Contains(view, "[feat/x]") || !strings.Contains(view, iconDirSession) {
+		t.Errorf("worktree defaults should show context and icons:\n%s", view)
+	}
+	view = renderWithDisplayOptions(t, &config.Config{}, "project", false)
+	if strings.Contains(view, "[feat/x]") {
+		t.Errorf("project defaults should hide context:\n%s", view)
+	}
+}
+
+func TestPickerDisplayOptions_ModeTableOverrides(t *testing.T) {
+	no, yes := false, true
+	cfg := &config.Config{
+		Worktree: &config.WorktreeConfig{UI: &config.PickerUIConfig{ShowContext: &no, ShowIcons: &no}},
+		Project:  &config.ProjectConfig{UI: &config.PickerUIConfig{ShowContext: &yes}},
+	}
+	view := renderWithDisplayOptions(t, cfg, "worktree", true)
+	if strings.Contains(view, "[feat/x]") || strings.Contains(view, iconDirSession) {
+		t.Errorf("[worktree.ui] should hide context and icons:\n%s", view)
+	}
+	view = renderWithDisplayOptions(t, cfg, "project", false)
+	if !strings.Contains(view, "[feat/x]") {
+		t.Errorf("[project.ui] show_context should show context:\n%s", view)
+	}
+}
+
+func TestPickerDisplayOptions_CursorAtEnd(t *testing.T) {
+	no := false
+	cfg := &config.Config{Project: &config.ProjectConfig{UI: &config.PickerUIConfig{CursorAtEnd: &no}}}
+	items := []ui.Item{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}}
+
+	for _, tt := range []struct {
+		cfg  *config.Config
+		want string
+	}{
+		{cfg: &config.Config{}, want: "/b"},
+		{cfg: cfg, want: "/a"},
+	} {
+		p := ui.NewPicker(items, pickerDisplayOptions(tt.cfg, "project", false)...)
+		p.Init()
+		p.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+		if got := p.Result().Selected; got == nil || got.Path != tt.want {
+			t.Errorf("Enter selected %+v, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestGitItemStatus(t *testing.T) {
+	var dirs []string
+	status := gitItemStatus(&project.Deps{Git: &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			dirs = append(dirs, dir)
+			if dir == "/src/notes" {
+				return "", errors.New("not a git repository")
+			}
+			return "# branch.ab +1 -0\n? scratch.txt", nil
+		},
+	}})
+
+	if got := status(ui.Item{Name: "api", Path: "/src/api"}); got != "● ↑1" {
+		t.Errorf("status = %q, want the dirty and ahead markers", got)
+	}
+	if got := status(ui.Item{Name: "notes", Path: "/src/notes"}); got != "" {
+		t.Errorf("status of a non-repo = %q, want none", got)
+	}
+	if got := status(ui.Item{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"}); got != "" {
+		t.Errorf("status of a standalone session = %q, want none", got)
+	}
+	if len(dirs) != 2 {
+		t.Errorf("git ran in %v, want no call for the standalone session", dirs)
+	}
+}
+
+func TestWithPickerTheme(t *testing.T) {
+	accent := "208"
+	cfg := &config.Config{Theme: &config.ThemeConfig{Accent: accent}}
+	var view string
+	run := withPickerTheme(func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		p := ui.NewPicker(items, opts...)
+		p.Init()
+		p.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+		view = p.View().Content
+		return ui.Result{}, nil
+	}, cfg)
+	if _, err := run([]ui.Item{{Name: "main", Path: "/repo/main"}}, ui.WithHeader("Pick")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(view, "38;5;"+accent) || !strings.Contains(ui.StripANSI(view), "Pick") {
+		t.Errorf("view = %q, want the caller's header in the [theme] accent", view)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/ui"
+)
+
+// selectPipelineDeps holds the per-mode actions behind the on_select steps.
+// Each picker wires them to the same helpers its built-in Enter path uses, so a
+// pipeline listing the default steps behaves exactly like no pipeline at all.
+type selectPipelineDeps struct {
+	RecordHistory func(path string)
+	// EnsureSession creates the item's session when it does not exist yet,
+	// without switching to it.
+	EnsureSession func(item *ui.Item) error
+	Switch        func(item *ui.Item) error
+	Run           func(command string, item *ui.Item) error
+}
+
+// runSelectPipeline runs an on_select pipeline for the selected item, step by
+// step in order. The first failing step aborts the rest, so a run: step that
+// exits non-zero keeps a later switch from landing in a half-prepared session.
+// Steps were validated at config load; an unknown one is skipped.
+func runSelectPipeline(d *selectPipelineDeps, steps []string, item *ui.Item) error {
+	for _, step := range steps {
+		var err error
+		switch {
+		case step == config.OnSelectRecordHistory:
+			d.RecordHistory(item.Path)
+		case step == config.OnSelectEnsureSession:
+			err = d.EnsureSession(item)
+		case step == config.OnSelectSwitch:
+			err = d.Switch(item)
+		case strings.HasPrefix(step, config.OnSelectRunPrefix):
+			err = d.Run(strings.TrimSpace(strings.TrimPrefix(step, config.OnSelectRunPrefix)), item)
+		}
+		if err != nil {
+			return fmt.Errorf("on_select step %q: %w", step, err)
+		}
+	}
+	return nil
+}
+
+// runPipelineCommand runs a run: step through sh -c in dir with the terminal
+// attached, so commands like `direnv allow` can prompt. env is appended to the
+// current environment.
+func runPipelineCommand(command, dir string, env ...string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/glebglazov/pop/ui"
+)
+
+func recordingPipeline(log *[]string, failOn string) *selectPipelineDeps {
+	step := func(name string) error {
+		*log = append(*log, name)
+		if name == failOn {
+			return errors.New("boom")
+		}
+		return nil
+	}
+	return &selectPipelineDeps{
+		RecordHistory: func(path string) { *log = append(*log, "history:"+path) },
+		EnsureSession: func(item *ui.Item) error { return step("ensure") },
+		Switch:        func(item *ui.Item) error { return step("switch") },
+		Run:           func(command string, item *ui.Item) error { return step("run:" + command) },
+	}
+}
+
+func TestRunSelectPipeline(t *testing.T) {
+	item := &ui.Item{Name: "app", Path: "/src/app", SessionName: "app"}
+
+	t.Run("runs steps in order", func(t *testing.T) {
+		var log []string
+		steps := []string{"record_history", "ensure_session", "run: direnv allow", "switch"}
+		if err := runSelectPipeline(recordingPipeline(&log, ""), steps, item); err != nil {
+			t.Fatalf("runSelectPipeline() error = %v", err)
+		}
+		want := []string{"history:/src/app", "ensure", "run:direnv allow", "switch"}
+		if !reflect.DeepEqual(log, want) {
+			t.Errorf("steps = %v, want %v", log, want)
+		}
+	})
+
+	t.Run("failing step aborts the rest", func(t *testing.T) {
+		var log []string
+		steps := []string{"ensure_session", "run:make setup", "switch"}
+		err := runSelectPipeline(recordingPipeline(&log, "run:make setup"), steps, item)
+		if err == nil {
+			t.Fatal("expected error from failing run: step")
+		}
+		want := []string{"ensure", "run:make setup"}
+		if !reflect.DeepEqual(log, want) {
+			t.Errorf("steps = %v, want %v (switch must not run)", log, want)
+		}
+	})
+}
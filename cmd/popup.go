@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/internal/shell"
+)
+
+// popupMode is --popup: re-run the picker inside a tmux display-popup.
+var popupMode bool
+
+// wantsPopup reports whether --popup should re-run pop in a display-popup:
+// inside tmux, but not already in a popup. A run-shell binding looks like a
+// popup ($TMUX without $TMUX_PANE) but has no terminal, which a popup has.
+func wantsPopup(inTmux, inPopup, hasTerminal bool) bool {
+	return popupMode && inTmux && (!inPopup || !hasTerminal)
+}
+
+// stdinIsTerminal reports whether stdin is a terminal the picker can run in.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runInPopup re-runs the current pop command in a display-popup sized by the
+// [<mode>.ui] table of the config at cfgPath, and returns once it closes.
+func runInPopup(mode, cfgPath string) error {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		// The popup command reports config problems itself.
+		cfg = &config.Config{}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("--popup: %w", err)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("--popup: %w", err)
+	}
+	width, height := cfg.PopupSizeForMode(mode)
+	return runInPopupWith(defaultTmux, popupArgs(exe, os.Args[1:], dir, width, height))
+}
+
+// runInPopupWith runs the display-popup built by popupArgs.
+func runInPopupWith(tmux deps.Tmux, args []string) error {
+	if _, err := tmux.Command(args...); err != nil {
+		return fmt.Errorf("tmux display-popup: %w", err)
+	}
+	return nil
+}
+
+// popupArgs returns the tmux arguments that open a width x height
+// display-popup in dir, running exe with args minus --popup. The popup closes
+// when pop exits (-E).
+func popupArgs(exe string, args []string, dir, width, height string) []string {
+	words := []string{shell.Quote(exe)}
+	for _, arg := range args {
+		if arg == "--popup" || strings.HasPrefix(arg, "--popup=") {
+			continue
+		}
+		words = append(words, shell.Quote(arg))
+	}
+	return []string{"display-popup", "-E", "-w", width, "-h", height, "-d", dir, strings.Join(words, " ")}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func TestPopupArgs(t *testing.T) {
+	got := popupArgs("/usr/bin/pop", []string{"select", "--popup", "--config", "/tmp/it's.toml", "--popup=true"}, "/src/api", "80%", "30")
+	want := []string{"display-popup", "-E", "-w", "80%", "-h", "30", "-d", "/src/api",
+		`'/usr/bin/pop' 'select' '--config' '/tmp/it'\''s.toml'`}
+	if !slices.Equal(got, want) {
+		t.Errorf("popupArgs() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWantsPopup(t *testing.T) {
+	defer func(prev bool) { popupMode = prev }(popupMode)
+
+	tests := []struct {
+		name                          string
+		flag, inTmux, inPopup, hasTTY bool
+		want                          bool
+	}{
+		{name: "pane", flag: true, inTmux: true, hasTTY: true, want: true},
+		{name: "run-shell binding", flag: true, inTmux: true, inPopup: true, want: true},
+		{name: "already in the popup", flag: true, inTmux: true, inPopup: true, hasTTY: true, want: false},
+		{name: "outside tmux", flag: true, hasTTY: true, want: false},
+		{name: "without --popup", inTmux: true, hasTTY: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			popupMode = tt.flag
+			if got := wantsPopup(tt.inTmux, tt.inPopup, tt.hasTTY); got != tt.want {
+				t.Errorf("wantsPopup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunInPopupWith(t *testing.T) {
+	var ran []string
+	tmux := &deps.MockTmux{CommandFunc: func(args ...string) (string, error) {
+		ran = args
+		return "", nil
+	}}
+	args := popupArgs("pop", []string{"select"}, "/", "60%", "60%")
+	if err := runInPopupWith(tmux, args); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ran, args) {
+		t.Errorf("tmux ran %q, want %q", ran, args)
+	}
+
+	tmux.CommandFunc = func(args ...string) (string, error) { return "", errors.New("unknown command") }
+	if err := runInPopupWith(tmux, args); err == nil {
+		t.Error("expected the tmux error")
+	}
+}
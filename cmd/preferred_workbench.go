@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
 	"strings"
 
 	"github.com/glebglazov/pop/config"
@@ -65,7 +63,7 @@ type preferredPickerDeps struct {
 // still propagate) and the config.runtime.toml [workbench.preferred] store.
 func defaultPreferredPickerDeps() *preferredPickerDeps {
 	return &preferredPickerDeps{
-		RunPicker: ui.Run,
+		RunPicker: runThemedPicker,
 		ResolveWorkbenches: func(path string) []config.Workbench {
 			cfgPath := cfgFile
 			if cfgPath == "" {
@@ -159,6 +157,6 @@ func setPreferredWorkbench(d *preferredPickerDeps, checkoutPath string) error {
 func warnPreferredWorkbenchErr(surface string, err error) {
 	if err != nil {
 		debug.Error("%s: set preferred workbench: %v", surface, err)
-		fmt.Fprintf(os.Stderr, "Failed to set preferred workbench: %v\n", err)
+		ui.Notify(ui.LevelError, "Failed to set preferred workbench: %v", err)
 	}
 }
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/internal/shell"
+	"github.com/glebglazov/pop/ui"
+)
+
+// previewTimeout bounds a single preview command run. The pane renders
+// asynchronously, so a slow command only delays its own pane — but a hung one
+// must not leak a process for every item the cursor passes over.
+const previewTimeout = 5 * time.Second
+
+// previewMaxBytes caps how much preview output is kept; the pane shows a
+// screenful at most, so a `cat` of a huge file is cut short here.
+const previewMaxBytes = 64 * 1024
+
+// detailsPreviewCommand is the reserved preview_command (and --preview-cmd)
+// value that selects the built-in details preview instead of a shell command.
+const detailsPreviewCommand = "builtin:details"
+
+// detailsReadmeLines caps how much of the README the details preview shows.
+const detailsReadmeLines = 15
+
+// detailsReadmeNames are the README file names the details preview looks for,
+// in order.
+var detailsReadmeNames = []string{"README.md", "README", "README.rst", "README.txt", "readme.md"}
+
+// expandPreviewCommand substitutes the {path}, {name}, and {session}
+// placeholders in a preview command with shell-quoted item values, mirroring
+// fzf's --preview {} syntax.
+func expandPreviewCommand(command string, item ui.Item) string {
+	return strings.NewReplacer(
+		"{path}", shell.Quote(item.Path),
+		"{name}", shell.Quote(item.Name),
+		"{session}", shell.Quote(item.SessionName),
+	).Replace(command)
+}
+
+// previewFunc returns the ui.PreviewFunc for a configured preview command, or
+// nil when command is empty (no preview pane).
+func previewFunc(command string, run func(command string, item ui.Item) string) ui.PreviewFunc {
+	if command == "" || run == nil {
+		return nil
+	}
+	return func(item ui.Item) string {
+		return run(command, item)
+	}
+}
+
+// runPreviewCommand runs a user-configured preview command for item via
+// `sh -c`, in the item's directory, and returns its combined output truncated
+// to previewMaxBytes. Standalone tmux sessions have no directory to preview, so
+// they get an empty pane. Failures are rendered into the pane rather than
+// returned — the preview is informational and must never abort the picker.
+func runPreviewCommand(command string, item ui.Item) string {
+	if isStandaloneSession(item) {
+		return ""
+	}
+	if command == detailsPreviewCommand {
+		return renderDetailsPreviewWith(&deps.RealGit{Timeout: previewTimeout}, deps.NewRealFileSystem(), item)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), previewTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", expandPreviewCommand(command, item))
+	cmd.Dir = item.Path
+	cmd.Env = append(os.Environ(),
+		"POP_PATH="+item.Path,
+		"POP_NAME="+item.Name,
+		"POP_SESSION_NAME="+item.SessionName,
+	)
+	out := &cappedBuffer{max: previewMaxBytes}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+
+	text := out.String()
+	if ctx.Err() == context.DeadlineExceeded {
+		return text + fmt.Sprintf("\n[preview timed out after %s]", previewTimeout)
+	}
+	if err != nil {
+		debug.Error("preview: %q for %s: %v", command, item.Path, err)
+		if text == "" {
+			return fmt.Sprintf("[preview failed: %v]", err)
+		}
+	}
+	return text
+}
+
+// cappedBuffer keeps the first max bytes written to it and drops the rest.
+// Every write still reports success, so the command runs on undisturbed
+// rather than failing on a short write.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	if room := c.max - c.buf.Len(); len(p) > room {
+		c.truncated = true
+		c.buf.Write(p[:max(room, 0)])
+	} else {
+		c.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns the kept output. When the cap split a character, its
+// leading bytes are dropped so the text ends on a rune boundary.
+func (c *cappedBuffer) String() string {
+	b := c.buf.Bytes()
+	if c.truncated {
+		start := len(b) - 1
+		for start > 0 && start > len(b)-utf8.UTFMax && !utf8.RuneStart(b[start]) {
+			start--
+		}
+		if start >= 0 && !utf8.FullRune(b[start:]) {
+			b = b[:start]
+		}
+	}
+	return string(b)
+}
+
+// renderDetailsPreviewWith renders the built-in details preview for item: its
+// session state, git branch, working-tree status and last commit (skipped
+// outside a git checkout), then the head of its README.
+func renderDetailsPreviewWith(git deps.Git, fs deps.FileSystem, item ui.Item) string {
+	var b strings.Builder
+	session := "none"
+	if item.HasSession {
+		session = "running"
+	}
+	fmt.Fprintf(&b, "session  %s\n", session)
+
+	if branch, err := git.CommandInDir(item.Path, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		fmt.Fprintf(&b, "branch   %s\n", branch)
+		if status, err := git.CommandInDir(item.Path, "status", "--porcelain"); err == nil {
+			if status == "" {
+				b.WriteString("status   clean\n")
+			} else {
+				files := "files"
+				n := len(strings.Split(status, "\n"))
+				if n == 1 {
+					files = "file"
+				}
+				fmt.Fprintf(&b, "status   %d changed %s\n", n, files)
+			}
+		}
+		if commit, err := git.CommandInDir(item.Path, "log", "-1", "--format=%h %s (%cr)"); err == nil && commit != "" {
+			fmt.Fprintf(&b, "commit   %s\n", commit)
+		}
+	}
+
+	for _, name := range detailsReadmeNames {
+		data, err := fs.ReadFile(filepath.Join(item.Path, name))
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		if len(lines) > detailsReadmeLines {
+			lines = lines[:detailsReadmeLines]
+		}
+		fmt.Fprintf(&b, "\n── %s ──\n%s\n", name, strings.Join(lines, "\n"))
+		break
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestExpandPreviewCommand(t *testing.T) {
+	item := ui.Item{Name: "it's", Path: "/tmp/my proj", SessionName: "my_proj"}
+	got := expandPreviewCommand("tree -L 1 {path} # {name} {session}", item)
+	want := `tree -L 1 '/tmp/my proj' # 'it'\''s' 'my_proj'`
+	if got != want {
+		t.Errorf("expandPreviewCommand = %q, want %q", got, want)
+	}
+}
+
+func TestPreviewFuncDisabledWhenEmpty(t *testing.T) {
+	run := func(string, ui.Item) string { return "x" }
+	if previewFunc("", run) != nil {
+		t.Error("empty command should disable the preview")
+	}
+	if previewFunc("ls", nil) != nil {
+		t.Error("nil runner should disable the preview")
+	}
+
+	var gotCommand string
+	fn := previewFunc("ls {path}", func(command string, item ui.Item) string {
+		gotCommand = command
+		return item.Path
+	})
+	if out := fn(ui.Item{Path: "/p"}); out != "/p" || gotCommand != "ls {path}" {
+		t.Errorf("previewFunc passed command %q and returned %q", gotCommand, out)
+	}
+}
+
+func TestRunPreviewCommand(t *testing.T) {
+	dir := t.TempDir()
+	item := ui.Item{Name: "proj", Path: dir}
+
+	if out := runPreviewCommand("echo {name}; pwd", item); !strings.Contains(out, "proj\n"+dir) {
+		t.Errorf("output = %q, want name and working directory", out)
+	}
+
+	out := runPreviewCommand("echo oops >&2; exit 3", item)
+	if !strings.Contains(out, "oops") {
+		t.Errorf("failing command should still render its output, got %q", out)
+	}
+
+	if out := runPreviewCommand("exit 3", item); !strings.Contains(out, "preview failed") {
+		t.Errorf("silent failure should render an error, got %q", out)
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	t.Run("drops what is written past the cap", func(t *testing.T) {
+		c := &cappedBuffer{max: 5}
+		for _, s := range []string{"abc", "def", "ghi"} {
+			if n, err := c.Write([]byte(s)); n != len(s) || err != nil {
+				t.Fatalf("Write(%q) = %d, %v, want the whole write accepted", s, n, err)
+			}
+		}
+		if got := c.String(); got != "abcde" {
+			t.Errorf("String() = %q, want abcde", got)
+		}
+	})
+
+	t.Run("cuts on a rune boundary", func(t *testing.T) {
+		c := &cappedBuffer{max: 6}
+		c.Write([]byte("abcé€")) // é ends at byte 5, € is cut after its first byte
+		if got := c.String(); got != "abcé" {
+			t.Errorf("String() = %q, want abcé", got)
+		}
+		c = &cappedBuffer{max: 4}
+		c.Write([]byte("abcé"))
+		if got := c.String(); got != "abc" {
+			t.Errorf("String() = %q, want abc", got)
+		}
+	})
+}
+
+func TestRenderDetailsPreviewWith(t *testing.T) {
+	readme := "# app\n" + strings.Repeat("line\n", 30)
+	fs := &deps.MockFileSystem{
+		ReadFileFunc: func(path string) ([]byte, error) {
+			if path == "/src/app/README.md" {
+				return []byte(readme), nil
+			}
+			return nil, os.ErrNotExist
+		},
+	}
+
+	t.Run("git checkout", func(t *testing.T) {
+		git := &deps.MockGit{
+			CommandInDirFunc: func(dir string, args ...string) (string, error) {
+				switch args[0] {
+				case "rev-parse":
+					return "feature/login", nil
+				case "status":
+					return " M main.go\n?? notes.txt", nil
+				case "log":
+					return "abc1234 Add login form (2 hours ago)", nil
+				}
+				return "", nil
+			},
+		}
+		out := renderDetailsPreviewWith(git, fs, ui.Item{Path: "/src/app", HasSession: true})
+		for _, want := range []string{
+			"session  running",
+			"branch   feature/login",
+			"status   2 changed files",
+			"commit   abc1234 Add login form (2 hours ago)",
+			"── README.md ──\n# app",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("preview missing %q:\n%s", want, out)
+			}
+		}
+		if n := strings.Count(out, "line"); n != detailsReadmeLines-1 {
+			t.Errorf("README lines shown = %d, want %d", n, detailsReadmeLines-1)
+		}
+	})
+
+	t.Run("not a git checkout", func(t *testing.T) {
+		git := &deps.MockGit{
+			CommandInDirFunc: func(dir string, args ...string) (string, error) {
+				return "", errors.New("not a git repository")
+			},
+		}
+		out := renderDetailsPreviewWith(git, &deps.MockFileSystem{
+			ReadFileFunc: func(string) ([]byte, error) { return nil, os.ErrNotExist },
+		}, ui.Item{Path: "/src/plain"})
+		if out != "session  none\n" {
+			t.Errorf("preview = %q, want only the session line", out)
+		}
+	})
+}
@@ -1,15 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	runtimedebug "runtime/debug"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/glebglazov/pop/config"
@@ -22,11 +23,16 @@ import (
 	"github.com/glebglazov/pop/tasks/binding"
 	"github.com/glebglazov/pop/ui"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 var tmuxCDPane string
 var yankTarget string
 var noHistory bool
+var previewCmd string
+var groupBy string
+var detachOthers bool
+var openHere bool
 
 var projectCmd = &cobra.Command{
 	Use:   "project",
@@ -40,23 +46,39 @@ Use "pop project dashboard" to open the picker.`,
 }
 
 var projectDashboardCmd = &cobra.Command{
-	Use:   "dashboard",
+	Use:   "dashboard [dir]",
 	Short: "Open the project picker",
 	Long: `Opens the project picker to choose a project, worktree, or standalone session.
 Projects with git worktrees are expanded to show individual worktrees.
 Choosing a project opens or switches to a tmux session.
 
 Example tmux binding:
-  bind-key p display-popup -E -w 60% -h 60% 'pop project dashboard'`,
+  bind-key p display-popup -E -w 60% -h 60% 'pop project dashboard'
+
+or, letting pop open the popup itself (sized by [project.ui] popup_width and
+popup_height):
+  bind-key p run-shell -b 'pop project dashboard --popup'
+
+With a directory argument the picker is skipped and the project at that
+directory opens directly; a bare repo offers just its worktrees. A directory
+no projects entry lists is opened ad hoc, as if it were one, and pop asks
+whether to add it to the config.
+
+With --here the picker is skipped too: the configured project holding the
+current directory (the directory itself or its nearest ancestor) opens
+directly. Bind it to sessionize the shell you are in:
+  bind-key P run-shell 'cd "#{pane_current_path}" && pop project dashboard --here'`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runProject,
 }
 
 // Deprecated: use `pop project dashboard` instead. Hidden alias for existing
 // keybindings. TODO: remove at next major release.
 var selectCmd = &cobra.Command{
-	Use:    "select",
+	Use:    "select [dir]",
 	Short:  "Open the project picker (alias for project dashboard)",
 	Hidden: true,
+	Args:   cobra.MaximumNArgs(1),
 	RunE:   runProject,
 }
 
@@ -67,9 +89,23 @@ func init() {
 	projectCmd.PersistentFlags().StringVar(&tmuxCDPane, "tmux-cd", "", "Send cd command to specified tmux pane instead of switching session")
 	projectCmd.PersistentFlags().StringVar(&yankTarget, "yank-target", "", "Send yanked path to specified tmux pane instead of system clipboard")
 	projectCmd.PersistentFlags().BoolVar(&noHistory, "no-history", false, "Do not record selection in history")
+	projectCmd.PersistentFlags().StringVar(&previewCmd, "preview-cmd", "", "Shell command rendered in the preview pane ({path}, {name}, {session} placeholders)")
+	projectCmd.PersistentFlags().StringVar(&groupBy, "group-by", "", "Group items under a header: parent (the directory each entry was matched in) or none")
+	projectCmd.PersistentFlags().BoolVar(&detachOthers, "detach-others", false, "When attaching from outside tmux, detach the session's other clients (attach -d)")
+	projectCmd.PersistentFlags().BoolVar(&popupMode, "popup", false, "Inside tmux, re-run in a display-popup sized by [project.ui] popup_width/popup_height")
+	projectCmd.PersistentFlags().StringVar(&resultOutput, "output", "", "print the final picker result to stdout: json")
+	for _, c := range []*cobra.Command{projectCmd, projectDashboardCmd} {
+		c.Flags().BoolVar(&openHere, "here", false, "Skip the picker and open the configured project holding the current directory")
+	}
 	selectCmd.Flags().StringVar(&tmuxCDPane, "tmux-cd", "", "Send cd command to specified tmux pane instead of switching session")
 	selectCmd.Flags().StringVar(&yankTarget, "yank-target", "", "Send yanked path to specified tmux pane instead of system clipboard")
 	selectCmd.Flags().BoolVar(&noHistory, "no-history", false, "Do not record selection in history")
+	selectCmd.Flags().StringVar(&previewCmd, "preview-cmd", "", "Shell command rendered in the preview pane ({path}, {name}, {session} placeholders)")
+	selectCmd.Flags().StringVar(&groupBy, "group-by", "", "Group items under a header: parent (the directory each entry was matched in) or none")
+	selectCmd.Flags().BoolVar(&detachOthers, "detach-others", false, "When attaching from outside tmux, detach the session's other clients (attach -d)")
+	selectCmd.Flags().BoolVar(&popupMode, "popup", false, "Inside tmux, re-run in a display-popup sized by [project.ui] popup_width/popup_height")
+	selectCmd.Flags().StringVar(&resultOutput, "output", "", "print the final picker result to stdout: json")
+	selectCmd.Flags().BoolVar(&openHere, "here", false, "Skip the picker and open the configured project holding the current directory")
 }
 
 // ProjectDeps holds dependencies for the project command.
@@ -88,12 +124,20 @@ type ProjectDeps struct {
 	// tests supply a fixed set (or none) without a real queue data dir.
 	ManagedWorktrees func() []project.ExpandedProject
 
+	// CachedProjects returns the expanded projects of the config at cfgPath
+	// from a running pop daemon, with the findings expanding them recorded;
+	// ok is false when no daemon can answer for it. Nil means always scan.
+	CachedProjects func(cfgPath string) (projects []project.ExpandedProject, failed []string, findings []config.Finding, ok bool)
+
 	// Picker — the critical testing seam
 	RunPicker func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error)
 
 	// Session state
 	SessionActivity   func() map[string]int64
 	AttentionSessions func() map[string]bool
+	// SessionDisplayNames returns the display names recorded on sessions
+	// whose names had to be made safe, keyed by session name. Nil means none.
+	SessionDisplayNames func() map[string]string
 
 	// Side effects (take deps.Tmux as first arg to match *With signatures)
 	OpenSession func(tmux deps.Tmux, item *ui.Item) error
@@ -101,22 +145,49 @@ type ProjectDeps struct {
 	// Workbench (stray shell window removed) and attaches to it. Used by the
 	// picker create-path when [workbench] pick_on_create is on (ADR-0075).
 	OpenSessionWithWorkbench func(tmux deps.Tmux, item *ui.Item, workbenchName string) error
-	OpenWindow               func(tmux deps.Tmux, item *ui.Item) error
-	KillSession              func(tmux deps.Tmux, name string)
-	SendCDToPane             func(tmux deps.Tmux, paneID, path string) error
-	YankPathToPane           func(tmux deps.Tmux, paneID, path string) error
-	SwitchToTarget           func(tmux deps.Tmux, target string) error
-	SwitchAndZoom            func(tmux deps.Tmux, target string) error
-	RunCustomCommand         func(command string, item *ui.Item)
+	// EnsureSession creates the item's session without switching to it: built
+	// from the named Workbench, or flat when workbenchName is "". Used by the
+	// ensure_session step of an on_select pipeline.
+	EnsureSession func(tmux deps.Tmux, item *ui.Item, workbenchName string) error
+	// RunPipelineCommand runs an on_select run: step for the item.
+	RunPipelineCommand func(command string, item *ui.Item) error
+	// RunHook runs a [hooks] command in dir with env appended.
+	RunHook               func(command, dir string, env ...string) error
+	OpenWindow            func(tmux deps.Tmux, item *ui.Item) error
+	KillSession           func(tmux deps.Tmux, name string)
+	SendCDToPane          func(tmux deps.Tmux, paneID, path, busy string) error
+	YankPathToPane        func(tmux deps.Tmux, paneID, path string) error
+	SwitchToTarget        func(tmux deps.Tmux, target string) error
+	SwitchAndZoom         func(tmux deps.Tmux, target string) error
+	RunCustomCommand      func(command string, item *ui.Item, extraEnv ...string)
+	RunMultiCustomCommand func(command string, items []ui.Item, extraEnv ...string)
+	// RunEditor runs the command that opens a project in an editor (open_with,
+	// alt+enter).
+	RunEditor func(name string, args ...string) error
+	// StartFetch starts the background git fetch of fetch_on_open in a
+	// project directory. Nil turns fetch_on_open off.
+	StartFetch func(path string) error
 	// EnsureSystemState synchronously runs integration checks and kicks off
 	// the monitor daemon in a goroutine. Returns warnings for the picker.
 	EnsureSystemState func() []string
 	RunConfigure      func() error
 
+	// RunPreview renders a preview command's output for an item. It runs off
+	// the UI goroutine, so it may block on the subprocess.
+	RunPreview func(command string, item ui.Item) string
+	// RunMatcher ranks items against a query with a matcher_command; see
+	// runMatcherCommand.
+	RunMatcher func(command, query string, items []ui.Item) ([]int, error)
+
 	// UpdateNotice returns the dimmed top-right Update notice text, or "" for
 	// none. It is a seam so tests never touch the real cache or network.
 	UpdateNotice func() string
 
+	// NextTip returns the next one-time footer tip for the project picker (and
+	// marks it shown), naming keys as the [keys] remaps in bindings leave
+	// them, or "" for none. A seam so tests never touch tip state.
+	NextTip func(quickAccessModifier string, bindings map[string]string, inTmux bool) string
+
 	// ResolveWorkbenches returns the Workbenches resolved for a project path,
 	// used by the create-path prompt (ADR-0075). A seam so tests can supply a
 	// fixed set without touching .pop.toml or the global library.
@@ -130,13 +201,45 @@ type ProjectDeps struct {
 	ResolvePreferredWorkbench func(cfg *config.Config, path string) (string, []string)
 
 	// Environment
-	InTmux         func() bool
+	InTmux func() bool
+	// InPopup reports whether pop runs in a tmux display-popup (see
+	// popup_switch).
+	InPopup        func() bool
 	CurrentSession func(tmux deps.Tmux) string
+	// CurrentSessionDir returns the start directory of the current tmux
+	// session, "" when unknown. Nil means unknown.
+	CurrentSessionDir func(tmux deps.Tmux) string
+	// CurrentClient returns the tty of the current tmux client, "" when
+	// unknown. Nil means unknown.
+	CurrentClient func(tmux deps.Tmux) string
+
+	// ToggleStore remembers each tmux client's last two opened items for pop
+	// toggle. Nil means nothing is remembered.
+	ToggleStore *toggleStore
 
 	// CLI flags (populated by cobra handler before calling RunProject)
-	TMuxCDPane string
-	YankTarget string
-	NoHistory  bool
+	TMuxCDPane     string
+	YankTarget     string
+	NoHistory      bool
+	PreviewCommand string   // --preview-cmd; overrides the configured preview_command
+	GroupBy        string   // --group-by; "parent", "none", or "" for the configured [project] group_by
+	DetachOthers   bool     // --detach-others; forces attach_behavior = "detach_others"
+	Query          string   // pop open; the best match is opened instead of showing the picker
+	SessionName    string   // pop open --session-name; the tmux session the best match opens in
+	Tags           []string // pop open --tag; only projects carrying every tag are candidates
+	All            bool     // pop open --all; every candidate gets a detached session instead of the best match opening
+	Workbench      string   // pop open --all --workbench; the workbench sessions created for --all are built from
+	Recent         bool     // pop recent; the last project opened before the current one is opened instead of showing the picker
+	Toggle         bool     // pop toggle; the current tmux client's previous project is opened instead of showing the picker
+	Dir            string   // pop select <dir>; the project at dir is opened, ad hoc when no entry lists it
+	Here           bool     // --here; the configured project holding the working directory is opened instead of showing the picker
+	Output         string   // --output; "json" prints the final picker Result to Stdout
+	Stdout         io.Writer
+
+	// ConfirmAddDir asks whether to add an ad-hoc pop select <dir> directory
+	// to the projects list, given the diff adding it makes to the config.
+	// Nil means never.
+	ConfirmAddDir func(dir, diff string) bool
 }
 
 // DefaultProjectDeps returns ProjectDeps wired to real production implementations.
@@ -161,13 +264,22 @@ func DefaultProjectDeps() *ProjectDeps {
 			return discoverManagedWorktreesWith(td.FS, binding.ManagedWorktreesRoot(td))
 		},
 
+		CachedProjects: func(cfgPath string) ([]project.ExpandedProject, []string, []config.Finding, bool) {
+			return queryProjectDaemon(projectDaemonSocketPath(), cfgPath)
+		},
+
 		RunPicker: ui.Run,
 
-		SessionActivity:   history.TmuxSessionActivity,
-		AttentionSessions: monitorAttentionSessions,
+		SessionActivity:     history.TmuxSessionActivity,
+		AttentionSessions:   monitorAttentionSessions,
+		SessionDisplayNames: sessionDisplayNames,
+		ConfirmAddDir:       confirmAddDir,
 
 		OpenSession:              openTmuxSessionWith,
 		OpenSessionWithWorkbench: openTmuxSessionWithWorkbenchWith,
+		EnsureSession:            ensureTmuxSessionWith,
+		RunPipelineCommand:       runProjectPipelineCommand,
+		RunHook:                  runPipelineCommand,
 		OpenWindow:               openTmuxWindowWith,
 		KillSession:              killTmuxSessionWith,
 		SendCDToPane:             sendCDToPaneWith,
@@ -175,6 +287,11 @@ func DefaultProjectDeps() *ProjectDeps {
 		SwitchToTarget:           switchToTmuxTargetWith,
 		SwitchAndZoom:            switchToTmuxTargetAndZoomWith,
 		RunCustomCommand:         executeProjectCustomCommand,
+		RunMultiCustomCommand:    executeMultiCustomCommand,
+		RunEditor:                runEditorCommand,
+		StartFetch:               startBackgroundFetch,
+		RunPreview:               runPreviewCommand,
+		RunMatcher:               runMatcherCommand,
 		EnsureSystemState:        ensureSystemState,
 		RunConfigure: func() error {
 			cd := defaultConfigureDeps()
@@ -183,6 +300,9 @@ func DefaultProjectDeps() *ProjectDeps {
 		},
 
 		UpdateNotice: pickerUpdateNotice,
+		NextTip: func(quickAccessModifier string, bindings map[string]string, inTmux bool) string {
+			return pickerTip("project", quickAccessModifier, bindings, inTmux)
+		},
 
 		ResolveWorkbenches: func(cfg *config.Config, path string) []config.Workbench {
 			templates, _ := cfg.ResolveWorkbenchesWith(config.DefaultDeps(), path)
@@ -193,23 +313,67 @@ func DefaultProjectDeps() *ProjectDeps {
 			return cfg.ResolvePreferredWorkbench(preferredResolverConfigDeps(cfg), path)
 		},
 
-		InTmux:         func() bool { return os.Getenv("TMUX") != "" },
-		CurrentSession: currentTmuxSessionWith,
+		InTmux:            func() bool { return os.Getenv("TMUX") != "" },
+		InPopup:           inTmuxPopup,
+		CurrentSession:    currentTmuxSessionWith,
+		CurrentSessionDir: currentTmuxSessionDirWith,
+		CurrentClient:     currentTmuxClientWith,
+
+		ToggleStore: defaultToggleStore(),
 	}
 }
 
 func runProject(cmd *cobra.Command, args []string) error {
+	if err := validateResultOutput(resultOutput); err != nil {
+		return err
+	}
+	// A popup's stdout never reaches the caller, so --output stays inline.
+	if resultOutput == "" && wantsPopup(os.Getenv("TMUX") != "", inTmuxPopup(), stdinIsTerminal()) {
+		cfgPath := cfgFile
+		if cfgPath == "" {
+			cfgPath = config.DefaultConfigPath()
+		}
+		return runInPopup("project", cfgPath)
+	}
 	d := DefaultProjectDeps()
 	d.TMuxCDPane = tmuxCDPane
 	d.YankTarget = yankTarget
 	d.NoHistory = noHistory
+	d.PreviewCommand = previewCmd
+	d.DetachOthers = detachOthers
+	d.Output = resultOutput
+	d.Here = openHere
+	switch groupBy {
+	case "", "parent", "none":
+		d.GroupBy = groupBy
+	default:
+		return fmt.Errorf("invalid --group-by %q (want parent or none)", groupBy)
+	}
+	if len(args) > 0 {
+		d.Dir = args[0]
+	}
 	return RunProject(d)
 }
 
 // RunProject runs the project command with the given dependencies.
 // It orchestrates config loading, project expansion, history sorting,
 // the picker loop, and action dispatch.
-func RunProject(d *ProjectDeps) error {
+func RunProject(d *ProjectDeps) (err error) {
+	// final is the picker Result that ended the run, for --output json.
+	var final *ui.Result
+	if d.Output == outputJSON {
+		defer func() {
+			if err != nil || final == nil {
+				return
+			}
+			w := d.Stdout
+			if w == nil {
+				w = os.Stdout
+			}
+			err = writeResultJSON(w, newResultJSON(*final, itemSessionName))
+		}()
+	}
+
 	// cfgPath is resolved only for the "no projects found" diagnostic message;
 	// LoadConfig hides how the config is actually loaded.
 	cfgPath := cfgFile
@@ -232,72 +396,47 @@ func RunProject(d *ProjectDeps) error {
 		}
 	}
 
-	systemWarnings := d.EnsureSystemState()
-
-	// The projects list is essential to this command (ADR 0054): a blocking
-	// finding on it leaves nothing to switch to, so the call site treats the
-	// getter's error as fatal. Non-essential findings (display_depth, a bad
-	// glob) are not surfaced here — they degrade to the warning banner below.
-	if _, err := cfg.ProjectEntries(); err != nil {
-		return fmt.Errorf("invalid projects configuration: %w", err)
+	if d.RunPicker != nil {
+		// The project picker and every picker it leads to.
+		d.RunPicker = withPickerTheme(d.RunPicker, cfg)
 	}
-
-	// Expand project paths
-	paths, err := cfg.ExpandProjects()
-	if err != nil {
-		return fmt.Errorf("failed to expand projects: %w", err)
+	if d.DetachOthers || cfg.GetAttachBehavior() == "detach_others" {
+		d.Tmux = detachOthersTmux{d.Tmux}
 	}
-
-	if len(paths) == 0 {
-		return fmt.Errorf("no projects found. Check your config at %s", cfgPath)
+	if d.InPopup != nil {
+		d.Tmux = withPopupSwitch(d.Tmux, cfg.GetPopupSwitch(), d.InPopup())
 	}
-
-	// Discover pop-managed worktrees concurrently with the configured-project
-	// expansion (ADR-0110). The walk is filesystem-only — no store, no git — so
-	// it can't slow expansion or fork; a nil seam simply contributes nothing.
-	managedCh := make(chan []project.ExpandedProject, 1)
-	go func() {
-		if d.ManagedWorktrees == nil {
-			managedCh <- nil
+	// Outside the popup switch, so on_switch runs before a popup switch can
+	// close pop.
+	hooks := &sessionHooks{cfg: cfg, Run: d.RunHook}
+	if d.RunHook != nil {
+		d.Tmux = withSessionHooks(d.Tmux, hooks)
+	}
+	// fireSelectHooks runs on_select for each project chosen at once, however
+	// they open; standalone sessions among them are skipped.
+	fireSelectHooks := func(items []ui.Item) {
+		if d.RunHook == nil {
 			return
 		}
-		managedCh <- d.ManagedWorktrees()
-	}()
-
-	// Expand projects, showing worktrees for bare repos (parallel).
-	// Per-project errors and panics are captured so one bad project can't
-	// crash the whole project flow.
-	expanded, expansionErrors := expandProjectsWith(d.Project, paths)
-
-	// Fold in the managed worktrees; they sort by History recency alongside
-	// configured entries and dedupe against live sessions like any other entry.
-	expanded = append(expanded, (<-managedCh)...)
-
-	// Get current tmux session name for optional exclusion
-	var excludedSessionNames map[string]bool
-	if cfg.ShouldExcludeCurrentSession() {
-		if currentSession := d.CurrentSession(d.Tmux); currentSession != "" {
-			excludedSessionNames = map[string]bool{currentSession: true}
-		}
-	}
-	if len(excludedSessionNames) > 0 {
-		filtered := expanded[:0]
-		for _, ep := range expanded {
-			if !excludedSessionNames[ep.SessionName] {
-				filtered = append(filtered, ep)
+		for _, item := range items {
+			if !isStandaloneSession(item) {
+				hooks.fire(config.HookOnSelect, item.SessionName, item.Path)
 			}
 		}
-		expanded = filtered
 	}
+	d.Tmux = withFetchOnOpen(d.Tmux, newProjectFetcher(cfg, d.StartFetch))
+	// Outermost, so on_switch already sees the restored window.
+	d.Tmux = withWindowRestore(d.Tmux, cfg.ProjectRestoreWindow(), d.LoadHistory)
 
-	// If every single project failed to expand, we can't start normal
-	// handling — surface the failure instead of showing an empty picker.
-	if len(expanded) == 0 && len(expansionErrors) > 0 {
-		return fmt.Errorf("failed to expand any projects: %d errors (see ~/.local/share/pop/pop.log for details)", len(expansionErrors))
-	}
+	systemWarnings := d.EnsureSystemState()
 
-	// Disambiguate projects with the same name
-	project.DisambiguateNames(expanded, cfg.GetDisambiguationStrategy())
+	// Identify the current tmux session for optional exclusion. --here
+	// keeps it: the project holding the working directory is usually the
+	// current session's own.
+	var exclude *sessionExclusion
+	if cfg.ShouldExcludeCurrentSession() && !d.Here {
+		exclude = currentSessionExclusionWith(d)
+	}
 
 	// Load history and sort by recency (oldest first, most recent last)
 	hist, err := d.LoadHistory()
@@ -305,44 +444,69 @@ func RunProject(d *ProjectDeps) error {
 		hist = &history.History{}
 	}
 
-	// Convert to Project for sorting, then back
-	projects := make([]project.Project, len(expanded))
-	for i, ep := range expanded {
-		projects[i] = project.Project{Name: ep.Name, Path: ep.Path}
-	}
-	projects = hist.SortByRecency(projects)
-
-	// Rebuild expanded list in sorted order
-	pathToExpanded := make(map[string]project.ExpandedProject)
-	for _, ep := range expanded {
-		pathToExpanded[ep.Path] = ep
+	var dir string
+	if d.Dir != "" {
+		if dir, err = resolveProjectDir(d.Project.FS, d.Dir); err != nil {
+			return err
+		}
 	}
-	sortedExpanded := make([]project.ExpandedProject, len(projects))
-	for i, p := range projects {
-		sortedExpanded[i] = pathToExpanded[p.Path]
+	var here string
+	if d.Here {
+		if d.Dir != "" {
+			return errors.New("--here takes no directory argument")
+		}
+		if here, err = resolveProjectDir(d.Project.FS, "."); err != nil {
+			return err
+		}
 	}
 
-	// Build base items (no icons, no sessions) — done once
-	baseItems := make([]ui.Item, len(sortedExpanded))
-	for i, ep := range sortedExpanded {
-		baseItems[i] = ui.Item{
-			Name:        ep.Name,
-			Path:        ep.Path,
-			Context:     ep.ProjectName,
-			SessionName: ep.SessionName,
+	var (
+		baseItems       []ui.Item
+		expansionErrors []string
+		projectsByPath  map[string]project.ExpandedProject
+	)
+	useProjects := func(sortedExpanded []project.ExpandedProject, failed []string) {
+		// Per-project overrides: a configured command follows every session
+		// creation path, and a configured workbench backs the preferred one.
+		d.Tmux = withStartupCommands(d.Tmux, sortedExpanded)
+		d.Tmux = withSessionDisplayNames(d.Tmux, sortedExpanded)
+		relinkLegacySessions(d.Tmux, sortedExpanded)
+		if d.ResolvePreferredWorkbench != nil && d.ResolveWorkbenches != nil {
+			d.ResolvePreferredWorkbench = withEntryWorkbench(d.ResolvePreferredWorkbench, d.ResolveWorkbenches, sortedExpanded)
+		}
+		// Build base items (configured icons only, no sessions) — done once
+		baseItems = projectBaseItems(sortedExpanded, cfg.GetIcons())
+		expansionErrors = failed
+		projectsByPath = make(map[string]project.ExpandedProject, len(sortedExpanded))
+		for _, ep := range sortedExpanded {
+			projectsByPath[ep.Path] = ep
+		}
+	}
+	// With [project] stream the first picker opens before the projects are
+	// collected; they are collected in its first iteration instead.
+	streaming := cfg.ProjectStream() && d.Query == "" && !d.All && !d.Recent && !d.Toggle && dir == "" && here == ""
+	if !streaming {
+		sortedExpanded, failed, err := collectProjectsWith(d, cfg, cfgPath, hist, exclude)
+		if err != nil {
+			return err
+		}
+		if dir != "" {
+			var adHoc bool
+			sortedExpanded, adHoc, err = withDirProject(d, cfg, hist, sortedExpanded, dir, exclude)
+			if err != nil {
+				return err
+			}
+			if adHoc {
+				if err := addDirProject(d.Project.FS, cfgPath, dir, d.ConfirmAddDir); err != nil {
+					return err
+				}
+			}
 		}
+		useProjects(sortedExpanded, failed)
 	}
 
 	// Load custom commands for project picker mode
-	var customCommands []ui.UserDefinedCommand
-	for _, cc := range cfg.CommandsForMode("project") {
-		customCommands = append(customCommands, ui.UserDefinedCommand{
-			Key:     cc.Key,
-			Label:   cc.Label,
-			Command: cc.Command,
-			Exit:    cc.Exit,
-		})
-	}
+	customCommands := pickerCommands(cfg, "project")
 
 	// Compute the Update notice once: it surfaces at most once per calendar day,
 	// so a single computation up front stamps shown-at and keeps the badge
@@ -355,8 +519,34 @@ func RunProject(d *ProjectDeps) error {
 		updateNotice = d.UpdateNotice()
 	}
 
+	// The --preview-cmd flag wins over the configured preview_command; with
+	// neither set there is no preview pane.
+	previewCommand := d.PreviewCommand
+	if previewCommand == "" {
+		previewCommand = cfg.PreviewCommandForMode("project")
+	}
+	preview := previewFunc(previewCommand, d.RunPreview)
+	matcher := matcherFunc(cfg.MatcherCommand, d.RunMatcher)
+
+	// --group-by overrides [project] group_by; "none" forces the flat list.
+	grouped := cfg.ProjectGroupBy() == "parent"
+	if d.GroupBy != "" {
+		grouped = d.GroupBy == "parent"
+	}
+
+	// A configured on_select pipeline replaces the built-in Enter sequence. An
+	// invalid one is already in the warning banner; fall back to the default.
+	onSelect, _ := cfg.OnSelectForMode("project")
+
 	// Run picker loop
 	inTmux := d.InTmux()
+
+	// Like the Update notice, pick the tip once so it stays put across
+	// picker-loop iterations and only one tip is retired per run.
+	var tip string
+	if d.NextTip != nil && cfg.TipsEnabled() {
+		tip = d.NextTip(cfg.QuickAccessModifierForMode("project"), cfg.KeyBindingsForPicker(), inTmux)
+	}
 	restoreCursorIdx := -1
 	for {
 		// Refresh session state each iteration
@@ -364,9 +554,22 @@ func RunProject(d *ProjectDeps) error {
 		if cfg.UnreadNotificationsEnabled("project") {
 			attention = d.AttentionSessions()
 		}
-		items := buildSessionAwareItemsWith(baseItems, hist, d.SessionActivity(), excludedSessionNames, attention)
+		activity := d.SessionActivity()
+		var displayNames map[string]string
+		if d.SessionDisplayNames != nil {
+			displayNames = d.SessionDisplayNames()
+		}
+		toItems := func(base []ui.Item) []ui.Item {
+			items := buildSessionAwareItemsWith(base, hist, activity, exclude.sessionNames(), attention)
+			items = applySessionDisplayNames(items, displayNames)
+			if grouped {
+				items = groupItemsByParent(items)
+			}
+			return items
+		}
+		items := toItems(baseItems)
 
-		quickAccessModifier := cfg.GetQuickAccessModifier()
+		quickAccessModifier := cfg.QuickAccessModifierForMode("project")
 		iconLegends := []ui.IconLegend{
 			{Icon: iconDirSession, Desc: "Directory with tmux session"},
 			{Icon: iconStandaloneSession, Desc: "Standalone tmux session"},
@@ -374,24 +577,20 @@ func RunProject(d *ProjectDeps) error {
 		if cfg.UnreadNotificationsEnabled("project") {
 			iconLegends = append(iconLegends, ui.IconLegend{Icon: iconAttention, Desc: "Agent has unread output"})
 		}
-		opts := []ui.PickerOption{
-			ui.WithCursorAtEnd(),
-			ui.WithKillSession(),
-			ui.WithReset(),
-			ui.WithSetPreferredWorkbench(),
-			ui.WithQuickAccess(quickAccessModifier),
-			ui.WithIconLegend(iconLegends...),
-		}
-		if inTmux {
-			opts = append(opts, ui.WithOpenWindow())
-		}
-		if len(customCommands) > 0 {
-			opts = append(opts, ui.WithUserDefinedCommands(customCommands))
+		brokenWorktrees := countBrokenWorktrees(projectsByPath)
+		if brokenWorktrees > 0 {
+			iconLegends = append(iconLegends, ui.IconLegend{Icon: iconBrokenWorktree, Desc: "Worktree with a stale .git pointer (Enter repairs it)"})
 		}
+		opts := append(append(pickerDisplayOptions(cfg, "project", false),
+			ui.WithIconLegend(iconLegends...),
+		), projectPickerKeys(quickAccessModifier, cfg.KeyBindingsForPicker(), inTmux, customCommands)...)
 		warnings := cfg.Warnings
 		if len(expansionErrors) > 0 {
 			warnings = append(warnings, fmt.Sprintf("%d project(s) failed to expand: %s (see pop.log)", len(expansionErrors), strings.Join(expansionErrors, ", ")))
 		}
+		if brokenWorktrees > 0 {
+			warnings = append(warnings, fmt.Sprintf("%d worktree(s) point at missing git metadata (%s); selecting one runs git worktree repair first", brokenWorktrees, iconBrokenWorktree))
+		}
 		warnings = append(warnings, systemWarnings...)
 		if len(warnings) > 0 {
 			opts = append(opts, ui.WithWarnings(warnings))
@@ -403,10 +602,128 @@ func RunProject(d *ProjectDeps) error {
 		if updateNotice != "" {
 			opts = append(opts, ui.WithUpdateNotice(updateNotice))
 		}
-		result, err := d.RunPicker(items, opts...)
+		if preview != nil {
+			opts = append(opts, ui.WithPreview(preview))
+		}
+		if matcher != nil {
+			opts = append(opts, ui.WithMatcher(matcher))
+		}
+		if cfg.GitStatus {
+			opts = append(opts, ui.WithItemStatus(gitItemStatus(d.Project)))
+		}
+		if tip != "" {
+			opts = append(opts, ui.WithTip(tip))
+		}
+		if root := cfg.GetCloneRoot(); root != "" {
+			opts = append(opts, ui.WithCreateProject(createProjectLabel(root)))
+		}
+		if grouped {
+			opts = append(opts, ui.WithGroupHeaders())
+		}
+		var stream *projectStream
+		if streaming {
+			streaming = false
+			stream = startProjectStream(d, cfg, cfgPath, hist, exclude, toItems)
+			// A picker exit that doesn't wait for the collection (cancel,
+			// error) stops its scan.
+			defer stream.cancel()
+			opts = append(opts, ui.WithItemStream(stream.items))
+		}
+		pick := d.RunPicker
+		bulk := false
+		if d.All {
+			// pop open --all: every candidate, marked, stands in for the
+			// first pick.
+			query, tags := d.Query, d.Tags
+			d.Query, d.All = "", false
+			bulk = true
+			pick = func(items []ui.Item, _ ...ui.PickerOption) (ui.Result, error) {
+				return pickAll(query, tags, items)
+			}
+		}
+		if d.Query != "" {
+			// pop open: the best match stands in for the first pick.
+			query, tags := d.Query, d.Tags
+			d.Query = ""
+			pick = func(items []ui.Item, _ ...ui.PickerOption) (ui.Result, error) {
+				result, err := pickByQuery(query, taggedItems(items, tags))
+				if err == nil && d.SessionName != "" {
+					result.Selected.SessionName = d.SessionName
+				}
+				return result, err
+			}
+		}
+		if d.Recent {
+			// pop recent: the last project opened stands in for the first pick.
+			d.Recent = false
+			pick = func(items []ui.Item, _ ...ui.PickerOption) (ui.Result, error) {
+				return pickRecent(hist, d.CurrentSession(d.Tmux), items)
+			}
+		}
+		if d.Toggle {
+			// pop toggle: the client's previous project stands in for the
+			// first pick.
+			d.Toggle = false
+			pick = func(items []ui.Item, _ ...ui.PickerOption) (ui.Result, error) {
+				var pair togglePair
+				if d.ToggleStore != nil && d.CurrentClient != nil {
+					pair = d.ToggleStore.pair(d.CurrentClient(d.Tmux))
+				}
+				return pickToggle(pair, d.CurrentSession(d.Tmux), items)
+			}
+		}
+		if dir != "" {
+			// pop select <dir>: the project at dir stands in for the first pick.
+			target := dir
+			dir = ""
+			pick = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+				return pickDir(target, d.RunPicker, items, opts...)
+			}
+		}
+		if here != "" {
+			// --here: the project holding the working directory stands in
+			// for the first pick.
+			target := here
+			here = ""
+			pick = func(items []ui.Item, _ ...ui.PickerOption) (ui.Result, error) {
+				return pickHere(target, items)
+			}
+		}
+		result, err := pick(items, opts...)
 		if err != nil {
 			return err
 		}
+		if stream != nil {
+			if result.Action == ui.ActionCancel {
+				return nil
+			}
+			// Acting on a pick needs every project's overrides, so wait for
+			// the rest of the expansion.
+			collected := <-stream.done
+			if collected.err != nil {
+				return collected.err
+			}
+			useProjects(collected.projects, collected.failed)
+		}
+
+		if result.Action == ui.ActionBrowseWorktrees {
+			worktreeOpts := append(pickerDisplayOptions(cfg, "project", false),
+				ui.WithQuickAccess(quickAccessModifier), ui.WithSessionFilter())
+			if preview != nil {
+				worktreeOpts = append(worktreeOpts, ui.WithPreview(preview))
+			}
+			if matcher != nil {
+				worktreeOpts = append(worktreeOpts, ui.WithMatcher(matcher))
+			}
+			if cfg.GitStatus {
+				worktreeOpts = append(worktreeOpts, ui.WithItemStatus(gitItemStatus(d.Project)))
+			}
+			result, err = pickProjectWorktree(d, result, toItems(baseItems), projectsByPath, worktreeOpts...)
+			if err != nil {
+				return err
+			}
+		}
+		final = &result
 
 		switch result.Action {
 		case ui.ActionCancel:
@@ -416,17 +733,65 @@ func RunProject(d *ProjectDeps) error {
 			if result.Selected == nil {
 				return nil
 			}
+			// A worktree with a stale .git pointer would open broken: repair
+			// it first, and stay in the picker if that fails.
+			if err := repairBrokenWorktrees(d.Project, projectsByPath, result); err != nil {
+				ui.Notify(ui.LevelError, "%v", err)
+				restoreCursorIdx = result.CursorIndex
+				continue
+			}
+			// open_behavior = "detach-create" only makes sure each chosen
+			// project has a session, leaving the switch for later. on_select
+			// and --tmux-cd say what Enter does themselves.
+			if bulk || (cfg.GetOpenBehavior() == config.OpenBehaviorDetachCreate && onSelect == nil && d.TMuxCDPane == "") {
+				targets := result.Marked
+				if len(targets) == 0 {
+					targets = []ui.Item{*result.Selected}
+				}
+				fireSelectHooks(targets)
+				return ensureProjectSessions(d, cfg, hist, targets)
+			}
+			// Several marked projects open side by side as windows of the
+			// current session; outside tmux there is no session to hold them.
+			if len(result.Marked) > 1 {
+				if inTmux {
+					fireSelectHooks(result.Marked)
+					return openProjectWindows(d, hist, result.Marked)
+				}
+				ui.Notify(ui.LevelInfo, "Opening several projects needs tmux; opening %s", result.Selected.Name)
+			}
+			if inTmux && d.TMuxCDPane == "" {
+				recordToggle(d, result.Selected)
+			}
 			if isStandaloneSession(*result.Selected) {
-				return d.SwitchToTarget(d.Tmux, standaloneSessionName(*result.Selected))
+				name := standaloneSessionName(*result.Selected)
+				if d.RunHook != nil {
+					hooks.fire(config.HookOnSelect, name, "")
+				}
+				return d.SwitchToTarget(d.Tmux, name)
+			}
+			if d.RunHook != nil {
+				hooks.fire(config.HookOnSelect, result.Selected.SessionName, result.Selected.Path)
+			}
+			// --tmux-cd is an explicit per-run request, so it still wins.
+			if onSelect != nil && d.TMuxCDPane == "" {
+				return runSelectPipeline(projectSelectPipeline(d, cfg, hist), onSelect, result.Selected)
 			}
 			if !d.NoHistory {
-				hist.Record(result.Selected.Path)
-				if err := hist.Save(); err != nil {
-					debug.Error("project: save history: %v", err)
-				}
+				recordHistory(hist, result.Selected.Path)
 			}
 			if d.TMuxCDPane != "" {
-				return d.SendCDToPane(d.Tmux, d.TMuxCDPane, result.Selected.Path)
+				return d.SendCDToPane(d.Tmux, d.TMuxCDPane, result.Selected.Path, cfg.GetTmuxCDBusy())
+			}
+			// open_with sends the project to editors instead of, or as well
+			// as, a tmux session.
+			if openWith := projectsByPath[result.Selected.Path].OpenWith; len(openWith) > 0 {
+				if err := openInEditorsWith(d, cfg, openWith, result.Selected.Path); err != nil {
+					return err
+				}
+				if !opensInTmux(openWith) {
+					return nil
+				}
 			}
 			// Preferred workbench (ADR-0078): a resolved per-checkout default
 			// auto-applies silently and suppresses the prompt regardless of
@@ -468,17 +833,39 @@ func RunProject(d *ProjectDeps) error {
 			return d.OpenSession(d.Tmux, result.Selected)
 
 		case ui.ActionOpenWindow:
+			if len(result.Marked) > 1 {
+				fireSelectHooks(result.Marked)
+				return openProjectWindows(d, hist, result.Marked)
+			}
 			if result.Selected == nil || isStandaloneSession(*result.Selected) {
 				continue
 			}
+			fireSelectHooks([]ui.Item{*result.Selected})
 			if !d.NoHistory {
-				hist.Record(result.Selected.Path)
-				if err := hist.Save(); err != nil {
-					debug.Error("project: save history: %v", err)
-				}
+				recordHistory(hist, result.Selected.Path)
 			}
 			return d.OpenWindow(d.Tmux, result.Selected)
 
+		case ui.ActionCreateProject:
+			item, err := createPickerProjectWith(d, cfg, cfgPath, cfg.GetCloneRoot(), result.Query)
+			if err != nil {
+				ui.Notify(ui.LevelError, "%v", err)
+				continue
+			}
+			if !d.NoHistory {
+				recordHistory(hist, item.Path)
+			}
+			return d.OpenSession(d.Tmux, item)
+
+		case ui.ActionOpenEditor:
+			if result.Selected == nil || isStandaloneSession(*result.Selected) {
+				continue
+			}
+			if !d.NoHistory {
+				recordHistory(hist, result.Selected.Path)
+			}
+			return openInEditorsWith(d, cfg, []string{cfg.ProjectEditor()}, result.Selected.Path)
+
 		case ui.ActionYankPath:
 			if result.Selected == nil {
 				return nil
@@ -495,18 +882,27 @@ func RunProject(d *ProjectDeps) error {
 		case ui.ActionKillSession:
 			if result.Selected != nil {
 				restoreCursorIdx = result.CursorIndex
-				if isStandaloneSession(*result.Selected) {
-					d.KillSession(d.Tmux, standaloneSessionName(*result.Selected))
-				} else {
-					d.KillSession(d.Tmux, result.Selected.SessionName)
+				targets := result.Marked
+				if len(targets) == 0 {
+					targets = []ui.Item{*result.Selected}
+				}
+				for _, item := range targets {
+					if isStandaloneSession(item) {
+						d.KillSession(d.Tmux, standaloneSessionName(item))
+					} else {
+						d.KillSession(d.Tmux, item.SessionName)
+					}
+				}
+				if !d.NoHistory {
+					forgetKilledProjects(hist, cfg.ProjectHistoryOnKill(), targets)
 				}
 			}
 			// Continue loop — session state refreshes automatically
 
 		case ui.ActionReset:
 			if result.Selected != nil && !isStandaloneSession(*result.Selected) {
-				hist.Remove(result.Selected.Path)
-				if err := hist.Save(); err != nil {
+				path := result.Selected.Path
+				if err := hist.Update(func(h *history.History) { h.Remove(path) }); err != nil {
 					debug.Error("project: save history: %v", err)
 				}
 				baseItems = sortBaseItemsByHistory(baseItems, hist)
@@ -517,6 +913,18 @@ func RunProject(d *ProjectDeps) error {
 			restoreCursorIdx = result.CursorIndex
 			// Continue loop — items rebuild with fresh attention state
 
+		case ui.ActionRename:
+			if result.Selected != nil && isStandaloneSession(*result.Selected) {
+				name := standaloneSessionName(*result.Selected)
+				if err := renameSessionWith(d.Tmux, name, result.NewName); err != nil {
+					ui.Notify(ui.LevelError, "Failed to rename %s: %v", name, err)
+				} else {
+					ui.Notify(ui.LevelInfo, "Renamed session: %s → %s", name, result.NewName)
+				}
+			}
+			restoreCursorIdx = result.CursorIndex
+			// Continue loop — items rebuild with the new session name
+
 		case ui.ActionSetPreferredWorkbench:
 			// Sets the per-checkout Preferred workbench (ADR-0078); never touches
 			// a running session. Skip standalone sessions (no real checkout).
@@ -527,16 +935,418 @@ func RunProject(d *ProjectDeps) error {
 			// Continue loop — preference set, session state unchanged.
 
 		case ui.ActionUserDefinedCommand:
-			if result.UserDefinedCommand != nil && result.Selected != nil {
-				d.RunCustomCommand(result.UserDefinedCommand.Command, result.Selected)
+			if result.UserDefinedCommand != nil && result.UserDefinedCommand.Multi && len(result.Marked) > 0 {
+				metadata := make([]itemMetadata, len(result.Marked))
+				for i := range result.Marked {
+					metadata[i] = projectItemMetadata(&result.Marked[i], projectsByPath)
+				}
+				d.RunMultiCustomCommand(result.UserDefinedCommand.Command, result.Marked, itemsJSONEnv(metadata))
 				if result.UserDefinedCommand.Exit {
 					return nil
 				}
+			} else if result.UserDefinedCommand != nil && result.Selected != nil {
+				d.RunCustomCommand(result.UserDefinedCommand.Command, result.Selected,
+					itemJSONEnv(projectItemMetadata(result.Selected, projectsByPath)))
+				if result.UserDefinedCommand.Exit {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// projectBaseItems converts expanded projects into picker items carrying
+// their configured icons and, in iconStyle, their type icons, before any
+// session state is applied.
+func projectBaseItems(projects []project.ExpandedProject, iconStyle string) []ui.Item {
+	items := make([]ui.Item, len(projects))
+	for i, ep := range projects {
+		items[i] = ui.Item{
+			Name:        ep.Name,
+			Path:        ep.Path,
+			Context:     ep.ProjectName,
+			SessionName: ep.SessionName,
+			Group:       ep.Group,
+			Icon:        ep.Icon,
+			TypeIcon:    projectTypeIcon(iconStyle, ep.Type),
+			Tags:        ep.Tags,
+			Pinned:      ep.Pinned,
+		}
+		if ep.Broken {
+			items[i].Icon = iconBrokenWorktree
+		}
+	}
+	return items
+}
+
+// projectStream is a project collection running behind an open picker
+// ([project] stream). items feeds ui.WithItemStream and is closed once the
+// collection ends; done then receives its outcome. cancel stops the
+// collection's scan, for when the picker exits without needing it.
+type projectStream struct {
+	items  chan []ui.Item
+	done   chan projectStreamResult
+	cancel context.CancelFunc
+}
+
+type projectStreamResult struct {
+	projects []project.ExpandedProject
+	failed   []string
+	err      error
+}
+
+// startProjectStream runs collectProjectsStreamWith in the background and
+// sends each snapshot, converted by toItems, to the picker. A snapshot the
+// picker has not read yet is replaced rather than queued, so the collection
+// never blocks on a picker that has already quit.
+func startProjectStream(d *ProjectDeps, cfg *config.Config, cfgPath string, hist *history.History, exclude *sessionExclusion, toItems func([]ui.Item) []ui.Item) *projectStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &projectStream{
+		items:  make(chan []ui.Item, 1),
+		done:   make(chan projectStreamResult, 1),
+		cancel: cancel,
+	}
+	send := func(projects []project.ExpandedProject) {
+		items := toItems(projectBaseItems(projects, cfg.GetIcons()))
+		select {
+		case <-s.items:
+		default:
+		}
+		s.items <- items
+	}
+	go func() {
+		projects, failed, err := collectProjectsStreamWith(ctx, d, cfg, cfgPath, hist, exclude, send)
+		switch {
+		case errors.Is(err, context.Canceled):
+			// The picker is gone; nobody waits for the projects.
+		case err != nil:
+			ui.Notify(ui.LevelError, "%v", err)
+		default:
+			send(projects)
+		}
+		close(s.items)
+		s.done <- projectStreamResult{projects: projects, failed: failed, err: err}
+	}()
+	return s
+}
+
+// collectProjectsWith expands the configured projects (worktrees of bare
+// repos included) plus the pop-managed worktrees, drops the entries exclude
+// matches, disambiguates display names, and sorts by history frecency, most
+// recent last. It also returns the per-project expansion
+// failures, which are non-fatal unless nothing expanded at all. cfgPath
+// identifies the config to a running pop daemon and feeds the "no projects
+// found" message.
+func collectProjectsWith(d *ProjectDeps, cfg *config.Config, cfgPath string, hist *history.History, exclude *sessionExclusion) ([]project.ExpandedProject, []string, error) {
+	return collectProjectsStreamWith(context.Background(), d, cfg, cfgPath, hist, exclude, nil)
+}
+
+// collectProjectsStreamWith is collectProjectsWith that also calls progress,
+// when non-nil, with the disambiguated, sorted projects expanded so far each
+// time another configured path finishes. The return value stays the complete
+// list, managed worktrees included. Once ctx is done the scan stops and
+// ctx's error is returned.
+func collectProjectsStreamWith(ctx context.Context, d *ProjectDeps, cfg *config.Config, cfgPath string, hist *history.History, exclude *sessionExclusion, progress func([]project.ExpandedProject)) ([]project.ExpandedProject, []string, error) {
+	// The projects list is essential to this command (ADR 0054): a blocking
+	// finding on it leaves nothing to switch to, so the call site treats the
+	// getter's error as fatal. Non-essential findings (display_depth, a bad
+	// glob) are not surfaced here — they degrade to the picker's warning banner.
+	if _, err := cfg.ProjectEntries(); err != nil {
+		return nil, nil, fmt.Errorf("invalid projects configuration: %w", err)
+	}
+
+	// Discover pop-managed worktrees concurrently with the configured-project
+	// expansion (ADR-0110). The walk is filesystem-only — no store, no git — so
+	// it can't slow expansion or fork; a nil seam simply contributes nothing.
+	managedCh := make(chan []project.ExpandedProject, 1)
+	go func() {
+		if d.ManagedWorktrees == nil {
+			managedCh <- nil
+			return
+		}
+		managedCh <- d.ManagedWorktrees()
+	}()
+
+	// Expand projects, showing worktrees for bare repos (parallel).
+	// Per-project errors and panics are captured so one bad project can't
+	// crash the whole project flow.
+	var expandProgress func([]project.ExpandedProject)
+	if progress != nil {
+		expandProgress = func(partial []project.ExpandedProject) {
+			progress(finishProjects(cfg, hist, partial, exclude))
+		}
+	}
+	// A running pop daemon has the expansion ready; without one, scan.
+	var (
+		expanded        []project.ExpandedProject
+		expansionErrors []string
+		cached          bool
+	)
+	if d.CachedProjects != nil {
+		var findings []config.Finding
+		expanded, expansionErrors, findings, cached = d.CachedProjects(cfgPath)
+		// The daemon's expansion ran into these for the same config; record
+		// them as a scan here would, so the warnings banner shows them.
+		cfg.RecordFindings(findings)
+	}
+	if !cached {
+		var err error
+		expanded, expansionErrors, err = scanProjectsWith(ctx, d.Project, cfg, cfgPath, expandProgress)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Write the glob cache now rather than on exit: with popup_switch =
+		// "close_popup" the session switch kills pop before it gets there.
+		if err := config.FlushGlobCache(); err != nil {
+			debug.Error("glob cache: %v", err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Fold in the managed worktrees; they sort by History recency alongside
+	// configured entries and dedupe against live sessions like any other entry.
+	expanded = append(expanded, (<-managedCh)...)
+
+	// Follow projects that moved since their history was recorded, before
+	// sorting by it. Prune after relinking so a moved project's entry is
+	// followed rather than dropped as missing.
+	relinkHistory(d, hist, expanded)
+	pruneHistory(d, cfg, hist)
+
+	sortedExpanded := finishProjects(cfg, hist, expanded, exclude)
+
+	// If every single project failed to expand, we can't start normal
+	// handling — surface the failure instead of an empty list.
+	if len(sortedExpanded) == 0 && len(expansionErrors) > 0 {
+		return nil, nil, fmt.Errorf("failed to expand any projects: %d errors (see ~/.local/share/pop/pop.log for details)", len(expansionErrors))
+	}
+	return sortedExpanded, expansionErrors, nil
+}
+
+// relinkHistory points hist's entries for moved projects at their new paths
+// and backfills project identities, saving hist when anything changed.
+func relinkHistory(d *ProjectDeps, hist *history.History, expanded []project.ExpandedProject) {
+	if d.NoHistory {
+		return
+	}
+	paths := make([]string, len(expanded))
+	for i, ep := range expanded {
+		paths[i] = ep.Path
+	}
+	// Relinking stats and identifies paths, so only take the history lock
+	// when the loaded copy shows there is something to relink.
+	if !hist.Relink(paths) {
+		return
+	}
+	if err := hist.Update(func(h *history.History) { h.Relink(paths) }); err != nil {
+		debug.Error("relinkHistory: save history: %v", err)
+	}
+}
+
+// pruneHistory applies [project] history_limit and history_prune_missing to
+// hist, saving it when entries were dropped.
+func pruneHistory(d *ProjectDeps, cfg *config.Config, hist *history.History) {
+	if d.NoHistory {
+		return
+	}
+	opts := history.PruneOptions{
+		Missing: cfg.ProjectHistoryPruneMissing(),
+		Limit:   cfg.ProjectHistoryLimit(),
+	}
+	if len(hist.Prune(opts)) == 0 {
+		return
+	}
+	if err := hist.Update(func(h *history.History) { h.Prune(opts) }); err != nil {
+		debug.Error("pruneHistory: save history: %v", err)
+	}
+}
+
+// scanProjectsWith expands cfg's project globs and then each matched path,
+// reporting progress as expandProjectsStreamWith does, scan_concurrency paths
+// at a time until ctx is done. With icons on, it also detects each project's
+// type.
+func scanProjectsWith(ctx context.Context, d *project.Deps, cfg *config.Config, cfgPath string, progress func([]project.ExpandedProject)) ([]project.ExpandedProject, []string, error) {
+	paths, err := cfg.ExpandProjects()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to expand projects: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no projects found. Check your config at %s", cfgPath)
+	}
+	detectTypes := projectTypeDetector(d, cfg)
+	if detectTypes != nil && progress != nil {
+		report := progress
+		progress = func(partial []project.ExpandedProject) {
+			detectTypes(partial)
+			report(partial)
+		}
+	}
+	expanded, failed := expandProjectsStreamWith(ctx, d, paths, cfg.GetScanConcurrency(), progress)
+	if detectTypes != nil {
+		detectTypes(expanded)
+	}
+	return expanded, failed, nil
+}
+
+// finishProjects drops the expanded projects exclude matches, disambiguates
+// display names, and sorts by history frecency, most recent last. It reuses
+// expanded's backing array.
+func finishProjects(cfg *config.Config, hist *history.History, expanded []project.ExpandedProject, exclude *sessionExclusion) []project.ExpandedProject {
+	if exclude != nil {
+		filtered := expanded[:0]
+		for _, ep := range expanded {
+			if !exclude.excludes(ep) {
+				filtered = append(filtered, ep)
+			}
+		}
+		expanded = filtered
+	}
+
+	// Disambiguate projects with the same name
+	project.DisambiguateNames(expanded, cfg.GetDisambiguationStrategy())
+
+	// Sort by recency (oldest first, most recent last).
+	// Convert to Project for sorting, then back
+	projects := make([]project.Project, len(expanded))
+	for i, ep := range expanded {
+		projects[i] = project.Project{Name: ep.Name, Path: ep.Path}
+	}
+	projects = hist.SortByRecency(projects)
+
+	// Rebuild expanded list in sorted order
+	pathToExpanded := make(map[string]project.ExpandedProject)
+	for _, ep := range expanded {
+		pathToExpanded[ep.Path] = ep
+	}
+	sortedExpanded := make([]project.ExpandedProject, len(projects))
+	for i, p := range projects {
+		sortedExpanded[i] = pathToExpanded[p.Path]
+	}
+	return sortedExpanded
+}
+
+// projectSelectPipeline wires the on_select steps for the project picker.
+// ensure_session auto-applies the checkout's preferred Workbench like the
+// built-in path does, but never prompts: pick_on_create is for interactive
+// Enter, and a pipeline spells out what happens instead.
+func projectSelectPipeline(d *ProjectDeps, cfg *config.Config, hist *history.History) *selectPipelineDeps {
+	return &selectPipelineDeps{
+		RecordHistory: func(path string) {
+			if d.NoHistory {
+				return
+			}
+			recordHistory(hist, path)
+		},
+		EnsureSession: func(item *ui.Item) error {
+			if d.Tmux.HasSession(item.SessionName) {
+				return nil
+			}
+			preferred, warns := d.ResolvePreferredWorkbench(cfg, item.Path)
+			for _, w := range warns {
+				debug.Error("project: %s", w)
+			}
+			return d.EnsureSession(d.Tmux, item, preferred)
+		},
+		Switch: func(item *ui.Item) error {
+			return d.SwitchToTarget(d.Tmux, item.SessionName)
+		},
+		Run: d.RunPipelineCommand,
+	}
+}
+
+// openProjectWindows opens each of items as a window of the current session,
+// recording each in history. Standalone sessions are skipped; the first
+// failure stops the rest.
+func openProjectWindows(d *ProjectDeps, hist *history.History, items []ui.Item) error {
+	var opened []string
+	for i := range items {
+		if isStandaloneSession(items[i]) {
+			continue
+		}
+		opened = append(opened, items[i].Path)
+		if err := d.OpenWindow(d.Tmux, &items[i]); err != nil {
+			return err
+		}
+	}
+	if !d.NoHistory {
+		recordHistory(hist, opened...)
+	}
+	return nil
+}
+
+// recordHistory records an access to each of paths in hist and saves it,
+// logging (not propagating) failures.
+func recordHistory(hist *history.History, paths ...string) {
+	err := hist.Update(func(h *history.History) {
+		for _, p := range paths {
+			h.Record(p)
+		}
+	})
+	if err != nil {
+		debug.Error("project: save history: %v", err)
+	}
+}
+
+// forgetKilledProjects applies [project] history_on_kill to the history
+// entries of the projects among killed, saving history when it changed.
+func forgetKilledProjects(hist *history.History, behavior string, killed []ui.Item) {
+	if behavior == config.HistoryOnKillKeep {
+		return
+	}
+	err := hist.Update(func(h *history.History) {
+		for _, item := range killed {
+			if isStandaloneSession(item) {
+				continue
 			}
+			if behavior == config.HistoryOnKillRemove {
+				h.Remove(item.Path)
+			} else {
+				h.RecordKill(item.Path)
+			}
+		}
+	})
+	if err != nil {
+		debug.Error("project: save history: %v", err)
+	}
+}
+
+// ensureProjectSessions creates the missing session of each of items,
+// detached and from d.Workbench, else its Preferred workbench when one
+// resolves, recording each in history. Standalone sessions already exist and
+// are skipped; the first failure stops the rest.
+func ensureProjectSessions(d *ProjectDeps, cfg *config.Config, hist *history.History, items []ui.Item) error {
+	var ensured []string
+	for i := range items {
+		item := &items[i]
+		if isStandaloneSession(*item) {
+			continue
+		}
+		ensured = append(ensured, item.Path)
+		if d.Tmux.HasSession(item.SessionName) {
+			continue
+		}
+		preferred := d.Workbench
+		if preferred == "" {
+			var warns []string
+			preferred, warns = d.ResolvePreferredWorkbench(cfg, item.Path)
+			for _, w := range warns {
+				debug.Error("project: %s", w)
+			}
+		}
+		if err := d.EnsureSession(d.Tmux, item, preferred); err != nil {
+			return err
 		}
 	}
+	if !d.NoHistory {
+		recordHistory(hist, ensured...)
+	}
+	return nil
 }
 
+// sortBaseItemsByHistory orders items by history recency, least first, with
+// pinned projects after the rest as sortByUnifiedRecency has them.
 func sortBaseItemsByHistory(items []ui.Item, hist *history.History) []ui.Item {
 	projects := make([]project.Project, len(items))
 	for i, item := range items {
@@ -551,6 +1361,9 @@ func sortBaseItemsByHistory(items []ui.Item, hist *history.History) []ui.Item {
 	for i, p := range projects {
 		sorted[i] = pathToItem[p.Path]
 	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return !sorted[i].Pinned && sorted[j].Pinned
+	})
 	return sorted
 }
 
@@ -569,14 +1382,15 @@ func buildSessionAwareItemsWith(baseItems []ui.Item, hist *history.History, sess
 		projectSessionNames[item.SessionName] = true
 	}
 
-	// Apply icons to project items that have active sessions
+	// Apply icons to project items that have active sessions; others keep
+	// their configured icon, if any
 	items := make([]ui.Item, len(baseItems))
 	copy(items, baseItems)
 	for i := range items {
-		if _, hasSession := sessionActivity[items[i].SessionName]; hasSession {
+		_, hasSession := sessionActivity[items[i].SessionName]
+		items[i].HasSession = hasSession
+		if hasSession {
 			items[i].Icon = iconDirSession
-		} else {
-			items[i].Icon = ""
 		}
 	}
 
@@ -597,9 +1411,10 @@ func buildSessionAwareItemsWith(baseItems []ui.Item, hist *history.History, sess
 				icon = iconAttention
 			}
 			items = append(items, ui.Item{
-				Name: sessionName,
-				Path: tmuxSessionPathPrefix + sessionName,
-				Icon: icon,
+				Name:       sessionName,
+				Path:       tmuxSessionPathPrefix + sessionName,
+				Icon:       icon,
+				HasSession: true,
 			})
 		}
 	}
@@ -608,33 +1423,64 @@ func buildSessionAwareItemsWith(baseItems []ui.Item, hist *history.History, sess
 	return sortByUnifiedRecency(items, hist, sessionActivity)
 }
 
+// groupItemsByParent makes each Group contiguous while keeping recency both
+// across and within groups: groups are ordered by their most recent member
+// (which, like every list here, sorts last) and members keep their relative
+// recency order. items must already be sorted oldest first.
+func groupItemsByParent(items []ui.Item) []ui.Item {
+	lastIndex := make(map[string]int, len(items))
+	for i, item := range items {
+		lastIndex[item.Group] = i
+	}
+	grouped := make([]ui.Item, len(items))
+	copy(grouped, items)
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return lastIndex[grouped[i].Group] < lastIndex[grouped[j].Group]
+	})
+	return grouped
+}
+
+// sortByUnifiedRecency orders projects and standalone sessions on one
+// frecency timeline, least first: history entries score by access count and
+// age, and a standalone session (never recorded in history) scores as a single
+// access at its tmux activity time. Pinned projects follow everything else,
+// ordered the same way among themselves, so they stay nearest the cursor.
 func sortByUnifiedRecency(items []ui.Item, hist *history.History, sessionActivity map[string]int64) []ui.Item {
-	historyTimes := make(map[string]time.Time)
+	historyEntries := make(map[string]history.Entry)
 	for _, e := range hist.Entries {
-		historyTimes[e.Path] = e.LastAccess
+		historyEntries[e.Path] = e
 	}
+	now := time.Now()
 
-	getAccessTime := func(item ui.Item) (time.Time, bool) {
-		if t, ok := historyTimes[item.Path]; ok {
-			return t, true
+	type access struct {
+		score float64
+		last  time.Time
+	}
+	getAccess := func(item ui.Item) (access, bool) {
+		if e, ok := historyEntries[item.Path]; ok {
+			return access{e.Frecency(now), e.LastAccess}, true
 		}
 		if isStandaloneSession(item) {
 			if ts, ok := sessionActivity[standaloneSessionName(item)]; ok {
-				return time.Unix(ts, 0), true
+				last := time.Unix(ts, 0)
+				return access{history.FrecencyScore(1, last, now), last}, true
 			}
 		}
-		return time.Time{}, false
+		return access{}, false
 	}
 
 	sorted := make([]ui.Item, len(items))
 	copy(sorted, items)
 
 	sort.SliceStable(sorted, func(i, j int) bool {
-		ti, oki := getAccessTime(sorted[i])
-		tj, okj := getAccessTime(sorted[j])
+		if sorted[i].Pinned != sorted[j].Pinned {
+			return sorted[j].Pinned
+		}
+		ai, oki := getAccess(sorted[i])
+		aj, okj := getAccess(sorted[j])
 
 		if oki && okj {
-			return ti.Before(tj)
+			return history.LessFrecent(ai.score, ai.last, aj.score, aj.last)
 		}
 		if oki {
 			return false
@@ -745,6 +1591,15 @@ func promptWorkbenchForCreate(d *ProjectDeps, order []string, workbenches []conf
 // It is the production implementation of ProjectDeps.OpenSessionWithWorkbench
 // (ADR-0075 picker create-path).
 func openTmuxSessionWithWorkbenchWith(tmux deps.Tmux, item *ui.Item, workbenchName string) error {
+	if err := createWorkbenchSessionWith(tmux, item, workbenchName); err != nil {
+		return err
+	}
+	return switchToTmuxTargetWith(tmux, item.SessionName)
+}
+
+// createWorkbenchSessionWith creates the item's session from the named
+// Workbench without attaching to it.
+func createWorkbenchSessionWith(tmux deps.Tmux, item *ui.Item, workbenchName string) error {
 	td := defaultTemplateRuntimeDeps()
 	td.Tmux = tmux
 	cfg, err := td.LoadConfig()
@@ -759,89 +1614,83 @@ func openTmuxSessionWithWorkbenchWith(tmux deps.Tmux, item *ui.Item, workbenchNa
 	if !ok {
 		return fmt.Errorf("workbench %q not found", workbenchName)
 	}
-	if err := createSessionFromWorkbench(td, tmpl, item.SessionName, item.Path); err != nil {
-		return err
+	return createSessionFromWorkbench(td, tmpl, item.SessionName, item.Path)
+}
+
+// ensureTmuxSessionWith is the production ProjectDeps.EnsureSession: a
+// Workbench session when workbenchName is set, else a flat one.
+func ensureTmuxSessionWith(tmux deps.Tmux, item *ui.Item, workbenchName string) error {
+	if workbenchName != "" {
+		return createWorkbenchSessionWith(tmux, item, workbenchName)
 	}
-	return switchToTmuxTargetWith(tmux, item.SessionName)
+	return session.EnsureWith(sessionDeps(tmux), item.SessionName, item.Path)
 }
 
-func openTmuxWindow(item *ui.Item) error {
-	return openTmuxWindowWith(defaultTmux, item)
+// runProjectPipelineCommand runs an on_select run: step in the project
+// directory with the same POP_* variables as custom commands.
+func runProjectPipelineCommand(command string, item *ui.Item) error {
+	return runPipelineCommand(command, item.Path,
+		"POP_PATH="+item.Path,
+		"POP_NAME="+item.Name,
+		"POP_SESSION_NAME="+item.SessionName,
+	)
 }
 
 func openTmuxWindowWith(tmux deps.Tmux, item *ui.Item) error {
-	windowName := sanitizeSessionName(item.Name)
-
-	session, err := tmux.Command("display-message", "-p", "#S")
-	if err != nil {
-		return fmt.Errorf("failed to get current tmux session: %w", err)
-	}
-
-	listOut, err := tmux.Command("list-windows", "-t", session, "-F", "#{window_name}")
-	if err != nil {
-		return fmt.Errorf("failed to list tmux windows: %w", err)
-	}
-
-	for _, name := range strings.Split(listOut, "\n") {
-		if name == windowName {
-			_, err := tmux.Command("select-window", "-t", session+":"+windowName)
-			return err
-		}
-	}
-
-	_, err = tmux.Command("new-window", "-t", session, "-n", windowName, "-c", item.Path)
-	return err
+	return session.OpenWindowWith(sessionDeps(tmux), sanitizeSessionName(item.Name), item.Path)
 }
 
 func sanitizeSessionName(name string) string {
-	// Replace dots and colons with underscores for tmux compatibility
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, ":", "_")
-	return name
-}
-
-func killTmuxSession(name string) {
-	killTmuxSessionWith(defaultTmux, name)
+	return project.SafeSessionName(name)
 }
 
+// killTmuxSessionWith kills the session named name, which is already a
+// session name: escaping it again would miss the session.
 func killTmuxSessionWith(tmux deps.Tmux, name string) {
-	sessionName := sanitizeSessionName(name)
-	_, err := tmux.Command("kill-session", "-t", sessionName)
-	if err != nil {
-		debug.Error("killTmuxSession %s: %v", sessionName, err)
-		fmt.Fprintf(os.Stderr, "Failed to kill session: %s\n", sessionName)
-	} else {
-		fmt.Fprintf(os.Stderr, "Killed session: %s\n", sessionName)
-	}
+	killTmuxSessionByNameWith(tmux, name)
 }
 
-func executeProjectCustomCommand(command string, item *ui.Item) {
+// executeProjectCustomCommand runs a project user-defined command with the
+// item's POP_* variables; extraEnv is appended to them.
+func executeProjectCustomCommand(command string, item *ui.Item, extraEnv ...string) {
 	cmd := exec.Command("sh", "-c", command)
 	cmd.Env = append(os.Environ(),
 		"POP_PATH="+item.Path,
 		"POP_NAME="+item.Name,
 	)
+	cmd.Env = append(cmd.Env, extraEnv...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 	if err := cmd.Run(); err != nil {
 		debug.Error("project: custom command %q: %v", command, err)
-		fmt.Fprintf(os.Stderr, "Custom command failed: %v\n", err)
+		ui.Notify(ui.LevelError, "Custom command failed: %v", err)
 	}
 }
 
-func sendCDToPane(paneID, path string) error {
-	return sendCDToPaneWith(defaultTmux, paneID, path)
-}
-
-func sendCDToPaneWith(tmux deps.Tmux, paneID, path string) error {
-	_, err := tmux.Command("send-keys", "-t", paneID, fmt.Sprintf("cd %q && clear", path), "Enter")
-	return err
+// sendCDToPaneWith types a cd into path at paneID's prompt. When the pane is
+// running something other than a shell — vim, a REPL — the keys would land in
+// that program instead, so busy (a config.TmuxCDBusy* value) decides: refuse,
+// open a split in path instead, or send regardless.
+func sendCDToPaneWith(tmux deps.Tmux, paneID, path, busy string) error {
+	sd := sessionDeps(tmux)
+	if busy != config.TmuxCDBusySend {
+		command, err := session.PaneCommandWith(sd, paneID)
+		if err != nil {
+			return fmt.Errorf("failed to read pane %s: %w", paneID, err)
+		}
+		if !session.IsShell(command) {
+			if busy == config.TmuxCDBusySplit {
+				return session.SplitPaneWith(sd, paneID, path)
+			}
+			return fmt.Errorf("pane %s is running %s, not a shell; not sending cd (set tmux_cd_busy = \"split\" or \"send\" to change this)", paneID, command)
+		}
+	}
+	return session.SendCDWith(sd, paneID, path)
 }
 
 func yankPathToPaneWith(tmux deps.Tmux, paneID, path string) error {
-	_, err := tmux.Command("send-keys", "-t", paneID, path)
-	return err
+	return session.SendTextWith(sessionDeps(tmux), paneID, path)
 }
 
 // discoverManagedWorktreesWith walks the pop-managed worktrees root with
@@ -916,12 +1765,62 @@ func expandProjects(paths []config.ExpandedPath) ([]project.ExpandedProject, []s
 	return expandProjectsWith(project.DefaultDeps(), paths)
 }
 
+// worktreeDisplayName returns the picker name of the worktree dir at path per
+// the entry's worktree_display. A branch that cannot be read falls back to the
+// directory name.
+func worktreeDisplayName(d *project.Deps, dir, path, mode string) string {
+	if mode != config.WorktreeDisplayBranch && mode != config.WorktreeDisplayBoth {
+		return dir
+	}
+	branch := project.WorktreeBranchWith(d, path)
+	switch {
+	case branch == "":
+		return dir
+	case mode == config.WorktreeDisplayBranch:
+		return branch
+	case branch == dir:
+		return dir
+	}
+	return dir + " (" + branch + ")"
+}
+
+// applyProjectOverrides copies an entry's per-project settings onto one of
+// the projects it expanded to. The session name is applied during expansion.
+func applyProjectOverrides(p *project.ExpandedProject, o config.ProjectOverrides) {
+	p.Command = o.Command
+	p.Container = o.Container
+	p.Workbench = o.Workbench
+	p.Icon = o.Icon
+	p.Tags = o.Tags
+	p.Pinned = o.Pinned
+	p.OpenWith = o.OpenWith
+}
+
+// bareWorktreeRepo is project.BareWorktreeRepoWith, skipped when the path's
+// entry turns worktree scanning off.
+func bareWorktreeRepo(d *project.Deps, ep config.ExpandedPath) (string, bool) {
+	if ep.SkipWorktrees {
+		return "", false
+	}
+	return project.BareWorktreeRepoWith(d, ep.Path)
+}
+
 // expandProjectsWith expands each configured path into one or more ExpandedProjects
-// in parallel. Bare repos with worktrees are expanded to individual worktrees;
-// regular directories become a single entry. The returned slice preserves the
-// input order. failedNames contains filepath.Base of any paths whose expansion
-// errored or panicked — expansion of other paths continues in both cases.
+// in parallel, config.DefaultScanConcurrency paths at a time. Bare repos with
+// worktrees are expanded to individual worktrees; regular directories become
+// a single entry. The returned slice preserves the input order. failedNames
+// contains filepath.Base of any paths whose expansion errored or panicked —
+// expansion of other paths continues in both cases.
 func expandProjectsWith(d *project.Deps, paths []config.ExpandedPath) (expanded []project.ExpandedProject, failedNames []string) {
+	return expandProjectsStreamWith(context.Background(), d, paths, config.DefaultScanConcurrency, nil)
+}
+
+// expandProjectsStreamWith is expandProjectsWith that scans at most limit
+// paths at once and also calls progress, when non-nil, with the projects
+// expanded so far (in input order) each time a path finishes. Once ctx is
+// done no further path is scanned; the paths already in flight finish and
+// the result holds whatever expanded before.
+func expandProjectsStreamWith(ctx context.Context, d *project.Deps, paths []config.ExpandedPath, limit int, progress func([]project.ExpandedProject)) (expanded []project.ExpandedProject, failedNames []string) {
 	type expandResult struct {
 		index    int
 		path     string
@@ -930,84 +1829,133 @@ func expandProjectsWith(d *project.Deps, paths []config.ExpandedPath) (expanded
 	}
 
 	results := make(chan expandResult, len(paths))
-	var wg sync.WaitGroup
-
-	for i, p := range paths {
-		wg.Add(1)
-		go func(idx int, ep config.ExpandedPath) {
-			defer wg.Done()
-
-			var (
-				projects  []project.ExpandedProject
-				expandErr error
-			)
-
-			// Recover from panics inside the goroutine so one bad project
-			// can't crash the whole process. The panic becomes an error
-			// on the result channel and flows through the existing error
-			// handling below.
-			defer func() {
-				if r := recover(); r != nil {
-					expandErr = fmt.Errorf("panic expanding %s: %v", ep.Path, r)
-					debug.Error("expandProjects: panic on %q: %v\n%s", ep.Path, r, runtimedebug.Stack())
-				}
-				results <- expandResult{index: idx, path: ep.Path, projects: projects, err: expandErr}
-			}()
-
-			displayName := ui.LastNSegments(ep.Path, ep.DisplayDepth)
-			projectName := filepath.Base(ep.Path)
-
-			if project.HasWorktreesWith(d, ep.Path) {
-				// Bare repo with worktrees - expand to individual worktrees
-				worktrees, err := project.ListWorktreesForPathWith(d, ep.Path)
-				if err != nil {
-					expandErr = err
-					return
-				}
-				ctx := &project.RepoContext{RepoName: projectName, IsBare: true}
-				for _, wt := range worktrees {
-					projects = append(projects, project.ExpandedProject{
-						Name:         displayName + "/" + wt.Name,
-						ProjectLabel: displayName,
-						Path:         wt.Path,
-						ProjectName:  projectName,
-						IsWorktree:   true,
-						SessionName:  project.TmuxSessionName(ctx, wt.Name),
-					})
-				}
-			} else {
-				// Regular project
+	var g errgroup.Group
+	g.SetLimit(limit)
+
+	expand := func(idx int, ep config.ExpandedPath) {
+		var (
+			projects  []project.ExpandedProject
+			expandErr error
+		)
+
+		// Recover from panics inside the goroutine so one bad project
+		// can't crash the whole process. The panic becomes an error
+		// on the result channel and flows through the existing error
+		// handling below.
+		defer func() {
+			if r := recover(); r != nil {
+				expandErr = fmt.Errorf("panic expanding %s: %v", ep.Path, r)
+				debug.Error("expandProjects: panic on %q: %v\n%s", ep.Path, r, runtimedebug.Stack())
+			}
+			results <- expandResult{index: idx, path: ep.Path, projects: projects, err: expandErr}
+		}()
+
+		displayName := ui.LastNSegments(ep.Path, ep.DisplayDepth)
+		projectName := filepath.Base(ep.Path)
+		// A custom session_name replaces the directory-derived part of the
+		// session name: the whole name for a regular project, the repo
+		// prefix for worktrees of a bare repo.
+		sessionBase := projectName
+		if ep.Overrides.SessionName != "" {
+			sessionBase = ep.Overrides.SessionName
+		}
+
+		if !ep.SkipWorktrees && project.HasWorktreesWith(d, ep.Path) {
+			// Bare repo with worktrees - expand to individual worktrees
+			worktrees, err := project.ListWorktreesForPathWith(d, ep.Path)
+			if err != nil {
+				expandErr = err
+				return
+			}
+			ctx := &project.RepoContext{RepoName: sessionBase, IsBare: true}
+			for _, wt := range worktrees {
 				projects = append(projects, project.ExpandedProject{
-					Name:         displayName,
+					Name:         displayName + "/" + worktreeDisplayName(d, wt.Name, wt.Path, ep.WorktreeDisplay),
 					ProjectLabel: displayName,
-					Path:         ep.Path,
+					Path:         wt.Path,
 					ProjectName:  projectName,
-					IsWorktree:   false,
-					SessionName:  project.TmuxSessionName(&project.RepoContext{IsBare: false}, filepath.Base(ep.Path)),
+					IsWorktree:   true,
+					Branch:       wt.Branch,
+					RepoRoot:     ep.Path,
+					SessionName:  project.TmuxSessionName(ctx, wt.Name),
+					Group:        filepath.Dir(ep.Path),
+					Broken:       wt.Broken,
 				})
 			}
-		}(i, p)
+		} else if repoRoot, ok := bareWorktreeRepo(d, ep); ok {
+			// A glob matched a bare repo's worktree directly (e.g.
+			// ~/Dev/repo/*): name and session it repo/worktree, exactly as
+			// if it had been expanded from the repo.
+			repoLabel := ui.LastNSegments(repoRoot, ep.DisplayDepth)
+			repoName := filepath.Base(repoRoot)
+			ctx := &project.RepoContext{RepoName: repoName, IsBare: true}
+			projects = append(projects, project.ExpandedProject{
+				Name:         repoLabel + "/" + worktreeDisplayName(d, projectName, ep.Path, ep.WorktreeDisplay),
+				ProjectLabel: repoLabel,
+				Path:         ep.Path,
+				ProjectName:  repoName,
+				IsWorktree:   true,
+				RepoRoot:     repoRoot,
+				SessionName:  project.TmuxSessionName(ctx, projectName),
+				Group:        filepath.Dir(repoRoot),
+				Broken:       project.StaleWorktreeWith(d, ep.Path),
+			})
+		} else {
+			// Regular project
+			projects = append(projects, project.ExpandedProject{
+				Name:         displayName,
+				ProjectLabel: displayName,
+				Path:         ep.Path,
+				ProjectName:  projectName,
+				IsWorktree:   false,
+				SessionName:  project.TmuxSessionName(&project.RepoContext{IsBare: false}, sessionBase),
+				Group:        filepath.Dir(ep.Path),
+			})
+		}
+		for i := range projects {
+			applyProjectOverrides(&projects[i], ep.Overrides)
+		}
 	}
 
+	// Launch from a goroutine: g.Go blocks while limit paths are in flight,
+	// and the results must be collected meanwhile to report progress.
 	go func() {
-		wg.Wait()
+		for i, p := range paths {
+			if ctx.Err() != nil {
+				break
+			}
+			g.Go(func() error {
+				// g.Go may have waited for a slot past the end of ctx.
+				if ctx.Err() != nil {
+					return nil
+				}
+				expand(i, p)
+				return nil
+			})
+		}
+		g.Wait()
 		close(results)
 	}()
 
 	// Collect results maintaining original order
 	resultsByIndex := make(map[int][]project.ExpandedProject, len(paths))
+	flatten := func() []project.ExpandedProject {
+		var flat []project.ExpandedProject
+		for i := range paths {
+			flat = append(flat, resultsByIndex[i]...)
+		}
+		return flat
+	}
 	for r := range results {
 		resultsByIndex[r.index] = r.projects
 		if r.err != nil {
 			debug.Error("expandProjects: %q: %v", r.path, r.err)
 			failedNames = append(failedNames, filepath.Base(r.path))
 		}
+		if progress != nil && len(r.projects) > 0 {
+			progress(flatten())
+		}
 	}
 
-	// Flatten in original order
-	for i := range paths {
-		expanded = append(expanded, resultsByIndex[i]...)
-	}
-
-	return expanded, failedNames
+	return flatten(), failedNames
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+// isCloneURL reports whether a picker query names a repository to clone
+// rather than a directory to create.
+func isCloneURL(query string) bool {
+	return strings.Contains(query, "://") || strings.HasPrefix(query, "git@")
+}
+
+// createProjectLabel describes for the picker hints what ctrl+n does with a
+// query under root.
+func createProjectLabel(root string) func(query string) string {
+	home, _ := os.UserHomeDir()
+	return func(query string) string {
+		if isCloneURL(query) {
+			return "clone into " + ui.ContractTilde(filepath.Join(root, repoNameFromURL(query)), home)
+		}
+		return "create " + ui.ContractTilde(filepath.Join(root, query), home)
+	}
+}
+
+// createPickerProjectWith makes the project ctrl+n asked for under root: a
+// clone when query is a git URL, otherwise a new directory named query, with
+// git init under [project] create_git_init. Unless an entry already covers
+// it, the project is offered for the config at cfgPath through
+// d.ConfirmAddDir. Returns its picker item.
+func createPickerProjectWith(d *ProjectDeps, cfg *config.Config, cfgPath, root, query string) (*ui.Item, error) {
+	name := query
+	if isCloneURL(query) {
+		name = repoNameFromURL(query)
+	}
+	if name == "" || name == "." || name == ".." || strings.ContainsRune(name, filepath.Separator) {
+		return nil, fmt.Errorf("cannot create a project named %q", query)
+	}
+	dest := filepath.Join(root, name)
+	if _, err := d.Project.FS.Stat(dest); err == nil {
+		return nil, fmt.Errorf("%s already exists", dest)
+	}
+	if err := d.Project.FS.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", root, err)
+	}
+
+	if isCloneURL(query) {
+		if _, err := d.Project.Git.Command("clone", query, dest); err != nil {
+			return nil, fmt.Errorf("clone %s: %w", query, err)
+		}
+	} else {
+		if err := d.Project.FS.MkdirAll(dest, 0o755); err != nil {
+			return nil, fmt.Errorf("create %s: %w", dest, err)
+		}
+		if cfg.ProjectCreateGitInit() {
+			if _, err := d.Project.Git.CommandInDir(dest, "init"); err != nil {
+				return nil, fmt.Errorf("git init %s: %w", dest, err)
+			}
+		}
+	}
+
+	if !cfg.MatchesProjectPath(dest) {
+		if err := addDirProject(d.Project.FS, cfgPath, dest, d.ConfirmAddDir); err != nil {
+			return nil, err
+		}
+	}
+	return &ui.Item{
+		Name:        name,
+		Path:        dest,
+		SessionName: project.SessionNameWith(d.Project, dest),
+	}, nil
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+// createTestDeps configures a "<root>/*" projects glob with one existing
+// project, backed by the real filesystem, and records the git commands that
+// change something (session naming's rev-parse calls are left out).
+func createTestDeps(t *testing.T, gitInit bool, gitCalls *[]string) (*ProjectDeps, string) {
+	t.Helper()
+	d := testProjectDeps(t)
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "existing"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{
+			Projects: []config.ProjectEntry{{Path: filepath.Join(root, "*")}},
+			Project:  &config.ProjectConfig{CreateGitInit: gitInit},
+		}, nil
+	}
+	d.Project.FS = &deps.MockFileSystem{StatFunc: os.Stat, MkdirAllFunc: os.MkdirAll}
+	d.Project.Git = &deps.MockGit{
+		CommandFunc: func(args ...string) (string, error) {
+			*gitCalls = append(*gitCalls, strings.Join(args, " "))
+			return "", nil
+		},
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			if args[0] == "rev-parse" {
+				return "", nil
+			}
+			*gitCalls = append(*gitCalls, dir+": "+strings.Join(args, " "))
+			return "", nil
+		},
+	}
+	return d, root
+}
+
+func TestRunProject_CreateProjectMakesDirAndOpensIt(t *testing.T) {
+	var gitCalls []string
+	d, root := createTestDeps(t, true, &gitCalls)
+	var opened *ui.Item
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		opened = item
+		return nil
+	}
+	d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+		return ui.Result{Action: ui.ActionCreateProject, Query: "newproj"}
+	})
+
+	if err := RunProject(d); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(root, "newproj")
+	if info, err := os.Stat(dest); err != nil || !info.IsDir() {
+		t.Fatalf("%s not created: %v", dest, err)
+	}
+	if want := []string{dest + ": init"}; !equalStrings(gitCalls, want) {
+		t.Errorf("git calls = %q, want %q", gitCalls, want)
+	}
+	if opened == nil || opened.Path != dest || opened.SessionName == "" {
+		t.Errorf("opened = %+v, want a session for %s", opened, dest)
+	}
+}
+
+func TestCreatePickerProjectWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantName string
+		wantGit  []string
+		wantErr  bool
+	}{
+		{name: "plain directory", query: "api", wantName: "api"},
+		{name: "git url clones", query: "git@github.com:me/tool.git", wantName: "tool", wantGit: []string{"clone git@github.com:me/tool.git "}},
+		{name: "nested path rejected", query: "a/b", wantErr: true},
+		{name: "existing rejected", query: "existing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gitCalls []string
+			d, root := createTestDeps(t, false, &gitCalls)
+			cfg, _ := d.LoadConfig()
+
+			item, err := createPickerProjectWith(d, cfg, filepath.Join(root, "config.toml"), root, tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if item.Name != tt.wantName || item.Path != filepath.Join(root, tt.wantName) {
+				t.Errorf("item = %+v, want %s under %s", item, tt.wantName, root)
+			}
+			for i := range tt.wantGit {
+				tt.wantGit[i] += item.Path
+			}
+			if !equalStrings(gitCalls, tt.wantGit) {
+				t.Errorf("git calls = %q, want %q", gitCalls, tt.wantGit)
+			}
+		})
+	}
+}
+
+func TestCreatePickerProjectWith_AsksBeforeAddingToConfig(t *testing.T) {
+	var gitCalls []string
+	d, root := createTestDeps(t, false, &gitCalls)
+	var written []string
+	d.Project.FS.(*deps.MockFileSystem).WriteFileFunc = func(path string, data []byte, perm os.FileMode) error {
+		written = append(written, path)
+		return nil
+	}
+	var asked, diff string
+	d.ConfirmAddDir = func(dir, d string) bool {
+		asked, diff = dir, d
+		return false
+	}
+	cfg := &config.Config{}
+
+	item, err := createPickerProjectWith(d, cfg, filepath.Join(root, "config.toml"), root, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asked != item.Path || !strings.Contains(diff, "+path = \""+item.Path+"\"") {
+		t.Errorf("asked about %q with diff %q, want %s and its entry", asked, diff, item.Path)
+	}
+	if len(written) != 0 {
+		t.Errorf("config written after declining: %v", written)
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+// resolveProjectDir turns the directory argument of pop select <dir> into a
+// clean absolute path, expanding a leading ~, and checks it is a directory.
+func resolveProjectDir(fs deps.FileSystem, dir string) (string, error) {
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := fs.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolve %s: %w", dir, err)
+		}
+		dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+	}
+	if !filepath.IsAbs(dir) {
+		wd, err := fs.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("resolve %s: %w", dir, err)
+		}
+		dir = filepath.Join(wd, dir)
+	}
+	dir = filepath.Clean(dir)
+	info, err := fs.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	return dir, nil
+}
+
+// coversDir reports whether one of projects is dir or lies under it, such as
+// a worktree of the bare repo at dir.
+func coversDir(projects []project.ExpandedProject, dir string) bool {
+	for _, p := range projects {
+		if pathWithin(p.Path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathWithin reports whether path is dir or lies under it.
+func pathWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// nearestHolding returns the index of the item at dir or, failing that, at
+// its nearest ancestor, or -1 when there is none. path gives an item's path,
+// or false for an item that has none.
+func nearestHolding[T any](items []T, dir string, path func(T) (string, bool)) int {
+	best, bestLen := -1, 0
+	for i, item := range items {
+		p, ok := path(item)
+		if !ok || !pathWithin(dir, p) {
+			continue
+		}
+		if best < 0 || len(p) > bestLen {
+			best, bestLen = i, len(p)
+		}
+	}
+	return best
+}
+
+// withDirProject makes sure pop select <dir> has a project to open. Unless a
+// project already covers dir, dir is expanded like a projects entry (a bare
+// repo becomes its worktrees) and joins the end of projects, nearest the
+// cursor, for this run only; adHoc reports that it did.
+func withDirProject(d *ProjectDeps, cfg *config.Config, hist *history.History, projects []project.ExpandedProject, dir string, exclude *sessionExclusion) (_ []project.ExpandedProject, adHoc bool, err error) {
+	if coversDir(projects, dir) {
+		return projects, false, nil
+	}
+	expanded, failed := expandProjectsWith(d.Project, []config.ExpandedPath{{Path: dir, Explicit: true}})
+	if len(expanded) == 0 {
+		if len(failed) > 0 {
+			return nil, false, fmt.Errorf("failed to expand %s (see ~/.local/share/pop/pop.log for details)", dir)
+		}
+		return nil, false, fmt.Errorf("no project at %s", dir)
+	}
+	return append(projects, finishProjects(cfg, hist, expanded, exclude)...), true, nil
+}
+
+// addDirProject offers to add dir to the projects list of the config at
+// cfgPath: confirm sees what would be appended to which file, and nothing is
+// written unless it agrees. A nil confirm never adds.
+func addDirProject(fs deps.FileSystem, cfgPath, dir string, confirm func(dir, diff string) bool) error {
+	if confirm == nil {
+		return nil
+	}
+	add, err := planProjectsAddition(fs, cfgPath, []config.ProjectEntry{{Path: dir}})
+	if err != nil || add == nil {
+		return err
+	}
+	if !confirm(dir, unifiedDiff(add.Path, add.Before, add.After)) {
+		return nil
+	}
+	return add.write(fs)
+}
+
+// confirmAddDir shows on the terminal how adding an ad-hoc directory changes
+// the config and asks whether to keep it in the projects list. Without a
+// terminal there is no one to ask.
+func confirmAddDir(dir, diff string) bool {
+	if !stdinIsTerminal() {
+		return false
+	}
+	if taskStdoutInteractive() && os.Getenv("NO_COLOR") == "" {
+		diff = colorizeDiff(diff)
+	}
+	fmt.Fprint(os.Stdout, "\n"+diff)
+	return confirm(bufio.NewScanner(os.Stdin), os.Stdout, fmt.Sprintf("Add %s to your projects?", dir))
+}
+
+// pickDir stands in for the first pick of pop select <dir>: the project at
+// dir is confirmed outright, while the projects under dir, such as the
+// worktrees of a bare repo, are offered on their own through pick.
+func pickDir(dir string, pick func([]ui.Item, ...ui.PickerOption) (ui.Result, error), items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+	var under []ui.Item
+	for i, item := range items {
+		if isStandaloneSession(item) {
+			continue
+		}
+		if item.Path == dir {
+			return ui.Result{Action: ui.ActionConfirm, Selected: &items[i]}, nil
+		}
+		if pathWithin(item.Path, dir) {
+			under = append(under, item)
+		}
+	}
+	switch len(under) {
+	case 0:
+		// Only the current session's project can go missing this way.
+		return ui.Result{}, fmt.Errorf("no project at %s to switch to", dir)
+	case 1:
+		return ui.Result{Action: ui.ActionConfirm, Selected: &under[0]}, nil
+	}
+	return pick(under, opts...)
+}
+
+// pickHere stands in for the first pick of --here: the configured project
+// holding dir — dir itself or its nearest ancestor among items, such as the
+// worktree a shell sits somewhere inside — is confirmed outright. A dir that
+// only holds projects, such as a bare repo's root, must hold exactly one.
+func pickHere(dir string, items []ui.Item) (ui.Result, error) {
+	best := nearestHolding(items, dir, func(item ui.Item) (string, bool) {
+		return item.Path, !isStandaloneSession(item)
+	})
+	if best < 0 {
+		var under []int
+		for i, item := range items {
+			if !isStandaloneSession(item) && item.Path != dir && pathWithin(item.Path, dir) {
+				under = append(under, i)
+			}
+		}
+		switch len(under) {
+		case 0:
+			return ui.Result{}, fmt.Errorf("no configured project holds %s", dir)
+		case 1:
+			best = under[0]
+		default:
+			return ui.Result{}, fmt.Errorf("%s holds %d projects; run pop select %s to choose one", dir, len(under), dir)
+		}
+	}
+	return ui.Result{Action: ui.ActionConfirm, Selected: &items[best]}, nil
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+// dirProjectDeps returns testProjectDeps on the real filesystem with the
+// picker forbidden, recording the path OpenSession is called with.
+func dirProjectDeps(t *testing.T, opened *string) *ProjectDeps {
+	t.Helper()
+	d := testProjectDeps(t)
+	d.Project.FS = deps.NewRealFileSystem()
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		t.Fatal("pop select <dir> showed the picker")
+		return ui.Result{}, nil
+	}
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		*opened = item.Path
+		return nil
+	}
+	return d
+}
+
+func TestRunProjectDirOpensAdHocProject(t *testing.T) {
+	var opened string
+	d := dirProjectDeps(t, &opened)
+	d.Dir = t.TempDir()
+	var asked string
+	d.ConfirmAddDir = func(dir, diff string) bool {
+		asked = dir
+		return false
+	}
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if opened != d.Dir {
+		t.Errorf("opened %q, want the ad-hoc directory %q", opened, d.Dir)
+	}
+	if asked != d.Dir {
+		t.Errorf("asked to add %q, want %q", asked, d.Dir)
+	}
+	if _, err := os.Stat(config.DefaultConfigPath()); err == nil {
+		t.Error("config written although adding was declined")
+	}
+}
+
+func TestRunProjectDirOpensConfiguredProject(t *testing.T) {
+	var opened string
+	d := dirProjectDeps(t, &opened)
+	cfg, _ := d.LoadConfig()
+	d.Dir = cfg.Projects[0].Path
+	d.ConfirmAddDir = func(dir, diff string) bool {
+		t.Errorf("asked to add configured project %s", dir)
+		return false
+	}
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if opened != d.Dir {
+		t.Errorf("opened %q, want %q", opened, d.Dir)
+	}
+}
+
+func TestRunProjectDirAddsToConfig(t *testing.T) {
+	var opened string
+	d := dirProjectDeps(t, &opened)
+	d.Dir = t.TempDir()
+	cfgPath := config.DefaultConfigPath()
+	if err := os.MkdirAll(filepath.Dir(cfgPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	existing := "# mine\nincludes = []\n\n[[projects]]\npath = \"~/keep\" # hand-written\n"
+	if err := os.WriteFile(cfgPath, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var shown string
+	d.ConfirmAddDir = func(dir, diff string) bool {
+		shown = diff
+		return true
+	}
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	added := "\n[[projects]]\npath = \"" + d.Dir + "\"\n"
+	if got, _ := os.ReadFile(cfgPath); string(got) != existing+added {
+		t.Errorf("config =\n%s\nwant the entry appended to\n%s", got, existing)
+	}
+	if !strings.Contains(shown, "+path = \""+d.Dir+"\"") {
+		t.Errorf("diff shown = %q, want the appended entry", shown)
+	}
+	if opened != d.Dir {
+		t.Errorf("opened %q, want %q", opened, d.Dir)
+	}
+}
+
+func TestPickDir(t *testing.T) {
+	items := []ui.Item{
+		{Name: "api", Path: "/src/api"},
+		{Name: "repo/main", Path: "/src/repo/main"},
+		{Name: "repo/fix", Path: "/src/repo/fix"},
+		{Name: "repo", Path: tmuxSessionPathPrefix + "repo"},
+	}
+	var offered []string
+	pick := func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		for _, item := range items {
+			offered = append(offered, item.Path)
+		}
+		return ui.Result{Action: ui.ActionCancel}, nil
+	}
+
+	result, err := pickDir("/src/api", pick, items)
+	if err != nil || result.Action != ui.ActionConfirm || result.Selected.Path != "/src/api" {
+		t.Errorf("pickDir(/src/api) = %+v, %v; want /src/api confirmed", result, err)
+	}
+	if _, err := pickDir("/src/repo", pick, items); err != nil {
+		t.Fatalf("pickDir(/src/repo): %v", err)
+	}
+	if want := []string{"/src/repo/main", "/src/repo/fix"}; !equalStrings(offered, want) {
+		t.Errorf("offered %q, want the repo's worktrees %q", offered, want)
+	}
+	if _, err := pickDir("/src/gone", pick, items); err == nil {
+		t.Error("pickDir(/src/gone) error = nil, want no project")
+	}
+}
+
+func TestPickHere(t *testing.T) {
+	items := []ui.Item{
+		{Name: "api", Path: "/src/api"},
+		{Name: "repo/main", Path: "/src/repo/main"},
+		{Name: "repo/fix", Path: "/src/repo/fix"},
+		{Name: "solo/main", Path: "/src/solo/main"},
+		{Name: "api", Path: tmuxSessionPathPrefix + "api"},
+	}
+	for dir, want := range map[string]string{
+		"/src/api":          "/src/api",
+		"/src/api/cmd/pop":  "/src/api",
+		"/src/repo/fix/sub": "/src/repo/fix",
+		"/src/solo":         "/src/solo/main",
+	} {
+		result, err := pickHere(dir, items)
+		if err != nil || result.Action != ui.ActionConfirm || result.Selected.Path != want {
+			t.Errorf("pickHere(%s) = %+v, %v; want %s confirmed", dir, result, err, want)
+		}
+	}
+	if _, err := pickHere("/other", items); err == nil {
+		t.Error("pickHere(/other) error = nil, want no project")
+	}
+	if _, err := pickHere("/src/repo", items); err == nil {
+		t.Error("pickHere(/src/repo) error = nil, want two projects to choose from")
+	}
+}
+
+func TestRunProjectHereOpensEnclosingProject(t *testing.T) {
+	var opened string
+	d := dirProjectDeps(t, &opened)
+	cfg, _ := d.LoadConfig()
+	sub := filepath.Join(cfg.Projects[0].Path, "internal")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+	d.Here = true
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if opened != cfg.Projects[0].Path {
+		t.Errorf("opened %q, want the enclosing project %q", opened, cfg.Projects[0].Path)
+	}
+}
+
+func TestRunProjectHereIgnoresExcludeCurrentSession(t *testing.T) {
+	var opened string
+	d := dirProjectDeps(t, &opened)
+	cfg, _ := d.LoadConfig()
+	cfg.ExcludeCurrentSession = true
+	d.LoadConfig = func() (*config.Config, error) { return cfg, nil }
+	// The shell sits in the project of the session pop runs from.
+	d.CurrentSession = func(deps.Tmux) string { return filepath.Base(cfg.Projects[0].Path) }
+	d.CurrentSessionDir = func(deps.Tmux) string { return cfg.Projects[0].Path }
+	t.Chdir(cfg.Projects[0].Path)
+	d.Here = true
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if opened != cfg.Projects[0].Path {
+		t.Errorf("opened %q, want the current session's project %q", opened, cfg.Projects[0].Path)
+	}
+}
+
+func TestResolveProjectDir(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "src", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs := &deps.MockFileSystem{
+		UserHomeDirFunc: func() (string, error) { return home, nil },
+		GetwdFunc:       func() (string, error) { return filepath.Join(home, "src"), nil },
+		StatFunc:        os.Stat,
+	}
+
+	for _, arg := range []string{"~/src/api", "api", "./api/", filepath.Join(home, "src", "api")} {
+		got, err := resolveProjectDir(fs, arg)
+		if err != nil || got != filepath.Join(home, "src", "api") {
+			t.Errorf("resolveProjectDir(%q) = %q, %v; want the api directory", arg, got, err)
+		}
+	}
+	if _, err := resolveProjectDir(fs, "~/notes.txt"); err == nil {
+		t.Error("resolveProjectDir(file) error = nil, want not a directory")
+	}
+	if _, err := resolveProjectDir(fs, "~/missing"); err == nil {
+		t.Error("resolveProjectDir(missing) error = nil")
+	}
+}
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -118,6 +123,31 @@ func TestSanitizeSessionName(t *testing.T) {
 			input:    "...::",
 			expected: "_____",
 		},
+		{
+			name:     "spaces and quotes escaped",
+			input:    `my "app" v2`,
+			expected: "my_20_22app_22_20v2",
+		},
+		{
+			name:     "non-ASCII letters escaped per byte",
+			input:    "Café",
+			expected: "Caf_c3_a9",
+		},
+		{
+			name:     "leading target markers escaped",
+			input:    "$home",
+			expected: "_24home",
+		},
+		{
+			name:     "at sign kept past the start",
+			input:    "@me@work",
+			expected: "_40me@work",
+		},
+		{
+			name:     "underscores and other ASCII kept",
+			input:    "my_repo+[v2]",
+			expected: "my_repo+[v2]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -192,6 +222,33 @@ func TestBuildSessionAwareItems(t *testing.T) {
 		if iconByPath[tmuxSessionPathPrefix+"scratch"] != iconStandaloneSession {
 			t.Errorf("standalone session: Icon = %q, want %q", iconByPath[tmuxSessionPathPrefix+"scratch"], iconStandaloneSession)
 		}
+
+		for _, item := range result {
+			want := item.Path != "/idle"
+			if item.HasSession != want {
+				t.Errorf("%s: HasSession = %v, want %v", item.Path, item.HasSession, want)
+			}
+		}
+	})
+
+	t.Run("configured icon shows until a session exists", func(t *testing.T) {
+		app := testItem("app", "/app")
+		app.Icon = "*"
+		idle := testItem("idle", "/idle")
+		idle.Icon = "*"
+		sessionActivity := map[string]int64{project.SessionName("/app"): now.Unix()}
+
+		result := buildSessionAwareItemsWith([]ui.Item{app, idle}, &history.History{}, sessionActivity, nil, nil)
+
+		for _, item := range result {
+			want := "*"
+			if item.Path == "/app" {
+				want = iconDirSession
+			}
+			if item.Icon != want {
+				t.Errorf("%s: Icon = %q, want %q", item.Path, item.Icon, want)
+			}
+		}
 	})
 
 	t.Run("no sessions means no icons and no standalone items", func(t *testing.T) {
@@ -458,6 +515,60 @@ func TestSortByUnifiedRecency(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("frequently used project outranks a one-off newer access", func(t *testing.T) {
+		now := time.Now()
+		items := []ui.Item{
+			{Name: "daily", Path: "/daily"},
+			{Name: "one-off", Path: "/one-off"},
+			{Name: "scratch", Path: "tmux:scratch"},
+		}
+		hist := &history.History{
+			Entries: []history.Entry{
+				{Path: "/daily", LastAccess: now.Add(-3 * time.Hour), Count: 20},
+				{Path: "/one-off", LastAccess: now.Add(-5 * time.Minute), Count: 1},
+			},
+		}
+		sessionActivity := map[string]int64{
+			"scratch": now.Add(-time.Minute).Unix(),
+		}
+
+		result := sortByUnifiedRecency(items, hist, sessionActivity)
+
+		expected := []string{"/one-off", "tmux:scratch", "/daily"}
+		for i, want := range expected {
+			if result[i].Path != want {
+				t.Errorf("result[%d].Path = %q, want %q", i, result[i].Path, want)
+			}
+		}
+	})
+
+	t.Run("pinned projects sort last whatever their history", func(t *testing.T) {
+		items := []ui.Item{
+			{Name: "pinned-new", Path: "/pinned-new", Pinned: true},
+			{Name: "pinned-never", Path: "/pinned-never", Pinned: true},
+			{Name: "recent", Path: "/recent"},
+			{Name: "session", Path: "tmux:session"},
+		}
+		hist := &history.History{
+			Entries: []history.Entry{
+				{Path: "/pinned-new", LastAccess: time.Unix(1000, 0)},
+				{Path: "/recent", LastAccess: time.Unix(3000, 0)},
+			},
+		}
+		sessionActivity := map[string]int64{
+			"session": 2000,
+		}
+
+		result := sortByUnifiedRecency(items, hist, sessionActivity)
+
+		expected := []string{"tmux:session", "/recent", "/pinned-never", "/pinned-new"}
+		for i, want := range expected {
+			if result[i].Path != want {
+				t.Errorf("result[%d].Path = %q, want %q", i, result[i].Path, want)
+			}
+		}
+	})
 }
 
 func TestSortBaseItemsByHistory(t *testing.T) {
@@ -565,6 +676,31 @@ func TestSortBaseItemsByHistory(t *testing.T) {
 			t.Errorf("result[0].Path = %q, want /ccc", result[0].Path)
 		}
 	})
+
+	t.Run("pinned items stay last", func(t *testing.T) {
+		now := time.Now()
+		items := []ui.Item{
+			{Name: "aaa", Path: "/aaa", Pinned: true},
+			{Name: "bbb", Path: "/bbb"},
+			{Name: "ccc", Path: "/ccc"},
+		}
+		hist := &history.History{
+			Entries: []history.Entry{
+				{Path: "/aaa", LastAccess: now.Add(-2 * time.Hour)},
+				{Path: "/bbb", LastAccess: now},
+			},
+		}
+
+		result := sortBaseItemsByHistory(items, hist)
+
+		var got []string
+		for _, item := range result {
+			got = append(got, item.Path)
+		}
+		if !equalStrings(got, []string{"/ccc", "/bbb", "/aaa"}) {
+			t.Errorf("order = %v, want the pinned /aaa last", got)
+		}
+	})
 }
 
 func TestOpenTmuxSessionWith(t *testing.T) {
@@ -839,6 +975,114 @@ func TestExpandProjectsWith_BareRepoExpandsWorktrees(t *testing.T) {
 	}
 }
 
+func TestExpandProjectsWith_SkipWorktrees(t *testing.T) {
+	paths := []config.ExpandedPath{
+		{Path: "/home/user/bare-proj", DisplayDepth: 1, SkipWorktrees: true},
+	}
+	d := buildExpandDeps([]mockProject{
+		{
+			path:        "/home/user/bare-proj",
+			hasWorktree: true,
+			worktrees:   []string{"main"},
+		},
+	})
+
+	expanded, failed := expandProjectsWith(d, paths)
+
+	if len(failed) != 0 {
+		t.Errorf("expected no failures, got %v", failed)
+	}
+	if len(expanded) != 1 || expanded[0].Name != "bare-proj" || expanded[0].IsWorktree {
+		t.Errorf("expanded = %+v, want bare-proj as a single regular project", expanded)
+	}
+}
+
+func TestExpandProjectsWith_WorktreeDisplay(t *testing.T) {
+	heads := map[string]string{
+		"/home/user/bare-proj/.bare/worktrees/PROJ-12/HEAD": "ref: refs/heads/fix-login\n",
+		"/home/user/bare-proj/.bare/worktrees/main/HEAD":    "ref: refs/heads/main\n",
+	}
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{config.WorktreeDisplayDir, []string{"bare-proj/PROJ-12", "bare-proj/main"}},
+		{config.WorktreeDisplayBranch, []string{"bare-proj/fix-login", "bare-proj/main"}},
+		// A worktree whose directory already matches its branch isn't doubled.
+		{config.WorktreeDisplayBoth, []string{"bare-proj/PROJ-12 (fix-login)", "bare-proj/main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			d := buildExpandDeps([]mockProject{{
+				path:        "/home/user/bare-proj",
+				hasWorktree: true,
+				worktrees:   []string{"PROJ-12", "main"},
+			}})
+			d.FS.(*deps.MockFileSystem).ReadFileFunc = func(path string) ([]byte, error) {
+				if strings.HasSuffix(path, "/.git") {
+					return []byte("gitdir: ../.bare/worktrees/" + filepath.Base(filepath.Dir(path))), nil
+				}
+				if head, ok := heads[path]; ok {
+					return []byte(head), nil
+				}
+				return nil, os.ErrNotExist
+			}
+
+			expanded, _ := expandProjectsWith(d, []config.ExpandedPath{
+				{Path: "/home/user/bare-proj", DisplayDepth: 1, WorktreeDisplay: tt.mode},
+			})
+
+			if got := expandedNames(expanded); !equalStrings(got, tt.want) {
+				t.Errorf("expanded names = %v, want %v", got, tt.want)
+			}
+			for _, p := range expanded {
+				if want := "bare-proj/" + filepath.Base(p.Path); p.SessionName != want {
+					t.Errorf("session name = %q, want directory-based %q", p.SessionName, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandProjectsWith_Overrides(t *testing.T) {
+	d := buildExpandDeps([]mockProject{
+		{path: "/home/user/api"},
+		{path: "/home/user/bare-proj", hasWorktree: true, worktrees: []string{"main"}},
+	})
+	overrides := config.ProjectOverrides{
+		SessionName: "svc.api",
+		Command:     "make dev",
+		Container:   "api-dev",
+		Workbench:   "dev",
+		Icon:        "*",
+		Tags:        []string{"work"},
+	}
+	bareOverrides := overrides
+	bareOverrides.SessionName = "bp"
+
+	expanded, _ := expandProjectsWith(d, []config.ExpandedPath{
+		{Path: "/home/user/api", DisplayDepth: 1, Overrides: overrides},
+		{Path: "/home/user/bare-proj", DisplayDepth: 1, Overrides: bareOverrides},
+	})
+
+	if len(expanded) != 2 {
+		t.Fatalf("got %d projects, want 2", len(expanded))
+	}
+	// The custom name is sanitized like a derived one; a bare repo's replaces
+	// the repo prefix of each worktree session.
+	if got := expanded[0].SessionName; got != "svc_api" {
+		t.Errorf("regular SessionName = %q, want svc_api", got)
+	}
+	if got := expanded[1].SessionName; got != "bp/main" {
+		t.Errorf("worktree SessionName = %q, want bp/main", got)
+	}
+	for _, p := range expanded {
+		if p.Command != "make dev" || p.Container != "api-dev" || p.Workbench != "dev" || p.Icon != "*" || !equalStrings(p.Tags, []string{"work"}) {
+			t.Errorf("%s: overrides = %q %q %q %q %v, want them copied from the entry", p.Name, p.Command, p.Container, p.Workbench, p.Icon, p.Tags)
+		}
+	}
+}
+
 func TestExpandProjectsWith_PartialFailureKeepsGoodProjects(t *testing.T) {
 	paths := []config.ExpandedPath{
 		{Path: "/home/user/good-a", DisplayDepth: 1},
@@ -913,6 +1157,37 @@ func TestExpandProjectsWith_PanicIsCapturedAsFailure(t *testing.T) {
 	}
 }
 
+func TestExpandProjectsWith_WorktreeMatchedDirectlyGetsRepoContext(t *testing.T) {
+	// ~/Dev/repo/* matched a bare repo's worktree directly: it must be named
+	// and sessioned repo/worktree, not as a plain "main" directory.
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	wt := filepath.Join(repo, "main")
+	if err := os.MkdirAll(filepath.Join(repo, ".bare", "worktrees", "main"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".bare", "config"), []byte("[core]\n\tbare = true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, ".git"), []byte("gitdir: ../.bare/worktrees/main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &project.Deps{Git: &deps.MockGit{}, FS: deps.NewRealFileSystem()}
+	expanded, failed := expandProjectsWith(d, []config.ExpandedPath{{Path: wt, DisplayDepth: 1}})
+
+	if len(failed) != 0 || len(expanded) != 1 {
+		t.Fatalf("expanded = %+v, failed = %v", expanded, failed)
+	}
+	got := expanded[0]
+	if got.Name != "repo/main" || got.SessionName != "repo/main" || got.ProjectName != "repo" || !got.IsWorktree {
+		t.Errorf("expanded = %+v, want repo/main worktree of repo", got)
+	}
+}
+
 func TestExpandProjectsWith_EmptyInput(t *testing.T) {
 	d := buildExpandDeps(nil)
 	expanded, failed := expandProjectsWith(d, nil)
@@ -961,6 +1236,50 @@ func scriptedPicker(fns ...func(items []ui.Item) ui.Result) func(items []ui.Item
 // config pointing at a fresh t.TempDir, which cfg.ExpandProjects resolves
 // to exactly one item (not a bare repo, no worktrees) — enough for the
 // picker loop to reach its first iteration.
+func TestRunProject_AttachBehavior(t *testing.T) {
+	tests := []struct {
+		name         string
+		behavior     string
+		flag         bool
+		wantDetached bool
+	}{
+		{"default attaches alongside other clients", "", false, false},
+		{"config detach_others", "detach_others", false, true},
+		{"--detach-others flag", "attach", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testProjectDeps(t)
+			projectDir := t.TempDir()
+			d.LoadConfig = func() (*config.Config, error) {
+				return &config.Config{
+					Projects:       []config.ProjectEntry{{Path: projectDir}},
+					AttachBehavior: tt.behavior,
+				}, nil
+			}
+			d.DetachOthers = tt.flag
+			var attached, detached bool
+			d.Tmux = &deps.MockTmux{
+				AttachSessionFunc:             func(string) error { attached = true; return nil },
+				AttachSessionDetachOthersFunc: func(string) error { detached = true; return nil },
+			}
+			d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+				return ui.Result{Action: ui.ActionConfirm, Selected: &items[0]}
+			})
+			d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+				return tmux.AttachSession(item.SessionName)
+			}
+
+			if err := RunProject(d); err != nil {
+				t.Fatalf("RunProject: %v", err)
+			}
+			if detached != tt.wantDetached || attached == tt.wantDetached {
+				t.Errorf("attached = %v, detached = %v; want detached = %v", attached, detached, tt.wantDetached)
+			}
+		})
+	}
+}
+
 func testProjectDeps(t *testing.T) *ProjectDeps {
 	t.Helper()
 
@@ -1003,12 +1322,16 @@ func testProjectDeps(t *testing.T) *ProjectDeps {
 
 		OpenSession:              func(tmux deps.Tmux, item *ui.Item) error { return nil },
 		OpenSessionWithWorkbench: func(tmux deps.Tmux, item *ui.Item, workbenchName string) error { return nil },
+		EnsureSession:            func(tmux deps.Tmux, item *ui.Item, workbenchName string) error { return nil },
+		RunPipelineCommand:       func(command string, item *ui.Item) error { return nil },
 		OpenWindow:               func(tmux deps.Tmux, item *ui.Item) error { return nil },
 		KillSession:              func(tmux deps.Tmux, name string) {},
-		SendCDToPane:             func(tmux deps.Tmux, paneID, path string) error { return nil },
+		SendCDToPane:             func(tmux deps.Tmux, paneID, path, busy string) error { return nil },
 		SwitchToTarget:           func(tmux deps.Tmux, target string) error { return nil },
 		SwitchAndZoom:            func(tmux deps.Tmux, target string) error { return nil },
-		RunCustomCommand:         func(command string, item *ui.Item) {},
+		RunCustomCommand:         func(command string, item *ui.Item, extraEnv ...string) {},
+		RunMultiCustomCommand:    func(command string, items []ui.Item, extraEnv ...string) {},
+		RunEditor:                func(name string, args ...string) error { return nil },
 		EnsureSystemState:        func() []string { return nil },
 		RunConfigure:             func() error { return nil },
 
@@ -1016,6 +1339,7 @@ func testProjectDeps(t *testing.T) *ProjectDeps {
 		ResolvePreferredWorkbench: func(cfg *config.Config, path string) (string, []string) { return "", nil },
 
 		InTmux:         func() bool { return false },
+		InPopup:        func() bool { return false },
 		CurrentSession: func(tmux deps.Tmux) string { return "" },
 	}
 }
@@ -1069,6 +1393,160 @@ func TestRunProject_ActionConfirmRecordsHistory(t *testing.T) {
 	}
 }
 
+func TestRunProject_KillSessionKillsEveryMarked(t *testing.T) {
+	var killed []string
+	calls := 0
+	d := testProjectDeps(t)
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		calls++
+		if calls > 1 {
+			return ui.Result{Action: ui.ActionCancel}, nil
+		}
+		marked := []ui.Item{
+			{Name: "api", Path: "/src/api", SessionName: "api"},
+			{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"},
+		}
+		return ui.Result{Action: ui.ActionKillSession, Selected: &marked[0], Marked: marked}, nil
+	}
+	d.KillSession = func(tmux deps.Tmux, name string) { killed = append(killed, name) }
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if !equalStrings(killed, []string{"api", "scratch"}) {
+		t.Errorf("killed = %v, want both marked sessions", killed)
+	}
+}
+
+func TestRunProject_KillAppliesHistoryOnKill(t *testing.T) {
+	for _, behavior := range []string{config.HistoryOnKillKeep, config.HistoryOnKillDemote, config.HistoryOnKillRemove} {
+		t.Run(behavior, func(t *testing.T) {
+			var hist *history.History
+			d := testProjectDeps(t)
+			d.LoadConfig = func() (*config.Config, error) {
+				return &config.Config{
+					Projects: []config.ProjectEntry{{Path: t.TempDir()}},
+					Project:  &config.ProjectConfig{HistoryOnKill: behavior},
+				}, nil
+			}
+			origLoadHistory := d.LoadHistory
+			d.LoadHistory = func() (*history.History, error) {
+				h, err := origLoadHistory()
+				if h != nil {
+					if err := h.Update(func(h *history.History) { h.Record("/src/api") }); err != nil {
+						t.Fatal(err)
+					}
+				}
+				hist = h
+				return h, err
+			}
+			calls := 0
+			d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+				calls++
+				if calls > 1 {
+					return ui.Result{Action: ui.ActionCancel}, nil
+				}
+				item := ui.Item{Name: "api", Path: "/src/api", SessionName: "api"}
+				return ui.Result{Action: ui.ActionKillSession, Selected: &item}, nil
+			}
+
+			if err := RunProject(d); err != nil {
+				t.Fatalf("RunProject: %v", err)
+			}
+			switch behavior {
+			case config.HistoryOnKillKeep:
+				if len(hist.Entries) != 1 || hist.Entries[0].Killed() {
+					t.Errorf("entries = %+v, want the entry untouched", hist.Entries)
+				}
+			case config.HistoryOnKillDemote:
+				if len(hist.Entries) != 1 || !hist.Entries[0].Killed() {
+					t.Errorf("entries = %+v, want the entry marked killed", hist.Entries)
+				}
+			case config.HistoryOnKillRemove:
+				if len(hist.Entries) != 0 {
+					t.Errorf("entries = %+v, want the entry removed", hist.Entries)
+				}
+			}
+		})
+	}
+}
+
+func TestRunProject_ConfirmOpensMarkedAsWindows(t *testing.T) {
+	var opened []string
+	d := testProjectDeps(t)
+	d.InTmux = func() bool { return true }
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		marked := []ui.Item{
+			{Name: "api", Path: "/src/api", SessionName: "api"},
+			{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"},
+			{Name: "web", Path: "/src/web", SessionName: "web"},
+		}
+		return ui.Result{Action: ui.ActionConfirm, Selected: &marked[2], Marked: marked}, nil
+	}
+	d.OpenWindow = func(tmux deps.Tmux, item *ui.Item) error {
+		opened = append(opened, item.Path)
+		return nil
+	}
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		t.Errorf("OpenSession(%s) called; several marked projects open as windows", item.Path)
+		return nil
+	}
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if !equalStrings(opened, []string{"/src/api", "/src/web"}) {
+		t.Errorf("opened windows = %v, want the marked projects without the standalone session", opened)
+	}
+}
+
+func TestRunProject_DetachCreateEnsuresMarkedSessions(t *testing.T) {
+	var ensured []string
+	d := testProjectDeps(t)
+	d.InTmux = func() bool { return true }
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{
+			Projects:     []config.ProjectEntry{{Path: t.TempDir()}},
+			OpenBehavior: config.OpenBehaviorDetachCreate,
+		}, nil
+	}
+	d.Tmux = &deps.MockTmux{HasSessionFunc: func(name string) bool { return name == "web" }}
+	d.ResolvePreferredWorkbench = func(cfg *config.Config, path string) (string, []string) {
+		if path == "/src/api" {
+			return "dev", nil
+		}
+		return "", nil
+	}
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		marked := []ui.Item{
+			{Name: "api", Path: "/src/api", SessionName: "api"},
+			{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"},
+			{Name: "web", Path: "/src/web", SessionName: "web"},
+			{Name: "docs", Path: "/src/docs", SessionName: "docs"},
+		}
+		return ui.Result{Action: ui.ActionConfirm, Selected: &marked[0], Marked: marked}, nil
+	}
+	d.EnsureSession = func(tmux deps.Tmux, item *ui.Item, workbenchName string) error {
+		ensured = append(ensured, item.SessionName+":"+workbenchName)
+		return nil
+	}
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		t.Errorf("OpenSession(%s) called; detach-create must not switch", item.Path)
+		return nil
+	}
+	d.OpenWindow = func(tmux deps.Tmux, item *ui.Item) error {
+		t.Errorf("OpenWindow(%s) called; detach-create must not open windows", item.Path)
+		return nil
+	}
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if !equalStrings(ensured, []string{"api:dev", "docs:"}) {
+		t.Errorf("ensured = %v, want the missing project sessions only", ensured)
+	}
+}
+
 func TestRunProject_ActionKillSessionContinuesLoop(t *testing.T) {
 	var killedNames []string
 	var pickerCalls int
@@ -1112,6 +1590,195 @@ func TestRunProject_ActionKillSessionContinuesLoop(t *testing.T) {
 	}
 }
 
+func TestRunProject_StreamOpensPickerBeforeProjects(t *testing.T) {
+	projectDir := t.TempDir()
+	var pickerCalls int
+	d := testProjectDeps(t)
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{
+			Projects: []config.ProjectEntry{{Path: projectDir}},
+			Project:  &config.ProjectConfig{Stream: true},
+		}, nil
+	}
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		pickerCalls++
+		hasProject := slices.ContainsFunc(items, func(it ui.Item) bool { return it.Path == projectDir })
+		switch pickerCalls {
+		case 1:
+			if hasProject {
+				t.Errorf("first picker already has the project; want it streamed in")
+			}
+			scratch := ui.Item{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"}
+			return ui.Result{Action: ui.ActionKillSession, Selected: &scratch}, nil
+		case 2:
+			if !hasProject {
+				t.Errorf("second picker items = %+v, want the collected project", items)
+			}
+		}
+		return ui.Result{Action: ui.ActionCancel}, nil
+	}
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if pickerCalls != 2 {
+		t.Errorf("picker called %d times, want 2", pickerCalls)
+	}
+}
+
+func TestExpandProjectsStreamWith_ReportsProgress(t *testing.T) {
+	paths := []config.ExpandedPath{
+		{Path: "/home/user/api", DisplayDepth: 1},
+		{Path: "/home/user/web", DisplayDepth: 1},
+	}
+	d := buildExpandDeps([]mockProject{{path: "/home/user/api"}, {path: "/home/user/web"}})
+
+	var sizes []int
+	expanded, _ := expandProjectsStreamWith(context.Background(), d, paths, 2, func(partial []project.ExpandedProject) {
+		sizes = append(sizes, len(partial))
+	})
+
+	if !equalStrings(expandedNames(expanded), []string{"api", "web"}) {
+		t.Errorf("expanded names = %v, want input order", expandedNames(expanded))
+	}
+	if len(sizes) != 2 || sizes[0] != 1 || sizes[1] != 2 {
+		t.Errorf("progress sizes = %v, want [1 2]", sizes)
+	}
+}
+
+// slowScanDeps is a filesystem where every Stat takes delay, recording the
+// most Stats in flight at once and each project path statted.
+func slowScanDeps(delay time.Duration, onStat func()) (*project.Deps, *atomic.Int32, func() int) {
+	var inFlight, peak atomic.Int32
+	var mu sync.Mutex
+	scanned := map[string]bool{}
+	d := &project.Deps{
+		Git: &deps.MockGit{},
+		FS: &deps.MockFileSystem{
+			StatFunc: func(path string) (os.FileInfo, error) {
+				n := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				mu.Lock()
+				scanned[filepath.Dir(path)] = true
+				mu.Unlock()
+				if onStat != nil {
+					onStat()
+				}
+				time.Sleep(delay)
+				return nil, os.ErrNotExist
+			},
+		},
+	}
+	return d, &peak, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(scanned)
+	}
+}
+
+func scanPaths(n int) []config.ExpandedPath {
+	paths := make([]config.ExpandedPath, n)
+	for i := range paths {
+		paths[i] = config.ExpandedPath{Path: fmt.Sprintf("/net/proj-%02d", i), DisplayDepth: 1}
+	}
+	return paths
+}
+
+func TestExpandProjectsStreamWith_BoundsConcurrency(t *testing.T) {
+	d, peak, _ := slowScanDeps(5*time.Millisecond, nil)
+	paths := scanPaths(12)
+
+	expanded, failed := expandProjectsStreamWith(context.Background(), d, paths, 3, nil)
+
+	if len(expanded) != 12 || len(failed) != 0 {
+		t.Fatalf("expanded %d, failed %v; want all 12", len(expanded), failed)
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("%d paths scanned at once, want at most 3", got)
+	}
+}
+
+func TestExpandProjectsStreamWith_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d, _, scanned := slowScanDeps(5*time.Millisecond, cancel)
+	paths := scanPaths(20)
+
+	expanded, _ := expandProjectsStreamWith(ctx, d, paths, 2, nil)
+
+	if got := scanned(); got > 2 {
+		t.Errorf("%d paths scanned after cancelling on the first, want at most the 2 in flight", got)
+	}
+	if len(expanded) >= len(paths) {
+		t.Errorf("expanded all %d paths despite cancellation", len(expanded))
+	}
+}
+
+func TestRunProject_MultiCustomCommandReceivesMarkedItems(t *testing.T) {
+	var gotCommand string
+	var gotItems []ui.Item
+	singleCalled := false
+
+	d := testProjectDeps(t)
+	d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+		return ui.Result{
+			Action:             ui.ActionUserDefinedCommand,
+			Selected:           &items[0],
+			UserDefinedCommand: &ui.UserDefinedCommandResult{Command: "code {paths}", Exit: true, Multi: true},
+			Marked:             []ui.Item{{Path: "/a"}, {Path: "/b"}},
+		}
+	})
+	d.RunMultiCustomCommand = func(command string, items []ui.Item, extraEnv ...string) {
+		gotCommand = command
+		gotItems = items
+	}
+	d.RunCustomCommand = func(command string, item *ui.Item, extraEnv ...string) { singleCalled = true }
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if singleCalled {
+		t.Error("multi command should not run through RunCustomCommand")
+	}
+	if gotCommand != "code {paths}" || len(gotItems) != 2 {
+		t.Errorf("RunMultiCustomCommand(%q, %v), want code {paths} with 2 items", gotCommand, gotItems)
+	}
+}
+
+func TestRunProject_CustomCommandReceivesItemJSON(t *testing.T) {
+	var gotEnv []string
+
+	d := testProjectDeps(t)
+	d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+		return ui.Result{
+			Action:             ui.ActionUserDefinedCommand,
+			Selected:           &items[0],
+			UserDefinedCommand: &ui.UserDefinedCommandResult{Command: "./script", Exit: true},
+		}
+	})
+	d.RunCustomCommand = func(command string, item *ui.Item, extraEnv ...string) { gotEnv = extraEnv }
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if len(gotEnv) != 1 || !strings.HasPrefix(gotEnv[0], "POP_ITEM_JSON={") {
+		t.Fatalf("extraEnv = %q, want POP_ITEM_JSON", gotEnv)
+	}
+	var m itemMetadata
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(gotEnv[0], "POP_ITEM_JSON=")), &m); err != nil {
+		t.Fatalf("POP_ITEM_JSON is not JSON: %v", err)
+	}
+	if m.Path == "" || m.SessionName == "" || m.IsWorktree {
+		t.Errorf("POP_ITEM_JSON = %+v, want the selected regular project", m)
+	}
+}
+
 func TestRunProject_ActionCancelExitsCleanly(t *testing.T) {
 	var pickerCalls int
 	openCalled := false
@@ -1185,6 +1852,47 @@ func TestRunProject_UpdateNoticeKillSwitch(t *testing.T) {
 	}
 }
 
+// TestRunProject_TipsKillSwitch verifies show_tips = false skips the NextTip
+// seam entirely, so no tip is retired from the user's state.
+func TestRunProject_TipsKillSwitch(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name       string
+		showTips   *bool
+		wantCalled bool
+	}{
+		{name: "absent key defaults to enabled", showTips: nil, wantCalled: true},
+		{name: "explicit false disabled", showTips: &disabled, wantCalled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			tipCalled := false
+
+			d := testProjectDeps(t)
+			d.LoadConfig = func() (*config.Config, error) {
+				return &config.Config{
+					Projects: []config.ProjectEntry{{Path: projectDir}},
+					ShowTips: tt.showTips,
+				}, nil
+			}
+			d.NextTip = func(string, map[string]string, bool) string {
+				tipCalled = true
+				return "Tip: test"
+			}
+
+			if err := RunProject(d); err != nil {
+				t.Fatalf("RunProject: unexpected error %v", err)
+			}
+			if tipCalled != tt.wantCalled {
+				t.Errorf("NextTip called = %v, want %v", tipCalled, tt.wantCalled)
+			}
+		})
+	}
+}
+
 // TestRunProject_NoGitCallsDuringPickerOpen asserts that opening the project
 // picker does not invoke git commands. Regression guard for the session-module
 // change that called project.SessionName (which runs git) inside hot loops
@@ -1911,7 +2619,7 @@ func TestDiscoverManagedWorktreesWith_Discovery(t *testing.T) {
 }
 
 // A basename that itself contains dashes must survive: only the trailing
-// -<12 hex> short hash is stripped. A "." in the basename is sanitised in the
+// -<12 hex> short hash is stripped. A "." in the basename is escaped in the
 // session name (matching the drain) but preserved in the display name.
 func TestDiscoverManagedWorktreesWith_NameAndSessionDerivation(t *testing.T) {
 	root := "/data/pop/queue/worktrees"
@@ -1979,3 +2687,265 @@ func TestRepoKeyBasename(t *testing.T) {
 		}
 	}
 }
+
+func TestGroupItemsByParent(t *testing.T) {
+	// Oldest first: b1 is the most recent item, so /b sorts last; a1 and a2
+	// keep their relative order inside /a.
+	items := []ui.Item{
+		{Name: "a1", Path: "/a/1", Group: "/a"},
+		{Name: "b2", Path: "/b/2", Group: "/b"},
+		{Name: "a2", Path: "/a/2", Group: "/a"},
+		{Name: "b1", Path: "/b/1", Group: "/b"},
+	}
+
+	got := groupItemsByParent(items)
+
+	var names []string
+	for _, item := range got {
+		names = append(names, item.Name)
+	}
+	want := []string{"a1", "a2", "b2", "b1"}
+	if !equalStrings(names, want) {
+		t.Errorf("groupItemsByParent order = %v, want %v", names, want)
+	}
+}
+
+func TestRunProject_GroupByFlagOverridesConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		configGroup string
+		flag        string
+		wantGrouped bool
+	}{
+		{name: "config parent", configGroup: "parent", wantGrouped: true},
+		{name: "flag parent", flag: "parent", wantGrouped: true},
+		{name: "flag none beats config", configGroup: "parent", flag: "none", wantGrouped: false},
+		{name: "default flat", wantGrouped: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			var gotItems []ui.Item
+
+			d := testProjectDeps(t)
+			d.GroupBy = tt.flag
+			d.LoadConfig = func() (*config.Config, error) {
+				return &config.Config{
+					Projects: []config.ProjectEntry{{Path: projectDir}},
+					Project:  &config.ProjectConfig{GroupBy: tt.configGroup},
+				}, nil
+			}
+			var view string
+			d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+				gotItems = items
+				p := ui.NewPicker(items, opts...)
+				p.Init()
+				view = p.View().Content
+				return ui.Result{Action: ui.ActionCancel}, nil
+			}
+
+			if err := RunProject(d); err != nil {
+				t.Fatalf("RunProject: %v", err)
+			}
+			parent := filepath.Dir(projectDir)
+			if len(gotItems) != 1 || gotItems[0].Group != parent {
+				t.Fatalf("items = %+v, want one item grouped under %s", gotItems, parent)
+			}
+			// The group header is only rendered in grouped mode.
+			if got := strings.Contains(view, parent); got != tt.wantGrouped {
+				t.Errorf("group header rendered = %v, want %v", got, tt.wantGrouped)
+			}
+		})
+	}
+}
+
+func TestRunProject_OnSelectPipeline(t *testing.T) {
+	withOnSelect := func(d *ProjectDeps, steps []string) {
+		load := d.LoadConfig
+		d.LoadConfig = func() (*config.Config, error) {
+			cfg, err := load()
+			if err != nil {
+				return nil, err
+			}
+			cfg.Project = &config.ProjectConfig{OnSelect: steps}
+			return cfg, nil
+		}
+	}
+
+	t.Run("runs configured steps instead of the built-in sequence", func(t *testing.T) {
+		d := testProjectDeps(t)
+		withOnSelect(d, []string{"ensure_session", "run:direnv allow", "switch"})
+		var log []string
+		d.ResolvePreferredWorkbench = func(cfg *config.Config, path string) (string, []string) { return "dev", nil }
+		d.EnsureSession = func(tmux deps.Tmux, item *ui.Item, workbenchName string) error {
+			log = append(log, "ensure:"+workbenchName)
+			return nil
+		}
+		d.RunPipelineCommand = func(command string, item *ui.Item) error {
+			log = append(log, "run:"+command)
+			return nil
+		}
+		d.SwitchToTarget = func(tmux deps.Tmux, target string) error {
+			log = append(log, "switch")
+			return nil
+		}
+		d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+			t.Error("OpenSession must not run when on_select is configured")
+			return nil
+		}
+		d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+			return ui.Result{Action: ui.ActionConfirm, Selected: &items[0]}
+		})
+
+		if err := RunProject(d); err != nil {
+			t.Fatalf("RunProject: %v", err)
+		}
+		want := []string{"ensure:dev", "run:direnv allow", "switch"}
+		if strings.Join(log, ",") != strings.Join(want, ",") {
+			t.Errorf("steps = %v, want %v", log, want)
+		}
+	})
+
+	t.Run("ensure_session skips an existing session", func(t *testing.T) {
+		d := testProjectDeps(t)
+		withOnSelect(d, []string{"ensure_session"})
+		d.Tmux = &deps.MockTmux{HasSessionFunc: func(name string) bool { return true }}
+		d.EnsureSession = func(tmux deps.Tmux, item *ui.Item, workbenchName string) error {
+			t.Error("EnsureSession must not run for a live session")
+			return nil
+		}
+		d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+			return ui.Result{Action: ui.ActionConfirm, Selected: &items[0]}
+		})
+
+		if err := RunProject(d); err != nil {
+			t.Fatalf("RunProject: %v", err)
+		}
+	})
+}
+
+func TestRunProject_PopupSwitch(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		mode    string
+		inPopup bool
+		want    bool
+	}{
+		{"popup closes by default", "", true, true},
+		{"direct keeps plain switch", "direct", true, false},
+		{"pane is unaffected", "", false, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testProjectDeps(t)
+			load := d.LoadConfig
+			d.LoadConfig = func() (*config.Config, error) {
+				cfg, err := load()
+				if err != nil {
+					return nil, err
+				}
+				cfg.PopupSwitch = tt.mode
+				return cfg, nil
+			}
+			d.InPopup = func() bool { return tt.inPopup }
+			var wrapped bool
+			d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+				_, wrapped = tmux.(popupTmux)
+				return nil
+			}
+			d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+				return ui.Result{Action: ui.ActionConfirm, Selected: &items[0]}
+			})
+
+			if err := RunProject(d); err != nil {
+				t.Fatalf("RunProject: %v", err)
+			}
+			if wrapped != tt.want {
+				t.Errorf("popupTmux wrapping = %v, want %v", wrapped, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendCDToPaneWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		running  string
+		busy     string
+		wantLast string
+		wantErr  bool
+	}{
+		{name: "shell gets the cd", running: "zsh", busy: config.TmuxCDBusyRefuse, wantLast: "send-keys"},
+		{name: "busy pane refuses", running: "nvim", busy: config.TmuxCDBusyRefuse, wantLast: "display-message", wantErr: true},
+		{name: "busy pane splits", running: "nvim", busy: config.TmuxCDBusySplit, wantLast: "split-window"},
+		{name: "send skips the check", running: "nvim", busy: config.TmuxCDBusySend, wantLast: "send-keys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			tmux := &deps.MockTmux{
+				CommandFunc: func(args ...string) (string, error) {
+					calls = append(calls, args[0])
+					if args[0] == "display-message" {
+						return tt.running + "\n", nil
+					}
+					return "", nil
+				},
+			}
+			err := sendCDToPaneWith(tmux, "%3", "/src/api", tt.busy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sendCDToPaneWith() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(calls) == 0 || calls[len(calls)-1] != tt.wantLast {
+				t.Errorf("tmux calls = %v, want to end with %s", calls, tt.wantLast)
+			}
+			if tt.busy == config.TmuxCDBusySend && len(calls) != 1 {
+				t.Errorf("tmux calls = %v, want only the send-keys", calls)
+			}
+		})
+	}
+}
+
+func TestRunProject_ExcludeCurrentSessionMatchesSessionDir(t *testing.T) {
+	root := t.TempDir()
+	api, web := filepath.Join(root, "api"), filepath.Join(root, "web")
+	for _, dir := range []string{api, web} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := testProjectDeps(t)
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{
+			Projects:              []config.ProjectEntry{{Path: api}, {Path: web}},
+			ExcludeCurrentSession: true,
+		}, nil
+	}
+	// The session was opened under its own name, through a symlink to api.
+	d.CurrentSession = func(deps.Tmux) string { return "work" }
+	d.CurrentSessionDir = func(deps.Tmux) string { return "/links/api" }
+	d.Project.FS = &deps.MockFileSystem{
+		EvalSymlinksFunc: func(path string) (string, error) {
+			if path == "/links/api" {
+				return filepath.EvalSymlinks(api)
+			}
+			return filepath.EvalSymlinks(path)
+		},
+	}
+	var shown []string
+	d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+		for _, item := range items {
+			shown = append(shown, filepath.Base(item.Path))
+		}
+		return ui.Result{Action: ui.ActionCancel}
+	})
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if !equalStrings(shown, []string{"web"}) {
+		t.Errorf("picker showed %v, want the session's project api excluded", shown)
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/project"
+)
+
+// projectTypeNerdFontIcons are the Nerd Font glyphs for each project type
+// project.DetectProjectType reports.
+var projectTypeNerdFontIcons = map[string]string{
+	"go":      "\ue627", // nf-seti-go
+	"rust":    "\ue7a8", // nf-dev-rust
+	"elixir":  "\ue62d", // nf-custom-elixir
+	"swift":   "\ue755", // nf-dev-swift
+	"haskell": "\ue777", // nf-dev-haskell
+	"java":    "\ue738", // nf-dev-java
+	"ruby":    "\ue739", // nf-dev-ruby
+	"php":     "\ue73d", // nf-dev-php
+	"python":  "\ue73c", // nf-dev-python
+	"node":    "\ue718", // nf-dev-nodejs_small
+	"c":       "\ue61d", // nf-custom-cpp
+	"nix":     "\uf313", // nf-linux-nixos
+}
+
+// projectTypeASCIIIcons are short labels for terminals without a Nerd Font.
+var projectTypeASCIIIcons = map[string]string{
+	"go":      "go",
+	"rust":    "rs",
+	"elixir":  "ex",
+	"swift":   "sw",
+	"haskell": "hs",
+	"java":    "jv",
+	"ruby":    "rb",
+	"php":     "php",
+	"python":  "py",
+	"node":    "js",
+	"c":       "c",
+	"nix":     "nix",
+}
+
+// projectTypeIcon returns the icon of project type typ in style (the icons
+// setting); "" for an unknown type or with icons off.
+func projectTypeIcon(style, typ string) string {
+	switch style {
+	case config.IconsNerdFont:
+		return projectTypeNerdFontIcons[typ]
+	case config.IconsASCII:
+		return projectTypeASCIIIcons[typ]
+	}
+	return ""
+}
+
+// projectTypeDetector returns a function that fills in the Type of the
+// projects it is given, reading each path's directory once however often
+// the path comes back (streamed scans report the same projects repeatedly);
+// nil when cfg turns icons off, so scans then skip the extra reads.
+func projectTypeDetector(d *project.Deps, cfg *config.Config) func([]project.ExpandedProject) {
+	if cfg.GetIcons() == config.IconsOff {
+		return nil
+	}
+	types := make(map[string]string)
+	return func(projects []project.ExpandedProject) {
+		for i := range projects {
+			typ, ok := types[projects[i].Path]
+			if !ok {
+				typ = project.DetectProjectTypeWith(d, projects[i].Path)
+				types[projects[i].Path] = typ
+			}
+			projects[i].Type = typ
+		}
+	}
+}
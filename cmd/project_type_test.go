@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+)
+
+type fakeDirEntry struct {
+	os.DirEntry
+	name string
+}
+
+func (e fakeDirEntry) Name() string { return e.name }
+
+func TestProjectTypeIcon(t *testing.T) {
+	if got := projectTypeIcon(config.IconsASCII, "go"); got != "go" {
+		t.Errorf("ascii go = %q, want go", got)
+	}
+	if got := projectTypeIcon(config.IconsNerdFont, "rust"); got != "\ue7a8" {
+		t.Errorf("nerdfont rust = %q, want nf-dev-rust", got)
+	}
+	if got := projectTypeIcon(config.IconsOff, "go"); got != "" {
+		t.Errorf("icons off = %q, want \"\"", got)
+	}
+	if got := projectTypeIcon(config.IconsASCII, ""); got != "" {
+		t.Errorf("unknown type = %q, want \"\"", got)
+	}
+}
+
+func TestProjectTypeDetector(t *testing.T) {
+	if projectTypeDetector(project.DefaultDeps(), &config.Config{}) != nil {
+		t.Fatal("icons are off by default; the detector should be nil")
+	}
+
+	reads := make(map[string]int)
+	d := &project.Deps{FS: &deps.MockFileSystem{
+		ReadDirFunc: func(path string) ([]os.DirEntry, error) {
+			reads[path]++
+			if path == "/src/api" {
+				return []os.DirEntry{fakeDirEntry{name: "go.mod"}}, nil
+			}
+			return nil, nil
+		},
+	}}
+	detect := projectTypeDetector(d, &config.Config{Icons: config.IconsASCII})
+	projects := []project.ExpandedProject{{Path: "/src/api"}, {Path: "/src/notes"}}
+	detect(projects)
+	detect(projects)
+
+	if projects[0].Type != "go" || projects[1].Type != "" {
+		t.Errorf("types = %q, %q; want go and none", projects[0].Type, projects[1].Type)
+	}
+	if reads["/src/api"] != 1 || reads["/src/notes"] != 1 {
+		t.Errorf("reads = %v, want each directory read once", reads)
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+// pickProjectWorktree lists the worktrees of the highlighted item's bare repo
+// in a picker of their own (ctrl+l), so browsing them needs no cd and pop
+// worktree. A worktree picked there comes back as a confirm, exactly as if
+// picked in the project picker; Esc, or an item that is not a worktree,
+// returns to the project picker on the same row.
+func pickProjectWorktree(d *ProjectDeps, result ui.Result, items []ui.Item, projectsByPath map[string]project.ExpandedProject, opts ...ui.PickerOption) (ui.Result, error) {
+	back := ui.Result{Action: ui.ActionRefresh, CursorIndex: result.CursorIndex}
+	if result.Selected == nil {
+		return back, nil
+	}
+	selected, ok := projectsByPath[result.Selected.Path]
+	if !ok || !selected.IsWorktree {
+		ui.Notify(ui.LevelInfo, "%s is not in a bare repo with worktrees", result.Selected.Name)
+		return back, nil
+	}
+
+	var worktrees []ui.Item
+	for _, item := range items {
+		if p, ok := projectsByPath[item.Path]; ok && p.IsWorktree && p.RepoRoot == selected.RepoRoot {
+			worktrees = append(worktrees, item)
+		}
+	}
+	opts = append(opts, ui.WithHeader("Worktrees of "+selected.ProjectLabel+" (esc to go back)"))
+	picked, err := d.RunPicker(worktrees, opts...)
+	if err != nil {
+		return ui.Result{}, err
+	}
+	if picked.Action != ui.ActionConfirm || picked.Selected == nil {
+		return back, nil
+	}
+	return ui.Result{Action: ui.ActionConfirm, Selected: picked.Selected}, nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+func browseWorktreesFixture() ([]ui.Item, map[string]project.ExpandedProject) {
+	projects := []project.ExpandedProject{
+		{Name: "api", Path: "/src/api"},
+		{Name: "repo/main", ProjectLabel: "repo", Path: "/src/repo/main", IsWorktree: true, RepoRoot: "/src/repo"},
+		{Name: "other/main", ProjectLabel: "other", Path: "/src/other/main", IsWorktree: true, RepoRoot: "/src/other"},
+		{Name: "repo/fix", ProjectLabel: "repo", Path: "/src/repo/fix", IsWorktree: true, RepoRoot: "/src/repo"},
+	}
+	byPath := make(map[string]project.ExpandedProject)
+	var items []ui.Item
+	for _, p := range projects {
+		byPath[p.Path] = p
+		items = append(items, ui.Item{Name: p.Name, Path: p.Path})
+	}
+	return items, byPath
+}
+
+func TestPickProjectWorktree(t *testing.T) {
+	items, byPath := browseWorktreesFixture()
+	d := testProjectDeps(t)
+	var offered []string
+	d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+		for _, item := range items {
+			offered = append(offered, item.Path)
+		}
+		return ui.Result{Action: ui.ActionConfirm, Selected: &items[1]}
+	})
+
+	result, err := pickProjectWorktree(d, ui.Result{Action: ui.ActionBrowseWorktrees, Selected: &items[1], CursorIndex: 1}, items, byPath)
+	if err != nil {
+		t.Fatalf("pickProjectWorktree: %v", err)
+	}
+	if want := []string{"/src/repo/main", "/src/repo/fix"}; !equalStrings(offered, want) {
+		t.Errorf("offered %q, want the repo's worktrees %q", offered, want)
+	}
+	if result.Action != ui.ActionConfirm || result.Selected == nil || result.Selected.Path != "/src/repo/fix" {
+		t.Errorf("result = %+v, want /src/repo/fix confirmed", result)
+	}
+}
+
+func TestPickProjectWorktreeEscGoesBack(t *testing.T) {
+	items, byPath := browseWorktreesFixture()
+	d := testProjectDeps(t)
+	d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+		return ui.Result{Action: ui.ActionCancel}
+	})
+
+	result, err := pickProjectWorktree(d, ui.Result{Action: ui.ActionBrowseWorktrees, Selected: &items[3], CursorIndex: 3}, items, byPath)
+	if err != nil {
+		t.Fatalf("pickProjectWorktree: %v", err)
+	}
+	if result.Action != ui.ActionRefresh || result.CursorIndex != 3 {
+		t.Errorf("result = %+v, want a refresh back on row 3", result)
+	}
+}
+
+func TestPickProjectWorktreeSkipsNonWorktrees(t *testing.T) {
+	items, byPath := browseWorktreesFixture()
+	d := testProjectDeps(t)
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		t.Fatal("worktree picker shown for a plain project")
+		return ui.Result{}, nil
+	}
+
+	result, err := pickProjectWorktree(d, ui.Result{Action: ui.ActionBrowseWorktrees, Selected: &items[0]}, items, byPath)
+	if err != nil {
+		t.Fatalf("pickProjectWorktree: %v", err)
+	}
+	if result.Action != ui.ActionRefresh {
+		t.Errorf("result = %+v, want a refresh back to the project picker", result)
+	}
+}
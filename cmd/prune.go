@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneYes    bool
+	pruneDryRun bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Clean up history, the glob cache, dead sessions and worktree metadata",
+	Long: `Run every cleanup pop knows of in one go:
+
+  - history entries whose directory no longer exists
+  - glob cache entries a directory change or cache_ttl made stale
+  - detached tmux sessions whose start directory was deleted
+  - git worktree metadata of the configured projects' repositories that
+    points at deleted worktrees (git worktree prune)
+
+pop prune prints what it would remove and asks before removing anything.
+--dry-run only prints the plan; --yes removes without asking. Attached
+sessions are never killed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPruneWith(defaultPruneDeps(), pruneYes, pruneDryRun)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "prune without asking")
+	pruneCmd.Flags().BoolVarP(&pruneDryRun, "dry-run", "n", false, "print what would be pruned and change nothing")
+}
+
+// pruneDeps holds dependencies for pop prune.
+type pruneDeps struct {
+	LoadConfig  func() (*config.Config, error)
+	LoadHistory func() (*history.History, error)
+	CachePath   string
+	// ProjectPaths returns the paths of the projects the picker lists.
+	ProjectPaths func() ([]string, error)
+	Tmux         deps.Tmux
+	Git          deps.Git
+	// RunHook runs a [hooks] command in dir with env appended.
+	RunHook func(command, dir string, env ...string) error
+	Now     func() time.Time
+	HomeDir func() (string, error)
+	Stdin   io.Reader
+	Stdout  io.Writer
+}
+
+func defaultPruneDeps() *pruneDeps {
+	return &pruneDeps{
+		LoadConfig: func() (*config.Config, error) {
+			cfgPath := cfgFile
+			if cfgPath == "" {
+				cfgPath = config.DefaultConfigPath()
+			}
+			return config.Load(cfgPath)
+		},
+		LoadHistory: func() (*history.History, error) {
+			return history.Load(history.DefaultHistoryPath())
+		},
+		CachePath:    config.DefaultCachePath(),
+		ProjectPaths: pickerProjectPaths,
+		Tmux:         deps.NewRealTmux(),
+		Git:          deps.NewRealGit(),
+		RunHook:      runPipelineCommand,
+		Now:          time.Now,
+		HomeDir:      os.UserHomeDir,
+		Stdin:        os.Stdin,
+		Stdout:       os.Stdout,
+	}
+}
+
+// deadSession is a detached session whose start directory is gone.
+type deadSession struct {
+	Name string
+	Path string
+}
+
+// prunableWorktree is worktree metadata git worktree prune would remove.
+type prunableWorktree struct {
+	// Repo is the repository's common git directory.
+	Repo   string
+	Path   string
+	Reason string
+}
+
+// prunePlan is everything pop prune would remove.
+type prunePlan struct {
+	History   []history.Entry
+	GlobCache []config.GlobCacheEntryStatus
+	Sessions  []deadSession
+	Worktrees []prunableWorktree
+}
+
+func (p *prunePlan) empty() bool {
+	return len(p.History)+len(p.GlobCache)+len(p.Sessions)+len(p.Worktrees) == 0
+}
+
+// runPruneWith plans every cleanup, prints the plan and, unless dryRun,
+// carries it out once confirmed (or straight away with yes).
+func runPruneWith(d *pruneDeps, yes, dryRun bool) error {
+	cfg, err := d.LoadConfig()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg = &config.Config{}
+	}
+	hist, err := d.LoadHistory()
+	if err != nil {
+		return fmt.Errorf("load history: %w", err)
+	}
+
+	plan := planPruneWith(d, cfg, hist)
+	if plan.empty() {
+		fmt.Fprintln(d.Stdout, "Nothing to prune.")
+		return nil
+	}
+	home, _ := d.HomeDir()
+	printPrunePlan(d.Stdout, plan, home)
+	if dryRun {
+		return nil
+	}
+	if !yes && !confirm(bufio.NewScanner(d.Stdin), d.Stdout, "\nPrune all of the above?") {
+		fmt.Fprintln(d.Stdout, "Nothing pruned.")
+		return nil
+	}
+	return applyPrunePlanWith(d, cfg, hist, plan)
+}
+
+// planPruneWith collects what each cleanup would remove. A cleanup that
+// can't be planned (no tmux server, an unreadable cache) is skipped.
+func planPruneWith(d *pruneDeps, cfg *config.Config, hist *history.History) *prunePlan {
+	plan := &prunePlan{}
+
+	preview := &history.History{Entries: slices.Clone(hist.Entries)}
+	plan.History = preview.Prune(history.PruneOptions{Missing: true})
+
+	statuses, err := config.InspectGlobCache(d.CachePath, cfg.GlobCacheTTL(), d.Now())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		debug.Error("prune: glob cache: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Changed || s.Expired {
+			plan.GlobCache = append(plan.GlobCache, s)
+		}
+	}
+
+	plan.Sessions = findDeadSessionsWith(d)
+	plan.Worktrees = findPrunableWorktreesWith(d)
+	return plan
+}
+
+// findDeadSessionsWith returns the detached sessions whose start directory no
+// longer exists.
+func findDeadSessionsWith(d *pruneDeps) []deadSession {
+	out, err := d.Tmux.Command("list-sessions", "-F", "#{session_name}\t#{session_attached}\t#{session_path}")
+	if err != nil {
+		debug.Error("prune: list-sessions: %v", err)
+		return nil
+	}
+	var dead []deadSession
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || fields[0] == "" || fields[1] != "0" || !filepath.IsAbs(fields[2]) {
+			continue
+		}
+		if _, err := os.Stat(fields[2]); os.IsNotExist(err) {
+			dead = append(dead, deadSession{Name: fields[0], Path: fields[2]})
+		}
+	}
+	return dead
+}
+
+// findPrunableWorktreesWith returns the worktree metadata git would prune in
+// the repositories of the configured projects, each repository checked once
+// however many of its worktrees are projects.
+func findPrunableWorktreesWith(d *pruneDeps) []prunableWorktree {
+	paths, err := d.ProjectPaths()
+	if err != nil {
+		debug.Error("prune: project paths: %v", err)
+		return nil
+	}
+	seen := make(map[string]bool)
+	var prunable []prunableWorktree
+	for _, path := range paths {
+		repo, err := d.Git.CommandInDir(path, "rev-parse", "--path-format=absolute", "--git-common-dir")
+		if err != nil || repo == "" || seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		out, err := d.Git.CommandInDir(repo, "worktree", "list", "--porcelain")
+		if err != nil {
+			debug.Error("prune: worktree list in %s: %v", repo, err)
+			continue
+		}
+		prunable = append(prunable, parsePrunableWorktrees(repo, out)...)
+	}
+	return prunable
+}
+
+// parsePrunableWorktrees picks the worktrees marked prunable out of
+// `git worktree list --porcelain` output.
+func parsePrunableWorktrees(repo, output string) []prunableWorktree {
+	var prunable []prunableWorktree
+	var path string
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = strings.TrimPrefix(line, "worktree ")
+		case line == "prunable" || strings.HasPrefix(line, "prunable "):
+			prunable = append(prunable, prunableWorktree{
+				Repo:   repo,
+				Path:   path,
+				Reason: strings.TrimSpace(strings.TrimPrefix(line, "prunable")),
+			})
+		}
+	}
+	return prunable
+}
+
+func printPrunePlan(w io.Writer, plan *prunePlan, home string) {
+	section := func(title string, n int) {
+		fmt.Fprintf(w, "%s (%d):\n", title, n)
+	}
+	if len(plan.History) > 0 {
+		section("History entries for missing directories", len(plan.History))
+		for _, e := range plan.History {
+			fmt.Fprintf(w, "  %s\n", ui.ContractTilde(e.Path, home))
+		}
+	}
+	if len(plan.GlobCache) > 0 {
+		section("Stale glob cache entries", len(plan.GlobCache))
+		for _, s := range plan.GlobCache {
+			state := "changed"
+			if !s.Changed {
+				state = "expired"
+			}
+			fmt.Fprintf(w, "  %s (%s)\n", ui.ContractTilde(s.Pattern, home), state)
+		}
+	}
+	if len(plan.Sessions) > 0 {
+		section("Sessions in deleted directories", len(plan.Sessions))
+		for _, s := range plan.Sessions {
+			fmt.Fprintf(w, "  %s (%s)\n", s.Name, ui.ContractTilde(s.Path, home))
+		}
+	}
+	if len(plan.Worktrees) > 0 {
+		section("Stale git worktree metadata", len(plan.Worktrees))
+		for _, wt := range plan.Worktrees {
+			line := ui.ContractTilde(wt.Path, home)
+			if wt.Reason != "" {
+				line += " (" + wt.Reason + ")"
+			}
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+}
+
+// applyPrunePlanWith carries out plan. Every cleanup runs even when an
+// earlier one fails; the failures are reported together.
+func applyPrunePlanWith(d *pruneDeps, cfg *config.Config, hist *history.History, plan *prunePlan) error {
+	var errs []error
+	if len(plan.History) > 0 {
+		var pruned []history.Entry
+		if err := hist.Update(func(h *history.History) {
+			pruned = h.Prune(history.PruneOptions{Missing: true})
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("save history: %w", err))
+		} else {
+			fmt.Fprintf(d.Stdout, "Pruned %d history entries\n", len(pruned))
+		}
+	}
+	if len(plan.GlobCache) > 0 {
+		patterns := make([]string, len(plan.GlobCache))
+		for i, s := range plan.GlobCache {
+			patterns[i] = s.Pattern
+		}
+		if err := config.DropGlobCacheEntries(d.CachePath, patterns); err != nil {
+			errs = append(errs, fmt.Errorf("prune glob cache: %w", err))
+		} else {
+			fmt.Fprintf(d.Stdout, "Dropped %d glob cache entries\n", len(patterns))
+		}
+	}
+	if len(plan.Sessions) > 0 {
+		names := make([]string, len(plan.Sessions))
+		for i, s := range plan.Sessions {
+			names[i] = s.Name
+		}
+		tmux := d.Tmux
+		if d.RunHook != nil {
+			tmux = withSessionHooks(tmux, &sessionHooks{cfg: cfg, Run: d.RunHook})
+		}
+		if err := killSessionsWith(tmux, names, d.Stdout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var repos []string
+	for _, wt := range plan.Worktrees {
+		if !slices.Contains(repos, wt.Repo) {
+			repos = append(repos, wt.Repo)
+		}
+	}
+	for _, repo := range repos {
+		if _, err := d.Git.CommandInDir(repo, "worktree", "prune"); err != nil {
+			errs = append(errs, fmt.Errorf("git worktree prune in %s: %w", repo, err))
+			continue
+		}
+		fmt.Fprintf(d.Stdout, "Pruned worktree metadata in %s\n", repo)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// testPruneDeps sets up one of everything pop prune cleans: a history entry,
+// a changed glob cache entry, a detached session and a worktree, each for a
+// deleted directory, next to their live counterparts. calls records the tmux
+// and git commands that change something.
+func testPruneDeps(t *testing.T, calls *[]string, stdin string, out *bytes.Buffer) (*pruneDeps, *history.History) {
+	t.Helper()
+	live := t.TempDir()
+	gone := filepath.Join(live, "gone")
+	hist := &history.History{Entries: []history.Entry{{Path: live}, {Path: gone}}}
+	cachePath := filepath.Join(t.TempDir(), "glob_cache.json")
+	writeGlobCache(t, cachePath, live, time.Now())
+
+	return &pruneDeps{
+		LoadConfig:   func() (*config.Config, error) { return &config.Config{}, nil },
+		LoadHistory:  func() (*history.History, error) { return hist, nil },
+		CachePath:    cachePath,
+		ProjectPaths: func() ([]string, error) { return []string{live, live + "/sub"}, nil },
+		Tmux: &deps.MockTmux{
+			CommandFunc: func(args ...string) (string, error) {
+				if args[0] == "list-sessions" {
+					return "dead\t0\t" + gone + "\nhere\t1\t" + gone + "\nlive\t0\t" + live, nil
+				}
+				*calls = append(*calls, strings.Join(args, " "))
+				return "", nil
+			},
+		},
+		Git: &deps.MockGit{
+			CommandInDirFunc: func(dir string, args ...string) (string, error) {
+				switch args[0] {
+				case "rev-parse":
+					return "/src/api/.git", nil
+				case "worktree":
+					if args[1] == "list" {
+						return "worktree /src/api\nbranch refs/heads/main\n\nworktree /src/api-old\nbranch refs/heads/old\nprunable gitdir file points to non-existent location\n", nil
+					}
+				}
+				*calls = append(*calls, dir+": git "+strings.Join(args, " "))
+				return "", nil
+			},
+		},
+		Now:     time.Now,
+		HomeDir: func() (string, error) { return "", nil },
+		Stdin:   strings.NewReader(stdin),
+		Stdout:  out,
+	}, hist
+}
+
+func TestRunPrune(t *testing.T) {
+	var calls []string
+	var out bytes.Buffer
+	d, hist := testPruneDeps(t, &calls, "y\n", &out)
+
+	if err := runPruneWith(d, false, false); err != nil {
+		t.Fatalf("runPruneWith() error = %v", err)
+	}
+	for _, want := range []string{
+		"History entries for missing directories (1)",
+		"Stale glob cache entries (1)",
+		"Sessions in deleted directories (1)",
+		"  dead (",
+		"/src/api-old (gitdir file points to non-existent location)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if len(hist.Entries) != 1 {
+		t.Errorf("history = %+v, want only the live entry", hist.Entries)
+	}
+	data, err := os.ReadFile(d.CachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cache config.GlobCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.Entries) != 1 {
+		t.Errorf("glob cache kept %d entries, want the fresh one", len(cache.Entries))
+	}
+	if !equalStrings(calls, []string{"kill-session -t =dead", "/src/api/.git: git worktree prune"}) {
+		t.Errorf("calls = %q, want dead killed and one worktree prune", calls)
+	}
+}
+
+func TestRunPrune_DeclinedOrDryRunChangesNothing(t *testing.T) {
+	for name, run := range map[string]func(d *pruneDeps) error{
+		"declined": func(d *pruneDeps) error { return runPruneWith(d, false, false) },
+		"dry run":  func(d *pruneDeps) error { return runPruneWith(d, true, true) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls []string
+			var out bytes.Buffer
+			d, hist := testPruneDeps(t, &calls, "n\n", &out)
+			if err := run(d); err != nil {
+				t.Fatal(err)
+			}
+			if len(calls) != 0 || len(hist.Entries) != 2 {
+				t.Errorf("calls = %q, history = %d entries; want nothing changed", calls, len(hist.Entries))
+			}
+		})
+	}
+}
+
+func TestParsePrunableWorktrees(t *testing.T) {
+	out := "worktree /r\nbare\n\nworktree /r/a\nHEAD abc\nprunable\n\nworktree /r/b\nbranch refs/heads/b\n"
+	got := parsePrunableWorktrees("/r", out)
+	if len(got) != 1 || got[0].Path != "/r/a" || got[0].Reason != "" {
+		t.Errorf("parsePrunableWorktrees() = %+v, want /r/a with no reason", got)
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+var recentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "Open the most recently used project, without the picker",
+	Long: `Open the project opened most recently, other than the one whose tmux session
+you are in, exactly as Enter in the picker would: switch to its tmux session,
+creating it first when needed, and record it in history. Run twice, it
+toggles between your last two projects.
+
+Standalone tmux sessions count when history recorded them; projects whose
+session was killed from the picker since they were last opened are skipped.
+
+Example:
+  bind-key Tab run-shell 'pop recent'`,
+	Args: cobra.NoArgs,
+	RunE: runRecent,
+}
+
+func init() {
+	rootCmd.AddCommand(recentCmd)
+	recentCmd.Flags().StringVar(&resultOutput, "output", "", "print the project opened to stdout: json")
+}
+
+func runRecent(cmd *cobra.Command, args []string) error {
+	if err := validateResultOutput(resultOutput); err != nil {
+		return err
+	}
+	d := DefaultProjectDeps()
+	d.Recent = true
+	d.Output = resultOutput
+	// Nothing is shown, so don't spend the once-a-day notice or a tip.
+	d.UpdateNotice = nil
+	d.NextTip = nil
+	d.RunConfigure = func() error {
+		return fmt.Errorf("no config — run pop configure first")
+	}
+	return RunProject(d)
+}
+
+// pickRecent stands in for the project picker for pop recent: it confirms the
+// item hist records as opened last, skipping the current session's and those
+// killed since their last access.
+func pickRecent(hist *history.History, currentSession string, items []ui.Item) (ui.Result, error) {
+	entries := make(map[string]history.Entry, len(hist.Entries))
+	for _, e := range hist.Entries {
+		entries[e.Path] = e
+	}
+	var (
+		best       *ui.Item
+		bestAccess time.Time
+	)
+	for i := range items {
+		e, ok := entries[items[i].Path]
+		if !ok || e.Killed() || itemSessionName(items[i]) == currentSession {
+			continue
+		}
+		if best == nil || e.LastAccess.After(bestAccess) {
+			best, bestAccess = &items[i], e.LastAccess
+		}
+	}
+	if best == nil {
+		return ui.Result{}, errors.New("no recently opened project to switch to")
+	}
+	return ui.Result{Action: ui.ActionConfirm, Selected: best}, nil
+}
+
+// itemSessionName returns the tmux session item opens in.
+func itemSessionName(item ui.Item) string {
+	if isStandaloneSession(item) {
+		return standaloneSessionName(item)
+	}
+	return item.SessionName
+}
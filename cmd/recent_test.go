@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestPickRecent(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	items := []ui.Item{
+		{Name: "api", Path: "/src/api", SessionName: "api"},
+		{Name: "web", Path: "/src/web", SessionName: "web"},
+		{Name: "docs", Path: "/src/docs", SessionName: "docs"},
+		{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"},
+		{Name: "never", Path: "/src/never", SessionName: "never"},
+	}
+	hist := &history.History{Entries: []history.Entry{
+		{Path: "/src/api", LastAccess: now},
+		{Path: "/src/web", LastAccess: now.Add(-time.Hour)},
+		{Path: "/src/docs", LastAccess: now.Add(time.Minute), KilledAt: now.Add(2 * time.Minute)},
+		{Path: tmuxSessionPathPrefix + "scratch", LastAccess: now.Add(-2 * time.Hour)},
+	}}
+
+	tests := []struct {
+		name    string
+		current string
+		want    string
+	}{
+		{name: "outside tmux the latest live one wins", current: "", want: "/src/api"},
+		{name: "the current session is skipped", current: "api", want: "/src/web"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := pickRecent(hist, tt.current, items)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Action != ui.ActionConfirm || result.Selected.Path != tt.want {
+				t.Errorf("picked %+v, want %s", result.Selected, tt.want)
+			}
+		})
+	}
+
+	onlyCurrent := &history.History{Entries: []history.Entry{{Path: "/src/api", LastAccess: now}}}
+	if _, err := pickRecent(onlyCurrent, "api", items); err == nil {
+		t.Error("expected an error when only the current project is in history")
+	}
+	if result, _ := pickRecent(&history.History{Entries: hist.Entries[3:]}, "", items); result.Selected == nil || result.Selected.Path != tmuxSessionPathPrefix+"scratch" {
+		t.Errorf("picked %+v, want the standalone session", result.Selected)
+	}
+}
+
+func TestRunProject_RecentOpensPreviousProjectWithoutPicker(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := testProjectDeps(t)
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{
+			Projects: []config.ProjectEntry{{Path: filepath.Join(root, "*")}},
+			Project:  &config.ProjectConfig{Stream: true},
+		}, nil
+	}
+	loadHistory := d.LoadHistory
+	d.LoadHistory = func() (*history.History, error) {
+		h, err := loadHistory()
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		h.Entries = []history.Entry{
+			{Path: filepath.Join(root, "api"), LastAccess: now},
+			{Path: filepath.Join(root, "web"), LastAccess: now.Add(-time.Hour)},
+		}
+		// On disk too: saving a change re-reads the file.
+		return h, h.Save()
+	}
+	d.CurrentSession = func(deps.Tmux) string { return "api" }
+	d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+		t.Fatal("pop recent showed the picker")
+		return ui.Result{}, nil
+	}
+	var opened string
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+		opened = item.Path
+		return nil
+	}
+	d.Recent = true
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	if want := filepath.Join(root, "web"); opened != want {
+		t.Errorf("opened %q, want %q", opened, want)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+// outputJSON is the --output value that prints a picker run's final Result
+// as JSON.
+const outputJSON = "json"
+
+// resultOutput is the --output flag shared by the picker commands.
+var resultOutput string
+
+// validateResultOutput rejects an --output value other than "" (none) and
+// json.
+func validateResultOutput(output string) error {
+	if output != "" && output != outputJSON {
+		return fmt.Errorf("invalid --output %q (want json)", output)
+	}
+	return nil
+}
+
+// resultJSON is the final picker Result as --output json prints it: the
+// action taken and, when it had one, the item it was taken on.
+type resultJSON struct {
+	Action      string `json:"action"`
+	Path        string `json:"path,omitempty"`
+	Name        string `json:"name,omitempty"`
+	SessionName string `json:"session_name,omitempty"`
+	// Marked holds the paths of the items marked with tab, when any were.
+	Marked []string `json:"marked,omitempty"`
+	// Command is the shell command of the user-defined command run.
+	Command string `json:"command,omitempty"`
+}
+
+// newResultJSON describes r, naming sessions with sessionName.
+func newResultJSON(r ui.Result, sessionName func(ui.Item) string) resultJSON {
+	out := resultJSON{Action: r.Action.String()}
+	if r.Selected != nil {
+		out.Path = r.Selected.Path
+		out.Name = r.Selected.Name
+		out.SessionName = sessionName(*r.Selected)
+	}
+	for _, item := range r.Marked {
+		out.Marked = append(out.Marked, item.Path)
+	}
+	if r.UserDefinedCommand != nil {
+		out.Command = r.UserDefinedCommand.Command
+	}
+	return out
+}
+
+// worktreeItemSessionName returns the tmux session the worktree picker opens
+// item in.
+func worktreeItemSessionName(item ui.Item) string {
+	return project.SessionName(item.Path)
+}
+
+// writeResultJSON prints r to w as one line of JSON.
+func writeResultJSON(w io.Writer, r resultJSON) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestNewResultJSON(t *testing.T) {
+	api := ui.Item{Name: "api", Path: "/src/api", SessionName: "api"}
+	web := ui.Item{Name: "web", Path: "/src/web", SessionName: "web"}
+	tests := []struct {
+		name   string
+		result ui.Result
+		want   string
+	}{
+		{
+			name:   "cancel",
+			result: ui.Result{Action: ui.ActionCancel},
+			want:   `{"action":"cancel"}`,
+		},
+		{
+			name:   "confirm",
+			result: ui.Result{Action: ui.ActionConfirm, Selected: &api},
+			want:   `{"action":"confirm","path":"/src/api","name":"api","session_name":"api"}`,
+		},
+		{
+			name:   "standalone session",
+			result: ui.Result{Action: ui.ActionConfirm, Selected: &ui.Item{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"}},
+			want:   `{"action":"confirm","path":"tmux:scratch","name":"scratch","session_name":"scratch"}`,
+		},
+		{
+			name: "multi command",
+			result: ui.Result{
+				Action:             ui.ActionUserDefinedCommand,
+				Selected:           &api,
+				Marked:             []ui.Item{api, web},
+				UserDefinedCommand: &ui.UserDefinedCommandResult{Command: "code {paths}", Multi: true},
+			},
+			want: `{"action":"user_defined_command","path":"/src/api","name":"api","session_name":"api","marked":["/src/api","/src/web"],"command":"code {paths}"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeResultJSON(&buf, newResultJSON(tt.result, itemSessionName)); err != nil {
+				t.Fatal(err)
+			}
+			if got := bytes.TrimSpace(buf.Bytes()); string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateResultOutput(t *testing.T) {
+	for _, output := range []string{"", "json"} {
+		if err := validateResultOutput(output); err != nil {
+			t.Errorf("validateResultOutput(%q) = %v", output, err)
+		}
+	}
+	if err := validateResultOutput("yaml"); err == nil {
+		t.Error("validateResultOutput(yaml) = nil, want an error")
+	}
+}
+
+func TestRunProject_OutputJSONPrintsFinalResult(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	d := testProjectDeps(t)
+	d.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(root, "api")}}}, nil
+	}
+	var out bytes.Buffer
+	d.Output = outputJSON
+	d.Stdout = &out
+	// Refreshing keeps the picker open; only the pick ending the run prints.
+	d.RunPicker = scriptedPicker(
+		func(items []ui.Item) ui.Result { return ui.Result{Action: ui.ActionRefresh} },
+		func(items []ui.Item) ui.Result { return ui.Result{Action: ui.ActionConfirm, Selected: &items[0]} },
+	)
+	d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error { return nil }
+
+	if err := RunProject(d); err != nil {
+		t.Fatalf("RunProject: %v", err)
+	}
+	var got resultJSON
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not one JSON object: %v\n%s", err, out.String())
+	}
+	if got.Action != "confirm" || got.Path != filepath.Join(root, "api") || got.SessionName != "api" {
+		t.Errorf("printed %+v, want the confirmed api project", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	runtimedebug "runtime/debug"
 	"strings"
 
+	"github.com/glebglazov/pop/config"
 	"github.com/glebglazov/pop/debug"
 	"github.com/glebglazov/pop/ui"
 	"github.com/spf13/cobra"
@@ -104,7 +105,15 @@ func Execute() {
 		}
 	}()
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	// Every glob expansion of the run shared one in-memory glob cache; write
+	// it back once.
+	if flushErr := config.FlushGlobCache(); flushErr != nil {
+		debug.Error("glob cache: %v", flushErr)
+	}
+	// Feedback pushed after the last picker closed would otherwise be lost.
+	ui.FlushNotifications(os.Stderr)
+	if err != nil {
 		debug.Error("%v", err)
 		ui.ShowError(err, "")
 		os.Exit(1)
@@ -1,9 +1,10 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/glebglazov/pop/debug"
 	"github.com/glebglazov/pop/history"
@@ -21,8 +22,60 @@ const (
 	iconDirSession        = "■"
 	iconStandaloneSession = "□"
 	iconAttention         = ui.IconAttention
+	iconBrokenWorktree    = "⚠"
 )
 
+// detachOthersTmux makes every attach from outside tmux detach the session's
+// other clients (attach_behavior = "detach_others"), so a session last used on
+// a bigger screen resizes to this terminal instead of being letterboxed.
+// Switching from inside tmux is unchanged: switch-client only moves the
+// current client.
+type detachOthersTmux struct {
+	deps.Tmux
+}
+
+func (t detachOthersTmux) AttachSession(name string) error {
+	return t.Tmux.AttachSessionDetachOthers(name)
+}
+
+// popupTmux chains a display-popup -C onto every switch-client so the popup
+// pop runs in closes as part of the same tmux command (popup_switch =
+// "close_popup"). Leaving the close to pop's exit races tmux restoring focus
+// and can leave the client on the old window instead of the target's pane.
+// The server runs the whole chain even though closing the popup kills pop.
+type popupTmux struct {
+	deps.Tmux
+}
+
+func (t popupTmux) SwitchClient(name string) error {
+	_, err := t.Tmux.Command("switch-client", "-t", name, ";", "display-popup", "-C")
+	return err
+}
+
+// Command closes the popup after chained switch-client commands too, such as
+// the dashboard's switch-and-zoom.
+func (t popupTmux) Command(args ...string) (string, error) {
+	if len(args) > 0 && args[0] == "switch-client" {
+		args = append(append([]string(nil), args...), ";", "display-popup", "-C")
+	}
+	return t.Tmux.Command(args...)
+}
+
+// inTmuxPopup reports whether pop runs in a tmux display-popup. tmux exports
+// $TMUX to popup commands but, unlike panes, no $TMUX_PANE.
+func inTmuxPopup() bool {
+	return os.Getenv("TMUX") != "" && os.Getenv("TMUX_PANE") == ""
+}
+
+// withPopupSwitch applies popup_switch: inside a popup, "close_popup" wraps
+// tmux in popupTmux; otherwise tmux is returned unchanged.
+func withPopupSwitch(tmux deps.Tmux, mode string, inPopup bool) deps.Tmux {
+	if inPopup && mode == "close_popup" {
+		return popupTmux{tmux}
+	}
+	return tmux
+}
+
 func currentTmuxSession() string {
 	return currentTmuxSessionWith(defaultTmux)
 }
@@ -36,6 +89,100 @@ func currentTmuxSessionWith(tmux deps.Tmux) string {
 	return out
 }
 
+// currentTmuxSessionDirWith returns the start directory of the tmux session
+// pop runs in, or "" outside tmux.
+func currentTmuxSessionDirWith(tmux deps.Tmux) string {
+	out, err := tmux.Command("display-message", "-p", "#{session_path}")
+	if err != nil {
+		debug.Error("currentTmuxSessionDir: %v", err)
+		return ""
+	}
+	return out
+}
+
+// currentTmuxClientWith returns the tty of the tmux client pop runs for, or ""
+// outside tmux.
+func currentTmuxClientWith(tmux deps.Tmux) string {
+	out, err := tmux.Command("display-message", "-p", "#{client_tty}")
+	if err != nil {
+		debug.Error("currentTmuxClient: %v", err)
+		return ""
+	}
+	return out
+}
+
+// sessionExclusion is what exclude_current_session hides from the project
+// picker: the current tmux session by name, and any project whose directory
+// is the session's with symlinks resolved, so a project reached through a
+// symlink or opened under another session name is still recognised. A nil
+// *sessionExclusion excludes nothing.
+type sessionExclusion struct {
+	names map[string]bool
+	// dir is the session's start directory, resolved; "" when unknown.
+	dir string
+	fs  deps.FileSystem
+
+	mu sync.Mutex
+	// resolved caches resolve by path: the streaming picker checks the
+	// growing project list once per expanded entry.
+	resolved map[string]string
+}
+
+// currentSessionExclusionWith returns the exclusion for the tmux session pop
+// runs in, or nil outside tmux.
+func currentSessionExclusionWith(d *ProjectDeps) *sessionExclusion {
+	name := d.CurrentSession(d.Tmux)
+	if name == "" {
+		return nil
+	}
+	e := &sessionExclusion{names: map[string]bool{name: true}, resolved: make(map[string]string)}
+	if d.Project != nil {
+		e.fs = d.Project.FS
+	}
+	if d.CurrentSessionDir != nil {
+		if dir := d.CurrentSessionDir(d.Tmux); dir != "" {
+			e.dir = e.resolve(dir)
+		}
+	}
+	return e
+}
+
+// sessionNames returns the excluded session names.
+func (e *sessionExclusion) sessionNames() map[string]bool {
+	if e == nil {
+		return nil
+	}
+	return e.names
+}
+
+// excludes reports whether ep is the current session's project.
+func (e *sessionExclusion) excludes(ep project.ExpandedProject) bool {
+	if e == nil {
+		return false
+	}
+	if e.names[ep.SessionName] {
+		return true
+	}
+	return e.dir != "" && (ep.Path == e.dir || e.resolve(ep.Path) == e.dir)
+}
+
+// resolve returns path with symlinks resolved, or cleaned when that fails.
+func (e *sessionExclusion) resolve(path string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if r, ok := e.resolved[path]; ok {
+		return r
+	}
+	r := filepath.Clean(path)
+	if e.fs != nil {
+		if evaluated, err := e.fs.EvalSymlinks(path); err == nil {
+			r = evaluated
+		}
+	}
+	e.resolved[path] = r
+	return r
+}
+
 func isStandaloneSession(item ui.Item) bool {
 	return strings.HasPrefix(item.Path, tmuxSessionPathPrefix)
 }
@@ -44,39 +191,14 @@ func standaloneSessionName(item ui.Item) string {
 	return strings.TrimPrefix(item.Path, tmuxSessionPathPrefix)
 }
 
-// switchToTmuxTarget switches to or attaches to a tmux target (session name or pane ID)
-func switchToTmuxTarget(target string) error {
-	return switchToTmuxTargetWith(defaultTmux, target)
-}
-
+// switchToTmuxTargetWith switches to or attaches to a tmux target (session name or pane ID)
 func switchToTmuxTargetWith(tmux deps.Tmux, target string) error {
 	return session.SwitchTargetWith(sessionDeps(tmux), target)
 }
 
-// switchToTmuxTargetAndZoom switches to a tmux pane and zooms it
-func switchToTmuxTargetAndZoom(target string) error {
-	return switchToTmuxTargetAndZoomWith(defaultTmux, target)
-}
-
+// switchToTmuxTargetAndZoomWith switches to a tmux pane and zooms it
 func switchToTmuxTargetAndZoomWith(tmux deps.Tmux, target string) error {
-	d := sessionDeps(tmux)
-	if d.InTmux() {
-		// Single tmux invocation: switch to pane and zoom it if not already zoomed
-		_, err := tmux.Command(
-			"switch-client", "-t", target, ";",
-			"if-shell", "-F", "#{!=:#{window_zoomed_flag},1}",
-			"resize-pane -Z",
-		)
-		return err
-	}
-	// Outside tmux: zoom before attaching since attach takes over stdio
-	if _, err := tmux.Command(
-		"if-shell", "-t", target, "-F", "#{!=:#{window_zoomed_flag},1}",
-		"resize-pane -Z",
-	); err != nil {
-		debug.Error("switchToTmuxTargetAndZoom: pre-attach zoom: %v", err)
-	}
-	return session.SwitchTargetWith(d, target)
+	return session.SwitchTargetZoomedWith(sessionDeps(tmux), target)
 }
 
 func sessionDeps(tmux deps.Tmux) *session.Deps {
@@ -263,11 +385,10 @@ func killTmuxSessionByName(sessionName string) {
 }
 
 func killTmuxSessionByNameWith(tmux deps.Tmux, sessionName string) {
-	_, err := tmux.Command("kill-session", "-t", sessionName)
-	if err != nil {
+	if err := session.KillWith(sessionDeps(tmux), sessionName); err != nil {
 		debug.Error("killTmuxSessionByName %s: %v", sessionName, err)
-		fmt.Fprintf(os.Stderr, "Failed to kill session: %s\n", sessionName)
+		ui.Notify(ui.LevelError, "Failed to kill session: %s", sessionName)
 	} else {
-		fmt.Fprintf(os.Stderr, "Killed session: %s\n", sessionName)
+		ui.Notify(ui.LevelInfo, "Killed session: %s", sessionName)
 	}
 }
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+// sessionNameOption is the tmux session option holding the display name of a
+// project whose session name project.SafeSessionName had to change, so a
+// session outliving its projects entry is still listed by that name.
+const sessionNameOption = "@pop_name"
+
+// sessionNameTmux records a project's display name on the session pop creates
+// for it. Like startupCommandTmux it watches both creation paths: NewSession
+// for flat sessions and a raw new-session for Workbench ones.
+type sessionNameTmux struct {
+	deps.Tmux
+	names map[string]string // session name → display name
+}
+
+// withSessionDisplayNames wraps tmux in sessionNameTmux when any of projects
+// has a display name that is not a safe session name.
+func withSessionDisplayNames(tmux deps.Tmux, projects []project.ExpandedProject) deps.Tmux {
+	names := make(map[string]string)
+	for _, p := range projects {
+		if project.SafeSessionName(p.Name) != p.Name {
+			names[p.SessionName] = p.Name
+		}
+	}
+	if len(names) == 0 {
+		return tmux
+	}
+	return sessionNameTmux{Tmux: tmux, names: names}
+}
+
+func (t sessionNameTmux) NewSession(name, dir string) error {
+	if err := t.Tmux.NewSession(name, dir); err != nil {
+		return err
+	}
+	t.record(name)
+	return nil
+}
+
+func (t sessionNameTmux) Command(args ...string) (string, error) {
+	out, err := t.Tmux.Command(args...)
+	if err == nil && len(args) > 0 && args[0] == "new-session" {
+		t.record(flagValue(args, "-s"))
+	}
+	return out, err
+}
+
+// record stores the session's display name. The session is already up, so a
+// failure only costs the pretty name.
+func (t sessionNameTmux) record(name string) {
+	display, ok := t.names[name]
+	if !ok {
+		return
+	}
+	if _, err := t.Tmux.Command("set-option", "-t", "="+name, sessionNameOption, display); err != nil {
+		debug.Error("project: record display name of %s: %v", name, err)
+	}
+}
+
+// legacySessionName is the session name pop gave a project before
+// project.SafeSessionName escaped spaces, quotes and non-ASCII characters:
+// only dots and colons became underscores.
+func legacySessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+// relinkLegacySessions renames the running sessions pop created under a
+// legacy name to the name each project now has, so an open session is found
+// again rather than listed as a standalone one next to its project. A session
+// is renamed only when it sits in the project's directory. History is keyed
+// by project path, not session name, so it needs no relinking.
+func relinkLegacySessions(tmux deps.Tmux, projects []project.ExpandedProject) {
+	out, err := tmux.Command("list-sessions", "-F", "#{session_name}\t#{session_path}")
+	if err != nil {
+		return
+	}
+	live := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if name, path, ok := strings.Cut(line, "\t"); ok {
+			live[name] = path
+		}
+	}
+	for _, p := range projects {
+		if _, ok := live[p.SessionName]; ok {
+			continue
+		}
+		for _, old := range legacySessionNames(p) {
+			path, ok := live[old]
+			if !ok || filepath.Clean(path) != filepath.Clean(p.Path) {
+				continue
+			}
+			if _, err := tmux.Command("rename-session", "-t", "="+old, p.SessionName); err != nil {
+				debug.Error("project: rename session %s to %s: %v", old, p.SessionName, err)
+				break
+			}
+			delete(live, old)
+			live[p.SessionName] = path
+			break
+		}
+	}
+}
+
+// legacySessionNames returns the names p's session may have had before: the
+// legacy form of the directory names its session name was built from, and
+// each shorter "/"-separated suffix of both, for a session name that since had
+// parent directories prefixed to keep it apart from another project's.
+func legacySessionNames(p project.ExpandedProject) []string {
+	segments := strings.Split(p.SessionName, "/")
+	dirs := strings.Split(filepath.ToSlash(filepath.Clean(p.Path)), "/")
+	seen := map[string]bool{p.SessionName: true}
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for i := range segments {
+		if n := len(segments) - i; n <= len(dirs) {
+			add(legacySessionName(strings.Join(dirs[len(dirs)-n:], "/")))
+		}
+		add(strings.Join(segments[i:], "/"))
+	}
+	return names
+}
+
+// sessionDisplayNames returns the recorded display name of each session that
+// has one, keyed by session name.
+func sessionDisplayNames() map[string]string {
+	return sessionDisplayNamesWith(defaultTmux)
+}
+
+func sessionDisplayNamesWith(tmux deps.Tmux) map[string]string {
+	out, err := tmux.Command("list-sessions", "-F", "#{session_name}\t#{"+sessionNameOption+"}")
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if name, display, ok := strings.Cut(line, "\t"); ok && display != "" {
+			names[name] = display
+		}
+	}
+	return names
+}
+
+// applySessionDisplayNames names each standalone session item after its
+// recorded display name. Its path keeps the session name, which is what
+// every action targets.
+func applySessionDisplayNames(items []ui.Item, names map[string]string) []ui.Item {
+	for i := range items {
+		if !isStandaloneSession(items[i]) {
+			continue
+		}
+		if display, ok := names[standaloneSessionName(items[i])]; ok {
+			items[i].Name = display
+		}
+	}
+	return items
+}
+
+// standaloneSessionRename is the picker's ui.RenameFunc: only standalone
+// sessions rename, since pop names a project's session after its directory
+// (session_name changes that).
+func standaloneSessionRename(item ui.Item) (string, bool) {
+	if !isStandaloneSession(item) {
+		return "", false
+	}
+	if item.Name != "" {
+		return item.Name, true
+	}
+	return standaloneSessionName(item), true
+}
+
+// renameSessionWith renames session name to the safe form of newName and, when
+// that had to change it, records newName as the session's display name. Any
+// display name left from before is cleared otherwise.
+func renameSessionWith(tmux deps.Tmux, name, newName string) error {
+	safe := project.SafeSessionName(newName)
+	if safe != name && tmux.HasSession(safe) {
+		return fmt.Errorf("session %s already exists", safe)
+	}
+	if _, err := tmux.Command("rename-session", "-t", "="+name, safe); err != nil {
+		return err
+	}
+	args := []string{"set-option", "-t", "=" + safe, sessionNameOption, newName}
+	if safe == newName {
+		args = []string{"set-option", "-u", "-t", "=" + safe, sessionNameOption}
+	}
+	if _, err := tmux.Command(args...); err != nil {
+		debug.Error("project: record display name of %s: %v", safe, err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestSessionNameTmux(t *testing.T) {
+	var calls []string
+	inner := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			calls = append(calls, strings.Join(args, " "))
+			return "", nil
+		},
+		NewSessionFunc: func(name, dir string) error {
+			calls = append(calls, "new "+name)
+			return nil
+		},
+	}
+	tmux := withSessionDisplayNames(inner, []project.ExpandedProject{
+		{Name: "my app", SessionName: "my_app"},
+		{Name: "docs", SessionName: "docs"},
+	})
+
+	if err := tmux.NewSession("my_app", "/src/my app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmux.NewSession("docs", "/src/docs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmux.Command("new-session", "-d", "-s", "my_app", "-c", "/src/my app"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"new my_app",
+		"set-option -t =my_app @pop_name my app",
+		"new docs",
+		"new-session -d -s my_app -c /src/my app",
+		"set-option -t =my_app @pop_name my app",
+	}
+	if !equalStrings(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestWithSessionDisplayNames_SafeNamesLeaveTmuxUnwrapped(t *testing.T) {
+	inner := &deps.MockTmux{}
+	if got := withSessionDisplayNames(inner, []project.ExpandedProject{{Name: "api", SessionName: "api"}}); got != deps.Tmux(inner) {
+		t.Errorf("withSessionDisplayNames wrapped tmux with only safe names: %T", got)
+	}
+}
+
+func TestSessionDisplayNamesWith(t *testing.T) {
+	tmux := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			return "my_app\tmy app\ndocs\t\nCaf_\tCafé", nil
+		},
+	}
+	got := sessionDisplayNamesWith(tmux)
+	if len(got) != 2 || got["my_app"] != "my app" || got["Caf_"] != "Café" {
+		t.Errorf("sessionDisplayNamesWith() = %v, want the two recorded names", got)
+	}
+}
+
+func TestApplySessionDisplayNames(t *testing.T) {
+	items := []ui.Item{
+		{Name: "Caf_", Path: tmuxSessionPathPrefix + "Caf_"},
+		{Name: "scratch", Path: tmuxSessionPathPrefix + "scratch"},
+		{Name: "Caf_", Path: "/src/Caf_"},
+	}
+	items = applySessionDisplayNames(items, map[string]string{"Caf_": "Café"})
+
+	if items[0].Name != "Café" || standaloneSessionName(items[0]) != "Caf_" {
+		t.Errorf("standalone item = %+v, want display name Café targeting Caf_", items[0])
+	}
+	if items[1].Name != "scratch" {
+		t.Errorf("unrecorded session renamed to %q", items[1].Name)
+	}
+	if items[2].Name != "Caf_" {
+		t.Errorf("project item renamed to %q", items[2].Name)
+	}
+}
+
+func TestStandaloneSessionRename(t *testing.T) {
+	if name, ok := standaloneSessionRename(ui.Item{Name: "Café", Path: tmuxSessionPathPrefix + "Caf_"}); !ok || name != "Café" {
+		t.Errorf("standaloneSessionRename(session) = %q, %v; want its display name", name, ok)
+	}
+	if _, ok := standaloneSessionRename(ui.Item{Name: "api", Path: "/src/api"}); ok {
+		t.Error("standaloneSessionRename(project) ok = true, want project sessions left alone")
+	}
+}
+
+func TestRenameSessionWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		newName  string
+		existing string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:    "safe name clears the display name",
+			newName: "notes",
+			want:    []string{"rename-session -t =scratch notes", "set-option -u -t =notes @pop_name"},
+		},
+		{
+			name:    "unsafe name keeps it as the display name",
+			newName: "my notes",
+			want:    []string{"rename-session -t =scratch my_20notes", "set-option -t =my_20notes @pop_name my notes"},
+		},
+		{
+			name:     "taken name fails",
+			newName:  "api",
+			existing: "api",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			tmux := &deps.MockTmux{
+				HasSessionFunc: func(name string) bool { return name == tt.existing },
+				CommandFunc: func(args ...string) (string, error) {
+					calls = append(calls, strings.Join(args, " "))
+					return "", nil
+				},
+			}
+			err := renameSessionWith(tmux, "scratch", tt.newName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renameSessionWith() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !equalStrings(calls, tt.want) {
+				t.Errorf("calls = %q, want %q", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelinkLegacySessions(t *testing.T) {
+	var renamed []string
+	tmux := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			if args[0] == "list-sessions" {
+				return "my app\t/src/my app\nwork_api\t/src/work.api\napi\t/src/work/api\nnotes\t/elsewhere", nil
+			}
+			renamed = append(renamed, strings.Join(args, " "))
+			return "", nil
+		},
+	}
+	relinkLegacySessions(tmux, []project.ExpandedProject{
+		{Path: "/src/my app", SessionName: "my_20app"},
+		{Path: "/src/work.api", SessionName: "work_api"},
+		{Path: "/src/work/api", SessionName: "work/api"},
+		{Path: "/src/notes v1", SessionName: "notes_20v1"},
+	})
+
+	want := []string{
+		"rename-session -t =my app my_20app",
+		"rename-session -t =api work/api",
+	}
+	if !equalStrings(renamed, want) {
+		t.Errorf("renames = %q, want %q", renamed, want)
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/glebglazov/pop/history"
 	"github.com/glebglazov/pop/internal/deps"
 	"github.com/glebglazov/pop/monitor"
+	"github.com/glebglazov/pop/project"
 	"github.com/glebglazov/pop/ui"
 )
 
@@ -100,7 +102,7 @@ func TestSessionHistoryPath(t *testing.T) {
 	}{
 		{
 			name:        "exact match via sanitized base",
-			sessionName: "my_project", // sanitize turns . into _
+			sessionName: "my_project", // SafeSessionName turns . into _
 			expected:    "/home/user/my.project",
 		},
 		{
@@ -416,3 +418,107 @@ func TestSwitchToTmuxTargetAndZoomWith_InTmux(t *testing.T) {
 		}
 	}
 }
+
+func TestPopupTmux(t *testing.T) {
+	var got [][]string
+	inner := &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			got = append(got, args)
+			return "", nil
+		},
+		SwitchClientFunc: func(name string) error {
+			t.Error("popupTmux must chain the switch through Command")
+			return nil
+		},
+	}
+	tmux := withPopupSwitch(inner, "close_popup", true)
+
+	if err := tmux.SwitchClient("work"); err != nil {
+		t.Fatalf("SwitchClient() error = %v", err)
+	}
+	if _, err := tmux.Command("switch-client", "-t", "%5", ";", "resize-pane", "-Z"); err != nil {
+		t.Fatalf("Command() error = %v", err)
+	}
+	if _, err := tmux.Command("display-message", "-p", "#S"); err != nil {
+		t.Fatalf("Command() error = %v", err)
+	}
+
+	want := []string{
+		"switch-client -t work ; display-popup -C",
+		"switch-client -t %5 ; resize-pane -Z ; display-popup -C",
+		"display-message -p #S",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("commands = %v, want %v", got, want)
+	}
+	for i := range want {
+		if strings.Join(got[i], " ") != want[i] {
+			t.Errorf("command %d = %q, want %q", i, strings.Join(got[i], " "), want[i])
+		}
+	}
+}
+
+func TestWithPopupSwitch(t *testing.T) {
+	inner := &deps.MockTmux{}
+	if _, ok := withPopupSwitch(inner, "close_popup", true).(popupTmux); !ok {
+		t.Error("close_popup inside a popup should wrap tmux")
+	}
+	if withPopupSwitch(inner, "direct", true) != deps.Tmux(inner) {
+		t.Error("direct should leave tmux unchanged")
+	}
+	if withPopupSwitch(inner, "close_popup", false) != deps.Tmux(inner) {
+		t.Error("outside a popup tmux should be unchanged")
+	}
+}
+
+func TestInTmuxPopup(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,12345,0")
+	t.Setenv("TMUX_PANE", "%3")
+	if inTmuxPopup() {
+		t.Error("a pane with $TMUX_PANE is not a popup")
+	}
+	t.Setenv("TMUX_PANE", "")
+	if !inTmuxPopup() {
+		t.Error("$TMUX without $TMUX_PANE should be detected as a popup")
+	}
+	t.Setenv("TMUX", "")
+	if inTmuxPopup() {
+		t.Error("outside tmux is not a popup")
+	}
+}
+
+func TestSessionExclusion(t *testing.T) {
+	var nilExclusion *sessionExclusion
+	if nilExclusion.excludes(project.ExpandedProject{Path: "/src/api"}) || nilExclusion.sessionNames() != nil {
+		t.Error("nil exclusion should exclude nothing")
+	}
+
+	resolves := 0
+	e := &sessionExclusion{
+		names: map[string]bool{"api": true},
+		dir:   "/src/web",
+		fs: &deps.MockFileSystem{EvalSymlinksFunc: func(path string) (string, error) {
+			resolves++
+			return strings.Replace(path, "/links/", "/src/", 1), nil
+		}},
+		resolved: make(map[string]string),
+	}
+	tests := []struct {
+		ep   project.ExpandedProject
+		want bool
+	}{
+		{project.ExpandedProject{Path: "/src/api", SessionName: "api"}, true},
+		{project.ExpandedProject{Path: "/src/web", SessionName: "web"}, true},
+		{project.ExpandedProject{Path: "/links/web", SessionName: "web-link"}, true},
+		{project.ExpandedProject{Path: "/src/docs", SessionName: "docs"}, false},
+	}
+	for _, tt := range tests {
+		if got := e.excludes(tt.ep); got != tt.want {
+			t.Errorf("excludes(%+v) = %v, want %v", tt.ep, got, tt.want)
+		}
+	}
+	e.excludes(project.ExpandedProject{Path: "/links/web"})
+	if resolves != 2 {
+		t.Errorf("resolved %d times, want each path once", resolves)
+	}
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/project"
+	"github.com/spf13/cobra"
+)
+
+var statusFormat string
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the repo, project and session of the current directory",
+	Long: `Print what pop makes of the current directory: the git repo it is in, the
+configured project holding it (and the projects entry that lists it), the
+worktree, the tmux session pop opens for it and when it was last opened.
+
+--format plain (default) prints one "key: value" line each; --format json
+prints one object, for status bars. Fields pop can't work out are left out.
+Comparing the project name with the session name shows how a name was made
+safe for tmux.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStatusWith(DefaultProjectDeps(), statusFormat, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVar(&statusFormat, "format", "plain", "output format: plain or json")
+}
+
+// statusReport is the output of pop status.
+type statusReport struct {
+	Dir     string         `json:"dir"`
+	Repo    *statusRepo    `json:"repo,omitempty"`
+	Project *statusProject `json:"project,omitempty"`
+	// CurrentSession is the tmux session pop runs in, "" outside tmux.
+	CurrentSession string `json:"current_session,omitempty"`
+}
+
+type statusRepo struct {
+	Root string `json:"root"`
+	Name string `json:"name"`
+	Bare bool   `json:"bare"`
+}
+
+type statusProject struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Entry is the path of the projects entry listing the project; "" for a
+	// pop-managed worktree.
+	Entry       string     `json:"entry,omitempty"`
+	Worktree    string     `json:"worktree,omitempty"`
+	Branch      string     `json:"branch,omitempty"`
+	SessionName string     `json:"session_name"`
+	HasSession  bool       `json:"has_session"`
+	LastAccess  *time.Time `json:"last_access,omitempty"`
+}
+
+// runStatusWith prints the status of the working directory in format to w.
+// Projects are collected by statusProjectsWith.
+func runStatusWith(d *ProjectDeps, format string, w io.Writer) error {
+	if format != "plain" && format != "json" {
+		return fmt.Errorf("invalid --format %q (want plain or json)", format)
+	}
+	// Status only reads: history is neither relinked nor pruned.
+	d.NoHistory = true
+	dir, err := d.Project.FS.Getwd()
+	if err != nil {
+		return err
+	}
+	report := statusReport{Dir: filepath.Clean(dir)}
+
+	if ctx, err := project.DetectRepoContextFromPathWith(d.Project, report.Dir); err == nil {
+		report.Repo = &statusRepo{Root: ctx.GitRoot, Name: ctx.RepoName, Bare: ctx.IsBare}
+	}
+	if d.InTmux != nil && d.InTmux() && d.CurrentSession != nil {
+		report.CurrentSession = d.CurrentSession(d.Tmux)
+	}
+
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	cfg, err := d.LoadConfig()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// Without a config there is no project to report.
+	case err != nil:
+		return fmt.Errorf("failed to load config: %w", err)
+	default:
+		hist, err := d.LoadHistory()
+		if err != nil {
+			hist = &history.History{}
+		}
+		projects, err := statusProjectsWith(d, cfg, cfgPath, report.Dir)
+		if err != nil {
+			return err
+		}
+		if p, ok := projectHolding(projects, report.Dir); ok {
+			report.Project = statusProjectFor(d, cfg, hist, p)
+		}
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(report)
+	}
+	writeStatusPlain(w, report)
+	return nil
+}
+
+// statusProjectsWith collects the projects named as the picker names them,
+// cheaply enough for a status bar polling every few seconds: a running
+// daemon's list when there is one, otherwise the globs expanded through the
+// glob cache with only the paths holding dir checked for bare-repo worktrees.
+// No config projects is no error, just nothing to report.
+func statusProjectsWith(d *ProjectDeps, cfg *config.Config, cfgPath, dir string) ([]project.ExpandedProject, error) {
+	var (
+		expanded []project.ExpandedProject
+		cached   bool
+	)
+	if d.CachedProjects != nil {
+		expanded, _, _, cached = d.CachedProjects(cfgPath)
+	}
+	if !cached {
+		paths, err := cfg.ExpandProjects()
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand projects: %w", err)
+		}
+		for i := range paths {
+			if paths[i].Path != dir && !strings.HasPrefix(dir, paths[i].Path+"/") {
+				paths[i].SkipWorktrees = true
+			}
+		}
+		expanded, _ = expandProjectsStreamWith(context.Background(), d.Project, paths, cfg.GetScanConcurrency(), nil)
+		if err := config.FlushGlobCache(); err != nil {
+			debug.Error("glob cache: %v", err)
+		}
+	}
+	if d.ManagedWorktrees != nil {
+		expanded = append(expanded, d.ManagedWorktrees()...)
+	}
+	project.DisambiguateNames(expanded, cfg.GetDisambiguationStrategy())
+	return expanded, nil
+}
+
+// projectHolding returns the project at dir or, failing that, the one at its
+// nearest ancestor.
+func projectHolding(projects []project.ExpandedProject, dir string) (project.ExpandedProject, bool) {
+	best := nearestHolding(projects, dir, func(p project.ExpandedProject) (string, bool) {
+		return p.Path, true
+	})
+	if best < 0 {
+		return project.ExpandedProject{}, false
+	}
+	return projects[best], true
+}
+
+func statusProjectFor(d *ProjectDeps, cfg *config.Config, hist *history.History, p project.ExpandedProject) *statusProject {
+	sp := &statusProject{Name: p.Name, Path: p.Path, SessionName: p.SessionName}
+	// A worktree expanded from a bare repo is listed through the repo.
+	listed := p.Path
+	if p.IsWorktree && p.RepoRoot != "" {
+		listed = p.RepoRoot
+		sp.Worktree = filepath.Base(p.Path)
+		sp.Branch = p.Branch
+	}
+	if entry, ok := cfg.MatchingProjectEntry(listed); ok {
+		sp.Entry = entry.Path
+	}
+	_, sp.HasSession = d.SessionActivity()[p.SessionName]
+	for _, e := range hist.Entries {
+		if e.Path == p.Path {
+			t := e.LastAccess
+			sp.LastAccess = &t
+			break
+		}
+	}
+	return sp
+}
+
+func writeStatusPlain(w io.Writer, r statusReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "dir:\t%s\n", r.Dir)
+	if r.Repo != nil {
+		kind := ""
+		if r.Repo.Bare {
+			kind = ", bare"
+		}
+		fmt.Fprintf(tw, "repo:\t%s (%s%s)\n", r.Repo.Root, r.Repo.Name, kind)
+	}
+	if p := r.Project; p != nil {
+		fmt.Fprintf(tw, "project:\t%s (%s)\n", p.Name, p.Path)
+		if p.Entry != "" {
+			fmt.Fprintf(tw, "entry:\t%s\n", p.Entry)
+		}
+		if p.Worktree != "" {
+			worktree := p.Worktree
+			if p.Branch != "" && p.Branch != p.Worktree {
+				worktree += " (" + p.Branch + ")"
+			}
+			fmt.Fprintf(tw, "worktree:\t%s\n", worktree)
+		}
+		state := "not running"
+		if p.HasSession {
+			state = "running"
+		}
+		fmt.Fprintf(tw, "session:\t%s (%s)\n", p.SessionName, state)
+		lastAccess := "never"
+		if p.LastAccess != nil {
+			lastAccess = p.LastAccess.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "last access:\t%s\n", lastAccess)
+	} else {
+		fmt.Fprintf(tw, "project:\tnone\n")
+	}
+	if r.CurrentSession != "" {
+		fmt.Fprintf(tw, "current session:\t%s\n", r.CurrentSession)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// statusTestDeps is listTestDeps with the working directory inside the
+// recent project, a regular git repo.
+func statusTestDeps(t *testing.T) (*ProjectDeps, string) {
+	t.Helper()
+	d, _, recent := listTestDeps(t)
+	d.Project.FS = &deps.MockFileSystem{
+		GetwdFunc: func() (string, error) { return filepath.Join(recent, "cmd"), nil },
+	}
+	d.Project.Git = &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			if strings.Join(args, " ") == "rev-parse --show-toplevel" {
+				return recent, nil
+			}
+			return "", errors.New("not this")
+		},
+	}
+	return d, recent
+}
+
+func TestRunStatus_Plain(t *testing.T) {
+	d, recent := statusTestDeps(t)
+	var out bytes.Buffer
+	if err := runStatusWith(d, "plain", &out); err != nil {
+		t.Fatalf("runStatusWith() error = %v", err)
+	}
+	for _, want := range []string{
+		"repo:         " + recent + " (recent)",
+		"project:      recent (" + recent + ")",
+		"entry:        " + filepath.Join(filepath.Dir(recent), "*"),
+		"session:      recent (running)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunStatus_JSON(t *testing.T) {
+	d, recent := statusTestDeps(t)
+	var out bytes.Buffer
+	if err := runStatusWith(d, "json", &out); err != nil {
+		t.Fatalf("runStatusWith() error = %v", err)
+	}
+	var report statusReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Dir != filepath.Join(recent, "cmd") || report.Repo == nil || report.Repo.Root != recent {
+		t.Errorf("report = %+v, want the cwd inside the recent repo", report)
+	}
+	p := report.Project
+	if p == nil || p.Path != recent || p.SessionName != "recent" || !p.HasSession || p.LastAccess == nil {
+		t.Errorf("project = %+v, want recent with its session and last access", p)
+	}
+}
+
+func TestRunStatus_OutsideProjects(t *testing.T) {
+	d, _ := statusTestDeps(t)
+	d.Project.FS = &deps.MockFileSystem{GetwdFunc: func() (string, error) { return "/tmp", nil }}
+	var out bytes.Buffer
+	if err := runStatusWith(d, "plain", &out); err != nil {
+		t.Fatalf("runStatusWith() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "project:  none") {
+		t.Errorf("output = %q, want no project", out.String())
+	}
+}
+
+func TestRunStatus_NoProjects(t *testing.T) {
+	d, _ := statusTestDeps(t)
+	d.LoadConfig = func() (*config.Config, error) { return &config.Config{}, nil }
+	var out bytes.Buffer
+	if err := runStatusWith(d, "plain", &out); err != nil {
+		t.Fatalf("runStatusWith() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "project:  none") {
+		t.Errorf("output = %q, want no project", out.String())
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/project"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statuslineFormat string
+	statuslineDir    string
+)
+
+var statuslineCmd = &cobra.Command{
+	Use:   "statusline",
+	Short: "Print the current project as a compact status-line segment",
+	Long: `Print the configured project holding the current directory as one short
+line, for tmux status-right or a shell prompt. Outside every project it prints
+nothing.
+
+The format (--format, else statusline_format in the config, else
+"{project}{dirty}") replaces these tokens:
+
+  {project}   the picker name, such as repo/worktree
+  {repo}      the repository (or project directory) name
+  {worktree}  the worktree directory, "" outside bare-repo worktrees
+  {branch}    the checked-out branch
+  {dirty}     "*" when the checkout has changes
+  {status}    git state as in the picker: ● changes, ↑ahead ↓behind
+  {session}   the tmux session pop opens for the project
+
+tmux runs #() commands in its own directory, so pass the pane's:
+  set -g status-right '#(pop statusline --dir "#{pane_current_path}")'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStatuslineWith(DefaultProjectDeps(), statuslineFormat, statuslineDir, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statuslineCmd)
+	statuslineCmd.Flags().StringVar(&statuslineFormat, "format", "", "segment format (default: statusline_format, else \"{project}{dirty}\")")
+	statuslineCmd.Flags().StringVar(&statuslineDir, "dir", "", "directory to describe instead of the current one")
+}
+
+// runStatuslineWith prints the status-line segment of the project holding dir
+// (the working directory when "") in format (the configured one when "") to
+// w. Projects are collected by statusProjectsWith; git runs only when format
+// asks for the branch or state.
+func runStatuslineWith(d *ProjectDeps, format, dir string, w io.Writer) error {
+	// tmux polls this every status-interval: never touch history.
+	d.NoHistory = true
+	if dir == "" {
+		wd, err := d.Project.FS.Getwd()
+		if err != nil {
+			return err
+		}
+		dir = wd
+	}
+	dir = filepath.Clean(dir)
+
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	cfg, err := d.LoadConfig()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if format == "" {
+		format = cfg.GetStatuslineFormat()
+	}
+	projects, err := statusProjectsWith(d, cfg, cfgPath, dir)
+	if err != nil {
+		return err
+	}
+	p, ok := projectHolding(projects, dir)
+	if !ok {
+		return nil
+	}
+	_, err = fmt.Fprintln(w, formatStatusline(d.Project, format, p))
+	return err
+}
+
+// formatStatusline fills format's tokens in for p.
+func formatStatusline(d *project.Deps, format string, p project.ExpandedProject) string {
+	worktree := ""
+	if p.IsWorktree {
+		worktree = filepath.Base(p.Path)
+	}
+	branch, dirty, status := p.Branch, "", ""
+	if strings.Contains(format, "{branch}") || strings.Contains(format, "{dirty}") || strings.Contains(format, "{status}") {
+		if s, err := project.GitStatusOfWith(d, p.Path); err != nil {
+			debug.Log("statusline: git status %s: %v", p.Path, err)
+		} else {
+			if s.Branch != "" {
+				branch = s.Branch
+			}
+			if s.Dirty {
+				dirty = "*"
+			}
+			status = s.String()
+		}
+	}
+	return strings.NewReplacer(
+		"{project}", p.Name,
+		"{repo}", p.ProjectName,
+		"{worktree}", worktree,
+		"{branch}", branch,
+		"{dirty}", dirty,
+		"{status}", status,
+		"{session}", p.SessionName,
+	).Replace(format)
+}
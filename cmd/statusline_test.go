@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func TestRunStatusline(t *testing.T) {
+	d, _, recent := listTestDeps(t)
+	gitCalls := 0
+	d.Project.Git = &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			if args[0] != "status" || dir != recent {
+				return "", errors.New("not this")
+			}
+			gitCalls++
+			return "# branch.head feature\n# branch.ab +2 -0\n1 .M N... 100644 100644 100644 a b main.go\n", nil
+		},
+	}
+	dir := filepath.Join(recent, "cmd")
+
+	tests := []struct {
+		format string
+		want   string
+		git    int
+	}{
+		{"", "recent*\n", 1},
+		{"{repo}@{branch} {status}", "recent@feature ● ↑2\n", 1},
+		{"[{session}]", "[recent]\n", 0},
+	}
+	for _, tt := range tests {
+		gitCalls = 0
+		var out bytes.Buffer
+		if err := runStatuslineWith(d, tt.format, dir, &out); err != nil {
+			t.Fatalf("runStatuslineWith(%q) error = %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("runStatuslineWith(%q) = %q, want %q", tt.format, out.String(), tt.want)
+		}
+		if gitCalls != tt.git {
+			t.Errorf("runStatuslineWith(%q) ran git status %d times, want %d", tt.format, gitCalls, tt.git)
+		}
+	}
+}
+
+func TestRunStatusline_OutsideProjectsPrintsNothing(t *testing.T) {
+	d, _, _ := listTestDeps(t)
+	var out bytes.Buffer
+	if err := runStatuslineWith(d, "", "/tmp", &out); err != nil {
+		t.Fatalf("runStatuslineWith() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output = %q, want nothing", out.String())
+	}
+}
+
+func TestRunStatusline_NoProjectsPrintsNothing(t *testing.T) {
+	d, _, recent := listTestDeps(t)
+	d.LoadConfig = func() (*config.Config, error) { return &config.Config{}, nil }
+	var out bytes.Buffer
+	if err := runStatuslineWith(d, "", recent, &out); err != nil {
+		t.Fatalf("runStatuslineWith() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output = %q, want nothing", out.String())
+	}
+}
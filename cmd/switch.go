@@ -40,8 +40,9 @@ type SwitchDeps struct {
 
 	SessionName func(path string) string
 	LoadHistory func() (*history.History, error)
-	SaveHistory func(h *history.History) error
-	InTmux      func() bool
+	// UpdateHistory applies fn to h and saves it (see history.History.Update).
+	UpdateHistory func(h *history.History, fn func(*history.History)) error
+	InTmux        func() bool
 }
 
 // DefaultSwitchDeps returns SwitchDeps wired to real production implementations.
@@ -53,8 +54,10 @@ func DefaultSwitchDeps() *SwitchDeps {
 		LoadHistory: func() (*history.History, error) {
 			return history.Load(history.DefaultHistoryPath())
 		},
-		SaveHistory: func(h *history.History) error { return h.Save() },
-		InTmux:      func() bool { return os.Getenv("TMUX") != "" },
+		UpdateHistory: func(h *history.History, fn func(*history.History)) error {
+			return h.Update(fn)
+		},
+		InTmux: func() bool { return os.Getenv("TMUX") != "" },
 	}
 }
 
@@ -78,8 +81,7 @@ func RunProjectSwitch(d *SwitchDeps, dir string) error {
 	if hist == nil {
 		hist = &history.History{}
 	}
-	hist.Record(path)
-	if err := d.SaveHistory(hist); err != nil {
+	if err := d.UpdateHistory(hist, func(h *history.History) { h.Record(path) }); err != nil {
 		debug.Error("project switch: save history: %v", err)
 	}
 
@@ -34,8 +34,11 @@ func mockSwitchDeps() (*SwitchDeps, *history.History, *[]string) {
 		},
 		SessionName: func(path string) string { return "session-name" },
 		LoadHistory: func() (*history.History, error) { return hist, nil },
-		SaveHistory: func(h *history.History) error { return nil },
-		InTmux:      func() bool { return true },
+		UpdateHistory: func(h *history.History, fn func(*history.History)) error {
+			fn(h)
+			return nil
+		},
+		InTmux: func() bool { return true },
 	}
 	return d, hist, &tmuxCalls
 }
@@ -116,7 +119,11 @@ func TestRunProjectSwitch(t *testing.T) {
 		d, _, _ := mockSwitchDeps()
 		d.LoadHistory = func() (*history.History, error) { return nil, os.ErrPermission }
 		var saved *history.History
-		d.SaveHistory = func(h *history.History) error { saved = h; return nil }
+		d.UpdateHistory = func(h *history.History, fn func(*history.History)) error {
+			fn(h)
+			saved = h
+			return nil
+		}
 
 		if err := RunProjectSwitch(d, "/repo/feature"); err != nil {
 			t.Fatal(err)
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/glebglazov/pop/tips"
+	"github.com/glebglazov/pop/ui"
+)
+
+// pickerTips returns the one-time footer tips for a picker mode ("project" or
+// "worktree"), in the order they are shown. Each tip names the key the action
+// is bound to once the [keys] remaps in bindings apply. Tips naming a key the
+// picker will not honour (quick access disabled, tmux-only actions outside
+// tmux) are left out. IDs are shared across modes where the action is, so a
+// tip seen in one picker is not repeated in the other.
+func pickerTips(mode, quickAccessModifier string, bindings map[string]string, inTmux bool) []tips.Tip {
+	key := func(action string) string { return ui.KeyHint(action, bindings) }
+	list := []tips.Tip{
+		{ID: "help", Text: "Tip: " + key("help") + " lists every key binding"},
+	}
+	switch quickAccessModifier {
+	case "alt":
+		list = append(list, tips.Tip{ID: "quick-access", Text: "Tip: A-1..9 jumps straight to a numbered entry"})
+	case "ctrl":
+		list = append(list, tips.Tip{ID: "quick-access", Text: "Tip: C-1..9 jumps straight to a numbered entry"})
+	}
+	if mode == "worktree" {
+		list = append(list, tips.Tip{ID: "create-worktree", Text: "Tip: " + key("create_worktree") + " creates a new worktree from a branch"})
+	}
+	if !inTmux {
+		return list
+	}
+	list = append(list,
+		tips.Tip{ID: "kill-session", Text: "Tip: " + key("kill_session") + " kills the highlighted tmux session"},
+		tips.Tip{ID: "yank-path", Text: "Tip: " + key("yank_path") + " sends the highlighted path to a tmux pane"},
+	)
+	if mode == "project" {
+		list = append(list, tips.Tip{ID: "open-window", Text: "Tip: " + key("open_window") + " opens the project as a window in the current session"})
+	}
+	return list
+}
+
+// pickerTip returns the next unseen tip text for a picker mode and marks it
+// shown, or "" once every tip has been seen.
+func pickerTip(mode, quickAccessModifier string, bindings map[string]string, inTmux bool) string {
+	tip, ok := tips.Next(pickerTips(mode, quickAccessModifier, bindings, inTmux))
+	if !ok {
+		return ""
+	}
+	return tip.Text
+}
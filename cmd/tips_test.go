@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/glebglazov/pop/tips"
+)
+
+func tipIDs(list []tips.Tip) map[string]bool {
+	ids := make(map[string]bool, len(list))
+	for _, tip := range list {
+		ids[tip.ID] = true
+	}
+	return ids
+}
+
+func TestPickerTips(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		modifier string
+		inTmux   bool
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "project in tmux",
+			mode:     "project",
+			modifier: "alt",
+			inTmux:   true,
+			want:     []string{"help", "quick-access", "kill-session", "yank-path", "open-window"},
+			notWant:  []string{"create-worktree"},
+		},
+		{
+			name:     "outside tmux drops tmux-only tips",
+			mode:     "project",
+			modifier: "alt",
+			want:     []string{"help", "quick-access"},
+			notWant:  []string{"kill-session", "yank-path", "open-window"},
+		},
+		{
+			name:     "quick access disabled",
+			mode:     "worktree",
+			modifier: "disabled",
+			inTmux:   true,
+			want:     []string{"help", "create-worktree"},
+			notWant:  []string{"quick-access", "open-window"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := tipIDs(pickerTips(tt.mode, tt.modifier, nil, tt.inTmux))
+			for _, id := range tt.want {
+				if !ids[id] {
+					t.Errorf("missing tip %q", id)
+				}
+			}
+			for _, id := range tt.notWant {
+				if ids[id] {
+					t.Errorf("unexpected tip %q", id)
+				}
+			}
+		})
+	}
+}
+
+func TestPickerTips_NameRemappedKeys(t *testing.T) {
+	texts := make(map[string]string)
+	for _, tip := range pickerTips("project", "alt", map[string]string{"kill_session": "ctrl-q"}, true) {
+		texts[tip.ID] = tip.Text
+	}
+	if want := "Tip: C-q kills the highlighted tmux session"; texts["kill-session"] != want {
+		t.Errorf("kill-session tip = %q, want %q", texts["kill-session"], want)
+	}
+	if want := "Tip: C-y sends the highlighted path to a tmux pane"; texts["yank-path"] != want {
+		t.Errorf("yank-path tip = %q, want the default key: %q", texts["yank-path"], want)
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/spf13/cobra"
+)
+
+// tmuxInitOptions are the pop tmux-init flags: the popup size, empty for the
+// configured one, and the key of each binding, "none" leaving that binding out.
+type tmuxInitOptions struct {
+	Width       string
+	Height      string
+	ProjectKey  string
+	WorktreeKey string
+	KillKey     string
+}
+
+var (
+	tmuxInitOpts    tmuxInitOptions
+	tmuxInitInstall bool
+	tmuxInitPath    string
+)
+
+var tmuxInitCmd = &cobra.Command{
+	Use:   "tmux-init",
+	Short: "Print the recommended tmux bindings for pop",
+	Long: `Print tmux key bindings that open pop's pickers in a display-popup: the
+project picker, the worktree picker for the repository of the current pane
+(switching sessions on Enter), and pop kill.
+
+The popups take the size --popup opens them at: popup_width and popup_height
+of [project.ui] (the project picker and pop kill) or [worktree.ui], 60% by
+default. --width and --height override it, as cells or a percentage.
+--project-key, --worktree-key and --kill-key choose the keys bound after the
+prefix; "none" leaves a binding out. The default keys shadow none of tmux's.
+
+--install writes the bindings to ~/.tmux.conf.d/pop.tmux (or --path) instead,
+replacing what an earlier run wrote there. Source it from ~/.tmux.conf:
+
+  source-file ~/.tmux.conf.d/pop.tmux
+
+Examples:
+  pop tmux-init >> ~/.tmux.conf
+  pop tmux-init --install --width 80% --height 80% --kill-key none`,
+	Args: cobra.NoArgs,
+	RunE: runTmuxInit,
+}
+
+func init() {
+	rootCmd.AddCommand(tmuxInitCmd)
+	tmuxInitCmd.Flags().StringVar(&tmuxInitOpts.Width, "width", "", "popup width, cells or a percentage (default: the configured popup_width)")
+	tmuxInitCmd.Flags().StringVar(&tmuxInitOpts.Height, "height", "", "popup height, cells or a percentage (default: the configured popup_height)")
+	tmuxInitCmd.Flags().StringVar(&tmuxInitOpts.ProjectKey, "project-key", "g", `key for the project picker, "none" to leave it out`)
+	tmuxInitCmd.Flags().StringVar(&tmuxInitOpts.WorktreeKey, "worktree-key", "P", `key for the worktree picker, "none" to leave it out`)
+	tmuxInitCmd.Flags().StringVar(&tmuxInitOpts.KillKey, "kill-key", "K", `key for pop kill, "none" to leave it out`)
+	tmuxInitCmd.Flags().BoolVar(&tmuxInitInstall, "install", false, "write the bindings to a file to source instead of printing them")
+	tmuxInitCmd.Flags().StringVar(&tmuxInitPath, "path", "", "file --install writes (default ~/.tmux.conf.d/pop.tmux)")
+}
+
+func runTmuxInit(cmd *cobra.Command, args []string) error {
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		// Without a config the popups take the default size.
+		cfg = &config.Config{}
+	}
+	if !tmuxInitInstall {
+		if tmuxInitPath != "" {
+			return fmt.Errorf("--path needs --install")
+		}
+		return runTmuxInitWith(nil, cfg, tmuxInitOpts, "", os.Stdout)
+	}
+	path := tmuxInitPath
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("tmux-init: %w", err)
+		}
+		path = filepath.Join(home, ".tmux.conf.d", "pop.tmux")
+	}
+	return runTmuxInitWith(deps.NewRealFileSystem(), cfg, tmuxInitOpts, path, os.Stdout)
+}
+
+// runTmuxInitWith renders the bindings for o and cfg and prints them to w, or,
+// when path is set, writes them to path through fs and tells w how to source
+// it.
+func runTmuxInitWith(fs deps.FileSystem, cfg *config.Config, o tmuxInitOptions, path string, w io.Writer) error {
+	bindings, err := tmuxInitBindings(cfg, o)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		_, err := io.WriteString(w, bindings)
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("tmux-init: %w", err)
+	}
+	if err := fs.WriteFile(path, []byte(bindings), 0o644); err != nil {
+		return fmt.Errorf("tmux-init: %w", err)
+	}
+	fmt.Fprintf(w, "Wrote %s. Source it from ~/.tmux.conf:\n  source-file %s\n", path, path)
+	return nil
+}
+
+// tmuxInitBindings returns the tmux config for o, one bind-key per binding
+// it keeps, under a header saying where it came from. Popups o does not size
+// take cfg's popup size for their picker mode.
+func tmuxInitBindings(cfg *config.Config, o tmuxInitOptions) (string, error) {
+	for _, size := range []struct{ flag, value string }{{"--width", o.Width}, {"--height", o.Height}} {
+		if size.value != "" && !config.ValidPopupSize(size.value) {
+			return "", fmt.Errorf("invalid %s %q (want a cell count or a percentage)", size.flag, size.value)
+		}
+	}
+	popup := func(mode string) string {
+		width, height := cfg.PopupSizeForMode(mode)
+		if o.Width != "" {
+			width = o.Width
+		}
+		if o.Height != "" {
+			height = o.Height
+		}
+		return fmt.Sprintf("display-popup -E -w %s -h %s", width, height)
+	}
+	bindings := []struct{ flag, key, command string }{
+		{"--project-key", o.ProjectKey, popup("project") + " 'pop project dashboard'"},
+		{"--worktree-key", o.WorktreeKey, popup("worktree") + ` -d "#{pane_current_path}" 'pop worktree dashboard --switch'`},
+		{"--kill-key", o.KillKey, popup("project") + " 'pop kill'"},
+	}
+
+	var b strings.Builder
+	b.WriteString("# pop tmux bindings, generated by pop tmux-init.\n")
+	for _, binding := range bindings {
+		if binding.key == "none" {
+			continue
+		}
+		if binding.key == "" || strings.ContainsAny(binding.key, " \t'\"") {
+			return "", fmt.Errorf("invalid %s %q", binding.flag, binding.key)
+		}
+		fmt.Fprintf(&b, "bind-key %s %s\n", binding.key, binding.command)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func defaultTmuxInitOptions() tmuxInitOptions {
+	return tmuxInitOptions{ProjectKey: "g", WorktreeKey: "P", KillKey: "K"}
+}
+
+func TestTmuxInitBindings(t *testing.T) {
+	got, err := tmuxInitBindings(&config.Config{}, defaultTmuxInitOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# pop tmux bindings, generated by pop tmux-init.
+bind-key g display-popup -E -w 60% -h 60% 'pop project dashboard'
+bind-key P display-popup -E -w 60% -h 60% -d "#{pane_current_path}" 'pop worktree dashboard --switch'
+bind-key K display-popup -E -w 60% -h 60% 'pop kill'
+`
+	if got != want {
+		t.Errorf("bindings =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTmuxInitBindings_ConfiguredSize(t *testing.T) {
+	cfg := &config.Config{
+		Project:  &config.ProjectConfig{UI: &config.PickerUIConfig{PopupWidth: "90%", PopupHeight: "40"}},
+		Worktree: &config.WorktreeConfig{UI: &config.PickerUIConfig{PopupWidth: "50%"}},
+	}
+	got, err := tmuxInitBindings(cfg, defaultTmuxInitOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"bind-key g display-popup -E -w 90% -h 40 'pop project dashboard'",
+		"bind-key P display-popup -E -w 50% -h 60% -d",
+		"bind-key K display-popup -E -w 90% -h 40 'pop kill'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("bindings =\n%s\nwant %q", got, want)
+		}
+	}
+
+	o := defaultTmuxInitOptions()
+	o.Width = "70%"
+	if got, _ := tmuxInitBindings(cfg, o); !strings.Contains(got, "-w 70% -h 40 'pop project dashboard'") {
+		t.Errorf("bindings =\n%s\nwant --width to override the configured width only", got)
+	}
+}
+
+func TestTmuxInitBindings_Options(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(o *tmuxInitOptions)
+		want    string
+		wantErr string
+	}{
+		{name: "custom size", edit: func(o *tmuxInitOptions) { o.Width, o.Height = "120", "80%" }, want: "-w 120 -h 80%"},
+		{name: "binding left out", edit: func(o *tmuxInitOptions) { o.KillKey = "none" }},
+		{name: "bad size", edit: func(o *tmuxInitOptions) { o.Width = "wide" }, wantErr: `invalid --width "wide"`},
+		{name: "zero size", edit: func(o *tmuxInitOptions) { o.Height = "0%" }, wantErr: `invalid --height "0%"`},
+		{name: "bad key", edit: func(o *tmuxInitOptions) { o.ProjectKey = "" }, wantErr: `invalid --project-key ""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := defaultTmuxInitOptions()
+			tt.edit(&o)
+			got, err := tmuxInitBindings(&config.Config{}, o)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("bindings =\n%s\nwant %q", got, tt.want)
+			}
+			if o.KillKey == "none" && strings.Contains(got, "pop kill") {
+				t.Errorf("bindings =\n%s\nwant no pop kill binding", got)
+			}
+		})
+	}
+}
+
+func TestRunTmuxInitWith_Install(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".tmux.conf.d", "pop.tmux")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("stale\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+
+	if err := runTmuxInitWith(deps.NewRealFileSystem(), &config.Config{}, defaultTmuxInitOptions(), path, &out); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := tmuxInitBindings(&config.Config{}, defaultTmuxInitOptions())
+	if string(data) != want {
+		t.Errorf("wrote\n%s\nwant\n%s", data, want)
+	}
+	if !strings.Contains(out.String(), "source-file "+path) {
+		t.Errorf("output %q, want the source-file line", out.String())
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+	"github.com/spf13/cobra"
+)
+
+var toggleCmd = &cobra.Command{
+	Use:   "toggle",
+	Short: "Flip between the last two projects opened from this tmux client",
+	Long: `Open the project this tmux client had open before the current one, as Enter
+in the picker would, creating its session when it is gone. Run again, it flips
+back — like tmux's last-session, but it remembers projects rather than
+sessions, so a killed session comes back in the right directory.
+
+Each tmux client keeps its own pair, so two terminals attached to the same
+server toggle independently. Unlike pop recent, which goes by the shared
+history, only projects opened through pop on this client count.
+
+Example:
+  bind-key Tab run-shell 'pop toggle'`,
+	Args: cobra.NoArgs,
+	RunE: runToggle,
+}
+
+func init() {
+	rootCmd.AddCommand(toggleCmd)
+	toggleCmd.Flags().StringVar(&resultOutput, "output", "", "print the project opened to stdout: json")
+}
+
+func runToggle(cmd *cobra.Command, args []string) error {
+	if err := validateResultOutput(resultOutput); err != nil {
+		return err
+	}
+	d := DefaultProjectDeps()
+	d.Toggle = true
+	d.Output = resultOutput
+	// Nothing is shown, so don't spend the once-a-day notice or a tip.
+	d.UpdateNotice = nil
+	d.NextTip = nil
+	d.RunConfigure = func() error {
+		return fmt.Errorf("no config — run pop configure first")
+	}
+	return RunProject(d)
+}
+
+// togglePair is what pop toggle flips between for one tmux client: the paths
+// of the last two items it opened, Current the latest.
+type togglePair struct {
+	Current  string `json:"current"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// toggleStore persists each tmux client's togglePair, keyed by client tty, in
+// a small JSON file beside history. Like tips it is best-effort: a file that
+// cannot be read or written only costs the toggle its memory.
+type toggleStore struct {
+	FS   deps.FileSystem
+	Path string
+	// Lock takes an exclusive lock for the file at a path, as history does;
+	// nil locks nothing.
+	Lock func(path string) (func(), error)
+}
+
+// defaultToggleStore returns the toggle store in pop's data dir.
+func defaultToggleStore() *toggleStore {
+	return &toggleStore{
+		FS:   deps.NewRealFileSystem(),
+		Path: filepath.Join(filepath.Dir(history.DefaultHistoryPath()), "toggle.json"),
+		Lock: history.LockFile,
+	}
+}
+
+// load returns the stored pairs, empty on any error.
+func (s *toggleStore) load() map[string]togglePair {
+	pairs := map[string]togglePair{}
+	data, err := s.FS.ReadFile(s.Path)
+	if err != nil {
+		return pairs
+	}
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		debug.Error("toggle: unmarshal %s: %v", s.Path, err)
+		return map[string]togglePair{}
+	}
+	return pairs
+}
+
+// pair returns client's togglePair.
+func (s *toggleStore) pair(client string) togglePair {
+	return s.load()[client]
+}
+
+// record notes that client opened path: the item it had open becomes the
+// previous one. Opening the current item again changes nothing. The file is
+// re-read under the lock, so two clients recording at once both keep their
+// pair, and pairs of ttys that no longer exist are dropped.
+func (s *toggleStore) record(client, path string) {
+	if s.Lock != nil {
+		unlock, err := s.Lock(s.Path)
+		if err != nil {
+			debug.Error("toggle: %v", err)
+			return
+		}
+		defer unlock()
+	}
+
+	pairs := s.load()
+	p := pairs[client]
+	if p.Current == path {
+		return
+	}
+	for tty := range pairs {
+		if _, err := s.FS.Stat(tty); tty != client && os.IsNotExist(err) {
+			delete(pairs, tty)
+		}
+	}
+	pairs[client] = togglePair{Current: path, Previous: p.Current}
+
+	if err := s.save(pairs); err != nil {
+		debug.Error("toggle: save %s: %v", s.Path, err)
+	}
+}
+
+// save replaces the file with pairs through a temp file and a rename, so a
+// reader never sees it half-written.
+func (s *toggleStore) save(pairs map[string]togglePair) error {
+	data, err := json.MarshalIndent(pairs, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.Path)
+	if err := s.FS.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(dir, fmt.Sprintf(".%s.%d.tmp", filepath.Base(s.Path), os.Getpid()))
+	if err := s.FS.WriteFile(tmpPath, data, 0o644); err != nil {
+		_ = s.FS.RemoveAll(tmpPath)
+		return err
+	}
+	if err := s.FS.Rename(tmpPath, s.Path); err != nil {
+		_ = s.FS.RemoveAll(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// recordToggle notes item as opened by the current tmux client for pop
+// toggle. A nil store or client lookup records nothing.
+func recordToggle(d *ProjectDeps, item *ui.Item) {
+	if d.ToggleStore == nil || d.CurrentClient == nil {
+		return
+	}
+	if client := d.CurrentClient(d.Tmux); client != "" {
+		d.ToggleStore.record(client, item.Path)
+	}
+}
+
+// pickToggle stands in for the project picker for pop toggle: it confirms the
+// pair's previous item, or its current one when the client has since moved to
+// the previous item's session some other way. Items no longer listed, such
+// as a standalone session that was killed, are passed over.
+func pickToggle(pair togglePair, currentSession string, items []ui.Item) (ui.Result, error) {
+	for _, path := range []string{pair.Previous, pair.Current} {
+		if path == "" {
+			continue
+		}
+		for i := range items {
+			if items[i].Path == path && itemSessionName(items[i]) != currentSession {
+				return ui.Result{Action: ui.ActionConfirm, Selected: &items[i]}, nil
+			}
+		}
+	}
+	return ui.Result{}, errors.New("nothing to toggle to: open another project with pop from this tmux client first")
+}
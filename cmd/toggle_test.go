@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestToggleStoreRecord(t *testing.T) {
+	dir := t.TempDir()
+	s := &toggleStore{FS: deps.NewRealFileSystem(), Path: filepath.Join(dir, "pop", "toggle.json"), Lock: history.LockFile}
+	// Stand-ins for client ttys, which must exist to keep their pairs.
+	tty1, tty2 := filepath.Join(dir, "ttys001"), filepath.Join(dir, "ttys002")
+	for _, tty := range []string{tty1, tty2} {
+		if err := os.WriteFile(tty, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := s.pair(tty1); got != (togglePair{}) {
+		t.Fatalf("pair before any record = %+v, want zero", got)
+	}
+	s.record(tty1, "/src/api")
+	s.record(tty1, "/src/web")
+	s.record(tty1, "/src/web")
+	s.record(tty2, "/src/docs")
+
+	if got, want := s.pair(tty1), (togglePair{Current: "/src/web", Previous: "/src/api"}); got != want {
+		t.Errorf("client 1 pair = %+v, want %+v", got, want)
+	}
+	if got, want := s.pair(tty2), (togglePair{Current: "/src/docs"}); got != want {
+		t.Errorf("client 2 pair = %+v, want %+v", got, want)
+	}
+
+	// A closed terminal's tty goes away, and its pair with it.
+	if err := os.Remove(tty1); err != nil {
+		t.Fatal(err)
+	}
+	s.record(tty2, "/src/api")
+	if got := s.pair(tty1); got != (togglePair{}) {
+		t.Errorf("pair of a gone tty = %+v, want it dropped", got)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "pop", ".*.tmp")); len(matches) != 0 {
+		t.Errorf("temp files left behind: %v", matches)
+	}
+
+	if err := os.WriteFile(s.Path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.pair(tty2); got != (togglePair{}) {
+		t.Errorf("pair from a malformed file = %+v, want zero", got)
+	}
+}
+
+func TestPickToggle(t *testing.T) {
+	items := []ui.Item{
+		{Name: "api", Path: "/src/api", SessionName: "api"},
+		{Name: "web", Path: "/src/web", SessionName: "web"},
+	}
+	pair := togglePair{Current: "/src/web", Previous: "/src/api"}
+
+	tests := []struct {
+		name    string
+		pair    togglePair
+		current string
+		want    string
+		wantErr bool
+	}{
+		{name: "flips to the previous project", pair: pair, current: "web", want: "/src/api"},
+		{name: "back to the current one after moving away", pair: pair, current: "api", want: "/src/web"},
+		{name: "previous project no longer listed", pair: togglePair{Current: "/src/web", Previous: "/src/gone"}, current: "docs", want: "/src/web"},
+		{name: "nothing opened before", pair: togglePair{Current: "/src/web"}, current: "web", wantErr: true},
+		{name: "no pair", current: "web", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := pickToggle(tt.pair, tt.current, items)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("picked %+v, want an error", result.Selected)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Action != ui.ActionConfirm || result.Selected.Path != tt.want {
+				t.Errorf("picked %+v, want %s", result.Selected, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunProject_ToggleFlipsBetweenClientProjects(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := &toggleStore{FS: deps.NewRealFileSystem(), Path: filepath.Join(t.TempDir(), "toggle.json")}
+	session := ""
+	newDeps := func() *ProjectDeps {
+		d := testProjectDeps(t)
+		d.LoadConfig = func() (*config.Config, error) {
+			return &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(root, "*")}}}, nil
+		}
+		d.InTmux = func() bool { return true }
+		d.CurrentSession = func(deps.Tmux) string { return session }
+		d.CurrentClient = func(deps.Tmux) string { return "/dev/ttys001" }
+		d.ToggleStore = store
+		d.OpenSession = func(tmux deps.Tmux, item *ui.Item) error {
+			session = item.SessionName
+			return nil
+		}
+		return d
+	}
+	open := func(name string) {
+		t.Helper()
+		d := newDeps()
+		d.RunPicker = scriptedPicker(func(items []ui.Item) ui.Result {
+			for i := range items {
+				if items[i].Name == name {
+					return ui.Result{Action: ui.ActionConfirm, Selected: &items[i]}
+				}
+			}
+			t.Fatalf("no item %s", name)
+			return ui.Result{}
+		})
+		if err := RunProject(d); err != nil {
+			t.Fatalf("RunProject: %v", err)
+		}
+	}
+	toggle := func() string {
+		t.Helper()
+		d := newDeps()
+		d.RunPicker = func(items []ui.Item, opts ...ui.PickerOption) (ui.Result, error) {
+			t.Fatal("pop toggle showed the picker")
+			return ui.Result{}, nil
+		}
+		d.Toggle = true
+		if err := RunProject(d); err != nil {
+			t.Fatalf("RunProject: %v", err)
+		}
+		return session
+	}
+
+	open("api")
+	open("web")
+	if got := toggle(); got != "api" {
+		t.Errorf("first toggle opened %q, want api", got)
+	}
+	if got := toggle(); got != "web" {
+		t.Errorf("second toggle opened %q, want web", got)
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// windowTmux applies [project] restore_window around the tmux calls that
+// switch to a session: before switching it records the window and pane the
+// client is leaving (mode "last"), then selects the window the target session
+// should open on. Both happen before the switch, since switching from a popup
+// may close it and end pop.
+type windowTmux struct {
+	deps.Tmux
+	mode        string
+	loadHistory func() (*history.History, error)
+}
+
+// withWindowRestore wraps tmux in windowTmux when restore_window is set.
+func withWindowRestore(tmux deps.Tmux, mode string, loadHistory func() (*history.History, error)) deps.Tmux {
+	if mode == "" || loadHistory == nil {
+		return tmux
+	}
+	return windowTmux{Tmux: tmux, mode: mode, loadHistory: loadHistory}
+}
+
+func (t windowTmux) SwitchClient(name string) error {
+	t.recordCurrent()
+	t.restore(name)
+	return t.Tmux.SwitchClient(name)
+}
+
+func (t windowTmux) AttachSession(name string) error {
+	t.restore(name)
+	return t.Tmux.AttachSession(name)
+}
+
+func (t windowTmux) AttachSessionDetachOthers(name string) error {
+	t.restore(name)
+	return t.Tmux.AttachSessionDetachOthers(name)
+}
+
+func (t windowTmux) Command(args ...string) (string, error) {
+	if len(args) > 0 && args[0] == "switch-client" {
+		t.recordCurrent()
+		t.restore(flagValue(args, "-t"))
+	}
+	return t.Tmux.Command(args...)
+}
+
+// recordCurrent notes in history the window and pane active in the client's
+// current session, the one being switched away from.
+func (t windowTmux) recordCurrent() {
+	if t.mode != config.RestoreWindowLast {
+		return
+	}
+	out, err := t.Tmux.Command("display-message", "-p", "#{session_path}\t#{window_id}\t#{pane_id}")
+	if err != nil {
+		return // not attached to a client, nothing to leave
+	}
+	fields := strings.Split(out, "\t")
+	if len(fields) != 3 || fields[0] == "" {
+		return
+	}
+	hist, err := t.loadHistory()
+	if err != nil {
+		debug.Error("restore_window: load history: %v", err)
+		return
+	}
+	if err := hist.Update(func(h *history.History) { h.RecordWindow(fields[0], fields[1], fields[2]) }); err != nil {
+		debug.Error("restore_window: save history: %v", err)
+	}
+}
+
+// restore selects the window the session behind target opens on. A target
+// naming a pane or window (the dashboard's switch-and-zoom) already picks
+// one and is left alone.
+func (t windowTmux) restore(target string) {
+	if target == "" || strings.ContainsAny(target, "%@:") {
+		return
+	}
+	name := strings.TrimPrefix(target, "=")
+	if t.mode != config.RestoreWindowLast {
+		if _, err := t.Tmux.Command("select-window", "-t", "="+name+":"+t.mode); err != nil {
+			debug.Log("restore_window: no window %q in %s: %v", t.mode, name, err)
+		}
+		return
+	}
+
+	path, err := t.Tmux.Command("display-message", "-p", "-t", "="+name, "#{session_path}")
+	if err != nil {
+		return // a session about to be created has no window to restore
+	}
+	hist, err := t.loadHistory()
+	if err != nil {
+		return
+	}
+	for _, e := range hist.Entries {
+		if e.Path != path || e.Window == "" {
+			continue
+		}
+		// Window IDs are only unique within one tmux server; after a restart
+		// the recorded ID may name another session's window.
+		if owner, err := t.Tmux.Command("display-message", "-p", "-t", e.Window, "#{session_name}"); err != nil || owner != name {
+			return
+		}
+		if _, err := t.Tmux.Command("select-window", "-t", e.Window); err != nil {
+			return
+		}
+		if e.Pane != "" {
+			_, _ = t.Tmux.Command("select-pane", "-t", e.Pane)
+		}
+		return
+	}
+}
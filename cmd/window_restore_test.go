@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// windowMockTmux answers the display-message queries windowTmux makes: the
+// client is in /src/web on @1/%1, session api lives in /src/api and owns @7.
+func windowMockTmux(calls *[]string) *deps.MockTmux {
+	return &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			*calls = append(*calls, strings.Join(args, " "))
+			switch {
+			case args[0] != "display-message":
+				return "", nil
+			case len(args) == 3:
+				return "/src/web\t@1\t%1", nil
+			case args[3] == "=api":
+				return "/src/api", nil
+			case args[3] == "@7":
+				return "api", nil
+			case args[3] == "@9":
+				return "other", nil
+			}
+			return "", errors.New("can't find session")
+		},
+	}
+}
+
+func TestWindowTmux_LastRecordsAndRestores(t *testing.T) {
+	hist := &history.History{Entries: []history.Entry{
+		{Path: "/src/web"},
+		{Path: "/src/api", Window: "@7", Pane: "%12"},
+	}}
+	var calls []string
+	switched := ""
+	inner := windowMockTmux(&calls)
+	inner.SwitchClientFunc = func(name string) error {
+		switched = name
+		return nil
+	}
+	tmux := withWindowRestore(inner, "last", func() (*history.History, error) { return hist, nil })
+
+	if err := tmux.SwitchClient("api"); err != nil {
+		t.Fatal(err)
+	}
+	if switched != "api" {
+		t.Errorf("switched to %q, want api", switched)
+	}
+	if web := hist.Entries[0]; web.Window != "@1" || web.Pane != "%1" {
+		t.Errorf("left session recorded as %+v, want @1/%%1", web)
+	}
+	joined := strings.Join(calls, "\n")
+	for _, want := range []string{"select-window -t @7", "select-pane -t %12"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("calls missing %q:\n%s", want, joined)
+		}
+	}
+}
+
+func TestWindowTmux_LastSkipsWindowOfAnotherSession(t *testing.T) {
+	hist := &history.History{Entries: []history.Entry{{Path: "/src/api", Window: "@9", Pane: "%3"}}}
+	var calls []string
+	tmux := withWindowRestore(windowMockTmux(&calls), "last", func() (*history.History, error) { return hist, nil })
+
+	if err := tmux.AttachSession("api"); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range calls {
+		if strings.HasPrefix(c, "select-") {
+			t.Errorf("unexpected %q for a stale window ID", c)
+		}
+	}
+}
+
+func TestWindowTmux_NamedWindow(t *testing.T) {
+	var calls []string
+	tmux := withWindowRestore(windowMockTmux(&calls), "editor", func() (*history.History, error) { return &history.History{}, nil })
+
+	if _, err := tmux.Command("switch-client", "-t", "api"); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "select-window -t =api:editor" || calls[1] != "switch-client -t api" {
+		t.Errorf("calls = %q, want select-window on =api:editor before the switch", calls)
+	}
+
+	// A pane target already picks its window.
+	calls = nil
+	if _, err := tmux.Command("switch-client", "-t", "%4"); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("calls = %q, want only the switch", calls)
+	}
+}
+
+func TestWithWindowRestore_UnsetLeavesTmux(t *testing.T) {
+	inner := &deps.MockTmux{}
+	if got := withWindowRestore(inner, "", func() (*history.History, error) { return nil, nil }); got != deps.Tmux(inner) {
+		t.Errorf("withWindowRestore with no mode wrapped tmux: %T", got)
+	}
+}
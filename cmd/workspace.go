@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/history"
+	"github.com/glebglazov/pop/workspace"
+	"github.com/spf13/cobra"
+)
+
+// workspaceCmd is the `pop workspace` command group. Bare `pop workspace`
+// prints help.
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Save and restore project sessions across tmux restarts",
+}
+
+var workspaceSaveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Record the running project sessions and their windows",
+	Long: `Record which project sessions are running — with each window's name,
+directory, pane count and layout — into ~/.local/share/pop/workspace.json,
+replacing the previous save. Only sessions of configured projects and their
+worktrees are saved; standalone tmux sessions are left out.`,
+	Args: cobra.NoArgs,
+	RunE: runWorkspaceSave,
+}
+
+var workspaceRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Recreate the sessions recorded by pop workspace save",
+	Long: `Recreate every saved session that is not already running, with its windows,
+panes and layouts. Panes start fresh shells in the saved directories; running
+programs are not restored. Sessions whose project directory is gone are
+skipped.`,
+	Args: cobra.NoArgs,
+	RunE: runWorkspaceRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(workspaceSaveCmd)
+	workspaceCmd.AddCommand(workspaceRestoreCmd)
+}
+
+func runWorkspaceSave(cmd *cobra.Command, args []string) error {
+	return runWorkspaceSaveWith(DefaultProjectDeps(), workspace.DefaultDeps(), os.Stdout)
+}
+
+func runWorkspaceRestore(cmd *cobra.Command, args []string) error {
+	return runWorkspaceRestoreWith(workspace.DefaultDeps(), os.Stdout)
+}
+
+// runWorkspaceSaveWith saves the running sessions of the projects the picker
+// would list.
+func runWorkspaceSaveWith(pd *ProjectDeps, wd *workspace.Deps, w io.Writer) error {
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	cfg, err := pd.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	hist, err := pd.LoadHistory()
+	if err != nil {
+		hist = &history.History{}
+	}
+	projects, _, err := collectProjectsWith(pd, cfg, cfgPath, hist, nil)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]workspace.Target, len(projects))
+	for i, p := range projects {
+		targets[i] = workspace.Target{Name: p.SessionName, Path: p.Path}
+	}
+	ws, err := workspace.CaptureWith(wd, targets)
+	if err != nil {
+		return err
+	}
+	if err := workspace.SaveWith(wd, ws); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Saved %d session(s) to %s\n", len(ws.Sessions), wd.Path)
+	return nil
+}
+
+// runWorkspaceRestoreWith recreates the saved sessions and reports each one.
+func runWorkspaceRestoreWith(wd *workspace.Deps, w io.Writer) error {
+	ws, err := workspace.LoadWith(wd)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no saved workspace at %s — run pop workspace save first", wd.Path)
+		}
+		return err
+	}
+	outcomes, err := workspace.RestoreWith(wd, ws)
+	for _, o := range outcomes {
+		if o.Skipped != "" {
+			fmt.Fprintf(w, "Skipped %s (%s)\n", o.Name, o.Skipped)
+		} else {
+			fmt.Fprintf(w, "Restored %s\n", o.Name)
+		}
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/config"
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/workspace"
+)
+
+func TestRunWorkspaceSaveAndRestore(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	web := filepath.Join(root, "web")
+	for _, dir := range []string{api, web} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pd := testProjectDeps(t)
+	pd.LoadConfig = func() (*config.Config, error) {
+		return &config.Config{Projects: []config.ProjectEntry{{Path: filepath.Join(root, "*")}}}, nil
+	}
+
+	live := map[string]bool{"api": true, "scratch": true}
+	var created []string
+	wd := &workspace.Deps{
+		FS:   deps.NewRealFileSystem(),
+		Now:  time.Now,
+		Path: filepath.Join(t.TempDir(), "workspace.json"),
+		Tmux: &deps.MockTmux{
+			HasSessionFunc: func(name string) bool { return live[name] },
+			CommandFunc: func(args ...string) (string, error) {
+				switch args[0] {
+				case "list-windows":
+					return "1\tc0d1,80x24,0,0,1\t" + api + "\tedit", nil
+				case "new-session":
+					created = append(created, args[3])
+				}
+				return "@1", nil
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := runWorkspaceSaveWith(pd, wd, &out); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if !strings.Contains(out.String(), "Saved 1 session(s)") {
+		t.Errorf("save output = %q, want only the api project session saved", out.String())
+	}
+
+	// After a reboot nothing is running.
+	live = map[string]bool{}
+	out.Reset()
+	if err := runWorkspaceRestoreWith(wd, &out); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if len(created) != 1 || created[0] != "api" {
+		t.Errorf("created sessions = %v, want [api]", created)
+	}
+	if !strings.Contains(out.String(), "Restored api") {
+		t.Errorf("restore output = %q", out.String())
+	}
+}
+
+func TestRunWorkspaceRestore_NothingSaved(t *testing.T) {
+	wd := &workspace.Deps{FS: deps.NewRealFileSystem(), Path: filepath.Join(t.TempDir(), "workspace.json")}
+	err := runWorkspaceRestoreWith(wd, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "pop workspace save") {
+		t.Errorf("error = %v, want a hint to run pop workspace save", err)
+	}
+}
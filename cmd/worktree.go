@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/glebglazov/pop/config"
 	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/events"
 	"github.com/glebglazov/pop/history"
 	"github.com/glebglazov/pop/internal/deps"
 	"github.com/glebglazov/pop/project"
@@ -37,6 +41,7 @@ Keybindings:
   enter    - switch to worktree (prints path or switches tmux session)
   ctrl-d   - delete worktree
   ctrl-x   - force delete worktree
+  ctrl-v   - toggle the branch filter (--branch-filter or [worktree] branch_filter)
   esc      - cancel
 
 Example tmux binding:
@@ -45,16 +50,40 @@ Example tmux binding:
 }
 
 var switchSession bool
+
+// worktreeOpenAsWindow is [worktree] open_as = "window": --switch opens a
+// worktree as a window in the repository's session instead of a session.
+var worktreeOpenAsWindow bool
 var worktreeYankTarget string
+var worktreePreviewCmd string
+var worktreeDetachOthers bool
+var worktreeBranchFilter []string
 
 func init() {
 	worktreeCmd.PersistentFlags().BoolVarP(&switchSession, "switch", "s", false, "Switch tmux session instead of printing path")
 	worktreeCmd.PersistentFlags().StringVar(&worktreeYankTarget, "yank-target", "", "Send yanked path to specified tmux pane instead of system clipboard")
+	worktreeCmd.PersistentFlags().StringVar(&worktreePreviewCmd, "preview-cmd", "", "Shell command rendered in the preview pane ({path}, {name}, {session} placeholders)")
+	worktreeCmd.PersistentFlags().StringArrayVar(&worktreeBranchFilter, "branch-filter", nil, "Branch glob the picker's branch filter keeps (repeatable, a leading ! hides matches; overrides [worktree] branch_filter)")
+	worktreeCmd.PersistentFlags().BoolVar(&worktreeDetachOthers, "detach-others", false, "When attaching from outside tmux, detach the session's other clients (attach -d)")
+	worktreeCmd.PersistentFlags().StringVar(&resultOutput, "output", "", "print the final picker result to stdout instead of the path: json")
 	worktreeCmd.AddCommand(worktreeDashboardCmd)
 	rootCmd.AddCommand(worktreeCmd)
 }
 
-func runWorktree(cmd *cobra.Command, args []string) error {
+func runWorktree(cmd *cobra.Command, args []string) (err error) {
+	if err := validateResultOutput(resultOutput); err != nil {
+		return err
+	}
+	// final is the picker Result that ended the run, for --output json.
+	var final *ui.Result
+	if resultOutput == outputJSON {
+		defer func() {
+			if err == nil && final != nil {
+				err = writeResultJSON(os.Stdout, newResultJSON(*final, worktreeItemSessionName))
+			}
+		}()
+	}
+
 	systemWarnings := ensureSystemState()
 
 	// Detect repo context
@@ -67,21 +96,47 @@ func runWorktree(cmd *cobra.Command, args []string) error {
 	var customCommands []ui.UserDefinedCommand
 	var configWarnings []string
 	quickAccessModifier := "alt"
+	var keyBindings map[string]string
+	displayOpts := pickerDisplayOptions(&config.Config{}, "worktree", true)
 	attentionEnabled := false
 	updateNoticeEnabled := true
+	previewCommand := worktreePreviewCmd
+	matcherCommand := ""
+	tipsEnabled := true
+	detachOthers := worktreeDetachOthers
+	popupSwitch := "close_popup"
+	var onSelect []string
+	var hookCfg *config.Config
+	branchFilter := worktreeBranchFilter
 	if cfg, err := config.Load(config.DefaultConfigPath()); err == nil {
-		quickAccessModifier = cfg.GetQuickAccessModifier()
+		hookCfg = cfg
+		quickAccessModifier = cfg.QuickAccessModifierForMode("worktree")
+		keyBindings = cfg.KeyBindingsForPicker()
+		displayOpts = append(pickerDisplayOptions(cfg, "worktree", true), ui.WithTheme(pickerTheme(cfg)))
+		if cfg.GitStatus {
+			displayOpts = append(displayOpts, ui.WithItemStatus(gitItemStatus(project.DefaultDeps())))
+		}
+		if cfg.GetAttachBehavior() == "detach_others" {
+			detachOthers = true
+		}
+		popupSwitch = cfg.GetPopupSwitch()
+		if len(branchFilter) == 0 {
+			branchFilter = cfg.WorktreeBranchFilter()
+		}
+		worktreeOpenAsWindow = cfg.WorktreeOpenAs() == config.WorktreeOpenAsWindow
+		matcherCommand = cfg.MatcherCommand
+		if previewCommand == "" {
+			previewCommand = cfg.PreviewCommandForMode("worktree")
+		}
+		// Before the warnings: reading the script commands records theirs.
+		customCommands = pickerCommands(cfg, "worktree")
 		configWarnings = cfg.Warnings
+		// An invalid on_select is already in configWarnings; Enter then keeps
+		// its built-in behavior.
+		onSelect, _ = cfg.OnSelectForMode("worktree")
 		attentionEnabled = cfg.UnreadNotificationsEnabled("worktree")
 		updateNoticeEnabled = cfg.UpdateNoticeEnabled()
-		for _, cc := range cfg.CommandsForMode("worktree") {
-			customCommands = append(customCommands, ui.UserDefinedCommand{
-				Key:     cc.Key,
-				Label:   cc.Label,
-				Command: cc.Command,
-				Exit:    cc.Exit,
-			})
-		}
+		tipsEnabled = cfg.TipsEnabled()
 		// Surface non-fatal .pop.toml scope-legality findings (ADR-0083): a
 		// global/machine-only or [repo]-only key committed to .pop.toml is ignored
 		// but warned about here. The error is deliberately dropped — findings are
@@ -93,14 +148,44 @@ func runWorktree(cmd *cobra.Command, args []string) error {
 		}
 	}
 	configWarnings = append(configWarnings, systemWarnings...)
+	if detachOthers {
+		// Every worktree attach path goes through defaultTmux.
+		defaultTmux = detachOthersTmux{defaultTmux}
+	}
+	defaultTmux = withPopupSwitch(defaultTmux, popupSwitch, inTmuxPopup())
+	// Outermost, so on_switch runs before a popup switch can close pop.
+	hooks := &sessionHooks{cfg: hookCfg, Run: runPipelineCommand}
+	defaultTmux = withSessionHooks(defaultTmux, hooks)
+	if hookCfg != nil {
+		defaultTmux = withFetchOnOpen(defaultTmux, newProjectFetcher(hookCfg, startBackgroundFetch))
+	}
+	preview := previewFunc(previewCommand, runPreviewCommand)
+	matcher := matcherFunc(matcherCommand, runMatcherCommand)
+	var tip string
+	if tipsEnabled {
+		tip = pickerTip("worktree", quickAccessModifier, keyBindings, os.Getenv("TMUX") != "")
+	}
 
 	restoreCursorIdx := -1
+	sortMode := worktreeSortRecent
+	branchFilterOn := len(branchFilter) > 0
 	for {
-		result, err := showWorktreePicker(ctx, customCommands, quickAccessModifier, restoreCursorIdx, configWarnings, attentionEnabled, updateNoticeEnabled)
+		opts := displayOpts
+		if len(branchFilter) > 0 {
+			opts = append(slices.Clone(displayOpts), ui.WithBranchFilter(func(item ui.Item) bool {
+				return branchFilterKeeps(branchFilter, item.Context)
+			}, branchFilterOn))
+		}
+		result, err := showWorktreePicker(ctx, customCommands, quickAccessModifier, keyBindings, opts, restoreCursorIdx, configWarnings, attentionEnabled, updateNoticeEnabled, preview, matcher, tip, sortMode)
 		restoreCursorIdx = -1
 		if err != nil {
 			return err
 		}
+		if result.SortMode != "" {
+			sortMode = result.SortMode
+		}
+		branchFilterOn = result.BranchFilter
+		final = &result
 
 		switch result.Action {
 		case ui.ActionCancel:
@@ -110,6 +195,10 @@ func runWorktree(cmd *cobra.Command, args []string) error {
 			if result.Selected == nil {
 				return nil
 			}
+			hooks.fire(config.HookOnSelect, project.SessionName(result.Selected.Path), result.Selected.Path)
+			if onSelect != nil {
+				return runSelectPipeline(worktreeSelectPipeline(defaultWorktreeShapeDeps(), ctx), onSelect, result.Selected)
+			}
 			// Selecting an existing worktree gets the same birth-time shaping
 			// as the create/project paths, gated on session-absence (ADR-0075):
 			// no live session → Preferred auto-applies / pick_on_create prompts /
@@ -156,9 +245,9 @@ func runWorktree(cmd *cobra.Command, args []string) error {
 			// Continue loop to show picker again
 
 		case ui.ActionCreateWorktree:
-			if err := createWorktree(ctx); err != nil {
+			if err := createWorktree(ctx, hooks); err != nil {
 				debug.Error("worktree: create: %v", err)
-				fmt.Fprintf(os.Stderr, "Failed to create worktree: %v\n", err)
+				ui.Notify(ui.LevelError, "Failed to create worktree: %v", err)
 				// Continue loop to show picker again
 				continue
 			}
@@ -178,7 +267,16 @@ func runWorktree(cmd *cobra.Command, args []string) error {
 			return yankPathToPaneWith(defaultTmux, paneID, result.Selected.Path)
 
 		case ui.ActionUserDefinedCommand:
-			if result.UserDefinedCommand != nil && result.Selected != nil {
+			if result.UserDefinedCommand != nil && result.UserDefinedCommand.Multi && len(result.Marked) > 0 {
+				metadata := make([]itemMetadata, len(result.Marked))
+				for i := range result.Marked {
+					metadata[i] = worktreeItemMetadata(&result.Marked[i], ctx)
+				}
+				executeMultiCustomCommand(result.UserDefinedCommand.Command, result.Marked, "POP_REPO_ROOT="+ctx.GitRoot, itemsJSONEnv(metadata))
+				if result.UserDefinedCommand.Exit {
+					return nil
+				}
+			} else if result.UserDefinedCommand != nil && result.Selected != nil {
 				executeCustomCommand(result.UserDefinedCommand.Command, result.Selected, ctx)
 				if result.UserDefinedCommand.Exit {
 					return nil
@@ -189,7 +287,7 @@ func runWorktree(cmd *cobra.Command, args []string) error {
 	}
 }
 
-func showWorktreePicker(ctx *project.RepoContext, customCommands []ui.UserDefinedCommand, quickAccessModifier string, initialCursorIdx int, warnings []string, attentionEnabled, updateNoticeEnabled bool) (ui.Result, error) {
+func showWorktreePicker(ctx *project.RepoContext, customCommands []ui.UserDefinedCommand, quickAccessModifier string, keyBindings map[string]string, displayOpts []ui.PickerOption, initialCursorIdx int, warnings []string, attentionEnabled, updateNoticeEnabled bool, preview ui.PreviewFunc, matcher ui.MatchFunc, tip, sortMode string) (ui.Result, error) {
 	worktrees, err := project.ListWorktrees(ctx)
 	if err != nil {
 		return ui.Result{Action: ui.ActionCancel}, fmt.Errorf("failed to list worktrees: %w", err)
@@ -241,23 +339,12 @@ func showWorktreePicker(ctx *project.RepoContext, customCommands []ui.UserDefine
 			}
 		}
 	}
-	opts := []ui.PickerOption{
-		ui.WithDelete(),
-		ui.WithContext(),
-		ui.WithCursorAtEnd(),
-		ui.WithKillSession(),
-		ui.WithReset(),
-		ui.WithCreateWorktree(),
-		ui.WithSetPreferredWorkbench(),
-		ui.WithQuickAccess(quickAccessModifier),
+	opts := append(append(slices.Clone(displayOpts),
 		ui.WithIconLegend(iconLegends...),
-	}
+	), worktreePickerKeys(quickAccessModifier, keyBindings, customCommands)...)
 	if initialCursorIdx >= 0 {
 		opts = append(opts, ui.WithInitialCursorIndex(initialCursorIdx))
 	}
-	if len(customCommands) > 0 {
-		opts = append(opts, ui.WithUserDefinedCommands(customCommands))
-	}
 	if len(warnings) > 0 {
 		opts = append(opts, ui.WithWarnings(warnings))
 	}
@@ -268,10 +355,73 @@ func showWorktreePicker(ctx *project.RepoContext, customCommands []ui.UserDefine
 			opts = append(opts, ui.WithUpdateNotice(notice))
 		}
 	}
+	if preview != nil {
+		opts = append(opts, ui.WithPreview(preview))
+	}
+	if matcher != nil {
+		opts = append(opts, ui.WithMatcher(matcher))
+	}
+	if tip != "" {
+		opts = append(opts, ui.WithTip(tip))
+	}
+	// Branch activity is the alternative order for spotting stale branches;
+	// when git can't report it the sort key stays off.
+	if times, err := project.BranchCommitTimes(ctx); err != nil {
+		debug.Error("worktree: branch commit times: %v", err)
+	} else {
+		byActivity := project.SortWorktreesByBranchActivity(sortedWorktrees, times)
+		opts = append(opts, ui.WithSortModes(sortMode,
+			ui.SortMode{Name: worktreeSortRecent, Items: items},
+			ui.SortMode{Name: worktreeSortActivity, Items: itemsInWorktreeOrder(items, byActivity)},
+		))
+	}
 
 	return ui.Run(items, opts...)
 }
 
+// branchFilterKeeps reports whether the branch filter keeps a worktree on
+// branch. Patterns are path.Match globs, so "feature/*" matches one level of
+// feature branches; a pattern starting with ! hides the branches it matches.
+// With only ! patterns every other branch is kept.
+func branchFilterKeeps(patterns []string, branch string) bool {
+	keep, positive := false, false
+	for _, pattern := range patterns {
+		if exclude, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matched, _ := path.Match(exclude, branch); matched {
+				return false
+			}
+			continue
+		}
+		positive = true
+		if matched, _ := path.Match(pattern, branch); matched {
+			keep = true
+		}
+	}
+	return keep || !positive
+}
+
+// Worktree picker sort modes, cycled with the sort key.
+const (
+	worktreeSortRecent   = "recent"
+	worktreeSortActivity = "branch activity"
+)
+
+// itemsInWorktreeOrder returns items rearranged to follow worktrees, matched
+// by path.
+func itemsInWorktreeOrder(items []ui.Item, worktrees []project.Worktree) []ui.Item {
+	byPath := make(map[string]ui.Item, len(items))
+	for _, item := range items {
+		byPath[item.Path] = item
+	}
+	ordered := make([]ui.Item, 0, len(items))
+	for _, wt := range worktrees {
+		if item, ok := byPath[wt.Path]; ok {
+			ordered = append(ordered, item)
+		}
+	}
+	return ordered
+}
+
 func buildWorktreeItems(ctx *project.RepoContext, worktrees []project.Worktree, sessionActivity map[string]int64) []ui.Item {
 	items := make([]ui.Item, len(worktrees))
 	for i, wt := range worktrees {
@@ -283,6 +433,7 @@ func buildWorktreeItems(ctx *project.RepoContext, worktrees []project.Worktree,
 		sessionName := project.TmuxSessionName(ctx, wt.Name)
 		if _, hasSession := sessionActivity[sessionName]; hasSession {
 			items[i].Icon = iconDirSession
+			items[i].HasSession = true
 		}
 	}
 	return items
@@ -290,8 +441,9 @@ func buildWorktreeItems(ctx *project.RepoContext, worktrees []project.Worktree,
 
 // createWorktree runs the interactive create flow (ADR-0076): pick a branch,
 // derive the worktree name/path, run `git worktree add`, record the new checkout
-// in history, and attach a flat session for it immediately.
-func createWorktree(ctx *project.RepoContext) error {
+// in history, and attach a flat session for it immediately. hooks emits the
+// worktree_created event once the checkout exists.
+func createWorktree(ctx *project.RepoContext, hooks *sessionHooks) error {
 	branches, err := project.ListBranches(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
@@ -311,7 +463,7 @@ func createWorktree(ctx *project.RepoContext) error {
 		byRef[b.Ref] = b
 	}
 
-	result, err := ui.Run(items,
+	result, err := runThemedPicker(items,
 		ui.WithHeader("Pick a branch for the new worktree"),
 		ui.WithCursorAtEnd())
 	if err != nil {
@@ -341,6 +493,7 @@ func createWorktree(ctx *project.RepoContext) error {
 	if err != nil {
 		return err
 	}
+	hooks.emit(events.WorktreeCreated, project.SessionName(path), path)
 
 	// Shape the new checkout's session: a Workbench when [workbench]
 	// pick_on_create is on and one resolves (ADR-0075/0076), else today's flat
@@ -367,6 +520,13 @@ type worktreeShapeDeps struct {
 	RecordHistory             func(path string)
 	Attach                    func(sessionName string) error
 	Flat                      func(ctx *project.RepoContext, item *ui.Item) error
+	// OpenAsWindow is set when the worktree opens as a window of the
+	// repository's session, which has no session of its own to shape.
+	OpenAsWindow bool
+	// EnsureFlat and RunCommand back the ensure_session and run: steps of an
+	// on_select pipeline.
+	EnsureFlat func(sessionName, path string) error
+	RunCommand func(command string, ctx *project.RepoContext, item *ui.Item) error
 }
 
 // defaultWorktreeShapeDeps wires worktreeShapeDeps to production implementations,
@@ -391,7 +551,7 @@ func defaultWorktreeShapeDeps() *worktreeShapeDeps {
 			return cfg.ResolvePreferredWorkbench(preferredResolverConfigDeps(cfg), path)
 		},
 		PromptWorkbench: func(order []string, workbenches []config.Workbench) (string, bool, error) {
-			return promptWorkbenchForCreate(&ProjectDeps{RunPicker: ui.Run}, order, workbenches)
+			return promptWorkbenchForCreate(&ProjectDeps{RunPicker: runThemedPicker}, order, workbenches)
 		},
 		FindWorkbench: findWorkbench,
 		CreateSession: func(tmpl config.Workbench, sessionName, path string) error {
@@ -399,22 +559,74 @@ func defaultWorktreeShapeDeps() *worktreeShapeDeps {
 		},
 		SessionName:   project.SessionName,
 		SessionExists: func(sessionName string) bool { return defaultTmux.HasSession(sessionName) },
+		OpenAsWindow:  switchSession && worktreeOpenAsWindow,
 		RecordHistory: recordWorktreeHistory,
 		Attach:        func(sessionName string) error { return switchToTmuxTargetWith(defaultTmux, sessionName) },
 		Flat:          handleWorktreeSelect,
+		EnsureFlat: func(sessionName, path string) error {
+			return session.EnsureWith(sessionDeps(defaultTmux), sessionName, path)
+		},
+		RunCommand: runWorktreePipelineCommand,
+	}
+}
+
+// worktreeSelectPipeline wires the on_select steps for the worktree picker.
+// Like the project picker's, ensure_session auto-applies a resolved preferred
+// Workbench to a new session but never prompts.
+func worktreeSelectPipeline(d *worktreeShapeDeps, ctx *project.RepoContext) *selectPipelineDeps {
+	return &selectPipelineDeps{
+		RecordHistory: d.RecordHistory,
+		EnsureSession: func(item *ui.Item) error {
+			sessionName := d.SessionName(item.Path)
+			if d.SessionExists(sessionName) {
+				return nil
+			}
+			if cfg, err := d.LoadConfig(); err == nil {
+				preferred, warns := d.ResolvePreferredWorkbench(cfg, item.Path)
+				for _, w := range warns {
+					debug.Error("worktree: %s", w)
+				}
+				if preferred != "" {
+					if tmpl, ok := d.FindWorkbench(d.ResolveWorkbenches(cfg, item.Path), preferred); ok {
+						return d.CreateSession(tmpl, sessionName, item.Path)
+					}
+				}
+			}
+			return d.EnsureFlat(sessionName, item.Path)
+		},
+		Switch: func(item *ui.Item) error {
+			return d.Attach(d.SessionName(item.Path))
+		},
+		Run: func(command string, item *ui.Item) error {
+			return d.RunCommand(command, ctx, item)
+		},
 	}
 }
 
+// runWorktreePipelineCommand runs an on_select run: step in the worktree with
+// the same POP_* variables as worktree custom commands.
+func runWorktreePipelineCommand(command string, ctx *project.RepoContext, item *ui.Item) error {
+	return runPipelineCommand(command, item.Path,
+		"POP_PATH="+item.Path,
+		"POP_NAME="+filepath.Base(item.Path),
+		"POP_WORKTREE_PATH="+item.Path,
+		"POP_WORKTREE_NAME="+filepath.Base(item.Path),
+		"POP_BRANCH="+item.Context,
+		"POP_REPO_ROOT="+ctx.GitRoot,
+	)
+}
+
 // openWorktreeWithShaping opens path's checkout with birth-time Workbench
 // shaping, gated on session-absence (ADR-0075). When path's session already
 // exists it attaches flat with no reshaping of the built session; when the
 // session is absent it runs the same shaping the create flow uses — a resolved
 // Preferred workbench auto-applies silently, else pick_on_create prompts, else
-// it falls through to today's flat attach. This is the single shared entry
+// it falls through to today's flat attach. A worktree opening as a window
+// always goes the flat way. This is the single shared entry
 // point for the worktree-picker select path and the native create flow (and,
 // later, the queue-dashboard open).
 func openWorktreeWithShaping(d *worktreeShapeDeps, ctx *project.RepoContext, path string) error {
-	if d.SessionExists(d.SessionName(path)) {
+	if d.OpenAsWindow || d.SessionExists(d.SessionName(path)) {
 		// Session already built (ADR-0075): attach flat, never reshape.
 		return d.Flat(ctx, &ui.Item{Name: filepath.Base(path), Path: path})
 	}
@@ -483,10 +695,15 @@ func handleWorktreeSelect(ctx *project.RepoContext, item *ui.Item) error {
 	recordWorktreeHistory(item.Path)
 
 	if switchSession {
+		if worktreeOpenAsWindow {
+			return switchTmuxWindow(ctx, item)
+		}
 		return switchTmuxSession(item)
 	}
-	// Print path for shell integration
-	fmt.Println(item.Path)
+	// Print path for shell integration, unless --output json carries it.
+	if resultOutput != outputJSON {
+		fmt.Println(item.Path)
+	}
 	return nil
 }
 
@@ -498,8 +715,7 @@ func recordWorktreeHistory(path string) {
 	if err != nil {
 		debug.Error("worktree: load history: %v", err)
 	}
-	hist.Record(path)
-	if err := hist.Save(); err != nil {
+	if err := hist.Update(func(h *history.History) { h.Record(path) }); err != nil {
 		debug.Error("worktree: save history: %v", err)
 	}
 }
@@ -515,6 +731,19 @@ func switchTmuxSessionWith(tmux deps.Tmux, item *ui.Item) error {
 	}, project.SessionName(item.Path), item.Path)
 }
 
+// switchTmuxWindow opens item as a window named after the worktree in the
+// repository's session, for [worktree] open_as = "window".
+func switchTmuxWindow(ctx *project.RepoContext, item *ui.Item) error {
+	return switchTmuxWindowWith(defaultTmux, project.RepoSessionName(ctx), item)
+}
+
+func switchTmuxWindowWith(tmux deps.Tmux, repoSession string, item *ui.Item) error {
+	return session.AttachWindowWith(&session.Deps{
+		Tmux:   tmux,
+		InTmux: func() bool { return os.Getenv("TMUX") != "" },
+	}, repoSession, project.SafeSessionName(filepath.Base(item.Path)), item.Path)
+}
+
 func deleteWorktree(path string, force bool) {
 	args := []string{"worktree", "remove"}
 	if force {
@@ -527,10 +756,10 @@ func deleteWorktree(path string, force bool) {
 
 	if err != nil {
 		debug.Error("deleteWorktree %s: %v: %s", path, err, output)
-		fmt.Fprintf(os.Stderr, "Failed to delete worktree: %s\n%s\n", path, output)
+		ui.Notify(ui.LevelError, "Failed to delete worktree: %s: %s", path, strings.TrimSpace(string(output)))
 		return
 	}
-	fmt.Fprintf(os.Stderr, "Deleted: %s\n", path)
+	ui.Notify(ui.LevelInfo, "Deleted: %s", path)
 	// Worktree is gone — drop its history entry so it no longer skews
 	// recency sorting or session-name matching. The tmux session (if any)
 	// is left alone; killing it stays an explicit, separate action.
@@ -566,8 +795,7 @@ func removeFromHistoryWith(d *history.Deps, histPath, path string) {
 		debug.Error("worktree: load history: %v", err)
 		return
 	}
-	hist.RemoveWith(d, path)
-	if err := hist.SaveWith(d); err != nil {
+	if err := hist.UpdateWith(d, func(h *history.History) { h.RemoveWith(d, path) }); err != nil {
 		debug.Error("worktree: save history: %v", err)
 	}
 }
@@ -583,6 +811,7 @@ func executeCustomCommand(command string, item *ui.Item, ctx *project.RepoContex
 		"POP_WORKTREE_NAME="+filepath.Base(item.Path),
 		"POP_BRANCH="+item.Context,
 		"POP_REPO_ROOT="+ctx.GitRoot,
+		itemJSONEnv(worktreeItemMetadata(item, ctx)),
 	)
 
 	cmd.Stdout = os.Stdout
@@ -591,6 +820,6 @@ func executeCustomCommand(command string, item *ui.Item, ctx *project.RepoContex
 
 	if err := cmd.Run(); err != nil {
 		debug.Error("worktree: custom command %q: %v", command, err)
-		fmt.Fprintf(os.Stderr, "Custom command failed: %v\n", err)
+		ui.Notify(ui.LevelError, "Custom command failed: %v", err)
 	}
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+// countBrokenWorktrees returns how many of projects are worktrees with a stale
+// .git pointer.
+func countBrokenWorktrees(projects map[string]project.ExpandedProject) int {
+	n := 0
+	for _, ep := range projects {
+		if ep.Broken {
+			n++
+		}
+	}
+	return n
+}
+
+// repairBrokenWorktrees runs git worktree repair for each broken worktree
+// among the items result acts on, marking it repaired in projects. It stops
+// at the first worktree git cannot relink.
+func repairBrokenWorktrees(d *project.Deps, projects map[string]project.ExpandedProject, result ui.Result) error {
+	targets := result.Marked
+	if len(targets) == 0 && result.Selected != nil {
+		targets = []ui.Item{*result.Selected}
+	}
+	for _, item := range targets {
+		ep, ok := projects[item.Path]
+		if !ok || !ep.Broken {
+			continue
+		}
+		if err := project.RepairWorktreesWith(d, ep.RepoRoot, ep.Path); err != nil {
+			return fmt.Errorf("repair %s: %w", item.Name, err)
+		}
+		ep.Broken = false
+		projects[item.Path] = ep
+		ui.Notify(ui.LevelInfo, "Repaired worktree: %s", item.Name)
+	}
+	return nil
+}
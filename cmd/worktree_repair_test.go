@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+	"github.com/glebglazov/pop/ui"
+)
+
+func TestRepairBrokenWorktrees(t *testing.T) {
+	projects := map[string]project.ExpandedProject{
+		"/src/repo/main": {Path: "/src/repo/main", RepoRoot: "/src/repo", IsWorktree: true, Broken: true},
+		"/src/api":       {Path: "/src/api"},
+	}
+	var repaired []string
+	d := &project.Deps{Git: &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			repaired = append(repaired, dir+" "+args[len(args)-1])
+			return "", nil
+		},
+	}}
+
+	if err := repairBrokenWorktrees(d, projects, ui.Result{Selected: &ui.Item{Name: "api", Path: "/src/api"}}); err != nil {
+		t.Fatalf("repairBrokenWorktrees(api): %v", err)
+	}
+	if len(repaired) != 0 {
+		t.Errorf("repaired %q for a healthy project", repaired)
+	}
+
+	if err := repairBrokenWorktrees(d, projects, ui.Result{Selected: &ui.Item{Name: "repo/main", Path: "/src/repo/main"}}); err != nil {
+		t.Fatalf("repairBrokenWorktrees(repo/main): %v", err)
+	}
+	if want := []string{"/src/repo /src/repo/main"}; !equalStrings(repaired, want) {
+		t.Errorf("repaired %q, want %q", repaired, want)
+	}
+	if projects["/src/repo/main"].Broken {
+		t.Error("repaired worktree still marked broken")
+	}
+}
+
+func TestRepairBrokenWorktreesFailure(t *testing.T) {
+	projects := map[string]project.ExpandedProject{
+		"/src/repo/main": {Path: "/src/repo/main", RepoRoot: "/src/repo", IsWorktree: true, Broken: true},
+	}
+	d := &project.Deps{Git: &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			return "", errors.New("fatal: not a valid path")
+		},
+	}}
+
+	err := repairBrokenWorktrees(d, projects, ui.Result{Selected: &ui.Item{Name: "repo/main", Path: "/src/repo/main"}})
+	if err == nil {
+		t.Fatal("repairBrokenWorktrees() error = nil, want the repair failure")
+	}
+	if !projects["/src/repo/main"].Broken {
+		t.Error("worktree marked repaired although git failed")
+	}
+}
+
+func TestProjectBaseItemsMarksBrokenWorktrees(t *testing.T) {
+	items := projectBaseItems([]project.ExpandedProject{
+		{Name: "repo/main", Path: "/src/repo/main", Broken: true},
+		{Name: "api", Path: "/src/api", Icon: "★"},
+	}, "")
+	if items[0].Icon != iconBrokenWorktree {
+		t.Errorf("broken worktree icon = %q, want %q", items[0].Icon, iconBrokenWorktree)
+	}
+	if items[1].Icon != "★" {
+		t.Errorf("healthy project icon = %q, want its configured icon", items[1].Icon)
+	}
+}
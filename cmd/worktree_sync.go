@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/glebglazov/pop/project"
+	"github.com/spf13/cobra"
+)
+
+var worktreeSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fast-forward every clean worktree of the current repository",
+	Long: `Fetch the current repository once, then fast-forward each of its worktrees
+to its upstream branch and print a summary table.
+
+A worktree is only touched when it is on a branch, has no uncommitted changes
+to tracked files, and tracks an upstream; the others are listed as skipped.
+Nothing is ever merged: a branch that has diverged from its upstream is
+reported as failed and left as it was. pop worktree sync exits non-zero when
+any worktree failed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := project.DetectRepoContext()
+		if err != nil {
+			return fmt.Errorf("not in a git repository")
+		}
+		return runWorktreeSyncWith(project.DefaultDeps(), ctx, os.Stdout)
+	},
+}
+
+func init() {
+	worktreeCmd.AddCommand(worktreeSyncCmd)
+}
+
+// runWorktreeSyncWith syncs the worktrees of the repo in ctx and prints one
+// row per worktree to w, followed by a count per outcome.
+func runWorktreeSyncWith(d *project.Deps, ctx *project.RepoContext, w io.Writer) error {
+	results, err := project.SyncWorktreesWith(d, ctx)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No worktrees")
+		return nil
+	}
+
+	counts := make(map[project.SyncStatus]int)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORKTREE\tBRANCH\tSTATUS\tDETAIL")
+	for _, r := range results {
+		counts[r.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Worktree.Name, r.Worktree.Branch, r.Status, r.Detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%d updated, %d up to date, %d skipped, %d failed\n",
+		counts[project.SyncUpdated], counts[project.SyncUpToDate], counts[project.SyncSkipped], counts[project.SyncFailed])
+
+	if n := counts[project.SyncFailed]; n > 0 {
+		return fmt.Errorf("%d worktree(s) could not be fast-forwarded", n)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/project"
+)
+
+func TestRunWorktreeSyncWith(t *testing.T) {
+	diverged := false
+	d := &project.Deps{Git: &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			switch args[0] {
+			case "worktree":
+				return "worktree /repo/main\nbranch refs/heads/main\n\nworktree /repo/feature\nbranch refs/heads/feature\n", nil
+			case "rev-parse":
+				return "origin/" + strings.TrimPrefix(dir, "/repo/"), nil
+			case "rev-list":
+				if dir == "/repo/main" {
+					return "2", nil
+				}
+				return "0", nil
+			case "merge":
+				if diverged {
+					return "", fmt.Errorf("Not possible to fast-forward")
+				}
+			}
+			return "", nil
+		},
+	}}
+	ctx := &project.RepoContext{GitRoot: "/repo", RepoName: "repo"}
+
+	var buf bytes.Buffer
+	if err := runWorktreeSyncWith(d, ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"WORKTREE", "main", "2 commits from origin/main", "up to date", "1 updated, 1 up to date, 0 skipped, 0 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	diverged = true
+	buf.Reset()
+	if err := runWorktreeSyncWith(d, ctx, &buf); err == nil {
+		t.Error("expected an error when a worktree cannot be fast-forwarded")
+	}
+	if !strings.Contains(buf.String(), "0 updated, 1 up to date, 0 skipped, 1 failed") {
+		t.Errorf("summary missing from output:\n%s", buf.String())
+	}
+}
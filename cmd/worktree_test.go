@@ -53,6 +53,9 @@ func TestBuildWorktreeItems(t *testing.T) {
 		if items[0].Icon != iconDirSession {
 			t.Errorf("Icon = %q, want %q", items[0].Icon, iconDirSession)
 		}
+		if !items[0].HasSession {
+			t.Error("HasSession = false, want true")
+		}
 		if items[0].Context != "feature-branch" {
 			t.Errorf("Context = %q, want %q", items[0].Context, "feature-branch")
 		}
@@ -69,6 +72,9 @@ func TestBuildWorktreeItems(t *testing.T) {
 		if items[0].Icon != "" {
 			t.Errorf("Icon = %q, want empty", items[0].Icon)
 		}
+		if items[0].HasSession {
+			t.Error("HasSession = true, want false")
+		}
 	})
 
 	t.Run("mixed session and no-session worktrees", func(t *testing.T) {
@@ -109,6 +115,43 @@ func TestBuildWorktreeItems(t *testing.T) {
 	})
 }
 
+func TestItemsInWorktreeOrder(t *testing.T) {
+	items := []ui.Item{
+		{Name: "a", Path: "/repo/a"},
+		{Name: "b", Path: "/repo/b"},
+		{Name: "c", Path: "/repo/c"},
+	}
+	worktrees := []project.Worktree{{Path: "/repo/c"}, {Path: "/repo/a"}, {Path: "/repo/b"}}
+
+	got := itemsInWorktreeOrder(items, worktrees)
+
+	if len(got) != 3 || got[0].Name != "c" || got[1].Name != "a" || got[2].Name != "b" {
+		t.Errorf("order = %v, want c a b", got)
+	}
+}
+
+func TestBranchFilterKeeps(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		branch   string
+		want     bool
+	}{
+		{[]string{"feature/*"}, "feature/login", true},
+		{[]string{"feature/*"}, "release/1.0", false},
+		{[]string{"feature/*"}, "feature/a/b", false},
+		{[]string{"feature/*", "main"}, "main", true},
+		{[]string{"!release/*"}, "release/1.0", false},
+		{[]string{"!release/*"}, "main", true},
+		{[]string{"*", "!release/*"}, "release/1.0", false},
+		{[]string{"feature/*"}, "", false},
+	}
+	for _, tt := range tests {
+		if got := branchFilterKeeps(tt.patterns, tt.branch); got != tt.want {
+			t.Errorf("branchFilterKeeps(%v, %q) = %v, want %v", tt.patterns, tt.branch, got, tt.want)
+		}
+	}
+}
+
 func TestRemoveFromHistoryWith(t *testing.T) {
 	histJSON := `{"entries":[
 		{"path":"/repo/feature","last_access":"2026-06-01T10:00:00Z"},
@@ -511,6 +554,60 @@ func TestOpenWorktreeWithShaping_SessionPresentAttachesFlat(t *testing.T) {
 	}
 }
 
+// TestOpenWorktreeWithShaping_OpenAsWindowAttachesFlat asserts that with
+// [worktree] open_as = "window" the worktree's missing session is not shaped:
+// the window opens in the repository's session instead.
+func TestOpenWorktreeWithShaping_OpenAsWindowAttachesFlat(t *testing.T) {
+	wbs := []config.Workbench{{Name: "gs-dev"}}
+	d, spy := newShapeDeps(true, wbs, "gs-dev", true)
+	d.OpenAsWindow = true
+
+	if err := openWorktreeWithShaping(d, &project.RepoContext{}, "/repo/feature"); err != nil {
+		t.Fatalf("openWorktreeWithShaping: %v", err)
+	}
+
+	if !spy.flatCalled {
+		t.Error("expected the flat path, which opens the window")
+	}
+	if spy.promptCalled || spy.createdTmpl != "" {
+		t.Error("a worktree opening as a window must not get a Workbench session")
+	}
+}
+
+func TestSwitchTmuxWindowWith(t *testing.T) {
+	var calls []string
+	var created, switched string
+	tmux := &deps.MockTmux{
+		HasSessionFunc: func(name string) bool { return false },
+		NewSessionFunc: func(name, dir string) error {
+			created = name + " " + dir
+			return nil
+		},
+		CommandFunc: func(args ...string) (string, error) {
+			calls = append(calls, strings.Join(args, " "))
+			return "", nil
+		},
+		SwitchClientFunc: func(name string) error {
+			switched = name
+			return nil
+		},
+	}
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+	if err := switchTmuxWindowWith(tmux, "app", &ui.Item{Path: "/src/app/fix.login"}); err != nil {
+		t.Fatalf("switchTmuxWindowWith: %v", err)
+	}
+	if created != "app /src/app/fix.login" {
+		t.Errorf("created session %q, want app at the worktree", created)
+	}
+	if len(calls) != 2 || calls[0] != "rename-window -t app fix_login" || calls[1] != "set-option -w -t app @pop_path /src/app/fix.login" {
+		t.Errorf("tmux calls = %v, want the first window named fix_login and tagged with its path", calls)
+	}
+	if switched != "app" {
+		t.Errorf("switched to %q, want app", switched)
+	}
+}
+
 func TestWorktreeHelpHasNoPhantomCreateBinding(t *testing.T) {
 	// ctrl-n is cursor-down in the picker; a create binding never shipped.
 	// Guard against the stale help line returning.
@@ -2,8 +2,14 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -20,6 +26,9 @@ type GlobCacheEntry struct {
 	// Includes the base directory and all intermediate directories whose
 	// contents contribute to the glob result.
 	DirMtimes map[string]time.Time `json:"dir_mtimes"`
+	// CachedAt is when the glob was expanded, checked against cache_ttl.
+	// Zero for entries written before it was recorded.
+	CachedAt time.Time `json:"cached_at,omitzero"`
 }
 
 // GlobCache holds cached glob expansion results
@@ -72,22 +81,127 @@ func loadGlobCache(d *Deps, path string) *GlobCache {
 	return &loaded
 }
 
-// saveGlobCache writes the cache file. Errors are silently ignored (cache is best-effort).
+// saveGlobCache writes the cache file. Errors are logged and otherwise ignored
+// (cache is best-effort).
 func saveGlobCache(d *Deps, path string, cache *GlobCache) {
+	if err := writeGlobCache(d, path, cache); err != nil {
+		debug.Error("saveGlobCache: %v", err)
+	}
+}
+
+// writeGlobCache writes the cache file atomically: the data goes to a synced
+// temp file that is renamed over path, so a crash or a concurrent pop leaves
+// either the old cache or the new one, never a torn file.
+func writeGlobCache(d *Deps, path string, cache *GlobCache) error {
 	dir := filepath.Dir(path)
 	if err := d.FS.MkdirAll(dir, 0755); err != nil {
-		debug.Error("saveGlobCache: mkdir %s: %v", dir, err)
-		return
+		return fmt.Errorf("mkdir %s: %w", dir, err)
 	}
 
 	data, err := json.MarshalIndent(cache, "", "  ")
 	if err != nil {
-		debug.Error("saveGlobCache: marshal: %v", err)
-		return
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	tmpPath := filepath.Join(dir, fmt.Sprintf(".%s.%d-%d.tmp", filepath.Base(path), os.Getpid(), atomic.AddUint64(&globCacheSaveSeq, 1)))
+	if err := d.FS.WriteFileSync(tmpPath, data, 0644); err != nil {
+		_ = d.FS.RemoveAll(tmpPath)
+		return fmt.Errorf("write %s: %w", tmpPath, err)
+	}
+	if err := d.FS.Rename(tmpPath, path); err != nil {
+		_ = d.FS.RemoveAll(tmpPath)
+		return fmt.Errorf("rename to %s: %w", path, err)
+	}
+	return nil
+}
+
+// globCacheSaveSeq numbers writeGlobCache's temp files so concurrent writes
+// in one process never share one.
+var globCacheSaveSeq uint64
+
+// GlobCacheManager holds a process's glob caches: each cache file is read
+// once, shared by every ExpandProjectsWith call, and written back once by
+// Flush rather than after every expansion. It is safe for concurrent use.
+type GlobCacheManager struct {
+	mu     sync.Mutex
+	caches map[string]*managedGlobCache
+}
+
+type managedGlobCache struct {
+	d     *Deps
+	cache *GlobCache
+	dirty bool
+}
+
+// NewGlobCacheManager returns an empty manager.
+func NewGlobCacheManager() *GlobCacheManager {
+	return &GlobCacheManager{caches: make(map[string]*managedGlobCache)}
+}
+
+// sharedGlobCache is the manager DefaultDeps hands out, flushed by
+// FlushGlobCache.
+var sharedGlobCache = NewGlobCacheManager()
+
+// FlushGlobCache writes the glob caches the process changed back to disk.
+// pop calls it once on exit.
+func FlushGlobCache() error {
+	return sharedGlobCache.Flush()
+}
+
+// use runs fn on the cache at path, loading it on first use; fn reports
+// whether it changed the cache. Calls are serialized.
+func (m *GlobCacheManager) use(d *Deps, path string, fn func(*GlobCache) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mc, ok := m.caches[path]
+	if !ok {
+		mc = &managedGlobCache{d: d, cache: loadGlobCache(d, path)}
+		m.caches[path] = mc
+	}
+	if fn(mc.cache) {
+		mc.dirty = true
+	}
+}
+
+// Flush writes every changed cache back to disk. A cache that fails to write
+// stays marked changed for the next Flush.
+func (m *GlobCacheManager) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for path, mc := range m.caches {
+		if !mc.dirty {
+			continue
+		}
+		if err := writeGlobCache(mc.d, path, mc.cache); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		mc.dirty = false
 	}
+	return errors.Join(errs...)
+}
 
-	if err := d.FS.WriteFile(path, data, 0644); err != nil {
-		debug.Error("saveGlobCache: write %s: %v", path, err)
+// forget drops the in-memory copy of the cache at path, so a file changed
+// behind the manager's back (pop cache clear, pop prune) is neither shadowed
+// nor overwritten by the next Flush.
+func (m *GlobCacheManager) forget(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.caches, path)
+}
+
+// withGlobCache runs fn on the glob cache at path, through d's manager when it
+// has one. Without one the file is read for this call and, when fn reports a
+// change, written straight back.
+func withGlobCache(d *Deps, path string, fn func(*GlobCache) bool) {
+	if d.GlobCache != nil {
+		d.GlobCache.use(d, path, fn)
+		return
+	}
+	cache := loadGlobCache(d, path)
+	if fn(cache) {
+		saveGlobCache(d, path, cache)
 	}
 }
 
@@ -106,43 +220,70 @@ func isCacheEntryValid(d *Deps, entry GlobCacheEntry) bool {
 	return true
 }
 
-// expandGlobCached attempts to use cached glob results. Returns the matches,
-// whether the cache was updated, and any error.
-func expandGlobCached(d *Deps, pattern string, cache *GlobCache) ([]string, bool, error) {
-	if entry, ok := cache.Entries[pattern]; ok {
-		if isCacheEntryValid(d, entry) {
-			return entry.Matches, false, nil
+// isCacheEntryExpired reports whether entry is older than ttl as of now. With
+// a ttl set, an entry without a recorded time counts as expired.
+func isCacheEntryExpired(entry GlobCacheEntry, ttl time.Duration, now time.Time) bool {
+	return ttl > 0 && (entry.CachedAt.IsZero() || now.Sub(entry.CachedAt) > ttl)
+}
+
+// expandGlobCached attempts to use cached glob results, re-globbing entries
+// older than ttl (0 = no limit). Returns the matches, whether the cache was
+// updated, and any error.
+func expandGlobCached(d *Deps, pattern string, cache *GlobCache, ttl time.Duration) ([]string, bool, error) {
+	cached, ok := cache.Entries[pattern]
+	matches, entry, err := refreshGlob(d, pattern, cached, ok, ttl)
+	if entry == nil {
+		return matches, false, err
+	}
+	storeGlobCacheEntry(cache, pattern, entry)
+	return matches, true, err
+}
+
+// refreshGlob returns pattern's matches from cached (present when ok) while
+// that entry is still valid, with a nil entry. Otherwise it globs again and
+// returns the entry to store in its place: a fresh one, or the zero entry to
+// drop the pattern. It doesn't touch the cache, so it can run unlocked
+// alongside other expansions.
+func refreshGlob(d *Deps, pattern string, cached GlobCacheEntry, ok bool, ttl time.Duration) ([]string, *GlobCacheEntry, error) {
+	now := time.Now()
+	if ok && !isCacheEntryExpired(cached, ttl, now) {
+		if isCacheEntryValid(d, cached) {
+			return cached.Matches, nil, nil
 		}
 	}
 
 	// Cache miss — perform actual glob
 	matches, resolvedBase, err := expandGlobWithBase(d, pattern)
 	if err != nil {
-		delete(cache.Entries, pattern)
-		return nil, true, err
+		return nil, &GlobCacheEntry{}, err
 	}
 
 	// Don't cache empty results — the directory may not exist yet or may be
 	// temporarily empty. Re-globbing is cheap and avoids stale empty entries
 	// that depend on mtime detection to recover.
 	if len(matches) == 0 {
-		delete(cache.Entries, pattern)
-		return nil, true, nil
+		return nil, &GlobCacheEntry{}, nil
 	}
 
 	// Determine the pattern portion (after base split)
 	_, pat := doublestar.SplitPattern(pattern)
 
-	// Collect directory mtimes for cache validation
-	dirMtimes := collectDirMtimes(d, resolvedBase, pat)
-
-	cache.Entries[pattern] = GlobCacheEntry{
+	return matches, &GlobCacheEntry{
 		BasePath:  resolvedBase,
 		Matches:   matches,
-		DirMtimes: dirMtimes,
-	}
+		DirMtimes: collectDirMtimes(d, resolvedBase, pat),
+		CachedAt:  now,
+	}, nil
+}
 
-	return matches, true, nil
+// storeGlobCacheEntry puts an entry refreshGlob returned into cache; the zero
+// entry removes pattern.
+func storeGlobCacheEntry(cache *GlobCache, pattern string, entry *GlobCacheEntry) {
+	if entry.Matches == nil {
+		delete(cache.Entries, pattern)
+		return
+	}
+	cache.Entries[pattern] = *entry
 }
 
 // collectDirMtimes gathers modification times for all directories whose contents
@@ -164,18 +305,39 @@ func collectDirMtimes(d *Deps, resolvedBase string, pattern string) map[string]t
 }
 
 // countWildcardDepth counts the number of path segments in a pattern that
-// contain a wildcard character.
+// contain glob syntax (*, ?, [...] or {a,b}). A brace group spanning a
+// slash, like {a/b,c}, can reach different depths per alternative, so every
+// segment then counts.
 func countWildcardDepth(pattern string) int {
 	segments := strings.Split(pattern, "/")
+	if braceSpansSlash(pattern) {
+		return len(segments)
+	}
 	count := 0
 	for _, seg := range segments {
-		if strings.Contains(seg, "*") {
+		if isGlobPattern(seg) {
 			count++
 		}
 	}
 	return count
 }
 
+// braceSpansSlash reports whether a {...} group in pattern contains a slash.
+func braceSpansSlash(pattern string) bool {
+	depth := 0
+	for _, r := range pattern {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}' && depth > 0:
+			depth--
+		case r == '/' && depth > 0:
+			return true
+		}
+	}
+	return false
+}
+
 // collectChildDirMtimes recursively collects mtimes of subdirectories
 // up to the specified remaining depth.
 func collectChildDirMtimes(d *Deps, dir string, remainingDepth int, mtimes map[string]time.Time) {
@@ -202,3 +364,93 @@ func collectChildDirMtimes(d *Deps, dir string, remainingDepth int, mtimes map[s
 		}
 	}
 }
+
+// GlobCacheEntryStatus describes one cached glob for pop cache status.
+type GlobCacheEntryStatus struct {
+	Pattern  string
+	Matches  int
+	CachedAt time.Time // zero for entries cached before it was recorded
+	// Changed is set when a directory the glob depends on changed since it
+	// was cached, Expired when it is older than cache_ttl. Either way the
+	// next scan globs it again.
+	Changed bool
+	Expired bool
+}
+
+// InspectGlobCache describes each entry of the glob cache at path.
+// Uses default dependencies.
+func InspectGlobCache(path string, ttl time.Duration, now time.Time) ([]GlobCacheEntryStatus, error) {
+	return InspectGlobCacheWith(defaultDeps, path, ttl, now)
+}
+
+// InspectGlobCacheWith describes each entry of the glob cache at path, sorted
+// by pattern, judging expiry against ttl as of now. Unlike the picker, which
+// starts over from an empty cache, it reports a missing or unreadable file as
+// an error.
+func InspectGlobCacheWith(d *Deps, path string, ttl time.Duration, now time.Time) ([]GlobCacheEntryStatus, error) {
+	data, err := d.FS.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cache GlobCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if cache.Version != 1 {
+		return nil, fmt.Errorf("%s has unknown version %d", path, cache.Version)
+	}
+
+	statuses := make([]GlobCacheEntryStatus, 0, len(cache.Entries))
+	for pattern, entry := range cache.Entries {
+		statuses = append(statuses, GlobCacheEntryStatus{
+			Pattern:  pattern,
+			Matches:  len(entry.Matches),
+			CachedAt: entry.CachedAt,
+			Changed:  !isCacheEntryValid(d, entry),
+			Expired:  isCacheEntryExpired(entry, ttl, now),
+		})
+	}
+	slices.SortFunc(statuses, func(a, b GlobCacheEntryStatus) int { return strings.Compare(a.Pattern, b.Pattern) })
+	return statuses, nil
+}
+
+// ClearGlobCache deletes the glob cache at path, so the next scan lists every
+// directory again. Uses default dependencies.
+func ClearGlobCache(path string) error {
+	return ClearGlobCacheWith(defaultDeps, path)
+}
+
+// ClearGlobCacheWith deletes the glob cache at path. A missing file is not an
+// error.
+func ClearGlobCacheWith(d *Deps, path string) error {
+	if d.GlobCache != nil {
+		d.GlobCache.forget(path)
+	}
+	return d.FS.RemoveAll(path)
+}
+
+// DropGlobCacheEntries removes the entries for patterns from the glob cache at
+// path. Uses default dependencies.
+func DropGlobCacheEntries(path string, patterns []string) error {
+	return DropGlobCacheEntriesWith(defaultDeps, path, patterns)
+}
+
+// DropGlobCacheEntriesWith removes the entries for patterns from the glob
+// cache at path, keeping the rest. Patterns not in the cache are ignored.
+func DropGlobCacheEntriesWith(d *Deps, path string, patterns []string) error {
+	data, err := d.FS.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var cache GlobCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, p := range patterns {
+		delete(cache.Entries, p)
+	}
+	if d.GlobCache != nil {
+		d.GlobCache.forget(path)
+	}
+	return writeGlobCache(d, path, &cache)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -114,7 +115,7 @@ func TestLoadGlobCache(t *testing.T) {
 }
 
 func TestSaveGlobCache(t *testing.T) {
-	var savedPath string
+	var tmpPath, savedPath string
 	var savedData []byte
 	var mkdirPath string
 
@@ -124,11 +125,18 @@ func TestSaveGlobCache(t *testing.T) {
 				mkdirPath = path
 				return nil
 			},
-			WriteFileFunc: func(path string, data []byte, perm os.FileMode) error {
-				savedPath = path
+			WriteFileSyncFunc: func(path string, data []byte, perm os.FileMode) error {
+				tmpPath = path
 				savedData = data
 				return nil
 			},
+			RenameFunc: func(oldpath, newpath string) error {
+				if oldpath != tmpPath {
+					t.Errorf("Rename from %q, want the synced temp file %q", oldpath, tmpPath)
+				}
+				savedPath = newpath
+				return nil
+			},
 		},
 	}
 
@@ -150,8 +158,11 @@ func TestSaveGlobCache(t *testing.T) {
 	if mkdirPath != "/cache/dir" {
 		t.Errorf("MkdirAll path = %q, want %q", mkdirPath, "/cache/dir")
 	}
+	if filepath.Dir(tmpPath) != "/cache/dir" || tmpPath == "/cache/dir/glob_cache.json" {
+		t.Errorf("temp file = %q, want a sibling of the cache file", tmpPath)
+	}
 	if savedPath != "/cache/dir/glob_cache.json" {
-		t.Errorf("WriteFile path = %q, want %q", savedPath, "/cache/dir/glob_cache.json")
+		t.Errorf("renamed to %q, want %q", savedPath, "/cache/dir/glob_cache.json")
 	}
 
 	// Verify round-trip
@@ -164,6 +175,49 @@ func TestSaveGlobCache(t *testing.T) {
 	}
 }
 
+func TestGlobCacheManager_ReadsOnceAndFlushesOnce(t *testing.T) {
+	var reads, writes int
+	d := &Deps{
+		FS: &deps.MockFileSystem{
+			ReadFileFunc: func(path string) ([]byte, error) {
+				reads++
+				return []byte(`{"version":1,"entries":{}}`), nil
+			},
+			WriteFileSyncFunc: func(path string, data []byte, perm os.FileMode) error {
+				writes++
+				return nil
+			},
+		},
+		GlobCache: NewGlobCacheManager(),
+	}
+
+	for i := range 3 {
+		withGlobCache(d, "/cache/glob_cache.json", func(cache *GlobCache) bool {
+			cache.Entries[strings.Repeat("x", i+1)] = GlobCacheEntry{}
+			return true
+		})
+	}
+	if reads != 1 || writes != 0 {
+		t.Fatalf("reads = %d, writes = %d before Flush; want 1 and 0", reads, writes)
+	}
+	if err := d.GlobCache.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.GlobCache.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if writes != 1 {
+		t.Errorf("writes = %d after two Flushes, want 1", writes)
+	}
+
+	// After the file changes behind the manager, the next use reads it again.
+	d.GlobCache.forget("/cache/glob_cache.json")
+	withGlobCache(d, "/cache/glob_cache.json", func(*GlobCache) bool { return false })
+	if reads != 2 {
+		t.Errorf("reads = %d after forget, want 2", reads)
+	}
+}
+
 func TestIsCacheEntryValid(t *testing.T) {
 	now := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
 	later := now.Add(time.Hour)
@@ -251,6 +305,10 @@ func TestCountWildcardDepth(t *testing.T) {
 		{"*/*/*", 3},
 		{"foo/bar", 0},
 		{"*/*/baz", 2},
+		{"{work,personal}/*", 2},
+		{"app-?/src", 1},
+		{"[a-m]*/*", 2},
+		{"{a/b,c}/*", 3},
 	}
 
 	for _, tt := range tests {
@@ -331,6 +389,40 @@ func TestCollectDirMtimes(t *testing.T) {
 		}
 	})
 
+	t.Run("brace alternatives track each alternative's directory", func(t *testing.T) {
+		d := &Deps{
+			FS: &deps.MockFileSystem{
+				StatFunc: func(path string) (os.FileInfo, error) {
+					switch path {
+					case "/base":
+						return deps.MockFileInfo{IsDirVal: true, ModTimeVal: baseTime}, nil
+					case "/base/work", "/base/personal":
+						return deps.MockFileInfo{IsDirVal: true, ModTimeVal: childTime}, nil
+					default:
+						return nil, os.ErrNotExist
+					}
+				},
+				ReadDirFunc: func(path string) ([]os.DirEntry, error) {
+					if path == "/base" {
+						return []os.DirEntry{
+							deps.MockDirEntry{NameVal: "work", IsDirVal: true},
+							deps.MockDirEntry{NameVal: "personal", IsDirVal: true},
+						}, nil
+					}
+					return nil, nil
+				},
+			},
+		}
+
+		mtimes := collectDirMtimes(d, "/base", "{work,personal}/*")
+
+		for _, dir := range []string{"/base", "/base/work", "/base/personal"} {
+			if _, ok := mtimes[dir]; !ok {
+				t.Errorf("%s not tracked: %v", dir, mtimes)
+			}
+		}
+	})
+
 	t.Run("skips non-directory entries", func(t *testing.T) {
 		d := &Deps{
 			FS: &deps.MockFileSystem{
@@ -578,7 +670,7 @@ func TestExpandGlobCached_EmptyResultsNotCached(t *testing.T) {
 	cache := &GlobCache{Version: 1, Entries: make(map[string]GlobCacheEntry)}
 
 	// First call: empty directory, should return nothing
-	matches, updated, err := expandGlobCached(d, "/home/user/dev/*", cache)
+	matches, updated, err := expandGlobCached(d, "/home/user/dev/*", cache, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -609,7 +701,7 @@ func TestExpandGlobCached_EmptyResultsNotCached(t *testing.T) {
 		},
 	}
 
-	matches, _, err = expandGlobCached(d, "/home/user/dev/*", cache)
+	matches, _, err = expandGlobCached(d, "/home/user/dev/*", cache, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -618,6 +710,63 @@ func TestExpandGlobCached_EmptyResultsNotCached(t *testing.T) {
 	}
 }
 
+func TestExpandGlobCached_TTL(t *testing.T) {
+	mtime := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	d := &Deps{
+		FS: &deps.MockFileSystem{
+			StatFunc: func(path string) (os.FileInfo, error) {
+				return deps.MockFileInfo{IsDirVal: true, ModTimeVal: mtime}, nil
+			},
+			EvalSymlinksFunc: func(path string) (string, error) { return path, nil },
+			DirFSFunc: func(dir string) fs.FS {
+				return &deps.MockFS{Dirs: map[string][]string{".": {"fresh"}}}
+			},
+		},
+	}
+	cached := func(age time.Duration) *GlobCache {
+		return &GlobCache{Version: 1, Entries: map[string]GlobCacheEntry{
+			"/dev/*": {
+				BasePath:  "/dev",
+				Matches:   []string{"/dev/cached"},
+				DirMtimes: map[string]time.Time{"/dev": mtime},
+				CachedAt:  time.Now().Add(-age),
+			},
+		}}
+	}
+
+	matches, updated, _ := expandGlobCached(d, "/dev/*", cached(2*time.Hour), 24*time.Hour)
+	if updated || len(matches) != 1 || matches[0] != "/dev/cached" {
+		t.Errorf("within ttl: matches = %v, updated = %v, want the cached entry", matches, updated)
+	}
+
+	cache := cached(2 * time.Hour)
+	matches, updated, _ = expandGlobCached(d, "/dev/*", cache, time.Hour)
+	if !updated || len(matches) != 1 || matches[0] != "/dev/fresh" {
+		t.Errorf("past ttl: matches = %v, updated = %v, want a fresh glob", matches, updated)
+	}
+	if cache.Entries["/dev/*"].CachedAt.IsZero() {
+		t.Error("re-globbed entry should record when it was cached")
+	}
+}
+
+func TestGlobCacheTTL(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"24h", 24 * time.Hour},
+		{" 90m ", 90 * time.Minute},
+		{"tomorrow", 0},
+		{"-1h", 0},
+	}
+	for _, tt := range tests {
+		if got := (&Config{CacheTTL: tt.value}).GlobCacheTTL(); got != tt.want {
+			t.Errorf("GlobCacheTTL(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
 func TestExpandProjectsWith_ExactPathsSkipCache(t *testing.T) {
 	d := &Deps{
 		FS: &deps.MockFileSystem{
@@ -644,3 +793,31 @@ func TestExpandProjectsWith_ExactPathsSkipCache(t *testing.T) {
 		t.Errorf("expected /home/user/exact/project, got %s", result[0].Path)
 	}
 }
+
+func TestProjectWatchDirs(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"work/api", "personal"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &Config{Projects: []ProjectEntry{
+		{Path: filepath.Join(root, "*", "*")},
+		{Path: filepath.Join(root, "solo")},
+		{Path: filepath.Join(root, "later", "*")},
+		{Path: filepath.Join(root, "**")},
+	}}
+
+	got := cfg.projectWatchDirsWith(&Deps{FS: deps.NewRealFileSystem()})
+	want := []string{
+		root,
+		filepath.Join(root, "personal"),
+		filepath.Join(root, "work"),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("projectWatchDirsWith() = %q, want %q", got, want)
+	}
+}
@@ -4,16 +4,23 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/glebglazov/pop/debug"
 	"github.com/glebglazov/pop/internal/deps"
+	"golang.org/x/sync/errgroup"
 )
 
 // Deps holds external dependencies for the config package
@@ -27,12 +34,22 @@ type Deps struct {
 	// config cannot import tasks/binding, so callers with git access inject
 	// this; a nil Trunk disables the inheritance layer.
 	Trunk func(checkoutPath string) (path string, ok bool)
+	// GlobCache shares one in-memory glob cache across ExpandProjectsWith
+	// calls; nil makes every call read and write the cache file itself.
+	GlobCache *GlobCacheManager
+	// Users resolves ~user paths; nil leaves them as written.
+	Users deps.Users
+	// WindowsEnv expands %VAR% in paths, as Windows does.
+	WindowsEnv bool
 }
 
 // DefaultDeps returns dependencies using real implementations
 func DefaultDeps() *Deps {
 	return &Deps{
-		FS: deps.NewRealFileSystem(),
+		FS:         deps.NewRealFileSystem(),
+		GlobCache:  sharedGlobCache,
+		Users:      deps.NewRealUsers(),
+		WindowsEnv: runtime.GOOS == "windows",
 	}
 }
 
@@ -42,8 +59,11 @@ var defaultDeps = DefaultDeps()
 type UserDefinedCommand struct {
 	Key     string `toml:"key" desc:"Key binding that triggers this command (e.g. \"ctrl-l\")."`
 	Label   string `toml:"label" desc:"Display label shown in the picker hint bar."`
+	Hint    string `toml:"hint" desc:"Text shown for the key instead of the binding (e.g. \"⌘K\")."`
+	HintBar bool   `toml:"hint_bar" desc:"Also list the command in the hint bar below the picker."`
 	Command string `toml:"command" desc:"Shell command to execute."`
 	Exit    bool   `toml:"exit" desc:"Exit the picker after running the command."`
+	Multi   bool   `toml:"multi" desc:"Run once for all items marked with tab: {paths} expands to the quoted paths, otherwise they arrive NUL-delimited on stdin."`
 }
 
 // PaneMonitoringConfig holds pane monitoring configuration
@@ -88,6 +108,16 @@ const DefaultTopicWords = 5
 // 30s gives a multi-GB local model room to cold-load before pop falls through.
 const DefaultTopicDerivationTimeoutSeconds = 30
 
+// ThemeConfig holds the [theme] TUI colors. Each is an ANSI 256 index ("39")
+// or a hex value ("#5f87ff"); empty keeps pop's default.
+type ThemeConfig struct {
+	SelectedBg string `toml:"selected_bg" desc:"Background of the highlighted picker row."`
+	SelectedFg string `toml:"selected_fg" desc:"Text color of the highlighted picker row."`
+	Accent     string `toml:"accent" desc:"Cursor block, prompt, headers, marks and query matches."`
+	Hint       string `toml:"hint" desc:"Footer hints and other dimmed text."`
+	Context    string `toml:"context" desc:"The picker's context column (e.g. branches)."`
+}
+
 // DashboardConfig holds dashboard-specific configuration
 type DashboardConfig struct {
 	CurrentPaneAlwaysUnderCursor bool     `toml:"current_pane_always_under_cursor" desc:"Deprecated: place the current pane under the cursor (use cursor_position)."`
@@ -121,15 +151,71 @@ var DefaultSortCriteria = []string{SortByStatus, SortByPaneLastActiveAt, SortByA
 // WorktreeConfig holds worktree-specific configuration
 type WorktreeConfig struct {
 	Commands                   []UserDefinedCommand `toml:"commands" desc:"User-defined commands for the worktree picker."`
+	PreviewCommand             string               `toml:"preview_command" desc:"Shell command whose output fills the worktree picker's preview pane (overrides the global one)."`
+	OnSelect                   []string             `toml:"on_select" desc:"Steps run on Enter in the worktree picker (record_history, ensure_session, run:<cmd>, switch)."`
+	OpenAs                     string               `toml:"open_as" desc:"Where --switch opens a worktree: session (default, a session per worktree) or window (a window named after it in the repository's session)."`
+	BranchFilter               []string             `toml:"branch_filter" desc:"Branch glob patterns the worktree picker's branch filter keeps (a leading ! hides matches instead)."`
+	UI                         *PickerUIConfig      `toml:"ui" desc:"Worktree picker display defaults ([worktree.ui] table)."`
 	UnreadNotificationsEnabled bool                 `toml:"unread_notifications_enabled" desc:"Enable unread-status notifications in worktree mode."`
 	// Deprecated: use UnreadNotificationsEnabled. The old key is read for
 	// backwards compat; a warning is emitted when it is present.
 	AttentionNotificationsEnabled bool `toml:"attention_notifications_enabled" desc:"Deprecated: use unread_notifications_enabled."`
 }
 
+// PickerUIConfig holds one picker mode's display defaults ([project.ui] and
+// [worktree.ui] tables). Unset fields keep the mode's built-in behavior.
+type PickerUIConfig struct {
+	CursorAtEnd         *bool  `toml:"cursor_at_end" desc:"Start with the cursor on the last (most recent) item (default true)."`
+	ShowContext         *bool  `toml:"show_context" desc:"Show the context column: the branch in worktree mode, the repo in project mode."`
+	ShowIcons           *bool  `toml:"show_icons" desc:"Show session and project icons beside items (default true)."`
+	QuickAccessModifier string `toml:"quick_access_modifier" desc:"Quick-access modifier for this picker (alt|ctrl|disabled); overrides the global one."`
+	Height              int    `toml:"height" desc:"Most item rows the picker shows (0 = fill the terminal)."`
+	PopupWidth          string `toml:"popup_width" desc:"Width of the display-popup --popup opens (cells or a percentage, default 60%)."`
+	PopupHeight         string `toml:"popup_height" desc:"Height of the display-popup --popup opens (cells or a percentage, default 60%)."`
+}
+
+// HooksConfig holds the [hooks] shell commands pop runs around session
+// lifecycle events in the project and worktree pickers.
+type HooksConfig struct {
+	OnCreate string `toml:"on_create" desc:"Shell command run after pop creates a session."`
+	OnSwitch string `toml:"on_switch" desc:"Shell command run before pop switches or attaches to a session."`
+	OnKill   string `toml:"on_kill" desc:"Shell command run after pop kills a session."`
+	OnSelect string `toml:"on_select" desc:"Shell command run when an item is chosen in the picker, before anything else."`
+}
+
+// Hook events, named after their [hooks] keys.
+const (
+	HookOnCreate = "on_create"
+	HookOnSwitch = "on_switch"
+	HookOnKill   = "on_kill"
+	HookOnSelect = "on_select"
+)
+
+// EventsConfig holds the [events] shell commands, keyed by the event names
+// of the events package, that integrations subscribe to.
+type EventsConfig struct {
+	ProjectSelected string `toml:"project_selected" desc:"Shell command run when a project or worktree is chosen in a picker."`
+	SessionCreated  string `toml:"session_created" desc:"Shell command run after pop creates a session."`
+	WorktreeCreated string `toml:"worktree_created" desc:"Shell command run after pop creates a worktree."`
+	SessionKilled   string `toml:"session_killed" desc:"Shell command run after pop kills a session."`
+}
+
 // ProjectConfig holds project-picker-specific configuration
 type ProjectConfig struct {
 	Commands                   []UserDefinedCommand `toml:"commands" desc:"User-defined commands for the project picker."`
+	PreviewCommand             string               `toml:"preview_command" desc:"Shell command whose output fills the project picker's preview pane (overrides the global one)."`
+	GroupBy                    string               `toml:"group_by" desc:"Group picker items under a header (parent = the directory each entry was matched in)."`
+	OnSelect                   []string             `toml:"on_select" desc:"Steps run on Enter in the project picker (record_history, ensure_session, run:<cmd>, switch)."`
+	Stream                     bool                 `toml:"stream" desc:"Open the project picker immediately and add projects as their directories are scanned."`
+	HistoryOnKill              string               `toml:"history_on_kill" desc:"What killing a project's session from the picker does to its history (keep|demote|remove, default keep)."`
+	HistoryPruneMissing        bool                 `toml:"history_prune_missing" desc:"Drop history entries whose directory no longer exists when the picker opens."`
+	HistoryLimit               int                  `toml:"history_limit" desc:"Most history entries to keep, the most recently opened (0 = default 500, -1 = unlimited)."`
+	GCAfterDays                int                  `toml:"gc_after_days" desc:"Days a detached project session may sit unused before pop gc offers to kill it (0 = default 7)."`
+	CreateGitInit              bool                 `toml:"create_git_init" desc:"Run git init in projects created from the picker with ctrl+n."`
+	Editor                     string               `toml:"editor" desc:"Editor alt+enter opens the highlighted project in (code|nvim|zed, default code)."`
+	RestoreWindow              string               `toml:"restore_window" desc:"Window pop selects when it switches back to a project's session: last (the window and pane active when pop last switched away) or a window name or index."`
+	NvimServer                 string               `toml:"nvim_server" desc:"Address of the nvim --listen server open_with = \"nvim\" opens projects in (default $NVIM)."`
+	UI                         *PickerUIConfig      `toml:"ui" desc:"Project picker display defaults ([project.ui] table)."`
 	UnreadNotificationsEnabled bool                 `toml:"unread_notifications_enabled" desc:"Enable unread-status notifications in project mode."`
 	// Deprecated: use UnreadNotificationsEnabled. The old key is read for
 	// backwards compat; a warning is emitted when it is present.
@@ -309,6 +395,14 @@ type WorkbenchOptions struct {
 	// "<reset>"). A token that resolves to nothing is ignored. Settable from an
 	// included file (first definition wins; the main config wins over includes).
 	Order []string `toml:"order" include:"replace" desc:"Fixed display order of Workbench-list tokens (array of on-screen labels)."`
+
+	// Default is the global fallback Workbench: it auto-applies to every new
+	// session whose checkout has no preferred Workbench from any repo-specific
+	// or runtime layer, so a project without its own layout still starts from a
+	// shared one instead of a single bare window. It is the lowest rung of the
+	// preferred_workbench ladder (ResolvePreferredWorkbench); a runtime
+	// explicit-none (ctrl+w "<empty>") opts a checkout out of it.
+	Default string `toml:"default" include:"replace" desc:"Global fallback Workbench auto-applied to new sessions with no repo-specific preference."`
 }
 
 // Workbench is a named blueprint for an ordered list of tmux windows,
@@ -493,6 +587,39 @@ func (c *Config) ResolveQueue() (ResolvedQueueConfig, error) {
 type ProjectEntry struct {
 	Path         string `toml:"path" desc:"Exact path or glob pattern to a project directory."`
 	DisplayDepth int    `toml:"display_depth" desc:"Trailing path segments to show in the picker name (0 = default 1)."`
+	// WorktreeDisplay names the worktrees this entry expands to: by directory
+	// (the default), by checked-out branch, or both as "dir (branch)". Session
+	// names always stay directory-based so renaming a branch never orphans a
+	// running session.
+	WorktreeDisplay string `toml:"worktree_display,omitempty" desc:"How worktrees of this entry are named in the picker (dir|branch|both = \"dir (branch)\")."`
+
+	// Per-project overrides, so one project can behave differently from the
+	// rest. SessionName only applies to exact paths: a glob would give every
+	// match the same session.
+	SessionName string   `toml:"session_name,omitempty" desc:"tmux session name for this project instead of the directory-derived one (exact paths only)."`
+	Command     string   `toml:"command,omitempty" desc:"Shell command typed into a new session for this project once pop creates it."`
+	Container   string   `toml:"container,omitempty" desc:"Docker container new sessions of this project open a shell in (docker exec, starting in /workspace)."`
+	Workbench   string   `toml:"workbench,omitempty" desc:"Workbench applied to new sessions of this project when no other preferred Workbench resolves."`
+	Icon        string   `toml:"icon,omitempty" desc:"Icon shown beside this project in the picker when no session status icon applies."`
+	Tags        []string `toml:"tags,omitempty" desc:"Labels shown after the project name in the picker and matched by the query (array)."`
+	Pinned      bool     `toml:"pinned,omitempty" desc:"Keep this project at the bottom of the picker, nearest the cursor, whatever its history."`
+	OpenWith    []string `toml:"open_with,omitempty" desc:"Where Enter opens this project: any of tmux, code, nvim, zed (array; default [\"tmux\"])."`
+
+	// AllowBroad opts a glob rooted at $HOME or / with few segments out of
+	// the broad-glob match cap.
+	AllowBroad bool `toml:"allow_broad,omitempty" desc:"List every match of a very broad glob (rooted at ~ or / with at most 2 wildcard levels) instead of capping it."`
+
+	// ScanWorktrees overrides the global scan_worktrees for this entry.
+	ScanWorktrees *bool `toml:"scan_worktrees,omitempty" desc:"Look for bare-repo worktrees under this entry's paths, overriding the global scan_worktrees."`
+	// FetchOnOpen overrides the global fetch_on_open for this entry.
+	FetchOnOpen *bool `toml:"fetch_on_open,omitempty" desc:"Run git fetch in the background when this entry's projects are opened, overriding the global fetch_on_open."`
+
+	// invalidKeys lists override keys that had the wrong type; like
+	// displayDepthInvalid they surface as findings and are otherwise ignored.
+	invalidKeys []string
+	// unknownKeys lists keys no ProjectEntry field decodes, reported as
+	// findings like unknown keys elsewhere in the config.
+	unknownKeys []string
 
 	// displayDepthInvalid records that the configured display_depth had the
 	// wrong type (e.g. a string) so the value could not be decoded. Per ADR 0054
@@ -502,6 +629,25 @@ type ProjectEntry struct {
 	displayDepthInvalid bool
 }
 
+// worktree_display values.
+const (
+	WorktreeDisplayDir    = "dir"
+	WorktreeDisplayBranch = "branch"
+	WorktreeDisplayBoth   = "both"
+)
+
+// Where a projects entry's open_with can open it: a tmux session, or an editor
+// workspace instead of or alongside one.
+const (
+	OpenWithTmux = "tmux"
+	OpenWithCode = "code"
+	OpenWithNvim = "nvim"
+	OpenWithZed  = "zed"
+)
+
+// OpenWithTargets lists the valid open_with values.
+var OpenWithTargets = []string{OpenWithTmux, OpenWithCode, OpenWithNvim, OpenWithZed}
+
 // UnmarshalTOML tolerantly decodes a single project entry. A wrong-typed
 // display_depth (the only non-essential field) is recorded as invalid rather
 // than aborting the whole config decode — BurntSushi stops at the first type
@@ -513,6 +659,12 @@ func (p *ProjectEntry) UnmarshalTOML(v interface{}) error {
 	if !ok {
 		return fmt.Errorf("project entry must be a table, got %T", v)
 	}
+	known := projectEntryKeys()
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		if !known[key] {
+			p.unknownKeys = append(p.unknownKeys, key)
+		}
+	}
 	if raw, present := m["path"]; present {
 		s, ok := raw.(string)
 		if !ok {
@@ -530,9 +682,183 @@ func (p *ProjectEntry) UnmarshalTOML(v interface{}) error {
 			p.displayDepthInvalid = true
 		}
 	}
+	if raw, present := m["worktree_display"]; present {
+		// A wrong-typed value is kept as its printed form so
+		// GetWorktreeDisplay reports it like any other unknown value.
+		p.WorktreeDisplay = fmt.Sprint(raw)
+	}
+	for key, dst := range map[string]*string{
+		"session_name": &p.SessionName,
+		"command":      &p.Command,
+		"container":    &p.Container,
+		"workbench":    &p.Workbench,
+		"icon":         &p.Icon,
+	} {
+		if raw, present := m[key]; present {
+			if v, ok := raw.(string); ok {
+				*dst = v
+			} else {
+				p.invalidKeys = append(p.invalidKeys, key)
+			}
+		}
+	}
+	if raw, present := m["pinned"]; present {
+		if v, ok := raw.(bool); ok {
+			p.Pinned = v
+		} else {
+			p.invalidKeys = append(p.invalidKeys, "pinned")
+		}
+	}
+	if raw, present := m["allow_broad"]; present {
+		if v, ok := raw.(bool); ok {
+			p.AllowBroad = v
+		} else {
+			p.invalidKeys = append(p.invalidKeys, "allow_broad")
+		}
+	}
+	if raw, present := m["scan_worktrees"]; present {
+		if v, ok := raw.(bool); ok {
+			p.ScanWorktrees = &v
+		} else {
+			p.invalidKeys = append(p.invalidKeys, "scan_worktrees")
+		}
+	}
+	if raw, present := m["fetch_on_open"]; present {
+		if v, ok := raw.(bool); ok {
+			p.FetchOnOpen = &v
+		} else {
+			p.invalidKeys = append(p.invalidKeys, "fetch_on_open")
+		}
+	}
+	if raw, present := m["open_with"]; present {
+		p.OpenWith = nil
+		list, ok := raw.([]interface{})
+		if name, isString := raw.(string); isString {
+			list, ok = []interface{}{name}, true
+		}
+		for _, v := range list {
+			name, isString := v.(string)
+			if !isString {
+				ok = false
+				break
+			}
+			p.OpenWith = append(p.OpenWith, name)
+		}
+		if !ok {
+			p.OpenWith = nil
+			p.invalidKeys = append(p.invalidKeys, "open_with")
+		}
+	}
+	if raw, present := m["tags"]; present {
+		p.Tags = nil
+		list, ok := raw.([]interface{})
+		for _, v := range list {
+			tag, isString := v.(string)
+			if !isString {
+				ok = false
+				break
+			}
+			p.Tags = append(p.Tags, tag)
+		}
+		if !ok {
+			p.Tags = nil
+			p.invalidKeys = append(p.invalidKeys, "tags")
+		}
+	}
+	slices.Sort(p.invalidKeys)
 	return nil
 }
 
+// projectEntryKeys returns the keys a projects entry accepts, derived by
+// reflection from ProjectEntry's toml tags.
+func projectEntryKeys() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(ProjectEntry{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("toml"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// IsGlob reports whether the entry's path is a glob pattern rather than an
+// exact directory.
+func (p ProjectEntry) IsGlob() bool {
+	return isGlobPattern(p.Path)
+}
+
+// isGlobPattern reports whether path uses any glob syntax doublestar
+// understands: *, ?, [...] classes or {a,b} alternatives. A character escaped
+// with a backslash, as in ~/src/lab\[1\], is literal.
+func isGlobPattern(path string) bool {
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			if globEscapes {
+				i++
+			}
+		case '*', '?', '[', '{':
+			return true
+		}
+	}
+	return false
+}
+
+// globEscapes reports whether a backslash escapes glob syntax in paths. On
+// Windows it is the path separator instead.
+const globEscapes = filepath.Separator != '\\'
+
+// unescapeGlob returns the literal path an exact (non-glob) entry names,
+// without the backslashes escaping its glob characters.
+func unescapeGlob(path string) string {
+	if !globEscapes || !strings.Contains(path, `\`) {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+1 < len(path) {
+			i++
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// overrideFindings reports the entry's unknown and wrong-typed override keys,
+// and a session_name on a glob entry, which is ignored.
+func (p ProjectEntry) overrideFindings() []Finding {
+	var findings []Finding
+	for _, key := range p.unknownKeys {
+		findings = append(findings, Finding{
+			Path:    "projects[]." + key,
+			Message: fmt.Sprintf("projects entry %q has unknown key %q; ignoring it", p.Path, key),
+		})
+	}
+	for _, key := range p.invalidKeys {
+		findings = append(findings, Finding{
+			Path:    "projects[]." + key,
+			Message: fmt.Sprintf("projects entry %q has a wrong-typed %s; ignoring it", p.Path, key),
+		})
+	}
+	if p.SessionName != "" && p.IsGlob() {
+		findings = append(findings, Finding{
+			Path:    "projects[].session_name",
+			Message: fmt.Sprintf("projects entry %q: session_name only applies to exact paths; ignoring it", p.Path),
+		})
+	}
+	for _, target := range p.OpenWith {
+		if !slices.Contains(OpenWithTargets, target) {
+			findings = append(findings, Finding{
+				Path:    "projects[].open_with",
+				Message: fmt.Sprintf("projects entry %q: unknown open_with %q (want %s); ignoring it", p.Path, target, strings.Join(OpenWithTargets, ", ")),
+			})
+		}
+	}
+	return findings
+}
+
 // GetDisplayDepth returns the effective display depth and an error iff the
 // configured display_depth was the wrong type. Per ADR 0054 the caller decides
 // severity: this value is non-essential, so the project dashboard ignores the
@@ -551,6 +877,23 @@ func (p ProjectEntry) GetDisplayDepth() (int, error) {
 	return p.DisplayDepth, nil
 }
 
+// GetWorktreeDisplay returns the effective worktree_display and an error iff
+// the configured value is unknown. Like display_depth it is non-essential: the
+// error carries a Finding for the warning banner while the caller falls back
+// to the returned default ("dir").
+func (p ProjectEntry) GetWorktreeDisplay() (string, error) {
+	switch p.WorktreeDisplay {
+	case "", WorktreeDisplayDir:
+		return WorktreeDisplayDir, nil
+	case WorktreeDisplayBranch, WorktreeDisplayBoth:
+		return p.WorktreeDisplay, nil
+	}
+	return WorktreeDisplayDir, Finding{
+		Path:    "projects[].worktree_display",
+		Message: fmt.Sprintf("projects entry %q has unknown worktree_display %q (want dir, branch or both); using dir", p.Path, p.WorktreeDisplay),
+	}
+}
+
 // Finding is a single config validation problem, keyed to the config path of
 // the offending key (e.g. "effort.opencode.extreme") and carrying a
 // human-readable, file-qualified message. Per ADR 0054 findings are collected
@@ -570,19 +913,37 @@ type Finding struct {
 func (f Finding) Error() string { return f.Message }
 
 type Config struct {
-	Includes              []string             `toml:"includes" desc:"Additional config files to merge in (paths, later wins)."`
+	Includes              []string             `toml:"includes" desc:"Additional config files to merge in (paths; may nest, loaded depth-first, cycles skipped)."`
 	Projects              []ProjectEntry       `toml:"projects" include:"append" desc:"Directories or globs offered in the project picker."`
 	Commands              []UserDefinedCommand `toml:"commands" desc:"User-defined commands surfaced in the picker."`
+	PreviewCommand        string               `toml:"preview_command" desc:"Shell command whose output fills the picker's preview pane ({path}, {name}, {session} placeholders)."`
+	MatcherCommand        string               `toml:"matcher_command" desc:"Shell command that ranks picker results: reads {query, candidates} JSON on stdin, prints a JSON array of indices (best first)."`
+	CloneRoot             string               `toml:"clone_root" desc:"Directory pop clone clones into (default: the base of the first \"<dir>/*\" projects glob)."`
 	ExcludeCurrentSession bool                 `toml:"exclude_current_session" desc:"Hide the current tmux session from the picker."`
 	// Deprecated: use ExcludeCurrentSession. TODO: remove after v1.0.
-	ExcludeCurrentDir      bool            `toml:"exclude_current_dir" desc:"Deprecated: use exclude_current_session."`
-	DisambiguationStrategy string          `toml:"disambiguation_strategy" desc:"How to shorten duplicate display names (first_unique_segment|full_path)."`
-	QuickAccessModifier    string          `toml:"quick_access_modifier" desc:"Modifier for quick-access hotkeys (alt|ctrl|disabled)."`
-	Worktree               *WorktreeConfig `toml:"worktree" desc:"Worktree dashboard behavior ([worktree] table)."`
-	Project                *ProjectConfig  `toml:"project" desc:"Project dashboard behavior ([project] table)."`
+	ExcludeCurrentDir      bool              `toml:"exclude_current_dir" desc:"Deprecated: use exclude_current_session."`
+	DisambiguationStrategy string            `toml:"disambiguation_strategy" desc:"How to shorten duplicate display names (first_unique_segment|full_path)."`
+	QuickAccessModifier    string            `toml:"quick_access_modifier" desc:"Modifier for quick-access hotkeys (alt|ctrl|disabled)."`
+	Keys                   map[string]string `toml:"keys" desc:"Remap built-in picker actions ([keys] table, e.g. kill_session = \"ctrl-q\")."`
+	ShowTips               *bool             `toml:"show_tips" desc:"Show one-time tips in the picker footer (default true)."`
+	Icons                  string            `toml:"icons" desc:"Project-type icons in the project picker (nerdfont|ascii|off, default off)."`
+	GitStatus              bool              `toml:"git_status" desc:"Show each item's git state (● changes, ↑ahead ↓behind) in the project and worktree pickers; one git call per item, run in the background."`
+	ScanWorktrees          *bool             `toml:"scan_worktrees" desc:"Look for bare-repo worktrees under every project path (default true); false skips the check, speeding up large configs."`
+	ScanConcurrency        int               `toml:"scan_concurrency" desc:"How many project paths are checked for bare-repo worktrees at once (0 = default 16); lower it on slow network filesystems."`
+	CacheTTL               string            `toml:"cache_ttl" desc:"Longest a cached glob expansion is reused before the directories are listed again (a Go duration such as \"24h\"; unset = until a directory changes)."`
+	FetchOnOpen            bool              `toml:"fetch_on_open" desc:"Run git fetch --quiet in the background when a project's session is opened or switched to."`
+	FetchOnOpenMinutes     int               `toml:"fetch_on_open_minutes" desc:"Skip fetch_on_open for a project fetched within this many minutes (0 = default 5)."`
+	AttachBehavior         string            `toml:"attach_behavior" desc:"Attaching from outside tmux: attach (default) or detach_others (attach -d, resizing to this terminal)."`
+	OpenBehavior           string            `toml:"open_behavior" desc:"Enter in the project picker: switch (default) to the project's session, or detach-create to only create it, detached."`
+	TmuxCDBusy             string            `toml:"tmux_cd_busy" desc:"What --tmux-cd does when the pane runs something other than a shell: refuse (default), split (open a new pane in the directory) or send (type the cd anyway)."`
+	PopupSwitch            string            `toml:"popup_switch" desc:"Switching from a tmux display-popup: close_popup (default, closes the popup in the same tmux command) or direct."`
+	StatuslineFormat       string            `toml:"statusline_format" desc:"What pop statusline prints ({project}, {repo}, {worktree}, {branch}, {dirty}, {status}, {session} tokens; default \"{project}{dirty}\")."`
+	Worktree               *WorktreeConfig   `toml:"worktree" desc:"Worktree dashboard behavior ([worktree] table)."`
+	Project                *ProjectConfig    `toml:"project" desc:"Project dashboard behavior ([project] table)."`
 	// Deprecated: use Project. TODO: remove at next major release.
 	Select         *ProjectConfig        `toml:"select" desc:"Deprecated: use [project]."`
 	PaneMonitoring *PaneMonitoringConfig `toml:"pane_monitoring" desc:"Pane attention/status monitoring daemon settings ([pane_monitoring] table)."`
+	Theme          *ThemeConfig          `toml:"theme" desc:"TUI colors ([theme] table)."`
 	Dashboard      *DashboardConfig      `toml:"dashboard" desc:"Shared dashboard and cursor behavior ([dashboard] table)."`
 	Task           *TasksConfig          `toml:"tasks" include:"fields" desc:"Task-set execution defaults ([tasks] table)."`
 	// Deprecated: use Task. The [workload] table was renamed to [tasks] in
@@ -592,11 +953,14 @@ type Config struct {
 	Effort   map[string]EffortConfig `toml:"effort" include:"map-first-wins" desc:"Per-agent reasoning-effort ladders ([effort.<agent>] tables)."`
 	// Workbenches is the canonical TOML key for session blueprints.
 	Workbenches []Workbench `toml:"workbenches" include:"append" desc:"Global session blueprints (templates)."`
-	// WorkbenchOpts holds the [workbench] options table (pick_on_create, order).
+	// WorkbenchOpts holds the [workbench] options table (pick_on_create, order,
+	// default).
 	WorkbenchOpts *WorkbenchOptions   `toml:"workbench" include:"fields" desc:"Workbench options ([workbench] table)."`
 	Routines      *RoutinesConfig     `toml:"routines" desc:"Routine settings ([routines] table)."`
 	Queue         *QueueConfig        `toml:"queue" desc:"Queue supervisor settings ([queue] table)."`
 	Updates       *UpdatesConfig      `toml:"updates" desc:"Auto-update behavior ([updates] table)."`
+	Hooks         *HooksConfig        `toml:"hooks" desc:"Shell commands run on session lifecycle events ([hooks] table)."`
+	Events        *EventsConfig       `toml:"events" desc:"Shell commands run on pop events for integrations ([events] table)."`
 	Integrations  *IntegrationsConfig `toml:"integrations" merge:"fields" desc:"AI-agent integration settings ([integrations] table)."`
 	// Repo holds [repo."<path>"] override blocks keyed by any checkout path.
 	// The key is canonicalized (~ expanded, symlinks resolved) at resolution
@@ -612,6 +976,9 @@ type Config struct {
 	Findings []Finding `toml:"-"`
 
 	Warnings []string `toml:"-"` // non-serialized warnings from config loading
+
+	// scripts reads the script commands on first use; see ScriptCommands.
+	scripts *scriptCommandsLoader
 }
 
 // recordFinding appends a finding and mirrors its message into Warnings, so a
@@ -622,6 +989,14 @@ func (c *Config) recordFinding(f Finding) {
 	c.Warnings = append(c.Warnings, f.Message)
 }
 
+// RecordFindings adds findings made for c outside of loading it, such as the
+// glob problems a pop daemon's expansion of the same config ran into.
+func (c *Config) RecordFindings(findings []Finding) {
+	for _, f := range findings {
+		c.recordFinding(f)
+	}
+}
+
 // blockingFindingFor returns the first finding whose config path lies under the
 // given top-level section (an exact match or a "<section>." prefix), or nil. A
 // value getter for that section returns this as its error so the caller decides
@@ -756,7 +1131,7 @@ func LoadRepoConfigWith(d *Deps, repoRoot string) (RepoConfig, error) {
 
 // canonicalPath expands ~ and resolves symlinks, returning a clean absolute path.
 func canonicalPath(d *Deps, path string) string {
-	p := expandHomeWith(d, path)
+	p := expandPathWith(d, path)
 	if r, err := d.FS.EvalSymlinks(p); err == nil {
 		p = r
 	}
@@ -1002,6 +1377,7 @@ func (c *Config) WorkbenchOrder() []string {
 //	4  <trunk>/.pop.toml (→ id-root)      repo in-tree, inherited from the Trunk
 //	5  config.runtime.toml[<wt-path>]     runtime, this worktree (ctrl+w)
 //	6  config.runtime.toml[<trunk-path>]  runtime, inherited from the Trunk
+//	7  config.toml [workbench] default    user central · global fallback
 //	   → none
 //
 // Everything hand-authored (1–4) beats everything runtime (5–6): a repo default
@@ -1014,7 +1390,10 @@ func (c *Config) WorkbenchOrder() []string {
 // .pop.toml overrides the inherited trunk one, and a worktree without inherits
 // trunk's. Layer 4 reuses ADR-0078's Deps.Trunk resolver and its this-is-trunk
 // read-once guard (skipped when the inherited anchor is this very checkout, so a
-// stale name never double-warns).
+// stale name never double-warns). The global [workbench] default (7) is the
+// exception to hand-authored-first: being repo-agnostic it falls below runtime,
+// so a per-checkout ctrl+w choice — including explicit none — still overrides
+// it.
 //
 // The runtime layers stay three-valued (ADR-0078): an explicit-none entry
 // (empty string) short-circuits to flat/prompt here — but only within the
@@ -1107,9 +1486,48 @@ func (c *Config) ResolvePreferredWorkbench(d *Deps, checkoutPath string) (string
 
 // ExpandedPath represents a resolved project path with display metadata
 type ExpandedPath struct {
-	Path         string
-	DisplayDepth int  // number of path segments to show in display name
-	Explicit     bool // true if the path was listed explicitly (not from a glob)
+	Path            string
+	DisplayDepth    int    // number of path segments to show in display name
+	Explicit        bool   // true if the path was listed explicitly (not from a glob)
+	WorktreeDisplay string // how worktrees under this path are named (WorktreeDisplay* constants)
+	SkipWorktrees   bool   // don't look for bare-repo worktrees under this path (scan_worktrees = false)
+	Overrides       ProjectOverrides
+}
+
+// ProjectOverrides are the per-project settings a projects entry passes on to
+// every path it expands to.
+type ProjectOverrides struct {
+	SessionName string // custom tmux session name ("" = derived; exact paths only)
+	Command     string // typed into each new session
+	Container   string // docker container each new session execs into
+	Workbench   string // lowest-precedence preferred Workbench
+	Icon        string
+	Tags        []string
+	Pinned      bool     // sorted nearest the cursor regardless of history
+	OpenWith    []string // known open_with targets; nil = tmux only
+}
+
+// Overrides returns the entry's per-project overrides, dropping a session_name
+// on a glob entry.
+func (p ProjectEntry) Overrides() ProjectOverrides {
+	o := ProjectOverrides{
+		SessionName: p.SessionName,
+		Command:     p.Command,
+		Container:   p.Container,
+		Workbench:   p.Workbench,
+		Icon:        p.Icon,
+		Tags:        p.Tags,
+		Pinned:      p.Pinned,
+	}
+	for _, target := range p.OpenWith {
+		if slices.Contains(OpenWithTargets, target) {
+			o.OpenWith = append(o.OpenWith, target)
+		}
+	}
+	if p.IsGlob() {
+		o.SessionName = ""
+	}
+	return o
 }
 
 // ShouldExcludeCurrentSession returns true if the current session should be
@@ -1138,6 +1556,428 @@ func (c *Config) GetQuickAccessModifier() string {
 	}
 }
 
+// KeyBindingActions lists the built-in picker actions [keys] can remap, in
+// the order problems with them are reported. Navigation, Enter and Esc are
+// fixed.
+var KeyBindingActions = []string{
+	"page_up", "page_down", "clear_input",
+	"delete", "force_delete", "kill_session", "reset", "open_window", "open_editor",
+	"create_project", "yank_path", "create_worktree", "set_preferred_workbench",
+	"browse_worktrees", "rename",
+	"mark", "session_filter", "branch_filter", "sort", "help",
+}
+
+// KeyBindingsForPicker returns the [keys] remaps of known actions to
+// non-empty keys; nil when there are none.
+func (c *Config) KeyBindingsForPicker() map[string]string {
+	var out map[string]string
+	for action, k := range c.Keys {
+		if !slices.Contains(KeyBindingActions, action) || strings.TrimSpace(k) == "" {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[action] = k
+	}
+	return out
+}
+
+// keyBindingFindings reports [keys] entries that name an unknown action or
+// leave the key empty; KeyBindingsForPicker skips them.
+func keyBindingFindings(path string, keys map[string]string) []Finding {
+	var findings []Finding
+	for _, action := range slices.Sorted(maps.Keys(keys)) {
+		switch {
+		case !slices.Contains(KeyBindingActions, action):
+			findings = append(findings, Finding{
+				Path:    "keys." + action,
+				Message: fmt.Sprintf("%s: [keys] has unknown action %q (want one of %s)", path, action, strings.Join(KeyBindingActions, ", ")),
+			})
+		case strings.TrimSpace(keys[action]) == "":
+			findings = append(findings, Finding{
+				Path:    "keys." + action,
+				Message: fmt.Sprintf("%s: [keys] %s has an empty key; keeping the default", path, action),
+			})
+		}
+	}
+	return findings
+}
+
+// PickerUIForMode returns the [<mode>.ui] display defaults of the project
+// ("select" is accepted) or worktree picker; the zero value when unset.
+func (c *Config) PickerUIForMode(mode string) PickerUIConfig {
+	var ui *PickerUIConfig
+	switch mode {
+	case "project", "select":
+		if pc := c.projectConfig(); pc != nil {
+			ui = pc.UI
+		}
+	case "worktree":
+		if c.Worktree != nil {
+			ui = c.Worktree.UI
+		}
+	}
+	if ui == nil {
+		return PickerUIConfig{}
+	}
+	return *ui
+}
+
+// DefaultPopupSize is the width and height of the display-popup --popup
+// opens when [<mode>.ui] does not set them.
+const DefaultPopupSize = "60%"
+
+// PopupSizeForMode returns the display-popup width and height for a picker
+// mode's --popup: its [<mode>.ui] popup_width and popup_height when they are
+// a cell count or a percentage, else DefaultPopupSize.
+func (c *Config) PopupSizeForMode(mode string) (width, height string) {
+	ui := c.PickerUIForMode(mode)
+	return popupSize(ui.PopupWidth), popupSize(ui.PopupHeight)
+}
+
+func popupSize(s string) string {
+	if !ValidPopupSize(s) {
+		return DefaultPopupSize
+	}
+	return s
+}
+
+// ValidPopupSize reports whether s is a size display-popup takes: a positive
+// cell count or percentage.
+func ValidPopupSize(s string) bool {
+	digits := strings.TrimSuffix(s, "%")
+	return strings.Trim(digits, "0123456789") == "" && strings.Trim(digits, "0") != ""
+}
+
+// QuickAccessModifierForMode returns the quick access modifier of a picker
+// mode: its [<mode>.ui] quick_access_modifier when valid, else
+// GetQuickAccessModifier.
+func (c *Config) QuickAccessModifierForMode(mode string) string {
+	switch m := c.PickerUIForMode(mode).QuickAccessModifier; m {
+	case "alt", "ctrl", "disabled":
+		return m
+	}
+	return c.GetQuickAccessModifier()
+}
+
+// Project-type icon styles for the icons setting.
+const (
+	IconsNerdFont = "nerdfont"
+	IconsASCII    = "ascii"
+	IconsOff      = "off"
+)
+
+// GetIcons returns how the project picker shows project-type icons: Nerd
+// Font glyphs, short ASCII labels, or not at all. Defaults to "off" when not
+// set or invalid, since the glyphs need a patched font.
+func (c *Config) GetIcons() string {
+	switch c.Icons {
+	case IconsNerdFont, IconsASCII:
+		return c.Icons
+	default:
+		return IconsOff
+	}
+}
+
+// DefaultStatuslineFormat is what pop statusline prints when
+// statusline_format is unset: the project's picker name, starred when dirty.
+const DefaultStatuslineFormat = "{project}{dirty}"
+
+// GetStatuslineFormat returns the pop statusline format, defaulting to
+// DefaultStatuslineFormat.
+func (c *Config) GetStatuslineFormat() string {
+	if c == nil || c.StatuslineFormat == "" {
+		return DefaultStatuslineFormat
+	}
+	return c.StatuslineFormat
+}
+
+// GetTheme returns the [theme] colors, with any value that is not a valid
+// color cleared so the default applies (themeFindings reports those).
+func (c *Config) GetTheme() ThemeConfig {
+	if c.Theme == nil {
+		return ThemeConfig{}
+	}
+	t := *c.Theme
+	for _, field := range themeFields(&t) {
+		if !validColor(*field.value) {
+			*field.value = ""
+		}
+	}
+	return t
+}
+
+type themeField struct {
+	key   string
+	value *string
+}
+
+// themeFields lists t's colors by their [theme] key.
+func themeFields(t *ThemeConfig) []themeField {
+	return []themeField{
+		{"selected_bg", &t.SelectedBg},
+		{"selected_fg", &t.SelectedFg},
+		{"accent", &t.Accent},
+		{"hint", &t.Hint},
+		{"context", &t.Context},
+	}
+}
+
+// validColor reports whether s is empty, an ANSI 256 index, or a #rgb or
+// #rrggbb hex color.
+func validColor(s string) bool {
+	if s == "" {
+		return true
+	}
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// themeFindings reports [theme] values that are not colors.
+func themeFindings(path string, t *ThemeConfig) []Finding {
+	if t == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, field := range themeFields(t) {
+		if !validColor(*field.value) {
+			findings = append(findings, Finding{
+				Path:    "theme." + field.key,
+				Message: fmt.Sprintf("%s: [theme] %s = %q is not a color (want 0-255 or #rrggbb); using the default", path, field.key, *field.value),
+			})
+		}
+	}
+	return findings
+}
+
+// GetAttachBehavior returns how pop attaches to a session from outside tmux:
+// "attach" joins alongside any other clients, "detach_others" detaches them so
+// the window takes the new terminal's size. Defaults to "attach" when not set
+// or invalid.
+func (c *Config) GetAttachBehavior() string {
+	switch c.AttachBehavior {
+	case "attach", "detach_others":
+		return c.AttachBehavior
+	default:
+		return "attach"
+	}
+}
+
+// Project picker open behaviors for the open_behavior setting.
+const (
+	OpenBehaviorSwitch       = "switch"
+	OpenBehaviorDetachCreate = "detach-create"
+)
+
+// GetOpenBehavior returns what Enter does with the chosen projects: "switch"
+// opens and switches to a project's session, "detach-create" only makes sure
+// each has a session, created detached, so several can be pre-warmed and
+// switched to later. Defaults to "switch" when not set or invalid.
+func (c *Config) GetOpenBehavior() string {
+	if c.OpenBehavior == OpenBehaviorDetachCreate {
+		return c.OpenBehavior
+	}
+	return OpenBehaviorSwitch
+}
+
+// Worktree targets for the [worktree] open_as setting.
+const (
+	WorktreeOpenAsSession = "session"
+	WorktreeOpenAsWindow  = "window"
+)
+
+// WorktreeOpenAs returns where pop worktree --switch opens a worktree:
+// "session" gives each worktree a session of its own, "window" opens it as a
+// window, named after the worktree, in one session for the whole repository.
+// Defaults to "session" when not set or invalid.
+func (c *Config) WorktreeOpenAs() string {
+	if c.Worktree != nil && c.Worktree.OpenAs == WorktreeOpenAsWindow {
+		return WorktreeOpenAsWindow
+	}
+	return WorktreeOpenAsSession
+}
+
+// WorktreeBranchFilter returns the [worktree] branch_filter patterns, nil
+// when none are set.
+func (c *Config) WorktreeBranchFilter() []string {
+	if c.Worktree == nil {
+		return nil
+	}
+	return c.Worktree.BranchFilter
+}
+
+// --tmux-cd behaviors for the tmux_cd_busy setting.
+const (
+	TmuxCDBusyRefuse = "refuse"
+	TmuxCDBusySplit  = "split"
+	TmuxCDBusySend   = "send"
+)
+
+// GetTmuxCDBusy returns what --tmux-cd does when its pane is running a
+// program other than a shell, where typed keys would land in that program:
+// "refuse" fails without sending anything, "split" opens a new pane beside it
+// in the project directory, and "send" types the cd regardless. Defaults to
+// "refuse" when not set or invalid.
+func (c *Config) GetTmuxCDBusy() string {
+	switch c.TmuxCDBusy {
+	case TmuxCDBusySplit, TmuxCDBusySend:
+		return c.TmuxCDBusy
+	}
+	return TmuxCDBusyRefuse
+}
+
+// HookCommand returns the [hooks] command configured for event (one of the
+// Hook* constants), or "" when none is set. The receiver may be nil.
+func (c *Config) HookCommand(event string) string {
+	if c == nil || c.Hooks == nil {
+		return ""
+	}
+	switch event {
+	case HookOnCreate:
+		return c.Hooks.OnCreate
+	case HookOnSwitch:
+		return c.Hooks.OnSwitch
+	case HookOnKill:
+		return c.Hooks.OnKill
+	case HookOnSelect:
+		return c.Hooks.OnSelect
+	}
+	return ""
+}
+
+// EventCommand returns the [events] command configured for the named event,
+// or "" when none is set. The receiver may be nil.
+func (c *Config) EventCommand(event string) string {
+	if c == nil || c.Events == nil {
+		return ""
+	}
+	switch event {
+	case "project_selected":
+		return c.Events.ProjectSelected
+	case "session_created":
+		return c.Events.SessionCreated
+	case "worktree_created":
+		return c.Events.WorktreeCreated
+	case "session_killed":
+		return c.Events.SessionKilled
+	}
+	return ""
+}
+
+// GetPopupSwitch returns how pop switches sessions when it runs inside a tmux
+// display-popup: "close_popup" chains the popup close onto the switch-client
+// so focus lands on the target's active pane, "direct" only switches and lets
+// the popup close when pop exits. Defaults to "close_popup" when not set or
+// invalid.
+func (c *Config) GetPopupSwitch() string {
+	switch c.PopupSwitch {
+	case "close_popup", "direct":
+		return c.PopupSwitch
+	default:
+		return "close_popup"
+	}
+}
+
+// TipsEnabled reports whether the picker shows one-time footer tips. Defaults
+// to true; only an explicit show_tips = false disables them. The receiver may
+// be nil.
+func (c *Config) TipsEnabled() bool {
+	if c == nil || c.ShowTips == nil {
+		return true
+	}
+	return *c.ShowTips
+}
+
+// ScanWorktreesFor reports whether the paths of entry are checked for
+// bare-repo worktrees: the entry's scan_worktrees when set, else the global
+// one, else true.
+func (c *Config) ScanWorktreesFor(entry ProjectEntry) bool {
+	if entry.ScanWorktrees != nil {
+		return *entry.ScanWorktrees
+	}
+	if c == nil || c.ScanWorktrees == nil {
+		return true
+	}
+	return *c.ScanWorktrees
+}
+
+// FetchOnOpenFor reports whether opening the project at path starts a
+// background git fetch: the fetch_on_open of the first projects entry that
+// sets one and lists path or a directory above it (as for a bare repo's
+// worktrees), else the global fetch_on_open.
+func (c *Config) FetchOnOpenFor(path string) bool {
+	return c.FetchOnOpenForWith(defaultDeps, path)
+}
+
+// FetchOnOpenForWith is FetchOnOpenFor using provided dependencies.
+func (c *Config) FetchOnOpenForWith(d *Deps, path string) bool {
+	path = filepath.Clean(path)
+	for _, entry := range c.Projects {
+		if entry.FetchOnOpen == nil {
+			continue
+		}
+		pattern := filepath.Clean(expandPathWith(d, entry.Path))
+		for dir := path; ; dir = filepath.Dir(dir) {
+			if ok, err := doublestar.Match(pattern, dir); err == nil && ok {
+				return *entry.FetchOnOpen
+			}
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	return c.FetchOnOpen
+}
+
+// GlobCacheTTL returns how long a cached glob expansion may be reused, 0
+// meaning no limit: entries then stay valid until a directory they depend on
+// changes. An unparsable or non-positive cache_ttl also means no limit.
+func (c *Config) GlobCacheTTL() time.Duration {
+	if c == nil || strings.TrimSpace(c.CacheTTL) == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(c.CacheTTL))
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// DefaultScanConcurrency is how many project paths are scanned at once when
+// scan_concurrency is unset.
+const DefaultScanConcurrency = 16
+
+// GetScanConcurrency returns how many project paths may be scanned for
+// worktrees at once. Defaults to 16 when unset or invalid.
+func (c *Config) GetScanConcurrency() int {
+	if c == nil || c.ScanConcurrency <= 0 {
+		return DefaultScanConcurrency
+	}
+	return c.ScanConcurrency
+}
+
+// DefaultFetchOnOpenMinutes is how long fetch_on_open waits before fetching
+// the same project again when fetch_on_open_minutes is unset.
+const DefaultFetchOnOpenMinutes = 5
+
+// FetchOnOpenInterval returns how long after a fetch_on_open fetch the same
+// project is not fetched again. Defaults to 5 minutes when unset or invalid.
+func (c *Config) FetchOnOpenInterval() time.Duration {
+	minutes := c.FetchOnOpenMinutes
+	if minutes <= 0 {
+		minutes = DefaultFetchOnOpenMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
 // DismissUnreadInActivePane returns whether unread status should be
 // automatically downgraded to clear when the pane is currently active.
 // Supports both the new and deprecated config keys.
@@ -1267,6 +2107,129 @@ func (c *Config) projectConfig() *ProjectConfig {
 	return c.Select
 }
 
+// ProjectGroupBy returns the project picker grouping mode: "parent" groups
+// items under their parent directory; "" (the default, and any unknown value)
+// keeps the flat recency list.
+func (c *Config) ProjectGroupBy() string {
+	if pc := c.projectConfig(); pc != nil && pc.GroupBy == "parent" {
+		return pc.GroupBy
+	}
+	return ""
+}
+
+// ProjectStream reports whether the project picker opens before every
+// project is expanded, filling in as expansion finishes ([project] stream).
+func (c *Config) ProjectStream() bool {
+	pc := c.projectConfig()
+	return pc != nil && pc.Stream
+}
+
+// [project] history_on_kill values.
+const (
+	HistoryOnKillKeep   = "keep"
+	HistoryOnKillDemote = "demote"
+	HistoryOnKillRemove = "remove"
+)
+
+// ProjectHistoryOnKill returns what killing a project's session from the
+// picker does to its history entry: "keep" leaves it, "demote" drops its
+// frecency until the project is opened again, and "remove" forgets it.
+// Defaults to "keep" when not set or invalid.
+func (c *Config) ProjectHistoryOnKill() string {
+	if pc := c.projectConfig(); pc != nil {
+		switch pc.HistoryOnKill {
+		case HistoryOnKillDemote, HistoryOnKillRemove:
+			return pc.HistoryOnKill
+		}
+	}
+	return HistoryOnKillKeep
+}
+
+// RestoreWindowLast is the [project] restore_window value that returns to the
+// window and pane active when pop last switched away from a session.
+const RestoreWindowLast = "last"
+
+// ProjectRestoreWindow returns the window pop selects on switching back to a
+// project's session: RestoreWindowLast, or a window name or index to always
+// land on. "" leaves tmux's own choice, the session's current window.
+func (c *Config) ProjectRestoreWindow() string {
+	if pc := c.projectConfig(); pc != nil {
+		return strings.TrimSpace(pc.RestoreWindow)
+	}
+	return ""
+}
+
+// DefaultHistoryLimit is the number of history entries kept when [project]
+// history_limit is unset.
+const DefaultHistoryLimit = 500
+
+// ProjectHistoryPruneMissing returns whether opening the picker drops history
+// entries whose directory no longer exists. Defaults to false: a directory on
+// an unmounted drive looks the same as a deleted one.
+func (c *Config) ProjectHistoryPruneMissing() bool {
+	pc := c.projectConfig()
+	return pc != nil && pc.HistoryPruneMissing
+}
+
+// ProjectHistoryLimit returns the most history entries to keep, or 0 for no
+// limit. Unset means DefaultHistoryLimit; any negative value means no limit.
+func (c *Config) ProjectHistoryLimit() int {
+	limit := 0
+	if pc := c.projectConfig(); pc != nil {
+		limit = pc.HistoryLimit
+	}
+	switch {
+	case limit == 0:
+		return DefaultHistoryLimit
+	case limit < 0:
+		return 0
+	}
+	return limit
+}
+
+// ProjectEditor returns the editor alt+enter opens a project in: [project]
+// editor when it names one of the editor open_with targets, else code.
+func (c *Config) ProjectEditor() string {
+	if pc := c.projectConfig(); pc != nil {
+		switch pc.Editor {
+		case OpenWithCode, OpenWithNvim, OpenWithZed:
+			return pc.Editor
+		}
+	}
+	return OpenWithCode
+}
+
+// ProjectNvimServer returns the nvim server address projects open in:
+// [project] nvim_server with ~ expanded, else $NVIM, set inside nvim's own
+// terminal.
+func (c *Config) ProjectNvimServer() string {
+	if pc := c.projectConfig(); pc != nil && pc.NvimServer != "" {
+		return expandPathWith(defaultDeps, pc.NvimServer)
+	}
+	return os.Getenv("NVIM")
+}
+
+// ProjectCreateGitInit returns whether a project created from the picker
+// with ctrl+n gets git init.
+func (c *Config) ProjectCreateGitInit() bool {
+	pc := c.projectConfig()
+	return pc != nil && pc.CreateGitInit
+}
+
+// DefaultGCAfterDays is how long pop gc leaves a detached project session
+// alone when [project] gc_after_days is unset.
+const DefaultGCAfterDays = 7
+
+// ProjectGCAfterDays returns how many days a detached project session may go
+// unused before pop gc offers to kill it. Unset or non-positive means
+// DefaultGCAfterDays.
+func (c *Config) ProjectGCAfterDays() int {
+	if pc := c.projectConfig(); pc != nil && pc.GCAfterDays > 0 {
+		return pc.GCAfterDays
+	}
+	return DefaultGCAfterDays
+}
+
 // UnreadNotificationsEnabled returns whether unread notifications are
 // enabled for the given mode ("project" or "worktree"). "select" is accepted
 // as a deprecated alias for "project". Supports both the new and deprecated
@@ -1291,7 +2254,8 @@ func (c *Config) UnreadNotificationsEnabled(mode string) bool {
 
 // CommandsForMode returns the effective custom commands for the given mode
 // ("project" or "worktree"). "select" is accepted as a deprecated alias for
-// "project". Section-specific commands override global ones matched by key.
+// "project". Section-specific commands override global ones matched by key,
+// and both override script commands.
 func (c *Config) CommandsForMode(mode string) []UserDefinedCommand {
 	byKey := make(map[string]UserDefinedCommand)
 	for _, cmd := range c.Commands {
@@ -1326,9 +2290,101 @@ func (c *Config) CommandsForMode(mode string) []UserDefinedCommand {
 			seen[cmd.Key] = true
 		}
 	}
+	for _, cmd := range c.ScriptCommands() {
+		if !seen[cmd.Key] {
+			result = append(result, cmd)
+			seen[cmd.Key] = true
+		}
+	}
 	return result
 }
 
+// PreviewCommandForMode returns the effective preview command for the given
+// mode ("project" or "worktree"; "select" is a deprecated alias for
+// "project"). A section-level preview_command overrides the global one; ""
+// means no preview pane.
+func (c *Config) PreviewCommandForMode(mode string) string {
+	switch mode {
+	case "project", "select":
+		if pc := c.projectConfig(); pc != nil && pc.PreviewCommand != "" {
+			return pc.PreviewCommand
+		}
+	case "worktree":
+		if c.Worktree != nil && c.Worktree.PreviewCommand != "" {
+			return c.Worktree.PreviewCommand
+		}
+	}
+	return c.PreviewCommand
+}
+
+// on_select pipeline steps. A pipeline is an ordered list of these; run:
+// steps carry a shell command after the prefix.
+const (
+	OnSelectRecordHistory = "record_history"
+	OnSelectEnsureSession = "ensure_session"
+	OnSelectSwitch        = "switch"
+	OnSelectRunPrefix     = "run:"
+)
+
+// OnSelectForMode returns the on_select pipeline configured for a picker mode,
+// or nil when unset, in which case the picker keeps its built-in Enter
+// behavior. The error is non-nil iff the mode's pipeline has a finding (an
+// unknown step or an empty run: command); callers fall back to the built-in
+// behavior, the finding already being in the warning banner (ADR 0054).
+func (c *Config) OnSelectForMode(mode string) ([]string, error) {
+	var steps []string
+	section := mode
+	switch mode {
+	case "project", "select":
+		section = "project"
+		if pc := c.projectConfig(); pc != nil {
+			steps = pc.OnSelect
+		}
+	case "worktree":
+		if c.Worktree != nil {
+			steps = c.Worktree.OnSelect
+		}
+	}
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	if err := c.blockingFindingFor(section + ".on_select"); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// onSelectFindings validates the [project] and [worktree] on_select pipelines.
+func onSelectFindings(path string, cfg *Config) []Finding {
+	var findings []Finding
+	check := func(section string, steps []string) {
+		for i, step := range steps {
+			switch {
+			case step == OnSelectRecordHistory, step == OnSelectEnsureSession, step == OnSelectSwitch:
+			case strings.HasPrefix(step, OnSelectRunPrefix):
+				if strings.TrimSpace(strings.TrimPrefix(step, OnSelectRunPrefix)) == "" {
+					findings = append(findings, Finding{
+						Path:    section + ".on_select",
+						Message: fmt.Sprintf("%s: [%s] on_select step %d has an empty run: command", path, section, i+1),
+					})
+				}
+			default:
+				findings = append(findings, Finding{
+					Path:    section + ".on_select",
+					Message: fmt.Sprintf("%s: [%s] on_select step %q is unknown (want record_history, ensure_session, run:<cmd> or switch)", path, section, step),
+				})
+			}
+		}
+	}
+	if pc := cfg.projectConfig(); pc != nil {
+		check("project", pc.OnSelect)
+	}
+	if cfg.Worktree != nil {
+		check("worktree", cfg.Worktree.OnSelect)
+	}
+	return findings
+}
+
 // DefaultConfigPath returns the default config file path
 func DefaultConfigPath() string {
 	return DefaultConfigPathWith(defaultDeps)
@@ -1372,6 +2428,12 @@ func LoadWith(d *Deps, path string) (*Config, error) {
 	for _, f := range projectEntryFindings(path, cfg.Projects) {
 		cfg.recordFinding(f)
 	}
+	for _, f := range keyBindingFindings(path, cfg.Keys) {
+		cfg.recordFinding(f)
+	}
+	for _, f := range themeFindings(path, cfg.Theme) {
+		cfg.recordFinding(f)
+	}
 	if cfg.Workbenches != nil {
 		tmplFindings, validTemplates := workbenchFindings(path, cfg.Workbenches)
 		for _, f := range tmplFindings {
@@ -1388,6 +2450,9 @@ func LoadWith(d *Deps, path string) (*Config, error) {
 	for _, f := range queueAgentsWarnings(path, md) {
 		cfg.recordFinding(f)
 	}
+	for _, f := range unknownKeyFindings(path, md) {
+		cfg.recordFinding(f)
+	}
 
 	selectSectionUsed := cfg.Select != nil
 	if selectSectionUsed {
@@ -1397,6 +2462,18 @@ func LoadWith(d *Deps, path string) (*Config, error) {
 		}
 	}
 
+	for _, f := range onSelectFindings(path, &cfg) {
+		cfg.recordFinding(f)
+	}
+	if ttl := strings.TrimSpace(cfg.CacheTTL); ttl != "" {
+		if d, err := time.ParseDuration(ttl); err != nil || d <= 0 {
+			cfg.recordFinding(Finding{
+				Path:    "cache_ttl",
+				Message: fmt.Sprintf("cache_ttl %q is not a positive duration such as \"24h\"; ignoring it", cfg.CacheTTL),
+			})
+		}
+	}
+
 	// Deprecation findings for the needs_attention → unread rename.
 	if cfg.PaneMonitoring != nil && cfg.PaneMonitoring.DismissAttentionInActivePane {
 		cfg.recordFinding(Finding{
@@ -1427,7 +2504,6 @@ func LoadWith(d *Deps, path string) (*Config, error) {
 		})
 	}
 
-	configDir := filepath.Dir(path)
 	// Include merge is first-definition-wins across the ADR-0037 whitelist,
 	// driven by the include: tags through the shared walker (ADR-0122). One
 	// policy threads its claimed ledger across every include so the first source
@@ -1435,77 +2511,156 @@ func LoadWith(d *Deps, path string) (*Config, error) {
 	// config so an include never overrides a field the parent already set. The
 	// collision callback reads the walker's dotted key path and rebuilds today's
 	// exact per-section warning strings; whitelist enforcement ("ignored")
-	// stays with includeFileWarnings below, so untagged sections are dropped
+	// stays with includeFileWarnings, so untagged sections are dropped
 	// silently by the walker and warned once by that helper.
-	var currentInclude string
-	includePol := includePolicy(func(keyPath string) {
-		cfg.Warnings = append(cfg.Warnings, includeCollisionMessage(currentInclude, keyPath))
+	loader := &includeLoader{d: d, cfg: &cfg, visited: map[string]bool{filepath.Clean(path): true}}
+	loader.policy = includePolicy(func(keyPath string) {
+		cfg.Warnings = append(cfg.Warnings, includeCollisionMessage(loader.current, keyPath))
 	}, nil)
-	seedIncludeClaims(includePol, &cfg, md)
-	for _, include := range cfg.Includes {
-		expanded := expandHomeWith(d, include)
-		if !filepath.IsAbs(expanded) {
-			expanded = filepath.Join(configDir, expanded)
-		}
-		currentInclude = expanded
+	seedIncludeClaims(loader.policy, &cfg, md)
+	if err := loader.loadAll(cfg.Includes, []string{filepath.Clean(path)}); err != nil {
+		return nil, err
+	}
 
-		var included Config
-		includedMD, err := toml.DecodeFile(expanded, &included)
-		if err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("include file %q not found, skipping", include))
-				continue
-			}
-			return nil, fmt.Errorf("loading include %q: %w", include, err)
+	// After the includes, so their projects entries are checked too.
+	for _, f := range pathExpansionFindings(d, cfg.Projects) {
+		cfg.recordFinding(f)
+	}
+
+	// Read on first use: most commands never bind the scripts' keys.
+	cfg.scripts = &scriptCommandsLoader{d: d, dir: ScriptCommandsDir(path)}
+
+	return &cfg, nil
+}
+
+// maxIncludeDepth bounds include nesting. The parent config is depth 0, its
+// includes depth 1, and so on; an include past the limit is skipped with a
+// warning rather than loaded.
+const maxIncludeDepth = 8
+
+// includeLoader walks the include graph depth-first in declaration order, so
+// the merge order — and therefore which source wins a first-definition
+// collision — is deterministic: a file's own fields merge before those of the
+// files it includes, and sibling includes merge in the order they are listed.
+// Each file is loaded at most once; a file reached again through a different
+// parent is skipped silently, while one that includes an ancestor of itself is
+// reported as a cycle.
+type includeLoader struct {
+	d       *Deps
+	cfg     *Config
+	policy  *mergePolicy
+	visited map[string]bool
+	// current is the include file being merged, read by the collision callback.
+	current string
+}
+
+// loadAll loads each include listed by the file at the tail of chain, resolving
+// relative paths against that file's directory.
+func (l *includeLoader) loadAll(includes []string, chain []string) error {
+	for _, include := range includes {
+		expanded := resolveIncludePathWith(l.d, chain[len(chain)-1], include)
+
+		if slices.Contains(chain, expanded) {
+			cycle := append(slices.Clone(chain), expanded)
+			l.cfg.Warnings = append(l.cfg.Warnings, fmt.Sprintf(
+				"include cycle detected, skipping %q: %s", include, strings.Join(cycle, " -> "),
+			))
+			continue
 		}
-		for _, f := range effortConfigFindings(expanded, includedMD) {
-			cfg.recordFinding(f)
+		if l.visited[expanded] {
+			continue
 		}
-		for _, f := range projectEntryFindings(expanded, included.Projects) {
-			cfg.recordFinding(f)
+		if len(chain) > maxIncludeDepth {
+			l.cfg.Warnings = append(l.cfg.Warnings, fmt.Sprintf(
+				"%s: include %q skipped, nesting deeper than %d levels", chain[len(chain)-1], include, maxIncludeDepth,
+			))
+			continue
 		}
-		for _, f := range repoRenameFindings(expanded, includedMD) {
-			cfg.recordFinding(f)
+		l.visited[expanded] = true
+		if err := l.load(include, expanded, append(slices.Clone(chain), expanded)); err != nil {
+			return err
 		}
-		for _, f := range repoBlockWarnings(expanded, includedMD) {
-			cfg.recordFinding(f)
+	}
+	return nil
+}
+
+// ResolveIncludePath returns the file an includes entry of the config at
+// configPath refers to: ~ expanded, and a relative path taken from the
+// config's directory.
+func ResolveIncludePath(configPath, include string) string {
+	return resolveIncludePathWith(defaultDeps, configPath, include)
+}
+
+func resolveIncludePathWith(d *Deps, configPath, include string) string {
+	expanded := expandPathWith(d, include)
+	if !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(filepath.Dir(configPath), expanded)
+	}
+	return filepath.Clean(expanded)
+}
+
+// load decodes one include file, records its findings, merges it into the
+// config, and then descends into its own includes.
+func (l *includeLoader) load(include, expanded string, chain []string) error {
+	cfg, d := l.cfg, l.d
+	l.current = expanded
+
+	var included Config
+	includedMD, err := toml.DecodeFile(expanded, &included)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("include file %q not found, skipping", include))
+			return nil
 		}
-		// Migrate deprecated [workload] → [tasks] in included file (ADR-0092).
-		// This resolves included.Task before the merge so [workload]-sourced
-		// fields participate; it carries no [tasks.*] keys in includedMD, so the
-		// task subtree merges from metadata synthesized off the resolved struct.
-		hadWorkload := included.Workload != nil
-		for _, f := range workloadMigrationFindings(&included, expanded) {
+		return fmt.Errorf("loading include %q: %w", include, err)
+	}
+	for _, f := range effortConfigFindings(expanded, includedMD) {
+		cfg.recordFinding(f)
+	}
+	for _, f := range projectEntryFindings(expanded, included.Projects) {
+		cfg.recordFinding(f)
+	}
+	for _, f := range repoRenameFindings(expanded, includedMD) {
+		cfg.recordFinding(f)
+	}
+	for _, f := range repoBlockWarnings(expanded, includedMD) {
+		cfg.recordFinding(f)
+	}
+	// Migrate deprecated [workload] → [tasks] in included file (ADR-0092).
+	// This resolves included.Task before the merge so [workload]-sourced
+	// fields participate; it carries no [tasks.*] keys in includedMD, so the
+	// task subtree merges from metadata synthesized off the resolved struct.
+	hadWorkload := included.Workload != nil
+	for _, f := range workloadMigrationFindings(&included, expanded) {
+		cfg.recordFinding(f)
+	}
+	cfg.Warnings = append(cfg.Warnings, includeFileWarnings(expanded, d)...)
+
+	if included.Workbenches != nil {
+		tmplFindings, validTemplates := workbenchFindings(expanded, included.Workbenches)
+		for _, f := range tmplFindings {
 			cfg.recordFinding(f)
 		}
-		cfg.Warnings = append(cfg.Warnings, includeFileWarnings(expanded, &included, d)...)
+		included.Workbenches = validTemplates
+	}
 
-		if included.Workbenches != nil {
-			tmplFindings, validTemplates := workbenchFindings(expanded, included.Workbenches)
-			for _, f := range tmplFindings {
-				cfg.recordFinding(f)
-			}
-			included.Workbenches = validTemplates
-		}
-
-		if hadWorkload {
-			// Merge every whitelisted section except tasks off the include's own
-			// metadata, then the resolved task config off synthesized metadata so
-			// [workload]-migrated fields land too.
-			savedTask := included.Task
-			included.Task = nil
-			mergeWalk(&cfg, &included, includedMD, includePol)
-			included.Task = savedTask
-			if savedTask != nil {
-				taskSrc := Config{Task: savedTask}
-				mergeWalk(&cfg, &taskSrc, taskConfigMetadata(savedTask), includePol)
-			}
-		} else {
-			mergeWalk(&cfg, &included, includedMD, includePol)
+	if hadWorkload {
+		// Merge every whitelisted section except tasks off the include's own
+		// metadata, then the resolved task config off synthesized metadata so
+		// [workload]-migrated fields land too.
+		savedTask := included.Task
+		included.Task = nil
+		mergeWalk(cfg, &included, includedMD, l.policy)
+		included.Task = savedTask
+		if savedTask != nil {
+			taskSrc := Config{Task: savedTask}
+			mergeWalk(cfg, &taskSrc, taskConfigMetadata(savedTask), l.policy)
 		}
+	} else {
+		mergeWalk(cfg, &included, includedMD, l.policy)
 	}
 
-	return &cfg, nil
+	return l.loadAll(included.Includes, chain)
 }
 
 // seedIncludeClaims marks every first-wins include field the parent config
@@ -1545,6 +2700,9 @@ func seedIncludeClaims(policy *mergePolicy, cfg *Config, md toml.MetaData) {
 	if md.IsDefined("workbench", "order") {
 		policy.claim("workbench.order")
 	}
+	if md.IsDefined("workbench", "default") {
+		policy.claim("workbench.default")
+	}
 }
 
 // taskConfigMetadata synthesizes a toml.MetaData whose defined keys mirror the
@@ -1600,6 +2758,8 @@ func includeCollisionMessage(path, keyPath string) string {
 		return fmt.Sprintf("%s: [workbench] pick_on_create skipped, already defined (first definition wins)", path)
 	case "workbench.order":
 		return fmt.Sprintf("%s: [workbench] order skipped, already defined (first definition wins)", path)
+	case "workbench.default":
+		return fmt.Sprintf("%s: [workbench] default skipped, already defined (first definition wins)", path)
 	}
 	return fmt.Sprintf("%s: %s skipped, already defined (first definition wins)", path, keyPath)
 }
@@ -1706,19 +2866,25 @@ func duplicatePaneSpecNames(layout *WorkbenchPaneSpec) []string {
 }
 
 // projectEntryFindings collects a finding for every project entry whose
-// display_depth had the wrong type. Per ADR 0054 these are non-essential: they
-// are keyed under "projects[].display_depth" (deliberately not the "projects"
-// section, so the essential ProjectEntries getter stays non-fatal) and only
-// surface as a warning banner while the entry still resolves at the default
-// depth. The file path is prepended so the banner names the offending file.
+// display_depth or an override had the wrong type, whose worktree_display is
+// unknown, or whose glob path carries a session_name. Per
+// ADR 0054 these are non-essential: they are keyed under "projects[].<key>"
+// (deliberately not the "projects" section, so the essential ProjectEntries
+// getter stays non-fatal) and only surface as a warning banner while the entry
+// still resolves with the default. The file path is prepended so the banner
+// names the offending file.
 func projectEntryFindings(path string, entries []ProjectEntry) []Finding {
 	var findings []Finding
 	for i := range entries {
-		if _, err := entries[i].GetDisplayDepth(); err != nil {
-			f, ok := err.(Finding)
-			if !ok {
-				continue
+		_, depthErr := entries[i].GetDisplayDepth()
+		_, displayErr := entries[i].GetWorktreeDisplay()
+		entryFindings := entries[i].overrideFindings()
+		for _, err := range []error{depthErr, displayErr} {
+			if f, ok := err.(Finding); ok {
+				entryFindings = append(entryFindings, f)
 			}
+		}
+		for _, f := range entryFindings {
 			f.Message = fmt.Sprintf("%s: %s", path, f.Message)
 			findings = append(findings, f)
 		}
@@ -2056,20 +3222,12 @@ func repoBlockWarnings(path string, md toml.MetaData) []Finding {
 }
 
 // includeFileWarnings returns load-time warnings for non-whitelisted top-level
-// keys and nested includes in an included file. Includes carry a fixed whitelist:
+// keys in an included file. Includes carry a fixed whitelist:
 // `projects`, `workbenches`, `[workbench]`, `[tasks]`, `[effort.<agent>]`, and
 // `[repo."<path>"]`.
-func includeFileWarnings(path string, cfg *Config, d *Deps) []string {
+func includeFileWarnings(path string, d *Deps) []string {
 	var warnings []string
 
-	// Check for nested includes (not allowed)
-	if len(cfg.Includes) > 0 {
-		warnings = append(warnings, fmt.Sprintf(
-			"%s: includes field ignored (nested includes not supported, one level only)",
-			path,
-		))
-	}
-
 	// Detect all top-level keys actually present in the include file by parsing
 	// into a generic map. This catches both struct fields and undecoded keys.
 	data, err := d.FS.ReadFile(path)
@@ -2086,12 +3244,12 @@ func includeFileWarnings(path string, cfg *Config, d *Deps) []string {
 	whitelisted := map[string]bool{
 		"projects":    true,
 		"workbenches": true,
-		"workbench":   true, // [workbench] options block (pick_on_create, order)
+		"workbench":   true, // [workbench] options block (pick_on_create, order, default)
 		"repo":        true,
 		"tasks":       true,
 		"workload":    true, // deprecated alias for tasks (ADR-0092)
 		"effort":      true,
-		"includes":    true, // mentioned in includes, so we track it for warning above
+		"includes":    true, // nested includes, loaded by includeLoader
 	}
 
 	// Check for non-whitelisted keys
@@ -2137,64 +3295,259 @@ func (c *Config) ExpandProjects() ([]ExpandedPath, error) {
 
 // ExpandProjectsWith resolves all project paths using provided dependencies
 func (c *Config) ExpandProjectsWith(d *Deps) ([]ExpandedPath, error) {
-	cachePath := DefaultCachePathWith(d)
-	cache := loadGlobCache(d, cachePath)
-	cacheModified := false
-
 	var projects []ExpandedPath
 	seen := make(map[string]bool)
 
-	addProject := func(path string, displayDepth int, explicit bool) {
+	addProject := func(path string, entry ProjectEntry, explicit bool) {
 		if !seen[path] && isDirectoryWith(d, path) {
 			seen[path] = true
-			projects = append(projects, ExpandedPath{Path: path, DisplayDepth: displayDepth, Explicit: explicit})
+			// display_depth and worktree_display are non-essential (ADR
+			// 0054): a bad value falls back to the default here while the
+			// entry still resolves. The finding was already recorded at load
+			// time, so it surfaces in the banner.
+			displayDepth, _ := entry.GetDisplayDepth()
+			worktreeDisplay, _ := entry.GetWorktreeDisplay()
+			projects = append(projects, ExpandedPath{
+				Path:            path,
+				DisplayDepth:    displayDepth,
+				Explicit:        explicit,
+				WorktreeDisplay: worktreeDisplay,
+				SkipWorktrees:   !c.ScanWorktreesFor(entry),
+				Overrides:       entry.Overrides(),
+			})
 		}
 	}
 
+	withGlobCache(d, DefaultCachePathWith(d), func(cache *GlobCache) bool {
+		return c.expandEntries(d, cache, addProject)
+	})
+
+	return removeSubsumedPaths(projects), nil
+}
+
+// ProjectWatchDirs returns the directories whose entries decide what
+// ExpandProjects finds: for a glob, its base and the intermediate directories
+// the glob cache tracks; for an exact path, its parent. A project appears or
+// goes away only through a change in one of them.
+func (c *Config) ProjectWatchDirs() []string {
+	return c.projectWatchDirsWith(defaultDeps)
+}
+
+func (c *Config) projectWatchDirsWith(d *Deps) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
 	for _, entry := range c.Projects {
-		expanded := expandHomeWith(d, entry.Path)
-		// display_depth is non-essential (ADR 0054): a wrong-typed value falls
-		// back to the default here while the entry still resolves. The finding
-		// was already recorded at load time, so it surfaces in the banner.
-		displayDepth, _ := entry.GetDisplayDepth()
+		expanded := expandPathWith(d, entry.Path)
+		if strings.Contains(expanded, "**") {
+			continue // recursive globs are skipped by ExpandProjects too
+		}
+		if !isGlobPattern(expanded) {
+			add(filepath.Dir(filepath.Clean(unescapeGlob(expanded))))
+			continue
+		}
+		base, pat := doublestar.SplitPattern(expanded)
+		if r, err := d.FS.EvalSymlinks(base); err == nil {
+			base = r
+		}
+		tracked := collectDirMtimes(d, base, pat)
+		// A base that does not exist yet still gets watched from its parent,
+		// so creating it is noticed.
+		if len(tracked) == 0 {
+			add(filepath.Dir(base))
+			continue
+		}
+		for _, dir := range slices.Sorted(maps.Keys(tracked)) {
+			add(dir)
+		}
+	}
+	return dirs
+}
+
+// globExpandConcurrency bounds how many projects entries ExpandProjectsWith
+// expands at once. Expansion is filesystem-bound (directory listings and
+// stats), so it pays off on slow or network filesystems; the cap keeps a long
+// projects list from opening hundreds of directories at the same time.
+const globExpandConcurrency = 8
+
+// entryExpansion is what expanding one projects entry found.
+type entryExpansion struct {
+	expanded string // the entry's path with ~ and variables expanded
+	glob     bool
+	matches  []string // glob matches, or the one resolved exact path
+	err      error
+}
+
+// expandEntries resolves each projects entry and hands the directories to add,
+// expanding globs through cache. Entries expand concurrently; their results
+// are then merged in config order, so the projects and findings come out the
+// same as a serial expansion. It reports whether the cache changed.
+func (c *Config) expandEntries(d *Deps, cache *GlobCache, add func(path string, entry ProjectEntry, explicit bool)) bool {
+	ttl := c.GlobCacheTTL()
+	results := make([]entryExpansion, len(c.Projects))
+	var mu sync.Mutex // guards cache and cacheModified
+	cacheModified := false
 
-		// Check if it's a glob pattern (only single * allowed, not **)
+	var g errgroup.Group
+	g.SetLimit(globExpandConcurrency)
+	for i, entry := range c.Projects {
+		expanded := expandPathWith(d, entry.Path)
+
+		// Check if it's a glob pattern (any single-level syntax, not **)
 		if strings.Contains(expanded, "**") {
 			continue // Skip recursive glob patterns
 		}
-		if strings.Contains(expanded, "*") {
-			matches, updated, err := expandGlobCached(d, expanded, cache)
-			if updated {
-				cacheModified = true
+		g.Go(func() error {
+			if !isGlobPattern(expanded) {
+				// Exact path - resolve symlinks
+				resolved := unescapeGlob(expanded)
+				if r, err := d.FS.EvalSymlinks(resolved); err == nil {
+					resolved = r
+				}
+				results[i] = entryExpansion{expanded: expanded, matches: []string{resolved}}
+				return nil
 			}
-			if err != nil {
-				// A malformed glob degrades to a warning rather than aborting:
-				// other entries still resolve, and the picker renders what it
-				// can while naming the bad pattern in the banner (ADR 0054).
-				c.recordFinding(Finding{
-					Path:    "projects[].path",
-					Message: fmt.Sprintf("project path %q is not a valid glob pattern (%v); skipping", entry.Path, err),
-				})
-				continue // Skip invalid patterns
+			mu.Lock()
+			cached, ok := cache.Entries[expanded]
+			mu.Unlock()
+			matches, updated, err := refreshGlob(d, expanded, cached, ok, ttl)
+			if updated != nil {
+				mu.Lock()
+				storeGlobCacheEntry(cache, expanded, updated)
+				cacheModified = true
+				mu.Unlock()
 			}
-			for _, match := range matches {
-				addProject(match, displayDepth, false)
+			results[i] = entryExpansion{expanded: expanded, glob: true, matches: matches, err: err}
+			return nil
+		})
+	}
+	_ = g.Wait() // the workers report failures per entry, never to the group
+
+	for i, entry := range c.Projects {
+		r := results[i]
+		if !r.glob {
+			// Skipped recursive globs leave no matches.
+			for _, path := range r.matches {
+				add(path, entry, true)
 			}
-		} else {
-			// Exact path - resolve symlinks
-			resolved := expanded
-			if r, err := d.FS.EvalSymlinks(expanded); err == nil {
-				resolved = r
+			continue
+		}
+		if r.err != nil {
+			// A malformed glob degrades to a warning rather than aborting:
+			// other entries still resolve, and the picker renders what it
+			// can while naming the bad pattern in the banner (ADR 0054).
+			c.recordFinding(Finding{
+				Path:    "projects[].path",
+				Message: fmt.Sprintf("project path %q is not a valid glob pattern (%v); skipping", entry.Path, r.err),
+			})
+			continue // Skip invalid patterns
+		}
+		matches := r.matches
+		// A glob like ~/* can sweep in thousands of unrelated
+		// directories and hang the popup; it needs an explicit opt-in.
+		if !entry.AllowBroad && isBroadGlobWith(d, r.expanded) {
+			c.recordFinding(Finding{
+				Path:    "projects[].path",
+				Message: fmt.Sprintf("project glob %q is very broad; listing at most %d matches (set allow_broad = true on the entry if intended)", entry.Path, broadGlobMatchCap),
+			})
+			if len(matches) > broadGlobMatchCap {
+				matches = matches[:broadGlobMatchCap]
 			}
-			addProject(resolved, displayDepth, true)
 		}
+		for _, match := range matches {
+			add(match, entry, false)
+		}
+	}
+
+	return cacheModified
+}
+
+// broadGlobMatchCap is how many matches a broad glob (see isBroadGlobWith)
+// lists without allow_broad; enough for a real projects directory, few enough
+// to keep the popup responsive when ~/* picks up everything in $HOME.
+const broadGlobMatchCap = 200
+
+// broadGlobMaxDepth is the most path segments below $HOME or / a glob may
+// have and still count as broad.
+const broadGlobMaxDepth = 2
+
+// isBroadGlobWith reports whether pattern (already ~-expanded) is rooted at /
+// or $HOME with at most broadGlobMaxDepth segments below it, like ~/* or
+// /*/*, so it likely matches unrelated directories by the thousand.
+func isBroadGlobWith(d *Deps, pattern string) bool {
+	pattern = filepath.Clean(pattern)
+	base := pattern
+	for isGlobPattern(base) {
+		base = filepath.Dir(base)
+	}
+	home, _ := d.FS.UserHomeDir()
+	if base != "/" && (home == "" || base != filepath.Clean(home)) {
+		return false
 	}
+	rel, err := filepath.Rel(base, pattern)
+	if err != nil {
+		return false
+	}
+	return len(strings.Split(rel, string(filepath.Separator))) <= broadGlobMaxDepth
+}
 
-	if cacheModified {
-		saveGlobCache(d, cachePath, cache)
+// GetCloneRoot returns the directory `pop clone` clones into: clone_root when
+// set, else the base of the first projects glob of the form "<dir>/*" so the
+// clone lands where the picker already looks. "" means neither is available.
+func (c *Config) GetCloneRoot() string {
+	return c.GetCloneRootWith(defaultDeps)
+}
+
+// GetCloneRootWith is GetCloneRoot using provided dependencies.
+func (c *Config) GetCloneRootWith(d *Deps) string {
+	if c.CloneRoot != "" {
+		return expandPathWith(d, c.CloneRoot)
 	}
+	for _, entry := range c.Projects {
+		dir, found := strings.CutSuffix(entry.Path, "/*")
+		if found && !isGlobPattern(dir) {
+			return unescapeGlob(expandPathWith(d, dir))
+		}
+	}
+	return ""
+}
 
-	return removeSubsumedPaths(projects), nil
+// MatchesProjectPath reports whether path is listed by a projects entry,
+// exactly or through a glob, without touching the filesystem.
+func (c *Config) MatchesProjectPath(path string) bool {
+	return c.MatchesProjectPathWith(defaultDeps, path)
+}
+
+// MatchesProjectPathWith is MatchesProjectPath using provided dependencies.
+func (c *Config) MatchesProjectPathWith(d *Deps, path string) bool {
+	_, ok := c.MatchingProjectEntryWith(d, path)
+	return ok
+}
+
+// MatchingProjectEntry returns the first projects entry that lists path,
+// exactly or through a glob, without touching the filesystem.
+func (c *Config) MatchingProjectEntry(path string) (ProjectEntry, bool) {
+	return c.MatchingProjectEntryWith(defaultDeps, path)
+}
+
+// MatchingProjectEntryWith is MatchingProjectEntry using provided dependencies.
+func (c *Config) MatchingProjectEntryWith(d *Deps, path string) (ProjectEntry, bool) {
+	path = filepath.Clean(path)
+	for _, entry := range c.Projects {
+		pattern := filepath.Clean(expandPathWith(d, entry.Path))
+		if pattern == path {
+			return entry, true
+		}
+		if ok, err := doublestar.Match(pattern, path); err == nil && ok {
+			return entry, true
+		}
+	}
+	return ProjectEntry{}, false
 }
 
 // removeSubsumedPaths filters out paths that are strict parents of other paths
@@ -2224,16 +3577,85 @@ func removeSubsumedPaths(paths []ExpandedPath) []ExpandedPath {
 	return result
 }
 
-// expandHomeWith replaces ~ with the user's home directory
-func expandHomeWith(d *Deps, path string) string {
-	if strings.HasPrefix(path, "~/") {
+// expandPathWith replaces environment variables ($VAR or ${VAR}, and %VAR%
+// with WindowsEnv) and a leading ~ or ~user with their values. A variable
+// that is unset or empty, or a user that can't be looked up, is left as
+// written, so "$WORK_DIR/*" matches nothing rather than globbing from /.
+func expandPathWith(d *Deps, path string) string {
+	expanded, problems := expandPathCheckedWith(d, path)
+	for _, p := range problems {
+		debug.Log("expandPath: %s in %q", p, path)
+	}
+	return expanded
+}
+
+// envVar matches a $VAR or ${VAR} reference.
+var envVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// windowsEnvVar matches a %VAR% reference.
+var windowsEnvVar = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// expandPathCheckedWith is expandPathWith that also returns what it could not
+// expand, one message per unset variable or unknown user.
+func expandPathCheckedWith(d *Deps, path string) (string, []string) {
+	var problems []string
+	if strings.Contains(path, "$") {
+		path = envVar.ReplaceAllStringFunc(path, func(ref string) string {
+			name := strings.Trim(ref, "${}")
+			if value := d.FS.Getenv(name); value != "" {
+				return value
+			}
+			problems = append(problems, "$"+name+" is not set")
+			return ref
+		})
+	}
+	if d.WindowsEnv && strings.Contains(path, "%") {
+		path = windowsEnvVar.ReplaceAllStringFunc(path, func(ref string) string {
+			name := strings.Trim(ref, "%")
+			if value := d.FS.Getenv(name); value != "" {
+				return value
+			}
+			problems = append(problems, ref+" is not set")
+			return ref
+		})
+	}
+	if !strings.HasPrefix(path, "~") {
+		return path, problems
+	}
+	name, rest := path[1:], ""
+	if i := strings.IndexAny(name, "/"+string(filepath.Separator)); i >= 0 {
+		name, rest = name[:i], name[i+1:]
+	}
+	if name == "" {
 		home, err := d.FS.UserHomeDir()
 		if err != nil {
-			debug.Error("expandHome: UserHomeDir: %v", err)
+			debug.Error("expandPath: UserHomeDir: %v", err)
 		}
-		return filepath.Join(home, path[2:])
+		return filepath.Join(home, rest), problems
 	}
-	return path
+	if d.Users == nil {
+		return path, problems
+	}
+	home, err := d.Users.HomeDir(name)
+	if err != nil {
+		return path, append(problems, fmt.Sprintf("~%s can't be resolved (%v)", name, err))
+	}
+	return filepath.Join(home, rest), problems
+}
+
+// pathExpansionFindings warns about projects entries whose path names an unset
+// variable or an unknown user: they match nothing.
+func pathExpansionFindings(d *Deps, projects []ProjectEntry) []Finding {
+	var findings []Finding
+	for _, entry := range projects {
+		if _, problems := expandPathCheckedWith(d, entry.Path); len(problems) > 0 {
+			findings = append(findings, Finding{
+				Path:    "projects[].path",
+				Message: fmt.Sprintf("project path %q: %s; it matches nothing", entry.Path, strings.Join(problems, ", ")),
+			})
+		}
+	}
+	return findings
 }
 
 // expandGlobWithBase expands a glob pattern and returns both the matches
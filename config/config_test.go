@@ -2,13 +2,17 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"os/user"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/glebglazov/pop/internal/deps"
 )
 
@@ -901,6 +905,71 @@ func TestLoadSyntaxErrorIsFatal(t *testing.T) {
 	}
 }
 
+func TestLoadInvalidWorktreeDisplayYieldsFinding(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+	content := `projects = [
+  { path = "~/bad", worktree_display = "ticket" },
+  { path = "~/good", worktree_display = "branch" },
+]
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load returned a fatal error for an unknown worktree_display: %v", err)
+	}
+	if len(cfg.Findings) != 1 || cfg.Findings[0].Path != "projects[].worktree_display" {
+		t.Fatalf("findings = %+v, want one projects[].worktree_display finding", cfg.Findings)
+	}
+	if got, err := cfg.Projects[0].GetWorktreeDisplay(); got != WorktreeDisplayDir || err == nil {
+		t.Errorf("bad entry GetWorktreeDisplay() = (%q, %v), want (dir, finding)", got, err)
+	}
+	if got, err := cfg.Projects[1].GetWorktreeDisplay(); got != WorktreeDisplayBranch || err != nil {
+		t.Errorf("good entry GetWorktreeDisplay() = (%q, %v), want (branch, nil)", got, err)
+	}
+}
+
+func TestLoadProjectOverrides(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `projects = [
+  { path = "/src/api", session_name = "api", command = "make dev", container = "api-dev", workbench = "dev", icon = "*", tags = ["work", "go"], pinned = true },
+  { path = "/src/*", session_name = "shared", tags = "work" },
+  { path = "/src/docs", command = 3, pinned = "yes", bogus_key = 1 },
+]
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load returned a fatal error for bad overrides: %v", err)
+	}
+
+	got := cfg.Projects[0].Overrides()
+	if got.SessionName != "api" || got.Command != "make dev" || got.Container != "api-dev" || got.Workbench != "dev" || got.Icon != "*" || !slices.Equal(got.Tags, []string{"work", "go"}) || !got.Pinned {
+		t.Errorf("Overrides() = %+v, want every override set", got)
+	}
+	// A glob entry drops its session_name; wrong types are ignored.
+	if got := cfg.Projects[1].Overrides(); got.SessionName != "" || got.Tags != nil {
+		t.Errorf("glob Overrides() = %+v, want no session name and no tags", got)
+	}
+	if got := cfg.Projects[2].Overrides(); got.Command != "" || got.Pinned {
+		t.Errorf("Overrides() = %+v, want wrong-typed command and pinned ignored", got)
+	}
+
+	var paths []string
+	for _, f := range cfg.Findings {
+		paths = append(paths, f.Path)
+	}
+	slices.Sort(paths)
+	want := []string{"projects[].bogus_key", "projects[].command", "projects[].pinned", "projects[].session_name", "projects[].tags"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("finding paths = %v, want %v", paths, want)
+	}
+}
+
 // TestLoadInvalidDisplayDepthYieldsFinding asserts that a wrong-typed
 // display_depth no longer aborts Load (ADR 0054): the load succeeds, every
 // other entry survives, the bad entry falls back to the default depth, and the
@@ -1349,6 +1418,76 @@ func TestPopTOMLPresenceDoesNotRegisterProject(t *testing.T) {
 	}
 }
 
+func TestIsBroadGlobWith(t *testing.T) {
+	d := &Deps{FS: &deps.MockFileSystem{
+		UserHomeDirFunc: func() (string, error) { return "/home/user", nil },
+	}}
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"/home/user/*", true},
+		{"/home/user/*/*", true},
+		{"/*/*", true},
+		{"/home/user/*/*/*", false},
+		{"/home/user/Dev/*", false},
+		{"/home/*", false},
+	}
+	for _, tt := range tests {
+		if got := isBroadGlobWith(d, tt.pattern); got != tt.want {
+			t.Errorf("isBroadGlobWith(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestExpandProjectsWith_CapsBroadGlob(t *testing.T) {
+	home := t.TempDir()
+	for i := 0; i < broadGlobMatchCap+5; i++ {
+		if err := os.Mkdir(filepath.Join(home, fmt.Sprintf("dir%03d", i)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	real := deps.NewRealFileSystem()
+	d := &Deps{FS: &deps.MockFileSystem{
+		GetenvFunc: func(key string) string {
+			if key == "XDG_CACHE_HOME" || key == "XDG_DATA_HOME" {
+				return filepath.Join(t.TempDir(), "xdg")
+			}
+			return ""
+		},
+		UserHomeDirFunc:  func() (string, error) { return home, nil },
+		StatFunc:         real.Stat,
+		ReadDirFunc:      real.ReadDir,
+		ReadFileFunc:     real.ReadFile,
+		WriteFileFunc:    real.WriteFile,
+		MkdirAllFunc:     real.MkdirAll,
+		RenameFunc:       real.Rename,
+		DirFSFunc:        real.DirFS,
+		EvalSymlinksFunc: real.EvalSymlinks,
+	}}
+
+	capped := &Config{Projects: []ProjectEntry{{Path: "~/*"}}}
+	projects, err := capped.ExpandProjectsWith(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != broadGlobMatchCap {
+		t.Errorf("got %d projects, want the cap of %d", len(projects), broadGlobMatchCap)
+	}
+	if len(capped.Findings) != 1 || !strings.Contains(capped.Findings[0].Message, "allow_broad") {
+		t.Errorf("findings = %+v, want one pointing at allow_broad", capped.Findings)
+	}
+
+	allowed := &Config{Projects: []ProjectEntry{{Path: "~/*", AllowBroad: true}}}
+	projects, err = allowed.ExpandProjectsWith(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != broadGlobMatchCap+5 || len(allowed.Findings) != 0 {
+		t.Errorf("allow_broad: got %d projects and findings %+v, want every match and no finding", len(projects), allowed.Findings)
+	}
+}
+
 func TestResolveSkillsPrefix(t *testing.T) {
 	empty := ""
 	custom := "my-"
@@ -1590,6 +1729,45 @@ max_tries = 8
 	}
 }
 
+func TestGetCloneRootWith(t *testing.T) {
+	d := &Deps{FS: &deps.MockFileSystem{
+		UserHomeDirFunc: func() (string, error) { return "/home/me", nil },
+	}}
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"clone_root wins", Config{CloneRoot: "~/src", Projects: []ProjectEntry{{Path: "~/Dev/*"}}}, "/home/me/src"},
+		{"first dir glob", Config{Projects: []ProjectEntry{{Path: "~/notes"}, {Path: "~/Dev/*/*"}, {Path: "~/Dev/*"}}}, "/home/me/Dev"},
+		{"no glob", Config{Projects: []ProjectEntry{{Path: "~/notes"}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.GetCloneRootWith(d); got != tt.want {
+				t.Errorf("GetCloneRootWith() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesProjectPathWith(t *testing.T) {
+	d := &Deps{FS: &deps.MockFileSystem{
+		UserHomeDirFunc: func() (string, error) { return "/home/me", nil },
+	}}
+	cfg := Config{Projects: []ProjectEntry{{Path: "~/Dev/*"}, {Path: "/opt/tool"}}}
+	for path, want := range map[string]bool{
+		"/home/me/Dev/app":     true,
+		"/home/me/Dev/app/sub": false,
+		"/opt/tool/":           true,
+		"/opt/other":           false,
+	} {
+		if got := cfg.MatchesProjectPathWith(d, path); got != want {
+			t.Errorf("MatchesProjectPathWith(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
 func TestExpandProjectsWith(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1742,7 +1920,7 @@ func TestExpandProjectsWith(t *testing.T) {
 	}
 }
 
-func TestExpandHomeWith(t *testing.T) {
+func TestExpandPathWith(t *testing.T) {
 	tests := []struct {
 		name     string
 		path     string
@@ -1767,8 +1945,43 @@ func TestExpandHomeWith(t *testing.T) {
 			home:     "/home/user",
 			expected: "relative/path",
 		},
+		{
+			name:     "expands $VAR",
+			path:     "$WORK_DIR/*",
+			home:     "/home/user",
+			expected: "/srv/work/*",
+		},
+		{
+			name:     "expands ${VAR} mid-path",
+			path:     "${XDG_DATA_HOME}/repos/*",
+			home:     "/home/user",
+			expected: "/home/user/.local/share/repos/*",
+		},
+		{
+			name:     "expands a variable holding ~",
+			path:     "$TILDE_ROOT/api",
+			home:     "/home/user",
+			expected: "/home/user/code/api",
+		},
+		{
+			name:     "leaves unset variable as written",
+			path:     "$UNSET_DIR/*",
+			home:     "/home/user",
+			expected: "$UNSET_DIR/*",
+		},
+		{
+			name:     "leaves unset braced variable as written",
+			path:     "${UNSET_DIR}/*",
+			home:     "/home/user",
+			expected: "${UNSET_DIR}/*",
+		},
 	}
 
+	env := map[string]string{
+		"WORK_DIR":      "/srv/work",
+		"XDG_DATA_HOME": "/home/user/.local/share",
+		"TILDE_ROOT":    "~/code",
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			d := &Deps{
@@ -1776,18 +1989,89 @@ func TestExpandHomeWith(t *testing.T) {
 					UserHomeDirFunc: func() (string, error) {
 						return tt.home, nil
 					},
+					GetenvFunc: func(key string) string { return env[key] },
 				},
 			}
 
-			result := expandHomeWith(d, tt.path)
+			result := expandPathWith(d, tt.path)
 
 			if result != tt.expected {
-				t.Errorf("expandHomeWith() = %q, want %q", result, tt.expected)
+				t.Errorf("expandPathWith() = %q, want %q", result, tt.expected)
 			}
 		})
 	}
 }
 
+func TestExpandPathCheckedWith_OtherUsersAndWindowsEnv(t *testing.T) {
+	d := &Deps{
+		FS: &deps.MockFileSystem{
+			UserHomeDirFunc: func() (string, error) { return "/home/me", nil },
+			GetenvFunc: func(key string) string {
+				return map[string]string{"USERPROFILE": `C:\Users\me`, "WORK": "/srv/work"}[key]
+			},
+		},
+		Users: &deps.MockUsers{HomeDirFunc: func(name string) (string, error) {
+			if name == "alice" {
+				return "/home/alice", nil
+			}
+			return "", user.UnknownUserError(name)
+		}},
+		WindowsEnv: true,
+	}
+	tests := []struct {
+		path     string
+		want     string
+		problems int
+	}{
+		{"~", "/home/me", 0},
+		{"~alice/Dev/*", "/home/alice/Dev/*", 0},
+		{"~alice", "/home/alice", 0},
+		{"~bob/Dev", "~bob/Dev", 1},
+		{"%USERPROFILE%", `C:\Users\me`, 0},
+		{"%WORK%/api", "/srv/work/api", 0},
+		{"%UNSET%/api", "%UNSET%/api", 1},
+		{"/srv/100%/x", "/srv/100%/x", 0},
+	}
+	for _, tt := range tests {
+		got, problems := expandPathCheckedWith(d, tt.path)
+		if got != tt.want || len(problems) != tt.problems {
+			t.Errorf("expandPathCheckedWith(%q) = %q, %q; want %q with %d problems", tt.path, got, problems, tt.want, tt.problems)
+		}
+	}
+
+	// Without WindowsEnv a % is just a character, and without Users a ~user
+	// path stays as written.
+	plain := &Deps{FS: d.FS}
+	for _, path := range []string{"%WORK%/api", "~alice/Dev"} {
+		if got, problems := expandPathCheckedWith(plain, path); got != path || len(problems) != 0 {
+			t.Errorf("expandPathCheckedWith(%q) without Users/WindowsEnv = %q, %q; want it unchanged", path, got, problems)
+		}
+	}
+}
+
+func TestLoadWarnsAboutUnresolvablePaths(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	body := "projects = [{ path = \"~nobody/Dev/*\" }, { path = \"$POP_UNSET_ROOT/*\" }, { path = \"/src\" }]\n"
+	if err := os.WriteFile(configPath, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d := &Deps{FS: &deps.MockFileSystem{}, Users: &deps.MockUsers{}}
+
+	cfg, err := LoadWith(d, configPath)
+	if err != nil {
+		t.Fatalf("LoadWith() error: %v", err)
+	}
+	var messages []string
+	for _, f := range cfg.Findings {
+		if f.Path == "projects[].path" {
+			messages = append(messages, f.Message)
+		}
+	}
+	if len(messages) != 2 || !strings.Contains(messages[0], "~nobody can't be resolved") || !strings.Contains(messages[1], "$POP_UNSET_ROOT is not set") {
+		t.Errorf("path findings = %q, want one for the unknown user and one for the unset variable", messages)
+	}
+}
+
 func TestLoadUserDefinedCommands(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1993,6 +2277,70 @@ display_depth = 3
 	}
 }
 
+func TestProjectEntryOpenWith(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+[[projects]]
+path = "/a"
+open_with = ["tmux", "code", "emacs"]
+
+[[projects]]
+path = "/b"
+open_with = "zed"
+
+[[projects]]
+path = "/c"
+open_with = [1]
+`
+	if err := os.WriteFile(configPath, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if got := cfg.Projects[0].Overrides().OpenWith; !slices.Equal(got, []string{"tmux", "code"}) {
+		t.Errorf("/a OpenWith = %q, want the known targets [tmux code]", got)
+	}
+	if got := cfg.Projects[1].Overrides().OpenWith; !slices.Equal(got, []string{"zed"}) {
+		t.Errorf("/b OpenWith = %q, want a single string accepted", got)
+	}
+	if got := cfg.Projects[2].Overrides().OpenWith; got != nil {
+		t.Errorf("/c OpenWith = %q, want a wrong-typed value dropped", got)
+	}
+	var paths []string
+	for _, f := range cfg.Findings {
+		if strings.HasPrefix(f.Path, "projects[].open_with") {
+			paths = append(paths, f.Message)
+		}
+	}
+	if len(paths) != 2 {
+		t.Errorf("open_with findings = %q, want the unknown target and the wrong type", paths)
+	}
+}
+
+func TestProjectEditor(t *testing.T) {
+	tests := []struct {
+		name   string
+		editor string
+		want   string
+	}{
+		{name: "unset", want: OpenWithCode},
+		{name: "zed", editor: "zed", want: OpenWithZed},
+		{name: "nvim", editor: "nvim", want: OpenWithNvim},
+		{name: "tmux is not an editor", editor: "tmux", want: OpenWithCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Project: &ProjectConfig{Editor: tt.editor}}
+			if got := cfg.ProjectEditor(); got != tt.want {
+				t.Errorf("ProjectEditor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestUpdateNoticeEnabled(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2044,6 +2392,152 @@ func TestUpdateNoticeEnabled(t *testing.T) {
 	}
 }
 
+func TestProjectGroupBy(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		want string
+	}{
+		{name: "unset", cfg: &Config{}, want: ""},
+		{name: "parent", cfg: &Config{Project: &ProjectConfig{GroupBy: "parent"}}, want: "parent"},
+		{name: "deprecated select section", cfg: &Config{Select: &ProjectConfig{GroupBy: "parent"}}, want: "parent"},
+		{name: "unknown value is flat", cfg: &Config{Project: &ProjectConfig{GroupBy: "org"}}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.ProjectGroupBy(); got != tt.want {
+				t.Errorf("ProjectGroupBy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectHistoryLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		want int
+	}{
+		{name: "unset", cfg: &Config{}, want: DefaultHistoryLimit},
+		{name: "set", cfg: &Config{Project: &ProjectConfig{HistoryLimit: 50}}, want: 50},
+		{name: "negative is unlimited", cfg: &Config{Project: &ProjectConfig{HistoryLimit: -1}}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.ProjectHistoryLimit(); got != tt.want {
+				t.Errorf("ProjectHistoryLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectGCAfterDays(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		want int
+	}{
+		{name: "unset", cfg: &Config{}, want: DefaultGCAfterDays},
+		{name: "set", cfg: &Config{Project: &ProjectConfig{GCAfterDays: 30}}, want: 30},
+		{name: "negative uses default", cfg: &Config{Project: &ProjectConfig{GCAfterDays: -1}}, want: DefaultGCAfterDays},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.ProjectGCAfterDays(); got != tt.want {
+				t.Errorf("ProjectGCAfterDays() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectHistoryOnKill(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		want string
+	}{
+		{name: "unset", cfg: &Config{}, want: HistoryOnKillKeep},
+		{name: "demote", cfg: &Config{Project: &ProjectConfig{HistoryOnKill: "demote"}}, want: HistoryOnKillDemote},
+		{name: "remove", cfg: &Config{Project: &ProjectConfig{HistoryOnKill: "remove"}}, want: HistoryOnKillRemove},
+		{name: "deprecated select section", cfg: &Config{Select: &ProjectConfig{HistoryOnKill: "demote"}}, want: HistoryOnKillDemote},
+		{name: "unknown value keeps", cfg: &Config{Project: &ProjectConfig{HistoryOnKill: "forget"}}, want: HistoryOnKillKeep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.ProjectHistoryOnKill(); got != tt.want {
+				t.Errorf("ProjectHistoryOnKill() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTipsEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		toml     string
+		expected bool
+	}{
+		{name: "defaults to true when key absent", toml: `projects = [{ path = "~/Dev" }]`, expected: true},
+		{name: "explicit true", toml: "show_tips = true\nprojects = [{ path = \"~/Dev\" }]", expected: true},
+		{name: "explicit false disables", toml: "show_tips = false\nprojects = [{ path = \"~/Dev\" }]", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(configPath, []byte(tt.toml), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			cfg, err := Load(configPath)
+			if err != nil {
+				t.Fatalf("Load() error: %v", err)
+			}
+			if got := cfg.TipsEnabled(); got != tt.expected {
+				t.Errorf("TipsEnabled() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	if !(*Config)(nil).TipsEnabled() {
+		t.Errorf("nil Config TipsEnabled() = false, want true")
+	}
+}
+
+func TestScanWorktreesFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		toml     string
+		expected []bool // per projects entry
+	}{
+		{name: "defaults to true", toml: `projects = [{ path = "~/Dev" }]`, expected: []bool{true}},
+		{name: "global false", toml: "scan_worktrees = false\nprojects = [{ path = \"~/Dev\" }]", expected: []bool{false}},
+		{
+			name:     "entry re-enables",
+			toml:     "scan_worktrees = false\nprojects = [{ path = \"~/Dev/*\" }, { path = \"~/Dev/bare\", scan_worktrees = true }]",
+			expected: []bool{false, true},
+		},
+		{name: "entry disables", toml: `projects = [{ path = "~/Dev", scan_worktrees = false }]`, expected: []bool{false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(configPath, []byte(tt.toml), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			cfg, err := Load(configPath)
+			if err != nil {
+				t.Fatalf("Load() error: %v", err)
+			}
+			for i, entry := range cfg.Projects {
+				if got := cfg.ScanWorktreesFor(entry); got != tt.expected[i] {
+					t.Errorf("ScanWorktreesFor(projects[%d]) = %v, want %v", i, got, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
 func TestDashboardZoomOnSwitch(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2157,26 +2651,284 @@ func TestExpandProjectsRejectsDoubleStarGlob(t *testing.T) {
 	}
 }
 
-func TestGetQuickAccessModifier(t *testing.T) {
-	tests := []struct {
-		name     string
-		value    string
-		expected string
-	}{
-		{"default empty", "", "alt"},
-		{"explicit alt", "alt", "alt"},
-		{"explicit ctrl", "ctrl", "ctrl"},
-		{"explicit disabled", "disabled", "disabled"},
-		{"invalid value", "foo", "alt"},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			cfg := &Config{QuickAccessModifier: tt.value}
-			if got := cfg.GetQuickAccessModifier(); got != tt.expected {
-				t.Errorf("GetQuickAccessModifier() = %q, want %q", got, tt.expected)
-			}
-		})
-	}
+func TestGetQuickAccessModifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"default empty", "", "alt"},
+		{"explicit alt", "alt", "alt"},
+		{"explicit ctrl", "ctrl", "ctrl"},
+		{"explicit disabled", "disabled", "disabled"},
+		{"invalid value", "foo", "alt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{QuickAccessModifier: tt.value}
+			if got := cfg.GetQuickAccessModifier(); got != tt.expected {
+				t.Errorf("GetQuickAccessModifier() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetAttachBehavior(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"default empty", "", "attach"},
+		{"explicit attach", "attach", "attach"},
+		{"explicit detach_others", "detach_others", "detach_others"},
+		{"invalid value", "resize", "attach"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{AttachBehavior: tt.value}
+			if got := cfg.GetAttachBehavior(); got != tt.expected {
+				t.Errorf("GetAttachBehavior() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetOpenBehavior(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"default empty", "", OpenBehaviorSwitch},
+		{"explicit switch", "switch", OpenBehaviorSwitch},
+		{"explicit detach-create", "detach-create", OpenBehaviorDetachCreate},
+		{"invalid value", "detach_create", OpenBehaviorSwitch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{OpenBehavior: tt.value}
+			if got := cfg.GetOpenBehavior(); got != tt.expected {
+				t.Errorf("GetOpenBehavior() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWorktreeOpenAs(t *testing.T) {
+	tests := []struct {
+		name     string
+		worktree *WorktreeConfig
+		expected string
+	}{
+		{"no worktree section", nil, WorktreeOpenAsSession},
+		{"default empty", &WorktreeConfig{}, WorktreeOpenAsSession},
+		{"explicit window", &WorktreeConfig{OpenAs: "window"}, WorktreeOpenAsWindow},
+		{"invalid value", &WorktreeConfig{OpenAs: "tab"}, WorktreeOpenAsSession},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Worktree: tt.worktree}
+			if got := cfg.WorktreeOpenAs(); got != tt.expected {
+				t.Errorf("WorktreeOpenAs() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFetchOnOpenFor(t *testing.T) {
+	var cfg Config
+	if _, err := toml.Decode(`
+fetch_on_open = true
+
+[[projects]]
+path = "/src/vendor/*"
+fetch_on_open = false
+
+[[projects]]
+path = "/src/*"
+`, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	d := &Deps{FS: &deps.MockFileSystem{}}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/src/api", true},
+		{"/src/vendor/lib", false},
+		{"/src/vendor/lib/worktree", false},
+		{"/elsewhere", true},
+	}
+	for _, tt := range tests {
+		if got := cfg.FetchOnOpenForWith(d, tt.path); got != tt.want {
+			t.Errorf("FetchOnOpenFor(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if got := cfg.FetchOnOpenInterval(); got != 5*time.Minute {
+		t.Errorf("FetchOnOpenInterval() = %v, want the 5 minute default", got)
+	}
+}
+
+func TestGetTmuxCDBusy(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"default empty", "", TmuxCDBusyRefuse},
+		{"explicit split", "split", TmuxCDBusySplit},
+		{"explicit send", "send", TmuxCDBusySend},
+		{"invalid value", "prompt", TmuxCDBusyRefuse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{TmuxCDBusy: tt.value}
+			if got := cfg.GetTmuxCDBusy(); got != tt.expected {
+				t.Errorf("GetTmuxCDBusy() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetPopupSwitch(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"default empty", "", "close_popup"},
+		{"explicit close_popup", "close_popup", "close_popup"},
+		{"explicit direct", "direct", "direct"},
+		{"invalid value", "deferred", "close_popup"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{PopupSwitch: tt.value}
+			if got := cfg.GetPopupSwitch(); got != tt.expected {
+				t.Errorf("GetPopupSwitch() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHookCommand(t *testing.T) {
+	var nilCfg *Config
+	if got := nilCfg.HookCommand(HookOnCreate); got != "" {
+		t.Errorf("nil config HookCommand = %q, want empty", got)
+	}
+	cfg := &Config{Hooks: &HooksConfig{OnCreate: "direnv allow", OnKill: "echo bye"}}
+	for event, want := range map[string]string{
+		HookOnCreate: "direnv allow",
+		HookOnKill:   "echo bye",
+		HookOnSwitch: "",
+		"on_detach":  "",
+	} {
+		if got := cfg.HookCommand(event); got != want {
+			t.Errorf("HookCommand(%q) = %q, want %q", event, got, want)
+		}
+	}
+}
+
+func TestEventCommand(t *testing.T) {
+	var nilCfg *Config
+	if got := nilCfg.EventCommand("session_created"); got != "" {
+		t.Errorf("nil config EventCommand = %q, want empty", got)
+	}
+	var cfg Config
+	if _, err := toml.Decode("[events]\nsession_created = \"notify new\"\nworktree_created = \"direnv allow\"\n", &cfg); err != nil {
+		t.Fatal(err)
+	}
+	for event, want := range map[string]string{
+		"session_created":  "notify new",
+		"worktree_created": "direnv allow",
+		"session_killed":   "",
+		"on_create":        "",
+	} {
+		if got := cfg.EventCommand(event); got != want {
+			t.Errorf("EventCommand(%q) = %q, want %q", event, got, want)
+		}
+	}
+}
+
+func TestOnSelectForMode(t *testing.T) {
+	load := func(t *testing.T, body string) *Config {
+		t.Helper()
+		configPath := filepath.Join(t.TempDir(), "config.toml")
+		if err := os.WriteFile(configPath, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	t.Run("unset returns nil", func(t *testing.T) {
+		cfg := load(t, `projects = []`)
+		steps, err := cfg.OnSelectForMode("project")
+		if err != nil || steps != nil {
+			t.Errorf("OnSelectForMode() = %v, %v; want nil, nil", steps, err)
+		}
+	})
+
+	t.Run("per-mode pipelines", func(t *testing.T) {
+		cfg := load(t, `
+[project]
+on_select = ["record_history", "ensure_session", "run:direnv allow", "switch"]
+
+[worktree]
+on_select = ["ensure_session", "switch"]
+`)
+		steps, err := cfg.OnSelectForMode("project")
+		if err != nil || len(steps) != 4 || steps[2] != "run:direnv allow" {
+			t.Errorf("project OnSelectForMode() = %v, %v", steps, err)
+		}
+		steps, err = cfg.OnSelectForMode("worktree")
+		if err != nil || len(steps) != 2 {
+			t.Errorf("worktree OnSelectForMode() = %v, %v", steps, err)
+		}
+		if len(cfg.Warnings) != 0 {
+			t.Errorf("unexpected warnings: %v", cfg.Warnings)
+		}
+	})
+
+	t.Run("unknown step is a finding", func(t *testing.T) {
+		cfg := load(t, `
+[project]
+on_select = ["ensure_session", "teleport"]
+`)
+		if _, err := cfg.OnSelectForMode("project"); err == nil || !strings.Contains(err.Error(), `"teleport"`) {
+			t.Errorf("OnSelectForMode() error = %v, want unknown step teleport", err)
+		}
+		if len(cfg.Warnings) != 1 {
+			t.Errorf("warnings = %v, want the finding mirrored once", cfg.Warnings)
+		}
+		if _, err := cfg.OnSelectForMode("worktree"); err != nil {
+			t.Errorf("worktree must not inherit the project finding: %v", err)
+		}
+	})
+
+	t.Run("empty run command is a finding", func(t *testing.T) {
+		cfg := load(t, `
+[worktree]
+on_select = ["run:  ", "switch"]
+`)
+		if _, err := cfg.OnSelectForMode("worktree"); err == nil || !strings.Contains(err.Error(), "empty run:") {
+			t.Errorf("OnSelectForMode() error = %v, want empty run: command", err)
+		}
+	})
+
+	t.Run("deprecated select section", func(t *testing.T) {
+		cfg := load(t, `
+[select]
+on_select = ["switch"]
+`)
+		if steps, err := cfg.OnSelectForMode("project"); err != nil || len(steps) != 1 {
+			t.Errorf("OnSelectForMode() = %v, %v", steps, err)
+		}
+	})
 }
 
 func TestExpandProjectsDisplayDepth(t *testing.T) {
@@ -2225,6 +2977,103 @@ func TestExpandProjectsSkipsHiddenDirs(t *testing.T) {
 	}
 }
 
+func TestExpandProjectsBracesAndClasses(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	for _, dir := range []string{"work/api", "personal/blog", "archive/old", "apps/app-1", "apps/app-22", "apps/b"} {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+	}
+
+	cfg := &Config{Projects: []ProjectEntry{
+		{Path: filepath.Join(tmpDir, "{work,personal}/*")},
+		{Path: filepath.Join(tmpDir, "apps/app-?")},
+		{Path: filepath.Join(tmpDir, "apps/[a-b]")},
+	}}
+	result, err := cfg.ExpandProjects()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, p := range result {
+		rel, _ := filepath.Rel(tmpDir, p.Path)
+		got = append(got, rel)
+	}
+	slices.Sort(got)
+	want := []string{"apps/app-1", "apps/b", "personal/blog", "work/api"}
+	if !slices.Equal(got, want) {
+		t.Errorf("projects = %v, want %v", got, want)
+	}
+	if !cfg.Projects[0].IsGlob() || !cfg.Projects[1].IsGlob() || !cfg.Projects[2].IsGlob() {
+		t.Error("brace, ? and [...] patterns should count as globs")
+	}
+}
+
+func TestExpandProjectsEscapedGlobIsExact(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	for _, dir := range []string{"lab[1]", "lab1"} {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+	}
+
+	entry := ProjectEntry{Path: filepath.Join(tmpDir, `lab\[1\]`)}
+	if entry.IsGlob() {
+		t.Error("a path with escaped brackets should not count as a glob")
+	}
+	cfg := &Config{Projects: []ProjectEntry{entry}}
+	result, err := cfg.ExpandProjects()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join(tmpDir, "lab[1]"); len(result) != 1 || result[0].Path != want {
+		t.Errorf("projects = %+v, want only %s", result, want)
+	}
+	if !cfg.MatchesProjectPath(filepath.Join(tmpDir, "lab[1]")) {
+		t.Error("the escaped entry should match its literal path")
+	}
+}
+
+func TestExpandProjectsKeepsConfigOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	var entries []ProjectEntry
+	var want []string
+	// More entries than globExpandConcurrency, so they run in several waves.
+	for i := range 3 * globExpandConcurrency {
+		root := fmt.Sprintf("root%02d", i)
+		for _, child := range []string{"b", "a"} {
+			os.MkdirAll(filepath.Join(tmpDir, root, child), 0755)
+		}
+		entries = append(entries, ProjectEntry{Path: filepath.Join(tmpDir, root, "*")})
+		want = append(want, root+"/a", root+"/b")
+		if i == 5 {
+			entries = append(entries, ProjectEntry{Path: filepath.Join(tmpDir, "[bad")})
+		}
+	}
+	os.MkdirAll(filepath.Join(tmpDir, "exact"), 0755)
+	entries = append([]ProjectEntry{{Path: filepath.Join(tmpDir, "exact")}}, entries...)
+	want = append([]string{"exact"}, want...)
+
+	for range 2 { // a cold cache, then a warm one
+		cfg := &Config{Projects: entries}
+		result, err := cfg.ExpandProjects()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, p := range result {
+			rel, _ := filepath.Rel(tmpDir, p.Path)
+			got = append(got, rel)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("projects = %v, want %v", got, want)
+		}
+		if len(cfg.Findings) != 1 || !strings.Contains(cfg.Findings[0].Message, "[bad") {
+			t.Errorf("findings = %+v, want one for the bad glob", cfg.Findings)
+		}
+	}
+}
+
 func TestRemoveSubsumedPaths(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2559,7 +3408,7 @@ projects = [{ path = "/main" }]
 		}
 	})
 
-	t.Run("include with nested includes warns and ignores them", func(t *testing.T) {
+	t.Run("nested includes load depth-first in declaration order", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		writeFile := func(name, content string) string {
 			p := filepath.Join(tmpDir, name)
@@ -2567,13 +3416,15 @@ projects = [{ path = "/main" }]
 			return p
 		}
 
-		writeFile("nested.toml", `projects = [{ path = "/nested" }]`)
+		os.Mkdir(filepath.Join(tmpDir, "sub"), 0755)
+		writeFile("sub/nested.toml", `projects = [{ path = "/nested" }]`)
 		writeFile("extra.toml", `
-includes = ["nested.toml"]
+includes = ["sub/nested.toml"]
 projects = [{ path = "/extra" }]
 `)
+		writeFile("sibling.toml", `projects = [{ path = "/sibling" }]`)
 		configPath := writeFile("config.toml", `
-includes = ["extra.toml"]
+includes = ["extra.toml", "sibling.toml"]
 projects = [{ path = "/main" }]
 `)
 
@@ -2581,20 +3432,107 @@ projects = [{ path = "/main" }]
 		if err != nil {
 			t.Fatalf("Load() error: %v", err)
 		}
-		// Nested includes should not be processed
-		if len(cfg.Projects) != 2 {
-			t.Fatalf("got %d projects, want 2 (nested include should be ignored)", len(cfg.Projects))
+		var got []string
+		for _, p := range cfg.Projects {
+			got = append(got, p.Path)
+		}
+		want := []string{"/main", "/extra", "/nested", "/sibling"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("projects = %v, want %v", got, want)
+		}
+		if len(cfg.Warnings) != 0 {
+			t.Errorf("unexpected warnings: %v", cfg.Warnings)
+		}
+	})
+
+	t.Run("include cycle is skipped with the cycle path in the warning", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeFile := func(name, content string) string {
+			p := filepath.Join(tmpDir, name)
+			os.WriteFile(p, []byte(content), 0644)
+			return p
+		}
+
+		aPath := writeFile("a.toml", `
+includes = ["b.toml"]
+projects = [{ path = "/a" }]
+`)
+		bPath := writeFile("b.toml", `
+includes = ["a.toml", "config.toml"]
+projects = [{ path = "/b" }]
+`)
+		configPath := writeFile("config.toml", `
+includes = ["a.toml"]
+projects = [{ path = "/main" }]
+`)
+
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if len(cfg.Projects) != 3 {
+			t.Fatalf("got %d projects, want 3 (each file loaded once)", len(cfg.Projects))
+		}
+		wantWarnings := []string{
+			fmt.Sprintf("include cycle detected, skipping %q: %s -> %s -> %s -> %s",
+				"a.toml", configPath, aPath, bPath, aPath),
+			fmt.Sprintf("include cycle detected, skipping %q: %s -> %s -> %s -> %s",
+				"config.toml", configPath, aPath, bPath, configPath),
+		}
+		if !reflect.DeepEqual(cfg.Warnings, wantWarnings) {
+			t.Errorf("warnings = %q, want %q", cfg.Warnings, wantWarnings)
+		}
+	})
+
+	t.Run("file included twice without a cycle loads once silently", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeFile := func(name, content string) string {
+			p := filepath.Join(tmpDir, name)
+			os.WriteFile(p, []byte(content), 0644)
+			return p
+		}
+
+		writeFile("shared.toml", `projects = [{ path = "/shared" }]`)
+		writeFile("a.toml", `includes = ["shared.toml"]`)
+		writeFile("b.toml", `includes = ["./shared.toml"]`)
+		configPath := writeFile("config.toml", `includes = ["a.toml", "b.toml"]`)
+
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if len(cfg.Projects) != 1 {
+			t.Errorf("got %d projects, want 1 (shared include loaded once)", len(cfg.Projects))
+		}
+		if len(cfg.Warnings) != 0 {
+			t.Errorf("unexpected warnings: %v", cfg.Warnings)
+		}
+	})
+
+	t.Run("includes nested past the max depth are skipped with a warning", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for i := 1; i <= maxIncludeDepth+1; i++ {
+			content := fmt.Sprintf("includes = [\"%d.toml\"]\nprojects = [{ path = \"/p%d\" }]\n", i+1, i)
+			os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("%d.toml", i)), []byte(content), 0644)
+		}
+		configPath := filepath.Join(tmpDir, "config.toml")
+		os.WriteFile(configPath, []byte(`includes = ["1.toml"]`), 0644)
+
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if len(cfg.Projects) != maxIncludeDepth {
+			t.Errorf("got %d projects, want %d", len(cfg.Projects), maxIncludeDepth)
 		}
-		// Check for warning about nested includes
 		found := false
 		for _, w := range cfg.Warnings {
-			if strings.Contains(w, "extra.toml") && strings.Contains(w, "nested") {
+			if strings.Contains(w, fmt.Sprintf("%d.toml", maxIncludeDepth)) && strings.Contains(w, "nesting deeper than") {
 				found = true
-				break
 			}
 		}
 		if !found {
-			t.Errorf("expected warning about nested includes, got: %v", cfg.Warnings)
+			t.Errorf("expected depth-limit warning, got: %v", cfg.Warnings)
 		}
 	})
 
@@ -3326,6 +4264,36 @@ func TestCommandsForMode(t *testing.T) {
 	})
 }
 
+func TestPreviewCommandForMode(t *testing.T) {
+	cfg := &Config{
+		PreviewCommand: "tree -L 1 {path}",
+		Worktree:       &WorktreeConfig{PreviewCommand: "git -C {path} status"},
+	}
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"project", "tree -L 1 {path}"},
+		{"select", "tree -L 1 {path}"},
+		{"worktree", "git -C {path} status"},
+	}
+	for _, tt := range tests {
+		if got := cfg.PreviewCommandForMode(tt.mode); got != tt.want {
+			t.Errorf("PreviewCommandForMode(%q) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+
+	cfg.Project = &ProjectConfig{PreviewCommand: "ls {path}"}
+	if got := cfg.PreviewCommandForMode("project"); got != "ls {path}" {
+		t.Errorf("[project] preview_command should override global, got %q", got)
+	}
+
+	if got := (&Config{}).PreviewCommandForMode("project"); got != "" {
+		t.Errorf("unset preview_command = %q, want empty", got)
+	}
+}
+
 func TestShouldExcludeCurrentSession(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -3831,6 +4799,64 @@ func TestResolvePreferredWorkbenchPrecedence(t *testing.T) {
 		}
 	})
 
+	t.Run("global workbench default fills a checkout nothing else speaks for", func(t *testing.T) {
+		d := preferredResolverDeps(t)
+		root := t.TempDir()
+		cfg := &Config{
+			Workbenches:   []Workbench{{Name: "gs-dev"}, {Name: "minimal"}},
+			WorkbenchOpts: &WorkbenchOptions{Default: "minimal"},
+		}
+		name, warns := cfg.ResolvePreferredWorkbench(d, root)
+		if name != "minimal" || len(warns) != 0 {
+			t.Fatalf("name=%q warns=%v, want minimal/none (global default)", name, warns)
+		}
+	})
+
+	t.Run("runtime entry beats the global workbench default", func(t *testing.T) {
+		d := preferredResolverDeps(t)
+		root := t.TempDir()
+		if err := SetRuntimePreferredWorkbenchWith(d, root, "gs-dev"); err != nil {
+			t.Fatal(err)
+		}
+		cfg := &Config{
+			Workbenches:   []Workbench{{Name: "gs-dev"}, {Name: "minimal"}},
+			WorkbenchOpts: &WorkbenchOptions{Default: "minimal"},
+		}
+		name, warns := cfg.ResolvePreferredWorkbench(d, root)
+		if name != "gs-dev" || len(warns) != 0 {
+			t.Fatalf("name=%q warns=%v, want gs-dev/none (runtime beats global default)", name, warns)
+		}
+	})
+
+	t.Run("runtime explicit none opts out of the global workbench default", func(t *testing.T) {
+		d := preferredResolverDeps(t)
+		root := t.TempDir()
+		if err := SetRuntimePreferredWorkbenchWith(d, root, ""); err != nil {
+			t.Fatal(err)
+		}
+		cfg := &Config{
+			Workbenches:   []Workbench{{Name: "minimal"}},
+			WorkbenchOpts: &WorkbenchOptions{Default: "minimal"},
+		}
+		name, warns := cfg.ResolvePreferredWorkbench(d, root)
+		if name != "" || len(warns) != 0 {
+			t.Fatalf("name=%q warns=%v, want empty/none (explicit none beats global default)", name, warns)
+		}
+	})
+
+	t.Run("stale global workbench default warns and yields none", func(t *testing.T) {
+		d := preferredResolverDeps(t)
+		root := t.TempDir()
+		cfg := &Config{
+			Workbenches:   []Workbench{{Name: "gs-dev"}},
+			WorkbenchOpts: &WorkbenchOptions{Default: "gone"},
+		}
+		name, warns := cfg.ResolvePreferredWorkbench(d, root)
+		if name != "" || len(warns) != 1 || !strings.Contains(warns[0], `"gone"`) {
+			t.Fatalf("name=%q warns=%v, want empty with one stale warning", name, warns)
+		}
+	})
+
 	t.Run("explicit none short-circuits within the runtime tier", func(t *testing.T) {
 		d := preferredResolverDeps(t)
 		root := t.TempDir()
@@ -5158,3 +6184,173 @@ windows = [{name = "main", layout = {name = "editor", command = "vim"}}]
 		}
 	})
 }
+
+func TestPickerUIForMode(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `quick_access_modifier = "ctrl"
+
+[project.ui]
+cursor_at_end = false
+show_icons = false
+height = 12
+
+[worktree.ui]
+show_context = false
+quick_access_modifier = "disabled"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	project := cfg.PickerUIForMode("project")
+	if project.CursorAtEnd == nil || *project.CursorAtEnd || project.ShowIcons == nil || *project.ShowIcons || project.Height != 12 {
+		t.Errorf("PickerUIForMode(project) = %+v", project)
+	}
+	if project.ShowContext != nil {
+		t.Errorf("project show_context = %v, want unset", *project.ShowContext)
+	}
+	if cfg.PickerUIForMode("select") != project {
+		t.Error("select should read the project table")
+	}
+	worktree := cfg.PickerUIForMode("worktree")
+	if worktree.ShowContext == nil || *worktree.ShowContext || worktree.CursorAtEnd != nil {
+		t.Errorf("PickerUIForMode(worktree) = %+v", worktree)
+	}
+
+	if got := cfg.QuickAccessModifierForMode("project"); got != "ctrl" {
+		t.Errorf("QuickAccessModifierForMode(project) = %q, want the global ctrl", got)
+	}
+	if got := cfg.QuickAccessModifierForMode("worktree"); got != "disabled" {
+		t.Errorf("QuickAccessModifierForMode(worktree) = %q, want disabled", got)
+	}
+}
+
+func TestPickerUIForMode_DeprecatedSelectTable(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("[select.ui]\nshow_context = true\nquick_access_modifier = \"bogus\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := cfg.PickerUIForMode("project").ShowContext; got == nil || !*got {
+		t.Errorf("[select.ui] show_context = %v, want true", got)
+	}
+	if got := cfg.QuickAccessModifierForMode("project"); got != "alt" {
+		t.Errorf("invalid modifier resolved to %q, want the alt default", got)
+	}
+	if got := (&Config{}).PickerUIForMode("worktree"); got != (PickerUIConfig{}) {
+		t.Errorf("unset table = %+v, want the zero value", got)
+	}
+}
+
+func TestKeyBindingsForPicker(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `[keys]
+kill_session = "ctrl-q"
+help = "ctrl+g"
+launch = "ctrl+l"
+reset = ""
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	want := map[string]string{"kill_session": "ctrl-q", "help": "ctrl+g"}
+	if got := cfg.KeyBindingsForPicker(); !maps.Equal(got, want) {
+		t.Errorf("KeyBindingsForPicker() = %v, want %v", got, want)
+	}
+	var paths []string
+	for _, f := range cfg.Findings {
+		paths = append(paths, f.Path)
+	}
+	if !slices.Equal(paths, []string{"keys.launch", "keys.reset"}) {
+		t.Errorf("finding paths = %v, want [keys.launch keys.reset]", paths)
+	}
+	if (&Config{}).KeyBindingsForPicker() != nil {
+		t.Error("KeyBindingsForPicker() without [keys] should be nil")
+	}
+}
+
+func TestGetIcons(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"", IconsOff},
+		{"nerdfont", IconsNerdFont},
+		{"ascii", IconsASCII},
+		{"off", IconsOff},
+		{"emoji", IconsOff},
+	} {
+		if got := (&Config{Icons: tt.in}).GetIcons(); got != tt.want {
+			t.Errorf("GetIcons(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetTheme(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `[theme]
+selected_bg = "236"
+accent = "#5f87ff"
+hint = "grey"
+context = "300"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	want := ThemeConfig{SelectedBg: "236", Accent: "#5f87ff"}
+	if got := cfg.GetTheme(); got != want {
+		t.Errorf("GetTheme() = %+v, want %+v", got, want)
+	}
+	var paths []string
+	for _, f := range cfg.Findings {
+		paths = append(paths, f.Path)
+	}
+	if !slices.Equal(paths, []string{"theme.hint", "theme.context"}) {
+		t.Errorf("finding paths = %v, want [theme.hint theme.context]", paths)
+	}
+	if got := (&Config{}).GetTheme(); got != (ThemeConfig{}) {
+		t.Errorf("GetTheme() without [theme] = %+v", got)
+	}
+}
+
+func TestPopupSizeForMode(t *testing.T) {
+	cfg := &Config{
+		Project:  &ProjectConfig{UI: &PickerUIConfig{PopupWidth: "80%", PopupHeight: "30"}},
+		Worktree: &WorktreeConfig{UI: &PickerUIConfig{PopupWidth: "wide", PopupHeight: "0%"}},
+	}
+	if w, h := cfg.PopupSizeForMode("project"); w != "80%" || h != "30" {
+		t.Errorf("project popup = %s x %s, want 80%% x 30", w, h)
+	}
+	if w, h := cfg.PopupSizeForMode("worktree"); w != DefaultPopupSize || h != DefaultPopupSize {
+		t.Errorf("invalid worktree popup = %s x %s, want the default", w, h)
+	}
+	if w, h := (&Config{}).PopupSizeForMode("project"); w != DefaultPopupSize || h != DefaultPopupSize {
+		t.Errorf("unset popup = %s x %s, want the default", w, h)
+	}
+}
+
+func TestGetScanConcurrency(t *testing.T) {
+	var nilCfg *Config
+	if got := nilCfg.GetScanConcurrency(); got != DefaultScanConcurrency {
+		t.Errorf("nil config GetScanConcurrency() = %d, want %d", got, DefaultScanConcurrency)
+	}
+	for value, want := range map[int]int{0: DefaultScanConcurrency, -3: DefaultScanConcurrency, 4: 4} {
+		if got := (&Config{ScanConcurrency: value}).GetScanConcurrency(); got != want {
+			t.Errorf("GetScanConcurrency(%d) = %d, want %d", value, got, want)
+		}
+	}
+}
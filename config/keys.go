@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+
+	"github.com/BurntSushi/toml"
 )
 
 // ConfigScope identifies one of pop's config surfaces for key introspection.
@@ -252,3 +256,77 @@ func tomlTypeName(t reflect.Type) string {
 		return t.Kind().String()
 	}
 }
+
+// unknownKeySkip lists the undecoded keys other load-time findings already
+// explain: the [effort] ladder checks, the [repo] block checks and the
+// renamed-key tripwires.
+var unknownKeySkip = []string{"effort", "repo", "queue.agents", "worktree_ready", "execution_base", "queue_base"}
+
+// unknownKeyFindings reports the keys set in the config file at path that no
+// setting decodes — typos and removed settings, which would otherwise load
+// silently ignored — suggesting the closest legal key in the same table. An
+// unknown table is reported once, not key by key.
+func unknownKeyFindings(path string, md toml.MetaData) []Finding {
+	var findings []Finding
+	var reported []string
+	covered := func(k string, prefixes []string) bool {
+		return slices.ContainsFunc(prefixes, func(p string) bool { return k == p || strings.HasPrefix(k, p+".") })
+	}
+	for _, key := range md.Undecoded() {
+		k := key.String()
+		if covered(k, unknownKeySkip) || covered(k, reported) {
+			continue
+		}
+		reported = append(reported, k)
+		msg := fmt.Sprintf("%s: unknown key %q is ignored", path, k)
+		if suggestion := closestKey(key); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		findings = append(findings, Finding{Path: "unknown_key", Message: msg})
+	}
+	return findings
+}
+
+// closestKey returns the legal key of the global scope, in the same table as
+// key, within two edits of key's last segment, or "" when there is none.
+func closestKey(key toml.Key) string {
+	parent, name := key[:len(key)-1], key[len(key)-1]
+	var docs []ConfigKeyDoc
+	if len(parent) == 0 {
+		docs, _ = ScopeKeyDocs(ScopeGlobal)
+	} else {
+		docs, _, _, _ = TableKeyDocs(ScopeGlobal, parent.String(), false)
+	}
+	best, bestDistance := "", 3
+	for _, doc := range docs {
+		candidate := doc.Key[strings.LastIndex(doc.Key, ".")+1:]
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return append(slices.Clone(parent), best).String()
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -247,3 +250,70 @@ func TestTomlTypeName(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadWarnsAboutUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	body := `disambiguation_stratgy = "full_path"
+bogus = 1
+projects = [{ path = "~/src" }]
+
+[keys]
+kill_session = "ctrl-q"
+
+[project]
+nope = true
+
+[plugins.foo]
+a = 1
+b = 2
+
+[effort.opencode]
+extreme = [{ model = "x" }]
+`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var got []string
+	for _, f := range cfg.Findings {
+		if f.Path == "unknown_key" {
+			got = append(got, f.Message)
+		}
+	}
+	want := []string{
+		path + `: unknown key "disambiguation_stratgy" is ignored (did you mean "disambiguation_strategy"?)`,
+		path + `: unknown key "bogus" is ignored`,
+		path + `: unknown key "project.nope" is ignored`,
+		path + `: unknown key "plugins.foo" is ignored`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unknown key findings = %q, want %q", got, want)
+	}
+	for _, w := range want {
+		if !slices.Contains(cfg.Warnings, w) {
+			t.Errorf("Warnings missing %q", w)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"icons", "icons", 0},
+		{"icon", "icons", 1},
+		{"stratgy", "strategy", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -192,8 +192,11 @@ type preferredSource struct {
 //	4  <trunk-or-id-root>/.pop.toml       hand-authored, in-tree, inherited
 //	5  config.runtime.toml[<wt-path>]     runtime, this worktree
 //	6  config.runtime.toml[<trunk-path>]  runtime, inherited from the Trunk
+//	7  config.toml [workbench] default    hand-authored, central, global fallback
 //
-// Layer 2 (config.toml global keys) has no home for this key and is omitted.
+// Layer 2 (config.toml global keys) has no home for this key and is omitted;
+// the global [workbench] default sits below every repo-specific layer instead,
+// so it only fills checkouts nothing else speaks for.
 // Layer 4 is dropped when its anchor is this very checkout, and layer 6 when the
 // trunk is absent or is this checkout — the read-once guard, so a stale name is
 // never double-warned by re-reading the same anchor.
@@ -224,6 +227,10 @@ func (e *repoScopeEnumerator) preferredSources() []preferredSource {
 		}
 	}
 
+	if e.cfg != nil && e.cfg.WorkbenchOpts != nil {
+		sources = append(sources, preferredSource{name: e.cfg.WorkbenchOpts.Default}) // layer 7
+	}
+
 	return sources
 }
 
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/glebglazov/pop/internal/shell"
+)
+
+// ScriptCommandsDir returns the directory scanned for script commands for
+// the config file at path: commands/ beside it, ~/.config/pop/commands for
+// the default config.
+func ScriptCommandsDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "commands")
+}
+
+// scriptCommandsLoader reads a config's script commands once, when they are
+// first asked for.
+type scriptCommandsLoader struct {
+	d        *Deps
+	dir      string
+	once     sync.Once
+	commands []UserDefinedCommand
+}
+
+// ScriptCommands returns the user-defined commands read from the executable
+// scripts in ScriptCommandsDir, bound in both pickers below any [[commands]]
+// or section command with the same key. Load leaves the scripts unread: the
+// first call reads them and records their problems as findings, so a caller
+// showing Warnings asks for the commands first.
+func (c *Config) ScriptCommands() []UserDefinedCommand {
+	if c.scripts == nil {
+		return nil
+	}
+	c.scripts.once.Do(func() {
+		scripts, findings := loadScriptCommandsWith(c.scripts.d, c.scripts.dir)
+		for _, f := range findings {
+			c.recordFinding(f)
+		}
+		for _, sc := range scripts {
+			if slices.ContainsFunc(c.Commands, func(cc UserDefinedCommand) bool { return cc.Key == sc.Key }) {
+				c.recordFinding(Finding{
+					Path:    "commands",
+					Message: fmt.Sprintf("script command %s: key %s is already bound by [[commands]]; skipped", sc.Command, sc.Key),
+				})
+				continue
+			}
+			c.scripts.commands = append(c.scripts.commands, sc)
+		}
+	})
+	return c.scripts.commands
+}
+
+// loadScriptCommandsWith reads each executable file in dir as a user-defined
+// command running that file, configured by its header comments (see
+// parseScriptHeader). Files are taken in name order; a script without a key,
+// with a malformed header value, or repeating an earlier script's key is
+// skipped with a finding. A missing dir yields nothing.
+func loadScriptCommandsWith(d *Deps, dir string) ([]UserDefinedCommand, []Finding) {
+	entries, err := d.FS.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []Finding{{Path: "commands", Message: fmt.Sprintf("script commands: %v", err)}}
+	}
+
+	var commands []UserDefinedCommand
+	var findings []Finding
+	owner := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		info, err := d.FS.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		data, err := d.FS.ReadFile(path)
+		if err != nil {
+			findings = append(findings, Finding{Path: "commands." + name, Message: fmt.Sprintf("%s: %v", path, err)})
+			continue
+		}
+		cmd, err := parseScriptHeader(data)
+		if err == nil && cmd.Key == "" {
+			err = errors.New(`no "# key:" header line`)
+		}
+		if err == nil && owner[cmd.Key] != "" {
+			err = fmt.Errorf("key %s is already taken by %s", cmd.Key, owner[cmd.Key])
+		}
+		if err != nil {
+			findings = append(findings, Finding{Path: "commands." + name, Message: fmt.Sprintf("%s: %v; skipped", path, err)})
+			continue
+		}
+		owner[cmd.Key] = name
+		if cmd.Label == "" {
+			cmd.Label = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		cmd.Command = shell.Quote(path)
+		commands = append(commands, cmd)
+	}
+	return commands, findings
+}
+
+// parseScriptHeader reads the "# name: value" comment lines at the top of a
+// script, after any shebang, up to the first line that is neither a comment
+// nor blank. key, label, hint, hint_bar, exit and multi set the command's
+// fields of the same name; other names are ordinary comments.
+func parseScriptHeader(data []byte) (UserDefinedCommand, error) {
+	var cmd UserDefinedCommand
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if i == 0 && strings.HasPrefix(line, "#!") || line == "" {
+			continue
+		}
+		comment, ok := strings.CutPrefix(line, "#")
+		if !ok {
+			break
+		}
+		name, value, found := strings.Cut(comment, ":")
+		if !found {
+			continue
+		}
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		var err error
+		switch name {
+		case "key":
+			cmd.Key = value
+		case "label":
+			cmd.Label = value
+		case "hint":
+			cmd.Hint = value
+		case "hint_bar":
+			cmd.HintBar, err = strconv.ParseBool(value)
+		case "exit":
+			cmd.Exit, err = strconv.ParseBool(value)
+		case "multi":
+			cmd.Multi, err = strconv.ParseBool(value)
+		}
+		if err != nil {
+			return cmd, fmt.Errorf("%s: %q is not true or false", name, value)
+		}
+	}
+	return cmd, nil
+}
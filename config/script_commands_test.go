@@ -0,0 +1,158 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+	"github.com/glebglazov/pop/internal/shell"
+)
+
+func TestParseScriptHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    UserDefinedCommand
+		wantErr bool
+	}{
+		{
+			name:   "all fields",
+			script: "#!/bin/sh\n# key: ctrl-t\n# label: run tests\n# hint: ⌃T\n# hint_bar: true\n# exit: false\n# multi: true\nmake test\n# key: ctrl-x\n",
+			want:   UserDefinedCommand{Key: "ctrl-t", Label: "run tests", Hint: "⌃T", HintBar: true, Multi: true},
+		},
+		{
+			name:   "ordinary comments and blank lines",
+			script: "#!/usr/bin/env bash\n\n# Runs the tests.\n#\n# Key: alt-t\n# usage: see README\necho\n",
+			want:   UserDefinedCommand{Key: "alt-t"},
+		},
+		{
+			name:    "bad bool",
+			script:  "# key: ctrl-t\n# exit: sometimes\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseScriptHeader([]byte(tt.script))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseScriptHeader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseScriptHeader() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeScript(t *testing.T, dir, name, content string, perm os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadWith_ScriptCommands(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.toml")
+	writeScript(t, dir, "config.toml", `[[commands]]
+key = "ctrl-o"
+label = "open"
+command = "open ."
+
+[[worktree.commands]]
+key = "ctrl-t"
+label = "worktree tests"
+command = "make test"
+`, 0o644)
+	scripts := filepath.Join(dir, "commands")
+	if err := os.Mkdir(scripts, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := writeScript(t, scripts, "run-tests.sh", "#!/bin/sh\n# key: ctrl-t\n# label: run tests\nmake test\n", 0o755)
+	lint := writeScript(t, scripts, "lint", "#!/bin/sh\n# key: ctrl-l\n# exit: true\nmake lint\n", 0o755)
+	writeScript(t, scripts, "README", "# key: ctrl-r\n", 0o644)
+	writeScript(t, scripts, "nokey", "#!/bin/sh\necho\n", 0o755)
+	writeScript(t, scripts, "shadowed", "#!/bin/sh\n# key: ctrl-o\n", 0o755)
+	writeScript(t, scripts, "zz-duplicate", "#!/bin/sh\n# key: ctrl-l\n", 0o755)
+
+	cfg, err := LoadWith(DefaultDeps(), cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []UserDefinedCommand{
+		{Key: "ctrl-l", Label: "lint", Command: shell.Quote(lint), Exit: true},
+		{Key: "ctrl-t", Label: "run tests", Command: shell.Quote(tests)},
+	}
+	if len(cfg.ScriptCommands()) != len(want) {
+		t.Fatalf("ScriptCommands = %+v, want %+v", cfg.ScriptCommands(), want)
+	}
+	for i := range want {
+		if cfg.ScriptCommands()[i] != want[i] {
+			t.Errorf("ScriptCommands[%d] = %+v, want %+v", i, cfg.ScriptCommands()[i], want[i])
+		}
+	}
+
+	warnings := strings.Join(cfg.Warnings, "\n")
+	for _, name := range []string{"nokey", "shadowed", "zz-duplicate"} {
+		if !strings.Contains(warnings, name) {
+			t.Errorf("warnings do not mention %s:\n%s", name, warnings)
+		}
+	}
+	if strings.Contains(warnings, "README") {
+		t.Errorf("non-executable file reported:\n%s", warnings)
+	}
+
+	labels := func(mode string) []string {
+		var got []string
+		for _, c := range cfg.CommandsForMode(mode) {
+			got = append(got, c.Label)
+		}
+		return got
+	}
+	if got := strings.Join(labels("project"), ","); got != "open,lint,run tests" {
+		t.Errorf("project commands = %s, want TOML commands then scripts", got)
+	}
+	if got := strings.Join(labels("worktree"), ","); got != "open,worktree tests,lint" {
+		t.Errorf("worktree commands = %s, want [[worktree.commands]] to override the script's key", got)
+	}
+}
+
+func TestLoadWith_ReadsScriptCommandsOnFirstUse(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeScript(t, dir, "config.toml", "", 0o644)
+	if err := os.Mkdir(filepath.Join(dir, "commands"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeScript(t, filepath.Join(dir, "commands"), "lint", "#!/bin/sh\n# key: ctrl-l\n", 0o755)
+
+	real := deps.NewRealFileSystem()
+	listed := 0
+	d := DefaultDeps()
+	d.FS = &deps.MockFileSystem{
+		ReadFileFunc: real.ReadFile,
+		StatFunc:     real.Stat,
+		ReadDirFunc: func(path string) ([]os.DirEntry, error) {
+			if path == filepath.Join(dir, "commands") {
+				listed++
+			}
+			return real.ReadDir(path)
+		},
+	}
+
+	cfg, err := LoadWith(d, cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed != 0 {
+		t.Fatalf("Load read the commands dir %d times, want it left for first use", listed)
+	}
+	cfg.ScriptCommands()
+	if got := cfg.ScriptCommands(); len(got) != 1 || listed != 1 {
+		t.Errorf("ScriptCommands() = %+v after %d reads, want the script read once", got, listed)
+	}
+}
@@ -0,0 +1,143 @@
+// Package events is pop's in-process event bus. The cmd layer publishes what
+// it does to projects, sessions and worktrees; integrations (time trackers,
+// notifiers, dashboards) subscribe to react without patching the cmd layer.
+// The [events] config table maps the same events to shell commands.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Kind names an event. The values double as the [events] config keys.
+type Kind string
+
+const (
+	// ProjectSelected fires when a project or worktree is chosen in a picker,
+	// before pop opens it.
+	ProjectSelected Kind = "project_selected"
+	// SessionCreated fires after pop creates a tmux session.
+	SessionCreated Kind = "session_created"
+	// WorktreeCreated fires after pop adds a git worktree.
+	WorktreeCreated Kind = "worktree_created"
+	// SessionKilled fires after pop kills a tmux session.
+	SessionKilled Kind = "session_killed"
+)
+
+// Kinds lists every event kind, in the order the docs present them.
+var Kinds = []Kind{ProjectSelected, SessionCreated, WorktreeCreated, SessionKilled}
+
+// Event is one occurrence of a Kind.
+type Event struct {
+	Kind Kind
+	// SessionName is the tmux session the event concerns, or the session
+	// the project would get when it has none yet.
+	SessionName string
+	// Path is the project or worktree directory; "" for a standalone
+	// session with no known directory.
+	Path string
+	Time time.Time
+}
+
+// Env returns the event as the environment variables a shell command
+// subscribed through [events] receives.
+func (e Event) Env() []string {
+	return []string{
+		"POP_EVENT=" + string(e.Kind),
+		"POP_SESSION_NAME=" + e.SessionName,
+		"POP_PROJECT_PATH=" + e.Path,
+	}
+}
+
+// Handler reacts to an event. Handlers run synchronously on the publishing
+// goroutine, so a slow one delays pop; start a goroutine for long work.
+type Handler func(Event)
+
+type subscription struct {
+	id   int
+	kind Kind // "" subscribes to every kind
+	h    Handler
+}
+
+// Bus delivers published events to its subscribers. The zero value is ready
+// to use and safe for concurrent use.
+type Bus struct {
+	mu     sync.Mutex
+	nextID int
+	subs   []subscription
+}
+
+// Subscribe registers h for events of kind and returns a func that removes it.
+func (b *Bus) Subscribe(kind Kind, h Handler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, kind: kind, h: h})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SubscribeAll registers h for events of every kind.
+func (b *Bus) SubscribeAll(h Handler) (unsubscribe func()) {
+	return b.Subscribe("", h)
+}
+
+// Publish delivers e to the handlers subscribed to its kind, in the order
+// they subscribed. A zero Time is set to now.
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.mu.Lock()
+	var handlers []Handler
+	for _, s := range b.subs {
+		if s.kind == "" || s.kind == e.Kind {
+			handlers = append(handlers, s.h)
+		}
+	}
+	b.mu.Unlock()
+	// Outside the lock, so a handler may subscribe or unsubscribe.
+	for _, h := range handlers {
+		h(e)
+	}
+}
+
+// HasSubscribers reports whether publishing an event of kind reaches any
+// handler.
+func (b *Bus) HasSubscribers(kind Kind) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, s := range b.subs {
+		if s.kind == "" || s.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Default is the bus pop publishes to.
+var Default = &Bus{}
+
+// Subscribe registers h for events of kind on Default.
+func Subscribe(kind Kind, h Handler) (unsubscribe func()) {
+	return Default.Subscribe(kind, h)
+}
+
+// SubscribeAll registers h for events of every kind on Default.
+func SubscribeAll(h Handler) (unsubscribe func()) {
+	return Default.SubscribeAll(h)
+}
+
+// Publish delivers e to the subscribers of Default.
+func Publish(e Event) {
+	Default.Publish(e)
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBus_PublishReachesMatchingSubscribers(t *testing.T) {
+	var b Bus
+	var got []string
+	b.Subscribe(SessionCreated, func(e Event) { got = append(got, "created:"+e.SessionName) })
+	b.Subscribe(SessionKilled, func(e Event) { got = append(got, "killed:"+e.SessionName) })
+	b.SubscribeAll(func(e Event) { got = append(got, "all:"+string(e.Kind)) })
+
+	b.Publish(Event{Kind: SessionCreated, SessionName: "api"})
+	b.Publish(Event{Kind: WorktreeCreated, SessionName: "api-x"})
+
+	want := []string{"created:api", "all:session_created", "all:worktree_created"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	var b Bus
+	calls := 0
+	unsubscribe := b.Subscribe(ProjectSelected, func(Event) { calls++ })
+	if !b.HasSubscribers(ProjectSelected) {
+		t.Fatal("HasSubscribers() = false after Subscribe")
+	}
+
+	b.Publish(Event{Kind: ProjectSelected})
+	unsubscribe()
+	b.Publish(Event{Kind: ProjectSelected})
+
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	if b.HasSubscribers(ProjectSelected) {
+		t.Error("HasSubscribers() = true after unsubscribe")
+	}
+}
+
+func TestBus_PublishSetsTime(t *testing.T) {
+	var b Bus
+	var got Event
+	b.Subscribe(SessionKilled, func(e Event) { got = e })
+	b.Publish(Event{Kind: SessionKilled})
+	if got.Time.IsZero() {
+		t.Error("Publish left Time zero")
+	}
+}
+
+func TestEvent_Env(t *testing.T) {
+	env := Event{Kind: SessionCreated, SessionName: "api", Path: "/src/api"}.Env()
+	want := []string{"POP_EVENT=session_created", "POP_SESSION_NAME=api", "POP_PROJECT_PATH=/src/api"}
+	if !slices.Equal(env, want) {
+		t.Errorf("Env() = %v, want %v", env, want)
+	}
+}
@@ -2,11 +2,16 @@ package history
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/glebglazov/pop/debug"
@@ -18,28 +23,116 @@ import (
 type Deps struct {
 	FS   deps.FileSystem
 	Tmux deps.Tmux
+	// Now is the clock frecency scores are computed against; nil means
+	// time.Now.
+	Now func() time.Time
+	// Identify returns the stable identity of the project at a path, or ""
+	// when it has none; nil means no project has one.
+	Identify func(path string) string
+	// Lock takes the cross-process lock guarding the history file at path
+	// and returns its release func; nil means no locking.
+	Lock func(path string) (unlock func(), err error)
 }
 
 // DefaultDeps returns dependencies using real implementations
 func DefaultDeps() *Deps {
 	return &Deps{
-		FS:   deps.NewRealFileSystem(),
-		Tmux: deps.NewRealTmux(),
+		FS:       deps.NewRealFileSystem(),
+		Tmux:     deps.NewRealTmux(),
+		Now:      time.Now,
+		Identify: project.Identity,
+		Lock:     LockFile,
 	}
 }
 
+func (d *Deps) now() time.Time {
+	if d.Now == nil {
+		return time.Now()
+	}
+	return d.Now()
+}
+
+// identify returns the identity of the project at path, or NoIdentity when
+// it has none.
+func (d *Deps) identify(path string) string {
+	if d.Identify == nil {
+		return NoIdentity
+	}
+	if id := d.Identify(path); id != "" {
+		return id
+	}
+	return NoIdentity
+}
+
+// NoIdentity is the ID recorded for a project found to have no identity, so
+// it is looked up once rather than on every run.
+const NoIdentity = "-"
+
+func (d *Deps) lock(path string) (func(), error) {
+	if d.Lock == nil {
+		return func() {}, nil
+	}
+	return d.Lock(path)
+}
+
 var defaultDeps = DefaultDeps()
 
 // Entry represents a history entry for a project
 type Entry struct {
+	// ID is the project's stable identity (see project.Identity), which
+	// outlives its path: when the project moves, Relink points the entry at
+	// the new path. History stays keyed by Path; the ID only finds a moved
+	// entry its new one. NoIdentity for projects without one, empty for
+	// entries not yet backfilled.
+	ID         string    `json:"id,omitempty"`
 	Path       string    `json:"path"`
 	LastAccess time.Time `json:"last_access"`
+	// Count is the number of recorded accesses. Entries written before counts
+	// were tracked load as 0.
+	Count int `json:"count,omitempty"`
+	// KilledAt is when the project's session was last killed from the
+	// picker, recorded by RecordKill; zero when it never was.
+	KilledAt time.Time `json:"killed_at,omitzero"`
+	// Window and Pane are the tmux window and pane IDs (@3, %12) that were
+	// active when pop last switched away from the project's session, recorded
+	// by RecordWindow for [project] restore_window = "last".
+	Window string `json:"window,omitempty"`
+	Pane   string `json:"pane,omitempty"`
+}
+
+// Identity returns the entry's project identity, or "" when it has none or
+// was not yet looked up.
+func (e Entry) Identity() string {
+	if e.ID == NoIdentity {
+		return ""
+	}
+	return e.ID
+}
+
+// Killed reports whether the project's session was killed after its last
+// recorded access.
+func (e Entry) Killed() bool {
+	return !e.KilledAt.IsZero() && !e.KilledAt.Before(e.LastAccess)
+}
+
+// Frecency returns the entry's frecency as seen at now. A killed entry scores
+// as if its last access were more than a week old, so a project that was just
+// cleaned up drops away from the cursor but keeps the weight of its count.
+func (e Entry) Frecency(now time.Time) float64 {
+	if e.Killed() {
+		return FrecencyScore(e.Count, time.Time{}, now)
+	}
+	return FrecencyScore(e.Count, e.LastAccess, now)
 }
 
 // History manages project access history
 type History struct {
 	Entries []Entry `json:"entries"`
-	path    string
+	// Identities caches, by path, the identity (or NoIdentity) of listed
+	// projects that have no entry yet, so RelinkWith reads each from disk
+	// once. It holds only the paths the last RelinkWith was given.
+	Identities map[string]string `json:"identities,omitempty"`
+	path       string
 }
 
 // DefaultHistoryPath returns the default history file path
@@ -88,11 +181,17 @@ func LoadWith(d *Deps, path string) (*History, error) {
 }
 
 // dedupeEntriesBy merges entries that resolve to the same canonical path,
-// keeping the most recent timestamp for each
+// keeping the most recent timestamp and summing the access counts for each.
+// The window and pane come from the most recently accessed entry that has one.
 func (h *History) dedupeEntriesBy(evalSymlinks func(string) (string, error)) {
 	type canonicalEntry struct {
 		resolvedPath string
+		id           string
 		lastAccess   time.Time
+		killedAt     time.Time
+		count        int
+		window       string
+		pane         string
 	}
 
 	seen := make(map[string]*canonicalEntry)
@@ -104,14 +203,30 @@ func (h *History) dedupeEntriesBy(evalSymlinks func(string) (string, error)) {
 		}
 
 		if existing, ok := seen[resolved]; ok {
+			// Keep the window of the more recent access, before its timestamp
+			if e.Window != "" && (existing.window == "" || e.LastAccess.After(existing.lastAccess)) {
+				existing.window, existing.pane = e.Window, e.Pane
+			}
 			// Keep the more recent timestamp
 			if e.LastAccess.After(existing.lastAccess) {
 				existing.lastAccess = e.LastAccess
 			}
+			if e.KilledAt.After(existing.killedAt) {
+				existing.killedAt = e.KilledAt
+			}
+			existing.count += e.Count
+			if existing.id == "" {
+				existing.id = e.ID
+			}
 		} else {
 			seen[resolved] = &canonicalEntry{
 				resolvedPath: resolved,
+				id:           e.ID,
 				lastAccess:   e.LastAccess,
+				killedAt:     e.KilledAt,
+				count:        e.Count,
+				window:       e.Window,
+				pane:         e.Pane,
 			}
 		}
 	}
@@ -120,8 +235,13 @@ func (h *History) dedupeEntriesBy(evalSymlinks func(string) (string, error)) {
 	h.Entries = make([]Entry, 0, len(seen))
 	for _, ce := range seen {
 		h.Entries = append(h.Entries, Entry{
+			ID:         ce.id,
 			Path:       ce.resolvedPath,
 			LastAccess: ce.lastAccess,
+			KilledAt:   ce.killedAt,
+			Count:      ce.count,
+			Window:     ce.window,
+			Pane:       ce.pane,
 		})
 	}
 	// Sort for deterministic order — map iteration above is randomized
@@ -135,8 +255,12 @@ func (h *History) Save() error {
 	return h.SaveWith(defaultDeps)
 }
 
-// SaveWith writes history using provided dependencies
+// SaveWith writes history using provided dependencies. The file is replaced
+// through a temp file and a rename, so a reader never sees it half-written.
 func (h *History) SaveWith(d *Deps) error {
+	if h.path == "" {
+		return errors.New("history has no file to save to")
+	}
 	dir := filepath.Dir(h.path)
 	if err := d.FS.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -147,11 +271,88 @@ func (h *History) SaveWith(d *Deps) error {
 		return err
 	}
 
-	return d.FS.WriteFile(h.path, data, 0644)
+	seq := atomic.AddUint64(&saveSeq, 1)
+	tmpPath := filepath.Join(dir, fmt.Sprintf(".%s.%d-%d.tmp", filepath.Base(h.path), os.Getpid(), seq))
+	if err := d.FS.WriteFile(tmpPath, data, 0644); err != nil {
+		_ = d.FS.RemoveAll(tmpPath)
+		return err
+	}
+	if err := d.FS.Rename(tmpPath, h.path); err != nil {
+		_ = d.FS.RemoveAll(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// saveSeq numbers SaveWith's temp files so concurrent saves in one process
+// never share one.
+var saveSeq uint64
+
+// Update applies fn to the history on disk and saves it.
+// Uses default dependencies.
+func (h *History) Update(fn func(*History)) error {
+	return h.UpdateWith(defaultDeps, fn)
+}
+
+// UpdateWith re-reads h's file while holding its lock, applies fn to the
+// fresh copy, saves it and leaves the result in h. Another pop process may
+// have saved since h was loaded — a picker stays open for minutes — so
+// modifying h and saving it would drop that process's changes. A history
+// without a file, as kept when loading failed, is updated in memory only.
+func (h *History) UpdateWith(d *Deps, fn func(*History)) error {
+	if h.path == "" {
+		fn(h)
+		return nil
+	}
+	unlock, err := d.lock(h.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fresh, err := LoadWith(d, h.path)
+	if err != nil {
+		return err
+	}
+	fn(fresh)
+	if err := fresh.SaveWith(d); err != nil {
+		return err
+	}
+	h.Entries, h.Identities = fresh.Entries, fresh.Identities
+	return nil
+}
+
+// LockFile takes an exclusive flock on the lock file next to the file at
+// path, such as the history, waiting for any other holder. The lock file is
+// left in place: removing it would let two processes lock different files of
+// the same name.
+func LockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock %s: %w", f.Name(), err)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
 }
 
 // Record marks a project as accessed
 func (h *History) Record(path string) {
+	h.RecordWith(defaultDeps, path)
+}
+
+// RecordWith marks a project as accessed using provided dependencies,
+// stamping the entry with the project's identity when it has none yet: the
+// one cached in Identities, or else read from disk.
+func (h *History) RecordWith(d *Deps, path string) {
 	now := time.Now()
 
 	// Update existing or add new
@@ -159,19 +360,279 @@ func (h *History) Record(path string) {
 	for i := range h.Entries {
 		if h.Entries[i].Path == path {
 			h.Entries[i].LastAccess = now
+			h.Entries[i].Count++
+			if h.Entries[i].ID == "" {
+				h.Entries[i].ID = d.identify(path)
+			}
 			found = true
 			break
 		}
 	}
 
 	if !found {
+		id, ok := h.Identities[path]
+		if ok {
+			delete(h.Identities, path)
+		} else {
+			id = d.identify(path)
+		}
 		h.Entries = append(h.Entries, Entry{
+			ID:         id,
 			Path:       path,
 			LastAccess: now,
+			Count:      1,
 		})
 	}
 }
 
+// RecordKill notes that a project's session was killed, demoting the
+// project's frecency until it is next recorded. A path without an entry is
+// left alone: there is nothing to demote.
+func (h *History) RecordKill(path string) {
+	for i := range h.Entries {
+		if h.Entries[i].Path == path {
+			h.Entries[i].KilledAt = time.Now()
+			return
+		}
+	}
+}
+
+// RecordWindow notes the window and pane last active in a project's session.
+// A path without an entry is left alone: pop never opened the project.
+func (h *History) RecordWindow(path, window, pane string) {
+	for i := range h.Entries {
+		if h.Entries[i].Path == path {
+			h.Entries[i].Window = window
+			h.Entries[i].Pane = pane
+			return
+		}
+	}
+}
+
+// Relink re-keys history to the projects at paths.
+// Uses default dependencies.
+func (h *History) Relink(paths []string) bool {
+	return h.RelinkWith(defaultDeps, paths)
+}
+
+// RelinkWith re-keys history to the projects currently at paths and reports
+// whether any entry changed. It is the migration from path-keyed history:
+// entries for paths without an ID are backfilled with their project's
+// identity, and an entry whose path is gone moves to the one path in paths
+// with the same identity, merging into that path's entry if it already has
+// one. An identity shared by several paths, as by two clones of a remote,
+// relinks nothing. Each identity is read from disk once: entries keep theirs
+// and Identities caches the rest.
+func (h *History) RelinkWith(d *Deps, paths []string) bool {
+	byPath := make(map[string]int, len(h.Entries))
+	for i, e := range h.Entries {
+		byPath[e.Path] = i
+	}
+
+	// Identify the live paths: from their entries, or from the cache for
+	// paths not yet in history.
+	changed := false
+	live := make(map[string]bool, len(paths))
+	identities := make(map[string]string)
+	pathsByID := make(map[string][]string)
+	for _, p := range paths {
+		live[p] = true
+		var id string
+		if i, ok := byPath[p]; ok {
+			if h.Entries[i].ID == "" {
+				h.Entries[i].ID = d.identify(p)
+				changed = true
+			}
+			id = h.Entries[i].ID
+		} else {
+			cached, ok := h.Identities[p]
+			if !ok {
+				cached = d.identify(p)
+				changed = true
+			}
+			identities[p] = cached
+			id = cached
+		}
+		if id != NoIdentity && !slices.Contains(pathsByID[id], p) {
+			pathsByID[id] = append(pathsByID[id], p)
+		}
+	}
+	// Every cached path was either kept or was no longer listed.
+	if len(identities) != len(h.Identities) {
+		changed = true
+	}
+	h.Identities = identities
+
+	dropped := make(map[int]bool)
+	for i := range h.Entries {
+		e := h.Entries[i]
+		if live[e.Path] || e.Identity() == "" {
+			continue
+		}
+		candidates := pathsByID[e.ID]
+		if len(candidates) != 1 {
+			continue
+		}
+		if _, err := d.FS.Stat(e.Path); !os.IsNotExist(err) {
+			continue
+		}
+		target := candidates[0]
+		debug.Log("history: relinking %s to %s", e.Path, target)
+		if j, ok := byPath[target]; ok {
+			h.Entries[j].merge(e)
+			dropped[i] = true
+		} else {
+			h.Entries[i].Path = target
+			byPath[target] = i
+			delete(h.Identities, target)
+		}
+		changed = true
+	}
+	if len(dropped) > 0 {
+		kept := h.Entries[:0]
+		for i, e := range h.Entries {
+			if !dropped[i] {
+				kept = append(kept, e)
+			}
+		}
+		h.Entries = kept
+	}
+	return changed
+}
+
+// PruneOptions selects the entries Prune drops.
+type PruneOptions struct {
+	// Missing drops entries whose path no longer exists on disk. Entries that
+	// are not filesystem paths, like standalone tmux sessions, are kept.
+	Missing bool
+	// Limit keeps at most this many entries, the most recently accessed;
+	// 0 keeps them all.
+	Limit int
+}
+
+// Prune drops the entries opts selects.
+// Uses default dependencies.
+func (h *History) Prune(opts PruneOptions) []Entry {
+	return h.PruneWith(defaultDeps, opts)
+}
+
+// PruneWith drops the entries opts selects and returns them, missing paths
+// first. The kept entries stay in their order.
+func (h *History) PruneWith(d *Deps, opts PruneOptions) []Entry {
+	var pruned []Entry
+	kept := make([]Entry, 0, len(h.Entries))
+	for _, e := range h.Entries {
+		if opts.Missing && filepath.IsAbs(e.Path) {
+			if _, err := d.FS.Stat(e.Path); os.IsNotExist(err) {
+				pruned = append(pruned, e)
+				continue
+			}
+		}
+		kept = append(kept, e)
+	}
+
+	if opts.Limit > 0 && len(kept) > opts.Limit {
+		// Indices of kept, most recently accessed first.
+		byRecency := make([]int, len(kept))
+		for i := range byRecency {
+			byRecency[i] = i
+		}
+		sort.SliceStable(byRecency, func(a, b int) bool {
+			return kept[byRecency[a]].LastAccess.After(kept[byRecency[b]].LastAccess)
+		})
+		drop := make(map[int]bool, len(kept)-opts.Limit)
+		for _, i := range byRecency[opts.Limit:] {
+			drop[i] = true
+		}
+		capped := make([]Entry, 0, opts.Limit)
+		for i, e := range kept {
+			if drop[i] {
+				pruned = append(pruned, e)
+				continue
+			}
+			capped = append(capped, e)
+		}
+		kept = capped
+	}
+
+	h.Entries = kept
+	return pruned
+}
+
+// Merge folds entries into h, as when importing another machine's history,
+// and returns how many paths were new. An entry for a path h already has
+// keeps the later timestamps and the higher count, so merging the same
+// entries twice changes nothing.
+func (h *History) Merge(entries []Entry) int {
+	byPath := make(map[string]int, len(h.Entries))
+	for i, e := range h.Entries {
+		byPath[e.Path] = i
+	}
+	added := 0
+	for _, e := range entries {
+		if e.Path == "" {
+			continue
+		}
+		i, ok := byPath[e.Path]
+		if !ok {
+			byPath[e.Path] = len(h.Entries)
+			h.Entries = append(h.Entries, e)
+			added++
+			continue
+		}
+		existing := &h.Entries[i]
+		if e.LastAccess.After(existing.LastAccess) {
+			existing.LastAccess = e.LastAccess
+		}
+		if e.KilledAt.After(existing.KilledAt) {
+			existing.KilledAt = e.KilledAt
+		}
+		existing.Count = max(existing.Count, e.Count)
+		if existing.ID == "" {
+			existing.ID = e.ID
+		}
+	}
+	return added
+}
+
+// merge folds other's accesses into e: the later timestamps and the summed
+// count.
+func (e *Entry) merge(other Entry) {
+	if other.LastAccess.After(e.LastAccess) {
+		e.LastAccess = other.LastAccess
+	}
+	if other.KilledAt.After(e.KilledAt) {
+		e.KilledAt = other.KilledAt
+	}
+	e.Count += other.Count
+	if e.Window == "" {
+		e.Window, e.Pane = other.Window, other.Pane
+	}
+}
+
+// Top returns the entries accessed at or after since, most-used first. Ties
+// break on the more recent access, then on path for a stable order. A zero
+// since includes every entry.
+func (h *History) Top(since time.Time) []Entry {
+	var top []Entry
+	for _, e := range h.Entries {
+		if !since.IsZero() && e.LastAccess.Before(since) {
+			continue
+		}
+		top = append(top, e)
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		if !top[i].LastAccess.Equal(top[j].LastAccess) {
+			return top[i].LastAccess.After(top[j].LastAccess)
+		}
+		return top[i].Path < top[j].Path
+	})
+	return top
+}
+
 // Remove deletes a project from history
 func (h *History) Remove(path string) {
 	h.RemoveWith(defaultDeps, path)
@@ -187,38 +648,73 @@ func (h *History) RemoveWith(d *Deps, path string) {
 	}
 }
 
-// SortByRecency sorts projects by recency (oldest first, most recent last)
+// Frecency weights applied to an entry's access count by the age of its last
+// access, after zoxide: a project opened often stays near the cursor even when
+// something else was opened a few minutes ago, while one used heavily months
+// ago decays below today's working set.
+const (
+	frecencyWeightHour  = 4.0
+	frecencyWeightDay   = 2.0
+	frecencyWeightWeek  = 0.5
+	frecencyWeightOlder = 0.25
+)
+
+// FrecencyScore returns the frecency of count accesses, the latest at last,
+// as seen at now. Entries recorded before counts were tracked have count 0 and
+// score as a single access.
+func FrecencyScore(count int, last, now time.Time) float64 {
+	if count < 1 {
+		count = 1
+	}
+	age := now.Sub(last)
+	weight := frecencyWeightOlder
+	switch {
+	case age < time.Hour:
+		weight = frecencyWeightHour
+	case age < 24*time.Hour:
+		weight = frecencyWeightDay
+	case age < 7*24*time.Hour:
+		weight = frecencyWeightWeek
+	}
+	return float64(count) * weight
+}
+
+// LessFrecent reports whether an entry with frecency score si, last accessed
+// at ti, sorts before one with score sj and last access tj: lower score first,
+// ties broken by the older access.
+func LessFrecent(si float64, ti time.Time, sj float64, tj time.Time) bool {
+	if si != sj {
+		return si < sj
+	}
+	return ti.Before(tj)
+}
+
+// SortByRecency sorts projects by frecency (least first, most frecent last)
 // Projects not in history are placed at the beginning, sorted alphabetically
 func (h *History) SortByRecency(projects []project.Project) []project.Project {
 	return h.SortByRecencyWith(defaultDeps, projects)
 }
 
-// SortByRecencyWith sorts projects by recency using provided dependencies
+// SortByRecencyWith sorts projects by frecency using provided dependencies
 func (h *History) SortByRecencyWith(d *Deps, projects []project.Project) []project.Project {
+	now := d.now()
+
 	// Build lookup map
-	accessTimes := make(map[string]time.Time)
+	entries := make(map[string]Entry)
 	for _, e := range h.Entries {
-		accessTimes[e.Path] = e.LastAccess
-	}
-
-	// Helper to look up access time
-	getAccessTime := func(path string) (time.Time, bool) {
-		if t, ok := accessTimes[path]; ok {
-			return t, true
-		}
-		return time.Time{}, false
+		entries[e.Path] = e
 	}
 
 	sorted := make([]project.Project, len(projects))
 	copy(sorted, projects)
 
 	sort.SliceStable(sorted, func(i, j int) bool {
-		ti, oki := getAccessTime(sorted[i].Path)
-		tj, okj := getAccessTime(sorted[j].Path)
+		ei, oki := entries[sorted[i].Path]
+		ej, okj := entries[sorted[j].Path]
 
 		if oki && okj {
-			// Both have history: older first (ascending order)
-			return ti.Before(tj)
+			// Both have history: least frecent first (ascending order)
+			return LessFrecent(ei.Frecency(now), ei.LastAccess, ej.Frecency(now), ej.LastAccess)
 		}
 		if oki {
 			// i has history, j doesn't: j comes first (no history at top)
@@ -265,4 +761,3 @@ func TmuxSessionActivityWith(d *Deps) map[string]int64 {
 
 	return activity
 }
-
@@ -3,7 +3,10 @@ package history
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -117,6 +120,132 @@ func TestSortByRecency(t *testing.T) {
 	}
 }
 
+func TestSortByRecency_Frecency(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d := &Deps{Now: func() time.Time { return now }}
+	h := &History{
+		Entries: []Entry{
+			{Path: "/daily", LastAccess: now.Add(-2 * time.Hour), Count: 30},
+			{Path: "/just-opened", LastAccess: now.Add(-5 * time.Minute), Count: 1},
+			{Path: "/last-month", LastAccess: now.Add(-30 * 24 * time.Hour), Count: 100},
+			{Path: "/twice-today", LastAccess: now.Add(-10 * time.Minute), Count: 2},
+		},
+	}
+	projects := []project.Project{
+		{Name: "daily", Path: "/daily"},
+		{Name: "just-opened", Path: "/just-opened"},
+		{Name: "last-month", Path: "/last-month"},
+		{Name: "twice-today", Path: "/twice-today"},
+	}
+
+	// Scores: just-opened 1×4, twice-today 2×4, last-month 100×0.25,
+	// daily 30×2 — the daily driver stays nearest the cursor.
+	want := []string{"just-opened", "twice-today", "last-month", "daily"}
+	result := h.SortByRecencyWith(d, projects)
+	for i, p := range result {
+		if p.Name != want[i] {
+			t.Errorf("position %d: expected %q, got %q", i, want[i], p.Name)
+		}
+	}
+}
+
+func TestFrecencyScore(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		count int
+		age   time.Duration
+		want  float64
+	}{
+		{"within the hour", 3, 30 * time.Minute, 12},
+		{"within the day", 3, 5 * time.Hour, 6},
+		{"within the week", 4, 3 * 24 * time.Hour, 2},
+		{"older", 4, 30 * 24 * time.Hour, 1},
+		{"legacy entry without a count", 0, 30 * time.Minute, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FrecencyScore(tt.count, now.Add(-tt.age), now); got != tt.want {
+				t.Errorf("FrecencyScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordKillDemotesUntilNextAccess(t *testing.T) {
+	now := time.Now()
+	d := &Deps{Now: func() time.Time { return now }}
+	h := &History{
+		Entries: []Entry{
+			{Path: "/old", LastAccess: now.Add(-3 * time.Hour), Count: 1},
+			{Path: "/killed", LastAccess: now.Add(-time.Minute), Count: 3},
+		},
+	}
+	projects := []project.Project{
+		{Name: "old", Path: "/old"},
+		{Name: "killed", Path: "/killed"},
+	}
+	names := func() []string {
+		var out []string
+		for _, p := range h.SortByRecencyWith(d, projects) {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	h.RecordKill("/killed")
+	h.RecordKill("/missing")
+	if !h.Entries[1].Killed() || len(h.Entries) != 2 {
+		t.Fatalf("entries = %+v, want only /killed marked killed", h.Entries)
+	}
+	// Killed: 3×0.25 sorts below the hour-old single access at 1×2.
+	if got := names(); !slices.Equal(got, []string{"killed", "old"}) {
+		t.Errorf("order after kill = %v, want the killed project demoted", got)
+	}
+
+	h.Record("/killed")
+	if h.Entries[1].Killed() {
+		t.Error("entry still killed after being recorded again")
+	}
+	if got := names(); !slices.Equal(got, []string{"old", "killed"}) {
+		t.Errorf("order after reopening = %v, want the project back nearest the cursor", got)
+	}
+}
+
+func TestRecordWindow(t *testing.T) {
+	h := &History{Entries: []Entry{{Path: "/api", Count: 2}}}
+
+	h.RecordWindow("/api", "@3", "%12")
+	h.RecordWindow("/missing", "@1", "%1")
+
+	if len(h.Entries) != 1 {
+		t.Fatalf("entries = %+v, want no entry added for /missing", h.Entries)
+	}
+	if e := h.Entries[0]; e.Window != "@3" || e.Pane != "%12" || e.Count != 2 {
+		t.Errorf("entry = %+v, want window @3 pane %%12 and count untouched", e)
+	}
+}
+
+func TestRecordWindowSurvivesReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h := &History{path: path, Entries: []Entry{{Path: "/api", Count: 1}}}
+	h.RecordWindow("/api", "@1", "%2")
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Entries) != 1 {
+		t.Fatalf("entries = %+v, want one", reloaded.Entries)
+	}
+	if e := reloaded.Entries[0]; e.Window != "@1" || e.Pane != "%2" {
+		t.Errorf("entry = %+v, want window @1 pane %%2 after reload", e)
+	}
+}
+
 func TestSortByRecency_StableSort(t *testing.T) {
 	// Projects without history should maintain relative alphabetical order
 	h := &History{}
@@ -272,7 +401,7 @@ func TestLoadWith(t *testing.T) {
 
 func TestSaveWith(t *testing.T) {
 	var savedData []byte
-	var savedPath string
+	var tmpPath, savedPath string
 
 	d := &Deps{
 		FS: &deps.MockFileSystem{
@@ -280,10 +409,17 @@ func TestSaveWith(t *testing.T) {
 				return nil
 			},
 			WriteFileFunc: func(path string, data []byte, perm os.FileMode) error {
-				savedPath = path
+				tmpPath = path
 				savedData = data
 				return nil
 			},
+			RenameFunc: func(oldpath, newpath string) error {
+				if oldpath != tmpPath {
+					t.Errorf("renamed %s, want the temp file %s", oldpath, tmpPath)
+				}
+				savedPath = newpath
+				return nil
+			},
 		},
 	}
 
@@ -303,6 +439,9 @@ func TestSaveWith(t *testing.T) {
 	if savedPath != "/test/dir/history.json" {
 		t.Errorf("saved to wrong path: %s", savedPath)
 	}
+	if filepath.Dir(tmpPath) != "/test/dir" || tmpPath == savedPath {
+		t.Errorf("wrote %s, want a temp file beside the history", tmpPath)
+	}
 
 	if !strings.Contains(string(savedData), "/project1") {
 		t.Error("saved data doesn't contain expected content")
@@ -345,6 +484,23 @@ func TestRecord(t *testing.T) {
 		}
 	})
 
+	t.Run("counts accesses", func(t *testing.T) {
+		h := &History{
+			Entries: []Entry{
+				{Path: "/home/user/project-a", Count: 4},
+			},
+		}
+		h.Record("/home/user/project-a")
+		h.Record("/home/user/project-b")
+
+		if h.Entries[0].Count != 5 {
+			t.Errorf("existing Count = %d, want 5", h.Entries[0].Count)
+		}
+		if h.Entries[1].Count != 1 {
+			t.Errorf("new Count = %d, want 1", h.Entries[1].Count)
+		}
+	})
+
 	t.Run("preserves other entries", func(t *testing.T) {
 		original := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
 		h := &History{
@@ -361,6 +517,171 @@ func TestRecord(t *testing.T) {
 	})
 }
 
+func TestRecordWithStampsIdentity(t *testing.T) {
+	d := &Deps{Identify: func(path string) string { return "id:" + path }}
+	h := &History{Entries: []Entry{{Path: "/src/old"}}}
+
+	h.RecordWith(d, "/src/old")
+	h.RecordWith(d, "/src/new")
+
+	if h.Entries[0].ID != "id:/src/old" || h.Entries[1].ID != "id:/src/new" {
+		t.Errorf("entries = %+v, want both stamped with their identity", h.Entries)
+	}
+}
+
+func TestRelinkWith(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	ids := map[string]string{
+		"/new/api":     "origin:api",
+		"/src/web":     "origin:web",
+		"/clones/a":    "origin:lib",
+		"/clones/b":    "origin:lib",
+		"/src/scratch": "",
+	}
+	d := &Deps{
+		FS: &deps.MockFileSystem{
+			StatFunc: func(path string) (os.FileInfo, error) {
+				if path == "/src/kept" {
+					return deps.MockFileInfo{IsDirVal: true}, nil
+				}
+				return nil, os.ErrNotExist
+			},
+		},
+		Identify: func(path string) string { return ids[path] },
+	}
+	live := []string{"/new/api", "/src/web", "/clones/a", "/clones/b", "/src/scratch"}
+
+	tests := []struct {
+		name        string
+		entries     []Entry
+		wantChanged bool
+		want        []Entry
+	}{
+		{
+			name:        "moved project follows its identity",
+			entries:     []Entry{{ID: "origin:api", Path: "/old/api", Count: 3, LastAccess: older}},
+			wantChanged: true,
+			want:        []Entry{{ID: "origin:api", Path: "/new/api", Count: 3, LastAccess: older}},
+		},
+		{
+			name: "moved project merges into an entry at its new path",
+			entries: []Entry{
+				{ID: "origin:web", Path: "/old/web", Count: 3, LastAccess: older},
+				{Path: "/src/web", Count: 1, LastAccess: newer},
+			},
+			wantChanged: true,
+			want:        []Entry{{ID: "origin:web", Path: "/src/web", Count: 4, LastAccess: newer}},
+		},
+		{
+			name:        "ambiguous identity stays put",
+			entries:     []Entry{{ID: "origin:lib", Path: "/old/lib"}},
+			wantChanged: false,
+			want:        []Entry{{ID: "origin:lib", Path: "/old/lib"}},
+		},
+		{
+			name:        "path still on disk stays put",
+			entries:     []Entry{{ID: "origin:api", Path: "/src/kept"}},
+			wantChanged: false,
+			want:        []Entry{{ID: "origin:api", Path: "/src/kept"}},
+		},
+		{
+			name:        "live entries are backfilled",
+			entries:     []Entry{{Path: "/new/api"}, {Path: "/src/scratch"}},
+			wantChanged: true,
+			want:        []Entry{{ID: "origin:api", Path: "/new/api"}, {ID: NoIdentity, Path: "/src/scratch"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Identified on an earlier run.
+			cached := make(map[string]string)
+			for _, p := range live {
+				cached[p] = d.identify(p)
+			}
+			for _, e := range tt.entries {
+				delete(cached, e.Path)
+			}
+			h := &History{Entries: tt.entries, Identities: cached}
+			if got := h.RelinkWith(d, live); got != tt.wantChanged {
+				t.Errorf("RelinkWith() = %v, want %v", got, tt.wantChanged)
+			}
+			if !slices.Equal(h.Entries, tt.want) {
+				t.Errorf("entries = %+v, want %+v", h.Entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelinkWithReadsEachIdentityOnce(t *testing.T) {
+	reads := 0
+	d := &Deps{
+		FS: &deps.MockFileSystem{},
+		Identify: func(path string) string {
+			reads++
+			if path == "/src/scratch" {
+				return ""
+			}
+			return "origin:" + path
+		},
+	}
+	h := &History{Entries: []Entry{{Path: "/src/api"}, {Path: "/src/scratch"}}}
+	live := []string{"/src/api", "/src/scratch", "/src/web"}
+
+	if !h.RelinkWith(d, live) {
+		t.Error("first RelinkWith() = false, want true")
+	}
+	if reads != 3 {
+		t.Errorf("first run read %d identities, want 3", reads)
+	}
+	if h.RelinkWith(d, live) {
+		t.Error("second RelinkWith() = true, want false")
+	}
+	if reads != 3 {
+		t.Errorf("second run read %d identities, want none", reads-3)
+	}
+
+	h.RecordWith(d, "/src/web")
+	if got := h.Entries[2].ID; got != "origin:/src/web" || reads != 3 {
+		t.Errorf("recorded ID = %q after %d reads, want the cached identity", got, reads)
+	}
+	if _, ok := h.Identities["/src/web"]; ok {
+		t.Error("a recorded path stays in the identity cache")
+	}
+}
+
+func TestTop(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := &History{
+		Entries: []Entry{
+			{Path: "/stale", LastAccess: now.Add(-60 * 24 * time.Hour), Count: 50},
+			{Path: "/b", LastAccess: now.Add(-time.Hour), Count: 3},
+			{Path: "/a", LastAccess: now.Add(-time.Hour), Count: 3},
+			{Path: "/recent", LastAccess: now, Count: 3},
+			{Path: "/busy", LastAccess: now.Add(-48 * time.Hour), Count: 9},
+		},
+	}
+
+	t.Run("ranks by count then recency then path", func(t *testing.T) {
+		var got []string
+		for _, e := range h.Top(now.Add(-30 * 24 * time.Hour)) {
+			got = append(got, e.Path)
+		}
+		want := []string{"/busy", "/recent", "/a", "/b"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Top() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("zero since includes every entry", func(t *testing.T) {
+		top := h.Top(time.Time{})
+		if len(top) != 5 || top[0].Path != "/stale" {
+			t.Errorf("Top(zero) = %v, want all 5 entries led by /stale", top)
+		}
+	})
+}
+
 func TestRemoveWith(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -422,6 +743,190 @@ func TestRemoveWith(t *testing.T) {
 	}
 }
 
+func TestPruneWith(t *testing.T) {
+	now := time.Now()
+	entries := []Entry{
+		{Path: "/gone", LastAccess: now},
+		{Path: "/old", LastAccess: now.Add(-2 * time.Hour)},
+		{Path: "tmux:scratch", LastAccess: now.Add(-time.Hour)},
+		{Path: "/new", LastAccess: now.Add(-time.Minute)},
+	}
+	d := &Deps{FS: &deps.MockFileSystem{
+		StatFunc: func(path string) (os.FileInfo, error) {
+			if path == "/gone" {
+				return nil, os.ErrNotExist
+			}
+			if path == "tmux:scratch" {
+				t.Error("stat a path that is not a filesystem path")
+			}
+			return deps.MockFileInfo{IsDirVal: true}, nil
+		},
+	}}
+
+	tests := []struct {
+		name       string
+		opts       PruneOptions
+		wantKept   []string
+		wantPruned []string
+	}{
+		{
+			name:     "nothing selected",
+			wantKept: []string{"/gone", "/old", "tmux:scratch", "/new"},
+		},
+		{
+			name:       "missing paths",
+			opts:       PruneOptions{Missing: true},
+			wantKept:   []string{"/old", "tmux:scratch", "/new"},
+			wantPruned: []string{"/gone"},
+		},
+		{
+			name:       "limit keeps the most recent in order",
+			opts:       PruneOptions{Limit: 2},
+			wantKept:   []string{"/gone", "/new"},
+			wantPruned: []string{"/old", "tmux:scratch"},
+		},
+		{
+			name:       "limit applies after missing paths go",
+			opts:       PruneOptions{Missing: true, Limit: 2},
+			wantKept:   []string{"tmux:scratch", "/new"},
+			wantPruned: []string{"/gone", "/old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &History{Entries: slices.Clone(entries)}
+			pruned := h.PruneWith(d, tt.opts)
+
+			var kept, prunedPaths []string
+			for _, e := range h.Entries {
+				kept = append(kept, e.Path)
+			}
+			for _, e := range pruned {
+				prunedPaths = append(prunedPaths, e.Path)
+			}
+			if !slices.Equal(kept, tt.wantKept) {
+				t.Errorf("kept %v, want %v", kept, tt.wantKept)
+			}
+			if !slices.Equal(prunedPaths, tt.wantPruned) {
+				t.Errorf("pruned %v, want %v", prunedPaths, tt.wantPruned)
+			}
+		})
+	}
+}
+
+func TestUpdateWith(t *testing.T) {
+	t.Run("applies fn to the file's current content", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.json")
+		d := &Deps{FS: deps.NewRealFileSystem(), Lock: LockFile}
+
+		stale, err := LoadWith(d, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Another process records a project after stale was loaded.
+		other, _ := LoadWith(d, path)
+		if err := other.UpdateWith(d, func(h *History) { h.RecordWith(d, "/other") }); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := stale.UpdateWith(d, func(h *History) { h.RecordWith(d, "/mine") }); err != nil {
+			t.Fatal(err)
+		}
+		reloaded, _ := LoadWith(d, path)
+		for _, h := range []*History{stale, reloaded} {
+			var paths []string
+			for _, e := range h.Entries {
+				paths = append(paths, e.Path)
+			}
+			slices.Sort(paths)
+			if !slices.Equal(paths, []string{"/mine", "/other"}) {
+				t.Errorf("entries = %v, want both processes' records", paths)
+			}
+		}
+	})
+
+	t.Run("holds the lock around load and save", func(t *testing.T) {
+		var calls []string
+		d := &Deps{
+			FS: &deps.MockFileSystem{
+				ReadFileFunc: func(path string) ([]byte, error) {
+					calls = append(calls, "read")
+					return nil, os.ErrNotExist
+				},
+				MkdirAllFunc: func(string, os.FileMode) error { return nil },
+				WriteFileFunc: func(string, []byte, os.FileMode) error {
+					calls = append(calls, "write")
+					return nil
+				},
+				RenameFunc: func(string, string) error { return nil },
+			},
+			Lock: func(path string) (func(), error) {
+				calls = append(calls, "lock "+path)
+				return func() { calls = append(calls, "unlock") }, nil
+			},
+		}
+
+		h := &History{path: "/data/history.json"}
+		if err := h.UpdateWith(d, func(h *History) { h.RecordWith(d, "/a") }); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"lock /data/history.json", "read", "write", "unlock"}
+		if !slices.Equal(calls, want) {
+			t.Errorf("calls = %v, want %v", calls, want)
+		}
+		if len(h.Entries) != 1 || h.Entries[0].Path != "/a" {
+			t.Errorf("entries = %+v, want the update kept in h", h.Entries)
+		}
+	})
+
+	t.Run("history without a file updates in memory", func(t *testing.T) {
+		d := &Deps{Lock: func(string) (func(), error) {
+			t.Error("locked a history without a file")
+			return func() {}, nil
+		}}
+		h := &History{}
+		if err := h.UpdateWith(d, func(h *History) { h.RecordWith(d, "/a") }); err != nil {
+			t.Fatal(err)
+		}
+		if len(h.Entries) != 1 {
+			t.Errorf("entries = %+v, want the record kept", h.Entries)
+		}
+	})
+}
+
+func TestUpdateWith_ConcurrentUpdatesAreNotLost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	d := &Deps{FS: deps.NewRealFileSystem(), Lock: LockFile}
+
+	const workers, records = 4, 10
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < records; i++ {
+				// Each update loads its own copy, as a separate pop process
+				// would.
+				h := &History{path: path}
+				if err := h.UpdateWith(d, func(h *History) { h.RecordWith(d, "/a") }); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	h, err := LoadWith(d, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Entries) != 1 || h.Entries[0].Count != workers*records {
+		t.Errorf("entries = %+v, want one entry counted %d times", h.Entries, workers*records)
+	}
+}
+
 func TestDedupeEntriesBy(t *testing.T) {
 	t.Run("merges entries with same canonical path keeping latest timestamp", func(t *testing.T) {
 		older := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -450,6 +955,71 @@ func TestDedupeEntriesBy(t *testing.T) {
 		}
 	})
 
+	t.Run("keeps the latest kill of merged entries", func(t *testing.T) {
+		kill := time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC)
+		h := &History{
+			Entries: []Entry{
+				{Path: "/symlink/project", KilledAt: kill},
+				{Path: "/real/project"},
+			},
+		}
+		h.dedupeEntriesBy(func(path string) (string, error) {
+			return "/real/project", nil
+		})
+		if len(h.Entries) != 1 || !h.Entries[0].KilledAt.Equal(kill) {
+			t.Errorf("entries = %+v, want one entry killed at %v", h.Entries, kill)
+		}
+	})
+
+	t.Run("keeps the window of the latest access", func(t *testing.T) {
+		older := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		newer := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+		h := &History{
+			Entries: []Entry{
+				{Path: "/symlink/project", LastAccess: newer, Window: "@4", Pane: "%9"},
+				{Path: "/real/project", LastAccess: older, Window: "@1", Pane: "%2"},
+			},
+		}
+		h.dedupeEntriesBy(func(path string) (string, error) {
+			return "/real/project", nil
+		})
+		if e := h.Entries[0]; e.Window != "@4" || e.Pane != "%9" {
+			t.Errorf("entry = %+v, want window @4 pane %%9", e)
+		}
+	})
+
+	t.Run("sums counts of merged entries", func(t *testing.T) {
+		h := &History{
+			Entries: []Entry{
+				{Path: "/symlink/project", Count: 2},
+				{Path: "/real/project", Count: 3},
+			},
+		}
+
+		h.dedupeEntriesBy(func(path string) (string, error) {
+			return "/real/project", nil
+		})
+
+		if h.Entries[0].Count != 5 {
+			t.Errorf("Count = %d, want 5", h.Entries[0].Count)
+		}
+	})
+
+	t.Run("keeps the identity of merged entries", func(t *testing.T) {
+		h := &History{
+			Entries: []Entry{
+				{Path: "/symlink/project"},
+				{Path: "/real/project", ID: "origin:abc"},
+			},
+		}
+		h.dedupeEntriesBy(func(path string) (string, error) {
+			return "/real/project", nil
+		})
+		if h.Entries[0].ID != "origin:abc" {
+			t.Errorf("ID = %q, want origin:abc", h.Entries[0].ID)
+		}
+	})
+
 	t.Run("keeps entries with distinct canonical paths", func(t *testing.T) {
 		h := &History{
 			Entries: []Entry{
@@ -548,3 +1118,38 @@ func TestTmuxSessionActivityWith(t *testing.T) {
 		})
 	}
 }
+
+func TestMerge(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	h := &History{Entries: []Entry{
+		{Path: "/src/api", LastAccess: now.Add(-time.Hour), Count: 4},
+		{Path: "/src/web", LastAccess: now, Count: 1},
+	}}
+	imported := []Entry{
+		{Path: "/src/api", LastAccess: now, Count: 2, ID: "origin:abc"},
+		{Path: "/src/web", LastAccess: now.Add(-time.Hour), Count: 3},
+		{Path: "/src/docs", LastAccess: now, Count: 1},
+		{LastAccess: now},
+	}
+
+	if added := h.Merge(imported); added != 1 {
+		t.Errorf("Merge() = %d, want 1 new path", added)
+	}
+	if added := h.Merge(imported); added != 0 {
+		t.Errorf("second Merge() = %d, want 0", added)
+	}
+
+	want := []Entry{
+		{Path: "/src/api", LastAccess: now, Count: 4, ID: "origin:abc"},
+		{Path: "/src/web", LastAccess: now, Count: 3},
+		{Path: "/src/docs", LastAccess: now, Count: 1},
+	}
+	if len(h.Entries) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", h.Entries, want)
+	}
+	for i := range want {
+		if h.Entries[i] != want[i] {
+			t.Errorf("entries[%d] = %+v, want %+v", i, h.Entries[i], want[i])
+		}
+	}
+}
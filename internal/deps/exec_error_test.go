@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRealTmuxSurfacesStderr(t *testing.T) {
@@ -94,6 +95,23 @@ func TestRealGitSurfacesStderr(t *testing.T) {
 	}
 }
 
+func TestRealGitTimeout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte("#!/bin/sh\nexec sleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	start := time.Now()
+	_, err := (&RealGit{Timeout: 50 * time.Millisecond}).CommandInDir(dir, "status")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("git ran for %s, want it killed at the timeout", elapsed)
+	}
+}
+
 func TestCommandErrorLeavesEmptyStderrUnchanged(t *testing.T) {
 	withFakeCommand(t, "git", "")
 
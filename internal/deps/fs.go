@@ -22,6 +22,9 @@ type FileSystem interface {
 	ReadFile(path string) ([]byte, error)
 	// WriteFile writes data to the given file
 	WriteFile(path string, data []byte, perm os.FileMode) error
+	// WriteFileSync writes data to the given file and fsyncs it before
+	// returning, for a temp file about to be renamed into place
+	WriteFileSync(path string, data []byte, perm os.FileMode) error
 	// MkdirAll creates a directory and all parents
 	MkdirAll(path string, perm os.FileMode) error
 	// Rename moves oldpath to newpath
@@ -69,6 +72,22 @@ func (f *RealFileSystem) WriteFile(path string, data []byte, perm os.FileMode) e
 	return os.WriteFile(path, data, perm)
 }
 
+func (f *RealFileSystem) WriteFileSync(path string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 func (f *RealFileSystem) MkdirAll(path string, perm os.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
@@ -1,8 +1,10 @@
 package deps
 
 import (
+	"context"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // Git defines operations for interacting with git repositories
@@ -14,23 +16,33 @@ type Git interface {
 }
 
 // RealGit implements Git using actual git commands
-type RealGit struct{}
+type RealGit struct {
+	// Timeout, when set, kills a git command that runs longer.
+	Timeout time.Duration
+}
 
 func NewRealGit() *RealGit {
 	return &RealGit{}
 }
 
 func (g *RealGit) Command(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	out, err := cmd.Output()
-	if err != nil {
-		return "", outputError(err)
-	}
-	return strings.TrimSpace(string(out)), nil
+	return g.run(args...)
 }
 
 func (g *RealGit) CommandInDir(dir string, args ...string) (string, error) {
-	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	return g.run(append([]string{"-C", dir}, args...)...)
+}
+
+func (g *RealGit) run(args ...string) (string, error) {
+	ctx := context.Background()
+	if g.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.Timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	// A helper git started may hold the output open past the kill.
+	cmd.WaitDelay = time.Second
 	out, err := cmd.Output()
 	if err != nil {
 		return "", outputError(err)
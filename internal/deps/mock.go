@@ -3,6 +3,7 @@ package deps
 import (
 	"io/fs"
 	"os"
+	"os/user"
 )
 
 // MockGit is a test double for Git
@@ -25,20 +26,36 @@ func (m *MockGit) CommandInDir(dir string, args ...string) (string, error) {
 	return "", nil
 }
 
+// MockUsers is a test double for Users. Without HomeDirFunc every user is
+// unknown.
+type MockUsers struct {
+	HomeDirFunc func(name string) (string, error)
+}
+
+func (m *MockUsers) HomeDir(name string) (string, error) {
+	if m.HomeDirFunc != nil {
+		return m.HomeDirFunc(name)
+	}
+	return "", user.UnknownUserError(name)
+}
+
 // MockFileSystem is a test double for FileSystem
 type MockFileSystem struct {
-	GetwdFunc        func() (string, error)
-	UserHomeDirFunc  func() (string, error)
-	GetenvFunc       func(key string) string
-	StatFunc         func(path string) (os.FileInfo, error)
-	ReadDirFunc      func(path string) ([]os.DirEntry, error)
-	ReadFileFunc     func(path string) ([]byte, error)
-	WriteFileFunc    func(path string, data []byte, perm os.FileMode) error
-	MkdirAllFunc     func(path string, perm os.FileMode) error
-	RenameFunc       func(oldpath, newpath string) error
-	RemoveAllFunc    func(path string) error
-	DirFSFunc        func(dir string) fs.FS
-	EvalSymlinksFunc func(path string) (string, error)
+	GetwdFunc       func() (string, error)
+	UserHomeDirFunc func() (string, error)
+	GetenvFunc      func(key string) string
+	StatFunc        func(path string) (os.FileInfo, error)
+	ReadDirFunc     func(path string) ([]os.DirEntry, error)
+	ReadFileFunc    func(path string) ([]byte, error)
+	WriteFileFunc   func(path string, data []byte, perm os.FileMode) error
+	// WriteFileSyncFunc defaults to WriteFile, so tests that only watch
+	// WriteFile see synced writes too.
+	WriteFileSyncFunc func(path string, data []byte, perm os.FileMode) error
+	MkdirAllFunc      func(path string, perm os.FileMode) error
+	RenameFunc        func(oldpath, newpath string) error
+	RemoveAllFunc     func(path string) error
+	DirFSFunc         func(dir string) fs.FS
+	EvalSymlinksFunc  func(path string) (string, error)
 }
 
 func (m *MockFileSystem) Getwd() (string, error) {
@@ -90,6 +107,13 @@ func (m *MockFileSystem) WriteFile(path string, data []byte, perm os.FileMode) e
 	return nil
 }
 
+func (m *MockFileSystem) WriteFileSync(path string, data []byte, perm os.FileMode) error {
+	if m.WriteFileSyncFunc != nil {
+		return m.WriteFileSyncFunc(path, data, perm)
+	}
+	return m.WriteFile(path, data, perm)
+}
+
 func (m *MockFileSystem) MkdirAll(path string, perm os.FileMode) error {
 	if m.MkdirAllFunc != nil {
 		return m.MkdirAllFunc(path, perm)
@@ -140,13 +164,14 @@ func (m *MockReleaseFetcher) LatestReleaseTag() (string, error) {
 
 // MockTmux is a test double for Tmux
 type MockTmux struct {
-	CommandFunc       func(args ...string) (string, error)
-	HasSessionFunc    func(name string) bool
-	NewSessionFunc    func(name, dir string) error
-	SwitchClientFunc  func(name string) error
-	AttachSessionFunc func(name string) error
-	KillSessionFunc   func(name string) error
-	ListSessionsFunc  func() (string, error)
+	CommandFunc                   func(args ...string) (string, error)
+	HasSessionFunc                func(name string) bool
+	NewSessionFunc                func(name, dir string) error
+	SwitchClientFunc              func(name string) error
+	AttachSessionFunc             func(name string) error
+	AttachSessionDetachOthersFunc func(name string) error
+	KillSessionFunc               func(name string) error
+	ListSessionsFunc              func() (string, error)
 }
 
 func (m *MockTmux) Command(args ...string) (string, error) {
@@ -184,6 +209,13 @@ func (m *MockTmux) AttachSession(name string) error {
 	return nil
 }
 
+func (m *MockTmux) AttachSessionDetachOthers(name string) error {
+	if m.AttachSessionDetachOthersFunc != nil {
+		return m.AttachSessionDetachOthersFunc(name)
+	}
+	return nil
+}
+
 func (m *MockTmux) KillSession(name string) error {
 	if m.KillSessionFunc != nil {
 		return m.KillSessionFunc(name)
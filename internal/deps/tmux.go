@@ -21,6 +21,9 @@ type Tmux interface {
 	SwitchClient(name string) error
 	// AttachSession attaches to a session (when outside tmux)
 	AttachSession(name string) error
+	// AttachSessionDetachOthers attaches to a session (when outside tmux),
+	// detaching every other client so the window takes this terminal's size
+	AttachSessionDetachOthers(name string) error
 	// KillSession kills a session
 	KillSession(name string) error
 	// ListSessions returns session info in "name\tactivity" format per line.
@@ -70,7 +73,17 @@ func (t *RealTmux) SwitchClient(name string) error {
 }
 
 func (t *RealTmux) AttachSession(name string) error {
-	cmd := exec.Command("tmux", "attach-session", "-t", name)
+	return t.attach("attach-session", "-t", name)
+}
+
+func (t *RealTmux) AttachSessionDetachOthers(name string) error {
+	return t.attach("attach-session", "-d", "-t", name)
+}
+
+// attach runs an attach-session command with the terminal's stdio wired in,
+// so tmux takes over the terminal until the client detaches.
+func (t *RealTmux) attach(args ...string) error {
+	cmd := exec.Command("tmux", args...)
 	var stderr bytes.Buffer
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
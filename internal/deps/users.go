@@ -0,0 +1,24 @@
+package deps
+
+import "os/user"
+
+// Users defines lookups of the system's user accounts
+type Users interface {
+	// HomeDir returns the home directory of the named user
+	HomeDir(name string) (string, error)
+}
+
+// RealUsers implements Users using the system user database
+type RealUsers struct{}
+
+func NewRealUsers() *RealUsers {
+	return &RealUsers{}
+}
+
+func (u *RealUsers) HomeDir(name string) (string, error) {
+	account, err := user.Lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return account.HomeDir, nil
+}
@@ -0,0 +1,9 @@
+// Package shell builds sh command lines.
+package shell
+
+import "strings"
+
+// Quote wraps s in single quotes for sh, escaping embedded single quotes.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
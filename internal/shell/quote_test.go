@@ -0,0 +1,17 @@
+package shell
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"":             "''",
+		"/src/api":     "'/src/api'",
+		"it's":         `'it'\''s'`,
+		"$HOME; rm -r": "'$HOME; rm -r'",
+	}
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
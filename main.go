@@ -1,7 +1,15 @@
 package main
 
-import "github.com/glebglazov/pop/cmd"
+import (
+	_ "embed"
+
+	"github.com/glebglazov/pop/cmd"
+)
+
+//go:embed config.example.toml
+var configExample string
 
 func main() {
+	cmd.ConfigTemplate = configExample
 	cmd.Execute()
 }
@@ -331,13 +331,14 @@ func (m *mockFS) UserHomeDir() (string, error) {
 	}
 	return m.userHomeDir()
 }
-func (m *mockFS) Getenv(key string) string                    { return m.getenv(key) }
-func (m *mockFS) Stat(string) (os.FileInfo, error)            { return nil, nil }
-func (m *mockFS) ReadDir(string) ([]os.DirEntry, error)       { return nil, nil }
-func (m *mockFS) ReadFile(string) ([]byte, error)             { return nil, nil }
-func (m *mockFS) WriteFile(string, []byte, os.FileMode) error { return nil }
-func (m *mockFS) MkdirAll(string, os.FileMode) error          { return nil }
-func (m *mockFS) Rename(string, string) error                 { return nil }
-func (m *mockFS) RemoveAll(string) error                      { return nil }
-func (m *mockFS) DirFS(string) fs.FS                          { return nil }
-func (m *mockFS) EvalSymlinks(string) (string, error)         { return "", nil }
+func (m *mockFS) Getenv(key string) string                        { return m.getenv(key) }
+func (m *mockFS) Stat(string) (os.FileInfo, error)                { return nil, nil }
+func (m *mockFS) ReadDir(string) ([]os.DirEntry, error)           { return nil, nil }
+func (m *mockFS) ReadFile(string) ([]byte, error)                 { return nil, nil }
+func (m *mockFS) WriteFile(string, []byte, os.FileMode) error     { return nil }
+func (m *mockFS) WriteFileSync(string, []byte, os.FileMode) error { return nil }
+func (m *mockFS) MkdirAll(string, os.FileMode) error              { return nil }
+func (m *mockFS) Rename(string, string) error                     { return nil }
+func (m *mockFS) RemoveAll(string) error                          { return nil }
+func (m *mockFS) DirFS(string) fs.FS                              { return nil }
+func (m *mockFS) EvalSymlinks(string) (string, error)             { return "", nil }
@@ -9,6 +9,10 @@ import (
 // the same name. The strategy parameter controls how disambiguation works:
 //   - "first_unique_segment": appends the first unique parent segment in parentheses
 //   - "full_path": prepends parent segments to the name until all are unique
+//
+// Projects at different paths that would share a tmux session get distinct
+// session names the same way, whatever the strategy (see
+// disambiguateSessionNames).
 func DisambiguateNames(items []ExpandedProject, strategy string) {
 	groups := map[string][]int{}
 	for i, item := range items {
@@ -25,6 +29,7 @@ func DisambiguateNames(items []ExpandedProject, strategy string) {
 			disambiguateGroup(items, indices)
 		}
 	}
+	disambiguateSessionNames(items)
 }
 
 func disambiguateGroup(items []ExpandedProject, indices []int) {
@@ -126,6 +131,38 @@ func disambiguateGroup(items []ExpandedProject, indices []int) {
 	}
 }
 
+// disambiguateSessionNames prefixes parent directory segments to the session
+// names that projects at different paths share, as the full_path strategy
+// does for display names, so "api" under ~/work and ~/personal opens
+// "work/api" and "personal/api" rather than one session for both.
+func disambiguateSessionNames(items []ExpandedProject) {
+	groups := map[string][]int{}
+	for i, item := range items {
+		if item.SessionName != "" {
+			groups[item.SessionName] = append(groups[item.SessionName], i)
+		}
+	}
+	for name, indices := range groups {
+		paths := map[string]bool{}
+		for _, idx := range indices {
+			paths[items[idx].Path] = true
+		}
+		if len(paths) <= 1 {
+			continue
+		}
+		names := make([]ExpandedProject, len(indices))
+		all := make([]int, len(indices))
+		for j, idx := range indices {
+			names[j] = ExpandedProject{Name: name, Path: items[idx].Path}
+			all[j] = j
+		}
+		disambiguateGroupFullPath(names, all)
+		for j, idx := range indices {
+			items[idx].SessionName = SafeSessionName(names[j].Name)
+		}
+	}
+}
+
 // parentDir returns the parent directory of a project path, accounting for
 // the number of path segments in the project name. For example, if name is
 // "project/worktree" and path is "/a/b/project/worktree", parentDir returns
@@ -263,3 +263,24 @@ func TestSplitParentSegments(t *testing.T) {
 		}
 	}
 }
+
+func TestDisambiguateNames_SessionNames(t *testing.T) {
+	items := []ExpandedProject{
+		{Name: "api", Path: "/src/work/api", SessionName: "api"},
+		{Name: "api", Path: "/src/personal/api", SessionName: "api"},
+		{Name: "repo/main", Path: "/src/a/repo/main", SessionName: "repo/main"},
+		{Name: "repo/main", Path: "/src/b/repo/main", SessionName: "repo/main"},
+		{Name: "my.app", Path: "/src/x/my.app", SessionName: "my_app"},
+		{Name: "my_app", Path: "/src/y/my_app", SessionName: "my_app"},
+		{Name: "solo", Path: "/src/solo", SessionName: "solo"},
+		{Name: "solo", Path: "/src/solo", SessionName: "solo"},
+	}
+	DisambiguateNames(items, "first_unique_segment")
+
+	want := []string{"work/api", "personal/api", "a/repo/main", "b/repo/main", "x/my_app", "y/my_app", "solo", "solo"}
+	for i, item := range items {
+		if item.SessionName != want[i] {
+			t.Errorf("items[%d] (%s) session = %q, want %q", i, item.Path, item.SessionName, want[i])
+		}
+	}
+}
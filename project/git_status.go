@@ -0,0 +1,63 @@
+package project
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GitStatus is a checkout's working-tree state: its branch, whether it has
+// changes and how far the branch is ahead of and behind its upstream.
+type GitStatus struct {
+	Branch string // "" on a detached HEAD
+	Dirty  bool
+	Ahead  int
+	Behind int
+}
+
+// String renders the status as compact markers: ● for changes, ↑n and ↓n for
+// commits ahead and behind. A clean checkout level with its upstream is "".
+func (s GitStatus) String() string {
+	var parts []string
+	if s.Dirty {
+		parts = append(parts, "●")
+	}
+	if s.Ahead > 0 {
+		parts = append(parts, "↑"+strconv.Itoa(s.Ahead))
+	}
+	if s.Behind > 0 {
+		parts = append(parts, "↓"+strconv.Itoa(s.Behind))
+	}
+	return strings.Join(parts, " ")
+}
+
+// GitStatusOf returns the git status of the checkout at path. Uses default
+// dependencies.
+func GitStatusOf(path string) (GitStatus, error) {
+	return GitStatusOfWith(defaultDeps, path)
+}
+
+// GitStatusOfWith reads the checkout's state from a single
+// `git status --porcelain=v2 --branch`, using provided dependencies. Untracked
+// files count as changes; a branch without an upstream is neither ahead nor
+// behind.
+func GitStatusOfWith(d *Deps, path string) (GitStatus, error) {
+	out, err := d.Git.CommandInDir(path, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return GitStatus{}, err
+	}
+	var s GitStatus
+	for _, line := range strings.Split(out, "\n") {
+		if head, ok := strings.CutPrefix(line, "# branch.head "); ok {
+			if head != "(detached)" {
+				s.Branch = head
+			}
+		} else if ab, ok := strings.CutPrefix(line, "# branch.ab "); ok {
+			ahead, behind, _ := strings.Cut(ab, " ")
+			s.Ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+			s.Behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+		} else if line != "" && !strings.HasPrefix(line, "#") {
+			s.Dirty = true
+		}
+	}
+	return s, nil
+}
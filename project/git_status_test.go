@@ -0,0 +1,84 @@
+package project
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func TestGitStatusOfWith(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   GitStatus
+		text   string
+	}{
+		{
+			name:   "clean and level",
+			output: "# branch.oid abc123\n# branch.head main\n# branch.upstream origin/main\n# branch.ab +0 -0",
+			want:   GitStatus{Branch: "main"},
+			text:   "",
+		},
+		{
+			name:   "dirty, ahead and behind",
+			output: "# branch.head main\n# branch.ab +2 -1\n1 .M N... 100644 100644 100644 abc abc main.go",
+			want:   GitStatus{Branch: "main", Dirty: true, Ahead: 2, Behind: 1},
+			text:   "● ↑2 ↓1",
+		},
+		{
+			name:   "untracked file without upstream",
+			output: "# branch.head feature\n? notes.txt",
+			want:   GitStatus{Branch: "feature", Dirty: true},
+			text:   "●",
+		},
+		{
+			name:   "detached head",
+			output: "# branch.oid abc123\n# branch.head (detached)",
+			want:   GitStatus{},
+			text:   "",
+		},
+		{
+			name:   "behind only",
+			output: "# branch.ab +0 -4",
+			want:   GitStatus{Behind: 4},
+			text:   "↓4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []string
+			d := &Deps{Git: &deps.MockGit{
+				CommandInDirFunc: func(dir string, args ...string) (string, error) {
+					gotArgs = args
+					return tt.output, nil
+				},
+			}}
+			got, err := GitStatusOfWith(d, "/src/api")
+			if err != nil {
+				t.Fatalf("GitStatusOfWith: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GitStatusOfWith() = %+v, want %+v", got, tt.want)
+			}
+			if got.String() != tt.text {
+				t.Errorf("String() = %q, want %q", got.String(), tt.text)
+			}
+			if len(gotArgs) == 0 || gotArgs[0] != "status" {
+				t.Errorf("git args = %v, want a status call", gotArgs)
+			}
+		})
+	}
+}
+
+func TestGitStatusOfWithError(t *testing.T) {
+	d := &Deps{Git: &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			return "", errors.New("not a git repository")
+		},
+	}}
+	if _, err := GitStatusOfWith(d, "/src/notes"); err == nil {
+		t.Error("GitStatusOfWith() error = nil, want the git failure")
+	}
+}
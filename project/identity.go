@@ -0,0 +1,103 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// Identity returns the stable identity of the project at path.
+// Uses default dependencies.
+func Identity(path string) string {
+	return IdentityWith(defaultDeps, path)
+}
+
+// IdentityWith returns a stable identity for the git project at path that
+// survives moving it on disk, or "" when it has none (file-based, no git
+// commands). The identity hashes the repo's origin URL; a linked worktree adds
+// its worktree name, since every worktree of a repo shares the origin. Clones
+// of the same remote share an identity, so callers must treat a match as a
+// hint, not proof.
+func IdentityWith(d *Deps, path string) string {
+	configDir, worktree := identityGitDirWith(d, path)
+	if configDir == "" {
+		return ""
+	}
+	data, err := d.FS.ReadFile(filepath.Join(configDir, "config"))
+	if err != nil {
+		return ""
+	}
+	url := normalizeRemoteURL(originURL(string(data)))
+	if url == "" {
+		return ""
+	}
+	if worktree != "" {
+		url += "#" + worktree
+	}
+	sum := sha256.Sum256([]byte(url))
+	return "origin:" + hex.EncodeToString(sum[:8])
+}
+
+// identityGitDirWith returns the directory holding the git config for path —
+// its .git or .bare dir, the common dir of a linked worktree, or path itself
+// for a bare clone — and the worktree name when path is a linked worktree.
+func identityGitDirWith(d *Deps, path string) (configDir, worktree string) {
+	if gitDir, ok := linkedGitDirWith(d, path); ok {
+		worktreesDir := filepath.Dir(gitDir)
+		if filepath.Base(worktreesDir) != "worktrees" {
+			return "", ""
+		}
+		return filepath.Dir(worktreesDir), filepath.Base(gitDir)
+	}
+	for _, dir := range []string{".git", ".bare"} {
+		candidate := filepath.Join(path, dir)
+		if info, err := d.FS.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, ""
+		}
+	}
+	if isCoreBareWith(d, path) {
+		return path, ""
+	}
+	return "", ""
+}
+
+// originURL returns the url of the [remote "origin"] section of a git config
+// file, or "" when there is none.
+func originURL(config string) string {
+	inOrigin := false
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inOrigin = strings.ReplaceAll(line, " ", "") == `[remote"origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if found && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// normalizeRemoteURL reduces a remote URL to host/path so the ssh, scp-style
+// and https spellings of one remote compare equal.
+func normalizeRemoteURL(url string) string {
+	if _, rest, found := strings.Cut(url, "://"); found {
+		url = rest
+	} else if host, path, found := strings.Cut(url, ":"); found && !strings.Contains(host, "/") {
+		url = host + "/" + path
+	}
+	host, path, _ := strings.Cut(url, "/")
+	if _, h, found := strings.Cut(host, "@"); found {
+		host = h
+	}
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	if path == "" {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(host) + "/" + path
+}
@@ -0,0 +1,60 @@
+package project
+
+import (
+	"os"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func TestIdentityWith(t *testing.T) {
+	const origin = "[core]\n\tbare = false\n[remote \"origin\"]\n\turl = git@github.com:me/api.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n"
+	fs := func(dirs map[string]bool, files map[string]string) *deps.MockFileSystem {
+		return &deps.MockFileSystem{
+			StatFunc: func(path string) (os.FileInfo, error) {
+				if dirs[path] {
+					return deps.MockFileInfo{IsDirVal: true}, nil
+				}
+				if _, ok := files[path]; ok {
+					return deps.MockFileInfo{}, nil
+				}
+				return nil, os.ErrNotExist
+			},
+			ReadFileFunc: func(path string) ([]byte, error) {
+				if data, ok := files[path]; ok {
+					return []byte(data), nil
+				}
+				return nil, os.ErrNotExist
+			},
+		}
+	}
+
+	repo := &Deps{FS: fs(map[string]bool{"/src/api/.git": true}, map[string]string{"/src/api/.git/config": origin})}
+	moved := &Deps{FS: fs(map[string]bool{"/work/api/.git": true}, map[string]string{"/work/api/.git/config": origin})}
+	https := &Deps{FS: fs(map[string]bool{"/src/api/.git": true}, map[string]string{"/src/api/.git/config": "[remote \"origin\"]\n\turl = https://GitHub.com/me/api\n"})}
+	worktree := &Deps{FS: fs(nil, map[string]string{
+		"/src/api/main/.git":    "gitdir: /src/api/.bare/worktrees/main\n",
+		"/src/api/.bare/config": origin,
+	})}
+	noRemote := &Deps{FS: fs(map[string]bool{"/src/api/.git": true}, map[string]string{"/src/api/.git/config": "[core]\n\tbare = false\n"})}
+
+	id := IdentityWith(repo, "/src/api")
+	if id == "" {
+		t.Fatal("IdentityWith(repo) = \"\", want an identity from the origin URL")
+	}
+	if got := IdentityWith(moved, "/work/api"); got != id {
+		t.Errorf("moved repo identity = %q, want %q", got, id)
+	}
+	if got := IdentityWith(https, "/src/api"); got != id {
+		t.Errorf("https remote identity = %q, want the ssh remote's %q", got, id)
+	}
+	if got := IdentityWith(worktree, "/src/api/main"); got == "" || got == id {
+		t.Errorf("worktree identity = %q, want one distinct from the repo's", got)
+	}
+	if got := IdentityWith(noRemote, "/src/api"); got != "" {
+		t.Errorf("IdentityWith(no origin) = %q, want \"\"", got)
+	}
+	if got := IdentityWith(&Deps{FS: fs(nil, nil)}, "/src/notes"); got != "" {
+		t.Errorf("IdentityWith(not a repo) = %q, want \"\"", got)
+	}
+}
@@ -3,7 +3,10 @@ package project
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/glebglazov/pop/debug"
 	"github.com/glebglazov/pop/internal/deps"
@@ -54,6 +57,7 @@ type Worktree struct {
 	Name   string
 	Branch string
 	Path   string
+	Broken bool // .git points at missing metadata (StaleWorktreeWith); set by ListWorktreesForPathWith
 }
 
 // RepoContext holds information about the current git repository
@@ -166,7 +170,7 @@ func SessionNameWith(d *Deps, path string) string {
 	worktreeName := filepath.Base(path)
 	ctx, err := DetectRepoContextFromPathWith(d, path)
 	if err != nil {
-		return sanitizeSessionName(worktreeName)
+		return SafeSessionName(worktreeName)
 	}
 	return TmuxSessionName(ctx, worktreeName)
 }
@@ -186,6 +190,46 @@ func ListWorktreesWith(d *Deps, ctx *RepoContext) ([]Worktree, error) {
 	return parseWorktrees(output), nil
 }
 
+// BranchCommitTimes returns the last commit time of every local branch,
+// keyed by branch name, read with a single git for-each-ref.
+func BranchCommitTimes(ctx *RepoContext) (map[string]time.Time, error) {
+	return BranchCommitTimesWith(defaultDeps, ctx)
+}
+
+// BranchCommitTimesWith returns branch commit times using provided dependencies
+func BranchCommitTimesWith(d *Deps, ctx *RepoContext) (map[string]time.Time, error) {
+	output, err := d.Git.CommandInDir(ctx.GitRoot, "for-each-ref", "--format=%(refname:short)%09%(committerdate:unix)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	times := make(map[string]time.Time)
+	for _, line := range strings.Split(output, "\n") {
+		branch, unix, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		secs, err := strconv.ParseInt(strings.TrimSpace(unix), 10, 64)
+		if err != nil {
+			continue
+		}
+		times[branch] = time.Unix(secs, 0)
+	}
+	return times, nil
+}
+
+// SortWorktreesByBranchActivity orders worktrees by their branch's last
+// commit, oldest first, so stale branches sit at the top and the most active
+// last. Worktrees without a known branch time (detached HEADs) come first;
+// ties keep their input order.
+func SortWorktreesByBranchActivity(worktrees []Worktree, times map[string]time.Time) []Worktree {
+	sorted := make([]Worktree, len(worktrees))
+	copy(sorted, worktrees)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return times[sorted[i].Branch].Before(times[sorted[j].Branch])
+	})
+	return sorted
+}
+
 func parseWorktrees(output string) []Worktree {
 	var worktrees []Worktree
 	var current Worktree
@@ -228,7 +272,28 @@ func TmuxSessionName(ctx *RepoContext, worktreeName string) string {
 	} else {
 		name = worktreeName
 	}
-	return sanitizeSessionName(name)
+	return SafeSessionName(name)
+}
+
+// RepoSessionName returns the tmux session name for a repository as a whole,
+// the one [worktree] open_as = "window" opens worktrees in as windows: the
+// bare repo's name, or the main worktree's folder name for a regular repo,
+// even when ctx was detected from a linked worktree. Uses default
+// dependencies.
+func RepoSessionName(ctx *RepoContext) string {
+	return RepoSessionNameWith(defaultDeps, ctx)
+}
+
+// RepoSessionNameWith is the injectable variant of RepoSessionName.
+func RepoSessionNameWith(d *Deps, ctx *RepoContext) string {
+	if ctx.IsBare {
+		return SafeSessionName(ctx.RepoName)
+	}
+	commonDir, err := d.Git.CommandInDir(ctx.GitRoot, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil || commonDir == "" {
+		return SafeSessionName(ctx.RepoName)
+	}
+	return SafeSessionName(filepath.Base(filepath.Dir(strings.TrimSpace(commonDir))))
 }
 
 // FastSessionName returns a best-effort session name from a path without
@@ -239,13 +304,32 @@ func TmuxSessionName(ctx *RepoContext, worktreeName string) string {
 // only worktree. Use it only for fuzzy/bulk matching (dashboard history
 // sorting, test helpers) where speed matters more than exactness.
 func FastSessionName(path string) string {
-	return sanitizeSessionName(filepath.Base(path))
+	return SafeSessionName(filepath.Base(path))
 }
 
-func sanitizeSessionName(name string) string {
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, ":", "_")
-	return name
+// SafeSessionName makes name safe as a tmux session name and -t target. "."
+// and ":" become "_", as they always have: tmux splits targets on them. What
+// else tmux cannot target is written as "_" and two hex digits: spaces and
+// other control bytes, quotes, a leading "$", "@", "%" or "=" (read as an id
+// or exact-match marker) and every byte of a non-ASCII character, so
+// "my app" and "Café" become "my_20app" and "Caf_c3_a9". Any other name keeps
+// the session name pop always gave it. The name shown for a session is kept
+// in its @pop_name option, not recovered from the session name.
+func SafeSessionName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '.' || c == ':':
+			b.WriteByte('_')
+		case c <= ' ' || c >= 0x7f || c == '\'' || c == '"',
+			i == 0 && strings.IndexByte("$@%=", c) >= 0:
+			fmt.Fprintf(&b, "_%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
 }
 
 func findBareRootWith(d *Deps, startDir string) string {
@@ -349,6 +433,78 @@ func isCoreBareWith(d *Deps, gitDir string) bool {
 	return false
 }
 
+// BareWorktreeRepoWith reports whether path is a linked worktree of a bare
+// repo (file-based, no git commands). It follows the `gitdir:` line of the
+// worktree's .git file to <common>/worktrees/<name> and checks core.bare on the
+// common dir. repoRoot is the directory that stands for the whole repo — the
+// parent of a .bare or .git common dir, or the bare clone itself — so a
+// worktree matched directly by a glob can be named repo/worktree just like one
+// expanded from its repo.
+func BareWorktreeRepoWith(d *Deps, path string) (repoRoot string, ok bool) {
+	gitDir, ok := linkedGitDirWith(d, path)
+	if !ok {
+		return "", false
+	}
+
+	worktreesDir := filepath.Dir(gitDir)
+	if filepath.Base(worktreesDir) != "worktrees" {
+		return "", false
+	}
+	commonDir := filepath.Dir(worktreesDir)
+	if !isCoreBareWith(d, commonDir) {
+		return "", false
+	}
+	switch filepath.Base(commonDir) {
+	case ".bare", ".git":
+		return filepath.Dir(commonDir), true
+	}
+	return commonDir, true
+}
+
+// linkedGitDirWith returns the git dir a linked worktree's .git file points
+// at (<common>/worktrees/<name>), or false when path has no such file.
+func linkedGitDirWith(d *Deps, path string) (string, bool) {
+	gitFile := filepath.Join(path, ".git")
+	info, err := d.FS.Stat(gitFile)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	data, err := d.FS.ReadFile(gitFile)
+	if err != nil {
+		return "", false
+	}
+	gitDir, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !found {
+		return "", false
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(path, gitDir)
+	}
+	return filepath.Clean(gitDir), true
+}
+
+// WorktreeBranchWith returns the branch checked out in the linked worktree at
+// path (file-based, no git commands): the HEAD ref of the git dir its .git
+// file points at, without refs/heads/. It returns "detached" for a detached
+// HEAD and "" when the branch cannot be read.
+func WorktreeBranchWith(d *Deps, path string) string {
+	gitDir, ok := linkedGitDirWith(d, path)
+	if !ok {
+		return ""
+	}
+	data, err := d.FS.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	ref, found := strings.CutPrefix(head, "ref:")
+	if !found {
+		return "detached"
+	}
+	return strings.TrimPrefix(strings.TrimSpace(ref), "refs/heads/")
+}
+
 // ListWorktreesForPath returns worktrees for a given project path (file-based, no git commands)
 // Uses default dependencies
 func ListWorktreesForPath(path string) ([]Worktree, error) {
@@ -379,8 +535,9 @@ func ListWorktreesForPathWith(d *Deps, path string) ([]Worktree, error) {
 		}
 
 		worktrees = append(worktrees, Worktree{
-			Name: entry.Name(),
-			Path: wtPath,
+			Name:   entry.Name(),
+			Path:   wtPath,
+			Broken: StaleWorktreeWith(d, wtPath),
 		})
 	}
 
@@ -394,5 +551,20 @@ type ExpandedProject struct {
 	Path         string // Full path to the project/worktree
 	ProjectName  string // Base project name
 	IsWorktree   bool   // Whether this is a worktree of a bare repo
+	Branch       string // Checked-out branch of a worktree, when known
+	RepoRoot     string // Bare repo a worktree belongs to
 	SessionName  string // Pre-computed tmux session name
+	Group        string // Parent directory of the configured entry (the glob base), for group-by-parent
+	Type         string // Project type from its marker files (go, node, …), when icons are on
+
+	// Per-project overrides from the configured entry
+	Command   string   // Typed into a new session once it is created
+	Container string   // Docker container a new session execs into, before Command
+	Workbench string   // Fallback preferred Workbench for new sessions
+	Icon      string   // Picker icon when no session status icon applies
+	Tags      []string // Labels shown and matched in the picker
+	Pinned    bool     // Kept at the bottom of the picker, nearest the cursor
+	OpenWith  []string // Where Enter opens it (config.OpenWith*); nil = tmux only
+
+	Broken bool // Worktree whose .git file points at missing metadata (StaleWorktreeWith)
 }
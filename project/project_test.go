@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/glebglazov/pop/internal/deps"
 )
@@ -308,6 +310,12 @@ func TestTmuxSessionName(t *testing.T) {
 			worktreeName: "fix:bug",
 			expected:     "project_v1/fix_bug",
 		},
+		{
+			name:         "escapes spaces and unicode bytewise",
+			ctx:          &RepoContext{RepoName: "my app", IsBare: true},
+			worktreeName: "naïve",
+			expected:     "my_20app/na_c3_afve",
+		},
 	}
 
 	for _, tt := range tests {
@@ -320,6 +328,46 @@ func TestTmuxSessionName(t *testing.T) {
 	}
 }
 
+func TestRepoSessionNameWith(t *testing.T) {
+	commonDir := func(dir string, err error) *Deps {
+		return &Deps{Git: &deps.MockGit{
+			CommandInDirFunc: func(string, ...string) (string, error) { return dir, err },
+		}}
+	}
+	tests := []struct {
+		name     string
+		deps     *Deps
+		ctx      *RepoContext
+		expected string
+	}{
+		{
+			name:     "bare repo",
+			deps:     commonDir("", errors.New("unused")),
+			ctx:      &RepoContext{GitRoot: "/src/my.app", RepoName: "my.app", IsBare: true},
+			expected: "my_app",
+		},
+		{
+			name:     "linked worktree of a regular repo",
+			deps:     commonDir("/src/app/.git", nil),
+			ctx:      &RepoContext{GitRoot: "/src/app-feature", RepoName: "app-feature"},
+			expected: "app",
+		},
+		{
+			name:     "git failure falls back to the repo name",
+			deps:     commonDir("", errors.New("git too old")),
+			ctx:      &RepoContext{GitRoot: "/src/app", RepoName: "app"},
+			expected: "app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RepoSessionNameWith(tt.deps, tt.ctx); got != tt.expected {
+				t.Errorf("RepoSessionNameWith() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestDetectRepoContextWith_BareRepo(t *testing.T) {
 	d := &Deps{
 		Git: &deps.MockGit{
@@ -685,6 +733,123 @@ func TestListWorktreesForPathWith(t *testing.T) {
 	}
 }
 
+func TestBareWorktreeRepoWith(t *testing.T) {
+	bareConfig := []byte("[core]\n\tbare = true\n")
+	tests := []struct {
+		name     string
+		gitFile  string            // content of /dev/repo/wt/.git ("" = a directory)
+		configs  map[string][]byte // git config files by path
+		wantRoot string
+		wantOK   bool
+	}{
+		{
+			name:     ".bare layout, relative gitdir",
+			gitFile:  "gitdir: ../.bare/worktrees/wt\n",
+			configs:  map[string][]byte{"/dev/repo/.bare/config": bareConfig},
+			wantRoot: "/dev/repo",
+			wantOK:   true,
+		},
+		{
+			name:     "bare .git dir, absolute gitdir",
+			gitFile:  "gitdir: /dev/repo/.git/worktrees/wt",
+			configs:  map[string][]byte{"/dev/repo/.git/config": bareConfig},
+			wantRoot: "/dev/repo",
+			wantOK:   true,
+		},
+		{
+			name:     "bare clone layout",
+			gitFile:  "gitdir: /dev/repo.git/worktrees/wt",
+			configs:  map[string][]byte{"/dev/repo.git/config": bareConfig},
+			wantRoot: "/dev/repo.git",
+			wantOK:   true,
+		},
+		{
+			name:    "worktree of a non-bare repo",
+			gitFile: "gitdir: /dev/other/.git/worktrees/wt",
+			configs: map[string][]byte{"/dev/other/.git/config": []byte("[core]\n\tbare = false\n")},
+		},
+		{
+			name:    "submodule gitdir",
+			gitFile: "gitdir: ../.git/modules/wt",
+			configs: map[string][]byte{"/dev/repo/.git/config": bareConfig},
+		},
+		{
+			name: "regular checkout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Deps{
+				Git: &deps.MockGit{},
+				FS: &deps.MockFileSystem{
+					StatFunc: func(path string) (os.FileInfo, error) {
+						if path == "/dev/repo/wt/.git" {
+							return deps.MockFileInfo{IsDirVal: tt.gitFile == ""}, nil
+						}
+						return nil, os.ErrNotExist
+					},
+					ReadFileFunc: func(path string) ([]byte, error) {
+						if path == "/dev/repo/wt/.git" {
+							return []byte(tt.gitFile), nil
+						}
+						if data, ok := tt.configs[path]; ok {
+							return data, nil
+						}
+						return nil, os.ErrNotExist
+					},
+				},
+			}
+
+			root, ok := BareWorktreeRepoWith(d, "/dev/repo/wt")
+			if ok != tt.wantOK || root != tt.wantRoot {
+				t.Errorf("BareWorktreeRepoWith() = (%q, %v), want (%q, %v)", root, ok, tt.wantRoot, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWorktreeBranchWith(t *testing.T) {
+	tests := []struct {
+		name string
+		head string // content of the worktree's HEAD ("" = missing)
+		want string
+	}{
+		{name: "branch", head: "ref: refs/heads/feature/login\n", want: "feature/login"},
+		{name: "detached", head: "0123456789abcdef0123456789abcdef01234567\n", want: "detached"},
+		{name: "unreadable HEAD", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Deps{
+				Git: &deps.MockGit{},
+				FS: &deps.MockFileSystem{
+					StatFunc: func(path string) (os.FileInfo, error) {
+						if path == "/dev/repo/wt/.git" {
+							return deps.MockFileInfo{}, nil
+						}
+						return nil, os.ErrNotExist
+					},
+					ReadFileFunc: func(path string) ([]byte, error) {
+						switch {
+						case path == "/dev/repo/wt/.git":
+							return []byte("gitdir: ../.bare/worktrees/wt\n"), nil
+						case path == "/dev/repo/.bare/worktrees/wt/HEAD" && tt.head != "":
+							return []byte(tt.head), nil
+						}
+						return nil, os.ErrNotExist
+					},
+				},
+			}
+
+			if got := WorktreeBranchWith(d, "/dev/repo/wt"); got != tt.want {
+				t.Errorf("WorktreeBranchWith() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestListWorktreesWith(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -748,6 +913,57 @@ branch refs/heads/feature-branch
 	}
 }
 
+func TestBranchCommitTimesWith(t *testing.T) {
+	var gotArgs []string
+	d := &Deps{
+		Git: &deps.MockGit{
+			CommandInDirFunc: func(dir string, args ...string) (string, error) {
+				gotArgs = args
+				return "main\t1700000300\nfeature/login\t1700000100\nbroken\tnope\n", nil
+			},
+		},
+		FS: &deps.MockFileSystem{},
+	}
+
+	times, err := BranchCommitTimesWith(d, &RepoContext{GitRoot: "/projects/repo"})
+	if err != nil {
+		t.Fatalf("BranchCommitTimesWith() error = %v", err)
+	}
+	if len(gotArgs) == 0 || gotArgs[0] != "for-each-ref" {
+		t.Errorf("git args = %q, want a single for-each-ref", gotArgs)
+	}
+	if len(times) != 2 || times["main"].Unix() != 1700000300 || times["feature/login"].Unix() != 1700000100 {
+		t.Errorf("times = %v, want main and feature/login parsed, the bad line skipped", times)
+	}
+}
+
+func TestSortWorktreesByBranchActivity(t *testing.T) {
+	worktrees := []Worktree{
+		{Name: "main", Branch: "main"},
+		{Name: "old", Branch: "old"},
+		{Name: "spike", Branch: "detached"},
+		{Name: "new", Branch: "new"},
+	}
+	times := map[string]time.Time{
+		"main": time.Unix(200, 0),
+		"old":  time.Unix(100, 0),
+		"new":  time.Unix(300, 0),
+	}
+
+	sorted := SortWorktreesByBranchActivity(worktrees, times)
+
+	var got []string
+	for _, wt := range sorted {
+		got = append(got, wt.Name)
+	}
+	if want := "spike old main new"; strings.Join(got, " ") != want {
+		t.Errorf("order = %q, want %q (unknown first, most active last)", strings.Join(got, " "), want)
+	}
+	if worktrees[0].Name != "main" {
+		t.Error("SortWorktreesByBranchActivity reordered its input")
+	}
+}
+
 func TestCurrentCheckoutPathWith(t *testing.T) {
 	t.Run("returns cleaned worktree top-level", func(t *testing.T) {
 		d := &Deps{
@@ -0,0 +1,55 @@
+package project
+
+// projectTypeMarkers maps the files that mark a project's language or
+// toolchain to its type, most specific first: a Go service with a
+// package.json for its frontend tooling is still a Go project.
+var projectTypeMarkers = []struct {
+	file string
+	typ  string
+}{
+	{"go.mod", "go"},
+	{"Cargo.toml", "rust"},
+	{"mix.exs", "elixir"},
+	{"Package.swift", "swift"},
+	{"stack.yaml", "haskell"},
+	{"cabal.project", "haskell"},
+	{"pom.xml", "java"},
+	{"build.gradle", "java"},
+	{"build.gradle.kts", "java"},
+	{"Gemfile", "ruby"},
+	{"composer.json", "php"},
+	{"pyproject.toml", "python"},
+	{"setup.py", "python"},
+	{"requirements.txt", "python"},
+	{"Pipfile", "python"},
+	{"package.json", "node"},
+	{"CMakeLists.txt", "c"},
+	{"flake.nix", "nix"},
+	{"default.nix", "nix"},
+}
+
+// DetectProjectType returns the type of the project at path ("go", "node",
+// "rust", …) from the marker files at its top level, or "" when none is
+// found. Uses default dependencies.
+func DetectProjectType(path string) string {
+	return DetectProjectTypeWith(defaultDeps, path)
+}
+
+// DetectProjectTypeWith reads path's directory once and matches its entries
+// against the known marker files, using provided dependencies.
+func DetectProjectTypeWith(d *Deps, path string) string {
+	entries, err := d.FS.ReadDir(path)
+	if err != nil {
+		return ""
+	}
+	present := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		present[e.Name()] = true
+	}
+	for _, m := range projectTypeMarkers {
+		if present[m.file] {
+			return m.typ
+		}
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectProjectTypeWith(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "go module", files: []string{"go.mod", "main.go"}, want: "go"},
+		{name: "node package", files: []string{"package.json"}, want: "node"},
+		{name: "go wins over frontend tooling", files: []string{"package.json", "go.mod"}, want: "go"},
+		{name: "python requirements", files: []string{"requirements.txt"}, want: "python"},
+		{name: "no marker", files: []string{"README.md"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := DetectProjectTypeWith(DefaultDeps(), dir); got != tt.want {
+				t.Errorf("DetectProjectTypeWith() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := DetectProjectTypeWith(DefaultDeps(), filepath.Join(t.TempDir(), "missing")); got != "" {
+		t.Errorf("missing dir = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,32 @@
+package project
+
+import "fmt"
+
+// StaleWorktreeWith reports whether path is a linked worktree whose .git file
+// points at metadata that no longer exists, as after the repo was moved or its
+// worktrees/ entry pruned (file-based, no git commands). git refuses to work
+// in such a checkout until `git worktree repair` relinks it.
+func StaleWorktreeWith(d *Deps, path string) bool {
+	gitDir, ok := linkedGitDirWith(d, path)
+	if !ok {
+		return false
+	}
+	_, err := d.FS.Stat(gitDir)
+	return err != nil
+}
+
+// RepairWorktrees relinks the worktrees at paths to the repo at repoRoot.
+// Uses default dependencies.
+func RepairWorktrees(repoRoot string, paths ...string) error {
+	return RepairWorktreesWith(defaultDeps, repoRoot, paths...)
+}
+
+// RepairWorktreesWith runs `git worktree repair` in repoRoot for paths, using
+// provided dependencies. Run from the repo, git rewrites both each
+// worktree's .git file and the repo's pointer back to it.
+func RepairWorktreesWith(d *Deps, repoRoot string, paths ...string) error {
+	if _, err := d.Git.CommandInDir(repoRoot, append([]string{"worktree", "repair"}, paths...)...); err != nil {
+		return fmt.Errorf("git worktree repair: %w", err)
+	}
+	return nil
+}
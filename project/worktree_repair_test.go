@@ -0,0 +1,113 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// linkedWorktreeFS is a filesystem holding the worktree /src/repo/main, whose
+// .git file points at gitDir, and the paths in existing.
+func linkedWorktreeFS(gitDir string, existing ...string) *deps.MockFileSystem {
+	return &deps.MockFileSystem{
+		StatFunc: func(path string) (os.FileInfo, error) {
+			if path == "/src/repo/main/.git" {
+				return deps.MockFileInfo{NameVal: ".git"}, nil
+			}
+			for _, p := range existing {
+				if path == p {
+					return deps.MockFileInfo{IsDirVal: true}, nil
+				}
+			}
+			return nil, os.ErrNotExist
+		},
+		ReadFileFunc: func(path string) ([]byte, error) {
+			if path == "/src/repo/main/.git" {
+				return []byte("gitdir: " + gitDir + "\n"), nil
+			}
+			return nil, os.ErrNotExist
+		},
+	}
+}
+
+func TestStaleWorktreeWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		fs       *deps.MockFileSystem
+		expected bool
+	}{
+		{
+			name:     "metadata present",
+			fs:       linkedWorktreeFS("/src/repo/.bare/worktrees/main", "/src/repo/.bare/worktrees/main"),
+			expected: false,
+		},
+		{
+			name:     "repo moved away",
+			fs:       linkedWorktreeFS("/old/repo/.bare/worktrees/main"),
+			expected: true,
+		},
+		{
+			name:     "relative pointer resolved against the worktree",
+			fs:       linkedWorktreeFS("../.bare/worktrees/main", "/src/repo/.bare/worktrees/main"),
+			expected: false,
+		},
+		{
+			name:     "not a linked worktree",
+			fs:       &deps.MockFileSystem{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Deps{FS: tt.fs}
+			if got := StaleWorktreeWith(d, "/src/repo/main"); got != tt.expected {
+				t.Errorf("StaleWorktreeWith() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestListWorktreesForPathWithMarksBroken(t *testing.T) {
+	fs := linkedWorktreeFS("/old/repo/.bare/worktrees/main")
+	fs.ReadDirFunc = func(path string) ([]os.DirEntry, error) {
+		return []os.DirEntry{deps.MockDirEntry{NameVal: "main", IsDirVal: true}}, nil
+	}
+
+	worktrees, err := ListWorktreesForPathWith(&Deps{FS: fs}, "/src/repo")
+	if err != nil {
+		t.Fatalf("ListWorktreesForPathWith: %v", err)
+	}
+	if len(worktrees) != 1 || !worktrees[0].Broken {
+		t.Errorf("worktrees = %+v, want main marked broken", worktrees)
+	}
+}
+
+func TestRepairWorktreesWith(t *testing.T) {
+	var gotDir string
+	var gotArgs []string
+	d := &Deps{Git: &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			gotDir, gotArgs = dir, args
+			return "", nil
+		},
+	}}
+	if err := RepairWorktreesWith(d, "/src/repo", "/src/repo/main"); err != nil {
+		t.Fatalf("RepairWorktreesWith: %v", err)
+	}
+	if gotDir != "/src/repo" || strings.Join(gotArgs, " ") != "worktree repair /src/repo/main" {
+		t.Errorf("git -C %s %v, want git -C /src/repo worktree repair /src/repo/main", gotDir, gotArgs)
+	}
+
+	d.Git = &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			return "", errors.New("fatal: not a valid path")
+		},
+	}
+	if err := RepairWorktreesWith(d, "/src/repo", "/src/repo/main"); err == nil {
+		t.Error("RepairWorktreesWith() error = nil, want the git failure")
+	}
+}
@@ -0,0 +1,87 @@
+package project
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SyncStatus is the outcome of syncing one worktree.
+type SyncStatus string
+
+const (
+	SyncUpdated  SyncStatus = "updated"
+	SyncUpToDate SyncStatus = "up to date"
+	SyncSkipped  SyncStatus = "skipped"
+	SyncFailed   SyncStatus = "failed"
+)
+
+// SyncResult reports what SyncWorktrees did with one worktree. Detail says
+// why it was skipped or failed, or how many commits it moved.
+type SyncResult struct {
+	Worktree Worktree
+	Status   SyncStatus
+	Detail   string
+}
+
+// SyncWorktrees fetches the repo and fast-forwards every clean worktree that
+// tracks an upstream. Uses default dependencies.
+func SyncWorktrees(ctx *RepoContext) ([]SyncResult, error) {
+	return SyncWorktreesWith(defaultDeps, ctx)
+}
+
+// SyncWorktreesWith runs one `git fetch --all` for the repo, then brings each
+// worktree up to its upstream with `git merge --ff-only`, so a branch that
+// has diverged fails rather than merging. Worktrees on a detached HEAD, with
+// uncommitted changes, or without an upstream are skipped. Only a failed
+// fetch or worktree listing is returned as an error.
+func SyncWorktreesWith(d *Deps, ctx *RepoContext) ([]SyncResult, error) {
+	worktrees, err := ListWorktreesWith(d, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.Git.CommandInDir(ctx.GitRoot, "fetch", "--all", "--quiet"); err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+	results := make([]SyncResult, 0, len(worktrees))
+	for _, wt := range worktrees {
+		status, detail := syncWorktreeWith(d, wt)
+		results = append(results, SyncResult{Worktree: wt, Status: status, Detail: detail})
+	}
+	return results, nil
+}
+
+func syncWorktreeWith(d *Deps, wt Worktree) (SyncStatus, string) {
+	if wt.Branch == "" || wt.Branch == "detached" {
+		return SyncSkipped, "detached HEAD"
+	}
+	status, err := d.Git.CommandInDir(wt.Path, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return SyncFailed, err.Error()
+	}
+	if status != "" {
+		return SyncSkipped, "uncommitted changes"
+	}
+	upstream, err := d.Git.CommandInDir(wt.Path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return SyncSkipped, "no upstream"
+	}
+	behind, err := d.Git.CommandInDir(wt.Path, "rev-list", "--count", "HEAD..@{upstream}")
+	if err != nil {
+		return SyncFailed, err.Error()
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(behind))
+	if err != nil {
+		return SyncFailed, fmt.Sprintf("unexpected rev-list output %q", behind)
+	}
+	if n == 0 {
+		return SyncUpToDate, upstream
+	}
+	if _, err := d.Git.CommandInDir(wt.Path, "merge", "--ff-only", "--quiet", "@{upstream}"); err != nil {
+		return SyncFailed, "cannot fast-forward to " + upstream
+	}
+	if n == 1 {
+		return SyncUpdated, "1 commit from " + upstream
+	}
+	return SyncUpdated, fmt.Sprintf("%d commits from %s", n, upstream)
+}
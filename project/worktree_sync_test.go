@@ -0,0 +1,126 @@
+package project
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// syncTestGit fakes a bare repo with one worktree per case: clean and behind,
+// clean and current, dirty, without upstream, detached, and diverged.
+func syncTestGit(calls *[]string) *deps.MockGit {
+	return &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			call := dir + ": " + strings.Join(args, " ")
+			*calls = append(*calls, call)
+			switch {
+			case args[0] == "worktree":
+				return `worktree /repo/.bare
+bare
+
+worktree /repo/behind
+branch refs/heads/behind
+
+worktree /repo/current
+branch refs/heads/current
+
+worktree /repo/dirty
+branch refs/heads/dirty
+
+worktree /repo/local
+branch refs/heads/local
+
+worktree /repo/detached
+detached
+
+worktree /repo/diverged
+branch refs/heads/diverged
+`, nil
+			case args[0] == "fetch":
+				return "", nil
+			case args[0] == "status":
+				if dir == "/repo/dirty" {
+					return "M main.go", nil
+				}
+				return "", nil
+			case args[0] == "rev-parse":
+				if dir == "/repo/local" {
+					return "", fmt.Errorf("no upstream configured")
+				}
+				return "origin/" + dir[len("/repo/"):], nil
+			case args[0] == "rev-list":
+				if dir == "/repo/current" {
+					return "0", nil
+				}
+				return "3", nil
+			case args[0] == "merge":
+				if dir == "/repo/diverged" {
+					return "", fmt.Errorf("Not possible to fast-forward, aborting.")
+				}
+				return "", nil
+			}
+			return "", fmt.Errorf("unexpected git %s", call)
+		},
+	}
+}
+
+func TestSyncWorktreesWith(t *testing.T) {
+	var calls []string
+	d := &Deps{Git: syncTestGit(&calls)}
+	results, err := SyncWorktreesWith(d, &RepoContext{GitRoot: "/repo", RepoName: "repo", IsBare: true})
+	if err != nil {
+		t.Fatalf("SyncWorktreesWith() error: %v", err)
+	}
+
+	want := []struct {
+		name   string
+		status SyncStatus
+		detail string
+	}{
+		{"behind", SyncUpdated, "3 commits from origin/behind"},
+		{"current", SyncUpToDate, "origin/current"},
+		{"dirty", SyncSkipped, "uncommitted changes"},
+		{"local", SyncSkipped, "no upstream"},
+		{"detached", SyncSkipped, "detached HEAD"},
+		{"diverged", SyncFailed, "cannot fast-forward to origin/diverged"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Worktree.Name != w.name || r.Status != w.status || r.Detail != w.detail {
+			t.Errorf("result %d = {%s %s %q}, want {%s %s %q}", i, r.Worktree.Name, r.Status, r.Detail, w.name, w.status, w.detail)
+		}
+	}
+
+	if calls[1] != "/repo: fetch --all --quiet" {
+		t.Errorf("second git call = %q, want a single fetch of the repo", calls[1])
+	}
+	var merged []string
+	for _, c := range calls {
+		if strings.Contains(c, ": merge ") {
+			merged = append(merged, c[:strings.Index(c, ":")])
+		}
+	}
+	if !slices.Equal(merged, []string{"/repo/behind", "/repo/diverged"}) {
+		t.Errorf("merged in %v, want only the clean worktrees behind their upstream", merged)
+	}
+}
+
+func TestSyncWorktreesWith_FetchError(t *testing.T) {
+	d := &Deps{Git: &deps.MockGit{
+		CommandInDirFunc: func(dir string, args ...string) (string, error) {
+			if args[0] == "fetch" {
+				return "", fmt.Errorf("could not resolve host")
+			}
+			return "worktree /repo/main\nbranch refs/heads/main\n", nil
+		},
+	}}
+	if _, err := SyncWorktreesWith(d, &RepoContext{GitRoot: "/repo"}); err == nil || !strings.Contains(err.Error(), "could not resolve host") {
+		t.Errorf("err = %v, want the fetch error", err)
+	}
+}
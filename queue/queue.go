@@ -930,7 +930,15 @@ func loadRepoConfig(d *Deps, repoRoot string) (config.RepoConfig, error) {
 	if pd == nil || pd.FS == nil {
 		pd = project.DefaultDeps()
 	}
-	return config.LoadRepoConfigWith(&config.Deps{FS: pd.FS}, repoRoot)
+	return config.LoadRepoConfigWith(configDeps(pd), repoRoot)
+}
+
+// configDeps returns config's default dependencies reading through pd's
+// filesystem, so paths in the config expand ~user and %VAR% as anywhere else.
+func configDeps(pd *project.Deps) *config.Deps {
+	cd := config.DefaultDeps()
+	cd.FS = pd.FS
+	return cd
 }
 
 // firstAwaitingApprovalSetID returns the ID of the first Task-set in
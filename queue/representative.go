@@ -432,7 +432,7 @@ func resolveRepoConfigFor(d *Deps, cfg *config.Config, checkoutPath string) (con
 	if pd == nil || pd.FS == nil {
 		pd = project.DefaultDeps()
 	}
-	cd := &config.Deps{FS: pd.FS}
+	cd := configDeps(pd)
 	if cfg == nil {
 		return config.LoadRepoConfigWith(cd, checkoutPath)
 	}
@@ -374,3 +374,14 @@ func canon(t *testing.T, d *Deps, path string) string {
 	}
 	return c
 }
+
+func TestConfigDepsExpandsLikeTheConfig(t *testing.T) {
+	pd := project.DefaultDeps()
+	cd := configDeps(pd)
+	if cd.FS != pd.FS {
+		t.Error("configDeps() does not read through the project filesystem")
+	}
+	if cd.Users == nil {
+		t.Error("configDeps() leaves ~user paths in [repo.\"...\"] keys unexpanded")
+	}
+}
@@ -37,7 +37,7 @@ func DefaultDeps() *Deps {
 	taskDeps := tasks.DefaultDeps()
 	return &Deps{
 		FS:            deps.NewRealFileSystem(),
-		OpenEditor:    defaultOpenEditor,
+		OpenEditor:    OpenEditor,
 		OpenPager:     defaultOpenPager,
 		IsInteractive: defaultIsInteractive,
 		InTmux:        func() bool { return os.Getenv("TMUX") != "" },
@@ -92,7 +92,9 @@ func ListRoutines(d *Deps) ([]*Routine, []RoutineWarning, error) {
 	return routines, warnings, nil
 }
 
-func defaultOpenEditor(path string) error {
+// OpenEditor opens path in $EDITOR, falling back to vi, on the terminal pop
+// runs in.
+func OpenEditor(path string) error {
 	editor := strings.TrimSpace(os.Getenv("EDITOR"))
 	if editor == "" {
 		editor = "vi"
@@ -0,0 +1,80 @@
+package session
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SendCD types a cd into path followed by clear at the pane's prompt and
+// presses Enter.
+func SendCD(paneID, path string) error {
+	return SendCDWith(DefaultDeps(), paneID, path)
+}
+
+// SendCDWith is the injectable variant of SendCD.
+func SendCDWith(d *Deps, paneID, path string) error {
+	_, err := d.Tmux.Command("send-keys", "-t", paneID, fmt.Sprintf("cd %q && clear", path), "Enter")
+	return err
+}
+
+// SendText types text at the pane's prompt without pressing Enter.
+func SendText(paneID, text string) error {
+	return SendTextWith(DefaultDeps(), paneID, text)
+}
+
+// SendTextWith is the injectable variant of SendText.
+func SendTextWith(d *Deps, paneID, text string) error {
+	_, err := d.Tmux.Command("send-keys", "-t", paneID, text)
+	return err
+}
+
+// SendCommand types command at target's prompt and presses Enter, running it
+// in the target's shell.
+func SendCommand(target, command string) error {
+	return SendCommandWith(DefaultDeps(), target, command)
+}
+
+// SendCommandWith is the injectable variant of SendCommand.
+func SendCommandWith(d *Deps, target, command string) error {
+	_, err := d.Tmux.Command("send-keys", "-t", target, command, "Enter")
+	return err
+}
+
+// shells are the programs tmux reports as a pane's current command while it
+// sits at a shell prompt.
+var shells = []string{
+	"sh", "bash", "zsh", "fish", "dash", "ksh", "mksh", "yash",
+	"csh", "tcsh", "nu", "xonsh", "elvish", "pwsh", "osh", "ysh",
+}
+
+// IsShell reports whether command, a pane_current_command value, is an
+// interactive shell. Login shells report with a leading "-".
+func IsShell(command string) bool {
+	return slices.Contains(shells, strings.TrimPrefix(command, "-"))
+}
+
+// PaneCommand returns the program running in the foreground of paneID.
+func PaneCommand(paneID string) (string, error) {
+	return PaneCommandWith(DefaultDeps(), paneID)
+}
+
+// PaneCommandWith is the injectable variant of PaneCommand.
+func PaneCommandWith(d *Deps, paneID string) (string, error) {
+	out, err := d.Tmux.Command("display-message", "-p", "-t", paneID, "#{pane_current_command}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// SplitPane opens a new pane beside paneID with dir as its working directory.
+func SplitPane(paneID, dir string) error {
+	return SplitPaneWith(DefaultDeps(), paneID, dir)
+}
+
+// SplitPaneWith is the injectable variant of SplitPane.
+func SplitPaneWith(d *Deps, paneID, dir string) error {
+	_, err := d.Tmux.Command("split-window", "-t", paneID, "-c", dir)
+	return err
+}
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func recordCommand(got *[]string) *Deps {
+	return &Deps{Tmux: &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			*got = args
+			return "", nil
+		},
+	}}
+}
+
+func TestSendCDWith(t *testing.T) {
+	var got []string
+	if err := SendCDWith(recordCommand(&got), "%3", "/src/my app"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"send-keys", "-t", "%3", `cd "/src/my app" && clear`, "Enter"}
+	if len(got) != len(want) {
+		t.Fatalf("command = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("command = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSendTextWith(t *testing.T) {
+	var got []string
+	if err := SendTextWith(recordCommand(&got), "%3", "/src/api"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 4 || got[2] != "%3" || got[3] != "/src/api" {
+		t.Errorf("command = %q, want send-keys -t %%3 /src/api with no Enter", got)
+	}
+}
+
+func TestSendCommandWith(t *testing.T) {
+	var got []string
+	if err := SendCommandWith(recordCommand(&got), "=api:", "make dev"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"send-keys", "-t", "=api:", "make dev", "Enter"}
+	if len(got) != len(want) {
+		t.Fatalf("command = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("command = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestIsShell(t *testing.T) {
+	for _, command := range []string{"zsh", "-zsh", "bash", "fish"} {
+		if !IsShell(command) {
+			t.Errorf("IsShell(%q) = false, want true", command)
+		}
+	}
+	for _, command := range []string{"nvim", "python3", "ssh", ""} {
+		if IsShell(command) {
+			t.Errorf("IsShell(%q) = true, want false", command)
+		}
+	}
+}
+
+func TestPaneCommandWith(t *testing.T) {
+	var got []string
+	d := &Deps{Tmux: &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			got = args
+			return "nvim\n", nil
+		},
+	}}
+	command, err := PaneCommandWith(d, "%3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if command != "nvim" {
+		t.Errorf("PaneCommandWith() = %q, want nvim", command)
+	}
+	if len(got) != 5 || got[3] != "%3" || got[4] != "#{pane_current_command}" {
+		t.Errorf("command = %q, want display-message for %%3's current command", got)
+	}
+}
+
+func TestSplitPaneWith(t *testing.T) {
+	var got []string
+	if err := SplitPaneWith(recordCommand(&got), "%3", "/src/api"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"split-window", "-t", "%3", "-c", "/src/api"}
+	if len(got) != len(want) {
+		t.Fatalf("command = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("command = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package session
+
+// Kill kills the tmux session called name.
+func Kill(name string) error {
+	return KillWith(DefaultDeps(), name)
+}
+
+// KillWith is the injectable variant of Kill.
+func KillWith(d *Deps, name string) error {
+	_, err := d.Tmux.Command("kill-session", "-t", name)
+	return err
+}
@@ -0,0 +1,37 @@
+package session
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func TestKillWith(t *testing.T) {
+	var got []string
+	d := &Deps{Tmux: &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			got = args
+			return "", nil
+		},
+	}}
+
+	if err := KillWith(d, "my.project"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 || got[0] != "kill-session" || got[2] != "my.project" {
+		t.Errorf("command = %v, want kill-session -t my.project", got)
+	}
+}
+
+func TestKillWith_Error(t *testing.T) {
+	d := &Deps{Tmux: &deps.MockTmux{
+		CommandFunc: func(args ...string) (string, error) {
+			return "", errors.New("can't find session")
+		},
+	}}
+
+	if err := KillWith(d, "gone"); err == nil {
+		t.Error("expected error from kill-session to be returned")
+	}
+}
@@ -1,5 +1,7 @@
 package session
 
+import "github.com/glebglazov/pop/debug"
+
 // SwitchTarget jumps to an existing tmux session or pane ID without creating
 // anything. Uses switch-client when already inside tmux, attach-session with
 // stdio wired when outside.
@@ -14,3 +16,30 @@ func SwitchTargetWith(d *Deps, target string) error {
 	}
 	return d.Tmux.AttachSession(target)
 }
+
+// SwitchTargetZoomed is SwitchTarget for a pane ID that also zooms the pane
+// unless its window is already zoomed.
+func SwitchTargetZoomed(target string) error {
+	return SwitchTargetZoomedWith(DefaultDeps(), target)
+}
+
+// SwitchTargetZoomedWith is the injectable variant of SwitchTargetZoomed.
+func SwitchTargetZoomedWith(d *Deps, target string) error {
+	if d.InTmux() {
+		// Single tmux invocation: switch to pane and zoom it if not already zoomed
+		_, err := d.Tmux.Command(
+			"switch-client", "-t", target, ";",
+			"if-shell", "-F", "#{!=:#{window_zoomed_flag},1}",
+			"resize-pane -Z",
+		)
+		return err
+	}
+	// Outside tmux: zoom before attaching since attach takes over stdio
+	if _, err := d.Tmux.Command(
+		"if-shell", "-t", target, "-F", "#{!=:#{window_zoomed_flag},1}",
+		"resize-pane -Z",
+	); err != nil {
+		debug.Error("SwitchTargetZoomed: pre-attach zoom: %v", err)
+	}
+	return SwitchTargetWith(d, target)
+}
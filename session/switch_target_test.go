@@ -59,3 +59,45 @@ func TestSwitchTargetWith_OutsideTmux(t *testing.T) {
 		t.Errorf("AttachSession calls = %v, want [my-session]", log.attach)
 	}
 }
+
+func TestSwitchTargetZoomedWith_InTmux(t *testing.T) {
+	var log switchTargetCallLog
+	tmux := log.mock()
+	var commands [][]string
+	tmux.CommandFunc = func(args ...string) (string, error) {
+		commands = append(commands, args)
+		return "", nil
+	}
+	d := &Deps{Tmux: tmux, InTmux: func() bool { return true }}
+
+	if err := SwitchTargetZoomedWith(d, "%5"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commands) != 1 || commands[0][0] != "switch-client" || commands[0][2] != "%5" {
+		t.Errorf("commands = %v, want one chained switch-client -t %%5", commands)
+	}
+	if len(log.switchClient) != 0 {
+		t.Errorf("SwitchClient calls = %v, want none", log.switchClient)
+	}
+}
+
+func TestSwitchTargetZoomedWith_OutsideTmux(t *testing.T) {
+	var log switchTargetCallLog
+	tmux := log.mock()
+	var commands [][]string
+	tmux.CommandFunc = func(args ...string) (string, error) {
+		commands = append(commands, args)
+		return "", nil
+	}
+	d := &Deps{Tmux: tmux, InTmux: func() bool { return false }}
+
+	if err := SwitchTargetZoomedWith(d, "%5"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commands) != 1 || commands[0][0] != "if-shell" {
+		t.Errorf("commands = %v, want a single pre-attach if-shell zoom", commands)
+	}
+	if len(log.attach) != 1 || log.attach[0] != "%5" {
+		t.Errorf("AttachSession calls = %v, want [%%5]", log.attach)
+	}
+}
@@ -0,0 +1,121 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// OpenWindow selects the window called name in the current tmux session,
+// creating it with path as its working directory when none exists.
+func OpenWindow(name, path string) error {
+	return OpenWindowWith(DefaultDeps(), name, path)
+}
+
+// OpenWindowWith is the injectable variant of OpenWindow.
+func OpenWindowWith(d *Deps, name, path string) error {
+	current, err := d.Tmux.Command("display-message", "-p", "#S")
+	if err != nil {
+		return fmt.Errorf("failed to get current tmux session: %w", err)
+	}
+
+	listOut, err := d.Tmux.Command("list-windows", "-t", current, "-F", "#{window_name}")
+	if err != nil {
+		return fmt.Errorf("failed to list tmux windows: %w", err)
+	}
+
+	for _, w := range strings.Split(listOut, "\n") {
+		if w == name {
+			_, err := d.Tmux.Command("select-window", "-t", current+":"+name)
+			return err
+		}
+	}
+
+	_, err = d.Tmux.Command("new-window", "-t", current, "-n", name, "-c", path)
+	return err
+}
+
+// windowPathOption is the tmux window option AttachWindow tags its windows
+// with: the path the window was opened for.
+const windowPathOption = "@pop_path"
+
+// AttachWindow switches to the window for path in the tmux session called
+// session, creating the session and the window, with path as the working
+// directory, as needed. A session created here starts with that window. The
+// window is called name, or parent/name when another path's window already
+// is, such as a second "main" worktree.
+func AttachWindow(session, name, path string) error {
+	return AttachWindowWith(DefaultDeps(), session, name, path)
+}
+
+// AttachWindowWith is the injectable variant of AttachWindow.
+func AttachWindowWith(d *Deps, session, name, path string) error {
+	if !d.Tmux.HasSession(session) {
+		if err := d.Tmux.NewSession(session, path); err != nil {
+			return fmt.Errorf("failed to create tmux session: %w", err)
+		}
+		if _, err := d.Tmux.Command("rename-window", "-t", session, name); err != nil {
+			return fmt.Errorf("failed to name tmux window: %w", err)
+		}
+		if err := tagWindow(d, session, path); err != nil {
+			return err
+		}
+		return SwitchTargetWith(d, session)
+	}
+
+	listOut, err := d.Tmux.Command("list-windows", "-t", session, "-F", "#{window_id}\t#{"+windowPathOption+"}\t#{window_name}")
+	if err != nil {
+		return fmt.Errorf("failed to list tmux windows: %w", err)
+	}
+	var window, untagged string
+	taken := false
+	for _, line := range strings.Split(listOut, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		id, windowPath, windowName := fields[0], fields[1], fields[2]
+		if windowPath == path {
+			window = id
+			break
+		}
+		if windowName == name {
+			if windowPath == "" && untagged == "" {
+				untagged = id
+			} else {
+				taken = true
+			}
+		}
+	}
+	// A window opened before windows were tagged is known only by its name.
+	if window == "" && untagged != "" {
+		window = untagged
+		if err := tagWindow(d, window, path); err != nil {
+			return err
+		}
+	}
+	if window == "" {
+		if taken {
+			name = filepath.Base(filepath.Dir(path)) + "/" + name
+		}
+		window, err = d.Tmux.Command("new-window", "-d", "-t", session, "-n", name, "-c", path, "-P", "-F", "#{window_id}")
+		if err != nil {
+			return fmt.Errorf("failed to create tmux window: %w", err)
+		}
+		if err := tagWindow(d, window, path); err != nil {
+			return err
+		}
+	}
+	if _, err := d.Tmux.Command("select-window", "-t", window); err != nil {
+		return fmt.Errorf("failed to select tmux window: %w", err)
+	}
+	return SwitchTargetWith(d, session)
+}
+
+// tagWindow records on the window at target the path it was opened for.
+func tagWindow(d *Deps, target, path string) error {
+	if _, err := d.Tmux.Command("set-option", "-w", "-t", target, windowPathOption, path); err != nil {
+		return fmt.Errorf("failed to tag tmux window: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package session
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+func TestOpenWindowWith(t *testing.T) {
+	t.Run("selects existing window", func(t *testing.T) {
+		var calls [][]string
+		d := &Deps{Tmux: &deps.MockTmux{
+			CommandFunc: func(args ...string) (string, error) {
+				calls = append(calls, args)
+				switch args[0] {
+				case "display-message":
+					return "main", nil
+				case "list-windows":
+					return "edit\napi", nil
+				}
+				return "", nil
+			},
+		}}
+
+		if err := OpenWindowWith(d, "api", "/src/api"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		last := calls[len(calls)-1]
+		if last[0] != "select-window" || last[2] != "main:api" {
+			t.Errorf("last call = %v, want select-window -t main:api", last)
+		}
+	})
+
+	t.Run("creates missing window at path", func(t *testing.T) {
+		var last []string
+		d := &Deps{Tmux: &deps.MockTmux{
+			CommandFunc: func(args ...string) (string, error) {
+				last = args
+				switch args[0] {
+				case "display-message":
+					return "main", nil
+				case "list-windows":
+					return "edit", nil
+				}
+				return "", nil
+			},
+		}}
+
+		if err := OpenWindowWith(d, "api", "/src/api"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"new-window", "-t", "main", "-n", "api", "-c", "/src/api"}
+		if len(last) != len(want) {
+			t.Fatalf("last call = %v, want %v", last, want)
+		}
+		for i := range want {
+			if last[i] != want[i] {
+				t.Errorf("last call = %v, want %v", last, want)
+				break
+			}
+		}
+	})
+
+	t.Run("current session lookup failure", func(t *testing.T) {
+		d := &Deps{Tmux: &deps.MockTmux{
+			CommandFunc: func(args ...string) (string, error) {
+				return "", errors.New("no server")
+			},
+		}}
+
+		if err := OpenWindowWith(d, "api", "/src/api"); err == nil {
+			t.Error("expected error when the current session cannot be read")
+		}
+	})
+}
+
+func TestAttachWindowWith(t *testing.T) {
+	t.Run("new session starts with the window", func(t *testing.T) {
+		var log attachCallLog
+		var calls [][]string
+		tmux := log.mock(false)
+		tmux.CommandFunc = func(args ...string) (string, error) {
+			calls = append(calls, args)
+			return "", nil
+		}
+		d := &Deps{Tmux: tmux, InTmux: func() bool { return true }}
+
+		if err := AttachWindowWith(d, "api", "feature-x", "/src/api/feature-x"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(log.newSession) != 1 || log.newSession[0] != [2]string{"api", "/src/api/feature-x"} {
+			t.Errorf("NewSession calls = %v, want [api /src/api/feature-x]", log.newSession)
+		}
+		if len(calls) != 2 || strings.Join(calls[0], " ") != "rename-window -t api feature-x" ||
+			strings.Join(calls[1], " ") != "set-option -w -t api @pop_path /src/api/feature-x" {
+			t.Errorf("tmux calls = %v, want the window named feature-x and tagged with its path", calls)
+		}
+		if len(log.switchClient) != 1 || log.switchClient[0] != "api" {
+			t.Errorf("SwitchClient calls = %v, want [api]", log.switchClient)
+		}
+	})
+
+	t.Run("selects existing window", func(t *testing.T) {
+		var log attachCallLog
+		var calls [][]string
+		tmux := log.mock(true)
+		tmux.CommandFunc = func(args ...string) (string, error) {
+			calls = append(calls, args)
+			if args[0] == "list-windows" {
+				return "@1\t/src/api/main\tmain\n@4\t/src/api/feature-x\tfeature-x", nil
+			}
+			return "", nil
+		}
+		d := &Deps{Tmux: tmux, InTmux: func() bool { return true }}
+
+		if err := AttachWindowWith(d, "api", "feature-x", "/src/api/feature-x"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if last := strings.Join(calls[len(calls)-1], " "); last != "select-window -t @4" {
+			t.Errorf("last call = %q, want select-window -t @4", last)
+		}
+		if len(log.switchClient) != 1 || log.switchClient[0] != "api" {
+			t.Errorf("SwitchClient calls = %v, want [api]", log.switchClient)
+		}
+	})
+
+	t.Run("creates missing window at path", func(t *testing.T) {
+		var log attachCallLog
+		var calls [][]string
+		tmux := log.mock(true)
+		tmux.CommandFunc = func(args ...string) (string, error) {
+			calls = append(calls, args)
+			switch args[0] {
+			case "list-windows":
+				return "@1\t/src/api/main\tmain", nil
+			case "new-window":
+				return "@7", nil
+			}
+			return "", nil
+		}
+		d := &Deps{Tmux: tmux, InTmux: func() bool { return true }}
+
+		if err := AttachWindowWith(d, "api", "feature-x", "/src/api/feature-x"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{
+			"list-windows -t api -F #{window_id}\t#{@pop_path}\t#{window_name}",
+			"new-window -d -t api -n feature-x -c /src/api/feature-x -P -F #{window_id}",
+			"set-option -w -t @7 @pop_path /src/api/feature-x",
+			"select-window -t @7",
+		}
+		if len(calls) != len(want) {
+			t.Fatalf("tmux calls = %v, want %v", calls, want)
+		}
+		for i := range want {
+			if got := strings.Join(calls[i], " "); got != want[i] {
+				t.Errorf("call %d = %q, want %q", i, got, want[i])
+			}
+		}
+	})
+	t.Run("worktree sharing a name gets its own window", func(t *testing.T) {
+		var log attachCallLog
+		var calls []string
+		tmux := log.mock(true)
+		tmux.CommandFunc = func(args ...string) (string, error) {
+			calls = append(calls, strings.Join(args, " "))
+			switch args[0] {
+			case "list-windows":
+				return "@1\t/src/api/main\tmain", nil
+			case "new-window":
+				return "@7", nil
+			}
+			return "", nil
+		}
+		d := &Deps{Tmux: tmux, InTmux: func() bool { return true }}
+
+		if err := AttachWindowWith(d, "api", "main", "/src/fork/main"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := "new-window -d -t api -n fork/main -c /src/fork/main -P -F #{window_id}"; len(calls) < 2 || calls[1] != want {
+			t.Errorf("tmux calls = %v, want %q", calls, want)
+		}
+	})
+
+	t.Run("adopts an untagged window by name", func(t *testing.T) {
+		var log attachCallLog
+		var calls []string
+		tmux := log.mock(true)
+		tmux.CommandFunc = func(args ...string) (string, error) {
+			calls = append(calls, strings.Join(args, " "))
+			if args[0] == "list-windows" {
+				return "@3\t\tfeature-x", nil
+			}
+			return "", nil
+		}
+		d := &Deps{Tmux: tmux, InTmux: func() bool { return true }}
+
+		if err := AttachWindowWith(d, "api", "feature-x", "/src/api/feature-x"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{
+			"list-windows -t api -F #{window_id}\t#{@pop_path}\t#{window_name}",
+			"set-option -w -t @3 @pop_path /src/api/feature-x",
+			"select-window -t @3",
+		}
+		if !slices.Equal(calls, want) {
+			t.Errorf("tmux calls = %v, want %v", calls, want)
+		}
+	})
+}
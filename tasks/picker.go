@@ -71,6 +71,15 @@ func expandConfiguredPaths(pd *project.Deps, paths []config.ExpandedPath) ([]pro
 						IsWorktree:   true,
 					})
 				}
+			} else if repoRoot, ok := project.BareWorktreeRepoWith(pd, ep.Path); ok {
+				repoLabel := ui.LastNSegments(repoRoot, ep.DisplayDepth)
+				projects = append(projects, project.ExpandedProject{
+					Name:         repoLabel + "/" + projectName,
+					ProjectLabel: repoLabel,
+					Path:         ep.Path,
+					ProjectName:  filepath.Base(repoRoot),
+					IsWorktree:   true,
+				})
 			} else {
 				projects = append(projects, project.ExpandedProject{
 					Name:         displayName,
@@ -0,0 +1,115 @@
+// Package tips serves one-time hints for the picker footer. Each Tip is shown
+// at most once per user: the IDs already shown are persisted in pop's data
+// dir, so a tip retires as soon as it has been surfaced.
+package tips
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// Tip is a single one-time hint. ID is the stable key persisted once the tip
+// has been shown; Text is what the footer renders.
+type Tip struct {
+	ID   string
+	Text string
+}
+
+// state is the persisted record of shown tips, keyed by Tip.ID.
+type state struct {
+	Shown map[string]time.Time `json:"shown"`
+}
+
+// Deps holds the dependencies behind the tips state: a filesystem, a clock,
+// and the state file path.
+type Deps struct {
+	FS        deps.FileSystem
+	Now       func() time.Time
+	StatePath string
+}
+
+// DefaultDeps returns tips dependencies wired to real implementations.
+func DefaultDeps() *Deps {
+	fs := deps.NewRealFileSystem()
+	return &Deps{
+		FS:        fs,
+		Now:       time.Now,
+		StatePath: defaultStatePath(fs),
+	}
+}
+
+// defaultStatePath returns the tips state path in pop's data dir, respecting
+// XDG_DATA_HOME with the ~/.local/share/pop fallback, consistent with the
+// history and update-notice paths.
+func defaultStatePath(fs deps.FileSystem) string {
+	if xdgData := fs.Getenv("XDG_DATA_HOME"); xdgData != "" {
+		return filepath.Join(xdgData, "pop", "tips.json")
+	}
+	home, err := fs.UserHomeDir()
+	if err != nil {
+		debug.Error("tips: UserHomeDir: %v", err)
+	}
+	return filepath.Join(home, ".local", "share", "pop", "tips.json")
+}
+
+// Next returns the first tip in candidates that has not been shown yet and
+// records it as shown, so the next call moves on to the following one. ok is
+// false once every candidate has been shown.
+func Next(candidates []Tip) (Tip, bool) {
+	return NextWith(DefaultDeps(), candidates)
+}
+
+// NextWith is Next using provided dependencies. Persisting is best-effort: a
+// state file that cannot be written only means the tip may show again.
+func NextWith(d *Deps, candidates []Tip) (Tip, bool) {
+	st := loadState(d)
+	for _, tip := range candidates {
+		if _, shown := st.Shown[tip.ID]; shown {
+			continue
+		}
+		st.Shown[tip.ID] = d.Now()
+		saveState(d, st)
+		return tip, true
+	}
+	return Tip{}, false
+}
+
+// loadState reads the state file, returning an empty state on any error
+// (missing, unreadable, or malformed) — tips are best-effort.
+func loadState(d *Deps) state {
+	st := state{Shown: map[string]time.Time{}}
+	data, err := d.FS.ReadFile(d.StatePath)
+	if err != nil {
+		return st
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		debug.Error("tips: unmarshal state %s: %v", d.StatePath, err)
+		return state{Shown: map[string]time.Time{}}
+	}
+	if st.Shown == nil {
+		st.Shown = map[string]time.Time{}
+	}
+	return st
+}
+
+// saveState writes the state file, creating the data dir as needed. Errors are
+// logged but never surfaced.
+func saveState(d *Deps, st state) {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		debug.Error("tips: marshal state: %v", err)
+		return
+	}
+	dir := filepath.Dir(d.StatePath)
+	if err := d.FS.MkdirAll(dir, 0o755); err != nil {
+		debug.Error("tips: mkdir %s: %v", dir, err)
+		return
+	}
+	if err := d.FS.WriteFile(d.StatePath, data, 0o644); err != nil {
+		debug.Error("tips: write %s: %v", d.StatePath, err)
+	}
+}
@@ -0,0 +1,77 @@
+package tips
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// memFS is an in-memory FileSystem so state written by one NextWith call is
+// observable by the next.
+type memFS struct {
+	deps.MockFileSystem
+	files map[string][]byte
+}
+
+func newMemFS() *memFS {
+	m := &memFS{files: map[string][]byte{}}
+	m.ReadFileFunc = func(path string) ([]byte, error) {
+		data, ok := m.files[path]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return data, nil
+	}
+	m.WriteFileFunc = func(path string, data []byte, _ os.FileMode) error {
+		m.files[path] = append([]byte(nil), data...)
+		return nil
+	}
+	m.MkdirAllFunc = func(string, os.FileMode) error { return nil }
+	return m
+}
+
+const statePath = "/data/pop/tips.json"
+
+func tipsDeps(fs *memFS) *Deps {
+	return &Deps{
+		FS:        fs,
+		Now:       func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+		StatePath: statePath,
+	}
+}
+
+func TestNextWith_ShowsEachTipOnce(t *testing.T) {
+	d := tipsDeps(newMemFS())
+	candidates := []Tip{{ID: "a", Text: "first"}, {ID: "b", Text: "second"}}
+
+	for _, want := range []string{"a", "b"} {
+		tip, ok := NextWith(d, candidates)
+		if !ok || tip.ID != want {
+			t.Fatalf("NextWith = (%+v, %v), want tip %q", tip, ok, want)
+		}
+	}
+	if tip, ok := NextWith(d, candidates); ok {
+		t.Errorf("NextWith after all shown = %+v, want none", tip)
+	}
+}
+
+func TestNextWith_NewTipAfterOthersShown(t *testing.T) {
+	d := tipsDeps(newMemFS())
+	NextWith(d, []Tip{{ID: "a"}})
+
+	tip, ok := NextWith(d, []Tip{{ID: "a"}, {ID: "c", Text: "new"}})
+	if !ok || tip.ID != "c" {
+		t.Errorf("NextWith = (%+v, %v), want the unseen tip c", tip, ok)
+	}
+}
+
+func TestNextWith_MalformedStateStartsFresh(t *testing.T) {
+	fs := newMemFS()
+	fs.files[statePath] = []byte("{not json")
+	tip, ok := NextWith(tipsDeps(fs), []Tip{{ID: "a"}})
+	if !ok || tip.ID != "a" {
+		t.Errorf("NextWith = (%+v, %v), want tip a", tip, ok)
+	}
+}
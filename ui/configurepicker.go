@@ -34,12 +34,16 @@ type ConfigurePicker struct {
 	path          string   // confirmed path text (preserved across transitions)
 	depth         int      // current depth (preserved across transitions)
 	expandedPaths []string // raw absolute paths from expandFn
+	worktrees     []int    // worktree count of each of expandedPaths
 	preview       []string // display names computed from expandedPaths + depth
 	height        int
 	width         int
 	cancelled     bool
 	confirmed     bool
 	expandFn      func(string) []string
+	worktreeFn    func(string) int
+	collisionFn   func(paths []string, depth int) int
+	collisions    int // previewed names collisionFn reports colliding
 
 	// Cursor position memory per phase
 	pathCursor  int // remembered cursor position in path phase
@@ -51,18 +55,55 @@ type ConfigurePicker struct {
 	tabPrefix  string   // the text that was present when Tab was first pressed
 
 	showHelp bool
+
+	styles *styles // the colors it draws with, themed by WithConfigureTheme
+}
+
+// ConfigurePickerOption configures the configure picker
+type ConfigurePickerOption func(*ConfigurePicker)
+
+// WithWorktreeCount annotates preview entries with the number of worktrees
+// fn reports for their path, for matches the picker would expand into one
+// entry per worktree; fn returns 0 for everything else.
+func WithWorktreeCount(fn func(path string) int) ConfigurePickerOption {
+	return func(cp *ConfigurePicker) {
+		cp.worktreeFn = fn
+	}
+}
+
+// WithCollisionCount reports, in the preview summary, how many previewed names
+// fn finds colliding with each other or with projects already configured at
+// the chosen depth. The picker would disambiguate them, so the count is a cue
+// to raise the depth.
+func WithCollisionCount(fn func(paths []string, depth int) int) ConfigurePickerOption {
+	return func(cp *ConfigurePicker) {
+		cp.collisionFn = fn
+	}
+}
+
+// WithConfigureTheme draws the configure picker in t's colors.
+func WithConfigureTheme(t Theme) ConfigurePickerOption {
+	return func(cp *ConfigurePicker) {
+		cp.styles = t.styles()
+	}
 }
 
 // NewConfigurePicker creates a new configure picker with the given expand function
-func NewConfigurePicker(expandFn func(string) []string) *ConfigurePicker {
-	return &ConfigurePicker{
+func NewConfigurePicker(expandFn func(string) []string, opts ...ConfigurePickerOption) *ConfigurePicker {
+	cp := &ConfigurePicker{
 		phase:    phasePath,
 		input:    NewTextField(),
 		depth:    1,
 		expandFn: expandFn,
 		tabIndex: -1,
 		height:   10,
+		styles:   defaultStyles,
+	}
+	for _, opt := range opts {
+		opt(cp)
 	}
+	cp.input.styles = cp.styles
+	return cp
 }
 
 func (cp *ConfigurePicker) Init() tea.Cmd {
@@ -202,10 +243,17 @@ func (cp *ConfigurePicker) updatePreview() {
 	val := cp.input.Value()
 	if val == "" {
 		cp.expandedPaths = nil
+		cp.worktrees = nil
 		cp.preview = nil
 		return
 	}
 	cp.expandedPaths = cp.expandFn(val)
+	cp.worktrees = make([]int, len(cp.expandedPaths))
+	if cp.worktreeFn != nil {
+		for i, p := range cp.expandedPaths {
+			cp.worktrees[i] = cp.worktreeFn(p)
+		}
+	}
 	cp.computePreviewNames()
 }
 
@@ -216,8 +264,25 @@ func (cp *ConfigurePicker) updatePreviewForDepth() {
 func (cp *ConfigurePicker) computePreviewNames() {
 	cp.preview = make([]string, len(cp.expandedPaths))
 	for i, p := range cp.expandedPaths {
-		cp.preview[i] = LastNSegments(p, cp.depth)
+		cp.preview[i] = sanitizeName(LastNSegments(p, cp.depth))
+		if n := cp.worktrees[i]; n > 0 {
+			cp.preview[i] += " (" + countNoun(n, "worktree", "worktrees") + ")"
+		}
 	}
+	cp.collisions = 0
+	if cp.collisionFn != nil && len(cp.expandedPaths) > 0 {
+		cp.collisions = cp.collisionFn(cp.expandedPaths, cp.depth)
+	}
+}
+
+// previewTotal returns the number of picker entries the previewed pattern
+// expands to: one per worktree of a repo with worktrees, one per other match.
+func (cp *ConfigurePicker) previewTotal() int {
+	total := 0
+	for _, n := range cp.worktrees {
+		total += max(n, 1)
+	}
+	return total
 }
 
 // Tab completion
@@ -331,7 +396,7 @@ func (cp *ConfigurePicker) viewHelp() string {
 	case phaseDepth:
 		title = "Help · Depth"
 	}
-	return RenderHelpOverlay(title, cp.helpEntries(), cp.width, cp.height)
+	return renderHelpOverlay(title, cp.helpEntries(), cp.width, cp.height, "C-h", cp.styles)
 }
 
 // View renders the configure picker
@@ -351,11 +416,11 @@ func (cp *ConfigurePicker) View() tea.View {
 	switch cp.phase {
 	case phasePath:
 		b.WriteString("  ")
-		b.WriteString(headerStyle.Render("Enter a project directory pattern"))
+		b.WriteString(cp.styles.header.Render("Enter a project directory pattern"))
 		b.WriteString("\n")
 	case phaseDepth:
 		b.WriteString("  ")
-		b.WriteString(headerStyle.Render("Set display depth"))
+		b.WriteString(cp.styles.header.Render("Set display depth"))
 		b.WriteString("\n")
 	}
 
@@ -383,14 +448,17 @@ func (cp *ConfigurePicker) View() tea.View {
 		}
 	}
 
-	// Count total lines (header + preview items + optional "more" line)
-	totalPreviewLines := 1 + previewCount // 1 for header
-	if showMore {
-		totalPreviewLines++
+	// Count the lines below the header, which has a line of its own in the
+	// space WindowSizeMsg reserves: preview items and the optional "more"
+	// line, or the "(no matches)" line.
+	bodyLines := previewCount
+	if showMore || len(cp.preview) == 0 {
+		bodyLines++
 	}
 
-	// Empty lines to push content to bottom
-	emptyLines := cp.height - totalPreviewLines
+	// Empty lines to push content to bottom, so the view fills the terminal
+	// at any height
+	emptyLines := cp.height - bodyLines
 	if emptyLines < 0 {
 		emptyLines = 0
 	}
@@ -402,6 +470,7 @@ func (cp *ConfigurePicker) View() tea.View {
 	if len(cp.preview) > 0 {
 		b.WriteString("  ")
 		b.WriteString(previewStyle.Render(previewHeader))
+		b.WriteString(cp.styles.dim.Render(" " + cp.previewSummary()))
 		b.WriteString("\n")
 
 		// Preview items
@@ -414,7 +483,7 @@ func (cp *ConfigurePicker) View() tea.View {
 		if showMore {
 			remaining := len(cp.preview) - previewCount
 			b.WriteString("    ")
-			b.WriteString(dimStyle.Render(fmt.Sprintf("... and %d more", remaining)))
+			b.WriteString(cp.styles.dim.Render(fmt.Sprintf("... and %d more", remaining)))
 			b.WriteString("\n")
 		}
 	} else {
@@ -437,13 +506,39 @@ func (cp *ConfigurePicker) View() tea.View {
 	case phaseDepth:
 		hints = "  ↑/↓ adjust depth · Enter confirm · Esc back · C-h help"
 	}
-	b.WriteString(hintStyle.Render(hints))
+	b.WriteString(cp.styles.hint.Render(hints))
 
 	v := tea.NewView(b.String())
 	v.AltScreen = true
 	return v
 }
 
+// previewSummary describes the impact of the previewed pattern: the projects
+// it adds to the picker, when worktrees expand the matches they come from,
+// and how many of their names collide at this depth.
+func (cp *ConfigurePicker) previewSummary() string {
+	total := cp.previewTotal()
+	summary := countNoun(total, "project", "projects")
+	if matches := len(cp.expandedPaths); matches != total {
+		summary += " from " + countNoun(matches, "match", "matches")
+	}
+	if cp.collisions > 0 {
+		summary += " · " + countNoun(cp.collisions, "name collides", "names collide") + ", auto-disambiguated"
+		if cp.phase == phaseDepth {
+			summary += " (↑ raises depth)"
+		}
+	}
+	return summary
+}
+
+// countNoun formats n with the singular or plural noun.
+func countNoun(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
 // Result returns the configure picker result after running
 func (cp *ConfigurePicker) Result() ConfigurePickerResult {
 	if cp.cancelled || !cp.confirmed {
@@ -456,8 +551,8 @@ func (cp *ConfigurePicker) Result() ConfigurePickerResult {
 }
 
 // RunConfigurePicker launches the configure picker and returns the result
-func RunConfigurePicker(expandFn func(string) []string) (ConfigurePickerResult, error) {
-	cp := NewConfigurePicker(expandFn)
+func RunConfigurePicker(expandFn func(string) []string, opts ...ConfigurePickerOption) (ConfigurePickerResult, error) {
+	cp := NewConfigurePicker(expandFn, opts...)
 	program := tea.NewProgram(cp)
 	m, err := program.Run()
 	if err != nil {
@@ -500,6 +595,15 @@ func contractTilde(path string) string {
 	if err != nil {
 		return path
 	}
+	return ContractTilde(path, home)
+}
+
+// ContractTilde replaces a leading home directory in path with ~. An empty
+// home leaves path as is.
+func ContractTilde(path, home string) string {
+	if home == "" {
+		return path
+	}
 	if strings.HasPrefix(path, home+"/") {
 		return "~" + path[len(home):]
 	}
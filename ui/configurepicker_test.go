@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
@@ -675,3 +676,128 @@ func TestConfigurePicker_Help_HintsIncludeCtrH(t *testing.T) {
 	}
 }
 
+func TestConfigurePicker_PreviewSanitizesAdversarialNames(t *testing.T) {
+	paths := []string{"/home/user/Dev/evil\x1b[2Jname", "/home/user/Dev/bad\xffname", "/home/user/Dev/new\nline"}
+	cp := NewConfigurePicker(mockExpandFn(paths))
+	cp = sendKeys(cp, tea.WindowSizeMsg{Width: 80, Height: 24}, charKeyMsg("~"))
+
+	view := cp.View().Content
+	for _, bad := range []string{"\x1b[2J", "\xff", "new\nline"} {
+		if strings.Contains(view, bad) {
+			t.Errorf("view contains %q:\n%s", bad, view)
+		}
+	}
+	for _, want := range []string{"evilname", "bad�name", "new�line"} {
+		if !containsSubstring(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestConfigurePicker_PreviewCountsWorktrees(t *testing.T) {
+	paths := []string{"/dev/app", "/dev/repo", "/dev/lib"}
+	worktrees := map[string]int{"/dev/repo": 4, "/dev/lib": 1}
+	cp := NewConfigurePicker(mockExpandFn(paths), WithWorktreeCount(func(path string) int {
+		return worktrees[path]
+	}))
+	cp = sendKeys(cp, tea.WindowSizeMsg{Width: 80, Height: 24}, charKeyMsg("x"))
+
+	want := []string{"app", "repo (4 worktrees)", "lib (1 worktree)"}
+	if len(cp.preview) != len(want) {
+		t.Fatalf("preview = %q, want %q", cp.preview, want)
+	}
+	for i := range want {
+		if cp.preview[i] != want[i] {
+			t.Errorf("preview[%d] = %q, want %q", i, cp.preview[i], want[i])
+		}
+	}
+	if view := cp.View().Content; !containsSubstring(view, "6 projects from 3 matches") {
+		t.Errorf("view missing the total:\n%s", view)
+	}
+
+	// Depth changes keep the counts without recounting.
+	cp = sendKeys(cp, specialKeyMsg(tea.KeyEnter), specialKeyMsg(tea.KeyUp))
+	if cp.preview[1] != "dev/repo (4 worktrees)" {
+		t.Errorf("preview[1] at depth 2 = %q", cp.preview[1])
+	}
+}
+
+func TestConfigurePicker_PreviewSummary(t *testing.T) {
+	cp := NewConfigurePicker(mockExpandFn([]string{"/dev/app"}))
+	cp = sendKeys(cp, tea.WindowSizeMsg{Width: 80, Height: 24}, charKeyMsg("x"))
+	if got := cp.previewSummary(); got != "1 project" {
+		t.Errorf("previewSummary() = %q, want %q", got, "1 project")
+	}
+}
+
+func TestConfigurePicker_PreviewSummaryCountsCollisions(t *testing.T) {
+	var gotDepth int
+	cp := NewConfigurePicker(mockExpandFn([]string{"/a/app", "/b/app"}), WithCollisionCount(func(paths []string, depth int) int {
+		gotDepth = depth
+		if depth > 1 {
+			return 0
+		}
+		return len(paths)
+	}))
+	cp = sendKeys(cp, tea.WindowSizeMsg{Width: 80, Height: 24}, charKeyMsg("x"))
+	if want := "2 projects · 2 names collide, auto-disambiguated"; cp.previewSummary() != want {
+		t.Errorf("previewSummary() = %q, want %q", cp.previewSummary(), want)
+	}
+
+	cp = sendKeys(cp, specialKeyMsg(tea.KeyEnter))
+	if got := cp.previewSummary(); !strings.HasSuffix(got, "(↑ raises depth)") {
+		t.Errorf("depth phase previewSummary() = %q, want the raise-depth cue", got)
+	}
+	cp = sendKeys(cp, specialKeyMsg(tea.KeyUp))
+	if gotDepth != 2 || cp.previewSummary() != "2 projects" {
+		t.Errorf("at depth %d previewSummary() = %q, want no collisions at depth 2", gotDepth, cp.previewSummary())
+	}
+}
+
+func TestConfigurePicker_ResizeSequenceFillsTerminal(t *testing.T) {
+	var paths []string
+	for i := 0; i < 40; i++ {
+		paths = append(paths, fmt.Sprintf("/home/user/Dev/p%02d", i))
+	}
+	for _, tt := range []struct {
+		name  string
+		paths []string
+	}{
+		{name: "more matches than rows", paths: paths},
+		{name: "fewer matches than rows", paths: paths[:3]},
+		{name: "no matches", paths: nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := NewConfigurePicker(mockExpandFn(tt.paths))
+			cp = sendKeys(cp, tea.WindowSizeMsg{Width: 80, Height: 30}, charKeyMsg("~"))
+
+			for _, h := range []int{30, 12, 9, 50, 20} {
+				cp = sendKeys(cp, tea.WindowSizeMsg{Width: 80, Height: h})
+				view := cp.View().Content
+				if lines := strings.Count(view, "\n") + 1; lines != h {
+					t.Errorf("after resize to %d: view has %d lines, want %d", h, lines, h)
+				}
+				if len(tt.paths) > cp.height && !strings.Contains(view, "more") {
+					t.Errorf("after resize to %d: view lacks the more line:\n%s", h, view)
+				}
+			}
+		})
+	}
+}
+
+func TestContractTilde(t *testing.T) {
+	tests := []struct {
+		path, home, want string
+	}{
+		{"/home/me/src/api", "/home/me", "~/src/api"},
+		{"/home/me", "/home/me", "~"},
+		{"/home/meta/api", "/home/me", "/home/meta/api"},
+		{"/srv/api", "/home/me", "/srv/api"},
+		{"/home/me/api", "", "/home/me/api"},
+	}
+	for _, tt := range tests {
+		if got := ContractTilde(tt.path, tt.home); got != tt.want {
+			t.Errorf("ContractTilde(%q, %q) = %q, want %q", tt.path, tt.home, got, tt.want)
+		}
+	}
+}
@@ -278,6 +278,7 @@ func (d *MonitorDashboard) Init() tea.Cmd {
 	if d.reloadFunc != nil {
 		cmds = append(cmds, reloadTick())
 	}
+	cmds = append(cmds, notificationTick())
 	return tea.Batch(cmds...)
 }
 
@@ -286,6 +287,8 @@ func (d *MonitorDashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	d.syncToList()
 
 	switch msg.(type) {
+	case notificationTickMsg:
+		return d, notificationTick()
 	case spinnerTickMsg:
 		d.spinnerFrame = (d.spinnerFrame + 1) % len(spinnerFrames)
 		if d.hasWorkingPanes() {
@@ -684,7 +687,7 @@ func (d *MonitorDashboard) fetchPreview() {
 }
 
 // frameSpec builds the Frame describing the dashboard's screen chrome: the
-// update notice, warnings, and hints. No Header — the two-column header row
+// update notice, warnings, notifications, and hints. No Header — the two-column header row
 // is part of the body composition itself, not a separate Frame region.
 func (d *MonitorDashboard) frameSpec() Frame {
 	return Frame{
@@ -692,6 +695,8 @@ func (d *MonitorDashboard) frameSpec() Frame {
 		Notice:   d.updateNotice,
 		Warnings: d.warnings,
 		Hints:    d.buildHints(),
+
+		Notifications: notifications.Active(),
 	}
 }
 
@@ -825,7 +830,7 @@ func (d *MonitorDashboard) viewDashboard() string {
 		msgStyle := lipgloss.NewStyle().Foreground(colorDim)
 		var eb strings.Builder
 		if d.updateNotice != "" {
-			eb.WriteString(renderUpdateNotice(d.width, d.updateNotice))
+			eb.WriteString(renderUpdateNotice(d.width, d.updateNotice, dimStyle))
 			eb.WriteString("\n")
 		}
 		headerText := d.title
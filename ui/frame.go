@@ -20,11 +20,17 @@ type Frame struct {
 	Warnings []string // reserved AND rendered; nil/empty = none
 	Status   string   // "" = absent; transient action feedback, distinct from Warnings
 	Hints    string   // "" = absent
+	// Notifications are the shared bus's active messages, one line each,
+	// rendered after Warnings; nil/empty = none.
+	Notifications []Notification
+
+	styles *styles // nil: the defaults
 }
 
 // BodyHeight returns the body row budget for a terminal of height termH: termH
 // minus every present region (1 for Notice, 1 for Header, 3 for InputBox,
-// len(Warnings) for warnings, 1 for Status, 1 for Hints), floored at >= 3.
+// len(Warnings) for warnings, len(Notifications) for notifications, 1 for
+// Status, 1 for Hints), floored at >= 3.
 func (f Frame) BodyHeight(termH int) int {
 	h := termH
 	if f.Notice != "" {
@@ -37,6 +43,7 @@ func (f Frame) BodyHeight(termH int) int {
 		h -= 3
 	}
 	h -= len(f.Warnings)
+	h -= len(f.Notifications)
 	if f.Status != "" {
 		h--
 	}
@@ -50,21 +57,23 @@ func (f Frame) BodyHeight(termH int) int {
 }
 
 // Render composes the frame's regions around body in the fixed order notice
-// -> header -> body -> input box -> warnings -> status -> hints, omitting
-// absent ones. When TermH is known, a short body is padded to the full
-// BodyHeight budget so trailing regions sit at the bottom of the screen.
+// -> header -> body -> input box -> warnings -> notifications -> status ->
+// hints, omitting absent ones. When TermH is known, a short body is padded to
+// the full BodyHeight budget so trailing regions sit at the bottom of the
+// screen.
 func (f Frame) Render(body string) string {
 	if f.TermH > 0 {
 		body = f.padBody(body)
 	}
 
-	parts := make([]string, 0, 7)
+	st := stylesOr(f.styles)
+	parts := make([]string, 0, 8)
 
 	if f.Notice != "" {
-		parts = append(parts, renderUpdateNotice(f.Width, f.Notice))
+		parts = append(parts, renderUpdateNotice(f.Width, f.Notice, st.dim))
 	}
 	if f.Header != "" {
-		parts = append(parts, headerStyle.Render(f.Header))
+		parts = append(parts, st.header.Render(f.Header))
 	}
 
 	parts = append(parts, body)
@@ -84,13 +93,21 @@ func (f Frame) Render(body string) string {
 		parts = append(parts, strings.Join(lines, "\n"))
 	}
 
+	if len(f.Notifications) > 0 {
+		lines := make([]string, len(f.Notifications))
+		for i, n := range f.Notifications {
+			lines[i] = n.render()
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+
 	if f.Status != "" {
-		statusStyle := lipgloss.NewStyle().Foreground(colorAccent)
+		statusStyle := lipgloss.NewStyle().Foreground(st.accent)
 		parts = append(parts, statusStyle.Render("  "+f.Status))
 	}
 
 	if f.Hints != "" {
-		parts = append(parts, hintStyle.Render(f.Hints))
+		parts = append(parts, st.hint.Render(f.Hints))
 	}
 
 	return strings.Join(parts, "\n")
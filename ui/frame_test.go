@@ -61,6 +61,12 @@ func TestFrameBodyHeight(t *testing.T) {
 			termH: 20,
 			want:  17,
 		},
+		{
+			name:  "notifications reserve N lines",
+			frame: Frame{Notifications: []Notification{{Message: "one"}, {Level: LevelError, Message: "two"}}},
+			termH: 20,
+			want:  18,
+		},
 		{
 			name: "all regions combine",
 			frame: Frame{
@@ -115,6 +121,8 @@ func TestFrameRenderOrderAndOmission(t *testing.T) {
 		Warnings: []string{"low disk space"},
 		Status:   "Copied to clipboard",
 		Hints:    "  Esc back",
+
+		Notifications: []Notification{{Level: LevelError, Message: "hook failed"}},
 	}
 
 	out := f.Render("BODY")
@@ -124,12 +132,13 @@ func TestFrameRenderOrderAndOmission(t *testing.T) {
 	body := indexOf(t, out, "BODY")
 	inputBox := indexOf(t, out, "Help")
 	warning := indexOf(t, out, "low disk space")
+	notification := indexOf(t, out, "hook failed")
 	status := indexOf(t, out, "Copied to clipboard")
 	hints := indexOf(t, out, "Esc back")
 
-	if !(notice < header && header < body && body < inputBox && inputBox < warning && warning < status && status < hints) {
-		t.Fatalf("regions out of order: notice=%d header=%d body=%d inputBox=%d warning=%d status=%d hints=%d",
-			notice, header, body, inputBox, warning, status, hints)
+	if !(notice < header && header < body && body < inputBox && inputBox < warning && warning < notification && notification < status && status < hints) {
+		t.Fatalf("regions out of order: notice=%d header=%d body=%d inputBox=%d warning=%d notification=%d status=%d hints=%d",
+			notice, header, body, inputBox, warning, notification, status, hints)
 	}
 }
 
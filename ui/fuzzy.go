@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/junegunn/fzf/src/util"
+)
+
+// Sizes of the scratch slabs fzf's FuzzyMatchV2 works in, as fzf allocates
+// them per matcher.
+const (
+	slab16Size = 100 * 1024
+	slab32Size = 2048
+)
+
+// slabPool recycles matcher slabs, so filtering on a keystroke reuses ~200KB
+// of scratch space instead of allocating it. A slab serves one match loop at
+// a time: take it with getSlab and give it back with putSlab.
+var slabPool = sync.Pool{
+	New: func() any { return util.MakeSlab(slab16Size, slab32Size) },
+}
+
+func getSlab() *util.Slab { return slabPool.Get().(*util.Slab) }
+
+func putSlab(slab *util.Slab) { slabPool.Put(slab) }
+
+// fuzzyChars caches the lowercased util.Chars of filter values, so a picker
+// converts each item once instead of on every keystroke. fzf only reads the
+// Chars it matches against, so one cached value serves every query.
+type fuzzyChars map[string]*util.Chars
+
+// get returns the Chars of s lowercased, converting s on first use.
+func (c fuzzyChars) get(s string) *util.Chars {
+	chars, ok := c[s]
+	if !ok {
+		converted := util.ToChars([]byte(strings.ToLower(s)))
+		chars = &converted
+		c[s] = chars
+	}
+	return chars
+}
+
+// fit empties c when it holds far more values than the n being matched now,
+// so items replaced by a refresh or a stream do not pile up.
+func (c *fuzzyChars) fit(n int) {
+	if *c == nil || len(*c) > 2*n+1024 {
+		*c = make(fuzzyChars, n)
+	}
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+// benchItems returns n items shaped like a large projects list.
+func benchItems(n int) []Item {
+	items := make([]Item, n)
+	for i := range items {
+		name := fmt.Sprintf("Org%d/Service-%d", i%50, i)
+		items[i] = Item{Name: name, Path: "/home/me/src/" + name}
+	}
+	return items
+}
+
+func BenchmarkPickerFilter10k(b *testing.B) {
+	picker := NewPicker(benchItems(10000))
+	picker.input.SetValue("svc12")
+	b.ReportAllocs()
+	for b.Loop() {
+		picker.filter()
+	}
+}
+
+func BenchmarkMatchItems10k(b *testing.B) {
+	items := benchItems(10000)
+	b.ReportAllocs()
+	for b.Loop() {
+		MatchItems("svc12", items)
+	}
+}
+
+func TestPickerFilterReusesConvertedItems(t *testing.T) {
+	picker := NewPicker(benchItems(10000))
+	picker.input.SetValue("zzz")
+	picker.filter()
+	// Without per-keystroke conversion or slab allocation, a query that
+	// matches nothing allocates a handful of times, not once per item.
+	if allocs := testing.AllocsPerRun(5, picker.filter); allocs > 100 {
+		t.Errorf("filter allocated %v times per run over 10k items", allocs)
+	}
+}
+
+func TestPickerFilterMatchesReplacedItems(t *testing.T) {
+	picker := NewPicker(benchItems(10))
+	picker.source = SliceSource([]Item{{Name: "Gateway", Path: "/src/gateway"}})
+	picker.input.SetValue("gate")
+	picker.filter()
+	if len(picker.filtered) != 1 || picker.filtered[0].Path != "/src/gateway" {
+		t.Errorf("filtered = %+v, want the replacement item", picker.filtered)
+	}
+}
+
+func TestFuzzyCharsFit(t *testing.T) {
+	var c fuzzyChars
+	c.fit(1)
+	first := c.get("API")
+	if c.get("API") != first || first.ToString() != "api" {
+		t.Errorf("get(API) = %q, want one cached lowercase conversion", first.ToString())
+	}
+	for i := range 2000 {
+		c.get(fmt.Sprint(i))
+	}
+	c.fit(10)
+	if len(c) != 0 {
+		t.Errorf("fit kept %d stale values", len(c))
+	}
+}
@@ -28,13 +28,18 @@ type HelpEntry struct {
 // It returns true when the key was consumed (help was toggled, dismissed, or
 // swallowed while the overlay is open).
 func ToggleHelp(showHelp *bool, msg tea.KeyPressMsg) bool {
+	return toggleHelpWith(showHelp, msg, HelpKeys)
+}
+
+// toggleHelpWith is ToggleHelp with toggle in place of the shared help key.
+func toggleHelpWith(showHelp *bool, msg tea.KeyPressMsg, toggle key.Binding) bool {
 	if *showHelp {
-		if key.Matches(msg, HelpKeys) || key.Matches(msg, helpCloseKeys) {
+		if key.Matches(msg, toggle) || key.Matches(msg, helpCloseKeys) {
 			*showHelp = false
 		}
 		return true
 	}
-	if key.Matches(msg, HelpKeys) {
+	if key.Matches(msg, toggle) {
 		*showHelp = true
 		return true
 	}
@@ -45,6 +50,12 @@ func ToggleHelp(showHelp *bool, msg tea.KeyPressMsg) bool {
 // columns, a bottom input-box chrome showing title, and the standard footer
 // hint "C-h toggle · Esc close".
 func RenderHelpOverlay(title string, entries []HelpEntry, width, height int) string {
+	return renderHelpOverlay(title, entries, width, height, "C-h", defaultStyles)
+}
+
+// renderHelpOverlay is RenderHelpOverlay with toggleHint naming the help key
+// in the footer, drawn in st.
+func renderHelpOverlay(title string, entries []HelpEntry, width, height int, toggleHint string, st *styles) string {
 	var b strings.Builder
 
 	maxKeyWidth := 0
@@ -73,7 +84,7 @@ func RenderHelpOverlay(title string, entries []HelpEntry, width, height int) str
 	}
 
 	writeInputBox(&b, width, " "+title)
-	b.WriteString(hintStyle.Render("  C-h toggle · Esc close"))
+	b.WriteString(st.hint.Render("  " + toggleHint + " toggle · Esc close"))
 
 	return b.String()
 }
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"charm.land/bubbles/v2/key"
+	"github.com/glebglazov/pop/config"
+)
+
+// keyModifiers are the modifier prefixes a key name may carry, as bubbletea
+// spells them.
+var keyModifiers = []string{"ctrl", "alt", "shift", "meta", "hyper", "super"}
+
+// namedKeys are the non-printable keys bubbletea reports by name, besides the
+// function keys.
+var namedKeys = []string{
+	"enter", "tab", "backspace", "esc", "escape", "space", "delete", "insert",
+	"up", "down", "left", "right", "home", "end", "pgup", "pgdown",
+}
+
+// validKeyName reports whether k, in the "ctrl-q" or "ctrl+q" spelling, names
+// a key bubbletea can report: modifiers followed by a single character, a
+// named key or a function key.
+func validKeyName(k string) bool {
+	parts := strings.Split(normalizeKeyName(k), "+")
+	for _, mod := range parts[:len(parts)-1] {
+		if !slices.Contains(keyModifiers, mod) {
+			return false
+		}
+	}
+	last := parts[len(parts)-1]
+	if utf8.RuneCountInString(last) == 1 || slices.Contains(namedKeys, last) {
+		return true
+	}
+	if n, ok := strings.CutPrefix(last, "f"); ok {
+		i, err := strconv.Atoi(n)
+		return err == nil && i >= 1 && i <= 20
+	}
+	return false
+}
+
+// namedBinding is a picker binding under the name KeyBindingProblems reports
+// it by.
+type namedBinding struct {
+	action  string
+	binding *key.Binding
+}
+
+// KeyBindingProblems checks [keys] remaps as WithKeyBindings would apply
+// them: keys no terminal can report, and keys two actions end up sharing —
+// with each other, with a default binding, or with the fixed navigation,
+// Enter and Esc keys — where only one of the actions can fire. Unknown actions
+// and empty keys are config's to report and are skipped here.
+func KeyBindingProblems(bindings map[string]string) []string {
+	var problems []string
+	km := keys
+	for _, action := range config.KeyBindingActions {
+		k, ok := bindings[action]
+		if !ok || strings.TrimSpace(k) == "" {
+			continue
+		}
+		if !validKeyName(k) {
+			problems = append(problems, fmt.Sprintf("[keys] %s = %q is not a key name", action, k))
+			continue
+		}
+		*km.binding(action) = key.NewBinding(key.WithKeys(normalizeKeyName(k)))
+	}
+
+	named := []namedBinding{
+		{"navigation", &km.Up},
+		{"navigation", &km.Down},
+		{"enter", &km.Enter},
+		{"quit", &km.Quit},
+	}
+	for _, action := range config.KeyBindingActions {
+		named = append(named, namedBinding{action, km.binding(action)})
+	}
+
+	owner := make(map[string]string)
+	for _, nb := range named {
+		for _, k := range nb.binding.Keys() {
+			if first, taken := owner[k]; taken && first != nb.action {
+				if !sharesNavigation(first, nb.action) {
+					problems = append(problems, fmt.Sprintf("%s is bound to both %s and %s", k, first, nb.action))
+				}
+				continue
+			}
+			owner[k] = nb.action
+		}
+	}
+	return problems
+}
+
+// KeyHint returns how the hint bar spells the key that fires action once the
+// [keys] remaps in bindings are applied, or "" for an action that can't be
+// remapped.
+func KeyHint(action string, bindings map[string]string) string {
+	km := keys
+	b := km.binding(action)
+	if b == nil {
+		return ""
+	}
+	if k := bindings[action]; strings.TrimSpace(k) != "" {
+		*b = key.NewBinding(key.WithKeys(normalizeKeyName(k)))
+	}
+	return formatKeyHint(*b)
+}
+
+// sharesNavigation reports whether action may share a key with navigation:
+// create_project only fires while the query matches nothing, when there is
+// nothing to move through.
+func sharesNavigation(first, action string) bool {
+	return first == "navigation" && action == "create_project"
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeyBindingProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindings map[string]string
+		want     []string
+	}{
+		{
+			name: "defaults and free keys",
+			bindings: map[string]string{
+				"help":  "ctrl-g",
+				"reset": "f5",
+				"bogus": "ctrl-k",
+			},
+		},
+		{
+			name:     "two remaps on one key",
+			bindings: map[string]string{"reset": "ctrl-g", "help": "ctrl-g"},
+			want:     []string{"ctrl+g is bound to both reset and help"},
+		},
+		{
+			name:     "remap onto a fixed key",
+			bindings: map[string]string{"mark": "ctrl-n"},
+			want:     []string{"ctrl+n is bound to both navigation and mark"},
+		},
+		{
+			name:     "create_project may share with navigation",
+			bindings: map[string]string{"create_project": "ctrl-p"},
+		},
+		{
+			name:     "misspelt modifier",
+			bindings: map[string]string{"help": "crtl-h"},
+			want:     []string{`[keys] help = "crtl-h" is not a key name`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeyBindingProblems(tt.bindings); !slices.Equal(got, tt.want) {
+				t.Errorf("KeyBindingProblems() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidKeyName(t *testing.T) {
+	for _, k := range []string{"ctrl-q", "alt+backspace", "f2", "tab", "?", "ctrl+shift+k"} {
+		if !validKeyName(k) {
+			t.Errorf("validKeyName(%q) = false, want true", k)
+		}
+	}
+	for _, k := range []string{"crtl-q", "ctrl-", "f99", "control+q", "pageup"} {
+		if validKeyName(k) {
+			t.Errorf("validKeyName(%q) = true, want false", k)
+		}
+	}
+}
@@ -45,6 +45,7 @@ type List[T any] struct {
 	scroll int
 	height int
 	opts   Opts[T]
+	styles *styles // nil: the defaults
 }
 
 // NewList creates a list with the given items and options.
@@ -296,14 +297,14 @@ func (l *List[T]) VisibleRows() []string {
 
 func (l *List[T]) renderPrefix(selected bool, quickLabel string, prefixWidth int) string {
 	if selected {
-		indicator := indicatorStyle.Render("█")
+		indicator := stylesOr(l.styles).indicator.Render("█")
 		if l.opts.QuickLabel != nil {
 			return strings.Repeat(" ", prefixWidth-1) + indicator
 		}
 		return indicator + " "
 	}
 	if quickLabel != "" {
-		return dimStyle.Render(quickLabel)
+		return stylesOr(l.styles).dim.Render(quickLabel)
 	}
 	return strings.Repeat(" ", prefixWidth)
 }
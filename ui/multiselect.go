@@ -112,11 +112,14 @@ func NewMultiSelect(title string, items []MultiSelectItem) *MultiSelect {
 }
 
 func (m *MultiSelect) Init() tea.Cmd {
-	return nil
+	return notificationTick()
 }
 
 func (m *MultiSelect) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case notificationTickMsg:
+		return m, notificationTick()
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -175,14 +178,16 @@ func (m *MultiSelect) viewHelp() string {
 }
 
 // frameSpec builds the Frame describing MultiSelect's screen chrome: a
-// header (the title) and a static hint line, with no notice, input box, or
-// warnings.
+// header (the title), the shared notifications and a static hint line, with
+// no notice, input box, or warnings.
 func (m *MultiSelect) frameSpec() Frame {
 	return Frame{
 		Width:  m.width,
 		TermH:  m.height,
 		Header: m.title,
 		Hints:  "  Space toggle · Enter confirm · Esc cancel · C-h help",
+
+		Notifications: notifications.Active(),
 	}
 }
 
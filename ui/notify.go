@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// Level is the severity of a Notification.
+type Level int
+
+const (
+	// LevelInfo is transient feedback on an action ("Killed session: api").
+	LevelInfo Level = iota
+	// LevelWarning is a sticky problem worth keeping on screen for the rest
+	// of the run.
+	LevelWarning
+	// LevelError is a failed action, shown longer than info.
+	LevelError
+)
+
+// How long a notification stays on screen once a view first shows it.
+// Warnings have no lifetime: they stay until pop exits.
+const (
+	infoLifetime  = 4 * time.Second
+	errorLifetime = 8 * time.Second
+)
+
+// notificationPoll is how often a view re-renders while a notification may
+// expire.
+const notificationPoll = time.Second
+
+// Notification is one message on the bus.
+type Notification struct {
+	Level   Level
+	Message string
+
+	lifetime time.Duration // 0 = sticky
+	expires  time.Time     // set when first shown
+	shown    bool
+}
+
+// Notifications is the status bus every Frame-based view renders: the cmd
+// layer pushes feedback into it between and during picker runs instead of
+// printing to stderr, where the alt-screen would hide it. A notification's
+// lifetime starts when a view first shows it, so feedback pushed while no
+// picker is up (e.g. during a custom command) still gets its full time on
+// screen in the next one.
+type Notifications struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	items []Notification
+}
+
+// NewNotifications returns an empty bus using now as its clock.
+func NewNotifications(now func() time.Time) *Notifications {
+	return &Notifications{now: now}
+}
+
+// notifications is the process-wide bus shared by all pickers.
+var notifications = NewNotifications(time.Now)
+
+// Notify pushes a formatted message onto the shared bus.
+func Notify(level Level, format string, args ...any) {
+	notifications.Push(level, fmt.Sprintf(format, args...))
+}
+
+// FlushNotifications writes every notification no view got to show to w and
+// empties the shared bus. Run it on exit so feedback pushed after the last
+// picker closed is not lost.
+func FlushNotifications(w io.Writer) {
+	notifications.Flush(w)
+}
+
+// Push adds a message at level.
+func (n *Notifications) Push(level Level, message string) {
+	var lifetime time.Duration
+	switch level {
+	case LevelInfo:
+		lifetime = infoLifetime
+	case LevelError:
+		lifetime = errorLifetime
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.items = append(n.items, Notification{Level: level, Message: message, lifetime: lifetime})
+}
+
+// Active drops expired notifications and returns the rest oldest first,
+// starting the lifetime of any shown for the first time.
+func (n *Notifications) Active() []Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := n.now()
+	kept := n.items[:0]
+	for _, item := range n.items {
+		if !item.shown {
+			item.shown = true
+			if item.lifetime > 0 {
+				item.expires = now.Add(item.lifetime)
+			}
+		}
+		if item.lifetime > 0 && !now.Before(item.expires) {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	n.items = kept
+	if len(kept) == 0 {
+		return nil
+	}
+	return append([]Notification(nil), kept...)
+}
+
+// expiring reports whether any notification will still expire, i.e. whether
+// a view showing the bus needs to keep re-rendering.
+func (n *Notifications) expiring() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, item := range n.items {
+		if item.lifetime > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush writes the notifications no view has shown to w, one per line, and
+// empties the bus.
+func (n *Notifications) Flush(w io.Writer) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, item := range n.items {
+		if !item.shown {
+			fmt.Fprintln(w, item.Message)
+		}
+	}
+	n.items = nil
+}
+
+// render formats the notification as a Frame line.
+func (item Notification) render() string {
+	switch item.Level {
+	case LevelWarning:
+		return lipgloss.NewStyle().Foreground(colorWorking).Render("  ⚠ " + item.Message)
+	case LevelError:
+		return lipgloss.NewStyle().Foreground(colorAttention).Bold(true).Render("  ✗ " + item.Message)
+	}
+	return lipgloss.NewStyle().Foreground(colorAccent).Render("  · " + item.Message)
+}
+
+// notificationTickMsg re-renders a view so expired notifications disappear.
+type notificationTickMsg struct{}
+
+// notificationTick schedules the next re-render while a notification on the
+// shared bus may still expire, or returns nil.
+func notificationTick() tea.Cmd {
+	if !notifications.expiring() {
+		return nil
+	}
+	return tea.Tick(notificationPoll, func(time.Time) tea.Msg { return notificationTickMsg{} })
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func activeMessages(n *Notifications) []string {
+	var out []string
+	for _, item := range n.Active() {
+		out = append(out, item.Message)
+	}
+	return out
+}
+
+func TestNotificationsExpireByLevel(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	n := NewNotifications(func() time.Time { return now })
+	n.Push(LevelInfo, "killed api")
+	n.Push(LevelWarning, "config has findings")
+	n.Push(LevelError, "hook failed")
+
+	if got := activeMessages(n); len(got) != 3 {
+		t.Fatalf("Active() = %q, want all three", got)
+	}
+	now = now.Add(infoLifetime)
+	if got := activeMessages(n); strings.Join(got, ",") != "config has findings,hook failed" {
+		t.Errorf("after %v Active() = %q, want the info toast expired", infoLifetime, got)
+	}
+	now = now.Add(time.Hour)
+	if got := activeMessages(n); strings.Join(got, ",") != "config has findings" {
+		t.Errorf("after an hour Active() = %q, want only the sticky warning", got)
+	}
+	if n.expiring() {
+		t.Error("expiring() = true with only a sticky warning left")
+	}
+}
+
+func TestNotificationsLifetimeStartsWhenShown(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	n := NewNotifications(func() time.Time { return now })
+	n.Push(LevelInfo, "deleted worktree")
+
+	// Pushed while no picker was up: its time only starts once a view shows it.
+	now = now.Add(time.Minute)
+	if got := activeMessages(n); len(got) != 1 {
+		t.Fatalf("Active() = %q, want the unshown toast kept", got)
+	}
+	now = now.Add(infoLifetime - time.Millisecond)
+	if got := activeMessages(n); len(got) != 1 {
+		t.Errorf("Active() = %q, want the toast until its lifetime ends", got)
+	}
+}
+
+func TestNotificationsFlushWritesUnshown(t *testing.T) {
+	n := NewNotifications(time.Now)
+	n.Push(LevelError, "shown in a picker")
+	n.Active()
+	n.Push(LevelError, "custom command failed")
+
+	var buf bytes.Buffer
+	n.Flush(&buf)
+
+	if buf.String() != "custom command failed\n" {
+		t.Errorf("Flush wrote %q, want only the unshown notification", buf.String())
+	}
+	if got := n.Active(); got != nil {
+		t.Errorf("Active() after Flush = %v, want empty", got)
+	}
+}
+
+func TestPickerShowsNotifications(t *testing.T) {
+	t.Cleanup(func() { FlushNotifications(io.Discard) })
+	Notify(LevelInfo, "Killed session: %s", "api")
+
+	picker := NewPicker([]Item{{Name: "api", Path: "/api"}})
+	if picker.Init() == nil {
+		t.Error("Init() = nil, want a tick to expire the toast")
+	}
+	picker.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	if view := picker.View().Content; !strings.Contains(view, "Killed session: api") {
+		t.Errorf("view does not show the notification:\n%s", view)
+	}
+}
@@ -1,14 +1,16 @@
 package ui
 
 import (
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 	"github.com/glebglazov/pop/debug"
-	"github.com/junegunn/fzf/src/algo"
-	"github.com/junegunn/fzf/src/util"
 )
 
 // IconAttention is the icon used to mark items that have panes needing attention.
@@ -16,21 +18,32 @@ const IconAttention = "!"
 
 // Item represents a selectable item in the picker
 type Item struct {
-	Name        string // Display name
-	Path        string // Full path (returned on selection)
-	Context     string // Additional context (e.g., branch name)
-	Icon        string // Optional icon displayed to the left of name
-	SessionName string // Pre-computed tmux session name
+	Name        string   // Display name
+	Path        string   // Full path (returned on selection)
+	Context     string   // Additional context (e.g., branch name)
+	Icon        string   // Optional icon displayed to the left of name
+	TypeIcon    string   // Optional project-type icon, in a column between Icon and name
+	SessionName string   // Pre-computed tmux session name
+	Group       string   // Parent directory rendered as a group header (WithGroupHeaders)
+	HasSession  bool     // Item has a live tmux session (WithSessionFilter)
+	Tags        []string // Labels rendered dim after the name and matched by the query
+	Pinned      bool     // Ordered last, nearest the cursor, by the project picker
 }
 
+// FilterValue is the text the query is matched against: the name followed by
+// any tags.
 func (i Item) FilterValue() string {
-	return i.Name
+	if len(i.Tags) == 0 {
+		return i.Name
+	}
+	return i.Name + " " + strings.Join(i.Tags, " ")
 }
 
 // UserDefinedCommandResult holds info about a custom command to execute
 type UserDefinedCommandResult struct {
 	Command string
 	Exit    bool
+	Multi   bool // command receives Result.Marked rather than only Result.Selected
 }
 
 // Result holds the picker result
@@ -39,6 +52,22 @@ type Result struct {
 	Action             Action
 	CursorIndex        int                       // cursor position at time of action
 	UserDefinedCommand *UserDefinedCommandResult // set when Action == ActionUserDefinedCommand
+	// Marked holds the items a multi user-defined command operates on — and,
+	// under WithMultiSelect, Enter, open window and kill session: every item
+	// marked with tab (in list order), or the highlighted item when none are
+	// marked.
+	Marked []Item
+	// SortMode is the name of the active sort mode (WithSortModes), so a
+	// caller re-showing the picker can keep it; "" without sort modes.
+	SortMode string
+	// NewName is the name typed for Selected when Action == ActionRename.
+	NewName string
+	// Query is the filter text to create a project from when Action ==
+	// ActionCreateProject.
+	Query string
+	// BranchFilter reports whether the branch filter (WithBranchFilter) was
+	// on, so a caller re-showing the picker can keep it.
+	BranchFilter bool
 }
 
 // Action represents what action the user wants to take
@@ -57,11 +86,31 @@ const (
 	ActionYankPath
 	ActionCreateWorktree
 	ActionSetPreferredWorkbench
+	ActionBrowseWorktrees
+	ActionRename
+	ActionCreateProject
+	ActionOpenEditor
 )
 
+// actionNames are the Action names String returns, indexed by Action.
+var actionNames = []string{
+	"confirm", "cancel", "delete", "force_delete", "kill_session", "reset",
+	"open_window", "user_defined_command", "refresh", "yank_path",
+	"create_worktree", "set_preferred_workbench", "browse_worktrees", "rename",
+	"create_project", "open_editor",
+}
+
+// String returns the action's snake_case name, as scripts see it.
+func (a Action) String() string {
+	if a < 0 || int(a) >= len(actionNames) {
+		return "action(" + strconv.Itoa(int(a)) + ")"
+	}
+	return actionNames[a]
+}
+
 // Picker is a fuzzy-searchable list picker
 type Picker struct {
-	items    []Item
+	source   ItemSource
 	filtered []Item
 	input    TextField
 	list     *List[Item]
@@ -69,17 +118,25 @@ type Picker struct {
 	scroll   int // synced from list; kept for test access
 	height   int
 	width    int
+	termH    int // terminal height from the last WindowSizeMsg; 0 until one arrives
 	result   Result
 
-	showHelp           bool
-	showDelete         bool
-	showContext        bool
-	showKillSession    bool
-	showReset          bool
-	showOpenWindow     bool
-	showCreateWorktree bool
-	showSetPreferred   bool
-	cursorAtEnd        bool
+	showHelp            bool
+	showDelete          bool
+	showContext         bool
+	showKillSession     bool
+	showReset           bool
+	showOpenWindow      bool
+	showCreateWorktree  bool
+	showSetPreferred    bool
+	showBrowseWorktrees bool
+	showOpenEditor      bool
+	renameFn            RenameFunc
+	createLabel         func(query string) string // WithCreateProject; nil disables it
+	renaming            *renameState
+	cursorAtEnd         bool
+	hideIcons           bool
+	maxHeight           int // most list rows; 0 = fill the terminal
 
 	quickAccessModifier string
 	quickAccess         *QuickAccess
@@ -87,6 +144,13 @@ type Picker struct {
 	// Cursor memory: remembers selected item path per filter query
 	cursorMemory map[string]string
 	lastQuery    string
+	// chars holds the items' filter values converted for the fuzzy matcher.
+	chars fuzzyChars
+
+	// keys holds the built-in bindings, remapped by WithKeyBindings.
+	keys keyMap
+	// styles are the colors the picker draws with, themed by WithTheme.
+	styles *styles
 
 	customCommands   []UserDefinedKeyBinding
 	iconLegend       []iconLegendEntry
@@ -94,6 +158,103 @@ type Picker struct {
 	warnings         []string
 	updateNotice     string
 	header           string
+	tip              string
+
+	// groupHeaders renders a group column; groupHeads marks (by Path) the
+	// first item of each run of equal Group in the filtered list.
+	groupHeaders bool
+	groupHeads   map[string]bool
+	groupWidth   int
+
+	// typeIconWidth is the widest TypeIcon, 0 when no item has one.
+	typeIconWidth int
+
+	// matchPos holds, by Path, the rune positions of each item's FilterValue
+	// matched by the current query; nil when nothing is highlighted.
+	matchPos map[string][]int
+
+	// preview is the optional right-hand preview pane (nil = disabled).
+	preview *previewPane
+
+	// statuses holds the per-item statuses shown after names (nil = disabled).
+	statuses *itemStatuses
+
+	// markable enables tab marking; it is on when any user-defined command is
+	// multi. marked is keyed by Path so marks survive re-filtering.
+	markable bool
+	marked   map[string]bool
+	// multiSelect makes the built-in actions return the marked items too; its
+	// mark column only shows while something is marked.
+	multiSelect bool
+
+	// sessionFilterable enables the session-state toggle; sessionFilter is the
+	// current state, applied before the fuzzy query.
+	sessionFilterable bool
+	sessionFilter     sessionFilter
+	// branchKeep is the WithBranchFilter predicate, nil without one;
+	// branchFilterOn says whether it currently hides items.
+	branchKeep     func(Item) bool
+	branchFilterOn bool
+
+	// matcher is the optional external ranking (nil = built-in fuzzy match).
+	matcher MatchFunc
+
+	// sortModes are the orderings the sort key cycles through; sortIndex is
+	// the active one. Empty when sorting is fixed.
+	sortModes []SortMode
+	sortIndex int
+
+	// itemStream delivers item snapshots while the caller is still loading
+	// them (WithItemStream); loading stays true until it is closed.
+	itemStream <-chan []Item
+	// remote is the FilteringSource the picker was made from, asked to match
+	// queries; nil when the picker matches them itself.
+	remote  FilteringSource
+	loading bool
+}
+
+// SortMode is one ordering of the picker's items, oldest first like every
+// list here. Items holds the same items as the others, in this mode's order.
+type SortMode struct {
+	Name  string // shown in the footer, e.g. "recent"
+	Items []Item
+}
+
+// sessionFilter narrows the picker by tmux session state. The toggle key
+// cycles all → with session → without session → all.
+type sessionFilter int
+
+const (
+	sessionFilterAll sessionFilter = iota
+	sessionFilterWith
+	sessionFilterWithout
+)
+
+// next returns the state the toggle key cycles to.
+func (f sessionFilter) next() sessionFilter {
+	return (f + 1) % 3
+}
+
+// keep reports whether item passes the filter.
+func (f sessionFilter) keep(item Item) bool {
+	switch f {
+	case sessionFilterWith:
+		return item.HasSession
+	case sessionFilterWithout:
+		return !item.HasSession
+	}
+	return true
+}
+
+// hint is the footer indicator for the filter; empty when showing all.
+func (f sessionFilter) hint() string {
+	switch f {
+	case sessionFilterWith:
+		return "showing: with session"
+	case sessionFilterWithout:
+		return "showing: without session"
+	}
+	return ""
 }
 
 // iconLegendEntry maps an icon to its description in the help view
@@ -107,15 +268,29 @@ type UserDefinedKeyBinding struct {
 	Binding key.Binding
 	Command string
 	Label   string
+	// Hint is shown for the key in place of the binding; "" shows the
+	// binding.
+	Hint string
+	// HintBar lists the command in the hint bar as well as the help overlay.
+	HintBar bool
 	Exit    bool
+	Multi   bool
 }
 
 // UserDefinedCommand defines a custom command to add to the picker
 type UserDefinedCommand struct {
-	Key     string
-	Label   string
+	Key   string
+	Label string
+	// Hint replaces the formatted key wherever the command is listed.
+	Hint string
+	// HintBar lists the command in the hint bar below the picker; every
+	// command is in the help overlay.
+	HintBar bool
 	Command string
 	Exit    bool
+	// Multi makes the command receive every marked item. Declaring one enables
+	// tab marking in the picker.
+	Multi bool
 }
 
 // PickerOption configures the picker
@@ -156,6 +331,13 @@ func WithOpenWindow() PickerOption {
 	}
 }
 
+// WithOpenEditor enables the open-in-editor keybinding (alt+enter)
+func WithOpenEditor() PickerOption {
+	return func(p *Picker) {
+		p.showOpenEditor = true
+	}
+}
+
 // WithCreateWorktree enables the create-worktree keybinding (ctrl+a)
 func WithCreateWorktree() PickerOption {
 	return func(p *Picker) {
@@ -163,6 +345,15 @@ func WithCreateWorktree() PickerOption {
 	}
 }
 
+// WithCreateProject offers, while the query matches nothing, to create a
+// project from it (ctrl+n). label describes what creating does for a query,
+// e.g. "create ~/Dev/api", and is shown in the hints.
+func WithCreateProject(label func(query string) string) PickerOption {
+	return func(p *Picker) {
+		p.createLabel = label
+	}
+}
+
 // WithSetPreferredWorkbench enables the set-preferred-workbench keybinding
 // (ctrl+w). It is the feature flag gating the Workbench-preference picker
 // surface (ADR-0078); both the project picker and the worktree dashboard opt in.
@@ -172,6 +363,14 @@ func WithSetPreferredWorkbench() PickerOption {
 	}
 }
 
+// WithBrowseWorktrees enables the browse-worktrees keybinding (ctrl+l), which
+// asks the caller to list the highlighted project's worktrees.
+func WithBrowseWorktrees() PickerOption {
+	return func(p *Picker) {
+		p.showBrowseWorktrees = true
+	}
+}
+
 // WithCursorAtEnd starts the cursor at the last item
 func WithCursorAtEnd() PickerOption {
 	return func(p *Picker) {
@@ -222,12 +421,41 @@ func WithUserDefinedCommands(commands []UserDefinedCommand) PickerOption {
 				Binding: binding,
 				Command: cmd.Command,
 				Label:   cmd.Label,
+				Hint:    cmd.Hint,
+				HintBar: cmd.HintBar,
 				Exit:    cmd.Exit,
+				Multi:   cmd.Multi,
 			})
+			if cmd.Multi {
+				p.markable = true
+			}
 		}
 	}
 }
 
+// WithKeyBindings remaps built-in actions to other keys. bindings maps an
+// action name from the [keys] config table (kill_session, reset, help, …) to
+// a key such as "ctrl-q"; unknown actions are ignored.
+func WithKeyBindings(bindings map[string]string) PickerOption {
+	return func(p *Picker) {
+		for action, k := range bindings {
+			if b := p.keys.binding(action); b != nil && strings.TrimSpace(k) != "" {
+				*b = key.NewBinding(key.WithKeys(normalizeKeyName(k)))
+			}
+		}
+	}
+}
+
+// WithMultiSelect enables tab marking for the built-in actions: Enter, open
+// window (ctrl+o) and kill session (ctrl+k) also return the marked items in
+// Result.Marked, so callers can act on several at once.
+func WithMultiSelect() PickerOption {
+	return func(p *Picker) {
+		p.markable = true
+		p.multiSelect = true
+	}
+}
+
 // WithWarnings adds warning messages to display in the picker
 func WithWarnings(warnings []string) PickerOption {
 	return func(p *Picker) {
@@ -253,20 +481,145 @@ func WithHeader(text string) PickerOption {
 	}
 }
 
+// WithoutIcons hides item icons, and the column they would take.
+func WithoutIcons() PickerOption {
+	return func(p *Picker) {
+		p.hideIcons = true
+	}
+}
+
+// WithMaxHeight caps the list at rows rows, however tall the terminal. Zero or
+// less leaves the list filling the terminal.
+func WithMaxHeight(rows int) PickerOption {
+	return func(p *Picker) {
+		p.maxHeight = rows
+	}
+}
+
+// WithGroupHeaders renders each item's Group as a column, labelled once at the
+// top of every run of items sharing a group. Callers order items so groups are
+// contiguous; while filtering, runs follow the match order.
+func WithGroupHeaders() PickerOption {
+	return func(p *Picker) {
+		p.groupHeaders = true
+	}
+}
+
+// WithSessionFilter enables the session-state toggle (ctrl+t), cycling the list
+// between all items, only items with a live session, and only items without
+// one. Callers set Item.HasSession.
+func WithSessionFilter() PickerOption {
+	return func(p *Picker) {
+		p.sessionFilterable = true
+	}
+}
+
+// WithBranchFilter enables the branch-filter toggle (ctrl+v): while on, the
+// list keeps only the items keep accepts. on is the starting state.
+func WithBranchFilter(keep func(Item) bool, on bool) PickerOption {
+	return func(p *Picker) {
+		p.branchKeep = keep
+		p.branchFilterOn = on
+	}
+}
+
+// WithSortModes enables the sort key (ctrl+s), cycling the list through modes
+// in order. The picker starts in the mode named active (the first when none
+// matches), whose Items replace the ones passed to NewPicker.
+func WithSortModes(active string, modes ...SortMode) PickerOption {
+	return func(p *Picker) {
+		if len(modes) == 0 {
+			return
+		}
+		p.sortModes = modes
+		p.sortIndex = 0
+		for i, m := range modes {
+			if m.Name == active {
+				p.sortIndex = i
+			}
+		}
+		p.source = SliceSource(modes[p.sortIndex].Items)
+		p.filtered = modes[p.sortIndex].Items
+	}
+}
+
+// WithItemStream opens the picker before its items are all known: every
+// snapshot received on updates replaces the item list, and closing updates
+// ends loading. Until then the hints line shows "loading…". The sender should
+// never block on a picker that has already quit, e.g. by using a buffered
+// channel and dropping a snapshot nobody read.
+func WithItemStream(updates <-chan []Item) PickerOption {
+	return func(p *Picker) {
+		p.itemStream = updates
+		p.loading = true
+	}
+}
+
+// WithTip appends a one-time tip to the footer hints line.
+func WithTip(text string) PickerOption {
+	return func(p *Picker) {
+		p.tip = text
+	}
+}
+
+// WithPreview enables the right-hand preview pane. fn renders the preview for
+// the highlighted item; it runs asynchronously and its result is cached per
+// item, so it may shell out (e.g. a user-configured preview command).
+func WithPreview(fn PreviewFunc) PickerOption {
+	return func(p *Picker) {
+		if fn != nil {
+			p.preview = newPreviewPane(fn)
+		}
+	}
+}
+
+// MatchFunc ranks items against a non-empty query, returning the indices of
+// the matching items, best match first. It runs synchronously on every query
+// change, so it must bound its own latency; an error makes the picker fall
+// back to its built-in fuzzy matching for that query.
+type MatchFunc func(query string, items []Item) ([]int, error)
+
+// WithMatcher replaces the built-in fuzzy matching with fn (e.g. a
+// user-configured matcher command).
+func WithMatcher(fn MatchFunc) PickerOption {
+	return func(p *Picker) {
+		p.matcher = fn
+	}
+}
+
 // NewPicker creates a new picker with the given items
 func NewPicker(items []Item, opts ...PickerOption) *Picker {
+	return NewPickerFromSource(SliceSource(items), opts...)
+}
+
+// NewPickerFromSource creates a picker that pulls its items from src. Any
+// source but a SliceSource is read in the background once the picker runs,
+// through the same path as WithItemStream.
+func NewPickerFromSource(src ItemSource, opts ...PickerOption) *Picker {
+	slice, ready := src.(SliceSource)
 	p := &Picker{
-		items:            items,
-		filtered:         items,
+		source:           slice,
+		filtered:         slice,
 		input:            NewTextField(),
 		height:           10,
 		cursorMemory:     make(map[string]string),
+		marked:           make(map[string]bool),
 		initialCursorIdx: -1,
+		keys:             keys,
+		styles:           defaultStyles,
+	}
+	if !ready {
+		p.remote, _ = src.(FilteringSource)
+		WithItemStream(streamSource(src))(p)
 	}
 
 	for _, opt := range opts {
 		opt(p)
 	}
+	p.input.styles = p.styles
+	if p.branchKeep != nil && p.branchFilterOn {
+		p.filtered = p.keepItems(p.filtered, nil)
+	}
 
 	p.quickAccess = p.newQuickAccess()
 	scrollMargin := 0
@@ -274,7 +627,7 @@ func NewPicker(items []Item, opts ...PickerOption) *Picker {
 		scrollMargin = 9
 	}
 
-	p.list = NewList(items, Opts[Item]{
+	p.list = NewList(p.filtered, Opts[Item]{
 		Key:          func(it Item) string { return it.Path },
 		Wrap:         true,
 		Anchor:       AnchorBottom,
@@ -282,6 +635,15 @@ func NewPicker(items []Item, opts ...PickerOption) *Picker {
 		QuickLabel:   p.quickAccess.LabelFunc(),
 	})
 	p.list.opts.Cell = p.pickerCell
+	p.list.styles = p.styles
+	p.groupWidth = p.groupLabelWidth()
+	p.typeIconWidth = p.typeIconColumnWidth()
+	p.computeGroupHeads()
+
+	p.chars.fit(len(p.filtered))
+	for _, item := range p.filtered {
+		p.chars.get(item.FilterValue())
+	}
 
 	return p
 }
@@ -294,6 +656,21 @@ func (p *Picker) newQuickAccess() *QuickAccess {
 	return NewQuickAccess(modifier)
 }
 
+// resize fits the list to the body the terminal leaves around the frame's
+// other regions, re-clamping its scroll so the cursor row stays on screen.
+// It does nothing until the terminal size is known.
+func (p *Picker) resize() {
+	if p.termH == 0 {
+		return
+	}
+	p.height = p.frameSpec().BodyHeight(p.termH)
+	if p.maxHeight > 0 && p.height > p.maxHeight {
+		p.height = p.maxHeight
+	}
+	p.list.Resize(p.height)
+	p.syncFromList()
+}
+
 func (p *Picker) syncFromList() {
 	p.cursor = p.list.Cursor()
 	p.scroll = p.list.Scroll()
@@ -305,6 +682,32 @@ func (p *Picker) syncToList() {
 	}
 }
 
+// markedItems returns the marked items in list order, falling back to the
+// highlighted item when nothing is marked.
+func (p *Picker) markedItems() []Item {
+	var items []Item
+	for item := range sourceItems(p.source) {
+		if p.marked[item.Path] {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		if item, ok := p.selectedItem(); ok {
+			items = []Item{*item}
+		}
+	}
+	return items
+}
+
+// multiSelectedItems is markedItems for the built-in actions: nil unless
+// WithMultiSelect is on.
+func (p *Picker) multiSelectedItems() []Item {
+	if !p.multiSelect {
+		return nil
+	}
+	return p.markedItems()
+}
+
 func (p *Picker) selectedItem() (*Item, bool) {
 	item, ok := p.list.Selected()
 	if !ok {
@@ -320,7 +723,56 @@ func (p *Picker) Init() tea.Cmd {
 		p.list.SetCursor(len(p.filtered) - 1)
 	}
 	p.syncFromList()
-	return nil
+	return tea.Batch(p.previewCmd(), notificationTick(), p.waitForItems(), p.statusCmd())
+}
+
+// itemsMsg is the next snapshot from the item stream; done means the stream
+// was closed.
+type itemsMsg struct {
+	items []Item
+	done  bool
+}
+
+// waitForItems reads the next snapshot from the item stream, or returns nil
+// when there is none.
+func (p *Picker) waitForItems() tea.Cmd {
+	if p.itemStream == nil {
+		return nil
+	}
+	stream := p.itemStream
+	return func() tea.Msg {
+		items, ok := <-stream
+		return itemsMsg{items: items, done: !ok}
+	}
+}
+
+// replaceItems swaps in a new item list, keeping the query and the
+// highlighted item; a highlight on the last item stays on the last item, so
+// the most recent project keeps the cursor while more arrive.
+func (p *Picker) replaceItems(items []Item) {
+	current, hasCurrent := p.list.Selected()
+	atEnd := !hasCurrent || p.list.Cursor() == len(p.filtered)-1
+	p.source = SliceSource(items)
+	p.groupWidth = p.groupLabelWidth()
+	p.typeIconWidth = p.typeIconColumnWidth()
+	p.filter()
+	if atEnd || !p.list.SetCursorToKey(current.Path) {
+		p.list.SetCursor(len(p.filtered) - 1)
+	}
+	p.syncFromList()
+}
+
+// previewCmd requests the preview for the highlighted item, or returns nil
+// when the pane is disabled, the list is empty, or the result is cached.
+func (p *Picker) previewCmd() tea.Cmd {
+	if p.preview == nil {
+		return nil
+	}
+	item, ok := p.list.Selected()
+	if !ok {
+		return nil
+	}
+	return p.preview.request(item)
 }
 
 func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -329,43 +781,51 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
 		// Help overlay: toggle, dismiss, or swallow keys while open.
-		if ToggleHelp(&p.showHelp, msg) {
+		if toggleHelpWith(&p.showHelp, msg, p.keys.Help) {
 			return p, nil
 		}
+		if p.renaming != nil {
+			return p.updateRename(msg)
+		}
 
 		switch {
-		case key.Matches(msg, keys.Quit):
+		case key.Matches(msg, p.keys.Quit):
 			p.result = Result{Action: ActionCancel}
 			return p, tea.Quit
 
-		case key.Matches(msg, keys.Enter):
+		case key.Matches(msg, p.keys.Enter):
 			if item, ok := p.selectedItem(); ok {
 				p.result = Result{
 					Selected: item,
 					Action:   ActionConfirm,
+					Marked:   p.multiSelectedItems(),
 				}
 			}
 			return p, tea.Quit
 
-		case key.Matches(msg, keys.Up):
+		case key.Matches(msg, p.keys.Up):
 			p.list.MoveUp()
 			p.syncFromList()
-			return p, nil
+			return p, p.previewCmd()
+
+		case key.Matches(msg, p.keys.CreateProject) && p.createQuery() != "" && !p.isKeyOverridden(p.keys.CreateProject.Keys()...):
+			p.result = Result{Action: ActionCreateProject, Query: p.createQuery()}
+			return p, tea.Quit
 
-		case key.Matches(msg, keys.Down):
+		case key.Matches(msg, p.keys.Down):
 			p.list.MoveDown()
 			p.syncFromList()
-			return p, nil
+			return p, p.previewCmd()
 
-		case key.Matches(msg, keys.HalfPageUp):
+		case key.Matches(msg, p.keys.HalfPageUp):
 			p.list.HalfPageUp()
 			p.syncFromList()
-			return p, nil
+			return p, p.previewCmd()
 
-		case key.Matches(msg, keys.HalfPageDown):
+		case key.Matches(msg, p.keys.HalfPageDown):
 			p.list.HalfPageDown()
 			p.syncFromList()
-			return p, nil
+			return p, p.previewCmd()
 
 		case p.matchUserDefinedCommand(msg) != nil:
 			cc := p.matchUserDefinedCommand(msg)
@@ -374,14 +834,32 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				UserDefinedCommand: &UserDefinedCommandResult{
 					Command: cc.Command,
 					Exit:    cc.Exit,
+					Multi:   cc.Multi,
 				},
 			}
 			if item, ok := p.selectedItem(); ok {
 				p.result.Selected = item
 			}
+			if cc.Multi {
+				p.result.Marked = p.markedItems()
+			}
 			return p, tea.Quit
 
-		case key.Matches(msg, keys.Delete):
+		case p.markable && key.Matches(msg, p.keys.Mark):
+			if item, ok := p.selectedItem(); ok {
+				if p.marked[item.Path] {
+					delete(p.marked, item.Path)
+				} else {
+					p.marked[item.Path] = true
+				}
+			}
+			// Advance toward the next candidate (the list grows upward from
+			// the best match at the bottom), like fzf's tab.
+			p.list.MoveUp()
+			p.syncFromList()
+			return p, p.previewCmd()
+
+		case key.Matches(msg, p.keys.Delete):
 			if p.showDelete {
 				if item, ok := p.selectedItem(); ok {
 					p.result = Result{
@@ -392,7 +870,7 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case key.Matches(msg, keys.ForceDelete):
+		case key.Matches(msg, p.keys.ForceDelete):
 			if p.showDelete {
 				if item, ok := p.selectedItem(); ok {
 					p.result = Result{
@@ -403,18 +881,19 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case key.Matches(msg, keys.KillSession):
+		case key.Matches(msg, p.keys.KillSession):
 			if p.showKillSession {
 				if item, ok := p.selectedItem(); ok {
 					p.result = Result{
 						Selected: item,
 						Action:   ActionKillSession,
+						Marked:   p.multiSelectedItems(),
 					}
 					return p, tea.Quit
 				}
 			}
 
-		case key.Matches(msg, keys.Reset):
+		case key.Matches(msg, p.keys.Reset):
 			if p.showReset {
 				if item, ok := p.selectedItem(); ok {
 					p.result = Result{
@@ -425,18 +904,30 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case key.Matches(msg, keys.OpenWindow):
+		case key.Matches(msg, p.keys.OpenWindow):
 			if p.showOpenWindow {
 				if item, ok := p.selectedItem(); ok {
 					p.result = Result{
 						Selected: item,
 						Action:   ActionOpenWindow,
+						Marked:   p.multiSelectedItems(),
 					}
 					return p, tea.Quit
 				}
 			}
 
-		case key.Matches(msg, keys.CreateWorktree):
+		case key.Matches(msg, p.keys.OpenEditor):
+			if p.showOpenEditor {
+				if item, ok := p.selectedItem(); ok {
+					p.result = Result{
+						Selected: item,
+						Action:   ActionOpenEditor,
+					}
+					return p, tea.Quit
+				}
+			}
+
+		case key.Matches(msg, p.keys.CreateWorktree):
 			if p.showCreateWorktree {
 				p.result = Result{Action: ActionCreateWorktree}
 				if item, ok := p.selectedItem(); ok {
@@ -445,7 +936,7 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return p, tea.Quit
 			}
 
-		case key.Matches(msg, keys.SetPreferred):
+		case key.Matches(msg, p.keys.SetPreferred):
 			if p.showSetPreferred {
 				if item, ok := p.selectedItem(); ok {
 					p.result = Result{
@@ -456,7 +947,22 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case key.Matches(msg, keys.YankPath):
+		case key.Matches(msg, p.keys.BrowseWorktrees):
+			if p.showBrowseWorktrees {
+				if item, ok := p.selectedItem(); ok {
+					p.result = Result{
+						Selected: item,
+						Action:   ActionBrowseWorktrees,
+					}
+					return p, tea.Quit
+				}
+			}
+
+		case key.Matches(msg, p.keys.Rename):
+			p.startRename()
+			return p, nil
+
+		case key.Matches(msg, p.keys.YankPath):
 			if item, ok := p.selectedItem(); ok {
 				p.result = Result{
 					Selected: item,
@@ -465,10 +971,40 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return p, tea.Quit
 			}
 
-		case key.Matches(msg, keys.ClearInput):
+		case len(p.sortModes) > 1 && key.Matches(msg, p.keys.Sort):
+			// Keep the highlighted item highlighted across the reorder.
+			current, hasCurrent := p.list.Selected()
+			p.sortIndex = (p.sortIndex + 1) % len(p.sortModes)
+			p.source = SliceSource(p.sortModes[p.sortIndex].Items)
+			p.filter()
+			if !hasCurrent || !p.list.SetCursorToKey(current.Path) {
+				p.list.SetCursor(len(p.filtered) - 1)
+			}
+			p.syncFromList()
+			return p, p.previewCmd()
+
+		case p.branchKeep != nil && key.Matches(msg, p.keys.BranchFilter):
+			p.branchFilterOn = !p.branchFilterOn
+			p.filter()
+			if len(p.filtered) > 0 {
+				p.list.SetCursor(len(p.filtered) - 1)
+				p.syncFromList()
+			}
+			return p, p.previewCmd()
+
+		case p.sessionFilterable && key.Matches(msg, p.keys.SessionFilter):
+			p.sessionFilter = p.sessionFilter.next()
+			p.filter()
+			if len(p.filtered) > 0 {
+				p.list.SetCursor(len(p.filtered) - 1)
+				p.syncFromList()
+			}
+			return p, p.previewCmd()
+
+		case key.Matches(msg, p.keys.ClearInput):
 			p.input.SetValue("")
 			p.filter()
-			return p, nil
+			return p, p.previewCmd()
 
 		case p.isQuickAccessKey(msg):
 			n := p.quickAccessDigit(msg)
@@ -486,9 +1022,34 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.WindowSizeMsg:
 		p.width = msg.Width
-		p.height = p.frameSpec().BodyHeight(msg.Height)
-		p.list.Resize(p.height)
-		p.syncFromList()
+		p.termH = msg.Height
+		p.resize()
+
+	case notificationTickMsg:
+		// An expired notification frees a line for the list.
+		p.resize()
+		return p, notificationTick()
+
+	case itemsMsg:
+		if msg.done {
+			p.itemStream = nil
+			p.loading = false
+			return p, nil
+		}
+		p.replaceItems(msg.items)
+		return p, tea.Batch(p.waitForItems(), p.previewCmd(), p.statusCmd())
+
+	case previewMsg:
+		if p.preview != nil {
+			p.preview.store(msg)
+		}
+		return p, nil
+
+	case statusMsg:
+		if p.statuses != nil {
+			p.statuses.store(msg)
+		}
+		return p, nil
 	}
 
 	// Update text input
@@ -497,13 +1058,7 @@ func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Filter items
 	p.filter()
 
-	return p, nil
-}
-
-// fzfMatch holds an item with its fuzzy match score
-type fzfMatch struct {
-	item  Item
-	score int
+	return p, p.previewCmd()
 }
 
 func (p *Picker) filter() {
@@ -517,33 +1072,40 @@ func (p *Picker) filter() {
 		debug.Log("filter: query %q -> %q, saving cursor for %q: path=%q", p.lastQuery, query, p.lastQuery, path)
 	}
 
-	// Build filtered list
-	if query == "" {
-		p.filtered = p.items
-	} else {
-		pattern := []rune(strings.ToLower(query))
-		slab := util.MakeSlab(100*1024, 2048)
-
-		var matches []fzfMatch
-		for _, item := range p.items {
-			chars := util.ToChars([]byte(strings.ToLower(item.Name)))
-			result, _ := algo.FuzzyMatchV2(false, true, true, &chars, pattern, false, slab)
-			if result.Score > 0 {
-				matches = append(matches, fzfMatch{item: item, score: result.Score})
-			}
-		}
+	// #tag words restrict the list to tagged items; the rest of the query
+	// is matched as usual.
+	tags, fuzzy := p.splitTagQuery(query)
 
-		sort.Slice(matches, func(i, j int) bool {
-			return matches[i].score < matches[j].score
+	// Build filtered list. Only the built-in matcher reports positions.
+	p.matchPos = nil
+	var candidates []Item
+	if p.remote == nil || fuzzy == "" {
+		candidates = p.keepItems(collectItems(p.source), tags)
+	}
+	if fuzzy == "" {
+		p.filtered = candidates
+	} else if p.remote != nil {
+		p.filtered = p.keepItems(p.remote.Filter(fuzzy), tags)
+	} else if ranked, ok := p.externalMatch(fuzzy, candidates); ok {
+		p.filtered = ranked
+	} else {
+		p.chars.fit(len(candidates))
+		matches := matchItems(fuzzy, candidates, p.chars, true)
+		// Best last, nearest the cursor.
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Score < matches[j].Score
 		})
 
 		p.filtered = make([]Item, len(matches))
+		p.matchPos = make(map[string][]int, len(matches))
 		for i, m := range matches {
-			p.filtered[i] = m.item
+			p.filtered[i] = m.Item
+			p.matchPos[m.Item.Path] = m.pos
 		}
 	}
 
 	p.list.SetItems(p.filtered)
+	p.computeGroupHeads()
 
 	if queryChanged {
 		if path, ok := p.cursorMemory[query]; ok {
@@ -561,15 +1123,147 @@ func (p *Picker) filter() {
 	p.syncFromList()
 }
 
+// keepItems returns the items that pass the session and branch filters and
+// carry every tag in tags, in order. It returns items itself when nothing is
+// filtered.
+func (p *Picker) keepItems(items []Item, tags []string) []Item {
+	branchOn := p.branchKeep != nil && p.branchFilterOn
+	if p.sessionFilter == sessionFilterAll && !branchOn && len(tags) == 0 {
+		return items
+	}
+	var kept []Item
+	for _, item := range items {
+		if p.sessionFilter.keep(item) && (!branchOn || p.branchKeep(item)) && hasTags(item, tags) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// splitTagQuery separates the #tag words of query from the rest. Tag words
+// only count when some item carries tags, so a "#" in a plain list is matched
+// literally.
+func (p *Picker) splitTagQuery(query string) (tags []string, rest string) {
+	if !strings.Contains(query, "#") || !p.anyTagged() {
+		return nil, query
+	}
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if tag, ok := strings.CutPrefix(word, "#"); ok && tag != "" {
+			tags = append(tags, strings.ToLower(tag))
+		} else if word != "#" {
+			words = append(words, word)
+		}
+	}
+	return tags, strings.Join(words, " ")
+}
+
+func (p *Picker) anyTagged() bool {
+	for item := range sourceItems(p.source) {
+		if len(item.Tags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTags reports whether item has a tag starting with each of tags, so a
+// half-typed #wo already narrows to "work".
+func hasTags(item Item, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range item.Tags {
+			if strings.HasPrefix(strings.ToLower(tag), want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// externalMatch ranks candidates with the configured matcher, best match last
+// like the built-in ranking. ok is false when there is no matcher or it failed
+// (including returning an out-of-range index), so the caller falls back.
+func (p *Picker) externalMatch(query string, candidates []Item) ([]Item, bool) {
+	if p.matcher == nil {
+		return nil, false
+	}
+	indices, err := p.matcher(query, candidates)
+	if err != nil {
+		debug.Error("filter: external matcher for %q: %v", query, err)
+		return nil, false
+	}
+	seen := make(map[int]bool, len(indices))
+	ranked := make([]Item, 0, len(indices))
+	for _, idx := range indices {
+		if idx < 0 || idx >= len(candidates) {
+			debug.Error("filter: external matcher returned index %d of %d candidates", idx, len(candidates))
+			return nil, false
+		}
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		ranked = append(ranked, candidates[idx])
+	}
+	slices.Reverse(ranked)
+	return ranked, true
+}
+
 // buildHints returns the hints string based on enabled features
 func (p *Picker) buildHints() string {
-	return "  Enter open · Esc quit · C-h help"
+	if p.renaming != nil {
+		return "  Rename " + p.renaming.current + " · Enter confirm · Esc cancel"
+	}
+	hints := "  Enter open · Esc quit · " + formatKeyHint(p.keys.Help) + " help"
+	if h := p.sessionFilter.hint(); h != "" {
+		hints += " · " + h
+	}
+	if p.branchKeep != nil && p.branchFilterOn {
+		hints += " · branch filter on"
+	}
+	if len(p.sortModes) > 1 {
+		hints += " · sort: " + p.sortModes[p.sortIndex].Name
+	}
+	for _, cc := range p.customCommands {
+		if cc.HintBar {
+			hints += " · " + cc.keyHint() + " " + cc.Label
+		}
+	}
+	if p.loading {
+		hints += " · loading…"
+	}
+	if p.tip != "" {
+		hints += " · " + p.tip
+	}
+	if query := p.createQuery(); query != "" && !p.isKeyOverridden(p.keys.CreateProject.Keys()...) {
+		hints += " · " + formatKeyHint(p.keys.CreateProject) + " " + p.createLabel(query)
+	}
+	return hints
+}
+
+// createQuery returns the query to offer creating a project from: the trimmed
+// filter text while it matches nothing, or "" when creation is off or does
+// not apply.
+func (p *Picker) createQuery() string {
+	if p.createLabel == nil || len(p.filtered) > 0 || p.loading {
+		return ""
+	}
+	return strings.TrimSpace(p.input.Value())
 }
 
 // frameSpec builds the Frame describing the picker's screen chrome: the
-// update notice, header, input box, warnings, and hints.
+// update notice, header, input box, warnings, notifications, and hints.
 func (p *Picker) frameSpec() Frame {
 	header := p.header
+	inputBox := p.input.View()
+	if p.renaming != nil {
+		inputBox = p.renaming.field.View()
+	}
 	if header != "" {
 		header = "  " + header
 	}
@@ -577,10 +1271,23 @@ func (p *Picker) frameSpec() Frame {
 		Width:    p.width,
 		Notice:   p.updateNotice,
 		Header:   header,
-		InputBox: p.input.View(),
+		InputBox: inputBox,
 		Warnings: p.warnings,
 		Hints:    p.buildHints(),
+
+		Notifications: notifications.Active(),
+
+		styles: p.styles,
+	}
+}
+
+// keyHint returns what the hint bar and help overlay show for the command's
+// key: its hint when set, else the formatted binding.
+func (cc UserDefinedKeyBinding) keyHint() string {
+	if cc.Hint != "" {
+		return cc.Hint
 	}
+	return formatKeyHint(cc.Binding)
 }
 
 // formatKeyHint converts a key binding to a display-friendly hint format
@@ -639,8 +1346,11 @@ func (p *Picker) quickAccessDigit(msg tea.KeyPressMsg) int {
 }
 
 func (p *Picker) pickerHasIcons() bool {
-	for j := range p.items {
-		if p.items[j].Icon != "" {
+	if p.hideIcons {
+		return false
+	}
+	for item := range sourceItems(p.source) {
+		if item.Icon != "" {
 			return true
 		}
 	}
@@ -653,23 +1363,49 @@ func (p *Picker) pickerMaxContextLen() int {
 	}
 	maxContextLen := 0
 	for _, item := range p.filtered {
-		if len(item.Context) > maxContextLen {
-			maxContextLen = len(item.Context)
+		if w := lipgloss.Width(sanitizeName(item.Context)); w > maxContextLen {
+			maxContextLen = w
 		}
 	}
 	return maxContextLen
 }
 
-func (p *Picker) pickerCell(item Item, _ RowState) string {
+func (p *Picker) pickerCell(item Item, state RowState) string {
 	maxContextLen := p.pickerMaxContextLen()
 	hasIcons := p.pickerHasIcons()
+	name := sanitizeName(item.Name)
+	// Positions index the raw FilterValue, so they only line up with names
+	// that sanitizing left alone.
+	pos := p.matchPos[item.Path]
+	if name != item.Name {
+		pos = nil
+	}
+	tagsOffset := utf8.RuneCountInString(name) + 1
+	name = highlightMatches(name, 0, pos, nil, p.styles.match)
 
 	var line string
 	if p.showContext && item.Context != "" {
-		contextPadding := maxContextLen - len(item.Context)
-		line = " [" + item.Context + "]" + strings.Repeat(" ", contextPadding) + " " + item.Name
+		context := sanitizeName(item.Context)
+		contextPadding := maxContextLen - lipgloss.Width(context)
+		if p.styles.context != nil {
+			context = p.styles.context.Render(context)
+		}
+		line = " [" + context + "]" + strings.Repeat(" ", contextPadding) + " " + name
 	} else {
-		line = " " + item.Name
+		line = " " + name
+	}
+	if len(item.Tags) > 0 {
+		line += " " + highlightMatches(sanitizeName(strings.Join(item.Tags, " ")), tagsOffset, pos, &p.styles.dim, p.styles.match)
+	}
+	if p.statuses != nil {
+		if status := sanitizeName(p.statuses.results[item.Path]); status != "" {
+			line += " " + p.styles.hint.Render(status)
+		}
+	}
+
+	if p.typeIconWidth > 0 && !p.hideIcons {
+		typeIcon := sanitizeName(item.TypeIcon)
+		line = " " + typeIcon + strings.Repeat(" ", p.typeIconWidth-lipgloss.Width(typeIcon)) + line
 	}
 
 	if hasIcons {
@@ -680,9 +1416,69 @@ func (p *Picker) pickerCell(item Item, _ RowState) string {
 		}
 	}
 
+	if state.Selected && p.styles.selected != nil {
+		line = p.styles.selected.Render(line)
+	}
+
+	if p.groupHeaders {
+		label := ""
+		if p.groupHeads[item.Path] {
+			label = sanitizeName(contractTilde(item.Group))
+		}
+		pad := p.groupWidth - lipgloss.Width(label)
+		line = " " + p.styles.header.Render(label) + strings.Repeat(" ", pad) + line
+	}
+
+	if p.markable && (!p.multiSelect || len(p.marked) > 0) {
+		if p.marked[item.Path] {
+			line = " " + p.styles.indicator.Render("+") + line
+		} else {
+			line = "  " + line
+		}
+	}
+
 	return line
 }
 
+// typeIconColumnWidth is the widest project-type icon, so names line up
+// after the type column.
+func (p *Picker) typeIconColumnWidth() int {
+	width := 0
+	for item := range sourceItems(p.source) {
+		width = max(width, lipgloss.Width(sanitizeName(item.TypeIcon)))
+	}
+	return width
+}
+
+// computeGroupHeads marks the first item of each run of equal Group in the
+// filtered list, so the group label is drawn once per run.
+func (p *Picker) computeGroupHeads() {
+	if !p.groupHeaders {
+		return
+	}
+	p.groupHeads = make(map[string]bool, len(p.filtered))
+	for i, item := range p.filtered {
+		if i == 0 || p.filtered[i-1].Group != item.Group {
+			p.groupHeads[item.Path] = true
+		}
+	}
+}
+
+// groupLabelWidth is the widest group label, so names line up after the
+// group column.
+func (p *Picker) groupLabelWidth() int {
+	if !p.groupHeaders {
+		return 0
+	}
+	width := 0
+	for item := range sourceItems(p.source) {
+		if w := lipgloss.Width(sanitizeName(contractTilde(item.Group))); w > width {
+			width = w
+		}
+	}
+	return width
+}
+
 func (p *Picker) View() tea.View {
 	var content string
 	if p.showHelp {
@@ -699,35 +1495,68 @@ func (p *Picker) View() tea.View {
 func (p *Picker) helpEntries() []HelpEntry {
 	entries := []HelpEntry{
 		{"↑/↓ C-p/C-n", "Navigate"},
-		{"C-b/C-f", "Page up / down"},
-		{"C-u", "Clear filter"},
+		{formatKeyHint(p.keys.HalfPageUp) + "/" + formatKeyHint(p.keys.HalfPageDown), "Page up / down"},
+		{formatKeyHint(p.keys.ClearInput), "Clear filter"},
 		{"Enter", "Select"},
 		{"Esc", "Quit"},
 	}
 
-	if p.showKillSession && !p.isKeyOverridden("ctrl+k") {
-		entries = append(entries, HelpEntry{"C-k", "Kill tmux session"})
+	if p.showKillSession && !p.isKeyOverridden(p.keys.KillSession.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.KillSession), "Kill tmux session"})
 	}
-	if p.showReset && !p.isKeyOverridden("ctrl+r") {
-		entries = append(entries, HelpEntry{"C-r", "Reset history"})
+	if p.showReset && !p.isKeyOverridden(p.keys.Reset.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.Reset), "Reset history"})
 	}
-	if p.showOpenWindow && !p.isKeyOverridden("ctrl+o") {
-		entries = append(entries, HelpEntry{"C-o", "Open in window"})
+	if p.showOpenWindow && !p.isKeyOverridden(p.keys.OpenWindow.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.OpenWindow), "Open in window"})
 	}
-	if p.showCreateWorktree && !p.isKeyOverridden("ctrl+a") {
-		entries = append(entries, HelpEntry{"C-a", "Create worktree"})
+	if p.showOpenEditor && !p.isKeyOverridden(p.keys.OpenEditor.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.OpenEditor), "Open in editor"})
 	}
-	if p.showSetPreferred && !p.isKeyOverridden("ctrl+w") {
-		entries = append(entries, HelpEntry{"C-w", "Set preferred workbench"})
+	if p.showCreateWorktree && !p.isKeyOverridden(p.keys.CreateWorktree.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.CreateWorktree), "Create worktree"})
 	}
-	if p.showDelete && !p.isKeyOverridden("ctrl+d") {
-		entries = append(entries, HelpEntry{"C-d", "Delete"})
+	if p.showSetPreferred && !p.isKeyOverridden(p.keys.SetPreferred.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.SetPreferred), "Set preferred workbench"})
 	}
-	if !p.isKeyOverridden("ctrl+y") {
-		entries = append(entries, HelpEntry{"C-y", "Yank path to pane"})
+	if p.showBrowseWorktrees && !p.isKeyOverridden(p.keys.BrowseWorktrees.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.BrowseWorktrees), "Browse worktrees"})
 	}
-	if p.showDelete && !p.isKeyOverridden("ctrl+x") {
-		entries = append(entries, HelpEntry{"C-x", "Force delete"})
+	if p.createLabel != nil && !p.isKeyOverridden(p.keys.CreateProject.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.CreateProject), "Create project (when nothing matches)"})
+	}
+	if p.renameFn != nil && !p.isKeyOverridden(p.keys.Rename.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.Rename), "Rename session"})
+	}
+	if p.showDelete && !p.isKeyOverridden(p.keys.Delete.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.Delete), "Delete"})
+	}
+	if !p.isKeyOverridden(p.keys.YankPath.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.YankPath), "Yank path to pane"})
+	}
+	if p.showDelete && !p.isKeyOverridden(p.keys.ForceDelete.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.ForceDelete), "Force delete"})
+	}
+	if p.sessionFilterable && !p.isKeyOverridden(p.keys.SessionFilter.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.SessionFilter), "Cycle session filter"})
+	}
+	if p.branchKeep != nil && !p.isKeyOverridden(p.keys.BranchFilter.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.BranchFilter), "Toggle branch filter"})
+	}
+	if p.anyTagged() {
+		entries = append(entries, HelpEntry{"#tag", "Filter by tag"})
+	}
+	if len(p.sortModes) > 1 && !p.isKeyOverridden(p.keys.Sort.Keys()...) {
+		entries = append(entries, HelpEntry{formatKeyHint(p.keys.Sort), "Cycle sort order"})
+	}
+	markKey := formatKeyHint(p.keys.Mark)
+	if markKey == "tab" {
+		markKey = "Tab"
+	}
+	if p.multiSelect && !p.isKeyOverridden(p.keys.Mark.Keys()...) {
+		entries = append(entries, HelpEntry{markKey, "Mark; actions apply to every marked item"})
+	} else if p.markable && !p.isKeyOverridden(p.keys.Mark.Keys()...) {
+		entries = append(entries, HelpEntry{markKey, "Mark for multi commands"})
 	}
 	switch p.quickAccessModifier {
 	case "alt":
@@ -737,12 +1566,12 @@ func (p *Picker) helpEntries() []HelpEntry {
 	}
 
 	for _, cc := range p.customCommands {
-		entries = append(entries, HelpEntry{formatKeyHint(cc.Binding), cc.Label})
+		entries = append(entries, HelpEntry{cc.keyHint(), cc.Label})
 	}
 
 	iconsSeen := make(map[string]bool)
-	for _, item := range p.items {
-		if item.Icon != "" {
+	for item := range sourceItems(p.source) {
+		if item.Icon != "" && !p.hideIcons {
 			iconsSeen[item.Icon] = true
 		}
 	}
@@ -758,17 +1587,61 @@ func (p *Picker) helpEntries() []HelpEntry {
 	return entries
 }
 
+// HelpEntries returns the key table the help overlay of a picker built with
+// opts lists: the built-in keys no user-defined command overrides, then the
+// user-defined commands. Entries that depend on the items shown (tag filter,
+// icon legend) are left out.
+func HelpEntries(opts ...PickerOption) []HelpEntry {
+	return NewPicker(nil, opts...).helpEntries()
+}
+
+// HelpToggleKey returns the display form of the key that toggles the help
+// overlay of a picker built with opts.
+func HelpToggleKey(opts ...PickerOption) string {
+	return formatKeyHint(NewPicker(nil, opts...).keys.Help)
+}
+
 func (p *Picker) viewHelp() string {
-	return RenderHelpOverlay("Help", p.helpEntries(), p.width, p.height)
+	return renderHelpOverlay("Help", p.helpEntries(), p.width, p.height, formatKeyHint(p.keys.Help), p.styles)
 }
 
 func (p *Picker) viewProject() string {
-	return p.frameSpec().Render(strings.Join(p.list.VisibleRows(), "\n"))
+	return p.frameSpec().Render(strings.Join(p.bodyRows(), "\n"))
+}
+
+// bodyRows returns the list rows, joined side by side with the preview pane
+// when one is enabled and the terminal is wide enough to split.
+func (p *Picker) bodyRows() []string {
+	rows := p.list.VisibleRows()
+	if p.preview == nil || p.width < previewMinWidth {
+		return rows
+	}
+	leftWidth := p.width / 2
+	rightWidth := p.width - leftWidth - 2
+	// A short list still gets a full-height preview.
+	for len(rows) < p.height {
+		rows = append(rows, "")
+	}
+
+	var preview []string
+	if item, ok := p.list.Selected(); ok {
+		lines, cached := p.preview.lines(item.Path)
+		if cached {
+			preview = lines
+		} else {
+			preview = []string{p.styles.dim.Render("loading preview…")}
+		}
+	}
+	return joinPreviewColumns(rows, preview, leftWidth, rightWidth)
 }
 
 // Result returns the picker result after running
 func (p *Picker) Result() Result {
 	p.result.CursorIndex = p.list.Cursor()
+	if len(p.sortModes) > 0 {
+		p.result.SortMode = p.sortModes[p.sortIndex].Name
+	}
+	p.result.BranchFilter = p.branchKeep != nil && p.branchFilterOn
 	return p.result
 }
 
@@ -785,21 +1658,30 @@ func Run(items []Item, opts ...PickerOption) (Result, error) {
 
 // Key bindings
 type keyMap struct {
-	Up             key.Binding
-	Down           key.Binding
-	HalfPageUp     key.Binding
-	HalfPageDown   key.Binding
-	Enter          key.Binding
-	Quit           key.Binding
-	Delete         key.Binding
-	ForceDelete    key.Binding
-	KillSession    key.Binding
-	Reset          key.Binding
-	OpenWindow     key.Binding
-	ClearInput     key.Binding
-	YankPath       key.Binding
-	CreateWorktree key.Binding
-	SetPreferred   key.Binding
+	Up              key.Binding
+	Down            key.Binding
+	HalfPageUp      key.Binding
+	HalfPageDown    key.Binding
+	Enter           key.Binding
+	Quit            key.Binding
+	Delete          key.Binding
+	ForceDelete     key.Binding
+	KillSession     key.Binding
+	Reset           key.Binding
+	OpenWindow      key.Binding
+	OpenEditor      key.Binding
+	CreateProject   key.Binding
+	ClearInput      key.Binding
+	YankPath        key.Binding
+	CreateWorktree  key.Binding
+	SetPreferred    key.Binding
+	BrowseWorktrees key.Binding
+	Rename          key.Binding
+	Mark            key.Binding
+	SessionFilter   key.Binding
+	BranchFilter    key.Binding
+	Sort            key.Binding
+	Help            key.Binding
 }
 
 var keys = keyMap{
@@ -836,8 +1718,16 @@ var keys = keyMap{
 	OpenWindow: key.NewBinding(
 		key.WithKeys("ctrl+o"),
 	),
+	OpenEditor: key.NewBinding(
+		key.WithKeys("alt+enter"),
+	),
+	// CreateProject shares ctrl+n with Down, which has nothing to move
+	// through while the query matches nothing.
+	CreateProject: key.NewBinding(
+		key.WithKeys("ctrl+n"),
+	),
 	ClearInput: key.NewBinding(
-		key.WithKeys("alt+backspace", "ctrl+u"),
+		key.WithKeys("ctrl+u", "alt+backspace"),
 	),
 	YankPath: key.NewBinding(
 		key.WithKeys("ctrl+y"),
@@ -848,4 +1738,81 @@ var keys = keyMap{
 	SetPreferred: key.NewBinding(
 		key.WithKeys("ctrl+w"),
 	),
+	BrowseWorktrees: key.NewBinding(
+		key.WithKeys("ctrl+l"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("f2"),
+	),
+	Mark: key.NewBinding(
+		key.WithKeys("tab"),
+	),
+	SessionFilter: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+	),
+	BranchFilter: key.NewBinding(
+		key.WithKeys("ctrl+v"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+	),
+	Help: HelpKeys,
+}
+
+// binding returns the binding [keys] names action, or nil for an action that
+// can't be remapped. Navigation, Enter and Esc stay fixed.
+func (km *keyMap) binding(action string) *key.Binding {
+	switch action {
+	case "page_up":
+		return &km.HalfPageUp
+	case "page_down":
+		return &km.HalfPageDown
+	case "clear_input":
+		return &km.ClearInput
+	case "delete":
+		return &km.Delete
+	case "force_delete":
+		return &km.ForceDelete
+	case "kill_session":
+		return &km.KillSession
+	case "reset":
+		return &km.Reset
+	case "open_window":
+		return &km.OpenWindow
+	case "open_editor":
+		return &km.OpenEditor
+	case "create_project":
+		return &km.CreateProject
+	case "yank_path":
+		return &km.YankPath
+	case "create_worktree":
+		return &km.CreateWorktree
+	case "set_preferred_workbench":
+		return &km.SetPreferred
+	case "browse_worktrees":
+		return &km.BrowseWorktrees
+	case "rename":
+		return &km.Rename
+	case "mark":
+		return &km.Mark
+	case "session_filter":
+		return &km.SessionFilter
+	case "branch_filter":
+		return &km.BranchFilter
+	case "sort":
+		return &km.Sort
+	case "help":
+		return &km.Help
+	}
+	return nil
+}
+
+// normalizeKeyName accepts the "ctrl-q" spelling config files use for the
+// "ctrl+q" bubbletea reports.
+func normalizeKeyName(k string) string {
+	k = strings.ToLower(strings.TrimSpace(k))
+	k = strings.ReplaceAll(k, "ctrl-", "ctrl+")
+	k = strings.ReplaceAll(k, "alt-", "alt+")
+	k = strings.ReplaceAll(k, "shift-", "shift+")
+	return k
 }
@@ -1,7 +1,12 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
@@ -154,6 +159,77 @@ func TestCreateWorktreeKey(t *testing.T) {
 	}
 }
 
+func TestCreateProjectKey(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api"}, {Name: "web", Path: "/web"}}
+	label := func(query string) string { return "create ~/Dev/" + query }
+
+	// With matches, ctrl+n still moves down.
+	picker := NewPicker(items, WithCreateProject(label))
+	picker.Init()
+	picker.input.SetValue("a")
+	picker.filter()
+	if _, cmd := picker.Update(tea.KeyPressMsg{Code: 'n', Mod: tea.ModCtrl}); cmd != nil || picker.result.Action == ActionCreateProject {
+		t.Error("ctrl+n with matches should navigate, not create")
+	}
+
+	// Disabled: no matches, but ctrl+n is a no-op.
+	picker = NewPicker(items)
+	picker.Init()
+	picker.input.SetValue("newproj")
+	picker.filter()
+	picker.Update(tea.KeyPressMsg{Code: 'n', Mod: tea.ModCtrl})
+	if picker.result.Action == ActionCreateProject {
+		t.Error("ctrl+n should not create when WithCreateProject is disabled")
+	}
+
+	// Enabled with no matches: the hint offers it and ctrl+n fires it.
+	picker = NewPicker(items, WithCreateProject(label))
+	picker.Init()
+	picker.input.SetValue(" newproj ")
+	picker.filter()
+	if hints := picker.buildHints(); !strings.Contains(hints, "C-n create ~/Dev/newproj") {
+		t.Errorf("hints = %q, want the create affordance", hints)
+	}
+	_, cmd := picker.Update(tea.KeyPressMsg{Code: 'n', Mod: tea.ModCtrl})
+	if picker.result.Action != ActionCreateProject || picker.result.Query != "newproj" {
+		t.Errorf("result = %+v, want ActionCreateProject for newproj", picker.result)
+	}
+	if cmd == nil {
+		t.Error("ctrl+n should return tea.Quit cmd")
+	}
+}
+
+func TestCreateProjectKey_RemappedOrTakenOver(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api"}}
+	label := func(query string) string { return "create " + query }
+	noMatch := func(p *Picker) {
+		p.Init()
+		p.input.SetValue("newproj")
+		p.filter()
+	}
+
+	picker := NewPicker(items, WithCreateProject(label), WithKeyBindings(map[string]string{"create_project": "ctrl-e"}))
+	noMatch(picker)
+	picker.Update(tea.KeyPressMsg{Code: 'n', Mod: tea.ModCtrl})
+	if picker.result.Action == ActionCreateProject {
+		t.Error("ctrl+n created a project after create_project moved to ctrl+e")
+	}
+	picker.Update(tea.KeyPressMsg{Code: 'e', Mod: tea.ModCtrl})
+	if picker.result.Action != ActionCreateProject {
+		t.Errorf("ctrl+e result = %+v, want ActionCreateProject", picker.result)
+	}
+
+	picker = NewPicker(items, WithCreateProject(label), WithUserDefinedCommands([]UserDefinedCommand{{Key: "ctrl+n", Label: "notes", Command: "notes"}}))
+	noMatch(picker)
+	if hints := picker.buildHints(); strings.Contains(hints, "create newproj") {
+		t.Errorf("hints = %q, want no create affordance under a custom ctrl+n", hints)
+	}
+	picker.Update(tea.KeyPressMsg{Code: 'n', Mod: tea.ModCtrl})
+	if picker.result.Action == ActionCreateProject {
+		t.Error("ctrl+n created a project although a custom command takes it")
+	}
+}
+
 func TestSetPreferredWorkbenchKey(t *testing.T) {
 	items := []Item{{Name: "wt", Path: "/wt"}}
 
@@ -180,6 +256,55 @@ func TestSetPreferredWorkbenchKey(t *testing.T) {
 	}
 }
 
+func TestBrowseWorktreesKey(t *testing.T) {
+	items := []Item{{Name: "repo/main", Path: "/repo/main"}}
+
+	picker := NewPicker(items)
+	picker.Init()
+	picker.Update(tea.KeyPressMsg{Code: 'l', Mod: tea.ModCtrl})
+	if picker.result.Action == ActionBrowseWorktrees {
+		t.Error("ctrl+l should not fire when WithBrowseWorktrees is disabled")
+	}
+
+	picker = NewPicker(items, WithBrowseWorktrees())
+	picker.Init()
+	_, cmd := picker.Update(tea.KeyPressMsg{Code: 'l', Mod: tea.ModCtrl})
+	if picker.result.Action != ActionBrowseWorktrees {
+		t.Errorf("ctrl+l should fire ActionBrowseWorktrees, got %v", picker.result.Action)
+	}
+	if picker.result.Selected == nil || picker.result.Selected.Path != "/repo/main" {
+		t.Errorf("ctrl+l result should carry the highlighted row, got %+v", picker.result.Selected)
+	}
+	if cmd == nil {
+		t.Error("ctrl+l should return tea.Quit cmd")
+	}
+}
+
+func TestOpenEditorKey(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api"}}
+	altEnter := tea.KeyPressMsg{Code: tea.KeyEnter, Mod: tea.ModAlt}
+
+	picker := NewPicker(items)
+	picker.Init()
+	picker.Update(altEnter)
+	if picker.result.Action == ActionOpenEditor {
+		t.Error("alt+enter should not fire when WithOpenEditor is disabled")
+	}
+
+	picker = NewPicker(items, WithOpenEditor())
+	picker.Init()
+	_, cmd := picker.Update(altEnter)
+	if picker.result.Action != ActionOpenEditor {
+		t.Errorf("alt+enter should fire ActionOpenEditor, got %v", picker.result.Action)
+	}
+	if picker.result.Selected == nil || picker.result.Selected.Path != "/api" {
+		t.Errorf("alt+enter result should carry the highlighted row, got %+v", picker.result.Selected)
+	}
+	if cmd == nil {
+		t.Error("alt+enter should return tea.Quit cmd")
+	}
+}
+
 func TestHelpViewShowsSetPreferredWorkbench(t *testing.T) {
 	items := []Item{{Name: "test", Path: "/test"}}
 
@@ -881,6 +1006,184 @@ func TestFilterCaseInsensitive(t *testing.T) {
 	}
 }
 
+func TestFilterExternalMatcherRanksBestLast(t *testing.T) {
+	items := []Item{
+		{Name: "alpha", Path: "/a"},
+		{Name: "beta", Path: "/b"},
+		{Name: "gamma", Path: "/g"},
+	}
+	var gotQuery string
+	matcher := func(query string, items []Item) ([]int, error) {
+		gotQuery = query
+		return []int{2, 0, 2}, nil
+	}
+	picker := NewPicker(items, WithCursorAtEnd(), WithMatcher(matcher))
+	picker.Init()
+
+	typeInPicker(picker, "zz")
+
+	if gotQuery != "zz" {
+		t.Errorf("matcher query = %q, want %q", gotQuery, "zz")
+	}
+	if len(picker.filtered) != 2 || picker.filtered[0].Path != "/a" || picker.filtered[1].Path != "/g" {
+		t.Errorf("filtered = %v, want [/a /g] (best match last, duplicates dropped)", picker.filtered)
+	}
+}
+
+func TestFilterMatchesTags(t *testing.T) {
+	items := []Item{
+		{Name: "api", Path: "/api", Tags: []string{"work", "go"}},
+		{Name: "dotfiles", Path: "/dotfiles"},
+	}
+	picker := NewPicker(items, WithCursorAtEnd())
+	picker.Init()
+
+	typeInPicker(picker, "work")
+
+	if got := filteredPaths(picker); len(got) != 1 || got[0] != "/api" {
+		t.Errorf("filtered = %v, want [/api] matched by its tag", got)
+	}
+	if cell := StripANSI(picker.pickerCell(items[0], RowState{})); !strings.Contains(cell, "api") || !strings.Contains(cell, "work go") {
+		t.Errorf("cell = %q, want the name followed by the tags", cell)
+	}
+}
+
+func TestFilterTagWordsRestrictToTag(t *testing.T) {
+	items := []Item{
+		{Name: "api", Path: "/work/api", Tags: []string{"work", "go"}},
+		{Name: "web", Path: "/work/web", Tags: []string{"Work"}},
+		{Name: "api-client", Path: "/oss/api-client", Tags: []string{"oss", "go"}},
+		{Name: "notes", Path: "/notes"},
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"#work", []string{"/work/api", "/work/web"}},
+		{"#wo", []string{"/work/api", "/work/web"}},           // prefix while typing
+		{"#go #oss", []string{"/oss/api-client"}},             // every tag must match
+		{"api #go", []string{"/oss/api-client", "/work/api"}}, // fuzzy within the tag
+		{"#", []string{"/work/api", "/work/web", "/oss/api-client", "/notes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			picker := NewPicker(items, WithCursorAtEnd())
+			picker.Init()
+			typeInPicker(picker, tt.query)
+
+			got := filteredPaths(picker)
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("filtered = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFilterHashIsLiteralWithoutTags(t *testing.T) {
+	items := []Item{{Name: "c#-tools", Path: "/c#-tools"}, {Name: "go", Path: "/go"}}
+	picker := NewPicker(items, WithCursorAtEnd())
+	picker.Init()
+	typeInPicker(picker, "c#")
+
+	if got := filteredPaths(picker); len(got) != 1 || got[0] != "/c#-tools" {
+		t.Errorf("filtered = %v, want [/c#-tools]", got)
+	}
+}
+
+func TestMultiSelectReturnsMarkedOnBuiltinActions(t *testing.T) {
+	items := []Item{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}, {Name: "c", Path: "/c"}}
+	tests := []struct {
+		name string
+		key  tea.KeyPressMsg
+		opts []PickerOption
+	}{
+		{"enter", tea.KeyPressMsg{Code: tea.KeyEnter}, nil},
+		{"kill session", tea.KeyPressMsg{Code: 'k', Mod: tea.ModCtrl}, []PickerOption{WithKillSession()}},
+		{"open window", tea.KeyPressMsg{Code: 'o', Mod: tea.ModCtrl}, []PickerOption{WithOpenWindow()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]PickerOption{WithCursorAtEnd(), WithMultiSelect()}, tt.opts...)
+			picker := NewPicker(items, opts...)
+			picker.Init()
+			tab := tea.KeyPressMsg{Code: tea.KeyTab}
+			picker.Update(tab) // marks /c, moves to /b
+			picker.Update(tab) // marks /b, moves to /a
+
+			picker.Update(tt.key)
+
+			got := picker.Result().Marked
+			if len(got) != 2 || got[0].Path != "/b" || got[1].Path != "/c" {
+				t.Errorf("Marked = %v, want [/b /c]", got)
+			}
+		})
+	}
+}
+
+func TestMarkColumnOnlyWhileMarkedUnderMultiSelect(t *testing.T) {
+	items := []Item{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}}
+	picker := NewPicker(items, WithCursorAtEnd(), WithMultiSelect())
+	picker.Init()
+
+	if cell := picker.pickerCell(items[0], RowState{}); cell != " a" {
+		t.Errorf("cell with nothing marked = %q, want no mark column", cell)
+	}
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if cell := picker.pickerCell(items[0], RowState{}); cell != "   a" {
+		t.Errorf("cell with a mark elsewhere = %q, want the mark column", cell)
+	}
+}
+
+func TestPickerCellHighlightsMatches(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api", Tags: []string{"work"}}, {Name: "docs", Path: "/docs"}}
+	picker := NewPicker(items)
+	picker.Init()
+
+	if cell := picker.pickerCell(items[0], RowState{}); strings.Contains(cell, matchStyle.Render("api")) {
+		t.Errorf("cell without a query = %q, want no highlight", cell)
+	}
+	typeInPicker(picker, "api")
+	if cell := picker.pickerCell(items[0], RowState{}); !strings.HasPrefix(cell, " "+matchStyle.Render("api")) {
+		t.Errorf("cell = %q, want the matched name highlighted", cell)
+	}
+
+	picker.Update(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+	typeInPicker(picker, "wor")
+	cell := picker.pickerCell(items[0], RowState{})
+	if !strings.Contains(cell, matchStyle.Render("wor")) || strings.Contains(cell, matchStyle.Render("api")) {
+		t.Errorf("cell = %q, want only the matched tag characters highlighted", cell)
+	}
+}
+
+func TestFilterExternalMatcherFallsBack(t *testing.T) {
+	items := []Item{
+		{Name: "dev", Path: "/dev"},
+		{Name: "backstage", Path: "/backstage"},
+	}
+	tests := []struct {
+		name    string
+		matcher MatchFunc
+	}{
+		{"error", func(string, []Item) ([]int, error) { return nil, errors.New("boom") }},
+		{"out of range", func(string, []Item) ([]int, error) { return []int{5}, nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picker := NewPicker(items, WithCursorAtEnd(), WithMatcher(tt.matcher))
+			picker.Init()
+
+			typeInPicker(picker, "dev")
+
+			if len(picker.filtered) != 1 || picker.filtered[0].Path != "/dev" {
+				t.Errorf("filtered = %v, want the fuzzy match /dev", picker.filtered)
+			}
+		})
+	}
+}
+
 func TestFilterCaseInsensitiveUppercaseQuery(t *testing.T) {
 	items := []Item{
 		{Name: "dev", Path: "/dev"},
@@ -1195,3 +1498,530 @@ func TestResetAction(t *testing.T) {
 		t.Errorf("action = %v, want ActionReset", p.result.Action)
 	}
 }
+
+func TestMultiCommandReturnsMarkedItems(t *testing.T) {
+	items := []Item{
+		{Name: "a", Path: "/a"},
+		{Name: "b", Path: "/b"},
+		{Name: "c", Path: "/c"},
+	}
+	commands := []UserDefinedCommand{{Key: "ctrl+e", Label: "edit", Command: "code {paths}", Multi: true}}
+	picker := NewPicker(items, WithUserDefinedCommands(commands), WithCursorAtEnd())
+	picker.Init()
+
+	// tab marks /c and advances to /b; tab again marks /b.
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	picker.Update(tea.KeyPressMsg{Code: 'e', Mod: tea.ModCtrl})
+
+	result := picker.Result()
+	if result.UserDefinedCommand == nil || !result.UserDefinedCommand.Multi {
+		t.Fatalf("expected a multi user-defined command result, got %+v", result.UserDefinedCommand)
+	}
+	if len(result.Marked) != 2 || result.Marked[0].Path != "/b" || result.Marked[1].Path != "/c" {
+		t.Errorf("Marked = %v, want [/b /c] in list order", result.Marked)
+	}
+}
+
+func TestMultiCommandFallsBackToHighlighted(t *testing.T) {
+	items := []Item{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}}
+	commands := []UserDefinedCommand{{Key: "ctrl+e", Command: "code {paths}", Multi: true}}
+	picker := NewPicker(items, WithUserDefinedCommands(commands), WithCursorAtEnd())
+	picker.Init()
+
+	picker.Update(tea.KeyPressMsg{Code: 'e', Mod: tea.ModCtrl})
+	if m := picker.Result().Marked; len(m) != 1 || m[0].Path != "/b" {
+		t.Errorf("Marked = %v, want the highlighted item only", m)
+	}
+}
+
+func TestTabIgnoredWithoutMultiCommands(t *testing.T) {
+	items := []Item{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}}
+	picker := NewPicker(items, WithCursorAtEnd())
+	picker.Init()
+
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if len(picker.marked) != 0 {
+		t.Errorf("tab should not mark without a multi command, marked = %v", picker.marked)
+	}
+}
+
+func TestWithTipAppendsToHints(t *testing.T) {
+	items := []Item{{Name: "test", Path: "/test"}}
+	if hints := NewPicker(items).buildHints(); strings.Contains(hints, "Tip") {
+		t.Errorf("hints without a tip should not mention one: %q", hints)
+	}
+	hints := NewPicker(items, WithTip("Tip: C-h lists every key binding")).buildHints()
+	if !strings.HasSuffix(hints, " · Tip: C-h lists every key binding") {
+		t.Errorf("hints = %q, want the tip appended", hints)
+	}
+}
+
+func TestGroupHeadersLabelFirstItemOfEachRun(t *testing.T) {
+	items := []Item{
+		{Name: "api", Path: "/work/acme/api", Group: "/work/acme"},
+		{Name: "web", Path: "/work/acme/web", Group: "/work/acme"},
+		{Name: "pop", Path: "/oss/pop", Group: "/oss"},
+	}
+	picker := NewPicker(items, WithGroupHeaders())
+
+	if !picker.groupHeads["/work/acme/api"] || picker.groupHeads["/work/acme/web"] || !picker.groupHeads["/oss/pop"] {
+		t.Errorf("groupHeads = %v, want the first item of each run", picker.groupHeads)
+	}
+	if cell := picker.pickerCell(items[0], RowState{}); !strings.Contains(cell, "/work/acme") {
+		t.Errorf("head cell %q should carry the group label", cell)
+	}
+	if cell := picker.pickerCell(items[1], RowState{}); strings.Contains(cell, "/work/acme") {
+		t.Errorf("non-head cell %q should not repeat the group label", cell)
+	}
+}
+
+func filteredPaths(p *Picker) []string {
+	paths := make([]string, len(p.filtered))
+	for i, item := range p.filtered {
+		paths[i] = item.Path
+	}
+	return paths
+}
+
+func TestSessionFilterCyclesStates(t *testing.T) {
+	items := []Item{
+		{Name: "api", Path: "/api", HasSession: true},
+		{Name: "web", Path: "/web"},
+		{Name: "pop", Path: "/pop", HasSession: true},
+	}
+	picker := NewPicker(items, WithSessionFilter(), WithCursorAtEnd())
+	picker.Init()
+	ctrlT := tea.KeyPressMsg{Code: 't', Mod: tea.ModCtrl}
+
+	steps := []struct {
+		paths []string
+		hint  string
+	}{
+		{[]string{"/api", "/pop"}, "showing: with session"},
+		{[]string{"/web"}, "showing: without session"},
+		{[]string{"/api", "/web", "/pop"}, ""},
+	}
+	for i, step := range steps {
+		picker.Update(ctrlT)
+		if got := filteredPaths(picker); strings.Join(got, ",") != strings.Join(step.paths, ",") {
+			t.Errorf("step %d: filtered = %v, want %v", i, got, step.paths)
+		}
+		hints := picker.buildHints()
+		if step.hint != "" && !strings.Contains(hints, step.hint) {
+			t.Errorf("step %d: hints = %q, want %q", i, hints, step.hint)
+		}
+		if step.hint == "" && strings.Contains(hints, "showing:") {
+			t.Errorf("step %d: hints = %q, want no filter indicator", i, hints)
+		}
+	}
+}
+
+func TestSessionFilterComposesWithQuery(t *testing.T) {
+	items := []Item{
+		{Name: "api", Path: "/api", HasSession: true},
+		{Name: "api-docs", Path: "/api-docs"},
+		{Name: "web", Path: "/web", HasSession: true},
+	}
+	picker := NewPicker(items, WithSessionFilter())
+	picker.Init()
+	picker.Update(tea.KeyPressMsg{Code: 't', Mod: tea.ModCtrl})
+	typeInPicker(picker, "api")
+
+	if got := filteredPaths(picker); len(got) != 1 || got[0] != "/api" {
+		t.Errorf("filtered = %v, want [/api]", got)
+	}
+}
+
+func TestBranchFilterToggle(t *testing.T) {
+	items := []Item{
+		{Name: "main", Path: "/main", Context: "main"},
+		{Name: "login", Path: "/login", Context: "feature/login"},
+		{Name: "release", Path: "/release", Context: "release/1.0"},
+	}
+	keep := func(item Item) bool { return strings.HasPrefix(item.Context, "feature/") }
+	picker := NewPicker(items, WithBranchFilter(keep, true))
+	picker.Init()
+	ctrlV := tea.KeyPressMsg{Code: 'v', Mod: tea.ModCtrl}
+
+	if got := filteredPaths(picker); strings.Join(got, ",") != "/login" {
+		t.Errorf("filter on: filtered = %v, want [/login]", got)
+	}
+	if !strings.Contains(picker.buildHints(), "branch filter on") {
+		t.Errorf("hints = %q, want the branch filter indicator", picker.buildHints())
+	}
+
+	picker.Update(ctrlV)
+	if got := filteredPaths(picker); len(got) != 3 {
+		t.Errorf("filter off: filtered = %v, want all items", got)
+	}
+	if picker.Result().BranchFilter {
+		t.Error("Result().BranchFilter should report the filter off after toggling")
+	}
+
+	plain := NewPicker(items)
+	plain.Init()
+	plain.Update(ctrlV)
+	if got := filteredPaths(plain); len(got) != 3 || plain.Result().BranchFilter {
+		t.Errorf("ctrl+v without WithBranchFilter should not filter, got %v", got)
+	}
+}
+
+func TestSortModesCycleKeepsQueryAndHighlight(t *testing.T) {
+	recent := []Item{
+		{Name: "api-old", Path: "/api-old"},
+		{Name: "api-new", Path: "/api-new"},
+		{Name: "web", Path: "/web"},
+	}
+	activity := []Item{recent[1], recent[2], recent[0]}
+	picker := NewPicker(recent, WithCursorAtEnd(), WithSortModes("recent",
+		SortMode{Name: "recent", Items: recent},
+		SortMode{Name: "branch activity", Items: activity},
+	))
+	picker.Init()
+	typeInPicker(picker, "api")
+	picker.list.SetCursorToKey("/api-new")
+	picker.syncFromList()
+
+	picker.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+
+	if got := filteredPaths(picker); len(got) != 2 || got[0] != "/api-new" || got[1] != "/api-old" {
+		t.Errorf("filtered = %v, want the query kept in branch activity order", got)
+	}
+	if item, _ := picker.selectedItem(); item == nil || item.Path != "/api-new" {
+		t.Errorf("selected = %v, want /api-new still highlighted", item)
+	}
+	if hints := picker.buildHints(); !strings.Contains(hints, "sort: branch activity") {
+		t.Errorf("hints = %q, want the active sort mode", hints)
+	}
+	if got := picker.Result().SortMode; got != "branch activity" {
+		t.Errorf("Result().SortMode = %q, want branch activity", got)
+	}
+
+	picker.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+	if got := picker.Result().SortMode; got != "recent" {
+		t.Errorf("after a second C-s SortMode = %q, want recent", got)
+	}
+}
+
+func TestSortModesStartInActiveMode(t *testing.T) {
+	a := Item{Name: "a", Path: "/a"}
+	b := Item{Name: "b", Path: "/b"}
+	picker := NewPicker([]Item{a, b}, WithSortModes("activity",
+		SortMode{Name: "recent", Items: []Item{a, b}},
+		SortMode{Name: "activity", Items: []Item{b, a}},
+	))
+	picker.Init()
+
+	if got := filteredPaths(picker); len(got) != 2 || got[0] != "/b" {
+		t.Errorf("filtered = %v, want the activity order", got)
+	}
+}
+
+func TestItemStreamReplacesItems(t *testing.T) {
+	updates := make(chan []Item, 1)
+	picker := NewPicker(nil, WithCursorAtEnd(), WithItemStream(updates))
+	picker.Init()
+	if hints := picker.buildHints(); !strings.Contains(hints, "loading…") {
+		t.Errorf("hints = %q, want a loading indicator while streaming", hints)
+	}
+
+	receive := func(items []Item) {
+		t.Helper()
+		updates <- items
+		picker.Update(picker.waitForItems()())
+	}
+	api := Item{Name: "api", Path: "/api"}
+	web := Item{Name: "web", Path: "/web"}
+	docs := Item{Name: "docs", Path: "/docs"}
+
+	receive([]Item{api, web})
+	if item, _ := picker.selectedItem(); item == nil || item.Path != "/web" {
+		t.Errorf("selected = %v, want the last item", item)
+	}
+
+	// A highlight on the last item follows the new last item.
+	receive([]Item{web, api, docs})
+	if item, _ := picker.selectedItem(); item == nil || item.Path != "/docs" {
+		t.Errorf("selected = %v, want /docs, the new last item", item)
+	}
+
+	// A highlight moved elsewhere stays on its item.
+	picker.list.SetCursorToKey("/web")
+	picker.syncFromList()
+	receive([]Item{web, api, docs, {Name: "admin", Path: "/admin"}})
+	if item, _ := picker.selectedItem(); item == nil || item.Path != "/web" {
+		t.Errorf("selected = %v, want /web still highlighted", item)
+	}
+
+	// The query applies to every new snapshot.
+	typeInPicker(picker, "docs")
+	receive([]Item{web, api, docs, {Name: "admin", Path: "/admin"}, {Name: "blog", Path: "/blog"}})
+	if got := filteredPaths(picker); len(got) != 1 || got[0] != "/docs" {
+		t.Errorf("filtered = %v, want only /docs", got)
+	}
+
+	close(updates)
+	picker.Update(picker.waitForItems()())
+	if hints := picker.buildHints(); strings.Contains(hints, "loading…") {
+		t.Errorf("hints = %q, want no loading indicator once the stream closed", hints)
+	}
+	if picker.waitForItems() != nil {
+		t.Error("waitForItems after the stream closed should return nil")
+	}
+}
+
+func TestSessionFilterDisabledByDefault(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api", HasSession: true}, {Name: "web", Path: "/web"}}
+	picker := NewPicker(items)
+	picker.Init()
+	picker.Update(tea.KeyPressMsg{Code: 't', Mod: tea.ModCtrl})
+
+	if len(picker.filtered) != 2 {
+		t.Errorf("filtered = %v, want both items without WithSessionFilter", filteredPaths(picker))
+	}
+}
+
+func TestPickerRendersAdversarialNamesSafely(t *testing.T) {
+	items := []Item{
+		{Name: "clear\x1b[2J\x1b[Hme", Path: "/dev/clear\x1b[2J\x1b[Hme", Context: "br\tanch"},
+		{Name: "bad\xffutf8", Path: "/dev/bad\xffutf8", Group: "/dev/gr\noup"},
+		{Name: "two\nlines", Path: "/dev/two\nlines", Tags: []string{"t\x07ag"}},
+	}
+	picker := NewPicker(items, WithContext(), WithGroupHeaders())
+	picker.width = 80
+	picker.height = 20
+	picker.Init()
+
+	view := picker.View().Content
+	for _, bad := range []string{"\x1b[2J", "\x1b[H", "\xff", "\t", "\x07", "two\nlines", "gr\noup"} {
+		if strings.Contains(view, bad) {
+			t.Errorf("view contains %q:\n%s", bad, view)
+		}
+	}
+	for _, want := range []string{"clearme", "[br�anch]", "bad�utf8", "two�lines", "t�ag", "gr�oup"} {
+		if !containsSubstring(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	// Actions still get the raw path.
+	picker.cursor = 1
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if got := picker.Result().Selected; got == nil || got.Path != "/dev/bad\xffutf8" {
+		t.Errorf("Selected = %+v, want the unsanitized path", got)
+	}
+}
+
+func TestWithMaxHeightCapsListRows(t *testing.T) {
+	var items []Item
+	for i := 0; i < 30; i++ {
+		items = append(items, Item{Name: fmt.Sprintf("item%02d", i), Path: fmt.Sprintf("/item%02d", i)})
+	}
+	for _, tt := range []struct {
+		opts []PickerOption
+		want int
+	}{
+		{opts: nil, want: 40 - 4},
+		{opts: []PickerOption{WithMaxHeight(5)}, want: 5},
+		{opts: []PickerOption{WithMaxHeight(100)}, want: 40 - 4},
+	} {
+		picker := NewPicker(items, tt.opts...)
+		picker.Init()
+		picker.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+		if picker.height != tt.want {
+			t.Errorf("height = %d, want %d", picker.height, tt.want)
+		}
+	}
+}
+
+func TestPickerResizeSequenceKeepsCursorVisible(t *testing.T) {
+	var items []Item
+	for i := 0; i < 100; i++ {
+		items = append(items, Item{Name: fmt.Sprintf("item%02d", i), Path: fmt.Sprintf("/item%02d", i)})
+	}
+	for _, tt := range []struct {
+		name string
+		opts []PickerOption
+	}{
+		{name: "plain"},
+		{name: "quick access", opts: []PickerOption{WithQuickAccess("alt")}},
+		{name: "max height", opts: []PickerOption{WithMaxHeight(12)}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			picker := NewPicker(items, tt.opts...)
+			picker.Init()
+			picker.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+			picker.list.SetCursor(60)
+			picker.syncFromList()
+
+			for _, h := range []int{40, 8, 5, 1, 60, 12, 40} {
+				picker.Update(tea.WindowSizeMsg{Width: 80, Height: h})
+				if picker.cursor != 60 {
+					t.Fatalf("after resize to %d: cursor = %d, want 60", h, picker.cursor)
+				}
+				if picker.cursor < picker.scroll || picker.cursor >= picker.scroll+picker.height {
+					t.Fatalf("after resize to %d: cursor %d outside rows %d..%d", h, picker.cursor, picker.scroll, picker.scroll+picker.height-1)
+				}
+				if view := picker.View().Content; !strings.Contains(view, "item60") {
+					t.Fatalf("after resize to %d: view does not show the cursor row:\n%s", h, view)
+				}
+			}
+		})
+	}
+}
+
+func TestPickerGrowsListWhenNotificationExpires(t *testing.T) {
+	now := time.Now()
+	saved := notifications
+	notifications = NewNotifications(func() time.Time { return now })
+	t.Cleanup(func() { notifications = saved })
+
+	var items []Item
+	for i := 0; i < 30; i++ {
+		items = append(items, Item{Name: fmt.Sprintf("item%02d", i), Path: fmt.Sprintf("/item%02d", i)})
+	}
+	Notify(LevelInfo, "Killed session: api")
+	picker := NewPicker(items)
+	picker.Init()
+	picker.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	withNotification := picker.height
+
+	now = now.Add(infoLifetime)
+	picker.Update(notificationTickMsg{})
+	if picker.height != withNotification+1 {
+		t.Errorf("height after the notification expired = %d, want %d", picker.height, withNotification+1)
+	}
+}
+
+func TestWithoutIconsHidesIconsAndLegend(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api", Icon: "■"}}
+	picker := NewPicker(items, WithoutIcons(), WithIconLegend(IconLegend{Icon: "■", Desc: "Session"}))
+	picker.width = 60
+	picker.height = 20
+	picker.Init()
+
+	if cell := picker.pickerCell(items[0], RowState{}); strings.Contains(cell, "■") {
+		t.Errorf("cell = %q, want no icon", cell)
+	}
+	for _, e := range picker.helpEntries() {
+		if e.Key == "■" {
+			t.Error("help lists the legend of a hidden icon")
+		}
+	}
+}
+
+func TestWithKeyBindingsRemapsBuiltinActions(t *testing.T) {
+	items := []Item{{Name: "test", Path: "/test"}}
+	bindings := map[string]string{"kill_session": "ctrl-q", "help": "ctrl+g", "bogus": "ctrl+z"}
+
+	picker := NewPicker(items, WithKillSession(), WithKeyBindings(bindings))
+	picker.Init()
+	if _, cmd := picker.Update(tea.KeyPressMsg{Code: 'k', Mod: tea.ModCtrl}); cmd != nil {
+		t.Fatal("ctrl+k still kills the session after remapping")
+	}
+	if _, cmd := picker.Update(tea.KeyPressMsg{Code: 'q', Mod: tea.ModCtrl}); cmd == nil {
+		t.Fatal("expected ctrl+q to quit with kill session")
+	}
+	if got := picker.Result().Action; got != ActionKillSession {
+		t.Errorf("Action = %v, want ActionKillSession", got)
+	}
+
+	picker = NewPicker(items, WithKillSession(), WithKeyBindings(bindings))
+	picker.Init()
+	picker.Update(tea.KeyPressMsg{Code: 'h', Mod: tea.ModCtrl})
+	if picker.showHelp {
+		t.Error("ctrl+h still opens help after remapping")
+	}
+	picker.Update(tea.KeyPressMsg{Code: 'g', Mod: tea.ModCtrl})
+	if !picker.showHelp {
+		t.Fatal("ctrl+g should open help")
+	}
+
+	entries := HelpEntries(WithKillSession(), WithKeyBindings(bindings))
+	if !slices.Contains(entries, HelpEntry{"C-q", "Kill tmux session"}) {
+		t.Errorf("help entries = %v, want C-q for kill session", entries)
+	}
+	if got := HelpToggleKey(WithKeyBindings(bindings)); got != "C-g" {
+		t.Errorf("HelpToggleKey = %q, want C-g", got)
+	}
+	if !strings.Contains(picker.buildHints(), "C-g help") {
+		t.Errorf("hints = %q, want the remapped help key", picker.buildHints())
+	}
+}
+
+func TestWithKeyBindingsLeavesOtherPickersAlone(t *testing.T) {
+	NewPicker(nil, WithKeyBindings(map[string]string{"reset": "ctrl+q"}))
+	picker := NewPicker([]Item{{Name: "test", Path: "/test"}}, WithReset())
+	picker.Init()
+	if _, cmd := picker.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl}); cmd == nil {
+		t.Fatal("ctrl+r should still reset in a picker without remaps")
+	}
+	if got := picker.Result().Action; got != ActionReset {
+		t.Errorf("Action = %v, want ActionReset", got)
+	}
+}
+
+func TestTypeIconColumnAlignsNames(t *testing.T) {
+	items := []Item{
+		{Name: "api", Path: "/api", TypeIcon: "go"},
+		{Name: "web", Path: "/web", TypeIcon: "php"},
+		{Name: "notes", Path: "/notes"},
+	}
+	picker := NewPicker(items)
+	picker.Init()
+
+	var offsets []int
+	for _, item := range items {
+		cell := picker.pickerCell(item, RowState{})
+		offsets = append(offsets, strings.Index(cell, item.Name))
+	}
+	if offsets[0] != offsets[1] || offsets[1] != offsets[2] {
+		t.Errorf("name offsets = %v, want one column", offsets)
+	}
+	if cell := picker.pickerCell(items[1], RowState{}); !strings.Contains(cell, "php") {
+		t.Errorf("cell = %q, want the type icon", cell)
+	}
+
+	hidden := NewPicker(items, WithoutIcons())
+	hidden.Init()
+	if cell := hidden.pickerCell(items[0], RowState{}); strings.Contains(cell, "go") {
+		t.Errorf("cell = %q, want no type icon under WithoutIcons", cell)
+	}
+}
+
+func TestActionString(t *testing.T) {
+	for a := ActionConfirm; a <= ActionRename; a++ {
+		if strings.HasPrefix(a.String(), "action(") {
+			t.Errorf("Action %d has no name", int(a))
+		}
+	}
+	if ActionKillSession.String() != "kill_session" || Action(99).String() != "action(99)" {
+		t.Errorf("String() = %q, %q", ActionKillSession.String(), Action(99).String())
+	}
+}
+
+func TestUserDefinedCommandHint(t *testing.T) {
+	commands := []UserDefinedCommand{
+		{Key: "ctrl+k", Label: "kube", Hint: "⌘K", Command: "k9s"},
+		{Key: "ctrl+l", Label: "logs", Command: "tail -f app.log", HintBar: true},
+		{Key: "ctrl+g", Label: "grep", Hint: "⌘G", Command: "rg", HintBar: true},
+	}
+	picker := NewPicker([]Item{{Name: "test", Path: "/test"}}, WithUserDefinedCommands(commands))
+
+	hints := picker.buildHints()
+	if strings.Contains(hints, "kube") {
+		t.Errorf("a hint alone should not list the command in the hint bar, got: %q", hints)
+	}
+	if !strings.Contains(hints, "C-l logs") || !strings.Contains(hints, "⌘G grep") {
+		t.Errorf("hint_bar commands should be listed under their key hint, got: %q", hints)
+	}
+
+	help := map[string]string{}
+	for _, e := range picker.helpEntries() {
+		help[e.Desc] = e.Key
+	}
+	if help["kube"] != "⌘K" || help["logs"] != "C-l" {
+		t.Errorf("help keys = kube:%q logs:%q, want ⌘K and C-l", help["kube"], help["logs"])
+	}
+}
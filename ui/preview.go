@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// PreviewFunc renders the preview text for an item. It runs off the UI
+// goroutine as a tea.Cmd, so it may block on a subprocess; the picker stays
+// responsive and fills the pane in when the result arrives.
+type PreviewFunc func(item Item) string
+
+// previewMaxLines caps how much of a preview is kept in the cache. The pane
+// never shows more than the body height, so anything past this is dead weight
+// (a `git log` preview can easily run to thousands of lines).
+const previewMaxLines = 200
+
+// previewMinWidth is the narrowest terminal that still gets a preview pane;
+// below it the list keeps the full width so names stay readable.
+const previewMinWidth = 60
+
+// previewMsg delivers a finished preview back to the picker, keyed by the
+// item's Path (the picker's stable identity).
+type previewMsg struct {
+	key     string
+	content string
+}
+
+// previewPane owns the asynchronous preview state: one cached result per item
+// key, plus the set of keys with a request in flight so moving the cursor back
+// and forth never runs the same command twice.
+type previewPane struct {
+	fn      PreviewFunc
+	cache   map[string]string
+	pending map[string]bool
+}
+
+func newPreviewPane(fn PreviewFunc) *previewPane {
+	return &previewPane{
+		fn:      fn,
+		cache:   make(map[string]string),
+		pending: make(map[string]bool),
+	}
+}
+
+// request returns a command computing the preview for item, or nil when the
+// result is already cached or being computed.
+func (pp *previewPane) request(item Item) tea.Cmd {
+	key := item.Path
+	if _, ok := pp.cache[key]; ok || pp.pending[key] {
+		return nil
+	}
+	pp.pending[key] = true
+	fn := pp.fn
+	return func() tea.Msg {
+		return previewMsg{key: key, content: fn(item)}
+	}
+}
+
+// store records a finished preview, normalized and truncated for rendering.
+func (pp *previewPane) store(msg previewMsg) {
+	delete(pp.pending, msg.key)
+	pp.cache[msg.key] = normalizePreview(msg.content)
+}
+
+// lines returns the cached preview lines for key and whether one is cached.
+func (pp *previewPane) lines(key string) ([]string, bool) {
+	content, ok := pp.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if content == "" {
+		return nil, true
+	}
+	return strings.Split(content, "\n"), true
+}
+
+// normalizePreview makes command output safe to lay out in a fixed-width
+// column: carriage returns are dropped, tabs expanded, trailing blank lines
+// trimmed, and the line count capped at previewMaxLines.
+func normalizePreview(s string) string {
+	s = strings.ReplaceAll(s, "\r", "")
+	s = strings.ReplaceAll(s, "\t", "    ")
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > previewMaxLines {
+		lines = lines[:previewMaxLines]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinPreviewColumns lays list rows and preview lines side by side: the list
+// is truncated/padded to leftWidth, then a separator, then the preview line
+// truncated to rightWidth. A reset follows each cell so colours from preview
+// output (bat, eza, git) never bleed into the next row.
+func joinPreviewColumns(rows, preview []string, leftWidth, rightWidth int) []string {
+	sepStyle := lipgloss.NewStyle().Foreground(colorSeparator)
+	out := make([]string, len(rows))
+	for i, row := range rows {
+		left := truncateString(row, leftWidth)
+		if pad := leftWidth - lipgloss.Width(left); pad > 0 {
+			left += strings.Repeat(" ", pad)
+		}
+		right := ""
+		if i < len(preview) {
+			right = truncateString(preview[i], rightWidth)
+		}
+		out[i] = left + "\x1b[0m" + sepStyle.Render("│") + " " + right + "\x1b[0m"
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestPreviewRequestsHighlightedItemOnce(t *testing.T) {
+	items := []Item{
+		{Name: "a", Path: "/a"},
+		{Name: "b", Path: "/b"},
+	}
+	calls := map[string]int{}
+	picker := NewPicker(items, WithPreview(func(item Item) string {
+		calls[item.Path]++
+		return "preview of " + item.Name
+	}))
+
+	cmd := picker.Init()
+	if cmd == nil {
+		t.Fatal("Init should request the preview for the highlighted item")
+	}
+	picker.Update(cmd())
+	if calls["/a"] != 1 {
+		t.Fatalf("highlighted item /a previewed %d times, want 1", calls["/a"])
+	}
+
+	// Moving away and back must reuse the cached result.
+	_, cmd = picker.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if cmd == nil {
+		t.Fatal("moving to an uncached item should request its preview")
+	}
+	picker.Update(cmd())
+	_, cmd = picker.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	if cmd != nil {
+		t.Error("moving back to a cached item should not request a preview")
+	}
+	if calls["/a"] != 1 || calls["/b"] != 1 {
+		t.Errorf("calls = %v, want one per item", calls)
+	}
+}
+
+func TestPreviewPendingRequestNotDuplicated(t *testing.T) {
+	pp := newPreviewPane(func(item Item) string { return "x" })
+	item := Item{Name: "a", Path: "/a"}
+	if pp.request(item) == nil {
+		t.Fatal("first request should return a command")
+	}
+	if pp.request(item) != nil {
+		t.Error("request while pending should return nil")
+	}
+}
+
+func TestWithPreviewNilDisablesPane(t *testing.T) {
+	picker := NewPicker([]Item{{Name: "a", Path: "/a"}}, WithPreview(nil))
+	if picker.preview != nil {
+		t.Error("WithPreview(nil) should leave the pane disabled")
+	}
+	if picker.Init() != nil {
+		t.Error("Init without a preview should return nil")
+	}
+}
+
+func TestPreviewRendersBesideList(t *testing.T) {
+	items := []Item{{Name: "alpha", Path: "/alpha"}}
+	picker := NewPicker(items, WithPreview(func(item Item) string {
+		return "README.md\nmain.go"
+	}))
+	cmd := picker.Init()
+	picker.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+
+	if !strings.Contains(picker.viewProject(), "loading preview") {
+		t.Error("uncached preview should render a loading placeholder")
+	}
+	picker.Update(cmd())
+	view := picker.viewProject()
+	for _, want := range []string{"alpha", "README.md", "main.go"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestPreviewHiddenOnNarrowTerminal(t *testing.T) {
+	items := []Item{{Name: "alpha", Path: "/alpha"}}
+	picker := NewPicker(items, WithPreview(func(item Item) string { return "README.md" }))
+	picker.Update(picker.Init()())
+	picker.Update(tea.WindowSizeMsg{Width: previewMinWidth - 1, Height: 20})
+
+	if strings.Contains(picker.viewProject(), "README.md") {
+		t.Error("preview should be hidden below previewMinWidth")
+	}
+}
+
+func TestNormalizePreview(t *testing.T) {
+	got := normalizePreview("a\tb\r\nc\n\n\n")
+	if got != "a    b\nc" {
+		t.Errorf("normalizePreview = %q, want %q", got, "a    b\nc")
+	}
+
+	long := strings.Repeat("line\n", previewMaxLines+50)
+	if n := len(strings.Split(normalizePreview(long), "\n")); n != previewMaxLines {
+		t.Errorf("normalizePreview kept %d lines, want %d", n, previewMaxLines)
+	}
+}
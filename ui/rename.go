@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+)
+
+// RenameFunc returns the current name of what renaming item would rename,
+// such as its tmux session, and false when item cannot be renamed.
+type RenameFunc func(item Item) (current string, ok bool)
+
+// renameState is the picker's inline rename in progress: the item being
+// renamed, its current name, and the field holding the new one.
+type renameState struct {
+	item    Item
+	current string
+	field   TextField
+}
+
+// WithRename enables the rename keybinding (F2) for the items fn accepts.
+// The query box turns into a field holding the current name; Enter returns
+// ActionRename with the edited name in Result.NewName, Esc goes back to
+// filtering.
+func WithRename(fn RenameFunc) PickerOption {
+	return func(p *Picker) {
+		p.renameFn = fn
+	}
+}
+
+// startRename switches the highlighted item into inline rename, if it has a
+// name to edit.
+func (p *Picker) startRename() {
+	if p.renameFn == nil {
+		return
+	}
+	item, ok := p.selectedItem()
+	if !ok {
+		return
+	}
+	current, ok := p.renameFn(*item)
+	if !ok {
+		return
+	}
+	field := NewTextField()
+	field.styles = p.styles
+	field.SetValue(current)
+	field.SetCursor(len([]rune(current)))
+	p.renaming = &renameState{item: *item, current: current, field: field}
+}
+
+// updateRename handles a key while renaming. An unchanged or empty name
+// cancels like Esc.
+func (p *Picker) updateRename(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, p.keys.Quit):
+		p.renaming = nil
+		return p, nil
+
+	case key.Matches(msg, p.keys.Enter):
+		r := p.renaming
+		p.renaming = nil
+		name := strings.TrimSpace(r.field.Value())
+		if name == "" || name == r.current {
+			return p, nil
+		}
+		p.result = Result{Selected: &r.item, Action: ActionRename, NewName: name}
+		return p, tea.Quit
+	}
+	p.renaming.field.Update(msg)
+	return p, nil
+}
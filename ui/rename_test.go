@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// renameSessions renames items whose path starts with "tmux:".
+func renameSessions(item Item) (string, bool) {
+	name, ok := strings.CutPrefix(item.Path, "tmux:")
+	return name, ok
+}
+
+func TestRenameReturnsNewName(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api"}, {Name: "scratch", Path: "tmux:scratch"}}
+	picker := NewPicker(items, WithRename(renameSessions), WithCursorAtEnd())
+	picker.Init()
+
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyF2})
+	if picker.renaming == nil || picker.renaming.field.Value() != "scratch" {
+		t.Fatalf("F2 should start renaming with the current name, got %+v", picker.renaming)
+	}
+	typeInPicker(picker, "-2")
+	if picker.input.Value() != "" {
+		t.Errorf("typing while renaming changed the query to %q", picker.input.Value())
+	}
+	_, cmd := picker.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	if picker.result.Action != ActionRename || picker.result.NewName != "scratch-2" {
+		t.Errorf("result = %+v, want ActionRename to scratch-2", picker.result)
+	}
+	if picker.result.Selected == nil || picker.result.Selected.Path != "tmux:scratch" {
+		t.Errorf("result should carry the renamed item, got %+v", picker.result.Selected)
+	}
+	if cmd == nil {
+		t.Error("Enter while renaming should return tea.Quit cmd")
+	}
+}
+
+func TestRenameEscGoesBackToFiltering(t *testing.T) {
+	items := []Item{{Name: "scratch", Path: "tmux:scratch"}}
+	picker := NewPicker(items, WithRename(renameSessions))
+	picker.Init()
+
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyF2})
+	_, cmd := picker.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if picker.renaming != nil || cmd != nil {
+		t.Error("Esc while renaming should end the rename and keep the picker open")
+	}
+
+	// Enter with the name unchanged cancels the same way.
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyF2})
+	_, cmd = picker.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if picker.renaming != nil || cmd != nil || picker.result.Action == ActionRename {
+		t.Error("Enter with an unchanged name should end the rename without a result")
+	}
+}
+
+func TestRenameSkipsItemsFnRejects(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api"}}
+
+	picker := NewPicker(items)
+	picker.Init()
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyF2})
+	if picker.renaming != nil {
+		t.Error("F2 should do nothing without WithRename")
+	}
+
+	picker = NewPicker(items, WithRename(renameSessions))
+	picker.Init()
+	picker.Update(tea.KeyPressMsg{Code: tea.KeyF2})
+	if picker.renaming != nil {
+		t.Error("F2 should do nothing on an item the rename func rejects")
+	}
+}
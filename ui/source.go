@@ -0,0 +1,80 @@
+package ui
+
+import "iter"
+
+// sourceStreamChunk is how many items streamSource reads between snapshots.
+const sourceStreamChunk = 256
+
+// ItemSource supplies a picker's items by index, so a backend can compute
+// them lazily or fetch them on demand instead of handing the picker a
+// ready-made slice. The picker opens at once and reads the source in the
+// background, showing the items as they arrive, like WithItemStream; At is
+// called once per item.
+type ItemSource interface {
+	Len() int
+	At(i int) Item
+}
+
+// FilteringSource is an ItemSource that matches queries itself, e.g. on a
+// remote host. Filter returns the items matching query ordered as the picker
+// shows them, best match last. The picker then only applies its session and
+// #tag filters to the result; an empty query still lists the items read from
+// the source so far.
+type FilteringSource interface {
+	ItemSource
+	Filter(query string) []Item
+}
+
+// SliceSource is the ItemSource of a plain item slice.
+type SliceSource []Item
+
+func (s SliceSource) Len() int      { return len(s) }
+func (s SliceSource) At(i int) Item { return s[i] }
+
+// sourceItems yields every item of src in order.
+func sourceItems(src ItemSource) iter.Seq[Item] {
+	return func(yield func(Item) bool) {
+		for i := 0; i < src.Len(); i++ {
+			if !yield(src.At(i)) {
+				return
+			}
+		}
+	}
+}
+
+// streamSource reads src in the background, sending a snapshot of the items
+// read so far after every sourceStreamChunk of them and at the end, then
+// closes the channel. It never blocks: a snapshot the picker hasn't read yet
+// is replaced by the next.
+func streamSource(src ItemSource) <-chan []Item {
+	updates := make(chan []Item, 1)
+	go func() {
+		defer close(updates)
+		n := src.Len()
+		items := make([]Item, 0, n)
+		for i := 0; i < n; i++ {
+			items = append(items, src.At(i))
+			if len(items)%sourceStreamChunk != 0 && i < n-1 {
+				continue
+			}
+			select {
+			case <-updates:
+			default:
+			}
+			updates <- items[:len(items):len(items)]
+		}
+	}()
+	return updates
+}
+
+// collectItems reads all of src into a slice; a SliceSource is returned as is.
+func collectItems(src ItemSource) []Item {
+	if s, ok := src.(SliceSource); ok {
+		return s
+	}
+	items := make([]Item, 0, src.Len())
+	for item := range sourceItems(src) {
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// generatedSource computes its items on demand.
+type generatedSource struct {
+	names []string
+	reads int
+}
+
+func (s *generatedSource) Len() int { return len(s.names) }
+
+func (s *generatedSource) At(i int) Item {
+	s.reads++
+	return Item{Name: s.names[i], Path: "/" + s.names[i], HasSession: i%2 == 0}
+}
+
+// remoteSource matches queries itself, recording what it was asked.
+type remoteSource struct {
+	generatedSource
+	queries []string
+}
+
+func (s *remoteSource) Filter(query string) []Item {
+	s.queries = append(s.queries, query)
+	var matched []Item
+	for i, name := range s.names {
+		if strings.HasPrefix(name, query) {
+			matched = append(matched, s.At(i))
+		}
+	}
+	return matched
+}
+
+// loadSource feeds the picker every snapshot its source stream sends.
+func loadSource(picker *Picker) {
+	for picker.itemStream != nil {
+		picker.Update(picker.waitForItems()())
+	}
+}
+
+// blockingSource hands out its items only once release is closed.
+type blockingSource struct {
+	release chan struct{}
+}
+
+func (s *blockingSource) Len() int { return 2 }
+
+func (s *blockingSource) At(i int) Item {
+	<-s.release
+	return Item{Name: fmt.Sprint(i), Path: fmt.Sprint("/", i)}
+}
+
+func TestNewPickerFromSourceOpensBeforeReadingIt(t *testing.T) {
+	src := &blockingSource{release: make(chan struct{})}
+	picker := NewPickerFromSource(src)
+	picker.Init()
+	if len(picker.filtered) != 0 || !strings.Contains(picker.buildHints(), "loading…") {
+		t.Fatalf("filtered = %v, want an empty picker loading the source", picker.filtered)
+	}
+	close(src.release)
+	loadSource(picker)
+	if got := filteredPaths(picker); !slices.Equal(got, []string{"/0", "/1"}) {
+		t.Errorf("filtered = %v, want the source's items once read", got)
+	}
+}
+
+func TestNewPickerFromSource(t *testing.T) {
+	src := &generatedSource{names: []string{"api", "web", "worker"}}
+	picker := NewPickerFromSource(src)
+	picker.Init()
+	loadSource(picker)
+
+	if got := filteredPaths(picker); !slices.Equal(got, []string{"/api", "/web", "/worker"}) {
+		t.Errorf("filtered = %v, want every source item", got)
+	}
+	typeInPicker(picker, "wor")
+	if got := filteredPaths(picker); !slices.Equal(got, []string{"/worker"}) {
+		t.Errorf("filtered = %v, want the fuzzy match", got)
+	}
+	if src.reads == 0 {
+		t.Error("the picker never read the source")
+	}
+}
+
+func TestFilteringSourceMatchesQueries(t *testing.T) {
+	src := &remoteSource{generatedSource: generatedSource{names: []string{"api", "web", "worker", "wiki"}}}
+	picker := NewPickerFromSource(src, WithSessionFilter())
+	picker.Init()
+	loadSource(picker)
+
+	typeInPicker(picker, "w")
+	if got := filteredPaths(picker); !slices.Equal(got, []string{"/web", "/worker", "/wiki"}) {
+		t.Errorf("filtered = %v, want the source's matches in its order", got)
+	}
+	if len(src.queries) == 0 || src.queries[len(src.queries)-1] != "w" {
+		t.Errorf("queries = %v, want the source asked for \"w\"", src.queries)
+	}
+
+	// The session filter still applies on top of the source's matches.
+	picker.sessionFilter = sessionFilterWith
+	picker.filter()
+	if got := filteredPaths(picker); !slices.Equal(got, []string{"/worker"}) {
+		t.Errorf("filtered = %v, want only matches with a session", got)
+	}
+}
+
+func TestSliceSource(t *testing.T) {
+	items := []Item{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}}
+	src := SliceSource(items)
+	if src.Len() != 2 || src.At(1).Name != "b" {
+		t.Errorf("SliceSource = %d items, At(1) = %q", src.Len(), src.At(1).Name)
+	}
+	if got := collectItems(src); &got[0] != &items[0] {
+		t.Error("collectItems copied a SliceSource")
+	}
+}
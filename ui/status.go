@@ -0,0 +1,79 @@
+package ui
+
+import tea "charm.land/bubbletea/v2"
+
+// StatusFunc returns a short status shown after an item's name, such as its
+// git state, or "" for none. Like a PreviewFunc it runs off the UI goroutine,
+// so it may block on a subprocess.
+type StatusFunc func(item Item) string
+
+// statusWorkers caps how many StatusFuncs run at once, so a long list does
+// not start a process per item in one go.
+const statusWorkers = 8
+
+// statusMsg delivers an item's finished status, keyed by its Path.
+type statusMsg struct {
+	key    string
+	status string
+}
+
+// itemStatuses owns the asynchronous status state: one result per item key,
+// the keys already requested, and the worker slots bounding the requests in
+// flight.
+type itemStatuses struct {
+	fn        StatusFunc
+	results   map[string]string
+	requested map[string]bool
+	slots     chan struct{}
+}
+
+func newItemStatuses(fn StatusFunc) *itemStatuses {
+	return &itemStatuses{
+		fn:        fn,
+		results:   make(map[string]string),
+		requested: make(map[string]bool),
+		slots:     make(chan struct{}, statusWorkers),
+	}
+}
+
+// request returns a command computing the status of every item not asked
+// for yet, or nil when there is none.
+func (s *itemStatuses) request(items []Item) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, item := range items {
+		if s.requested[item.Path] {
+			continue
+		}
+		s.requested[item.Path] = true
+		fn, slots := s.fn, s.slots
+		cmds = append(cmds, func() tea.Msg {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			return statusMsg{key: item.Path, status: fn(item)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+func (s *itemStatuses) store(msg statusMsg) {
+	s.results[msg.key] = msg.status
+}
+
+// WithItemStatus shows fn's status after each item's name. Statuses are
+// computed in the background, a few at a time, and each row fills in as its
+// result arrives; items streamed in later (WithItemStream) get one too.
+func WithItemStatus(fn StatusFunc) PickerOption {
+	return func(p *Picker) {
+		if fn != nil {
+			p.statuses = newItemStatuses(fn)
+		}
+	}
+}
+
+// statusCmd requests the statuses of items that have none yet.
+func (p *Picker) statusCmd() tea.Cmd {
+	if p.statuses == nil {
+		return nil
+	}
+	return p.statuses.request(collectItems(p.source))
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// statusMsgs runs cmd, a batch of status requests, concurrently the way
+// Bubble Tea would and returns the messages it produced.
+func statusMsgs(t *testing.T, cmd tea.Cmd) []tea.Msg {
+	t.Helper()
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	msgs := make([]tea.Msg, len(batch))
+	var wg sync.WaitGroup
+	for i, c := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			msgs[i] = c()
+		}()
+	}
+	wg.Wait()
+	return msgs
+}
+
+func TestItemStatusFillsInRows(t *testing.T) {
+	items := []Item{{Name: "api", Path: "/api"}, {Name: "docs", Path: "/docs"}}
+	picker := NewPicker(items, WithItemStatus(func(item Item) string {
+		if item.Path == "/api" {
+			return "● ↑1"
+		}
+		return ""
+	}))
+
+	cmd := picker.statusCmd()
+	if cell := picker.pickerCell(items[0], RowState{}); strings.Contains(cell, "●") {
+		t.Errorf("cell before the status arrived = %q, want no status", cell)
+	}
+	for _, msg := range statusMsgs(t, cmd) {
+		picker.Update(msg)
+	}
+	if cell := StripANSI(picker.pickerCell(items[0], RowState{})); cell != " api ● ↑1" {
+		t.Errorf("cell = %q, want the status after the name", cell)
+	}
+	if cell := StripANSI(picker.pickerCell(items[1], RowState{})); cell != " docs" {
+		t.Errorf("cell = %q, want no status suffix", cell)
+	}
+	if picker.statusCmd() != nil {
+		t.Error("statuses requested again for items that already have one")
+	}
+}
+
+func TestItemStatusBoundsConcurrentCalls(t *testing.T) {
+	var items []Item
+	for _, name := range strings.Split("a b c d e f g h i j k l m n o p", " ") {
+		items = append(items, Item{Name: name, Path: "/" + name})
+	}
+	var running, peak atomic.Int32
+	picker := NewPicker(items, WithItemStatus(func(item Item) string {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return "●"
+	}))
+
+	msgs := statusMsgs(t, picker.statusCmd())
+	if len(msgs) != len(items) {
+		t.Fatalf("got %d status messages, want one per item", len(msgs))
+	}
+	if got := peak.Load(); got > statusWorkers {
+		t.Errorf("%d statuses computed at once, want at most %d", got, statusWorkers)
+	}
+}
+
+func TestItemStatusForStreamedItems(t *testing.T) {
+	updates := make(chan []Item, 1)
+	picker := NewPicker(nil, WithItemStream(updates), WithItemStatus(func(item Item) string { return "●" }))
+	picker.Init()
+
+	_, cmd := picker.Update(itemsMsg{items: []Item{{Name: "api", Path: "/api"}}})
+	if cmd == nil || !picker.statuses.requested["/api"] {
+		t.Error("streamed item's status not requested")
+	}
+}
+
+func TestWithItemStatusNilDisables(t *testing.T) {
+	picker := NewPicker([]Item{{Name: "a", Path: "/a"}}, WithItemStatus(nil))
+	if picker.statuses != nil || picker.statusCmd() != nil {
+		t.Error("WithItemStatus(nil) should leave statuses disabled")
+	}
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 )
 
 // promptGlyph is the house prompt glyph for single-line text entry (ADR-0081).
@@ -20,6 +21,7 @@ type TextField struct {
 	value   []rune // current edit buffer
 	cursor  int    // insertion index into value, 0..len(value)
 	focused bool
+	styles  *styles // nil: the defaults
 }
 
 // NewTextField returns a focused, empty text field with the house prompt glyph.
@@ -117,16 +119,18 @@ func (m *TextField) Update(msg tea.Msg) {
 // reverse-video block cursor marks the insertion point.
 func (m TextField) View() string {
 	buffer := string(m.value)
+	st := stylesOr(m.styles)
 	if m.focused {
-		buffer = renderInputWithCursor(m.value, m.cursor)
+		buffer = renderInputWithCursor(m.value, m.cursor, st.dim)
 	}
-	return indicatorStyle.Render(promptGlyph) + buffer
+	return st.indicator.Render(promptGlyph) + buffer
 }
 
 // renderInputWithCursor draws the buffer with a reverse-video block over the
-// rune at the cursor (or a trailing block when the cursor sits past the end).
-func renderInputWithCursor(value []rune, cursor int) string {
-	cursorStyle := dimStyle.Reverse(true)
+// rune at the cursor (or a trailing block when the cursor sits past the end),
+// in dim reversed.
+func renderInputWithCursor(value []rune, cursor int, dim lipgloss.Style) string {
+	cursorStyle := dim.Reverse(true)
 	if cursor >= len(value) {
 		return string(value) + cursorStyle.Render(" ")
 	}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"image/color"
+
+	"charm.land/lipgloss/v2"
+)
+
+// Theme overrides the TUI colors. Each field is a lipgloss color, an ANSI 256
+// index ("39") or a hex value ("#5f87ff"); an empty field keeps the default.
+type Theme struct {
+	SelectedBg string // background of the highlighted picker row
+	SelectedFg string // text of the highlighted picker row
+	Accent     string // cursor block, prompt, headers, marks and query matches
+	Hint       string // footer hints and other dimmed text
+	Context    string // the picker's context column (e.g. branches)
+}
+
+// styles are the colors a picker draws with: the package defaults with a
+// Theme's swapped in.
+type styles struct {
+	accent    color.Color
+	indicator lipgloss.Style // cursor block and prompt
+	header    lipgloss.Style
+	match     lipgloss.Style // the characters a query matched
+	hint      lipgloss.Style
+	dim       lipgloss.Style
+	// The highlighted row and the context column have no default: nil
+	// renders them as plain text.
+	selected *lipgloss.Style
+	context  *lipgloss.Style
+}
+
+// defaultStyles are the styles of a view no theme reaches.
+var defaultStyles = Theme{}.styles()
+
+// styles returns the default styles with t's colors in place.
+func (t Theme) styles() *styles {
+	s := &styles{
+		accent:    colorAccent,
+		indicator: indicatorStyle,
+		header:    headerStyle,
+		match:     matchStyle,
+		hint:      hintStyle,
+		dim:       dimStyle,
+	}
+	if t.Accent != "" {
+		s.accent = lipgloss.Color(t.Accent)
+		s.indicator = lipgloss.NewStyle().Foreground(s.accent)
+		s.header = lipgloss.NewStyle().Foreground(s.accent).Bold(true)
+		s.match = lipgloss.NewStyle().Foreground(s.accent).Bold(true)
+	}
+	if t.Hint != "" {
+		s.hint = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Hint))
+		s.dim = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Hint))
+	}
+	if t.SelectedBg != "" || t.SelectedFg != "" {
+		style := lipgloss.NewStyle()
+		if t.SelectedBg != "" {
+			style = style.Background(lipgloss.Color(t.SelectedBg))
+		}
+		if t.SelectedFg != "" {
+			style = style.Foreground(lipgloss.Color(t.SelectedFg))
+		}
+		s.selected = &style
+	}
+	if t.Context != "" {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(t.Context))
+		s.context = &style
+	}
+	return s
+}
+
+// stylesOr returns s, or the defaults when s is nil.
+func stylesOr(s *styles) *styles {
+	if s == nil {
+		return defaultStyles
+	}
+	return s
+}
+
+// WithTheme draws the picker in t's colors: its rows, frame, prompt, rename
+// field and help overlay.
+func WithTheme(t Theme) PickerOption {
+	return func(p *Picker) {
+		p.styles = t.styles()
+	}
+}
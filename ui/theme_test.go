@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithThemeRestylesPicker(t *testing.T) {
+	items := []Item{{Name: "main", Path: "/main", Context: "trunk"}, {Name: "dev", Path: "/dev", Context: "feat"}}
+	picker := NewPicker(items, WithContext(), WithTheme(Theme{SelectedBg: "236", SelectedFg: "231", Accent: "208", Context: "109"}))
+	picker.Init()
+
+	selected := picker.pickerCell(items[0], RowState{Selected: true})
+	if !strings.Contains(selected, "48;5;236") || !strings.Contains(selected, "38;5;231") {
+		t.Errorf("selected cell = %q, want the selected colors", selected)
+	}
+	other := picker.pickerCell(items[1], RowState{})
+	if strings.Contains(other, "48;5;236") {
+		t.Errorf("unselected cell = %q, want no selected background", other)
+	}
+	if !strings.Contains(other, "38;5;109") {
+		t.Errorf("unselected cell = %q, want the context color", other)
+	}
+	if got := picker.list.renderPrefix(true, "", 2); !strings.Contains(got, "38;5;208") {
+		t.Errorf("indicator = %q, want the accent color", got)
+	}
+	if got := picker.input.View(); !strings.Contains(got, "38;5;208") {
+		t.Errorf("prompt = %q, want the accent color", got)
+	}
+}
+
+func TestWithThemeStaysOnItsPicker(t *testing.T) {
+	items := []Item{{Name: "main", Path: "/main"}}
+	before := NewPicker(items).frameSpec().Render("")
+	NewPicker(items, WithTheme(Theme{Hint: "#00ff00", Accent: "1", SelectedBg: "1"}))
+
+	plain := NewPicker(items)
+	if got := plain.frameSpec().Render(""); got != before {
+		t.Errorf("an unthemed picker after a themed one renders %q, want %q", got, before)
+	}
+	if plain.styles.selected != nil || plain.styles.context != nil {
+		t.Error("an unthemed picker has the selected or context style set")
+	}
+}
+
+func TestEmptyThemeKeepsDefaults(t *testing.T) {
+	s := Theme{}.styles()
+	if s.indicator.Render("█") != indicatorStyle.Render("█") || s.hint.Render("x") != hintStyle.Render("x") || s.selected != nil {
+		t.Error("an empty theme changed the default styles")
+	}
+}
+
+func TestWithConfigureThemeRestylesConfigurePicker(t *testing.T) {
+	cp := NewConfigurePicker(func(string) []string { return nil }, WithConfigureTheme(Theme{Accent: "208"}))
+	if got := cp.View().Content; !strings.Contains(got, "38;5;208") {
+		t.Errorf("view = %q, want the accent color", got)
+	}
+	if got := NewConfigurePicker(func(string) []string { return nil }).View().Content; strings.Contains(got, "38;5;208") {
+		t.Errorf("unthemed view = %q, want the default colors", got)
+	}
+}
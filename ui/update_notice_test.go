@@ -6,7 +6,7 @@ import (
 )
 
 func TestRenderUpdateNotice_RightAlignedAndDimmed(t *testing.T) {
-	out := renderUpdateNotice(40, "update available: 2026.6.1")
+	out := renderUpdateNotice(40, "update available: 2026.6.1", dimStyle)
 	plain := StripANSI(out)
 
 	if !strings.HasSuffix(plain, "update available: 2026.6.1") {
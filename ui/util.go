@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	"charm.land/lipgloss/v2"
 	"github.com/junegunn/fzf/src/algo"
@@ -34,6 +35,8 @@ var (
 	hintStyle      = lipgloss.NewStyle().Foreground(colorDim)
 	dimStyle       = lipgloss.NewStyle().Foreground(colorDim)
 	headerStyle    = lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+	// matchStyle marks the characters a fuzzy query matched.
+	matchStyle = lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
 
 	// IndicatorStyle is the shared cursor-row block indicator; exported for cross-package use.
 	IndicatorStyle = indicatorStyle
@@ -81,22 +84,22 @@ func writeInputBox(b *strings.Builder, width int, content string) {
 }
 
 // renderUpdateNotice renders the dimmed Update notice anchored to the top-right
-// of a width-wide line. It is unobtrusive: dimmed and right-aligned, with the
-// text truncated if it would not fit. The caller reserves the line so the
-// notice never shifts surrounding content.
-func renderUpdateNotice(width int, text string) string {
+// of a width-wide line. It is unobtrusive: dimmed (in dim) and right-aligned,
+// with the text truncated if it would not fit. The caller reserves the line so
+// the notice never shifts surrounding content.
+func renderUpdateNotice(width int, text string, dim lipgloss.Style) string {
 	if text == "" {
 		return ""
 	}
 	if width <= 0 {
-		return dimStyle.Render(text)
+		return dim.Render(text)
 	}
 	text = truncateToWidth(text, width)
 	padding := width - len([]rune(text))
 	if padding < 0 {
 		padding = 0
 	}
-	return strings.Repeat(" ", padding) + dimStyle.Render(text)
+	return strings.Repeat(" ", padding) + dim.Render(text)
 }
 
 // TruncateToWidth trims s to at most width runes (plain text, no ANSI).
@@ -195,7 +198,8 @@ type fuzzyStringMatch struct {
 // by score ascending (best match last, for bottom-up display).
 func fuzzyMatch(query string, candidates []string) []string {
 	pattern := []rune(strings.ToLower(query))
-	slab := util.MakeSlab(100*1024, 2048)
+	slab := getSlab()
+	defer putSlab(slab)
 
 	var matches []fuzzyStringMatch
 	for _, c := range candidates {
@@ -217,6 +221,48 @@ func fuzzyMatch(query string, candidates []string) []string {
 	return out
 }
 
+// ItemMatch is an item with its fuzzy score against a query.
+type ItemMatch struct {
+	Item  Item
+	Score int
+	// pos holds the rune positions of the item's FilterValue the query
+	// matched, when they were asked for.
+	pos []int
+}
+
+// MatchItems scores items against query with the picker's built-in fuzzy
+// matcher and returns those that match, best first. Equal scores keep the
+// input order.
+func MatchItems(query string, items []Item) []ItemMatch {
+	matches := matchItems(query, items, make(fuzzyChars, len(items)), false)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// matchItems returns the items query fuzzy-matches, in input order, with the
+// matched positions when withPos is set. chars supplies the converted filter
+// values.
+func matchItems(query string, items []Item, chars fuzzyChars, withPos bool) []ItemMatch {
+	pattern := []rune(strings.ToLower(query))
+	slab := getSlab()
+	defer putSlab(slab)
+
+	var matches []ItemMatch
+	for _, item := range items {
+		result, pos := algo.FuzzyMatchV2(false, true, true, chars.get(item.FilterValue()), pattern, withPos, slab)
+		if result.Score > 0 {
+			m := ItemMatch{Item: item, Score: result.Score}
+			if pos != nil {
+				m.pos = *pos
+			}
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
 // LastNSegments returns the last n segments of a path joined with "/".
 // For n=2 and path="/a/b/c/d", returns "c/d".
 // For n=1, equivalent to filepath.Base.
@@ -244,3 +290,63 @@ var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]|\x1b\][^\x07]*\x07|\x1
 func StripANSI(s string) string {
 	return ansiRegex.ReplaceAllString(s, "")
 }
+
+// highlightMatches renders s with the runes whose index plus offset is in
+// pos in match and the rest in base (nil for plain text). pos holds rune
+// indices into the text the query was matched against, of which s is the
+// part starting at rune offset.
+func highlightMatches(s string, offset int, pos []int, base *lipgloss.Style, match lipgloss.Style) string {
+	render := func(text string) string {
+		if base == nil {
+			return text
+		}
+		return base.Render(text)
+	}
+	if len(pos) == 0 {
+		return render(s)
+	}
+	matched := make(map[int]bool, len(pos))
+	for _, i := range pos {
+		matched[i-offset] = true
+	}
+
+	var b, run strings.Builder
+	runMatched := false
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if runMatched {
+			b.WriteString(match.Render(run.String()))
+		} else {
+			b.WriteString(render(run.String()))
+		}
+		run.Reset()
+	}
+	i := 0
+	for _, r := range s {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run.WriteRune(r)
+		i++
+	}
+	flush()
+	return b.String()
+}
+
+// sanitizeName makes a name from the filesystem safe to draw: escape
+// sequences are dropped, and invalid UTF-8 and other control characters
+// become U+FFFD, so a hostile directory name can't move the cursor or break
+// the row layout. Only the display changes; callers keep the raw value for
+// actions.
+func sanitizeName(s string) string {
+	s = StripANSI(strings.ToValidUTF8(s, "�"))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '�'
+		}
+		return r
+	}, s)
+}
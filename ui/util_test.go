@@ -183,3 +183,75 @@ func TestLastNSegments(t *testing.T) {
 		})
 	}
 }
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "api", want: "api"},
+		{name: "unicode kept", in: "café/日本", want: "café/日本"},
+		{name: "color escapes dropped", in: "a\x1b[31mred\x1b[0m", want: "ared"},
+		{name: "cursor escapes dropped", in: "x\x1b[2J\x1b[Hy", want: "xy"},
+		{name: "title escape dropped", in: "x\x1b]0;pwned\x07y", want: "xy"},
+		{name: "invalid utf-8 replaced", in: "bad\xff\xfe", want: "bad�"},
+		{name: "whitespace controls replaced", in: "a\tb\nc\rd", want: "a�b�c�d"},
+		{name: "bell and del replaced", in: "x\x07\x7f", want: "x��"},
+		{name: "lone escape replaced", in: "x\x1by", want: "x�y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeName(tt.in); got != tt.want {
+				t.Errorf("sanitizeName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	m := matchStyle.Render
+	tests := []struct {
+		name   string
+		in     string
+		offset int
+		pos    []int
+		want   string
+	}{
+		{name: "no positions", in: "api", want: "api"},
+		{name: "runs grouped", in: "api-server", pos: []int{5, 0, 4, 1}, want: m("ap") + "i-" + m("se") + "rver"},
+		{name: "offset", in: "work", offset: 4, pos: []int{1, 4, 5}, want: m("wo") + "rk"},
+		{name: "multibyte runes", in: "café", pos: []int{3}, want: "caf" + m("é")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightMatches(tt.in, tt.offset, tt.pos, nil, matchStyle); got != tt.want {
+				t.Errorf("highlightMatches(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchItems(t *testing.T) {
+	items := []Item{
+		{Name: "w-e-b", Path: "/scattered"},
+		{Name: "api", Path: "/api"},
+		{Name: "website", Path: "/website"},
+		{Name: "docs", Path: "/docs", Tags: []string{"web"}},
+	}
+	matches := MatchItems("web", items)
+	var paths []string
+	for _, m := range matches {
+		paths = append(paths, m.Item.Path)
+	}
+	if len(paths) != 3 || paths[0] != "/website" {
+		t.Fatalf("MatchItems paths = %v, want website first and api left out", paths)
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Errorf("matches not sorted best first: %+v", matches)
+		}
+	}
+}
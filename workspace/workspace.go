@@ -0,0 +1,236 @@
+// Package workspace saves the shape of pop's project sessions — which ones
+// are running and their windows' names, directories and pane layouts — and
+// recreates them later, typically after a reboot cleared the tmux server.
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glebglazov/pop/debug"
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// Window is one saved tmux window. Path is the active pane's directory, which
+// every recreated pane starts in; Layout is tmux's window_layout string,
+// reapplied once the window has Panes panes again.
+type Window struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Panes  int    `json:"panes"`
+	Layout string `json:"layout"`
+}
+
+// Session is one saved project session.
+type Session struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Windows []Window `json:"windows"`
+}
+
+// Workspace is the saved set of sessions.
+type Workspace struct {
+	SavedAt  time.Time `json:"saved_at"`
+	Sessions []Session `json:"sessions"`
+}
+
+// Target names a session eligible for saving: a pop project session and the
+// project directory it belongs to.
+type Target struct {
+	Name string
+	Path string
+}
+
+// Deps holds the dependencies behind workspace save and restore.
+type Deps struct {
+	FS   deps.FileSystem
+	Tmux deps.Tmux
+	Now  func() time.Time
+	Path string
+}
+
+// DefaultDeps returns workspace dependencies wired to real implementations.
+func DefaultDeps() *Deps {
+	fs := deps.NewRealFileSystem()
+	return &Deps{
+		FS:   fs,
+		Tmux: deps.NewRealTmux(),
+		Now:  time.Now,
+		Path: defaultPath(fs),
+	}
+}
+
+// defaultPath returns the workspace file path in pop's data dir, respecting
+// XDG_DATA_HOME with the ~/.local/share/pop fallback, consistent with the
+// history and tips paths.
+func defaultPath(fs deps.FileSystem) string {
+	if xdgData := fs.Getenv("XDG_DATA_HOME"); xdgData != "" {
+		return filepath.Join(xdgData, "pop", "workspace.json")
+	}
+	home, err := fs.UserHomeDir()
+	if err != nil {
+		debug.Error("workspace: UserHomeDir: %v", err)
+	}
+	return filepath.Join(home, ".local", "share", "pop", "workspace.json")
+}
+
+// listWindowsFormat is the list-windows format Capture parses, one window per
+// line. The name goes last since it is the only field that may contain a tab.
+const listWindowsFormat = "#{window_panes}\t#{window_layout}\t#{pane_current_path}\t#{window_name}"
+
+// Capture records the windows of every target whose session is running.
+// Targets without a live session are left out.
+func Capture(targets []Target) (*Workspace, error) {
+	return CaptureWith(DefaultDeps(), targets)
+}
+
+// CaptureWith is Capture using provided dependencies.
+func CaptureWith(d *Deps, targets []Target) (*Workspace, error) {
+	ws := &Workspace{SavedAt: d.Now()}
+	for _, t := range targets {
+		if !d.Tmux.HasSession(t.Name) {
+			continue
+		}
+		out, err := d.Tmux.Command("list-windows", "-t", "="+t.Name, "-F", listWindowsFormat)
+		if err != nil {
+			return nil, fmt.Errorf("list windows of %s: %w", t.Name, err)
+		}
+		s := Session{Name: t.Name, Path: t.Path}
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.SplitN(line, "\t", 4)
+			if len(fields) != 4 {
+				continue
+			}
+			panes, _ := strconv.Atoi(fields[0])
+			s.Windows = append(s.Windows, Window{
+				Name:   fields[3],
+				Path:   fields[2],
+				Panes:  max(panes, 1),
+				Layout: fields[1],
+			})
+		}
+		ws.Sessions = append(ws.Sessions, s)
+	}
+	return ws, nil
+}
+
+// Save writes ws to the workspace file, creating the data dir as needed.
+func Save(ws *Workspace) error {
+	return SaveWith(DefaultDeps(), ws)
+}
+
+// SaveWith is Save using provided dependencies.
+func SaveWith(d *Deps, ws *Workspace) error {
+	data, err := json.MarshalIndent(ws, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal workspace: %w", err)
+	}
+	if err := d.FS.MkdirAll(filepath.Dir(d.Path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(d.Path), err)
+	}
+	if err := d.FS.WriteFile(d.Path, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", d.Path, err)
+	}
+	return nil
+}
+
+// Load reads the workspace file.
+func Load() (*Workspace, error) {
+	return LoadWith(DefaultDeps())
+}
+
+// LoadWith is Load using provided dependencies.
+func LoadWith(d *Deps) (*Workspace, error) {
+	data, err := d.FS.ReadFile(d.Path)
+	if err != nil {
+		return nil, err
+	}
+	var ws Workspace
+	if err := json.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", d.Path, err)
+	}
+	return &ws, nil
+}
+
+// Outcome reports what Restore did with one saved session.
+type Outcome struct {
+	Name string
+	// Skipped is the reason the session was not recreated, or "" when it was.
+	Skipped string
+}
+
+// Restore recreates every saved session that is not already running. Sessions
+// whose project directory is gone are skipped; a session that fails partway
+// is killed again so a retry starts clean.
+func Restore(ws *Workspace) ([]Outcome, error) {
+	return RestoreWith(DefaultDeps(), ws)
+}
+
+// RestoreWith is Restore using provided dependencies.
+func RestoreWith(d *Deps, ws *Workspace) ([]Outcome, error) {
+	var outcomes []Outcome
+	for _, s := range ws.Sessions {
+		switch {
+		case d.Tmux.HasSession(s.Name):
+			outcomes = append(outcomes, Outcome{Name: s.Name, Skipped: "already running"})
+			continue
+		case !exists(d, s.Path):
+			outcomes = append(outcomes, Outcome{Name: s.Name, Skipped: "directory missing"})
+			continue
+		}
+		if err := restoreSession(d, s); err != nil {
+			if _, killErr := d.Tmux.Command("kill-session", "-t", "="+s.Name); killErr != nil {
+				debug.Error("workspace: clean up %s: %v", s.Name, killErr)
+			}
+			return outcomes, fmt.Errorf("restore %s: %w", s.Name, err)
+		}
+		outcomes = append(outcomes, Outcome{Name: s.Name})
+	}
+	return outcomes, nil
+}
+
+func restoreSession(d *Deps, s Session) error {
+	windows := s.Windows
+	if len(windows) == 0 {
+		windows = []Window{{Path: s.Path, Panes: 1}}
+	}
+	for i, w := range windows {
+		dir := w.Path
+		if !exists(d, dir) {
+			dir = s.Path
+		}
+		args := []string{"new-window", "-d", "-t", s.Name + ":", "-P", "-F", "#{window_id}", "-c", dir}
+		if i == 0 {
+			args = []string{"new-session", "-d", "-s", s.Name, "-P", "-F", "#{window_id}", "-c", dir}
+		}
+		if w.Name != "" {
+			args = append(args, "-n", w.Name)
+		}
+		windowID, err := d.Tmux.Command(args...)
+		if err != nil {
+			return err
+		}
+		for p := 1; p < w.Panes; p++ {
+			if _, err := d.Tmux.Command("split-window", "-d", "-t", windowID, "-c", dir); err != nil {
+				return err
+			}
+		}
+		if w.Layout != "" {
+			// A layout that no longer fits (e.g. a much smaller terminal) is
+			// cosmetic; keep the panes tmux's default arrangement gave them.
+			if _, err := d.Tmux.Command("select-layout", "-t", windowID, w.Layout); err != nil {
+				debug.Error("workspace: layout for %s:%s: %v", s.Name, w.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func exists(d *Deps, path string) bool {
+	_, err := d.FS.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,170 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebglazov/pop/internal/deps"
+)
+
+// memFS is an in-memory FileSystem: written files are readable back, and Stat
+// succeeds for the files plus any directory listed in dirs.
+type memFS struct {
+	deps.MockFileSystem
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+func newMemFS(dirs ...string) *memFS {
+	m := &memFS{files: map[string][]byte{}, dirs: map[string]bool{}}
+	for _, d := range dirs {
+		m.dirs[d] = true
+	}
+	m.ReadFileFunc = func(path string) ([]byte, error) {
+		data, ok := m.files[path]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return data, nil
+	}
+	m.WriteFileFunc = func(path string, data []byte, _ os.FileMode) error {
+		m.files[path] = append([]byte(nil), data...)
+		return nil
+	}
+	m.MkdirAllFunc = func(string, os.FileMode) error { return nil }
+	m.StatFunc = func(path string) (os.FileInfo, error) {
+		if m.dirs[path] {
+			return &deps.MockFileInfo{}, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	return m
+}
+
+func TestCaptureWith(t *testing.T) {
+	d := &Deps{
+		Now: func() time.Time { return time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC) },
+		Tmux: &deps.MockTmux{
+			HasSessionFunc: func(name string) bool { return name == "api" },
+			CommandFunc: func(args ...string) (string, error) {
+				if args[0] != "list-windows" || args[2] != "=api" {
+					t.Errorf("unexpected command %v", args)
+				}
+				return "2\tb1f5,80x24,0,0{40x24,0,0,1,39x24,41,0,2}\t/src/api\tedit\n1\tc0d1,80x24,0,0,3\t/src/api/web\tdev server", nil
+			},
+		},
+	}
+
+	ws, err := CaptureWith(d, []Target{{Name: "api", Path: "/src/api"}, {Name: "idle", Path: "/src/idle"}})
+	if err != nil {
+		t.Fatalf("CaptureWith() error = %v", err)
+	}
+	want := []Session{{
+		Name: "api",
+		Path: "/src/api",
+		Windows: []Window{
+			{Name: "edit", Path: "/src/api", Panes: 2, Layout: "b1f5,80x24,0,0{40x24,0,0,1,39x24,41,0,2}"},
+			{Name: "dev server", Path: "/src/api/web", Panes: 1, Layout: "c0d1,80x24,0,0,3"},
+		},
+	}}
+	if !reflect.DeepEqual(ws.Sessions, want) {
+		t.Errorf("sessions = %+v, want %+v", ws.Sessions, want)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	fs := newMemFS()
+	d := &Deps{FS: fs, Path: "/data/pop/workspace.json"}
+	ws := &Workspace{
+		SavedAt:  time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		Sessions: []Session{{Name: "api", Path: "/src/api", Windows: []Window{{Name: "edit", Path: "/src/api", Panes: 1}}}},
+	}
+	if err := SaveWith(d, ws); err != nil {
+		t.Fatalf("SaveWith() error = %v", err)
+	}
+	got, err := LoadWith(d)
+	if err != nil {
+		t.Fatalf("LoadWith() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, ws) {
+		t.Errorf("round trip = %+v, want %+v", got, ws)
+	}
+}
+
+func TestRestoreWith(t *testing.T) {
+	var commands []string
+	d := &Deps{
+		FS: newMemFS("/src/api", "/src/running"),
+		Tmux: &deps.MockTmux{
+			HasSessionFunc: func(name string) bool { return name == "running" },
+			CommandFunc: func(args ...string) (string, error) {
+				commands = append(commands, strings.Join(args, " "))
+				if args[0] == "new-session" || args[0] == "new-window" {
+					return "@" + string(rune('0'+len(commands))), nil
+				}
+				return "", nil
+			},
+		},
+	}
+	ws := &Workspace{Sessions: []Session{
+		{Name: "running", Path: "/src/running"},
+		{Name: "gone", Path: "/src/gone"},
+		{Name: "api", Path: "/src/api", Windows: []Window{
+			{Name: "edit", Path: "/src/api", Panes: 2, Layout: "L1"},
+			{Name: "logs", Path: "/src/api/deleted", Panes: 1},
+		}},
+	}}
+
+	outcomes, err := RestoreWith(d, ws)
+	if err != nil {
+		t.Fatalf("RestoreWith() error = %v", err)
+	}
+	wantOutcomes := []Outcome{
+		{Name: "running", Skipped: "already running"},
+		{Name: "gone", Skipped: "directory missing"},
+		{Name: "api"},
+	}
+	if !reflect.DeepEqual(outcomes, wantOutcomes) {
+		t.Errorf("outcomes = %+v, want %+v", outcomes, wantOutcomes)
+	}
+	wantCommands := []string{
+		"new-session -d -s api -P -F #{window_id} -c /src/api -n edit",
+		"split-window -d -t @1 -c /src/api",
+		"select-layout -t @1 L1",
+		// The window's own directory is gone, so it falls back to the project.
+		"new-window -d -t api: -P -F #{window_id} -c /src/api -n logs",
+	}
+	if !reflect.DeepEqual(commands, wantCommands) {
+		t.Errorf("commands:\n%s\nwant:\n%s", strings.Join(commands, "\n"), strings.Join(wantCommands, "\n"))
+	}
+}
+
+func TestRestoreWith_FailureKillsPartialSession(t *testing.T) {
+	var killed string
+	d := &Deps{
+		FS: newMemFS("/src/api"),
+		Tmux: &deps.MockTmux{
+			CommandFunc: func(args ...string) (string, error) {
+				switch args[0] {
+				case "split-window":
+					return "", errors.New("no space for new pane")
+				case "kill-session":
+					killed = args[2]
+				}
+				return "@1", nil
+			},
+		},
+	}
+	ws := &Workspace{Sessions: []Session{{Name: "api", Path: "/src/api", Windows: []Window{{Name: "edit", Path: "/src/api", Panes: 3}}}}}
+
+	if _, err := RestoreWith(d, ws); err == nil {
+		t.Fatal("expected error when a pane cannot be split")
+	}
+	if killed != "=api" {
+		t.Errorf("killed %q, want =api", killed)
+	}
+}